@@ -9,8 +9,13 @@ import (
 	"strings"
 	"time"
 
+	"github.com/anicolao/dikuclient/internal/client"
 	"github.com/anicolao/dikuclient/internal/config"
+	"github.com/anicolao/dikuclient/internal/debuglog"
+	"github.com/anicolao/dikuclient/internal/outpipe"
+	"github.com/anicolao/dikuclient/internal/script"
 	"github.com/anicolao/dikuclient/internal/tui"
+	"github.com/anicolao/dikuclient/internal/version"
 	"github.com/anicolao/dikuclient/internal/web"
 	tea "github.com/charmbracelet/bubbletea"
 )
@@ -19,6 +24,8 @@ var (
 	host          = flag.String("host", "", "MUD server hostname")
 	port          = flag.Int("port", 4000, "MUD server port")
 	logAll        = flag.Bool("log-all", false, "Enable logging of MUD output and TUI content")
+	debugLevel    = flag.String("debug-level", "all", "What the --log-all telnet debug log records: negotiation or all")
+	logJSON       = flag.Bool("log-json", false, "Write MUD output as JSON lines for later analysis")
 	mapDebug      = flag.Bool("map-debug", false, "Enable mapper debug output")
 	accountName   = flag.String("account", "", "Use saved account")
 	saveAccount   = flag.Bool("save-account", false, "Save account credentials")
@@ -26,11 +33,41 @@ var (
 	deleteAccount = flag.String("delete-account", "", "Delete saved account")
 	webMode       = flag.Bool("web", false, "Start in web mode (HTTP server with WebSocket)")
 	webPort       = flag.Int("web-port", 8080, "Web server port")
+	clientBinary  = flag.String("client-binary", "", "dikuclient binary web mode runs for each session (default: $DIKUCLIENT_BINARY, then dikuclient on PATH)")
+	accessible    = flag.Bool("accessible", false, "Print output as plain scrolling lines for screen readers instead of the full-screen layout")
+	commandFile   = flag.String("command-file", "", "Run the commands in a file once connected and logged in")
+	replayFile    = flag.String("replay", "", "Play back a session recording instead of connecting")
+	replaySpeed   = flag.Float64("replay-speed", 1, "Replay speed multiplier (0 = no delays)")
+	charset       = flag.String("charset", "utf8", "Character set the MUD uses: utf8, latin1 or cp437")
+	lineEnding    = flag.String("line-ending", "crlf", "What ends each command sent to the MUD: crlf, lf or cr")
+	outputPipe    = flag.String("output-pipe", "", "Run a command (e.g. espeak) and write each line of MUD output to its input")
+	showVersion   = flag.Bool("version", false, "Print the version and build details and exit")
 )
 
 func main() {
 	flag.Parse()
 
+	if *showVersion {
+		fmt.Println(version.Get())
+		return
+	}
+
+	mudCharset, err := client.ParseCharset(*charset)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+	mudLineEnding, err := client.ParseLineEnding(*lineEnding)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+	telnetDebugLevel, err := debuglog.ParseLevel(*debugLevel)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+
 	cfg, err := config.LoadConfig()
 	if err != nil {
 		fmt.Printf("Error loading config: %v\n", err)
@@ -65,7 +102,7 @@ func main() {
 		if *logAll {
 			fmt.Printf("Logging enabled for spawned TUI instances (--log-all)\n")
 		}
-		if err := web.StartWithLogging(*webPort, *logAll); err != nil {
+		if err := web.StartWithClientBinary(*webPort, *logAll, *clientBinary); err != nil {
 			fmt.Printf("Error starting web server: %v\n", err)
 			os.Exit(1)
 		}
@@ -81,7 +118,11 @@ func main() {
 	webServer := os.Getenv("DIKUCLIENT_WEB_SERVER")
 	webPort := os.Getenv("DIKUCLIENT_WEB_PORT")
 	
-	if *accountName != "" {
+	if *replayFile != "" {
+		// Replay a recording; --host and --port only choose the map to use
+		finalHost = *host
+		finalPort = *port
+	} else if *accountName != "" {
 		// Use saved account
 		account, err := cfg.GetAccount(*accountName)
 		if err != nil {
@@ -213,6 +254,11 @@ func main() {
 		}
 		defer telnetDebugLog.Close()
 
+		// Each log starts with the build that wrote it
+		for _, logFile := range []*os.File{mudLogFile, tuiLogFile, telnetDebugLog} {
+			fmt.Fprintf(logFile, "# %s\n", version.Get())
+		}
+
 		fmt.Printf("Logging enabled:\n")
 		fmt.Printf("  MUD output: mud-output-%s.log\n", timestamp)
 		fmt.Printf("  TUI content: tui-content-%s.log\n", timestamp)
@@ -222,24 +268,113 @@ func main() {
 
 	// Create the TUI model with auto-login credentials
 	model := tui.NewModelWithAuth(finalHost, finalPort, username, password, mudLogFile, tuiLogFile, telnetDebugLog, *mapDebug)
+	model.SetDebugLevel(telnetDebugLevel)
+
+	// Start the structured log if --log-json flag is set
+	if *logJSON {
+		jsonLogPath := fmt.Sprintf("mud-output-%s.jsonl", time.Now().Format("20060102-150405"))
+		if err := model.StartJSONLog(jsonLogPath); err != nil {
+			fmt.Printf("Error creating JSON log file: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("JSON log: %s\n", jsonLogPath)
+	}
+
+	// Queue the startup commands if --command-file is set
+	if *commandFile != "" {
+		steps, err := script.LoadFile(*commandFile)
+		if err != nil {
+			fmt.Printf("Error reading command file: %v\n", err)
+			os.Exit(1)
+		}
+		model.SetStartupScript(steps)
+	}
+
+	// Answer the menus the MUD shows after the password, as saved for the character
+	if err := model.SetLoginMenu(cfg.LoginMenu(finalHost, finalPort, username)); err != nil {
+		fmt.Printf("Error in accounts.json: %v\n", err)
+		os.Exit(1)
+	}
+
+	// The server's saved charset and line ending apply unless given on the command line
+	savedCharset, savedLineEnding := cfg.Encoding(finalHost, finalPort)
+	if savedCharset != "" && !flagGiven("charset") {
+		if mudCharset, err = client.ParseCharset(savedCharset); err != nil {
+			fmt.Printf("Error in accounts.json: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	if savedLineEnding != "" && !flagGiven("line-ending") {
+		if mudLineEnding, err = client.ParseLineEnding(savedLineEnding); err != nil {
+			fmt.Printf("Error in accounts.json: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	model.SetCharset(mudCharset)
+	model.SetLineEnding(mudLineEnding)
+
+	// Feed MUD output to another program if --output-pipe is set
+	if *outputPipe != "" {
+		pipe, err := outpipe.Start(*outputPipe)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		defer pipe.Close()
+		model.SetOutputPipe(pipe)
+	}
+
+	// Play back a recording instead of connecting if --replay is set
+	if *replayFile != "" {
+		chunks, err := client.LoadRecording(*replayFile)
+		if err != nil {
+			fmt.Printf("Error reading recording: %v\n", err)
+			os.Exit(1)
+		}
+		model.SetReplay(*replayFile, chunks, *replaySpeed)
+	}
 
 	// Create the Bubble Tea program
 	// Explicitly specify input/output to ensure proper terminal handling
-	p := tea.NewProgram(
-		&model,
-		tea.WithAltScreen(),
-		tea.WithMouseCellMotion(),
+	options := []tea.ProgramOption{
 		tea.WithInput(os.Stdin),
 		tea.WithOutput(os.Stdout),
-	)
+	}
+	if *accessible {
+		// Output scrolls in the normal screen so screen readers pick up
+		// each new line; only the input line is redrawn
+		model.SetAccessible(true)
+	} else {
+		options = append(options, tea.WithAltScreen(), tea.WithMouseCellMotion())
+	}
+	p := tea.NewProgram(&model, options...)
 
-	// Run the program
-	if _, err := p.Run(); err != nil {
+	// Run the program, then write map and XP stats changes still waiting
+	// to be saved
+	_, err = p.Run()
+	model.FlushSaves()
+	model.FlushLogs()
+	if message := model.ExitMessage(); message != "" {
+		fmt.Println(message)
+	}
+	if err != nil {
 		fmt.Printf("Error: %v\n", err)
 		os.Exit(1)
 	}
 }
 
+// flagGiven reports whether a flag was set on the command line rather than
+// left at its default
+func flagGiven(name string) bool {
+	given := false
+	flag.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			given = true
+		}
+	})
+	return given
+}
+
 func handleListAccounts(cfg *config.Config) {
 	accounts := cfg.ListAccounts()
 	if len(accounts) == 0 {
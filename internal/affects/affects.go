@@ -0,0 +1,203 @@
+package affects
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Affect is a spell or skill currently affecting the character
+type Affect struct {
+	Name    string    // Affect name (e.g., "armor")
+	Hours   int       // Duration reported by the MUD in game hours (-1 = permanent)
+	Expires time.Time // When the affect wears off (zero if permanent)
+	Warned  bool      // The about-to-expire warning has been given
+}
+
+// Tracker follows the affects reported by the MUD's affects/spells listing
+type Tracker struct {
+	Affects []*Affect
+	listing bool      // Currently reading lines of an affects listing
+	pending []*Affect // Affects read so far from the current listing
+}
+
+// headerPattern matches the line that starts an affects listing. Some MUDs
+// put the first affect on the same line.
+var headerPattern = regexp.MustCompile(`(?i)^(?:you are affected by(?: the following spells)?|affecting spells|affects)\s*:\s*(.*)$`)
+
+// noAffectsPattern matches the reply when nothing is affecting the character
+var noAffectsPattern = regexp.MustCompile(`(?i)^you are not affected by any`)
+
+// affectPatterns match one affect per line in the listing formats seen on
+// Diku derivatives. Each has a name group and a duration group.
+var affectPatterns = []*regexp.Regexp{
+	// ROM/Merc: Spell: armor: modifies armor class by -20 for 24 hours
+	regexp.MustCompile(`(?i)^spell\s*:\s*'?(?P<name>[^':]+?)'?\s*:?\s+modifies .* for (?P<duration>\d+ hours?)`),
+	regexp.MustCompile(`(?i)^spell\s*:\s*'?(?P<name>[^':]+?)'?\s*:?\s+modifies .*(?P<duration>permanently)`),
+	// CircleMUD score: SPL: (  5hr) armor      modifies AC by -20
+	regexp.MustCompile(`(?i)^spl\s*:\s*\(\s*(?P<duration>\d+\s*hr|perm)\s*\)\s*(?P<name>.+?)(?:\s{2,}.*)?$`),
+	// armor (12 hours), bless (approx. 3 hours), sanctuary (permanent)
+	regexp.MustCompile(`(?i)^'?(?P<name>[^'(]+?)'?\s*\((?:approx\.?\s*)?(?P<duration>\d+\s*(?:hours?|hrs?|ticks?)|permanent)\)$`),
+	// sanctuary: 5 hours
+	regexp.MustCompile(`(?i)^'?(?P<name>[^':]+?)'?\s*:\s*(?P<duration>\d+\s*(?:hours?|hrs?|ticks?)|permanent)$`),
+}
+
+// durationNumber extracts the number of hours from a duration
+var durationNumber = regexp.MustCompile(`\d+`)
+
+// NewTracker creates an empty affects tracker
+func NewTracker() *Tracker {
+	return &Tracker{
+		Affects: make([]*Affect, 0),
+	}
+}
+
+// ParseAffectLine parses a single line of an affects listing, returning the
+// affect name and its duration in game hours (-1 if permanent)
+func ParseAffectLine(line string) (string, int, bool) {
+	line = strings.TrimSpace(line)
+	for _, pattern := range affectPatterns {
+		matches := pattern.FindStringSubmatch(line)
+		if matches == nil {
+			continue
+		}
+
+		name := strings.TrimSpace(matches[pattern.SubexpIndex("name")])
+		duration := strings.ToLower(matches[pattern.SubexpIndex("duration")])
+		if name == "" {
+			continue
+		}
+
+		if strings.HasPrefix(duration, "perm") {
+			return name, -1, true
+		}
+		hours, err := strconv.Atoi(durationNumber.FindString(duration))
+		if err != nil {
+			continue
+		}
+		return name, hours, true
+	}
+	return "", 0, false
+}
+
+// ProcessLine feeds one line of MUD output to the tracker. hourLength is the
+// real time length of a game hour (one tick). It returns true when a listing
+// has been read and the tracked affects were replaced.
+func (t *Tracker) ProcessLine(line string, now time.Time, hourLength time.Duration) bool {
+	line = strings.TrimSpace(line)
+
+	if noAffectsPattern.MatchString(line) {
+		t.Affects = make([]*Affect, 0)
+		t.listing = false
+		t.pending = nil
+		return true
+	}
+
+	if matches := headerPattern.FindStringSubmatch(line); matches != nil {
+		t.listing = true
+		t.pending = make([]*Affect, 0)
+		if rest := strings.TrimSpace(matches[1]); rest != "" {
+			t.addPending(rest, now, hourLength)
+		}
+		return false
+	}
+
+	if !t.listing {
+		return false
+	}
+
+	// Blank lines and ROM's ": modifies ..." continuation lines stay in the listing
+	if line == "" || strings.HasPrefix(line, ":") {
+		return false
+	}
+	if t.addPending(line, now, hourLength) {
+		return false
+	}
+
+	// Anything else (usually the prompt) ends the listing
+	t.Affects = t.pending
+	t.listing = false
+	t.pending = nil
+	return true
+}
+
+// addPending adds the affect on line to the listing being read
+func (t *Tracker) addPending(line string, now time.Time, hourLength time.Duration) bool {
+	name, hours, ok := ParseAffectLine(line)
+	if !ok {
+		return false
+	}
+
+	affect := &Affect{
+		Name:  name,
+		Hours: hours,
+	}
+	if hours >= 0 {
+		// An affect reported as N hours lasts until the (N+1)th tick
+		affect.Expires = now.Add(time.Duration(hours+1) * hourLength)
+	}
+	t.pending = append(t.pending, affect)
+	return true
+}
+
+// Permanent reports whether the affect never wears off
+func (a *Affect) Permanent() bool {
+	return a.Hours < 0
+}
+
+// Remaining returns the time left before the affect wears off
+func (a *Affect) Remaining(now time.Time) time.Duration {
+	if a.Permanent() {
+		return 0
+	}
+	remaining := a.Expires.Sub(now)
+	if remaining < 0 {
+		return 0
+	}
+	return remaining
+}
+
+// FormatRemaining formats the time left as "1h02m", "04:35" or "perm"
+func (a *Affect) FormatRemaining(now time.Time) string {
+	if a.Permanent() {
+		return "perm"
+	}
+	remaining := a.Remaining(now).Round(time.Second)
+	if remaining >= time.Hour {
+		return fmt.Sprintf("%dh%02dm", int(remaining.Hours()), int(remaining.Minutes())%60)
+	}
+	return fmt.Sprintf("%02d:%02d", int(remaining.Minutes()), int(remaining.Seconds())%60)
+}
+
+// Expiring returns affects that will wear off within lead and haven't been
+// warned about yet, marking them as warned
+func (t *Tracker) Expiring(now time.Time, lead time.Duration) []*Affect {
+	var expiring []*Affect
+	for _, affect := range t.Affects {
+		if affect.Permanent() || affect.Warned {
+			continue
+		}
+		if affect.Remaining(now) <= lead {
+			affect.Warned = true
+			expiring = append(expiring, affect)
+		}
+	}
+	return expiring
+}
+
+// RemoveExpired drops affects that have worn off and returns them
+func (t *Tracker) RemoveExpired(now time.Time) []*Affect {
+	var expired []*Affect
+	active := t.Affects[:0]
+	for _, affect := range t.Affects {
+		if !affect.Permanent() && !now.Before(affect.Expires) {
+			expired = append(expired, affect)
+			continue
+		}
+		active = append(active, affect)
+	}
+	t.Affects = active
+	return expired
+}
@@ -0,0 +1,146 @@
+package affects
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseAffectLine(t *testing.T) {
+	tests := []struct {
+		line  string
+		name  string
+		hours int
+		ok    bool
+	}{
+		{"armor (12 hours)", "armor", 12, true},
+		{"  bless                (1 hour)", "bless", 1, true},
+		{"detect invisibility (approx. 4 hours)", "detect invisibility", 4, true},
+		{"'sanctuary' (3 ticks)", "sanctuary", 3, true},
+		{"infravision (permanent)", "infravision", -1, true},
+		{"sanctuary: 5 hours", "sanctuary", 5, true},
+		{"Spell: armor: modifies armor class by -20 for 24 hours", "armor", 24, true},
+		{"Spell: 'bless' modifies hit roll by 3 for 6 hours with bits none.", "bless", 6, true},
+		{"Spell: fly: modifies none by 0 permanently", "fly", -1, true},
+		{"SPL: (  5hr) armor                modifies AC by -20", "armor", 5, true},
+		{"SPL: (perm) infravision", "infravision", -1, true},
+		{"101H 132V 1000X> ", "", 0, false},
+		{"You feel better.", "", 0, false},
+	}
+
+	for _, tt := range tests {
+		name, hours, ok := ParseAffectLine(tt.line)
+		if ok != tt.ok {
+			t.Errorf("ParseAffectLine(%q) ok = %v, want %v", tt.line, ok, tt.ok)
+			continue
+		}
+		if name != tt.name || hours != tt.hours {
+			t.Errorf("ParseAffectLine(%q) = (%q, %d), want (%q, %d)", tt.line, name, hours, tt.name, tt.hours)
+		}
+	}
+}
+
+func TestProcessListing(t *testing.T) {
+	tracker := NewTracker()
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	lines := []string{
+		"You are affected by:",
+		"armor (12 hours)",
+		"bless (1 hour)",
+		"infravision (permanent)",
+		"101H 132V 1000X>",
+	}
+	updated := false
+	for _, line := range lines {
+		if tracker.ProcessLine(line, now, time.Minute) {
+			updated = true
+		}
+	}
+
+	if !updated {
+		t.Fatal("Expected the listing to update the tracker")
+	}
+	if len(tracker.Affects) != 3 {
+		t.Fatalf("Expected 3 affects, got %d", len(tracker.Affects))
+	}
+	if tracker.Affects[0].Name != "armor" || !tracker.Affects[0].Expires.Equal(now.Add(13*time.Minute)) {
+		t.Errorf("Expected armor to expire in 13 minutes, got %+v", tracker.Affects[0])
+	}
+	if !tracker.Affects[2].Permanent() {
+		t.Error("Expected infravision to be permanent")
+	}
+
+	// Lines outside a listing are ignored
+	if tracker.ProcessLine("armor (1 hour)", now, time.Minute) {
+		t.Error("Affect line outside a listing should be ignored")
+	}
+
+	// A new listing replaces the old one
+	tracker.ProcessLine("You are affected by: sanctuary (2 hours)", now, time.Minute)
+	tracker.ProcessLine("101H 132V 1000X>", now, time.Minute)
+	if len(tracker.Affects) != 1 || tracker.Affects[0].Name != "sanctuary" {
+		t.Errorf("Expected only sanctuary after relisting, got %+v", tracker.Affects)
+	}
+
+	if !tracker.ProcessLine("You are not affected by any spells.", now, time.Minute) || len(tracker.Affects) != 0 {
+		t.Errorf("Expected no affects after 'not affected', got %+v", tracker.Affects)
+	}
+}
+
+func TestCountdown(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	affect := &Affect{Name: "armor", Hours: 2, Expires: now.Add(3 * time.Minute)}
+
+	if got := affect.Remaining(now); got != 3*time.Minute {
+		t.Errorf("Expected 3m remaining, got %v", got)
+	}
+	if got := affect.FormatRemaining(now); got != "03:00" {
+		t.Errorf("Expected '03:00', got %q", got)
+	}
+
+	later := now.Add(10 * time.Second)
+	if got := affect.Remaining(later); got != 2*time.Minute+50*time.Second {
+		t.Errorf("Expected remaining time to count down to 2m50s, got %v", got)
+	}
+	if got := affect.FormatRemaining(later); got != "02:50" {
+		t.Errorf("Expected '02:50', got %q", got)
+	}
+
+	if got := affect.Remaining(now.Add(time.Hour)); got != 0 {
+		t.Errorf("Expected no time remaining after expiry, got %v", got)
+	}
+
+	long := &Affect{Name: "sanctuary", Hours: 60, Expires: now.Add(62 * time.Minute)}
+	if got := long.FormatRemaining(now); got != "1h02m" {
+		t.Errorf("Expected '1h02m', got %q", got)
+	}
+	if got := (&Affect{Name: "fly", Hours: -1}).FormatRemaining(now); got != "perm" {
+		t.Errorf("Expected 'perm', got %q", got)
+	}
+}
+
+func TestExpiringAndRemoveExpired(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	tracker := NewTracker()
+	tracker.Affects = []*Affect{
+		{Name: "armor", Hours: 0, Expires: now.Add(30 * time.Second)},
+		{Name: "bless", Hours: 5, Expires: now.Add(6 * time.Minute)},
+		{Name: "fly", Hours: -1},
+	}
+
+	expiring := tracker.Expiring(now, time.Minute)
+	if len(expiring) != 1 || expiring[0].Name != "armor" {
+		t.Fatalf("Expected armor to be expiring, got %+v", expiring)
+	}
+	if again := tracker.Expiring(now, time.Minute); len(again) != 0 {
+		t.Errorf("Expected no repeated warning, got %+v", again)
+	}
+
+	expired := tracker.RemoveExpired(now.Add(time.Minute))
+	if len(expired) != 1 || expired[0].Name != "armor" {
+		t.Fatalf("Expected armor to expire, got %+v", expired)
+	}
+	if len(tracker.Affects) != 2 {
+		t.Errorf("Expected 2 affects left, got %d", len(tracker.Affects))
+	}
+}
@@ -6,20 +6,23 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
+	"sort"
 	"strings"
 )
 
 // Alias represents a command alias with parameter substitution
 type Alias struct {
-	ID       string `json:"id"`       // Unique identifier
-	Name     string `json:"name"`     // Alias name (e.g., "gat")
-	Template string `json:"template"` // Template with placeholders (e.g., "give all <target>")
+	ID       string `json:"id"`              // Unique identifier
+	Name     string `json:"name"`            // Alias name (e.g., "gat")
+	Template string `json:"template"`        // Template with placeholders (e.g., "give all <target>")
+	Group    string `json:"group,omitempty"` // Group the alias belongs to ("" = none)
 }
 
 // Manager manages all aliases
 type Manager struct {
-	Aliases  []*Alias `json:"aliases"`
-	filePath string   // Path to aliases.json (not serialized)
+	Aliases        []*Alias `json:"aliases"`
+	DisabledGroups []string `json:"disabled_groups,omitempty"` // Groups whose aliases don't expand
+	filePath       string   // Path to aliases.json (not serialized)
 }
 
 // NewManager creates a new alias manager
@@ -109,14 +112,23 @@ func (m *Manager) Save() error {
 
 // Add adds a new alias
 func (m *Manager) Add(name, template string) (*Alias, error) {
+	return m.AddToGroup(name, template, "")
+}
+
+// AddToGroup adds a new alias to a group. Groups can each have an alias of
+// the same name, e.g. "k" for a mage and for a warrior, and the first one in
+// an enabled group is used.
+func (m *Manager) AddToGroup(name, template, group string) (*Alias, error) {
 	// Validate alias name (must be alphanumeric, no spaces)
 	if !regexp.MustCompile(`^[a-zA-Z0-9]+$`).MatchString(name) {
 		return nil, fmt.Errorf("alias name must be alphanumeric")
 	}
 
 	// Check if alias already exists
-	if m.getAliasByName(name) != nil {
-		return nil, fmt.Errorf("alias '%s' already exists", name)
+	for _, alias := range m.Aliases {
+		if alias.Name == name && alias.Group == group {
+			return nil, fmt.Errorf("alias '%s' already exists", name)
+		}
 	}
 
 	// Generate a unique ID
@@ -129,6 +141,7 @@ func (m *Manager) Add(name, template string) (*Alias, error) {
 		ID:       id,
 		Name:     name,
 		Template: template,
+		Group:    group,
 	}
 
 	m.Aliases = append(m.Aliases, alias)
@@ -155,16 +168,59 @@ func (m *Manager) getAliasByID(id string) *Alias {
 	return nil
 }
 
-// getAliasByName finds an alias by its name
+// getAliasByName finds an alias by its name, skipping disabled groups
 func (m *Manager) getAliasByName(name string) *Alias {
 	for _, alias := range m.Aliases {
-		if alias.Name == name {
+		if alias.Name == name && m.GroupEnabled(alias.Group) {
 			return alias
 		}
 	}
 	return nil
 }
 
+// GroupEnabled reports whether aliases in a group expand. Aliases without a
+// group always do.
+func (m *Manager) GroupEnabled(group string) bool {
+	for _, disabled := range m.DisabledGroups {
+		if disabled == group {
+			return false
+		}
+	}
+	return true
+}
+
+// SetGroupEnabled enables or disables every alias in a group
+func (m *Manager) SetGroupEnabled(group string, enabled bool) {
+	if group == "" {
+		return
+	}
+	groups := m.DisabledGroups[:0]
+	for _, disabled := range m.DisabledGroups {
+		if disabled != group {
+			groups = append(groups, disabled)
+		}
+	}
+	if !enabled {
+		groups = append(groups, group)
+		sort.Strings(groups)
+	}
+	m.DisabledGroups = groups
+}
+
+// Groups returns the names of the groups that have aliases, sorted
+func (m *Manager) Groups() []string {
+	seen := make(map[string]bool)
+	var groups []string
+	for _, alias := range m.Aliases {
+		if alias.Group != "" && !seen[alias.Group] {
+			seen[alias.Group] = true
+			groups = append(groups, alias.Group)
+		}
+	}
+	sort.Strings(groups)
+	return groups
+}
+
 // Expand expands an alias with the given arguments
 // Returns the expanded command and true if the command matches an alias,
 // or the original command and false if it doesn't
@@ -326,9 +382,13 @@ func (m *Manager) expandTemplate(template string, args []string) string {
 		}
 	}
 	
-	// Substitute placeholders in the template
+	// Substitute placeholders in the template. A <target> given no
+	// argument is kept, for the client to fill in with the /target.
 	result := template
 	for varName, value := range varMap {
+		if varName == "target" && value == "" {
+			continue
+		}
 		placeholder := fmt.Sprintf("<%s>", varName)
 		result = strings.ReplaceAll(result, placeholder, value)
 	}
@@ -35,7 +35,13 @@ func TestAliasExpansion(t *testing.T) {
 			name:     "Two placeholders with one arg",
 			template: "give <object> <target>",
 			args:     []string{"sword"},
-			expected: "give sword ",
+			expected: "give sword <target>",
+		},
+		{
+			name:     "Two placeholders with no args",
+			template: "give <object> <who>",
+			args:     []string{},
+			expected: "give  ",
 		},
 		{
 			name:     "Args placeholder with multiple remaining",
@@ -77,7 +83,7 @@ func TestAliasExpansion(t *testing.T) {
 			name:     "Empty args with placeholder",
 			template: "give all <target>",
 			args:     []string{},
-			expected: "give all ",
+			expected: "give all <target>",
 		},
 	}
 
@@ -133,7 +139,7 @@ func TestManagerExpand(t *testing.T) {
 		{
 			name:     "Alias with no args",
 			command:  "gat",
-			expected: "give all ",
+			expected: "give all <target>",
 			expanded: true,
 		},
 		{
@@ -314,3 +320,55 @@ func TestComplexParameterSubstitution(t *testing.T) {
 		})
 	}
 }
+
+func TestGroupEnableDisable(t *testing.T) {
+	aliasesPath := filepath.Join(t.TempDir(), "aliases.json")
+	manager, _ := LoadFromPath(aliasesPath)
+	manager.AddToGroup("k", "cast 'magic missile' <target>", "mage")
+	manager.AddToGroup("k", "bash <target>", "warrior")
+	manager.AddToGroup("hl", "cast 'heal' <target>", "mage")
+	manager.Add("gat", "give all <target>")
+
+	// The first group with the name wins while both are enabled
+	if got, _ := manager.Expand("k orc"); got != "cast 'magic missile' orc" {
+		t.Errorf("Expected the mage alias, got %q", got)
+	}
+
+	manager.SetGroupEnabled("mage", false)
+	if got, _ := manager.Expand("k orc"); got != "bash orc" {
+		t.Errorf("Expected the warrior alias with mage disabled, got %q", got)
+	}
+	if _, ok := manager.Expand("hl bob"); ok {
+		t.Error("Expected every mage alias to be disabled")
+	}
+	if _, ok := manager.Expand("gat bob"); !ok {
+		t.Error("Expected aliases without a group to stay enabled")
+	}
+
+	// Membership and state survive a reload
+	if err := manager.Save(); err != nil {
+		t.Fatalf("Failed to save aliases: %v", err)
+	}
+	loaded, err := LoadFromPath(aliasesPath)
+	if err != nil {
+		t.Fatalf("Failed to load aliases: %v", err)
+	}
+	if loaded.GroupEnabled("mage") || !loaded.GroupEnabled("warrior") {
+		t.Errorf("Expected mage disabled and warrior enabled after loading, got %v", loaded.DisabledGroups)
+	}
+	if groups := loaded.Groups(); len(groups) != 2 || groups[0] != "mage" || groups[1] != "warrior" {
+		t.Errorf("Expected groups [mage warrior], got %v", groups)
+	}
+
+	loaded.SetGroupEnabled("mage", true)
+	if got, _ := loaded.Expand("hl bob"); got != "cast 'heal' bob" {
+		t.Errorf("Expected the mage alias back after enabling, got %q", got)
+	}
+	if len(loaded.DisabledGroups) != 0 {
+		t.Errorf("Expected no disabled groups, got %v", loaded.DisabledGroups)
+	}
+
+	if _, err := loaded.AddToGroup("k", "kick <target>", "warrior"); err == nil {
+		t.Error("Expected a duplicate name in the same group to fail")
+	}
+}
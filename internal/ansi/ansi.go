@@ -0,0 +1,190 @@
+package ansi
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Reset is the SGR sequence that clears all text attributes
+const Reset = "\x1b[0m"
+
+// escapePattern matches a complete escape sequence: CSI sequences (colors,
+// cursor movement, erase, private modes), OSC strings (window titles,
+// hyperlinks), character set selection and other two byte escapes
+var escapePattern = regexp.MustCompile(`\x1b(?:\[[0-?]*[ -/]*[@-~]|\][^\x07\x1b]*(?:\x07|\x1b\\)|[()][0-9A-Za-z]|[@-Z\\-_])`)
+
+// Token is a run of plain text or a single escape sequence
+type Token struct {
+	Text   string
+	Escape bool
+}
+
+// Strip removes all escape sequences from s
+func Strip(s string) string {
+	if !strings.Contains(s, "\x1b") {
+		return s
+	}
+	return escapePattern.ReplaceAllString(s, "")
+}
+
+// Split splits s into plain text and escape sequence tokens, in order
+func Split(s string) []Token {
+	var tokens []Token
+	last := 0
+	for _, loc := range escapePattern.FindAllStringIndex(s, -1) {
+		if loc[0] > last {
+			tokens = append(tokens, Token{Text: s[last:loc[0]]})
+		}
+		tokens = append(tokens, Token{Text: s[loc[0]:loc[1]], Escape: true})
+		last = loc[1]
+	}
+	if last < len(s) {
+		tokens = append(tokens, Token{Text: s[last:]})
+	}
+	return tokens
+}
+
+// urlPattern matches a web address in plain text
+var urlPattern = regexp.MustCompile(`https?://[^\s<>"'\x1b]+`)
+
+// Hyperlink wraps text in an OSC 8 hyperlink to url, which supporting
+// terminals make clickable and others show as the plain text
+func Hyperlink(url, text string) string {
+	return "\x1b]8;;" + url + "\x1b\\" + text + "\x1b]8;;\x1b\\"
+}
+
+// LinkURLs makes each web address in the visible text of s a hyperlink.
+// Punctuation ending a sentence isn't part of the address, and a line that
+// already has hyperlinks is left as it is.
+func LinkURLs(s string) string {
+	if !strings.Contains(s, "://") || strings.Contains(s, "\x1b]8;") {
+		return s
+	}
+
+	var b strings.Builder
+	for _, token := range Split(s) {
+		if token.Escape {
+			b.WriteString(token.Text)
+			continue
+		}
+		last := 0
+		for _, loc := range urlPattern.FindAllStringIndex(token.Text, -1) {
+			url := strings.TrimRight(token.Text[loc[0]:loc[1]], ".,;:!?)]}")
+			b.WriteString(token.Text[last:loc[0]])
+			b.WriteString(Hyperlink(url, url))
+			last = loc[0] + len(url)
+		}
+		b.WriteString(token.Text[last:])
+	}
+	return b.String()
+}
+
+// IsSGR reports whether seq is a Select Graphic Rendition sequence, such as
+// "\x1b[1;31m", "\x1b[38;5;208m" or "\x1b[38;2;255;128;0m"
+func IsSGR(seq string) bool {
+	return len(seq) >= 3 && strings.HasPrefix(seq, "\x1b[") && strings.HasSuffix(seq, "m") &&
+		strings.Trim(seq[2:len(seq)-1], "0123456789;:") == ""
+}
+
+// ColorNames are the 16 named terminal colors, in the order of their SGR
+// foreground codes: 30-37, then the bright ones 90-97
+var ColorNames = []string{
+	"black", "red", "green", "yellow", "blue", "magenta", "cyan", "white",
+	"bright-black", "bright-red", "bright-green", "bright-yellow",
+	"bright-blue", "bright-magenta", "bright-cyan", "bright-white",
+}
+
+// NamedColor returns the SGR sequence that sets the foreground to one of
+// the ColorNames (case-insensitive), e.g. "\x1b[33m" for yellow
+func NamedColor(name string) (string, bool) {
+	for i, color := range ColorNames {
+		if strings.EqualFold(name, color) {
+			code := 30 + i
+			if i >= 8 {
+				code = 90 + i - 8
+			}
+			return "\x1b[" + strconv.Itoa(code) + "m", true
+		}
+	}
+	return "", false
+}
+
+// applySGR returns the SGR sequences in effect after seq is applied to state
+func applySGR(state []string, seq string) []string {
+	params := seq[2 : len(seq)-1]
+	first, rest, _ := strings.Cut(params, ";")
+	if strings.Trim(first, "0") != "" {
+		return append(state, seq)
+	}
+	// A leading 0 (or no parameters) resets everything before the rest applies
+	if rest == "" {
+		return nil
+	}
+	return []string{seq}
+}
+
+// Highlight applies sgr to the visible text between byte offsets start and
+// end of Strip(s). The line's own sequences inside the span are followed by
+// sgr again so the highlight isn't lost, and after the span the attributes
+// are reset and the line's own attributes restored.
+func Highlight(s string, start, end int, sgr string) string {
+	if start >= end {
+		return s
+	}
+
+	var b strings.Builder
+	var state []string
+	pos := 0
+	inSpan, done := false, false
+
+	closeSpan := func() {
+		b.WriteString(Reset)
+		for _, seq := range state {
+			b.WriteString(seq)
+		}
+		inSpan, done = false, true
+	}
+
+	for _, token := range Split(s) {
+		if token.Escape {
+			b.WriteString(token.Text)
+			if IsSGR(token.Text) {
+				state = applySGR(state, token.Text)
+				if inSpan {
+					b.WriteString(sgr)
+				}
+			}
+			continue
+		}
+
+		text := token.Text
+		for len(text) > 0 {
+			if !inSpan && !done && pos == start {
+				b.WriteString(sgr)
+				inSpan = true
+			}
+
+			// Write up to the next span boundary
+			next := len(text)
+			if !inSpan && !done && start > pos && start-pos < next {
+				next = start - pos
+			}
+			if inSpan && end-pos < next {
+				next = end - pos
+			}
+			b.WriteString(text[:next])
+			pos += next
+			text = text[next:]
+
+			if inSpan && pos == end {
+				closeSpan()
+			}
+		}
+	}
+
+	if inSpan {
+		closeSpan()
+	}
+	return b.String()
+}
@@ -0,0 +1,197 @@
+package ansi
+
+import (
+	"testing"
+)
+
+func TestStrip(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"plain text", "plain text", "plain text"},
+		{"basic color", "\x1b[32mgreen\x1b[0m", "green"},
+		{"bold and background", "\x1b[1;33;44mwarning\x1b[m", "warning"},
+		{"256 color", "\x1b[38;5;208morange\x1b[0m text", "orange text"},
+		{"truecolor", "\x1b[38;2;255;128;0mtrue\x1b[48;2;0;0;64m color\x1b[0m", "true color"},
+		{"colon truecolor", "\x1b[38:2::255:128:0mcolon\x1b[0m", "colon"},
+		{"cursor and erase", "\x1b[2K\x1b[1Aline\x1b[?25h", "line"},
+		{"window title", "\x1b]0;DikuMUD\x07room", "room"},
+		{"hyperlink", "\x1b]8;;http://example.com\x1b\\link\x1b]8;;\x1b\\", "link"},
+		{"charset", "\x1b(Bplain", "plain"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Strip(tt.input); got != tt.expected {
+				t.Errorf("Strip(%q) = %q, want %q", tt.input, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestSplit(t *testing.T) {
+	tokens := Split("A \x1b[38;2;10;20;30mtruecolor\x1b[0m goblin")
+	expected := []Token{
+		{Text: "A "},
+		{Text: "\x1b[38;2;10;20;30m", Escape: true},
+		{Text: "truecolor"},
+		{Text: "\x1b[0m", Escape: true},
+		{Text: " goblin"},
+	}
+
+	if len(tokens) != len(expected) {
+		t.Fatalf("Expected %d tokens, got %d: %#v", len(expected), len(tokens), tokens)
+	}
+	for i := range tokens {
+		if tokens[i] != expected[i] {
+			t.Errorf("Token %d = %#v, want %#v", i, tokens[i], expected[i])
+		}
+	}
+}
+
+func TestIsSGR(t *testing.T) {
+	for _, seq := range []string{"\x1b[m", "\x1b[0m", "\x1b[1;31;47m", "\x1b[38;5;208m", "\x1b[48;2;1;2;3m", "\x1b[38:2::1:2:3m"} {
+		if !IsSGR(seq) {
+			t.Errorf("Expected %q to be SGR", seq)
+		}
+	}
+	for _, seq := range []string{"\x1b[2K", "\x1b[?25h", "\x1b]0;title\x07", "m"} {
+		if IsSGR(seq) {
+			t.Errorf("Expected %q not to be SGR", seq)
+		}
+	}
+}
+
+func TestHighlight(t *testing.T) {
+	const red = "\x1b[1;31m"
+
+	tests := []struct {
+		name     string
+		input    string
+		start    int
+		end      int
+		expected string
+	}{
+		{
+			name:     "plain line",
+			input:    "A goblin arrives.",
+			start:    2,
+			end:      8,
+			expected: "A " + red + "goblin" + Reset + " arrives.",
+		},
+		{
+			name:     "restores truecolor background",
+			input:    "\x1b[48;2;0;0;64mA goblin arrives.\x1b[0m",
+			start:    2,
+			end:      8,
+			expected: "\x1b[48;2;0;0;64mA " + red + "goblin" + Reset + "\x1b[48;2;0;0;64m arrives.\x1b[0m",
+		},
+		{
+			name:     "reapplies over inner color",
+			input:    "A \x1b[38;5;208mgob\x1b[0mlin arrives.",
+			start:    2,
+			end:      8,
+			expected: "A \x1b[38;5;208m" + red + "gob\x1b[0m" + red + "lin" + Reset + " arrives.",
+		},
+		{
+			name:     "restores stacked attributes",
+			input:    "\x1b[1m\x1b[44mThe goblin\x1b[0m",
+			start:    4,
+			end:      10,
+			expected: "\x1b[1m\x1b[44mThe " + red + "goblin" + Reset + "\x1b[1m\x1b[44m\x1b[0m",
+		},
+		{
+			name:     "span to end of line",
+			input:    "goblin",
+			start:    0,
+			end:      6,
+			expected: red + "goblin" + Reset,
+		},
+		{
+			name:     "empty span",
+			input:    "goblin",
+			start:    3,
+			end:      3,
+			expected: "goblin",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Highlight(tt.input, tt.start, tt.end, red)
+			if got != tt.expected {
+				t.Errorf("Highlight() = %q, want %q", got, tt.expected)
+			}
+			if Strip(got) != Strip(tt.input) {
+				t.Errorf("Highlight changed the visible text: %q", Strip(got))
+			}
+		})
+	}
+}
+
+func TestHyperlink(t *testing.T) {
+	got := Hyperlink("http://example.com/?id=1", "share")
+	want := "\x1b]8;;http://example.com/?id=1\x1b\\share\x1b]8;;\x1b\\"
+	if got != want {
+		t.Errorf("Hyperlink() = %q, want %q", got, want)
+	}
+	if Strip(got) != "share" {
+		t.Errorf("Expected Strip to leave only the text, got %q", Strip(got))
+	}
+}
+
+func TestLinkURLs(t *testing.T) {
+	link := func(url string) string { return Hyperlink(url, url) }
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"no url", "You see nothing special.", "You see nothing special."},
+		{"url", "Visit https://mud.example.com/wiki today", "Visit " + link("https://mud.example.com/wiki") + " today"},
+		{"trailing period", "See http://example.com.", "See " + link("http://example.com") + "."},
+		{"in parentheses", "(http://example.com/a)", "(" + link("http://example.com/a") + ")"},
+		{"two urls", "http://a.com and http://b.com", link("http://a.com") + " and " + link("http://b.com")},
+		{"colored", "\x1b[36mhttp://example.com\x1b[0m", "\x1b[36m" + link("http://example.com") + "\x1b[0m"},
+		{"already linked", Hyperlink("http://example.com", "here"), Hyperlink("http://example.com", "here")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := LinkURLs(tt.input)
+			if got != tt.expected {
+				t.Errorf("LinkURLs(%q) = %q, want %q", tt.input, got, tt.expected)
+			}
+			if Strip(got) != Strip(tt.input) {
+				t.Errorf("Expected the visible text unchanged, got %q", Strip(got))
+			}
+		})
+	}
+}
+
+func TestNamedColor(t *testing.T) {
+	tests := map[string]string{
+		"black":        "\x1b[30m",
+		"yellow":       "\x1b[33m",
+		"White":        "\x1b[37m",
+		"bright-black": "\x1b[90m",
+		"BRIGHT-CYAN":  "\x1b[96m",
+		"bright-white": "\x1b[97m",
+	}
+	for name, expected := range tests {
+		if got, ok := NamedColor(name); !ok || got != expected {
+			t.Errorf("NamedColor(%q) = %q, %v, want %q", name, got, ok, expected)
+		}
+	}
+
+	if len(ColorNames) != 16 {
+		t.Errorf("Expected 16 named colors, got %d", len(ColorNames))
+	}
+	for _, name := range []string{"", "orange", "brightred"} {
+		if _, ok := NamedColor(name); ok {
+			t.Errorf("Expected %q not to be a color", name)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+// Package autosave limits how often data that changes all the time, such as
+// the map while walking, is written to disk.
+package autosave
+
+import "time"
+
+// Scheduler decides when changed data is written: at most once per interval
+// however often it changes, and once more when flushed at the end
+type Scheduler struct {
+	Interval time.Duration
+	dirty    bool
+	lastSave time.Time
+}
+
+// New creates a scheduler that writes at most once per interval
+func New(interval time.Duration) *Scheduler {
+	return &Scheduler{Interval: interval}
+}
+
+// MarkDirty records that the data has changed and needs writing
+func (s *Scheduler) MarkDirty() {
+	s.dirty = true
+}
+
+// Dirty reports whether there are changes that haven't been written
+func (s *Scheduler) Dirty() bool {
+	return s.dirty
+}
+
+// Due reports whether the data should be written now: it has changed and
+// the last write was at least an interval ago
+func (s *Scheduler) Due(now time.Time) bool {
+	return s.dirty && (s.lastSave.IsZero() || now.Sub(s.lastSave) >= s.Interval)
+}
+
+// Run calls save if a write is due and reports whether it did. When save
+// fails the data stays dirty and is tried again an interval later.
+func (s *Scheduler) Run(now time.Time, save func() error) (bool, error) {
+	if !s.Due(now) {
+		return false, nil
+	}
+	return true, s.write(now, save)
+}
+
+// Flush calls save if there are unwritten changes, without waiting for the
+// interval, e.g. when quitting
+func (s *Scheduler) Flush(now time.Time, save func() error) error {
+	if !s.dirty {
+		return nil
+	}
+	return s.write(now, save)
+}
+
+// write calls save and records the attempt
+func (s *Scheduler) write(now time.Time, save func() error) error {
+	s.lastSave = now
+	if err := save(); err != nil {
+		return err
+	}
+	s.dirty = false
+	return nil
+}
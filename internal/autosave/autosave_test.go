@@ -0,0 +1,86 @@
+package autosave
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRapidChangesSaveOnce(t *testing.T) {
+	s := New(5 * time.Second)
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	saves := 0
+	save := func() error {
+		saves++
+		return nil
+	}
+
+	// A fast walk: a room every 200ms, with a check every second
+	for step := 0; step < 20; step++ {
+		now := start.Add(time.Duration(step) * 200 * time.Millisecond)
+		s.MarkDirty()
+		if step%5 == 0 {
+			s.Run(now, save)
+		}
+	}
+
+	// The first change is written straight away, the rest wait for the interval
+	if saves != 1 {
+		t.Errorf("Expected 1 save within the interval, got %d", saves)
+	}
+	if !s.Dirty() {
+		t.Error("Expected the later changes to be waiting")
+	}
+
+	if ran, _ := s.Run(start.Add(4*time.Second), save); ran {
+		t.Error("Expected no save before the interval has passed")
+	}
+	if ran, _ := s.Run(start.Add(5*time.Second), save); !ran || saves != 2 {
+		t.Errorf("Expected a second save once the interval passed, got %d", saves)
+	}
+	if ran, _ := s.Run(start.Add(20*time.Second), save); ran {
+		t.Error("Expected no save without changes")
+	}
+}
+
+func TestFlushWritesPendingChanges(t *testing.T) {
+	s := New(5 * time.Second)
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	saves := 0
+	save := func() error {
+		saves++
+		return nil
+	}
+
+	s.MarkDirty()
+	s.Run(now, save)
+	s.MarkDirty()
+	s.Flush(now.Add(time.Second), save)
+	if saves != 2 || s.Dirty() {
+		t.Errorf("Expected the flush to write the pending change, got %d saves", saves)
+	}
+
+	s.Flush(now.Add(2*time.Second), save)
+	if saves != 2 {
+		t.Errorf("Expected nothing to flush, got %d saves", saves)
+	}
+}
+
+func TestFailedSaveIsRetried(t *testing.T) {
+	s := New(5 * time.Second)
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	s.MarkDirty()
+	if _, err := s.Run(now, func() error { return errors.New("disk full") }); err == nil {
+		t.Fatal("Expected the save error to be returned")
+	}
+	if !s.Dirty() {
+		t.Error("Expected the data to stay dirty after a failed save")
+	}
+	if s.Due(now.Add(time.Second)) {
+		t.Error("Expected the retry to wait for the interval")
+	}
+	if !s.Due(now.Add(5 * time.Second)) {
+		t.Error("Expected a retry after the interval")
+	}
+}
@@ -0,0 +1,257 @@
+// Package captures extracts structured records, such as quest progress, from
+// MUD output using regular expressions.
+package captures
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Capture fills a named record from lines matching a pattern. The first
+// group of the pattern is the key of the entry, so a later line for the same
+// key (e.g. the same quest) updates the entry instead of adding another.
+type Capture struct {
+	Name    string         `json:"name"`             // Record the matches go to (e.g. "quest")
+	Pattern string         `json:"pattern"`          // Regular expression matched against each line
+	Format  string         `json:"format,omitempty"` // How an entry is shown, <1>, <2>, ... are the groups (empty = the matched text)
+	regex   *regexp.Regexp // Compiled pattern (not serialized)
+}
+
+// Manager manages all captures
+type Manager struct {
+	Captures []*Capture `json:"captures"`
+	filePath string     // Path to captures.json (not serialized)
+}
+
+// Match is a line matched by a capture
+type Match struct {
+	Name string // Record the entry belongs to
+	Key  string // Entry key (the first group, or the whole match without groups)
+	Text string // Entry as it is shown
+}
+
+// MaxEntries is how many entries a record keeps
+const MaxEntries = 50
+
+// Record is the entries captured for one name, in the order first seen
+type Record struct {
+	Keys    []string
+	Entries map[string]string
+}
+
+// NewManager creates a new capture manager
+func NewManager() *Manager {
+	return &Manager{
+		Captures: make([]*Capture, 0),
+	}
+}
+
+// GetCapturesPath returns the path to the captures file
+func GetCapturesPath() (string, error) {
+	var configDir string
+
+	// Check for environment variable override
+	if envConfigDir := os.Getenv("DIKUCLIENT_CONFIG_DIR"); envConfigDir != "" {
+		configDir = envConfigDir
+	} else {
+		homeDir, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("failed to get home directory: %w", err)
+		}
+		configDir = filepath.Join(homeDir, ".config", "dikuclient")
+	}
+
+	if err := os.MkdirAll(configDir, 0700); err != nil {
+		return "", fmt.Errorf("failed to create config directory: %w", err)
+	}
+
+	return filepath.Join(configDir, "captures.json"), nil
+}
+
+// Load loads captures from disk
+func Load() (*Manager, error) {
+	capturesPath, err := GetCapturesPath()
+	if err != nil {
+		return nil, err
+	}
+
+	return LoadFromPath(capturesPath)
+}
+
+// LoadFromPath loads captures from a specific path (useful for testing)
+func LoadFromPath(capturesPath string) (*Manager, error) {
+	data, err := os.ReadFile(capturesPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			// Return empty manager if file doesn't exist
+			m := NewManager()
+			m.filePath = capturesPath
+			return m, nil
+		}
+		return nil, fmt.Errorf("failed to read captures file: %w", err)
+	}
+
+	var m Manager
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("failed to parse captures file: %w", err)
+	}
+	m.filePath = capturesPath
+
+	// Compile the patterns, dropping any that no longer compile
+	valid := m.Captures[:0]
+	for _, capture := range m.Captures {
+		if regex, err := regexp.Compile(capture.Pattern); err == nil {
+			capture.regex = regex
+			valid = append(valid, capture)
+		}
+	}
+	m.Captures = valid
+
+	return &m, nil
+}
+
+// Save saves captures to disk
+func (m *Manager) Save() error {
+	capturesPath := m.filePath
+	if capturesPath == "" {
+		var err error
+		capturesPath, err = GetCapturesPath()
+		if err != nil {
+			return err
+		}
+		m.filePath = capturesPath
+	}
+
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal captures: %w", err)
+	}
+
+	if err := os.WriteFile(capturesPath, data, 0600); err != nil {
+		return fmt.Errorf("failed to write captures file: %w", err)
+	}
+
+	return nil
+}
+
+// Add adds a capture, replacing the format of one with the same name and
+// pattern if there is one
+func (m *Manager) Add(name, pattern, format string) (*Capture, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, fmt.Errorf("capture name cannot be empty")
+	}
+	if pattern == "" {
+		return nil, fmt.Errorf("capture pattern cannot be empty")
+	}
+	regex, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pattern: %w", err)
+	}
+
+	for _, capture := range m.Captures {
+		if capture.Name == name && capture.Pattern == pattern {
+			capture.Format = format
+			return capture, nil
+		}
+	}
+
+	capture := &Capture{
+		Name:    name,
+		Pattern: pattern,
+		Format:  format,
+		regex:   regex,
+	}
+	m.Captures = append(m.Captures, capture)
+	return capture, nil
+}
+
+// Remove removes a capture by index (0-based)
+func (m *Manager) Remove(index int) error {
+	if index < 0 || index >= len(m.Captures) {
+		return fmt.Errorf("invalid capture index: %d", index)
+	}
+
+	m.Captures = append(m.Captures[:index], m.Captures[index+1:]...)
+	return nil
+}
+
+// Match returns the entries a line fills in, one per matching capture
+func (m *Manager) Match(line string) []Match {
+	var matches []Match
+	for _, capture := range m.Captures {
+		if capture.regex == nil {
+			continue
+		}
+		groups := capture.regex.FindStringSubmatch(line)
+		if groups == nil {
+			continue
+		}
+
+		key := strings.TrimSpace(groups[0])
+		if len(groups) > 1 {
+			key = strings.TrimSpace(groups[1])
+		}
+		text := strings.TrimSpace(groups[0])
+		if capture.Format != "" {
+			text = expandGroups(capture.Format, groups)
+		}
+		matches = append(matches, Match{Name: capture.Name, Key: key, Text: text})
+	}
+	return matches
+}
+
+// expandGroups replaces <1>, <2>, ... in format with the matched groups,
+// highest first so <1> doesn't eat the start of <10>
+func expandGroups(format string, groups []string) string {
+	for i := len(groups) - 1; i >= 1; i-- {
+		format = strings.ReplaceAll(format, "<"+strconv.Itoa(i)+">", strings.TrimSpace(groups[i]))
+	}
+	return format
+}
+
+// Set adds or updates the entry for a key, dropping the oldest entry when the
+// record is full
+func (r *Record) Set(key, text string) {
+	if r.Entries == nil {
+		r.Entries = make(map[string]string)
+	}
+	if _, ok := r.Entries[key]; !ok {
+		r.Keys = append(r.Keys, key)
+		if len(r.Keys) > MaxEntries {
+			delete(r.Entries, r.Keys[0])
+			r.Keys = r.Keys[1:]
+		}
+	}
+	r.Entries[key] = text
+}
+
+// Lines returns the entries in the order first seen
+func (r *Record) Lines() []string {
+	lines := make([]string, len(r.Keys))
+	for i, key := range r.Keys {
+		lines[i] = r.Entries[key]
+	}
+	return lines
+}
+
+// PanelTitle returns the title of the sidebar panel showing a record, the
+// name capitalized and made plural (e.g. "quest" is shown as "Quests")
+func PanelTitle(name string) string {
+	runes := []rune(name)
+	if len(runes) == 0 {
+		return name
+	}
+	runes[0] = unicode.ToUpper(runes[0])
+	title := string(runes)
+	if !strings.HasSuffix(strings.ToLower(title), "s") {
+		title += "s"
+	}
+	return title
+}
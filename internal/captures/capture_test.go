@@ -0,0 +1,90 @@
+package captures
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestMatchFillsRecord(t *testing.T) {
+	m := NewManager()
+	if _, err := m.Add("quest", `Quest: (.+) - (\d+)/(\d+)`, "<1>: <2>/<3>"); err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+
+	record := &Record{}
+	for _, line := range []string{
+		"Quest: Slay the rats - 3/10",
+		"Quest: Find the ring - 0/1",
+		"You are hungry.",
+		"Quest: Slay the rats - 4/10",
+	} {
+		for _, match := range m.Match(line) {
+			if match.Name != "quest" {
+				t.Errorf("Expected the quest record, got %q", match.Name)
+			}
+			record.Set(match.Key, match.Text)
+		}
+	}
+
+	want := []string{"Slay the rats: 4/10", "Find the ring: 0/1"}
+	if got := record.Lines(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Lines() = %q, want %q", got, want)
+	}
+}
+
+func TestMatchWithoutFormat(t *testing.T) {
+	m := NewManager()
+	m.Add("task", `^Task: (\w+)`, "")
+
+	matches := m.Match("Task: gather herbs (2 left)")
+	if len(matches) != 1 || matches[0].Key != "gather" || matches[0].Text != "Task: gather" {
+		t.Errorf("Expected the matched text keyed by the first group, got %+v", matches)
+	}
+}
+
+func TestAddRejectsBadPattern(t *testing.T) {
+	m := NewManager()
+	if _, err := m.Add("quest", "Quest: (", ""); err == nil {
+		t.Error("Expected an invalid pattern to be rejected")
+	}
+	if _, err := m.Add("", "Quest", ""); err == nil {
+		t.Error("Expected an empty name to be rejected")
+	}
+}
+
+func TestRecordKeepsNewestEntries(t *testing.T) {
+	record := &Record{}
+	for i := 0; i <= MaxEntries; i++ {
+		record.Set(string(rune('A'+i)), "entry")
+	}
+	if len(record.Keys) != MaxEntries || len(record.Entries) != MaxEntries || record.Keys[0] != "B" {
+		t.Errorf("Expected the oldest entry dropped, got %d keys starting %q", len(record.Keys), record.Keys[0])
+	}
+}
+
+func TestPanelTitle(t *testing.T) {
+	for name, want := range map[string]string{"quest": "Quests", "tasks": "Tasks"} {
+		if got := PanelTitle(name); got != want {
+			t.Errorf("PanelTitle(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestPersistence(t *testing.T) {
+	capturesPath := filepath.Join(t.TempDir(), "captures.json")
+
+	m, _ := LoadFromPath(capturesPath)
+	m.Add("quest", `Quest: (.+) - (\d+)/(\d+)`, "<1>: <2>/<3>")
+	if err := m.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	loaded, err := LoadFromPath(capturesPath)
+	if err != nil {
+		t.Fatalf("LoadFromPath() error = %v", err)
+	}
+	if matches := loaded.Match("Quest: Slay the rats - 3/10"); len(matches) != 1 || matches[0].Text != "Slay the rats: 3/10" {
+		t.Errorf("Expected the loaded capture to match, got %+v", matches)
+	}
+}
@@ -0,0 +1,109 @@
+package client
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// Charset is the character encoding a MUD sends and expects. Old DikuMUDs
+// often use Latin-1, or CP437 for box-drawing characters.
+type Charset int
+
+const (
+	CharsetUTF8   Charset = iota // UTF-8 (the default)
+	CharsetLatin1                // ISO-8859-1
+	CharsetCP437                 // IBM PC code page 437
+)
+
+// cp437High maps CP437 bytes 0x80-0xFF to Unicode. Bytes below 0x80 are
+// taken as ASCII, since MUDs use them for text and control codes (ESC for
+// colors) rather than CP437's graphical glyphs.
+var cp437High = []rune("" +
+	"ÇüéâäàåçêëèïîìÄÅ" +
+	"ÉæÆôöòûùÿÖÜ¢£¥₧ƒ" +
+	"áíóúñÑªº¿⌐¬½¼¡«»" +
+	"░▒▓│┤╡╢╖╕╣║╗╝╜╛┐" +
+	"└┴┬├─┼╞╟╚╔╩╦╠═╬╧" +
+	"╨╤╥╙╘╒╓╫╪┘┌█▄▌▐▀" +
+	"αßΓπΣσµτΦΘΩδ∞φε∩" +
+	"≡±≥≤⌠⌡÷≈°∙·√ⁿ²■\u00a0")
+
+// cp437Encode is the reverse of cp437High
+var cp437Encode = func() map[rune]byte {
+	m := make(map[rune]byte, len(cp437High))
+	for i, r := range cp437High {
+		m[r] = byte(0x80 + i)
+	}
+	return m
+}()
+
+// ParseCharset returns the charset with the given name: utf8, latin1 or cp437
+func ParseCharset(name string) (Charset, error) {
+	switch strings.ToLower(name) {
+	case "utf8", "utf-8", "":
+		return CharsetUTF8, nil
+	case "latin1", "latin-1", "iso-8859-1", "iso8859-1":
+		return CharsetLatin1, nil
+	case "cp437", "ibm437":
+		return CharsetCP437, nil
+	}
+	return CharsetUTF8, fmt.Errorf("unknown charset %q (use utf8, latin1 or cp437)", name)
+}
+
+// String returns the charset's name as accepted by ParseCharset
+func (cs Charset) String() string {
+	switch cs {
+	case CharsetLatin1:
+		return "latin1"
+	case CharsetCP437:
+		return "cp437"
+	}
+	return "utf8"
+}
+
+// Decode converts text in the charset to UTF-8. UTF-8 is returned as is.
+func (cs Charset) Decode(data []byte) []byte {
+	if cs == CharsetUTF8 {
+		return data
+	}
+	result := make([]byte, 0, len(data))
+	for _, b := range data {
+		switch {
+		case b < 0x80:
+			result = append(result, b)
+		case cs == CharsetCP437:
+			result = utf8.AppendRune(result, cp437High[b-0x80])
+		default:
+			// Latin-1 bytes are the first 256 Unicode code points
+			result = utf8.AppendRune(result, rune(b))
+		}
+	}
+	return result
+}
+
+// Encode converts UTF-8 text to the charset. Characters the charset has no
+// byte for are sent as '?'.
+func (cs Charset) Encode(s string) []byte {
+	if cs == CharsetUTF8 {
+		return []byte(s)
+	}
+	result := make([]byte, 0, len(s))
+	for _, r := range s {
+		switch {
+		case r < 0x80:
+			result = append(result, byte(r))
+		case cs == CharsetCP437:
+			if b, ok := cp437Encode[r]; ok {
+				result = append(result, b)
+			} else {
+				result = append(result, '?')
+			}
+		case r <= 0xFF:
+			result = append(result, byte(r))
+		default:
+			result = append(result, '?')
+		}
+	}
+	return result
+}
@@ -0,0 +1,134 @@
+package client
+
+import (
+	"bufio"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestParseCharset(t *testing.T) {
+	tests := []struct {
+		name string
+		want Charset
+	}{
+		{"utf8", CharsetUTF8},
+		{"UTF-8", CharsetUTF8},
+		{"latin1", CharsetLatin1},
+		{"ISO-8859-1", CharsetLatin1},
+		{"cp437", CharsetCP437},
+	}
+	for _, tt := range tests {
+		got, err := ParseCharset(tt.name)
+		if err != nil || got != tt.want {
+			t.Errorf("ParseCharset(%q) = %v, %v; want %v", tt.name, got, err, tt.want)
+		}
+		if back, _ := ParseCharset(got.String()); back != got {
+			t.Errorf("%v doesn't round-trip through its name", got)
+		}
+	}
+	if _, err := ParseCharset("ebcdic"); err == nil {
+		t.Error("Expected an error for an unknown charset")
+	}
+}
+
+func TestCP437Table(t *testing.T) {
+	if len(cp437High) != 128 {
+		t.Fatalf("Expected 128 entries for bytes 0x80-0xFF, got %d", len(cp437High))
+	}
+	if len(cp437Encode) != 128 {
+		t.Errorf("Expected every CP437 character to be distinct, got %d", len(cp437Encode))
+	}
+}
+
+func TestDecodeCP437BoxDrawing(t *testing.T) {
+	// A small box as an old DikuMUD would draw it, with a colored title
+	box := []byte{
+		0xC9, 0xCD, 0xCD, 0xBB, '\r', '\n',
+		0xBA, 0x1B, '[', '1', 'm', 'M', 'a', 'p', 0x1B, '[', '0', 'm', 0xBA, '\r', '\n',
+		0xC8, 0xCD, 0xCD, 0xBC, '\r', '\n',
+		0xDA, 0xC4, 0xBF, ' ', 0xB0, 0xB1, 0xB2, 0xDB, ' ', 0xF8, 0x82,
+	}
+	want := "╔══╗\r\n║\x1b[1mMap\x1b[0m║\r\n╚══╝\r\n┌─┐ ░▒▓█ °é"
+
+	if got := string(CharsetCP437.Decode(box)); got != want {
+		t.Errorf("Decoded %q, want %q", got, want)
+	}
+	if got := CharsetCP437.Encode(want); string(got) != string(box) {
+		t.Errorf("Encoded back to % X, want % X", got, box)
+	}
+}
+
+func TestDecodeLatin1(t *testing.T) {
+	if got := string(CharsetLatin1.Decode([]byte("Caf\xe9 \xab\xbb \xff"))); got != "Café «» ÿ" {
+		t.Errorf("Decoded %q", got)
+	}
+	if got := CharsetLatin1.Encode("Café €5"); string(got) != "Caf\xe9 ?5" {
+		t.Errorf("Encoded %q, want unmappable characters as '?'", got)
+	}
+	if got := string(CharsetUTF8.Decode([]byte("Café"))); got != "Café" {
+		t.Errorf("UTF-8 should pass through, got %q", got)
+	}
+}
+
+func TestProcessTelnetDataDecodesCharset(t *testing.T) {
+	conn := &Connection{charset: CharsetCP437}
+
+	// A byte that starts a UTF-8 sequence is a whole character in CP437, so
+	// nothing is held back at the end of the buffer
+	if got := string(conn.processTelnetData([]byte{0xC4, 0xC4, IAC, GA, 0xC5})); got != "──┼" {
+		t.Errorf("Got %q", got)
+	}
+	if len(conn.telnetBuffer) != 0 {
+		t.Errorf("Expected nothing buffered, got % X", conn.telnetBuffer)
+	}
+
+	// An escaped IAC is the literal byte 0xFF, a no-break space in CP437
+	if got := string(conn.processTelnetData([]byte{'a', IAC, IAC, 'b'})); got != "a b" {
+		t.Errorf("Got %q", got)
+	}
+}
+
+func TestSendEncodesCharset(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	defer listener.Close()
+
+	accepted := make(chan net.Conn, 1)
+	go func() {
+		if conn, err := listener.Accept(); err == nil {
+			accepted <- conn
+		}
+	}()
+
+	conn, err := NewConnectionWithCharset("127.0.0.1", listener.Addr().(*net.TCPAddr).Port, nil, CharsetLatin1)
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer conn.Close()
+
+	server := <-accepted
+	defer server.Close()
+
+	conn.Send("say Café")
+	server.SetReadDeadline(time.Now().Add(2 * time.Second))
+	line, err := bufio.NewReader(server).ReadString('\n')
+	if err != nil {
+		t.Fatalf("Failed to read: %v", err)
+	}
+	if line != "say Caf\xe9\r\n" {
+		t.Errorf("Server got %q, want Latin-1 bytes", line)
+	}
+
+	server.Write([]byte("Ol\xe9!\r\n"))
+	select {
+	case got := <-conn.Receive():
+		if got.Text != "Olé!\n" {
+			t.Errorf("Received %q", got.Text)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Timed out waiting for output")
+	}
+}
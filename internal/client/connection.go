@@ -5,13 +5,14 @@ import (
 	"bytes"
 	"encoding/hex"
 	"fmt"
-	"io"
 	"net"
-	"os"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
 	"unicode/utf8"
+
+	"github.com/anicolao/dikuclient/internal/debuglog"
 )
 
 // Telnet IAC (Interpret As Command) constants
@@ -22,6 +23,7 @@ const (
 	DO   = 253 // 0xFD
 	DONT = 254 // 0xFE
 	GA   = 249 // 0xF9 - Go Ahead (marks end of prompt)
+	EOR  = 239 // 0xEF - End of Record (marks end of prompt once EOR is agreed)
 	SB   = 250 // 0xFA - Subnegotiation Begin
 	SE   = 240 // 0xF0 - Subnegotiation End
 )
@@ -29,23 +31,54 @@ const (
 // Telnet options
 const (
 	TELOPT_ECHO = 1
+	TELOPT_EOR  = 25
+	TELOPT_MSDP = 69
+	TELOPT_GMCP = 201
 )
 
+// Output is a chunk of text from the server. Prompt is set when the server
+// marked the end of the chunk as a prompt with telnet GA or EOR, so the
+// next output belongs on a new line.
+type Output struct {
+	Text   string
+	Prompt bool
+}
+
+// Subnegotiation is the data of an IAC SB <option> ... IAC SE sequence the
+// server sent, with doubled IAC bytes undone
+type Subnegotiation struct {
+	Option byte
+	Data   []byte
+}
+
 // Connection represents a connection to a MUD server
 type Connection struct {
-	conn         net.Conn
-	reader       *bufio.Reader
-	writer       *bufio.Writer
-	outChan      chan string
-	inChan       chan string
-	errChan      chan error
-	echoChan     chan bool // Sends echo suppression state changes
-	closeCh      chan struct{}
-	mu           sync.RWMutex
-	closed       bool
-	serverEcho   bool     // Whether server is echoing (false = password mode)
-	telnetBuffer []byte   // Buffer for incomplete telnet sequences
-	debugLog     *os.File // Optional debug log file for telnet/UTF-8 processing
+	conn          net.Conn
+	reader        *bufio.Reader
+	writer        *bufio.Writer
+	outChan       chan Output
+	inChan        chan string
+	rawChan       chan []byte // Raw bytes to write as is (telnet sequences)
+	errChan       chan error
+	echoChan      chan bool           // Sends echo suppression state changes
+	subnegChan    chan Subnegotiation // Sends MSDP subnegotiations
+	gmcpChan      chan GMCPMessage    // Sends GMCP messages
+	closeCh       chan struct{}
+	mu            sync.RWMutex
+	closed        bool
+	serverEcho    bool          // Whether the server echoes input (true = hidden input such as passwords)
+	gmcpAsked     bool          // IAC DO GMCP has been sent
+	gmcpOn        bool          // The server agreed to GMCP and the client has subscribed
+	telnetBuffer  []byte        // Buffer for incomplete telnet sequences
+	promptEnds    []int         // Where GA or EOR ended a prompt in the text processTelnetData last returned
+	recorder      *Recorder     // Captures raw server bytes while recording (nil = off)
+	charset       Charset       // Encoding the server sends and expects
+	lineEnding    LineEnding    // What ends each command sent
+	debugLog      *debuglog.Log // Optional debug log for telnet/UTF-8 processing (nil = off)
+	sendInterval  time.Duration // Minimum time between commands sent (0 = no limit)
+	rttMu         sync.Mutex    // Guards awaitingSince and latency, so writeLoop never needs mu
+	awaitingSince time.Time     // When the oldest unanswered command was written (zero = none)
+	latency       Latency       // Time from writing a command to the server's first reply
 }
 
 // NewConnection creates a new MUD connection
@@ -54,34 +87,49 @@ func NewConnection(host string, port int) (*Connection, error) {
 }
 
 // NewConnectionWithDebug creates a new MUD connection with optional debug logging
-func NewConnectionWithDebug(host string, port int, debugLog *os.File) (*Connection, error) {
-	address := fmt.Sprintf("%s:%d", host, port)
-	conn, err := net.Dial("tcp", address)
+func NewConnectionWithDebug(host string, port int, debugLog *debuglog.Log) (*Connection, error) {
+	return NewConnectionWithCharset(host, port, debugLog, CharsetUTF8)
+}
+
+// NewConnectionWithCharset creates a new MUD connection to a server that
+// uses the given charset, with optional debug logging
+func NewConnectionWithCharset(host string, port int, debugLog *debuglog.Log, charset Charset) (*Connection, error) {
+	address := net.JoinHostPort(host, strconv.Itoa(port))
+	conn, err := net.DialTimeout("tcp", address, dialTimeout)
 	if err != nil {
-		return nil, fmt.Errorf("failed to connect to %s: %w", address, err)
+		return nil, classifyDialError(err, address)
 	}
 
-	c := &Connection{
+	c := newConnection(conn, debugLog, charset)
+
+	if c.debugLog.Enabled(debuglog.Negotiation) {
+		fmt.Fprintf(c.debugLog, "[%s] === Connection established to %s ===\n\n", time.Now().Format("15:04:05.000"), address)
+	}
+
+	go c.readLoop()
+	go c.writeLoop()
+
+	return c, nil
+}
+
+// newConnection wraps conn without starting the read and write loops
+func newConnection(conn net.Conn, debugLog *debuglog.Log, charset Charset) *Connection {
+	return &Connection{
 		conn:       conn,
 		reader:     bufio.NewReader(conn),
 		writer:     bufio.NewWriter(conn),
-		outChan:    make(chan string, 100),
+		outChan:    make(chan Output, 100),
 		inChan:     make(chan string, 100),
+		rawChan:    make(chan []byte, 10),
 		errChan:    make(chan error, 10),
 		echoChan:   make(chan bool, 10),
+		subnegChan: make(chan Subnegotiation, 100),
+		gmcpChan:   make(chan GMCPMessage, 100),
 		closeCh:    make(chan struct{}),
-		serverEcho: true, // Assume server echoes initially
+		serverEcho: false, // Telnet starts with local echo until the server says WILL ECHO
 		debugLog:   debugLog,
+		charset:    charset,
 	}
-
-	if c.debugLog != nil {
-		fmt.Fprintf(c.debugLog, "[%s] === Connection established to %s ===\n\n", time.Now().Format("15:04:05.000"), address)
-	}
-
-	go c.readLoop()
-	go c.writeLoop()
-
-	return c, nil
 }
 
 // incompleteUTF8Tail returns the number of trailing bytes that form an incomplete UTF-8 sequence
@@ -149,18 +197,33 @@ func incompleteUTF8Tail(data []byte) int {
 	return 0
 }
 
+// hiddenWhileRedacting stands in the debug log for data from the server
+// while secrets are hidden: they can't be found in hex, nor at all when
+// split between two reads, each logged on its own
+const hiddenWhileRedacting = "(hidden while the log is redacted)"
+
+// debugHex returns data in hex for the debug log, or hiddenWhileRedacting
+func (c *Connection) debugHex(data []byte) string {
+	if c.debugLog.Redacting() {
+		return hiddenWhileRedacting
+	}
+	return hex.EncodeToString(data)
+}
+
 // processTelnetData strips telnet IAC sequences and handles negotiation
 // It properly handles telnet sequences that span buffer boundaries
 func (c *Connection) processTelnetData(data []byte) []byte {
-	if c.debugLog != nil {
+	if c.debugLog.Enabled(debuglog.All) {
 		fmt.Fprintf(c.debugLog, "[%s] === processTelnetData called ===\n", time.Now().Format("15:04:05.000"))
 		fmt.Fprintf(c.debugLog, "Input length: %d bytes\n", len(data))
-		fmt.Fprintf(c.debugLog, "Input hex: %s\n", hex.EncodeToString(data))
+		fmt.Fprintf(c.debugLog, "Input hex: %s\n", c.debugHex(data))
 		if len(c.telnetBuffer) > 0 {
-			fmt.Fprintf(c.debugLog, "Buffered from previous call: %d bytes: %s\n", len(c.telnetBuffer), hex.EncodeToString(c.telnetBuffer))
+			fmt.Fprintf(c.debugLog, "Buffered from previous call: %d bytes: %s\n", len(c.telnetBuffer), c.debugHex(c.telnetBuffer))
 		}
 	}
 
+	c.promptEnds = c.promptEnds[:0]
+
 	// Prepend any buffered incomplete sequence from previous call
 	if len(c.telnetBuffer) > 0 {
 		data = append(c.telnetBuffer, data...)
@@ -172,13 +235,13 @@ func (c *Connection) processTelnetData(data []byte) []byte {
 
 	for i < len(data) {
 		if data[i] == IAC {
-			if c.debugLog != nil {
+			if c.debugLog.Enabled(debuglog.All) {
 				fmt.Fprintf(c.debugLog, "Found IAC at position %d\n", i)
 			}
 			// Check if we have enough bytes for a complete sequence
 			if i+1 >= len(data) {
 				// Incomplete sequence - buffer it for next call
-				if c.debugLog != nil {
+				if c.debugLog.Enabled(debuglog.All) {
 					fmt.Fprintf(c.debugLog, "  Incomplete IAC at end of buffer, buffering %d bytes\n", len(data)-i)
 				}
 				c.telnetBuffer = append(c.telnetBuffer, data[i:]...)
@@ -187,13 +250,13 @@ func (c *Connection) processTelnetData(data []byte) []byte {
 
 			// Handle IAC sequences
 			cmd := data[i+1]
-			if c.debugLog != nil {
+			if c.debugLog.Enabled(debuglog.Negotiation) {
 				fmt.Fprintf(c.debugLog, "  IAC command: 0x%02X\n", cmd)
 			}
 			switch cmd {
 			case IAC:
 				// Escaped IAC (0xFF 0xFF) = literal 0xFF
-				if c.debugLog != nil {
+				if c.debugLog.Enabled(debuglog.All) {
 					fmt.Fprintf(c.debugLog, "  -> Escaped IAC, keeping literal 0xFF\n")
 				}
 				result = append(result, IAC)
@@ -202,7 +265,7 @@ func (c *Connection) processTelnetData(data []byte) []byte {
 				// Three-byte sequence: IAC WILL/WONT/DO/DONT <option>
 				if i+2 >= len(data) {
 					// Incomplete sequence - buffer it for next call
-					if c.debugLog != nil {
+					if c.debugLog.Enabled(debuglog.All) {
 						fmt.Fprintf(c.debugLog, "  Incomplete WILL/WONT/DO/DONT at end, buffering %d bytes\n", len(data)-i)
 					}
 					c.telnetBuffer = append(c.telnetBuffer, data[i:]...)
@@ -210,7 +273,7 @@ func (c *Connection) processTelnetData(data []byte) []byte {
 					i = len(data)
 				} else {
 					option := data[i+2]
-					if c.debugLog != nil {
+					if c.debugLog.Enabled(debuglog.Negotiation) {
 						fmt.Fprintf(c.debugLog, "  -> IAC %s option %d, stripping\n",
 							map[byte]string{WILL: "WILL", WONT: "WONT", DO: "DO", DONT: "DONT"}[cmd], option)
 					}
@@ -238,17 +301,29 @@ func (c *Connection) processTelnetData(data []byte) []byte {
 						}
 						c.mu.Unlock()
 					}
+					// Agree to EOR so servers that offer it mark their prompts
+					if option == TELOPT_EOR && cmd == WILL {
+						c.reply(EncodeNegotiation(DO, TELOPT_EOR))
+					}
+					// Agree to GMCP and subscribe to the packages the UI reads
+					if option == TELOPT_GMCP {
+						c.negotiateGMCP(cmd)
+					}
 					i += 3
 				}
-			case GA:
-				// Go Ahead - marks end of prompt, just skip it
-				if c.debugLog != nil {
-					fmt.Fprintf(c.debugLog, "  -> IAC GA (Go Ahead), stripping\n")
+			case GA, EOR:
+				// Go Ahead or End of Record - marks the end of a prompt
+				if c.debugLog.Enabled(debuglog.Negotiation) {
+					fmt.Fprintf(c.debugLog, "  -> IAC %s, prompt ends at byte %d\n",
+						map[byte]string{GA: "GA", EOR: "EOR"}[cmd], len(result))
+				}
+				if n := len(c.promptEnds); n == 0 || c.promptEnds[n-1] != len(result) {
+					c.promptEnds = append(c.promptEnds, len(result))
 				}
 				i += 2
 			case SB:
 				// Subnegotiation - skip until SE
-				if c.debugLog != nil {
+				if c.debugLog.Enabled(debuglog.Negotiation) {
 					fmt.Fprintf(c.debugLog, "  -> IAC SB (Subnegotiation), searching for IAC SE...\n")
 				}
 				sbStart := i
@@ -259,7 +334,7 @@ func (c *Connection) processTelnetData(data []byte) []byte {
 					if data[i] == IAC {
 						if i+1 >= len(data) {
 							// Incomplete - buffer from start of SB and exit
-							if c.debugLog != nil {
+							if c.debugLog.Enabled(debuglog.All) {
 								fmt.Fprintf(c.debugLog, "  Incomplete subnegotiation at end, buffering %d bytes\n", len(data)-sbStart)
 							}
 							c.telnetBuffer = append(c.telnetBuffer, data[sbStart:]...)
@@ -267,9 +342,10 @@ func (c *Connection) processTelnetData(data []byte) []byte {
 							break
 						}
 						if data[i+1] == SE {
-							if c.debugLog != nil {
+							if c.debugLog.Enabled(debuglog.Negotiation) {
 								fmt.Fprintf(c.debugLog, "  Found IAC SE, stripping entire subnegotiation\n")
 							}
+							c.handleSubnegotiation(data[sbStart+2 : i])
 							i += 2 // Skip IAC SE
 							foundSE = true
 							break
@@ -284,14 +360,14 @@ func (c *Connection) processTelnetData(data []byte) []byte {
 				// If we didn't find SE and didn't buffer, we hit end of data
 				if !foundSE && i >= len(data) && len(c.telnetBuffer) == 0 {
 					// Buffer the entire incomplete subnegotiation
-					if c.debugLog != nil {
+					if c.debugLog.Enabled(debuglog.All) {
 						fmt.Fprintf(c.debugLog, "  Subnegotiation incomplete (no SE found), buffering %d bytes\n", len(data)-sbStart)
 					}
 					c.telnetBuffer = append(c.telnetBuffer, data[sbStart:]...)
 				}
 			default:
 				// Unknown two-byte sequence
-				if c.debugLog != nil {
+				if c.debugLog.Enabled(debuglog.Negotiation) {
 					fmt.Fprintf(c.debugLog, "  -> Unknown IAC command 0x%02X, stripping 2 bytes\n", cmd)
 				}
 				i += 2
@@ -303,35 +379,108 @@ func (c *Connection) processTelnetData(data []byte) []byte {
 		}
 	}
 
-	// Check if result ends with incomplete UTF-8 sequence
-	incompleteLen := incompleteUTF8Tail(result)
-	if incompleteLen > 0 {
+	// Single-byte charsets decode byte by byte, so only UTF-8 can be split
+	// mid-character
+	if c.charset != CharsetUTF8 {
+		result = c.decodeMarkingPrompts(result)
+	} else if incompleteLen := incompleteUTF8Tail(result); incompleteLen > 0 {
 		// Buffer the incomplete UTF-8 bytes for next call
 		splitPoint := len(result) - incompleteLen
-		if c.debugLog != nil {
+		if c.debugLog.Enabled(debuglog.All) {
 			fmt.Fprintf(c.debugLog, "Incomplete UTF-8 at end: %d bytes: %s\n",
-				incompleteLen, hex.EncodeToString(result[splitPoint:]))
+				incompleteLen, c.debugHex(result[splitPoint:]))
 		}
 		c.telnetBuffer = append(c.telnetBuffer, result[splitPoint:]...)
 		result = result[:splitPoint]
+		for k, end := range c.promptEnds {
+			c.promptEnds[k] = min(end, splitPoint)
+		}
 	}
 
-	if c.debugLog != nil {
+	if c.debugLog.Enabled(debuglog.All) {
 		fmt.Fprintf(c.debugLog, "Output length: %d bytes\n", len(result))
-		fmt.Fprintf(c.debugLog, "Output hex: %s\n", hex.EncodeToString(result))
+		fmt.Fprintf(c.debugLog, "Output hex: %s\n", c.debugHex(result))
 		if len(c.telnetBuffer) > 0 {
 			fmt.Fprintf(c.debugLog, "Buffered for next call: %d bytes: %s\n",
-				len(c.telnetBuffer), hex.EncodeToString(c.telnetBuffer))
+				len(c.telnetBuffer), c.debugHex(c.telnetBuffer))
 		}
 		// Try to show as string (may have invalid UTF-8, but useful for debugging)
-		fmt.Fprintf(c.debugLog, "Output string (may contain invalid UTF-8): %q\n", string(result))
+		if c.debugLog.Redacting() {
+			fmt.Fprintf(c.debugLog, "Output string: %s\n", hiddenWhileRedacting)
+		} else {
+			fmt.Fprintf(c.debugLog, "Output string (may contain invalid UTF-8): %q\n", string(result))
+		}
 		fmt.Fprintf(c.debugLog, "\n")
-		c.debugLog.Sync()
 	}
 
 	return result
 }
 
+// decodeMarkingPrompts decodes text in a single-byte charset, moving the
+// prompt ends to match the decoded text
+func (c *Connection) decodeMarkingPrompts(text []byte) []byte {
+	var decoded []byte
+	start := 0
+	for k, end := range c.promptEnds {
+		decoded = append(decoded, c.charset.Decode(text[start:end])...)
+		c.promptEnds[k] = len(decoded)
+		start = end
+	}
+	return append(decoded, c.charset.Decode(text[start:])...)
+}
+
+// reply queues a telnet response without blocking the read loop
+func (c *Connection) reply(data []byte) {
+	select {
+	case c.rawChan <- data:
+	default:
+	}
+}
+
+// sendOutput passes processed text to the UI, split where the server marked
+// the end of a prompt so each prompt arrives on its own
+func (c *Connection) sendOutput(cleaned []byte) {
+	start := 0
+	for _, end := range c.promptEnds {
+		c.sendText(cleaned[start:end], true)
+		start = end
+	}
+	c.sendText(cleaned[start:], false)
+}
+
+// sendText strips carriage returns and sends what's left, if anything
+func (c *Connection) sendText(text []byte, prompt bool) {
+	if s := strings.ReplaceAll(string(text), "\r", ""); s != "" {
+		c.outChan <- Output{Text: s, Prompt: prompt}
+	}
+}
+
+// handleSubnegotiation passes on the GMCP messages and MSDP data the UI
+// reads vitals from; seq is the option byte and data between IAC SB and IAC SE
+func (c *Connection) handleSubnegotiation(seq []byte) {
+	if len(seq) == 0 || (seq[0] != TELOPT_GMCP && seq[0] != TELOPT_MSDP) {
+		return
+	}
+	data := make([]byte, 0, len(seq)-1)
+	for i := 1; i < len(seq); i++ {
+		data = append(data, seq[i])
+		if seq[i] == IAC && i+1 < len(seq) && seq[i+1] == IAC {
+			i++
+		}
+	}
+	if seq[0] == TELOPT_GMCP {
+		select {
+		case c.gmcpChan <- ParseGMCPMessage(data):
+		default:
+		}
+		return
+	}
+	select {
+	case c.subnegChan <- Subnegotiation{Option: seq[0], Data: data}:
+	default:
+	}
+}
+
 // readLoop continuously reads from the MUD server
 func (c *Connection) readLoop() {
 	defer func() {
@@ -357,59 +506,71 @@ func (c *Connection) readLoop() {
 						data := accumulated.Bytes()
 						accumulated.Reset()
 						// Process telnet sequences
-						cleaned := c.processTelnetData(data)
-						// Strip \r characters
-						dataStr := strings.ReplaceAll(string(cleaned), "\r", "")
-						if dataStr != "" {
-							c.outChan <- dataStr
-						}
+						c.sendOutput(c.processTelnetData(data))
 					}
 					continue
 				}
 				// Send error to error channel (including EOF) so TUI can detect connection closure
-				if err == io.EOF {
-					c.errChan <- fmt.Errorf("connection closed by remote host")
-				} else {
-					c.errChan <- fmt.Errorf("read error: %w", err)
-				}
+				c.errChan <- closedError(err, c.conn.RemoteAddr().String())
 				return
 			}
 
 			if n > 0 {
+				c.recordReply(time.Now())
+				c.recordChunk(buffer[:n], time.Now())
 				accumulated.Write(buffer[:n])
 
-				// Check if we have complete lines
+				// Check if we have complete lines or a marked prompt
 				data := accumulated.Bytes()
-				dataStr := string(data)
-				if strings.Contains(dataStr, "\n") {
-					// Send complete lines immediately
+				if bytes.Contains(data, []byte("\n")) || bytes.Contains(data, []byte{IAC, GA}) || bytes.Contains(data, []byte{IAC, EOR}) {
+					// Send them immediately
 					accumulated.Reset()
 					// Process telnet sequences
-					cleaned := c.processTelnetData(data)
-					// Strip \r characters
-					cleanedStr := strings.ReplaceAll(string(cleaned), "\r", "")
-					if cleanedStr != "" {
-						c.outChan <- cleanedStr
-					}
+					c.sendOutput(c.processTelnetData(data))
 				}
 			}
 		}
 	}
 }
 
-// writeLoop continuously writes to the MUD server
+// writeLoop continuously writes to the MUD server. Commands wait in its
+// own queue for the send interval, so inChan keeps draining and Send
+// doesn't block while a long burst is spaced out.
 func (c *Connection) writeLoop() {
 	defer func() {
 		c.Close()
 	}()
 
+	var lastWrite time.Time
+	var pending []string     // Commands waiting for the send interval
+	var due <-chan time.Time // Fires when the next pending command may be written
 	for {
+		if len(pending) > 0 && due == nil {
+			if wait := c.SendInterval() - time.Since(lastWrite); wait > 0 {
+				due = time.After(wait)
+			} else {
+				lastWrite = time.Now()
+				if err := c.writeCommand(pending[0], lastWrite); err != nil {
+					c.errChan <- err
+					return
+				}
+				pending = pending[1:]
+				continue
+			}
+		}
+
 		select {
 		case <-c.closeCh:
 			return
 		case msg := <-c.inChan:
-			_, err := c.writer.WriteString(msg + "\r\n")
-			if err != nil {
+			pending = append(pending, msg)
+		case <-due:
+			due = nil
+		case data := <-c.rawChan:
+			if c.debugLog.Enabled(debuglog.Negotiation) {
+				fmt.Fprintf(c.debugLog, "[%s] === Sent raw bytes ===\nHex: %s\n\n", time.Now().Format("15:04:05.000"), c.debugHex(data))
+			}
+			if _, err := c.writer.Write(data); err != nil {
 				c.errChan <- fmt.Errorf("write error: %w", err)
 				return
 			}
@@ -421,18 +582,102 @@ func (c *Connection) writeLoop() {
 	}
 }
 
-// Send sends a command to the MUD server
+// writeCommand writes one command with its line ending and starts timing
+// the round trip
+func (c *Connection) writeCommand(msg string, now time.Time) error {
+	if _, err := c.writer.Write(c.charset.Encode(msg + c.LineEnding().Terminator())); err != nil {
+		return fmt.Errorf("write error: %w", err)
+	}
+	if err := c.writer.Flush(); err != nil {
+		return fmt.Errorf("flush error: %w", err)
+	}
+	c.recordSent(now)
+	return nil
+}
+
+// Send sends a command to the MUD server. Throttled commands are held by
+// writeLoop, so Send returns at once rather than waiting for the interval.
 func (c *Connection) Send(msg string) {
+	if c.IsClosed() {
+		return
+	}
+	select {
+	case c.inChan <- msg:
+	case <-c.closeCh:
+	}
+}
+
+// SendRaw writes bytes to the server exactly as given, without a line ending
+// or throttling. It's meant for telnet negotiation.
+func (c *Connection) SendRaw(data []byte) {
+	if c.IsClosed() {
+		return
+	}
+	select {
+	case c.rawChan <- data:
+	case <-c.closeCh:
+	}
+}
+
+// SetSendInterval sets the minimum time between commands sent to the server,
+// to stay under MUD flood protection
+func (c *Connection) SetSendInterval(interval time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.sendInterval = interval
+}
+
+// SetLineEnding sets what ends each command sent to the server
+func (c *Connection) SetLineEnding(lineEnding LineEnding) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.lineEnding = lineEnding
+}
+
+// LineEnding returns what ends each command sent to the server
+func (c *Connection) LineEnding() LineEnding {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.lineEnding
+}
+
+// SendInterval returns the minimum time between commands sent to the server
+func (c *Connection) SendInterval() time.Duration {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
+	return c.sendInterval
+}
+
+// recordSent starts timing a round trip, unless an earlier command is still
+// waiting for its reply
+func (c *Connection) recordSent(now time.Time) {
+	c.rttMu.Lock()
+	defer c.rttMu.Unlock()
+	if c.awaitingSince.IsZero() {
+		c.awaitingSince = now
+	}
+}
 
-	if !c.closed {
-		c.inChan <- msg
+// recordReply finishes timing a round trip when data arrives
+func (c *Connection) recordReply(now time.Time) {
+	c.rttMu.Lock()
+	defer c.rttMu.Unlock()
+	if !c.awaitingSince.IsZero() {
+		c.latency.Add(now.Sub(c.awaitingSince))
+		c.awaitingSince = time.Time{}
 	}
 }
 
+// RTT returns the smoothed time between sending a command and the server's
+// first reply, and false until a command has been answered
+func (c *Connection) RTT() (time.Duration, bool) {
+	c.rttMu.Lock()
+	defer c.rttMu.Unlock()
+	return c.latency.Average()
+}
+
 // Receive returns the output channel for reading server messages
-func (c *Connection) Receive() <-chan string {
+func (c *Connection) Receive() <-chan Output {
 	return c.outChan
 }
 
@@ -441,6 +686,12 @@ func (c *Connection) EchoState() <-chan bool {
 	return c.echoChan
 }
 
+// Subnegotiations returns the channel of MSDP data from the server (GMCP
+// messages arrive on GMCP)
+func (c *Connection) Subnegotiations() <-chan Subnegotiation {
+	return c.subnegChan
+}
+
 // Errors returns the error channel
 func (c *Connection) Errors() <-chan error {
 	return c.errChan
@@ -458,6 +709,11 @@ func (c *Connection) Close() error {
 	c.closed = true
 	close(c.closeCh)
 
+	if c.recorder != nil {
+		c.recorder.Close()
+		c.recorder = nil
+	}
+
 	if c.conn != nil {
 		return c.conn.Close()
 	}
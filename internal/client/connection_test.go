@@ -1,8 +1,18 @@
 package client
 
 import (
+	"bufio"
 	"bytes"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/anicolao/dikuclient/internal/debuglog"
+	"github.com/anicolao/dikuclient/internal/redact"
 )
 
 func TestProcessTelnetData_CompleteSsequences(t *testing.T) {
@@ -278,3 +288,337 @@ func TestProcessTelnetData_UTF8Boundaries(t *testing.T) {
 		})
 	}
 }
+
+func TestSendIntervalSpacesCommands(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	defer listener.Close()
+
+	accepted := make(chan net.Conn, 1)
+	go func() {
+		if conn, err := listener.Accept(); err == nil {
+			accepted <- conn
+		}
+	}()
+
+	conn, err := NewConnection("127.0.0.1", listener.Addr().(*net.TCPAddr).Port)
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer conn.Close()
+
+	server := <-accepted
+	defer server.Close()
+	reader := bufio.NewReader(server)
+
+	interval := 50 * time.Millisecond
+	conn.SetSendInterval(interval)
+
+	// A burst of commands is queued, not dropped
+	commands := []string{"n", "e", "s", "w"}
+	for _, command := range commands {
+		conn.Send(command)
+	}
+
+	var times []time.Time
+	for _, expected := range commands {
+		server.SetReadDeadline(time.Now().Add(2 * time.Second))
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			t.Fatalf("Failed to read %q: %v", expected, err)
+		}
+		if line != expected+"\r\n" {
+			t.Errorf("Expected %q, got %q", expected, line)
+		}
+		times = append(times, time.Now())
+	}
+
+	for i := 1; i < len(times); i++ {
+		// Allow for timer granularity when the reads are timestamped
+		if gap := times[i].Sub(times[i-1]); gap < interval-10*time.Millisecond {
+			t.Errorf("Command %d sent %v after the previous one, want at least %v", i, gap, interval)
+		}
+	}
+}
+
+// TestSendQueueLongerThanBuffer tests that more commands than inChan holds
+// can be queued with a send interval without Send and writeLoop waiting on
+// each other
+func TestSendQueueLongerThanBuffer(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	defer listener.Close()
+
+	accepted := make(chan net.Conn, 1)
+	go func() {
+		if conn, err := listener.Accept(); err == nil {
+			accepted <- conn
+		}
+	}()
+
+	conn, err := NewConnection("127.0.0.1", listener.Addr().(*net.TCPAddr).Port)
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer conn.Close()
+
+	server := <-accepted
+	defer server.Close()
+	reader := bufio.NewReader(server)
+
+	conn.SetSendInterval(time.Millisecond)
+	const count = 250
+	go func() {
+		for i := 0; i < count; i++ {
+			conn.Send(fmt.Sprintf("say %d", i))
+		}
+	}()
+
+	for i := 0; i < count; i++ {
+		server.SetReadDeadline(time.Now().Add(5 * time.Second))
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			t.Fatalf("Failed to read command %d: %v", i, err)
+		}
+		if expected := fmt.Sprintf("say %d\r\n", i); line != expected {
+			t.Fatalf("Expected %q, got %q", expected, line)
+		}
+		if i%50 == 0 {
+			// Replies time the round trip while commands are still queued
+			server.Write([]byte("Ok.\n"))
+		}
+	}
+}
+
+// TestSendDoesNotWaitForInterval tests that a long throttled burst is
+// queued at once, so the caller (the UI) isn't held up while it drains
+func TestSendDoesNotWaitForInterval(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	defer listener.Close()
+
+	accepted := make(chan net.Conn, 1)
+	go func() {
+		if conn, err := listener.Accept(); err == nil {
+			accepted <- conn
+		}
+	}()
+
+	conn, err := NewConnection("127.0.0.1", listener.Addr().(*net.TCPAddr).Port)
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer conn.Close()
+
+	server := <-accepted
+	defer server.Close()
+	reader := bufio.NewReader(server)
+
+	// 300 commands at 20ms apart take 6 seconds to send
+	conn.SetSendInterval(20 * time.Millisecond)
+	start := time.Now()
+	for i := 0; i < 300; i++ {
+		conn.Send(fmt.Sprintf("say %d", i))
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Expected Send to return at once, took %v", elapsed)
+	}
+
+	for i := 0; i < 3; i++ {
+		server.SetReadDeadline(time.Now().Add(2 * time.Second))
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			t.Fatalf("Failed to read command %d: %v", i, err)
+		}
+		if expected := fmt.Sprintf("say %d\r\n", i); line != expected {
+			t.Errorf("Expected %q, got %q", expected, line)
+		}
+	}
+}
+
+func TestEchoStateFollowsToggles(t *testing.T) {
+	conn := &Connection{echoChan: make(chan bool, 10)}
+
+	// Input is shown until the server says it will echo, and each change is
+	// reported once, however often the server repeats itself
+	steps := []struct {
+		cmd  byte
+		want []bool
+	}{
+		{WONT, nil},
+		{WILL, []bool{true}},
+		{WILL, nil},
+		{WONT, []bool{false}},
+		{WILL, []bool{true}}, // Mid-session, e.g. choosing a new password
+		{WONT, []bool{false}},
+	}
+	for i, step := range steps {
+		conn.processTelnetData([]byte{IAC, step.cmd, TELOPT_ECHO})
+		var got []bool
+		for len(conn.echoChan) > 0 {
+			got = append(got, <-conn.echoChan)
+		}
+		if len(got) != len(step.want) || (len(got) == 1 && got[0] != step.want[0]) {
+			t.Errorf("Step %d: echo changes %v, want %v", i+1, got, step.want)
+		}
+	}
+}
+
+func TestSubnegotiationsPassGMCPAndMSDP(t *testing.T) {
+	conn := &Connection{subnegChan: make(chan Subnegotiation, 10), gmcpChan: make(chan GMCPMessage, 10)}
+
+	// A GMCP message split across reads, an MSDP variable with an escaped
+	// IAC, and a subnegotiation for an option the UI doesn't read
+	gmcp := append([]byte{'A', IAC, SB, TELOPT_GMCP}, `Char.Vitals {"hp": 50}`...)
+	chunks := [][]byte{
+		gmcp[:10],
+		append(gmcp[10:], IAC, SE, 'B'),
+		{IAC, SB, TELOPT_MSDP, 1, 'X', 2, IAC, IAC, IAC, SE},
+		{IAC, SB, 24, 0, 'x', IAC, SE},
+	}
+	var text []byte
+	for _, chunk := range chunks {
+		text = append(text, conn.processTelnetData(chunk)...)
+	}
+	if string(text) != "AB" {
+		t.Errorf("Expected subnegotiations stripped from the text, got %q", text)
+	}
+
+	if len(conn.gmcpChan) != 1 {
+		t.Fatalf("Expected 1 GMCP message, got %d", len(conn.gmcpChan))
+	}
+	if got := <-conn.gmcpChan; got.Package != "Char.Vitals" || string(got.JSON) != `{"hp": 50}` {
+		t.Errorf("Got GMCP message %s %s, want Char.Vitals {\"hp\": 50}", got.Package, got.JSON)
+	}
+
+	want := []Subnegotiation{
+		{TELOPT_MSDP, []byte{1, 'X', 2, IAC}},
+	}
+	if len(conn.subnegChan) != len(want) {
+		t.Fatalf("Expected %d subnegotiations, got %d", len(want), len(conn.subnegChan))
+	}
+	for _, w := range want {
+		got := <-conn.subnegChan
+		if got.Option != w.Option || !bytes.Equal(got.Data, w.Data) {
+			t.Errorf("Got subnegotiation %d %q, want %d %q", got.Option, got.Data, w.Option, w.Data)
+		}
+	}
+}
+
+func TestPromptMarkedByGAOrEOR(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	defer listener.Close()
+
+	accepted := make(chan net.Conn, 1)
+	go func() {
+		if conn, err := listener.Accept(); err == nil {
+			accepted <- conn
+		}
+	}()
+
+	conn, err := NewConnection("127.0.0.1", listener.Addr().(*net.TCPAddr).Port)
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer conn.Close()
+
+	server := <-accepted
+	defer server.Close()
+
+	receive := func() Output {
+		t.Helper()
+		select {
+		case out := <-conn.Receive():
+			return out
+		case <-time.After(2 * time.Second):
+			t.Fatal("Timed out waiting for output")
+		}
+		return Output{}
+	}
+
+	// A prompt without a newline is sent on at once, apart from what follows
+	server.Write(append([]byte("A dark room.\r\n<100hp> "), IAC, GA))
+	if out := receive(); out != (Output{"A dark room.\n<100hp> ", true}) {
+		t.Errorf("Got %+v, want the prompt marked", out)
+	}
+	server.Write(append([]byte("You are hungry.\r\n<99hp> "), append([]byte{IAC, GA}, "Dinner time.\r\n"...)...))
+	if out := receive(); out != (Output{"You are hungry.\n<99hp> ", true}) {
+		t.Errorf("Got %+v, want output split after the prompt", out)
+	}
+	if out := receive(); out != (Output{"Dinner time.\n", false}) {
+		t.Errorf("Got %+v, want the rest unmarked", out)
+	}
+
+	// The client agrees to EOR, which then marks prompts the same way
+	server.Write([]byte{IAC, WILL, TELOPT_EOR})
+	reply := make([]byte, 3)
+	server.SetReadDeadline(time.Now().Add(2 * time.Second))
+	if _, err := io.ReadFull(server, reply); err != nil || !bytes.Equal(reply, []byte{IAC, DO, TELOPT_EOR}) {
+		t.Fatalf("Expected IAC DO EOR in reply, got %v (%v)", reply, err)
+	}
+	server.Write(append([]byte("Password: "), IAC, EOR))
+	if out := receive(); out != (Output{"Password: ", true}) {
+		t.Errorf("Got %+v, want the EOR prompt marked", out)
+	}
+}
+
+func TestPromptEndsFollowDecoding(t *testing.T) {
+	conn := &Connection{charset: CharsetLatin1}
+
+	// é is one byte in Latin-1 but two once decoded, so the end moves with it
+	text := conn.processTelnetData(append([]byte("Caf\xe9> "), IAC, GA, 'x'))
+	if string(text) != "Café> x" {
+		t.Fatalf("Got %q", text)
+	}
+	if len(conn.promptEnds) != 1 || string(text[:conn.promptEnds[0]]) != "Café> " {
+		t.Errorf("Prompt ends %v, want after %q", conn.promptEnds, "Café> ")
+	}
+
+	// Ends are found afresh for each buffer
+	conn.processTelnetData([]byte("more\r\n"))
+	if len(conn.promptEnds) != 0 {
+		t.Errorf("Expected no prompt ends left over, got %v", conn.promptEnds)
+	}
+}
+
+// TestDebugLogHidesDataWhileRedacting verifies the debug log at level all
+// leaves the server's data out while secrets are hidden, so a login name
+// split between two reads isn't logged in hex or as text
+func TestDebugLogHidesDataWhileRedacting(t *testing.T) {
+	var out bytes.Buffer
+	log := debuglog.New(&out, 0, 0)
+	log.SetRedactor(redact.New([]string{"Gandalf"}))
+	conn := &Connection{debugLog: log}
+
+	conn.processTelnetData([]byte("Welcome, Gan"))
+	conn.processTelnetData([]byte("dalf!\r\n"))
+	log.Flush()
+
+	got := out.String()
+	for _, leak := range []string{"Gan", "dalf", hex.EncodeToString([]byte("Gan")), hex.EncodeToString([]byte("dalf"))} {
+		if strings.Contains(got, leak) {
+			t.Errorf("Expected %q kept out of the log, got:\n%s", leak, got)
+		}
+	}
+	if !strings.Contains(got, "Input hex: (hidden while the log is redacted)") {
+		t.Errorf("Expected the hex dump marked hidden, got:\n%s", got)
+	}
+
+	// Without secrets the data is dumped as before
+	out.Reset()
+	log.SetRedactor(nil)
+	conn.processTelnetData([]byte("Welcome"))
+	log.Flush()
+	if !strings.Contains(out.String(), "Input hex: "+hex.EncodeToString([]byte("Welcome"))) {
+		t.Errorf("Expected the hex dump without redaction, got:\n%s", out.String())
+	}
+}
@@ -0,0 +1,112 @@
+package client
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"syscall"
+	"time"
+)
+
+// dialTimeout is how long to wait for the server to accept the connection
+const dialTimeout = 15 * time.Second
+
+// ErrorKind says why a connection failed, so the player can be told what to
+// do about it
+type ErrorKind int
+
+const (
+	ErrorOther   ErrorKind = iota // Not one of the failures below
+	ErrorDNS                      // The host name could not be resolved
+	ErrorRefused                  // Nothing is listening on the port
+	ErrorTimeout                  // The server didn't answer in time
+	ErrorTLS                      // The TLS handshake failed
+	ErrorClosed                   // The server closed the connection
+)
+
+// ConnectionError is a failure to connect to, or stay connected to, a MUD
+type ConnectionError struct {
+	Kind    ErrorKind
+	Address string // host:port of the server
+	Err     error  // The underlying error
+}
+
+// Error keeps the wording of the underlying error, for logs
+func (e *ConnectionError) Error() string {
+	if e.Kind == ErrorClosed {
+		if e.Err == nil || errors.Is(e.Err, io.EOF) {
+			return "connection closed by remote host"
+		}
+		return fmt.Sprintf("read error: %v", e.Err)
+	}
+	return fmt.Sprintf("failed to connect to %s: %v", e.Address, e.Err)
+}
+
+// Unwrap returns the underlying error
+func (e *ConnectionError) Unwrap() error {
+	return e.Err
+}
+
+// Friendly describes the failure in words the player can act on
+func (e *ConnectionError) Friendly() string {
+	host, _, err := net.SplitHostPort(e.Address)
+	if err != nil {
+		host = e.Address
+	}
+
+	switch e.Kind {
+	case ErrorDNS:
+		return fmt.Sprintf("Could not resolve host %s — check the hostname", host)
+	case ErrorRefused:
+		return fmt.Sprintf("Connection to %s was refused — the MUD may be down, or check the port", e.Address)
+	case ErrorTimeout:
+		return fmt.Sprintf("Timed out connecting to %s — the server may be down, or check your network", e.Address)
+	case ErrorTLS:
+		return fmt.Sprintf("Secure connection to %s failed — the server may not use TLS on this port", e.Address)
+	case ErrorClosed:
+		return fmt.Sprintf("%s closed the connection", e.Address)
+	}
+	return fmt.Sprintf("Could not connect to %s: %v", e.Address, e.Err)
+}
+
+// Recoverable reports whether trying again later may work without changing
+// anything, as when the MUD is down for a reboot
+func (e *ConnectionError) Recoverable() bool {
+	return e.Kind == ErrorRefused || e.Kind == ErrorTimeout
+}
+
+// classifyDialError wraps an error from connecting to address
+func classifyDialError(err error, address string) *ConnectionError {
+	return &ConnectionError{Kind: dialErrorKind(err), Address: address, Err: err}
+}
+
+// dialErrorKind finds the kind of a connection failure
+func dialErrorKind(err error) ErrorKind {
+	var dnsErr *net.DNSError
+	var recordErr tls.RecordHeaderError
+	var alertErr tls.AlertError
+	var verifyErr *tls.CertificateVerificationError
+	var netErr net.Error
+
+	switch {
+	case errors.As(err, &dnsErr):
+		return ErrorDNS
+	case errors.Is(err, syscall.ECONNREFUSED):
+		return ErrorRefused
+	case errors.As(err, &recordErr), errors.As(err, &alertErr), errors.As(err, &verifyErr):
+		return ErrorTLS
+	case errors.Is(err, os.ErrDeadlineExceeded), errors.As(err, &netErr) && netErr.Timeout():
+		return ErrorTimeout
+	case errors.Is(err, io.EOF), errors.Is(err, syscall.ECONNRESET), errors.Is(err, syscall.EPIPE):
+		return ErrorClosed
+	}
+	return ErrorOther
+}
+
+// closedError wraps an error that ended an open connection
+func closedError(err error, address string) *ConnectionError {
+	return &ConnectionError{Kind: ErrorClosed, Address: address, Err: err}
+}
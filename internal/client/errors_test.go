@@ -0,0 +1,109 @@
+package client
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strings"
+	"syscall"
+	"testing"
+)
+
+func TestConnectionErrorFriendly(t *testing.T) {
+	address := "mud.example.com:4000"
+	tests := []struct {
+		name        string
+		err         error
+		kind        ErrorKind
+		friendly    string
+		recoverable bool
+	}{
+		{
+			name:     "dns",
+			err:      &net.OpError{Op: "dial", Net: "tcp", Err: &net.DNSError{Name: "mud.example.com", Err: "no such host", IsNotFound: true}},
+			kind:     ErrorDNS,
+			friendly: "Could not resolve host mud.example.com — check the hostname",
+		},
+		{
+			name:        "refused",
+			err:         &net.OpError{Op: "dial", Net: "tcp", Err: os.NewSyscallError("connect", syscall.ECONNREFUSED)},
+			kind:        ErrorRefused,
+			friendly:    "Connection to mud.example.com:4000 was refused",
+			recoverable: true,
+		},
+		{
+			name:        "timeout",
+			err:         &net.OpError{Op: "dial", Net: "tcp", Err: os.ErrDeadlineExceeded},
+			kind:        ErrorTimeout,
+			friendly:    "Timed out connecting to mud.example.com:4000",
+			recoverable: true,
+		},
+		{
+			name:     "tls",
+			err:      fmt.Errorf("handshake: %w", tls.RecordHeaderError{Msg: "first record does not look like a TLS handshake"}),
+			kind:     ErrorTLS,
+			friendly: "Secure connection to mud.example.com:4000 failed",
+		},
+		{
+			name:     "closed",
+			err:      io.EOF,
+			kind:     ErrorClosed,
+			friendly: "mud.example.com:4000 closed the connection",
+		},
+		{
+			name:     "other",
+			err:      errors.New("something odd"),
+			kind:     ErrorOther,
+			friendly: "Could not connect to mud.example.com:4000: something odd",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			connErr := classifyDialError(tt.err, address)
+			if connErr.Kind != tt.kind {
+				t.Errorf("Expected kind %d, got %d", tt.kind, connErr.Kind)
+			}
+			if !strings.HasPrefix(connErr.Friendly(), tt.friendly) {
+				t.Errorf("Expected message starting %q, got %q", tt.friendly, connErr.Friendly())
+			}
+			if connErr.Recoverable() != tt.recoverable {
+				t.Errorf("Expected recoverable %v", tt.recoverable)
+			}
+			if !errors.Is(connErr, tt.err) {
+				t.Error("Expected the underlying error to be kept")
+			}
+		})
+	}
+}
+
+func TestConnectionRefusedIsClassified(t *testing.T) {
+	// A port nothing listens on any more
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	port := listener.Addr().(*net.TCPAddr).Port
+	listener.Close()
+
+	_, err = NewConnection("127.0.0.1", port)
+	var connErr *ConnectionError
+	if !errors.As(err, &connErr) {
+		t.Fatalf("Expected a ConnectionError, got %v", err)
+	}
+	if connErr.Kind != ErrorRefused || !connErr.Recoverable() {
+		t.Errorf("Expected a recoverable refused connection, got %v", connErr)
+	}
+	if !strings.HasPrefix(connErr.Error(), "failed to connect to 127.0.0.1:") {
+		t.Errorf("Expected the raw error kept for logs, got %q", connErr.Error())
+	}
+}
+
+func TestClosedErrorKeepsWording(t *testing.T) {
+	if got := closedError(io.EOF, "127.0.0.1:4000").Error(); got != "connection closed by remote host" {
+		t.Errorf("Unexpected closed error %q", got)
+	}
+}
@@ -0,0 +1,93 @@
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"github.com/anicolao/dikuclient/internal/version"
+)
+
+// GMCPMessage is a GMCP (telnet option 201) message from the server: the
+// package and message name, such as Char.Vitals, and its JSON data
+type GMCPMessage struct {
+	Package string
+	JSON    json.RawMessage // Empty when the message has no data
+}
+
+// GMCPPackages are the GMCP packages the client subscribes to once the
+// server agrees to GMCP. Char carries Char.Vitals.
+var GMCPPackages = []string{"Char 1"}
+
+// ParseGMCPMessage splits the data of a GMCP subnegotiation into the package
+// name and the JSON after it
+func ParseGMCPMessage(data []byte) GMCPMessage {
+	name, payload, _ := bytes.Cut(bytes.TrimSpace(data), []byte(" "))
+	return GMCPMessage{
+		Package: string(name),
+		JSON:    json.RawMessage(bytes.TrimSpace(payload)),
+	}
+}
+
+// EncodeGMCP builds the subnegotiation sending a GMCP message, with data
+// encoded as its JSON (nil sends the package name alone)
+func EncodeGMCP(pkg string, data any) []byte {
+	message := []byte(pkg)
+	if data != nil {
+		encoded, err := json.Marshal(data)
+		if err != nil {
+			return nil
+		}
+		message = append(append(message, ' '), encoded...)
+	}
+	return EncodeSubnegotiation(TELOPT_GMCP, message)
+}
+
+// gmcpHandshake is what the client sends once the server agrees to GMCP:
+// Core.Hello naming the client, then the packages it wants
+func gmcpHandshake() []byte {
+	hello := EncodeGMCP("Core.Hello", map[string]string{
+		"client":  "dikuclient",
+		"version": version.Get().Version,
+	})
+	return append(hello, EncodeGMCP("Core.Supports.Set", GMCPPackages)...)
+}
+
+// negotiateGMCP answers the server's WILL or WONT GMCP. On WILL the client
+// agrees, unless RequestGMCP already asked, and subscribes to GMCPPackages.
+func (c *Connection) negotiateGMCP(cmd byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	switch cmd {
+	case WILL:
+		if c.gmcpOn {
+			return
+		}
+		c.gmcpOn = true
+		if !c.gmcpAsked {
+			c.gmcpAsked = true
+			c.reply(EncodeNegotiation(DO, TELOPT_GMCP))
+		}
+		c.reply(gmcpHandshake())
+	case WONT:
+		c.gmcpOn = false
+		c.gmcpAsked = false
+	}
+}
+
+// RequestGMCP asks the server to start GMCP, for servers that wait for the
+// client to ask rather than offering it. Once the server agrees the client
+// subscribes to GMCPPackages; messages arrive on GMCP.
+func (c *Connection) RequestGMCP() {
+	c.mu.Lock()
+	asked := c.gmcpAsked
+	c.gmcpAsked = true
+	c.mu.Unlock()
+	if !asked {
+		c.SendRaw(EncodeNegotiation(DO, TELOPT_GMCP))
+	}
+}
+
+// GMCP returns the channel of GMCP messages from the server
+func (c *Connection) GMCP() <-chan GMCPMessage {
+	return c.gmcpChan
+}
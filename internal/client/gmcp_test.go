@@ -0,0 +1,86 @@
+package client
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestParseGMCPMessage(t *testing.T) {
+	tests := []struct {
+		data, pkg, json string
+	}{
+		{`Char.Vitals {"hp": 40, "maxhp": 200}`, "Char.Vitals", `{"hp": 40, "maxhp": 200}`},
+		{`Room.Info   {"num": 3001} `, "Room.Info", `{"num": 3001}`},
+		{`Core.Goodbye`, "Core.Goodbye", ``},
+		{`Core.Ping`, "Core.Ping", ``},
+	}
+	for _, tt := range tests {
+		got := ParseGMCPMessage([]byte(tt.data))
+		if got.Package != tt.pkg || string(got.JSON) != tt.json {
+			t.Errorf("ParseGMCPMessage(%q) = %q %q, want %q %q", tt.data, got.Package, got.JSON, tt.pkg, tt.json)
+		}
+	}
+}
+
+// TestGMCPSplitAtEveryByte feeds a GMCP message, with an escaped IAC in its
+// JSON, split at each possible point between two reads
+func TestGMCPSplitAtEveryByte(t *testing.T) {
+	stream := []byte("Hello")
+	stream = append(stream, IAC, SB, TELOPT_GMCP)
+	stream = append(stream, `Char.Vitals {"hp": "5`...)
+	stream = append(stream, IAC, IAC)
+	stream = append(stream, `"}`...)
+	stream = append(stream, IAC, SE)
+	stream = append(stream, " world"...)
+
+	for split := 1; split < len(stream); split++ {
+		conn := &Connection{gmcpChan: make(chan GMCPMessage, 10)}
+		text := conn.processTelnetData(stream[:split])
+		text = append(text, conn.processTelnetData(stream[split:])...)
+		if string(text) != "Hello world" {
+			t.Errorf("Split at %d: expected the message stripped from the text, got %q", split, text)
+		}
+		if len(conn.gmcpChan) != 1 {
+			t.Fatalf("Split at %d: expected 1 GMCP message, got %d", split, len(conn.gmcpChan))
+		}
+		got := <-conn.gmcpChan
+		if want := "{\"hp\": \"5\xff\"}"; got.Package != "Char.Vitals" || string(got.JSON) != want {
+			t.Errorf("Split at %d: got %q %q, want Char.Vitals %q", split, got.Package, got.JSON, want)
+		}
+	}
+}
+
+func TestGMCPNegotiation(t *testing.T) {
+	conn := &Connection{rawChan: make(chan []byte, 10), gmcpChan: make(chan GMCPMessage, 10)}
+
+	// The server offers GMCP: the client agrees and subscribes, once
+	conn.processTelnetData([]byte{IAC, WILL, TELOPT_GMCP, IAC, WILL, TELOPT_GMCP})
+	var sent []byte
+	for len(conn.rawChan) > 0 {
+		sent = append(sent, <-conn.rawChan...)
+	}
+	want := append(EncodeNegotiation(DO, TELOPT_GMCP), gmcpHandshake()...)
+	if !bytes.Equal(sent, want) {
+		t.Fatalf("Expected DO GMCP and the handshake once, got %q", sent)
+	}
+	if !bytes.Contains(sent, []byte(`Core.Supports.Set ["Char 1"]`)) || !bytes.Contains(sent, []byte(`Core.Hello {"client":"dikuclient"`)) {
+		t.Errorf("Expected Core.Hello and Core.Supports.Set, got %q", sent)
+	}
+
+	// Having asked first, the client doesn't answer the server's WILL with
+	// another DO, but still subscribes
+	conn = &Connection{rawChan: make(chan []byte, 10), gmcpChan: make(chan GMCPMessage, 10)}
+	conn.RequestGMCP()
+	if got := <-conn.rawChan; !bytes.Equal(got, EncodeNegotiation(DO, TELOPT_GMCP)) {
+		t.Fatalf("Expected RequestGMCP to send DO GMCP, got %q", got)
+	}
+	conn.RequestGMCP()
+	conn.processTelnetData([]byte{IAC, WILL, TELOPT_GMCP})
+	sent = nil
+	for len(conn.rawChan) > 0 {
+		sent = append(sent, <-conn.rawChan...)
+	}
+	if !bytes.Equal(sent, gmcpHandshake()) {
+		t.Errorf("Expected only the handshake after asking, got %q", sent)
+	}
+}
@@ -0,0 +1,28 @@
+package client
+
+import "time"
+
+// latencyAlpha is the weight of each new sample in the smoothed round-trip
+// time. Lower values smooth out the odd slow reply; higher ones react faster.
+const latencyAlpha = 0.25
+
+// Latency keeps an exponential moving average of round-trip times
+type Latency struct {
+	average time.Duration
+	samples int
+}
+
+// Add records a round-trip sample. The first sample is taken as is.
+func (l *Latency) Add(sample time.Duration) {
+	if l.samples == 0 {
+		l.average = sample
+	} else {
+		l.average = time.Duration(latencyAlpha*float64(sample) + (1-latencyAlpha)*float64(l.average))
+	}
+	l.samples++
+}
+
+// Average returns the smoothed round-trip time, and false before any samples
+func (l *Latency) Average() (time.Duration, bool) {
+	return l.average, l.samples > 0
+}
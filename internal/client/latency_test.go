@@ -0,0 +1,83 @@
+package client
+
+import (
+	"bufio"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestLatencySmoothing(t *testing.T) {
+	var latency Latency
+	if _, ok := latency.Average(); ok {
+		t.Error("Expected no average before any samples")
+	}
+
+	samples := []struct {
+		sample time.Duration
+		want   time.Duration
+	}{
+		{40 * time.Millisecond, 40 * time.Millisecond},   // First sample is taken as is
+		{80 * time.Millisecond, 50 * time.Millisecond},   // 0.25*80 + 0.75*40
+		{50 * time.Millisecond, 50 * time.Millisecond},   // Steady
+		{450 * time.Millisecond, 150 * time.Millisecond}, // One slow reply moves it a quarter of the way
+		{150 * time.Millisecond, 150 * time.Millisecond},
+	}
+	for i, s := range samples {
+		latency.Add(s.sample)
+		got, ok := latency.Average()
+		if !ok || got != s.want {
+			t.Errorf("After sample %d (%v): average %v, want %v", i+1, s.sample, got, s.want)
+		}
+	}
+}
+
+func TestRTTMeasuresReplyToCommand(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	defer listener.Close()
+
+	accepted := make(chan net.Conn, 1)
+	go func() {
+		if conn, err := listener.Accept(); err == nil {
+			accepted <- conn
+		}
+	}()
+
+	conn, err := NewConnection("127.0.0.1", listener.Addr().(*net.TCPAddr).Port)
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer conn.Close()
+
+	server := <-accepted
+	defer server.Close()
+	reader := bufio.NewReader(server)
+
+	// Output the server sends unprompted isn't a reply
+	server.Write([]byte("Welcome!\r\n"))
+	<-conn.Receive()
+	if _, ok := conn.RTT(); ok {
+		t.Error("Expected no RTT before a command is answered")
+	}
+
+	conn.Send("look")
+	server.SetReadDeadline(time.Now().Add(2 * time.Second))
+	if _, err := reader.ReadString('\n'); err != nil {
+		t.Fatalf("Failed to read command: %v", err)
+	}
+	delay := 30 * time.Millisecond
+	time.Sleep(delay)
+	server.Write([]byte("Temple Square\r\n"))
+	<-conn.Receive()
+
+	rtt, ok := conn.RTT()
+	if !ok {
+		t.Fatal("Expected an RTT once the command was answered")
+	}
+	if rtt < delay || rtt > 2*time.Second {
+		t.Errorf("Expected an RTT of at least %v, got %v", delay, rtt)
+	}
+}
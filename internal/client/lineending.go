@@ -0,0 +1,52 @@
+package client
+
+import (
+	"fmt"
+	"strings"
+)
+
+// LineEnding is what ends each command sent to a MUD. Most accept CRLF, as
+// telnet specifies, but some take the CR as an extra empty command and
+// others ignore commands without it.
+type LineEnding int
+
+const (
+	LineEndingCRLF LineEnding = iota // "\r\n" (the default)
+	LineEndingLF                     // "\n"
+	LineEndingCR                     // "\r"
+)
+
+// ParseLineEnding returns the line ending with the given name: crlf, lf or cr
+func ParseLineEnding(name string) (LineEnding, error) {
+	switch strings.ToLower(name) {
+	case "crlf", "\\r\\n", "":
+		return LineEndingCRLF, nil
+	case "lf", "\\n":
+		return LineEndingLF, nil
+	case "cr", "\\r":
+		return LineEndingCR, nil
+	}
+	return LineEndingCRLF, fmt.Errorf("unknown line ending %q (use crlf, lf or cr)", name)
+}
+
+// String returns the line ending's name as accepted by ParseLineEnding
+func (le LineEnding) String() string {
+	switch le {
+	case LineEndingLF:
+		return "lf"
+	case LineEndingCR:
+		return "cr"
+	}
+	return "crlf"
+}
+
+// Terminator returns the characters that end a command
+func (le LineEnding) Terminator() string {
+	switch le {
+	case LineEndingLF:
+		return "\n"
+	case LineEndingCR:
+		return "\r"
+	}
+	return "\r\n"
+}
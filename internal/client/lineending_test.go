@@ -0,0 +1,83 @@
+package client
+
+import (
+	"io"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestParseLineEnding(t *testing.T) {
+	tests := []struct {
+		name string
+		want LineEnding
+	}{
+		{"", LineEndingCRLF},
+		{"crlf", LineEndingCRLF},
+		{"CRLF", LineEndingCRLF},
+		{"lf", LineEndingLF},
+		{`\n`, LineEndingLF},
+		{"cr", LineEndingCR},
+	}
+	for _, tt := range tests {
+		got, err := ParseLineEnding(tt.name)
+		if err != nil || got != tt.want {
+			t.Errorf("ParseLineEnding(%q) = %v, %v; want %v", tt.name, got, err, tt.want)
+		}
+		if back, _ := ParseLineEnding(got.String()); back != got {
+			t.Errorf("%v doesn't round-trip through its name", got)
+		}
+	}
+	if _, err := ParseLineEnding("nul"); err == nil {
+		t.Error("Expected an error for an unknown line ending")
+	}
+}
+
+func TestSendUsesLineEnding(t *testing.T) {
+	tests := []struct {
+		lineEnding LineEnding
+		want       string
+	}{
+		{LineEndingCRLF, "look\r\n"},
+		{LineEndingLF, "look\n"},
+		{LineEndingCR, "look\r"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.lineEnding.String(), func(t *testing.T) {
+			listener, err := net.Listen("tcp", "127.0.0.1:0")
+			if err != nil {
+				t.Fatalf("Failed to listen: %v", err)
+			}
+			defer listener.Close()
+
+			accepted := make(chan net.Conn, 1)
+			go func() {
+				if conn, err := listener.Accept(); err == nil {
+					accepted <- conn
+				}
+			}()
+
+			conn, err := NewConnection("127.0.0.1", listener.Addr().(*net.TCPAddr).Port)
+			if err != nil {
+				t.Fatalf("Failed to connect: %v", err)
+			}
+			server := <-accepted
+			defer server.Close()
+
+			conn.SetLineEnding(tt.lineEnding)
+			conn.Send("look")
+			conn.Send("look")
+			defer conn.Close()
+
+			// Two commands show the terminator isn't doubled or missing
+			got := make([]byte, 2*len(tt.want))
+			server.SetReadDeadline(time.Now().Add(2 * time.Second))
+			if _, err := io.ReadFull(server, got); err != nil {
+				t.Fatalf("Failed to read: %v", err)
+			}
+			if string(got) != tt.want+tt.want {
+				t.Errorf("Server got %q, want %q twice", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,178 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/anicolao/dikuclient/internal/debuglog"
+)
+
+// RecordedChunk is one read from the server in a session recording. Data is
+// the raw bytes as received, telnet sequences included, so a replay goes
+// through the same processing as the live session did.
+type RecordedChunk struct {
+	Ms   int64  `json:"ms"`   // Milliseconds since the recording started
+	Data []byte `json:"data"` // Raw bytes (base64 in the file)
+}
+
+// Recorder writes a session recording as JSON lines, one chunk per line
+type Recorder struct {
+	mu    sync.Mutex
+	w     io.WriteCloser
+	enc   *json.Encoder
+	start time.Time
+	path  string
+}
+
+// CreateRecording starts a new recording in the file at path, replacing it
+func CreateRecording(path string) (*Recorder, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	r := NewRecorder(f, time.Now())
+	r.path = path
+	return r, nil
+}
+
+// NewRecorder returns a recorder that writes to w, timing chunks from start
+func NewRecorder(w io.WriteCloser, start time.Time) *Recorder {
+	return &Recorder{w: w, enc: json.NewEncoder(w), start: start}
+}
+
+// Path returns the file being recorded to, or "" if not recording to a file
+func (r *Recorder) Path() string {
+	return r.path
+}
+
+// Record appends data received at now to the recording
+func (r *Recorder) Record(data []byte, now time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	chunk := RecordedChunk{
+		Ms:   now.Sub(r.start).Milliseconds(),
+		Data: append([]byte(nil), data...),
+	}
+	return r.enc.Encode(chunk)
+}
+
+// Close finishes the recording
+func (r *Recorder) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.w.Close()
+}
+
+// ReadRecording reads the chunks of a session recording
+func ReadRecording(r io.Reader) ([]RecordedChunk, error) {
+	dec := json.NewDecoder(r)
+	var chunks []RecordedChunk
+	for {
+		var chunk RecordedChunk
+		if err := dec.Decode(&chunk); err != nil {
+			if errors.Is(err, io.EOF) {
+				return chunks, nil
+			}
+			return nil, fmt.Errorf("chunk %d: %w", len(chunks)+1, err)
+		}
+		chunks = append(chunks, chunk)
+	}
+}
+
+// LoadRecording reads the session recording in the file at path
+func LoadRecording(path string) ([]RecordedChunk, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ReadRecording(f)
+}
+
+// StartRecording captures everything the server sends from now on, stopping
+// any recording already running
+func (c *Connection) StartRecording(r *Recorder) {
+	c.mu.Lock()
+	old := c.recorder
+	c.recorder = r
+	c.mu.Unlock()
+	if old != nil {
+		old.Close()
+	}
+}
+
+// StopRecording stops and closes the current recording, if any
+func (c *Connection) StopRecording() error {
+	c.mu.Lock()
+	r := c.recorder
+	c.recorder = nil
+	c.mu.Unlock()
+	if r == nil {
+		return nil
+	}
+	return r.Close()
+}
+
+// Recording returns the running recorder, or nil when not recording
+func (c *Connection) Recording() *Recorder {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.recorder
+}
+
+// recordChunk passes data read from the server to the recorder. A recording
+// that can't be written is dropped rather than stalling the session.
+func (c *Connection) recordChunk(data []byte, now time.Time) {
+	r := c.Recording()
+	if r == nil {
+		return
+	}
+	if err := r.Record(data, now); err != nil {
+		if c.debugLog.Enabled(debuglog.Negotiation) {
+			fmt.Fprintf(c.debugLog, "[%s] === Recording failed: %v ===\n\n", now.Format("15:04:05.000"), err)
+		}
+		c.StopRecording()
+	}
+}
+
+// NewReplayConnection plays a session recording back as if it came from a
+// server. Chunks arrive at their recorded times divided by speed (2 plays
+// twice as fast); a speed of 0 or less plays them without delay. Commands
+// sent to the connection are discarded. The connection stays open after the
+// last chunk so the session can still be looked over. The charset should be
+// the one the recorded server used.
+func NewReplayConnection(chunks []RecordedChunk, speed float64, charset Charset) *Connection {
+	local, remote := net.Pipe()
+	c := newConnection(local, nil, charset)
+
+	go c.readLoop()
+	go c.writeLoop()
+	go io.Copy(io.Discard, remote)
+	go replayChunks(remote, chunks, speed, c.closeCh)
+
+	return c
+}
+
+// replayChunks writes chunks to w on their recorded schedule until done
+func replayChunks(w io.Writer, chunks []RecordedChunk, speed float64, done <-chan struct{}) {
+	start := time.Now()
+	for _, chunk := range chunks {
+		if speed > 0 {
+			due := start.Add(time.Duration(float64(chunk.Ms) * float64(time.Millisecond) / speed))
+			select {
+			case <-done:
+				return
+			case <-time.After(time.Until(due)):
+			}
+		}
+		if _, err := w.Write(chunk.Data); err != nil {
+			return
+		}
+	}
+}
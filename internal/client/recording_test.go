@@ -0,0 +1,178 @@
+package client
+
+import (
+	"bytes"
+	"io"
+	"net"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+type nopWriteCloser struct{ io.Writer }
+
+func (nopWriteCloser) Close() error { return nil }
+
+func TestRecordingFormat(t *testing.T) {
+	var buf bytes.Buffer
+	start := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	rec := NewRecorder(nopWriteCloser{&buf}, start)
+
+	// Raw bytes include telnet sequences, which aren't valid UTF-8
+	prompt := []byte{'>', ' ', IAC, GA}
+	if err := rec.Record([]byte("Welcome!\r\n"), start); err != nil {
+		t.Fatalf("Record failed: %v", err)
+	}
+	if err := rec.Record(prompt, start.Add(1500*time.Millisecond)); err != nil {
+		t.Fatalf("Record failed: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected one line per chunk, got %q", buf.String())
+	}
+	if lines[0] != `{"ms":0,"data":"V2VsY29tZSENCg=="}` {
+		t.Errorf("Unexpected first line %s", lines[0])
+	}
+
+	chunks, err := ReadRecording(&buf)
+	if err != nil {
+		t.Fatalf("ReadRecording failed: %v", err)
+	}
+	if len(chunks) != 2 {
+		t.Fatalf("Expected 2 chunks, got %d", len(chunks))
+	}
+	if chunks[1].Ms != 1500 || !bytes.Equal(chunks[1].Data, prompt) {
+		t.Errorf("Second chunk round-tripped as %+v", chunks[1])
+	}
+}
+
+func TestReadRecordingReportsBadChunk(t *testing.T) {
+	_, err := ReadRecording(strings.NewReader("{\"ms\":0,\"data\":\"\"}\nnot json\n"))
+	if err == nil || !strings.Contains(err.Error(), "chunk 2") {
+		t.Errorf("Expected an error naming chunk 2, got %v", err)
+	}
+}
+
+func TestConnectionRecordsServerBytes(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	defer listener.Close()
+
+	accepted := make(chan net.Conn, 1)
+	go func() {
+		if conn, err := listener.Accept(); err == nil {
+			accepted <- conn
+		}
+	}()
+
+	conn, err := NewConnection("127.0.0.1", listener.Addr().(*net.TCPAddr).Port)
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer conn.Close()
+
+	server := <-accepted
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "session.rec")
+	rec, err := CreateRecording(path)
+	if err != nil {
+		t.Fatalf("CreateRecording failed: %v", err)
+	}
+	conn.StartRecording(rec)
+
+	server.Write([]byte("You are in a dark room.\r\n"))
+	select {
+	case <-conn.Receive():
+	case <-time.After(2 * time.Second):
+		t.Fatal("Timed out waiting for output")
+	}
+	if err := conn.StopRecording(); err != nil {
+		t.Fatalf("StopRecording failed: %v", err)
+	}
+	if conn.Recording() != nil {
+		t.Error("Expected recording to be stopped")
+	}
+
+	chunks, err := LoadRecording(path)
+	if err != nil {
+		t.Fatalf("LoadRecording failed: %v", err)
+	}
+	var got []byte
+	for _, chunk := range chunks {
+		got = append(got, chunk.Data...)
+	}
+	if string(got) != "You are in a dark room.\r\n" {
+		t.Errorf("Recorded %q, want the raw server bytes", got)
+	}
+}
+
+func TestReplayConnection(t *testing.T) {
+	chunks := []RecordedChunk{
+		{Ms: 0, Data: []byte("Welcome!\r\n")},
+		{Ms: 200, Data: []byte{IAC, WILL, TELOPT_ECHO}},
+		{Ms: 400, Data: []byte("Password: \r\n")},
+	}
+
+	start := time.Now()
+	conn := NewReplayConnection(chunks, 2, CharsetUTF8)
+	defer conn.Close()
+
+	var output strings.Builder
+	deadline := time.After(2 * time.Second)
+	for !strings.Contains(output.String(), "Password:") {
+		select {
+		case s := <-conn.Receive():
+			output.WriteString(s.Text)
+		case <-deadline:
+			t.Fatalf("Timed out, got %q", output.String())
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 200*time.Millisecond {
+		t.Errorf("Replay at double speed took %v, want at least 200ms", elapsed)
+	}
+	if output.String() != "Welcome!\nPassword: \n" {
+		t.Errorf("Replay produced %q", output.String())
+	}
+
+	// Telnet sequences are processed as they were live
+	select {
+	case suppressed := <-conn.EchoState():
+		if !suppressed {
+			t.Error("Expected WILL ECHO to hide input")
+		}
+	case <-time.After(time.Second):
+		t.Error("Expected an echo state change from the replayed WILL ECHO")
+	}
+
+	// Commands go nowhere but don't block
+	conn.Send("look")
+	if conn.IsClosed() {
+		t.Error("Replay connection should stay open after the last chunk")
+	}
+}
+
+func TestReplayConnectionWithoutDelay(t *testing.T) {
+	chunks := []RecordedChunk{{Ms: 60000, Data: []byte("An hour later\r\n")}}
+	conn := NewReplayConnection(chunks, 0, CharsetUTF8)
+	defer conn.Close()
+
+	select {
+	case s := <-conn.Receive():
+		if s.Text != "An hour later\n" {
+			t.Errorf("Got %q", s.Text)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Speed 0 should play chunks without waiting")
+	}
+}
+
+func TestCreateRecordingFailsForBadPath(t *testing.T) {
+	if _, err := CreateRecording(filepath.Join(t.TempDir(), "missing", "x.rec")); err == nil {
+		t.Error("Expected an error for a missing directory")
+	}
+}
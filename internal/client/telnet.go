@@ -0,0 +1,99 @@
+package client
+
+import (
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// telnetOptions are the option names accepted by ParseTelnetOption
+var telnetOptions = map[string]byte{
+	"binary":   0,
+	"echo":     TELOPT_ECHO,
+	"sga":      3,
+	"status":   5,
+	"tm":       6,
+	"ttype":    24,
+	"eor":      25,
+	"naws":     31,
+	"linemode": 34,
+	"environ":  39,
+	"charset":  42,
+	"msdp":     69,
+	"mssp":     70,
+	"mccp2":    86,
+	"mccp3":    87,
+	"msp":      90,
+	"mxp":      91,
+	"atcp":     200,
+	"gmcp":     201,
+}
+
+// telnetCommands are the negotiation commands accepted by ParseTelnetCommand
+var telnetCommands = map[string]byte{
+	"will": WILL,
+	"wont": WONT,
+	"do":   DO,
+	"dont": DONT,
+}
+
+// ParseTelnetOption reads a telnet option by name (gmcp, naws, ...) or number
+func ParseTelnetOption(s string) (byte, error) {
+	if option, ok := telnetOptions[strings.ToLower(s)]; ok {
+		return option, nil
+	}
+	n, err := strconv.ParseUint(s, 0, 8)
+	if err != nil {
+		return 0, fmt.Errorf("unknown telnet option %q (use a number 0-255 or one of: %s)", s, strings.Join(TelnetOptionNames(), ", "))
+	}
+	return byte(n), nil
+}
+
+// TelnetOptionNames returns the option names ParseTelnetOption knows, sorted
+func TelnetOptionNames() []string {
+	names := make([]string, 0, len(telnetOptions))
+	for name := range telnetOptions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ParseTelnetCommand reads a negotiation command: will, wont, do or dont
+func ParseTelnetCommand(s string) (byte, error) {
+	if cmd, ok := telnetCommands[strings.ToLower(s)]; ok {
+		return cmd, nil
+	}
+	return 0, fmt.Errorf("unknown telnet command %q (use will, wont, do or dont)", s)
+}
+
+// ParseHexBytes reads bytes written as hex, with or without spaces between
+// them ("01 ff" or "01ff")
+func ParseHexBytes(s string) ([]byte, error) {
+	s = strings.Join(strings.Fields(s), "")
+	data, err := hex.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid hex bytes %q: %w", s, err)
+	}
+	return data, nil
+}
+
+// EncodeNegotiation builds IAC <command> <option>
+func EncodeNegotiation(command, option byte) []byte {
+	return []byte{IAC, command, option}
+}
+
+// EncodeSubnegotiation builds IAC SB <option> <data> IAC SE, doubling any IAC
+// bytes in data so they aren't read as the end of the sequence
+func EncodeSubnegotiation(option byte, data []byte) []byte {
+	seq := []byte{IAC, SB, option}
+	for _, b := range data {
+		seq = append(seq, b)
+		if b == IAC {
+			seq = append(seq, IAC)
+		}
+	}
+	return append(seq, IAC, SE)
+}
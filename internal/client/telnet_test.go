@@ -0,0 +1,97 @@
+package client
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestEncodeTelnetSequences(t *testing.T) {
+	tests := []struct {
+		name string
+		got  []byte
+		want []byte
+	}{
+		{"IAC DO GMCP", EncodeNegotiation(DO, 201), []byte{0xFF, 0xFD, 0xC9}},
+		{"IAC WILL NAWS", EncodeNegotiation(WILL, 31), []byte{0xFF, 0xFB, 0x1F}},
+		{"IAC WONT ECHO", EncodeNegotiation(WONT, TELOPT_ECHO), []byte{0xFF, 0xFC, 0x01}},
+		{"IAC DONT MSDP", EncodeNegotiation(DONT, 69), []byte{0xFF, 0xFE, 0x45}},
+		{"NAWS 80x24", EncodeSubnegotiation(31, []byte{0, 80, 0, 24}), []byte{0xFF, 0xFA, 0x1F, 0x00, 0x50, 0x00, 0x18, 0xFF, 0xF0}},
+		{"IAC in data is doubled", EncodeSubnegotiation(31, []byte{0x01, 0xFF}), []byte{0xFF, 0xFA, 0x1F, 0x01, 0xFF, 0xFF, 0xFF, 0xF0}},
+		{"empty subnegotiation", EncodeSubnegotiation(24, nil), []byte{0xFF, 0xFA, 0x18, 0xFF, 0xF0}},
+	}
+	for _, tt := range tests {
+		if !bytes.Equal(tt.got, tt.want) {
+			t.Errorf("%s: got % X, want % X", tt.name, tt.got, tt.want)
+		}
+	}
+}
+
+func TestParseTelnetArguments(t *testing.T) {
+	for input, want := range map[string]byte{"gmcp": 201, "GMCP": 201, "naws": 31, "86": 86, "0x45": 69} {
+		if got, err := ParseTelnetOption(input); err != nil || got != want {
+			t.Errorf("ParseTelnetOption(%q) = %d, %v; want %d", input, got, err, want)
+		}
+	}
+	for _, input := range []string{"256", "bogus", "-1"} {
+		if _, err := ParseTelnetOption(input); err == nil {
+			t.Errorf("ParseTelnetOption(%q) should fail", input)
+		}
+	}
+
+	if cmd, err := ParseTelnetCommand("DONT"); err != nil || cmd != DONT {
+		t.Errorf("ParseTelnetCommand(DONT) = %d, %v", cmd, err)
+	}
+	if _, err := ParseTelnetCommand("maybe"); err == nil {
+		t.Error("ParseTelnetCommand(maybe) should fail")
+	}
+
+	for input, want := range map[string][]byte{"01 ff": {0x01, 0xFF}, "0050 0018": {0x00, 0x50, 0x00, 0x18}, "": {}} {
+		if got, err := ParseHexBytes(input); err != nil || !bytes.Equal(got, want) {
+			t.Errorf("ParseHexBytes(%q) = % X, %v; want % X", input, got, err, want)
+		}
+	}
+	if _, err := ParseHexBytes("0g"); err == nil {
+		t.Error("ParseHexBytes(0g) should fail")
+	}
+}
+
+func TestSendRawWritesBytesAsIs(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	defer listener.Close()
+
+	accepted := make(chan net.Conn, 1)
+	go func() {
+		if conn, err := listener.Accept(); err == nil {
+			accepted <- conn
+		}
+	}()
+
+	conn, err := NewConnection("127.0.0.1", listener.Addr().(*net.TCPAddr).Port)
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer conn.Close()
+
+	server := <-accepted
+	defer server.Close()
+	reader := bufio.NewReader(server)
+
+	want := EncodeNegotiation(DO, 201)
+	conn.SendRaw(want)
+
+	got := make([]byte, len(want))
+	server.SetReadDeadline(time.Now().Add(2 * time.Second))
+	if _, err := io.ReadFull(reader, got); err != nil {
+		t.Fatalf("Failed to read raw bytes: %v", err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("Expected % X, got % X", want, got)
+	}
+}
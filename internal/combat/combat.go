@@ -0,0 +1,105 @@
+package combat
+
+import (
+	"fmt"
+	"time"
+)
+
+// Hit is one damage message: an attack by you or on you
+type Hit struct {
+	Dealt  bool   // You attacked (false: you were attacked)
+	Who    string // The target you attacked, or the attacker
+	Damage int    // Damage printed by the MUD, or estimated from the wording
+	Miss   bool   // The attack missed
+}
+
+// Tally accumulates attacks in one direction
+type Tally struct {
+	Hits   int
+	Misses int
+	Damage int
+}
+
+// Fight is the damage dealt and taken since combat started
+type Fight struct {
+	Target string
+	Start  time.Time
+	End    time.Time
+	Dealt  Tally
+	Taken  Tally
+}
+
+// Log tracks the current fight and remembers the last one
+type Log struct {
+	Current *Fight
+	Last    *Fight
+}
+
+// NewLog creates an empty combat log
+func NewLog() *Log {
+	return &Log{}
+}
+
+// Start begins a fight against target unless one is already under way
+func (l *Log) Start(target string, now time.Time) {
+	if l.Current == nil {
+		l.Current = &Fight{Start: now}
+	}
+	if l.Current.Target == "" {
+		l.Current.Target = target
+	}
+}
+
+// Record adds a hit to the current fight, starting one if needed
+func (l *Log) Record(hit *Hit, now time.Time) {
+	target := ""
+	if hit.Dealt {
+		target = hit.Who
+	}
+	l.Start(target, now)
+
+	tally := &l.Current.Taken
+	if hit.Dealt {
+		tally = &l.Current.Dealt
+	}
+	if hit.Miss {
+		tally.Misses++
+		return
+	}
+	tally.Hits++
+	tally.Damage += hit.Damage
+}
+
+// EndFight finishes the current fight and returns it (nil if there wasn't one)
+func (l *Log) EndFight(now time.Time) *Fight {
+	fight := l.Current
+	if fight == nil {
+		return nil
+	}
+	fight.End = now
+	l.Last = fight
+	l.Current = nil
+	return fight
+}
+
+// Duration returns how long the fight lasted, or has lasted so far
+func (f *Fight) Duration(now time.Time) time.Duration {
+	if !f.End.IsZero() {
+		now = f.End
+	}
+	return now.Sub(f.Start)
+}
+
+// String formats a tally as "45 dmg (9/11 hits)"
+func (t Tally) String() string {
+	return fmt.Sprintf("%d dmg (%d/%d hits)", t.Damage, t.Hits, t.Hits+t.Misses)
+}
+
+// Summary describes the fight in one line
+func (f *Fight) Summary(now time.Time) string {
+	target := f.Target
+	if target == "" {
+		target = "unknown"
+	}
+	return fmt.Sprintf("%s - dealt %s, taken %s in %.1fs", target, f.Dealt, f.Taken, f.Duration(now).Seconds())
+}
@@ -0,0 +1,151 @@
+package combat
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestParseDefaultPatterns(t *testing.T) {
+	tests := []struct {
+		line   string
+		dealt  bool
+		who    string
+		damage int
+		miss   bool
+	}{
+		// DikuMUD
+		{"You miss the orc with your slash.", true, "the orc", 0, true},
+		{"You tickle the orc as you hit it.", true, "the orc", 1, false},
+		{"You barely slash the orc.", true, "the orc", 3, false},
+		{"You hit the orc.", true, "the orc", 5, false},
+		{"You pierce the orc hard.", true, "the orc", 8, false},
+		{"You slash the orc very hard.", true, "the orc", 13, false},
+		{"You crush the orc extremely hard.", true, "the orc", 18, false},
+		{"You massacre the orc to small fragments with your slash.", true, "the orc", 40, false},
+		{"The orc misses you with its claw.", false, "the orc", 0, true},
+		{"The orc hits you hard.", false, "the orc", 8, false},
+		{"The orc tickles you as it bites you.", false, "the orc", 1, false},
+		// Merc/ROM
+		{"Your slash hits the orc very hard.", true, "the orc", 13, false},
+		{"Your slash misses the orc.", true, "the orc", 0, true},
+		{"Your pierce *** DEMOLISHES *** the orc!", true, "the orc", 60, false},
+		{"The orc's claw wounds you.", false, "the orc", 10, false},
+		{"The orc's claw misses you.", false, "the orc", 0, true},
+		{"Gandalf's blast === OBLITERATES === you!", false, "gandalf", 70, false},
+		// Printed damage
+		{"Your slash wounds the orc. (14)", true, "the orc", 14, false},
+		{"The orc's claw grazes you. [2]", false, "the orc", 2, false},
+	}
+
+	manager := NewManager()
+	for _, tt := range tests {
+		hit, ok := manager.Parse(tt.line)
+		if !ok {
+			t.Errorf("Parse(%q) did not match", tt.line)
+			continue
+		}
+		if hit.Dealt != tt.dealt || hit.Who != tt.who || hit.Damage != tt.damage || hit.Miss != tt.miss {
+			t.Errorf("Parse(%q) = %+v, want dealt=%v who=%q damage=%d miss=%v", tt.line, *hit, tt.dealt, tt.who, tt.damage, tt.miss)
+		}
+	}
+
+	for _, line := range []string{"You open the door.", "The orc arrives from the north.", "You say 'hit me'", ""} {
+		if hit, ok := manager.Parse(line); ok {
+			t.Errorf("Parse(%q) should not match, got %+v", line, *hit)
+		}
+	}
+}
+
+func TestCustomPatternsOverrideDefaults(t *testing.T) {
+	manager := NewManager()
+	if _, err := manager.Add(Dealt, `^You strike (?P<target>.+?) for (?P<amount>\d+) damage\.$`); err != nil {
+		t.Fatalf("Failed to add pattern: %v", err)
+	}
+	if _, err := manager.Add(Taken, `^(?P<who>.+) hits you$`); err == nil {
+		t.Error("Expected a taken pattern without an attacker group to be rejected")
+	}
+	if _, err := manager.Add("sideways", `(?P<target>.+)`); err == nil {
+		t.Error("Expected an unknown direction to be rejected")
+	}
+
+	hit, ok := manager.Parse("You strike the troll for 27 damage.")
+	if !ok || !hit.Dealt || hit.Who != "the troll" || hit.Damage != 27 {
+		t.Errorf("Expected custom pattern to record 27 damage to the troll, got %+v", hit)
+	}
+
+	path := filepath.Join(t.TempDir(), "combat.json")
+	manager.filePath = path
+	if err := manager.Save(); err != nil {
+		t.Fatalf("Failed to save: %v", err)
+	}
+	loaded, err := LoadFromPath(path)
+	if err != nil {
+		t.Fatalf("Failed to load: %v", err)
+	}
+	if hit, ok := loaded.Parse("You strike the troll for 3 damage."); !ok || hit.Damage != 3 {
+		t.Errorf("Expected loaded pattern to match, got %+v", hit)
+	}
+}
+
+func TestLogAccumulatesFight(t *testing.T) {
+	manager := NewManager()
+	log := NewLog()
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	lines := []string{
+		"Your slash hits the orc very hard.",
+		"The orc's claw misses you.",
+		"Your slash misses the orc.",
+		"The orc's claw wounds you.",
+		"Your slash wounds the orc. (14)",
+		"The orc is dead!",
+	}
+	for _, line := range lines {
+		if hit, ok := manager.Parse(line); ok {
+			log.Record(hit, start)
+		}
+	}
+
+	fight := log.Current
+	if fight == nil {
+		t.Fatal("Expected a fight to be in progress")
+	}
+	if fight.Target != "the orc" {
+		t.Errorf("Expected target 'the orc', got %q", fight.Target)
+	}
+	if fight.Dealt != (Tally{Hits: 2, Misses: 1, Damage: 27}) {
+		t.Errorf("Unexpected dealt tally %+v", fight.Dealt)
+	}
+	if fight.Taken != (Tally{Hits: 1, Misses: 1, Damage: 10}) {
+		t.Errorf("Unexpected taken tally %+v", fight.Taken)
+	}
+
+	ended := log.EndFight(start.Add(12 * time.Second))
+	if ended != fight || log.Current != nil || log.Last != fight {
+		t.Error("Expected the fight to end and become the last fight")
+	}
+	if got, want := ended.Summary(time.Time{}), "the orc - dealt 27 dmg (2/3 hits), taken 10 dmg (1/2 hits) in 12.0s"; got != want {
+		t.Errorf("Summary() = %q, want %q", got, want)
+	}
+	if log.EndFight(start) != nil {
+		t.Error("Expected no fight to end when none is in progress")
+	}
+}
+
+func TestStartKeepsFightTarget(t *testing.T) {
+	log := NewLog()
+	now := time.Now()
+
+	// Being hit first starts a fight without a target
+	log.Record(&Hit{Who: "the orc", Damage: 5}, now)
+	if log.Current.Target != "" {
+		t.Errorf("Expected no target from an incoming hit, got %q", log.Current.Target)
+	}
+
+	log.Start("the orc", now)
+	log.Start("the goblin", now)
+	if log.Current.Target != "the orc" {
+		t.Errorf("Expected target to stay 'the orc', got %q", log.Current.Target)
+	}
+}
@@ -0,0 +1,312 @@
+package combat
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Directions a damage pattern can describe
+const (
+	Dealt = "dealt" // You hit something
+	Taken = "taken" // Something hit you
+)
+
+// Pattern recognises one form of damage message. The regex names who was
+// involved with a "target" (dealt) or "attacker" (taken) group, and may
+// capture an exact "amount" for MUDs that print damage numbers.
+type Pattern struct {
+	Pattern   string         `json:"pattern"`   // Regular expression
+	Direction string         `json:"direction"` // Dealt or Taken
+	regex     *regexp.Regexp // Compiled regex (not serialized)
+}
+
+// Manager holds the user's damage patterns, which are tried before the
+// built-in DikuMUD ones
+type Manager struct {
+	Patterns []*Pattern `json:"patterns"`
+	filePath string     // Path to combat.json (not serialized)
+}
+
+// attackVerbs are DikuMUD's weapon attack types
+const attackVerbs = `hit|pound|pierce|slash|whip|claw|bite|sting|crush`
+
+// damageVerbs are Merc/ROM damage verbs, with the decorations used on the
+// heavier ones (*** DEMOLISHES ***)
+const damageVerbs = `(?:[*=<>]{3} )?(?:scratches|grazes|hits|injures|wounds|mauls|decimates|devastates|maims|mutilates|disembowels|dismembers|massacres|mangles|demolishes|obliterates|annihilates|eradicates|does unspeakable things to)(?: [*=<>]{3})?`
+
+// degree is DikuMUD's "how hard" suffix
+const degree = `(?: hard| very hard| extremely hard)?`
+
+// amountSuffix matches a damage number printed after the message: (12) or [12]
+const amountSuffix = `(?:\s*[\[(](?P<amount>\d+)[\])])?$`
+
+// defaultPatterns cover DikuMUD and Merc/ROM damage messages
+var defaultPatterns = []*Pattern{
+	// You miss the orc with your slash. / Your slash misses the orc.
+	{Direction: Dealt, Pattern: `(?i)^You miss (?P<target>.+?)(?: with your .+?)?[.!]` + amountSuffix},
+	{Direction: Dealt, Pattern: `(?i)^Your [\w' -]+? misses (?P<target>.+?)[.!]` + amountSuffix},
+	// You tickle the orc as you hit it. / You massacre the orc to small fragments with your slash.
+	{Direction: Dealt, Pattern: `(?i)^You tickle (?P<target>.+?) as you \w+ \w+[.!]` + amountSuffix},
+	{Direction: Dealt, Pattern: `(?i)^You massacre (?P<target>.+?) to small fragments with your .+?[.!]` + amountSuffix},
+	// You barely slash the orc. / You hit the orc very hard.
+	{Direction: Dealt, Pattern: `(?i)^You (?:barely )?(?:` + attackVerbs + `) (?P<target>.+?)` + degree + `[.!]` + amountSuffix},
+	// Your slash wounds the orc. / Your pierce *** DEMOLISHES *** the orc!
+	{Direction: Dealt, Pattern: `(?i)^Your [\w' -]+? ` + damageVerbs + ` (?P<target>.+?)` + degree + `[.!]` + amountSuffix},
+
+	// The orc's claw misses you. / The orc misses you with its claw.
+	{Direction: Taken, Pattern: `(?i)^(?P<attacker>.+?)'s? [\w' -]+? misses you[.!]` + amountSuffix},
+	{Direction: Taken, Pattern: `(?i)^(?P<attacker>.+?) misses you(?: with \w+ .+?)?[.!]` + amountSuffix},
+	// The orc tickles you as it hits you. / The orc massacres you to small fragments with its claw.
+	{Direction: Taken, Pattern: `(?i)^(?P<attacker>.+?) tickles you as \w+ \w+ you[.!]` + amountSuffix},
+	{Direction: Taken, Pattern: `(?i)^(?P<attacker>.+?) massacres you to small fragments with .+?[.!]` + amountSuffix},
+	// The orc's claw wounds you.
+	{Direction: Taken, Pattern: `(?i)^(?P<attacker>.+?)'s? [\w' -]+? ` + damageVerbs + ` you` + degree + `[.!]` + amountSuffix},
+	// The orc barely claws you. / The orc hits you very hard.
+	{Direction: Taken, Pattern: `(?i)^(?P<attacker>.+?) (?:barely )?(?:hits|pounds|pierces|slashes|whips|claws|bites|stings|crushes) you` + degree + `[.!]` + amountSuffix},
+}
+
+func init() {
+	for _, pattern := range defaultPatterns {
+		if err := pattern.compile(); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// severities estimate the damage a message describes from its wording, most
+// specific first. DikuMUD and Merc/ROM scale their verbs with damage done.
+var severities = []struct {
+	regex  *regexp.Regexp
+	damage int
+}{
+	{regexp.MustCompile(`(?i)\bmiss(es)?\b`), 0},
+	{regexp.MustCompile(`(?i)\bunspeakable\b`), 100},
+	{regexp.MustCompile(`(?i)\beradicates\b`), 90},
+	{regexp.MustCompile(`(?i)\bannihilates\b`), 80},
+	{regexp.MustCompile(`(?i)\bobliterates\b`), 70},
+	{regexp.MustCompile(`(?i)\bdemolishes\b`), 60},
+	{regexp.MustCompile(`(?i)\bmangles\b`), 50},
+	{regexp.MustCompile(`(?i)\bmassacres?\b`), 40},
+	{regexp.MustCompile(`(?i)\bdismembers\b`), 36},
+	{regexp.MustCompile(`(?i)\bdisembowels\b`), 32},
+	{regexp.MustCompile(`(?i)\bmutilates\b`), 28},
+	{regexp.MustCompile(`(?i)\bmaims\b`), 24},
+	{regexp.MustCompile(`(?i)\bdevastates\b`), 21},
+	{regexp.MustCompile(`(?i)\bextremely hard\b`), 18},
+	{regexp.MustCompile(`(?i)\bdecimates\b`), 17},
+	{regexp.MustCompile(`(?i)\b(very hard|mauls)\b`), 13},
+	{regexp.MustCompile(`(?i)\bwounds\b`), 10},
+	{regexp.MustCompile(`(?i)\bhard\b`), 8},
+	{regexp.MustCompile(`(?i)\binjures\b`), 7},
+	{regexp.MustCompile(`(?i)\b(barely|grazes)\b`), 3},
+	{regexp.MustCompile(`(?i)\b(tickles?|scratches)\b`), 1},
+}
+
+// defaultDamage is the estimate for a plain hit
+const defaultDamage = 5
+
+// NewManager creates a manager with no custom patterns
+func NewManager() *Manager {
+	return &Manager{
+		Patterns: make([]*Pattern, 0),
+	}
+}
+
+// GetCombatPath returns the path to the combat patterns file
+func GetCombatPath() (string, error) {
+	var configDir string
+
+	// Check for environment variable override
+	if envConfigDir := os.Getenv("DIKUCLIENT_CONFIG_DIR"); envConfigDir != "" {
+		configDir = envConfigDir
+	} else {
+		homeDir, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("failed to get home directory: %w", err)
+		}
+		configDir = filepath.Join(homeDir, ".config", "dikuclient")
+	}
+
+	if err := os.MkdirAll(configDir, 0700); err != nil {
+		return "", fmt.Errorf("failed to create config directory: %w", err)
+	}
+
+	return filepath.Join(configDir, "combat.json"), nil
+}
+
+// Load loads combat patterns from disk
+func Load() (*Manager, error) {
+	combatPath, err := GetCombatPath()
+	if err != nil {
+		return nil, err
+	}
+
+	return LoadFromPath(combatPath)
+}
+
+// LoadFromPath loads combat patterns from a specific path (useful for testing)
+func LoadFromPath(combatPath string) (*Manager, error) {
+	data, err := os.ReadFile(combatPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			// Return empty manager if file doesn't exist
+			m := NewManager()
+			m.filePath = combatPath
+			return m, nil
+		}
+		return nil, fmt.Errorf("failed to read combat file: %w", err)
+	}
+
+	var m Manager
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("failed to parse combat file: %w", err)
+	}
+	m.filePath = combatPath
+
+	for _, pattern := range m.Patterns {
+		if err := pattern.compile(); err != nil {
+			return nil, fmt.Errorf("failed to compile combat pattern %q: %w", pattern.Pattern, err)
+		}
+	}
+
+	return &m, nil
+}
+
+// Save saves combat patterns to disk
+func (m *Manager) Save() error {
+	combatPath := m.filePath
+	if combatPath == "" {
+		var err error
+		combatPath, err = GetCombatPath()
+		if err != nil {
+			return err
+		}
+		m.filePath = combatPath
+	}
+
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal combat patterns: %w", err)
+	}
+
+	if err := os.WriteFile(combatPath, data, 0600); err != nil {
+		return fmt.Errorf("failed to write combat file: %w", err)
+	}
+
+	return nil
+}
+
+// Add adds a custom damage pattern
+func (m *Manager) Add(direction, pattern string) (*Pattern, error) {
+	if direction != Dealt && direction != Taken {
+		return nil, fmt.Errorf("direction must be %q or %q", Dealt, Taken)
+	}
+
+	p := &Pattern{
+		Pattern:   pattern,
+		Direction: direction,
+	}
+	if err := p.compile(); err != nil {
+		return nil, err
+	}
+
+	m.Patterns = append(m.Patterns, p)
+	return p, nil
+}
+
+// Remove removes a custom pattern by index (0-based)
+func (m *Manager) Remove(index int) error {
+	if index < 0 || index >= len(m.Patterns) {
+		return fmt.Errorf("invalid pattern index: %d", index)
+	}
+
+	m.Patterns = append(m.Patterns[:index], m.Patterns[index+1:]...)
+	return nil
+}
+
+// compile compiles the pattern and checks it names who was involved
+func (p *Pattern) compile() error {
+	regex, err := regexp.Compile(p.Pattern)
+	if err != nil {
+		return fmt.Errorf("failed to compile pattern: %w", err)
+	}
+
+	who := "target"
+	if p.Direction == Taken {
+		who = "attacker"
+	}
+	if regex.SubexpIndex(who) < 0 {
+		return fmt.Errorf("pattern needs a (?P<%s>...) group", who)
+	}
+
+	p.regex = regex
+	return nil
+}
+
+// Parse recognises a damage message, trying custom patterns before the
+// built-in ones
+func (m *Manager) Parse(line string) (*Hit, bool) {
+	line = strings.TrimSpace(line)
+	if line == "" {
+		return nil, false
+	}
+
+	for _, patterns := range [][]*Pattern{m.Patterns, defaultPatterns} {
+		for _, pattern := range patterns {
+			if hit, ok := pattern.parse(line); ok {
+				return hit, true
+			}
+		}
+	}
+	return nil, false
+}
+
+// parse matches a line against this pattern
+func (p *Pattern) parse(line string) (*Hit, bool) {
+	if p.regex == nil {
+		return nil, false
+	}
+	matches := p.regex.FindStringSubmatch(line)
+	if matches == nil {
+		return nil, false
+	}
+
+	who := "target"
+	if p.Direction == Taken {
+		who = "attacker"
+	}
+
+	hit := &Hit{
+		Dealt: p.Direction == Dealt,
+		Who:   strings.ToLower(strings.TrimSpace(matches[p.regex.SubexpIndex(who)])),
+	}
+
+	// Estimate from the wording, leaving out the name so it can't count
+	message := strings.Replace(line, matches[p.regex.SubexpIndex(who)], "", 1)
+	hit.Damage = EstimateDamage(message)
+	hit.Miss = hit.Damage == 0
+
+	if index := p.regex.SubexpIndex("amount"); index >= 0 && matches[index] != "" {
+		if amount, err := strconv.Atoi(matches[index]); err == nil {
+			hit.Damage = amount
+			hit.Miss = amount == 0
+		}
+	}
+
+	return hit, true
+}
+
+// EstimateDamage estimates the damage a message describes from its wording
+func EstimateDamage(message string) int {
+	for _, severity := range severities {
+		if severity.regex.MatchString(message) {
+			return severity.damage
+		}
+	}
+	return defaultDamage
+}
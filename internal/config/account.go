@@ -13,23 +13,36 @@ type Server struct {
 	Host         string `json:"host"`
 	Port         int    `json:"port"`
 	TickInterval int    `json:"tick_interval,omitempty"` // Tick interval in seconds (e.g., 60 or 75)
+	Charset      string `json:"charset,omitempty"`       // Character set: utf8, latin1 or cp437 ("" = --charset)
+	LineEnding   string `json:"line_ending,omitempty"`   // What ends each command: crlf, lf or cr ("" = --line-ending)
 }
 
 // Character represents a character on a specific server
 type Character struct {
-	Host     string `json:"host"`
-	Port     int    `json:"port"`
-	Username string `json:"username"`
+	Host      string     `json:"host"`
+	Port      int        `json:"port"`
+	Username  string     `json:"username"`
+	LoginMenu []MenuStep `json:"login_menu,omitempty"` // Menus to get through after the password
+}
+
+// MenuStep answers one menu the MUD shows after the password, e.g. a
+// numbered "1) Enter the game" menu
+type MenuStep struct {
+	Pattern  string `json:"pattern"`  // Regular expression matching a line of the menu (case doesn't matter)
+	Response string `json:"response"` // What to send when it matches, e.g. "1"
 }
 
 // Account represents a saved MUD account (legacy - kept for backward compatibility)
 // Note: Password is NOT stored in accounts.json, it's stored separately in .passwords file
 type Account struct {
-	Name     string `json:"name"`
-	Host     string `json:"host"`
-	Port     int    `json:"port"`
-	Username string `json:"username"`
-	Password string `json:"-"` // Never serialize to JSON
+	Name       string     `json:"name"`
+	Host       string     `json:"host"`
+	Port       int        `json:"port"`
+	Username   string     `json:"username"`
+	Password   string     `json:"-"`                     // Never serialize to JSON
+	LoginMenu  []MenuStep `json:"login_menu,omitempty"`  // Menus to get through after the password
+	Charset    string     `json:"charset,omitempty"`     // Character set of the server, as for Server
+	LineEnding string     `json:"line_ending,omitempty"` // Line ending of the server, as for Server
 }
 
 // Config represents the application configuration
@@ -230,6 +243,45 @@ func (c *Config) GetCharacter(username string, host string, port int) (*Characte
 	return nil, fmt.Errorf("character '%s' not found on %s:%d", username, host, port)
 }
 
+// LoginMenu returns the menu steps saved for a character, or for a legacy
+// account with the same server and username
+func (c *Config) LoginMenu(host string, port int, username string) []MenuStep {
+	if username == "" {
+		return nil
+	}
+	if character, err := c.GetCharacter(username, host, port); err == nil && len(character.LoginMenu) > 0 {
+		return character.LoginMenu
+	}
+	for _, account := range c.Accounts {
+		if account.Username == username && account.Host == host && account.Port == port && len(account.LoginMenu) > 0 {
+			return account.LoginMenu
+		}
+	}
+	return nil
+}
+
+// Encoding returns the charset and line ending saved for a server, from its
+// server entry or else a legacy account on it. Either is "" when not set.
+func (c *Config) Encoding(host string, port int) (charset, lineEnding string) {
+	for _, server := range c.Servers {
+		if server.Host == host && server.Port == port {
+			charset, lineEnding = server.Charset, server.LineEnding
+			break
+		}
+	}
+	for _, account := range c.Accounts {
+		if account.Host == host && account.Port == port {
+			if charset == "" {
+				charset = account.Charset
+			}
+			if lineEnding == "" {
+				lineEnding = account.LineEnding
+			}
+		}
+	}
+	return charset, lineEnding
+}
+
 // ListCharacters returns all saved characters
 func (c *Config) ListCharacters() []Character {
 	return c.Characters
@@ -547,3 +547,67 @@ func TestCharacterWithEmptyUsername(t *testing.T) {
 		t.Errorf("Expected empty username, got: %s", chars[0].Username)
 	}
 }
+
+func TestLoginMenu(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "accounts.json")
+	data := `{
+  "characters": [
+    {"host": "mud.test.com", "port": 4000, "username": "hero",
+     "login_menu": [{"pattern": "enter the game", "response": "1"}]}
+  ],
+  "accounts": [
+    {"name": "old", "host": "old.test.com", "port": 23, "username": "hero",
+     "login_menu": [{"pattern": "make your choice", "response": "2"}]}
+  ]
+}`
+	if err := os.WriteFile(configPath, []byte(data), 0600); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	cfg, err := LoadConfigFromPath(configPath)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	steps := cfg.LoginMenu("mud.test.com", 4000, "hero")
+	if len(steps) != 1 || steps[0].Pattern != "enter the game" || steps[0].Response != "1" {
+		t.Errorf("Expected the character's menu, got %+v", steps)
+	}
+	if steps := cfg.LoginMenu("old.test.com", 23, "hero"); len(steps) != 1 || steps[0].Response != "2" {
+		t.Errorf("Expected the legacy account's menu, got %+v", steps)
+	}
+	if steps := cfg.LoginMenu("mud.test.com", 4000, "someone"); steps != nil {
+		t.Errorf("Expected no menu for another character, got %+v", steps)
+	}
+	if steps := cfg.LoginMenu("mud.test.com", 4000, ""); steps != nil {
+		t.Errorf("Expected no menu without a username, got %+v", steps)
+	}
+}
+
+func TestEncoding(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "accounts.json")
+	data := `{
+  "servers": [
+    {"name": "Old MUD", "host": "old.test.com", "port": 4000, "charset": "cp437", "line_ending": "lf"}
+  ],
+  "accounts": [
+    {"name": "legacy", "host": "legacy.test.com", "port": 23, "username": "hero", "line_ending": "cr"}
+  ]
+}`
+	if err := os.WriteFile(configPath, []byte(data), 0600); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	cfg, err := LoadConfigFromPath(configPath)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	if charset, lineEnding := cfg.Encoding("old.test.com", 4000); charset != "cp437" || lineEnding != "lf" {
+		t.Errorf("Expected the server's settings, got %q %q", charset, lineEnding)
+	}
+	if charset, lineEnding := cfg.Encoding("legacy.test.com", 23); charset != "" || lineEnding != "cr" {
+		t.Errorf("Expected the legacy account's line ending, got %q %q", charset, lineEnding)
+	}
+	if charset, lineEnding := cfg.Encoding("other.test.com", 4000); charset != "" || lineEnding != "" {
+		t.Errorf("Expected nothing for an unknown server, got %q %q", charset, lineEnding)
+	}
+}
@@ -0,0 +1,253 @@
+// Package debuglog buffers the client's log files so that writing them
+// doesn't slow down reading from the MUD. A log is written out when enough
+// has built up, every so often, and whenever Flush is called.
+package debuglog
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/anicolao/dikuclient/internal/redact"
+)
+
+// Level is how much of the telnet processing the debug log records
+type Level int
+
+const (
+	Negotiation Level = iota + 1 // Connection events and telnet negotiation only
+	All                          // Also every buffer read from the server, in hex
+)
+
+// String returns the level's name as ParseLevel reads it
+func (l Level) String() string {
+	switch l {
+	case Negotiation:
+		return "negotiation"
+	case All:
+		return "all"
+	}
+	return fmt.Sprintf("Level(%d)", int(l))
+}
+
+// ParseLevel reads a level name: negotiation or all
+func ParseLevel(s string) (Level, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "negotiation", "neg":
+		return Negotiation, nil
+	case "all":
+		return All, nil
+	}
+	return 0, fmt.Errorf("unknown debug level %q (use negotiation or all)", s)
+}
+
+// Defaults used by New when no threshold or interval is given
+const (
+	DefaultThreshold = 32 * 1024
+	DefaultInterval  = time.Second
+)
+
+// Log is a buffered log writer safe for use from several goroutines.
+// A nil *Log discards everything, so callers need not check for one.
+type Log struct {
+	mu        sync.Mutex
+	w         io.Writer
+	buf       []byte
+	threshold int              // Bytes buffered before writing out
+	level     Level            // Most detailed level Printf records
+	err       error            // First write error, reported by Flush
+	redactor  *redact.Redactor // Hides secrets in what is written (nil = none)
+	partial   []byte           // The unfinished last line while redacting, not yet redacted
+	stop      chan struct{}
+	done      chan struct{}
+}
+
+// New creates a log writing to w once threshold bytes are buffered and every
+// interval. A threshold of 0 uses DefaultThreshold; an interval of 0 or less
+// turns off periodic flushing. The log records every level until SetLevel.
+func New(w io.Writer, threshold int, interval time.Duration) *Log {
+	if threshold <= 0 {
+		threshold = DefaultThreshold
+	}
+	l := &Log{
+		w:         w,
+		threshold: threshold,
+		level:     All,
+	}
+	if interval > 0 {
+		l.stop = make(chan struct{})
+		l.done = make(chan struct{})
+		go l.flushEvery(interval, l.stop)
+	}
+	return l
+}
+
+// flushEvery writes out the buffer on each tick until Stop is called
+func (l *Log) flushEvery(interval time.Duration, stop <-chan struct{}) {
+	defer close(l.done)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			// An unfinished line being redacted waits for its end
+			l.mu.Lock()
+			l.flushLocked()
+			l.mu.Unlock()
+		case <-stop:
+			return
+		}
+	}
+}
+
+// Level returns the most detailed level the log records
+func (l *Log) Level() Level {
+	if l == nil {
+		return 0
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.level
+}
+
+// SetLevel sets the most detailed level the log records
+func (l *Log) SetLevel(level Level) {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.level = level
+}
+
+// Enabled reports whether messages at level are recorded
+func (l *Log) Enabled(level Level) bool {
+	return l != nil && level <= l.Level()
+}
+
+// SetRedactor hides the redactor's secrets in everything written from now
+// on; nil turns redaction off
+func (l *Log) SetRedactor(r *redact.Redactor) {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.endPartialLocked()
+	l.redactor = r
+}
+
+// Redacting reports whether secrets are being hidden. Data that can't be
+// searched for them, such as hex dumps, shouldn't be written meanwhile.
+func (l *Log) Redacting() bool {
+	if l == nil {
+		return false
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.redactor != nil
+}
+
+// Printf records a message at level, if the log's level includes it
+func (l *Log) Printf(level Level, format string, args ...any) {
+	if !l.Enabled(level) {
+		return
+	}
+	fmt.Fprintf(l, format, args...)
+}
+
+// Write buffers p whatever the level, writing the buffer out once it
+// reaches the threshold
+func (l *Log) Write(p []byte) (int, error) {
+	if l == nil {
+		return len(p), nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.redactor != nil {
+		// A secret is only found when it is seen whole, and one message
+		// can be written in several pieces, so lines are redacted once
+		// they end. A line as long as the threshold is redacted anyway.
+		l.partial = append(l.partial, p...)
+		end := bytes.LastIndexByte(l.partial, '\n') + 1
+		if len(l.partial) >= l.threshold {
+			end = len(l.partial)
+		}
+		l.buf = append(l.buf, l.redactor.Redact(string(l.partial[:end]))...)
+		l.partial = append(l.partial[:0], l.partial[end:]...)
+	} else {
+		l.buf = append(l.buf, p...)
+	}
+	if len(l.buf) >= l.threshold {
+		l.flushLocked()
+	}
+	return len(p), nil
+}
+
+// Buffered returns the number of bytes waiting to be written
+func (l *Log) Buffered() int {
+	if l == nil {
+		return 0
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return len(l.buf) + len(l.partial)
+}
+
+// Flush writes out everything buffered, an unfinished line included,
+// returning the first error any write has hit
+func (l *Log) Flush() error {
+	if l == nil {
+		return nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.endPartialLocked()
+	l.flushLocked()
+	return l.err
+}
+
+// endPartialLocked redacts the unfinished line into the buffer; l.mu must
+// be held
+func (l *Log) endPartialLocked() {
+	if len(l.partial) == 0 {
+		return
+	}
+	l.buf = append(l.buf, l.redactor.Redact(string(l.partial))...)
+	l.partial = l.partial[:0]
+}
+
+// flushLocked writes the buffer to the underlying writer; l.mu must be held
+func (l *Log) flushLocked() {
+	if len(l.buf) == 0 {
+		return
+	}
+	if _, err := l.w.Write(l.buf); err != nil && l.err == nil {
+		l.err = err
+	}
+	l.buf = l.buf[:0]
+	if syncer, ok := l.w.(interface{ Sync() error }); ok {
+		syncer.Sync()
+	}
+}
+
+// Stop ends periodic flushing and writes out what is buffered. The log can
+// still be written to, but is then only flushed at the threshold or by
+// Flush. The underlying writer is left open.
+func (l *Log) Stop() error {
+	if l == nil {
+		return nil
+	}
+	l.mu.Lock()
+	stop := l.stop
+	l.stop = nil
+	l.mu.Unlock()
+	if stop != nil {
+		close(stop)
+		<-l.done
+	}
+	return l.Flush()
+}
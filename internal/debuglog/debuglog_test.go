@@ -0,0 +1,153 @@
+package debuglog
+
+import (
+	"bytes"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/anicolao/dikuclient/internal/redact"
+)
+
+// syncBuffer is a bytes.Buffer safe to read while the flusher writes to it
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestFlushesAtThreshold(t *testing.T) {
+	var out bytes.Buffer
+	l := New(&out, 16, 0)
+
+	l.Write([]byte("0123456789"))
+	if out.Len() != 0 || l.Buffered() != 10 {
+		t.Fatalf("Expected 10 bytes held below the threshold, wrote %q", out.String())
+	}
+
+	l.Write([]byte("abcdefghij"))
+	if out.String() != "0123456789abcdefghij" || l.Buffered() != 0 {
+		t.Errorf("Expected the buffer written at the threshold, got %q with %d buffered", out.String(), l.Buffered())
+	}
+}
+
+func TestExplicitFlush(t *testing.T) {
+	var out bytes.Buffer
+	l := New(&out, 0, 0)
+
+	l.Printf(Negotiation, "IAC WILL %d\n", 201)
+	if out.Len() != 0 {
+		t.Fatalf("Expected nothing written before a flush, got %q", out.String())
+	}
+	if err := l.Flush(); err != nil {
+		t.Fatalf("Flush failed: %v", err)
+	}
+	if out.String() != "IAC WILL 201\n" {
+		t.Errorf("Expected the message written by Flush, got %q", out.String())
+	}
+}
+
+func TestPeriodicFlush(t *testing.T) {
+	var out syncBuffer
+	l := New(&out, 0, 5*time.Millisecond)
+	defer l.Stop()
+
+	l.Write([]byte("tick\n"))
+	deadline := time.Now().Add(2 * time.Second)
+	for out.String() == "" {
+		if time.Now().After(deadline) {
+			t.Fatal("Expected the buffer written by the periodic flush")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestStopFlushes(t *testing.T) {
+	var out syncBuffer
+	l := New(&out, 0, time.Hour)
+	l.Write([]byte("last words\n"))
+	if err := l.Stop(); err != nil {
+		t.Fatalf("Stop failed: %v", err)
+	}
+	if out.String() != "last words\n" {
+		t.Errorf("Expected Stop to flush, got %q", out.String())
+	}
+}
+
+func TestLevel(t *testing.T) {
+	var out bytes.Buffer
+	l := New(&out, 0, 0)
+	l.SetLevel(Negotiation)
+
+	l.Printf(Negotiation, "IAC DO 69\n")
+	l.Printf(All, "Input hex: fffd45\n")
+	l.Flush()
+	if got := out.String(); got != "IAC DO 69\n" {
+		t.Errorf("Expected only negotiation recorded, got %q", got)
+	}
+
+	for input, want := range map[string]Level{"negotiation": Negotiation, "ALL": All} {
+		if got, err := ParseLevel(input); err != nil || got != want {
+			t.Errorf("ParseLevel(%q) = %v, %v, want %v", input, got, err, want)
+		}
+	}
+	if _, err := ParseLevel("verbose"); err == nil || !strings.Contains(err.Error(), "verbose") {
+		t.Errorf("Expected an error for an unknown level, got %v", err)
+	}
+}
+
+func TestNilLogDiscards(t *testing.T) {
+	var l *Log
+	l.Printf(All, "ignored\n")
+	if l.Enabled(Negotiation) || l.Flush() != nil || l.Stop() != nil {
+		t.Error("Expected a nil log to do nothing")
+	}
+}
+
+func TestRedaction(t *testing.T) {
+	var out bytes.Buffer
+	l := New(&out, 0, 0)
+	l.SetRedactor(redact.New([]string{"Gandalf", "hunter2"}))
+
+	l.Write([]byte("[12:00:00] Welcome, Gandalf! Your password hunter2 was accepted.\n"))
+	l.SetRedactor(nil)
+	l.Write([]byte("Gandalf again\n"))
+	l.Flush()
+
+	want := "[12:00:00] Welcome, [REDACTED]! Your password [REDACTED] was accepted.\nGandalf again\n"
+	if out.String() != want {
+		t.Errorf("Expected secrets replaced and other text kept, got %q", out.String())
+	}
+}
+
+func TestRedactionAcrossWrites(t *testing.T) {
+	var out bytes.Buffer
+	l := New(&out, 0, 0)
+	l.SetRedactor(redact.New([]string{"Gandalf"}))
+
+	// One line written in two pieces, split inside the secret
+	l.Write([]byte("Output string: \"Welcome, Gan"))
+	l.Write([]byte("dalf!\"\nprompt> Gand"))
+	l.Write([]byte("alf"))
+	l.Flush()
+
+	want := "Output string: \"Welcome, [REDACTED]!\"\nprompt> [REDACTED]"
+	if out.String() != want {
+		t.Errorf("Expected a secret split between writes redacted, got %q", out.String())
+	}
+	if !l.Redacting() {
+		t.Error("Expected the log to report redacting")
+	}
+}
@@ -0,0 +1,406 @@
+// Package editor is a full-screen editor for the trigger list, opened over
+// the main screen with /edit triggers. Changes are made to copies of the
+// triggers and only reach the trigger manager when the editor is saved.
+package editor
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/anicolao/dikuclient/internal/triggers"
+)
+
+// field is the part of a trigger being typed in
+type field int
+
+const (
+	fieldNone field = iota // Browsing the list
+	fieldPattern
+	fieldAction
+)
+
+// Help is the key summary shown at the bottom of the editor
+const Help = "↑/↓ select  a add  Enter edit  d delete  Space on/off  K/J move  Esc save  Ctrl+C discard"
+
+// Model is the state of the trigger editor
+type Model struct {
+	Items    []*triggers.Trigger // Copies of the triggers being edited, in order
+	cursor   int                 // Selected item
+	offset   int                 // First item shown
+	width    int
+	height   int
+	editing  field  // Field being typed in (fieldNone while browsing)
+	adding   bool   // The fields being typed are for a new trigger
+	pattern  string // Pattern typed so far while the action is edited
+	input    []rune // Text of the field being typed in
+	inputPos int    // Cursor position in input
+	message  string // Error or note shown above the help line
+	done     bool   // The editor has been closed
+	save     bool   // The editor was closed with Esc, keeping the changes
+}
+
+// New opens the editor on copies of the manager's triggers
+func New(manager *triggers.Manager, width, height int) *Model {
+	items := make([]*triggers.Trigger, len(manager.Triggers))
+	for i, trigger := range manager.Triggers {
+		item := *trigger
+		items[i] = &item
+	}
+	return &Model{Items: items, width: width, height: height}
+}
+
+// Cursor returns the index of the selected item
+func (e *Model) Cursor() int {
+	return e.cursor
+}
+
+// Done reports whether the editor has been closed
+func (e *Model) Done() bool {
+	return e.done
+}
+
+// Saved reports whether the editor was closed keeping the changes
+func (e *Model) Saved() bool {
+	return e.done && e.save
+}
+
+// SetSize sets the size of the screen the editor fills
+func (e *Model) SetSize(width, height int) {
+	e.width = width
+	e.height = height
+	e.scrollToCursor()
+}
+
+// Apply replaces the manager's triggers with the edited list
+func (e *Model) Apply(manager *triggers.Manager) {
+	manager.Replace(e.Items)
+}
+
+// Insert adds a trigger below the selected one and selects it
+func (e *Model) Insert(pattern, action string) error {
+	if strings.TrimSpace(pattern) == "" {
+		return fmt.Errorf("the pattern can't be empty")
+	}
+	trigger := &triggers.Trigger{Action: action}
+	if err := trigger.SetPattern(pattern); err != nil {
+		return err
+	}
+
+	index := 0
+	if len(e.Items) > 0 {
+		index = e.cursor + 1
+	}
+	e.Items = append(e.Items, nil)
+	copy(e.Items[index+1:], e.Items[index:])
+	e.Items[index] = trigger
+	e.cursor = index
+	e.scrollToCursor()
+	return nil
+}
+
+// Delete removes the selected trigger
+func (e *Model) Delete() {
+	if len(e.Items) == 0 {
+		return
+	}
+	e.Items = append(e.Items[:e.cursor], e.Items[e.cursor+1:]...)
+	if e.cursor >= len(e.Items) && e.cursor > 0 {
+		e.cursor--
+	}
+	e.scrollToCursor()
+}
+
+// Toggle enables or disables the selected trigger
+func (e *Model) Toggle() {
+	if len(e.Items) == 0 {
+		return
+	}
+	e.Items[e.cursor].Disabled = !e.Items[e.cursor].Disabled
+}
+
+// MoveUp swaps the selected trigger with the one above it, so it is
+// tried earlier
+func (e *Model) MoveUp() {
+	if e.cursor == 0 || len(e.Items) == 0 {
+		return
+	}
+	e.Items[e.cursor-1], e.Items[e.cursor] = e.Items[e.cursor], e.Items[e.cursor-1]
+	e.cursor--
+	e.scrollToCursor()
+}
+
+// MoveDown swaps the selected trigger with the one below it
+func (e *Model) MoveDown() {
+	if e.cursor >= len(e.Items)-1 {
+		return
+	}
+	e.Items[e.cursor+1], e.Items[e.cursor] = e.Items[e.cursor], e.Items[e.cursor+1]
+	e.cursor++
+	e.scrollToCursor()
+}
+
+// Update handles a key or resize while the editor is open
+func (e *Model) Update(msg tea.Msg) tea.Cmd {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		e.SetSize(msg.Width, msg.Height)
+	case tea.KeyMsg:
+		if e.editing != fieldNone {
+			e.handleInputKey(msg)
+		} else {
+			e.handleListKey(msg)
+		}
+	}
+	return nil
+}
+
+// handleListKey handles a key while browsing the list
+func (e *Model) handleListKey(msg tea.KeyMsg) {
+	e.message = ""
+	switch msg.String() {
+	case "up", "k":
+		e.moveTo(e.cursor - 1)
+	case "down", "j":
+		e.moveTo(e.cursor + 1)
+	case "pgup":
+		e.moveTo(e.cursor - e.listHeight())
+	case "pgdown":
+		e.moveTo(e.cursor + e.listHeight())
+	case "home", "g":
+		e.moveTo(0)
+	case "end", "G":
+		e.moveTo(len(e.Items) - 1)
+	case "a":
+		e.adding = true
+		e.startInput(fieldPattern, "")
+	case "enter", "e":
+		if len(e.Items) > 0 {
+			e.adding = false
+			e.startInput(fieldPattern, e.Items[e.cursor].Pattern)
+		}
+	case "d", "delete":
+		e.Delete()
+	case " ":
+		e.Toggle()
+	case "K", "shift+up":
+		e.MoveUp()
+	case "J", "shift+down":
+		e.MoveDown()
+	case "esc", "q":
+		e.done = true
+		e.save = true
+	case "ctrl+c":
+		e.done = true
+	}
+}
+
+// handleInputKey handles a key while typing a pattern or action. Enter
+// moves from the pattern to the action and then stores both; Esc drops
+// what was typed.
+func (e *Model) handleInputKey(msg tea.KeyMsg) {
+	switch msg.Type {
+	case tea.KeyEsc, tea.KeyCtrlC:
+		e.editing = fieldNone
+		e.message = ""
+	case tea.KeyEnter:
+		e.finishInput()
+	case tea.KeyLeft:
+		if e.inputPos > 0 {
+			e.inputPos--
+		}
+	case tea.KeyRight:
+		if e.inputPos < len(e.input) {
+			e.inputPos++
+		}
+	case tea.KeyHome, tea.KeyCtrlA:
+		e.inputPos = 0
+	case tea.KeyEnd, tea.KeyCtrlE:
+		e.inputPos = len(e.input)
+	case tea.KeyBackspace:
+		if e.inputPos > 0 {
+			e.input = append(e.input[:e.inputPos-1], e.input[e.inputPos:]...)
+			e.inputPos--
+		}
+	case tea.KeyDelete:
+		if e.inputPos < len(e.input) {
+			e.input = append(e.input[:e.inputPos], e.input[e.inputPos+1:]...)
+		}
+	case tea.KeyCtrlU:
+		e.input = e.input[e.inputPos:]
+		e.inputPos = 0
+	case tea.KeyRunes, tea.KeySpace:
+		runes := msg.Runes
+		if msg.Type == tea.KeySpace {
+			runes = []rune{' '}
+		}
+		input := make([]rune, 0, len(e.input)+len(runes))
+		input = append(input, e.input[:e.inputPos]...)
+		input = append(input, runes...)
+		input = append(input, e.input[e.inputPos:]...)
+		e.input = input
+		e.inputPos += len(runes)
+	}
+}
+
+// startInput starts typing in a field, beginning with text
+func (e *Model) startInput(f field, text string) {
+	e.editing = f
+	e.input = []rune(text)
+	e.inputPos = len(e.input)
+}
+
+// finishInput stores the field just typed: the pattern moves on to the
+// action, and the action completes the trigger
+func (e *Model) finishInput() {
+	text := string(e.input)
+	if e.editing == fieldPattern {
+		if strings.TrimSpace(text) == "" {
+			e.message = "The pattern can't be empty"
+			return
+		}
+		e.pattern = text
+		action := ""
+		if !e.adding {
+			action = e.Items[e.cursor].Action
+		}
+		e.startInput(fieldAction, action)
+		return
+	}
+
+	e.editing = fieldNone
+	if e.adding {
+		if err := e.Insert(e.pattern, text); err != nil {
+			e.message = fmt.Sprintf("Error: %v", err)
+		}
+		return
+	}
+	item := e.Items[e.cursor]
+	if err := item.SetPattern(e.pattern); err != nil {
+		e.message = fmt.Sprintf("Error: %v", err)
+		return
+	}
+	item.Action = text
+}
+
+// moveTo moves the cursor to an item, keeping it in range and on screen
+func (e *Model) moveTo(index int) {
+	if index >= len(e.Items) {
+		index = len(e.Items) - 1
+	}
+	if index < 0 {
+		index = 0
+	}
+	e.cursor = index
+	e.scrollToCursor()
+}
+
+// listHeight is the number of items that fit on screen: the rest holds
+// the title, a blank line, the input line, a message and the help
+func (e *Model) listHeight() int {
+	if e.height-5 < 1 {
+		return 1
+	}
+	return e.height - 5
+}
+
+// scrollToCursor scrolls the list so the selected item is shown
+func (e *Model) scrollToCursor() {
+	height := e.listHeight()
+	if e.cursor < e.offset {
+		e.offset = e.cursor
+	}
+	if e.cursor >= e.offset+height {
+		e.offset = e.cursor - height + 1
+	}
+	if e.offset < 0 {
+		e.offset = 0
+	}
+}
+
+// View renders the editor to fill the screen
+func (e *Model) View() string {
+	lines := []string{
+		fmt.Sprintf("\x1b[92m=== Edit Triggers (%d) ===\x1b[0m", len(e.Items)),
+		"",
+	}
+
+	height := e.listHeight()
+	if len(e.Items) == 0 {
+		lines = append(lines, "\x1b[93mNo triggers. Press a to add one.\x1b[0m")
+	}
+	for i := e.offset; i < len(e.Items) && i < e.offset+height; i++ {
+		lines = append(lines, e.renderItem(i))
+	}
+	for len(lines) < height+2 {
+		lines = append(lines, "")
+	}
+
+	switch e.editing {
+	case fieldPattern:
+		lines = append(lines, "\x1b[93mPattern:\x1b[0m "+e.renderInput())
+	case fieldAction:
+		lines = append(lines, "\x1b[93mAction:\x1b[0m "+e.renderInput())
+	default:
+		lines = append(lines, "")
+	}
+	if e.message != "" {
+		lines = append(lines, "\x1b[91m"+e.message+"\x1b[0m")
+	} else {
+		lines = append(lines, "")
+	}
+	lines = append(lines, "\x1b[90m"+truncate(Help, e.width)+"\x1b[0m")
+
+	return strings.Join(lines, "\n")
+}
+
+// renderItem renders one trigger in the list, highlighting the selected one
+func (e *Model) renderItem(index int) string {
+	item := e.Items[index]
+	state := "[x]"
+	if item.Disabled {
+		state = "[ ]"
+	}
+	text := fmt.Sprintf("%3d. %s \"%s\" -> \"%s\"", index+1, state, item.Pattern, item.Action)
+	if item.Mode == triggers.ModeGlob || item.Mode == triggers.ModeRegex {
+		text += " [" + item.Mode + "]"
+	}
+	if item.Color != "" {
+		text += fmt.Sprintf(" [%s %s]", item.Type(), item.Color)
+	} else if item.Type() != triggers.ActionCommand {
+		text += fmt.Sprintf(" [%s]", item.Type())
+	}
+	if item.Group != "" {
+		text += fmt.Sprintf(" [group %s]", item.Group)
+	}
+	text = truncate(text, e.width)
+
+	switch {
+	case index == e.cursor:
+		return "\x1b[7m" + text + "\x1b[0m"
+	case item.Disabled:
+		return "\x1b[90m" + text + "\x1b[0m"
+	default:
+		return "\x1b[96m" + text + "\x1b[0m"
+	}
+}
+
+// renderInput renders the field being typed with the cursor shown in
+// reverse video
+func (e *Model) renderInput() string {
+	before := string(e.input[:e.inputPos])
+	if e.inputPos >= len(e.input) {
+		return before + "\x1b[7m \x1b[0m"
+	}
+	return before + "\x1b[7m" + string(e.input[e.inputPos]) + "\x1b[0m" + string(e.input[e.inputPos+1:])
+}
+
+// truncate cuts text to fit in width columns (no limit when width is 0)
+func truncate(text string, width int) string {
+	runes := []rune(text)
+	if width <= 0 || len(runes) <= width {
+		return text
+	}
+	return string(runes[:width])
+}
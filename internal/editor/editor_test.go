@@ -0,0 +1,194 @@
+package editor
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/anicolao/dikuclient/internal/triggers"
+)
+
+// newTestEditor opens an editor on triggers with the given patterns
+func newTestEditor(t *testing.T, patterns ...string) (*Model, *triggers.Manager) {
+	t.Helper()
+	manager := triggers.NewManager()
+	for _, pattern := range patterns {
+		if _, err := manager.Add(pattern, "say "+pattern); err != nil {
+			t.Fatalf("Failed to add trigger: %v", err)
+		}
+	}
+	return New(manager, 80, 24), manager
+}
+
+// patterns lists the editor's items by pattern
+func patterns(e *Model) []string {
+	var list []string
+	for _, item := range e.Items {
+		list = append(list, item.Pattern)
+	}
+	return list
+}
+
+func assertPatterns(t *testing.T, e *Model, want ...string) {
+	t.Helper()
+	got := patterns(e)
+	if len(got) != len(want) {
+		t.Fatalf("Expected %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("Expected %v, got %v", want, got)
+		}
+	}
+}
+
+// typeLine sends text to the editor one key at a time, then Enter
+func typeLine(e *Model, text string) {
+	for _, r := range text {
+		e.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+	}
+	e.Update(tea.KeyMsg{Type: tea.KeyEnter})
+}
+
+func key(s string) tea.KeyMsg {
+	switch s {
+	case "enter":
+		return tea.KeyMsg{Type: tea.KeyEnter}
+	case "esc":
+		return tea.KeyMsg{Type: tea.KeyEsc}
+	case "down":
+		return tea.KeyMsg{Type: tea.KeyDown}
+	case "ctrl+c":
+		return tea.KeyMsg{Type: tea.KeyCtrlC}
+	}
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)}
+}
+
+func TestInsertAddsBelowCursor(t *testing.T) {
+	e, _ := newTestEditor(t, "one", "two")
+
+	if err := e.Insert("new", "say new"); err != nil {
+		t.Fatalf("Failed to insert: %v", err)
+	}
+	assertPatterns(t, e, "one", "new", "two")
+	if e.Cursor() != 1 {
+		t.Errorf("Expected the new trigger to be selected, got %d", e.Cursor())
+	}
+
+	if err := e.Insert("  ", "say nothing"); err == nil {
+		t.Error("Expected an error for an empty pattern")
+	}
+
+	empty, _ := newTestEditor(t)
+	if err := empty.Insert("first", "say first"); err != nil {
+		t.Fatalf("Failed to insert into an empty list: %v", err)
+	}
+	assertPatterns(t, empty, "first")
+}
+
+func TestDeleteRemovesSelected(t *testing.T) {
+	e, _ := newTestEditor(t, "one", "two", "three")
+
+	e.Update(key("down"))
+	e.Delete()
+	assertPatterns(t, e, "one", "three")
+
+	e.Update(key("down"))
+	e.Delete()
+	assertPatterns(t, e, "one")
+	if e.Cursor() != 0 {
+		t.Errorf("Expected the cursor to move up after deleting the last item, got %d", e.Cursor())
+	}
+
+	e.Delete()
+	e.Delete()
+	assertPatterns(t, e)
+}
+
+func TestMoveReorders(t *testing.T) {
+	e, _ := newTestEditor(t, "one", "two", "three")
+
+	e.MoveDown()
+	assertPatterns(t, e, "two", "one", "three")
+	e.MoveDown()
+	assertPatterns(t, e, "two", "three", "one")
+	e.MoveDown()
+	assertPatterns(t, e, "two", "three", "one")
+	if e.Cursor() != 2 {
+		t.Errorf("Expected the cursor to follow the moved item, got %d", e.Cursor())
+	}
+
+	e.Update(key("K"))
+	e.Update(key("K"))
+	e.Update(key("K"))
+	assertPatterns(t, e, "one", "two", "three")
+}
+
+func TestToggleDisables(t *testing.T) {
+	e, _ := newTestEditor(t, "one")
+
+	e.Update(key(" "))
+	if !e.Items[0].Disabled {
+		t.Error("Expected Space to disable the trigger")
+	}
+	e.Toggle()
+	if e.Items[0].Disabled {
+		t.Error("Expected a second toggle to enable it again")
+	}
+}
+
+func TestAddAndEditWithKeys(t *testing.T) {
+	e, _ := newTestEditor(t, "one")
+
+	e.Update(key("a"))
+	typeLine(e, "<who> smiles")
+	typeLine(e, "smile <who>")
+	assertPatterns(t, e, "one", "<who> smiles")
+	if e.Items[1].Action != "smile <who>" {
+		t.Errorf("Expected the typed action, got %q", e.Items[1].Action)
+	}
+
+	// Editing starts from the current text
+	e.Update(key("enter"))
+	e.Update(tea.KeyMsg{Type: tea.KeyBackspace})
+	typeLine(e, "S")
+	e.Update(tea.KeyMsg{Type: tea.KeyCtrlU})
+	typeLine(e, "grin")
+	if e.Items[1].Pattern != "<who> smileS" || e.Items[1].Action != "grin" {
+		t.Errorf("Expected the edited trigger, got %q -> %q", e.Items[1].Pattern, e.Items[1].Action)
+	}
+
+	// Esc while typing drops the edit
+	e.Update(key("enter"))
+	typeLine(e, "x")
+	e.Update(key("esc"))
+	if e.Items[1].Pattern != "<who> smileS" || e.Done() {
+		t.Errorf("Expected Esc to drop the edit only, got %q", e.Items[1].Pattern)
+	}
+}
+
+func TestCloseSavesOrDiscards(t *testing.T) {
+	e, manager := newTestEditor(t, "one", "two")
+	e.MoveDown()
+	e.Toggle()
+
+	// The manager is untouched until the editor is applied
+	if manager.Triggers[0].Pattern != "one" || manager.Triggers[1].Disabled {
+		t.Fatal("Expected the manager to be unchanged while editing")
+	}
+
+	e.Update(key("esc"))
+	if !e.Saved() {
+		t.Fatal("Expected Esc to close and save")
+	}
+	e.Apply(manager)
+	if manager.Triggers[0].Pattern != "two" || !manager.Triggers[1].Disabled {
+		t.Errorf("Expected the edits in the manager, got %+v", manager.Triggers)
+	}
+
+	discard, _ := newTestEditor(t, "one")
+	discard.Update(key("ctrl+c"))
+	if !discard.Done() || discard.Saved() {
+		t.Error("Expected Ctrl+C to close without saving")
+	}
+}
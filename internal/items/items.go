@@ -0,0 +1,273 @@
+package items
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Item is an object whose stats were seen when it was identified
+type Item struct {
+	Name       string    `json:"name"`       // Item name as the MUD shows it (e.g., "a long sword")
+	Stats      []string  `json:"stats"`      // Lines of identify/examine output, without ANSI codes
+	Remembered time.Time `json:"remembered"` // When the stats were stored
+}
+
+// Manager is the database of remembered items
+type Manager struct {
+	Items    []*Item `json:"items"`
+	filePath string  // Path to items.json (not serialized)
+
+	capturing *Item // Identify output being read, nil when not in one
+}
+
+// objectPatterns match the first line of identify output and capture the
+// item name
+var objectPatterns = []*regexp.Regexp{
+	// CircleMUD/DikuMUD: Object 'a long sword', Item type: WEAPON
+	regexp.MustCompile(`^Object '(.+?)', Item type:`),
+	// ROM/Merc: Object 'sword long' is type weapon, extra flags none.
+	regexp.MustCompile(`^Object '(.+?)' is type `),
+}
+
+// countSuffix matches the "[2]" or "(2)" some MUDs add to stacked inventory items
+var countSuffix = regexp.MustCompile(`\s*[\[(]\d+[\])]$`)
+
+// flagPrefix matches flags such as "(glowing)" or "(Magic)" shown before an
+// item's name in inventory lists
+var flagPrefix = regexp.MustCompile(`^(\([^)]*\)\s*)+`)
+
+// NewManager creates an empty item database
+func NewManager() *Manager {
+	return &Manager{
+		Items: make([]*Item, 0),
+	}
+}
+
+// GetItemsPath returns the path to the items file
+func GetItemsPath() (string, error) {
+	var configDir string
+
+	// Check for environment variable override
+	if envConfigDir := os.Getenv("DIKUCLIENT_CONFIG_DIR"); envConfigDir != "" {
+		configDir = envConfigDir
+	} else {
+		homeDir, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("failed to get home directory: %w", err)
+		}
+		configDir = filepath.Join(homeDir, ".config", "dikuclient")
+	}
+
+	if err := os.MkdirAll(configDir, 0700); err != nil {
+		return "", fmt.Errorf("failed to create config directory: %w", err)
+	}
+
+	return filepath.Join(configDir, "items.json"), nil
+}
+
+// Load loads the item database from disk
+func Load() (*Manager, error) {
+	itemsPath, err := GetItemsPath()
+	if err != nil {
+		return nil, err
+	}
+
+	return LoadFromPath(itemsPath)
+}
+
+// LoadFromPath loads the item database from a specific path (useful for testing)
+func LoadFromPath(itemsPath string) (*Manager, error) {
+	data, err := os.ReadFile(itemsPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			// Return empty manager if file doesn't exist
+			m := NewManager()
+			m.filePath = itemsPath
+			return m, nil
+		}
+		return nil, fmt.Errorf("failed to read items file: %w", err)
+	}
+
+	var m Manager
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("failed to parse items file: %w", err)
+	}
+	if m.Items == nil {
+		m.Items = make([]*Item, 0)
+	}
+	m.filePath = itemsPath
+
+	return &m, nil
+}
+
+// Save saves the item database to disk
+func (m *Manager) Save() error {
+	itemsPath := m.filePath
+	if itemsPath == "" {
+		var err error
+		itemsPath, err = GetItemsPath()
+		if err != nil {
+			return err
+		}
+		m.filePath = itemsPath
+	}
+
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal items: %w", err)
+	}
+
+	if err := os.WriteFile(itemsPath, data, 0600); err != nil {
+		return fmt.Errorf("failed to write items file: %w", err)
+	}
+
+	return nil
+}
+
+// normalizeName reduces an item name to the form items are keyed by:
+// lower case, single spaces, without inventory flags and counts
+func normalizeName(name string) string {
+	name = strings.TrimSpace(name)
+	name = countSuffix.ReplaceAllString(name, "")
+	name = flagPrefix.ReplaceAllString(name, "")
+	return strings.ToLower(strings.Join(strings.Fields(name), " "))
+}
+
+// Remember stores an item's stats, replacing any stats already stored under
+// the same name
+func (m *Manager) Remember(name string, stats []string, now time.Time) (*Item, error) {
+	name = strings.TrimSpace(name)
+	if normalizeName(name) == "" {
+		return nil, fmt.Errorf("item name cannot be empty")
+	}
+	if len(stats) == 0 {
+		return nil, fmt.Errorf("no stats to remember for '%s'", name)
+	}
+
+	if item := m.Get(name); item != nil {
+		item.Name = name
+		item.Stats = stats
+		item.Remembered = now
+		return item, nil
+	}
+
+	item := &Item{
+		Name:       name,
+		Stats:      stats,
+		Remembered: now,
+	}
+	m.Items = append(m.Items, item)
+	return item, nil
+}
+
+// Get returns the item stored under name, or nil if it isn't known. Flags
+// and counts from an inventory line are ignored, so an inventory line can be
+// passed as is.
+func (m *Manager) Get(name string) *Item {
+	key := normalizeName(name)
+	if key == "" {
+		return nil
+	}
+	for _, item := range m.Items {
+		if normalizeName(item.Name) == key {
+			return item
+		}
+	}
+	return nil
+}
+
+// Find returns the item named query, or else every item whose name contains
+// all of the words in query, sorted by name. An empty query returns every item.
+func (m *Manager) Find(query string) []*Item {
+	if item := m.Get(query); item != nil {
+		return []*Item{item}
+	}
+
+	words := strings.Fields(normalizeName(query))
+	var found []*Item
+	for _, item := range m.Items {
+		name := normalizeName(item.Name)
+		matches := true
+		for _, word := range words {
+			if !strings.Contains(name, word) {
+				matches = false
+				break
+			}
+		}
+		if matches {
+			found = append(found, item)
+		}
+	}
+	sort.Slice(found, func(i, j int) bool {
+		return normalizeName(found[i].Name) < normalizeName(found[j].Name)
+	})
+	return found
+}
+
+// Forget removes the item stored under name
+func (m *Manager) Forget(name string) bool {
+	key := normalizeName(name)
+	for i, item := range m.Items {
+		if normalizeName(item.Name) == key {
+			m.Items = append(m.Items[:i], m.Items[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// ParseObjectName returns the item name from the first line of identify
+// output
+func ParseObjectName(line string) (string, bool) {
+	line = strings.TrimSpace(line)
+	for _, pattern := range objectPatterns {
+		if matches := pattern.FindStringSubmatch(line); matches != nil {
+			return matches[1], true
+		}
+	}
+	return "", false
+}
+
+// ProcessLine feeds one line of MUD output (without ANSI codes) to the
+// identify capture. Identify output runs from its "Object '...'" line to the
+// next blank line or prompt. When it ends, the item is remembered and
+// returned.
+func (m *Manager) ProcessLine(line string, isPrompt bool, now time.Time) *Item {
+	line = strings.TrimRight(line, " \t\r")
+
+	if name, ok := ParseObjectName(line); ok {
+		finished := m.finishCapture(now)
+		m.capturing = &Item{Name: name, Stats: []string{strings.TrimSpace(line)}}
+		return finished
+	}
+
+	if m.capturing == nil {
+		return nil
+	}
+	if isPrompt || strings.TrimSpace(line) == "" {
+		return m.finishCapture(now)
+	}
+	m.capturing.Stats = append(m.capturing.Stats, line)
+	return nil
+}
+
+// finishCapture remembers the item being captured, if any
+func (m *Manager) finishCapture(now time.Time) *Item {
+	if m.capturing == nil {
+		return nil
+	}
+	captured := m.capturing
+	m.capturing = nil
+
+	item, err := m.Remember(captured.Name, captured.Stats, now)
+	if err != nil {
+		return nil
+	}
+	return item
+}
@@ -0,0 +1,140 @@
+package items
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+var identifyOutput = []string{
+	"You feel informed:",
+	"Object 'a long sword', Item type: WEAPON",
+	"Item will give you following abilities:  NOBITS",
+	"Item is: MAGIC",
+	"Weight: 8, Value: 600, Rent: 60",
+	"Damage Dice is '2D4' for an average per-round damage of 5.0.",
+	"Can affect you as :",
+	"   Affects: HITROLL By 2",
+}
+
+func TestIdentifyOutputIsRemembered(t *testing.T) {
+	m := NewManager()
+	now := time.Now()
+
+	for _, line := range identifyOutput {
+		if item := m.ProcessLine(line, false, now); item != nil {
+			t.Fatalf("Expected capture to continue, finished on %q", line)
+		}
+	}
+	item := m.ProcessLine("101H 132V 1000X 50C Exits:NS>", true, now)
+	if item == nil {
+		t.Fatal("Expected the prompt to finish the capture")
+	}
+	if item.Name != "a long sword" {
+		t.Errorf("Expected name 'a long sword', got %q", item.Name)
+	}
+	if len(item.Stats) != len(identifyOutput)-1 || item.Stats[0] != identifyOutput[1] || item.Stats[len(item.Stats)-1] != "   Affects: HITROLL By 2" {
+		t.Errorf("Expected the identify lines as stats, got %q", item.Stats)
+	}
+
+	// Lines after the capture aren't added to it
+	m.ProcessLine("A goblin arrives.", false, now)
+	if got := m.Get("a long sword"); got == nil || len(got.Stats) != len(identifyOutput)-1 {
+		t.Errorf("Expected stats to be unchanged after the prompt, got %+v", got)
+	}
+}
+
+func TestROMIdentifyEndsOnBlankLine(t *testing.T) {
+	m := NewManager()
+	now := time.Now()
+
+	m.ProcessLine("Object 'shield kite' is type armor, extra flags none.", false, now)
+	m.ProcessLine("Weight is 10, value is 120, level is 5.", false, now)
+	m.ProcessLine("Armor class is 4 pierce, 4 bash, 4 slash, and 0 vs. magic.", false, now)
+	item := m.ProcessLine("", false, now)
+	if item == nil || item.Name != "shield kite" || len(item.Stats) != 3 {
+		t.Fatalf("Expected the shield to be remembered with 3 lines, got %+v", item)
+	}
+}
+
+func TestGetMatchesInventoryLines(t *testing.T) {
+	m := NewManager()
+	if _, err := m.Remember("a long sword", []string{"Weight: 8"}, time.Now()); err != nil {
+		t.Fatalf("Failed to remember item: %v", err)
+	}
+
+	for _, line := range []string{"a long sword", "A Long  Sword", "(Glowing) (Humming) a long sword", "a long sword [2]", "a long sword (3)"} {
+		if m.Get(line) == nil {
+			t.Errorf("Expected %q to match the remembered sword", line)
+		}
+	}
+	if m.Get("a short sword") != nil {
+		t.Error("Expected a different item not to match")
+	}
+}
+
+func TestRememberReplacesAndFind(t *testing.T) {
+	m := NewManager()
+	now := time.Now()
+
+	m.Remember("a long sword", []string{"old"}, now)
+	m.Remember("A long sword", []string{"new"}, now)
+	m.Remember("a short sword", []string{"short"}, now)
+	m.Remember("a wooden shield", []string{"shield"}, now)
+
+	if len(m.Items) != 3 {
+		t.Fatalf("Expected the second long sword to replace the first, got %d items", len(m.Items))
+	}
+	if item := m.Get("a long sword"); item.Stats[0] != "new" {
+		t.Errorf("Expected the latest stats, got %q", item.Stats)
+	}
+
+	if found := m.Find("sword"); len(found) != 2 || found[0].Name != "A long sword" || found[1].Name != "a short sword" {
+		t.Errorf("Expected both swords sorted by name, got %+v", found)
+	}
+	if found := m.Find("a short sword"); len(found) != 1 {
+		t.Errorf("Expected an exact match to be the only result, got %d", len(found))
+	}
+	if found := m.Find(""); len(found) != 3 || found[2].Name != "a wooden shield" {
+		t.Errorf("Expected every item for an empty query, got %+v", found)
+	}
+	if found := m.Find("axe"); len(found) != 0 {
+		t.Errorf("Expected no matches, got %d", len(found))
+	}
+
+	if _, err := m.Remember("  ", []string{"x"}, now); err == nil {
+		t.Error("Expected an empty name to be rejected")
+	}
+	if _, err := m.Remember("an axe", nil, now); err == nil {
+		t.Error("Expected empty stats to be rejected")
+	}
+
+	if !m.Forget("a short sword") || m.Get("a short sword") != nil {
+		t.Error("Expected the short sword to be forgotten")
+	}
+	if m.Forget("a short sword") {
+		t.Error("Expected forgetting an unknown item to fail")
+	}
+}
+
+func TestSaveAndLoad(t *testing.T) {
+	itemsPath := filepath.Join(t.TempDir(), "items.json")
+
+	m, err := LoadFromPath(itemsPath)
+	if err != nil {
+		t.Fatalf("Failed to load missing file: %v", err)
+	}
+	m.Remember("a long sword", []string{"Object 'a long sword', Item type: WEAPON", "Weight: 8"}, time.Now())
+	if err := m.Save(); err != nil {
+		t.Fatalf("Failed to save items: %v", err)
+	}
+
+	loaded, err := LoadFromPath(itemsPath)
+	if err != nil {
+		t.Fatalf("Failed to load items: %v", err)
+	}
+	item := loaded.Get("a long sword")
+	if item == nil || len(item.Stats) != 2 || item.Stats[1] != "Weight: 8" {
+		t.Errorf("Expected the sword's stats to be loaded, got %+v", item)
+	}
+}
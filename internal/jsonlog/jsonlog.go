@@ -0,0 +1,97 @@
+package jsonlog
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/anicolao/dikuclient/internal/redact"
+)
+
+// Kinds of MUD output line
+const (
+	Room   = "room"   // Part of a room description (the exits line)
+	Prompt = "prompt" // The status prompt
+	Tell   = "tell"   // A tell from another player
+	Combat = "combat" // Damage, deaths and experience
+	Other  = "other"  // Anything else
+)
+
+// Entry is one line of MUD output
+type Entry struct {
+	TS       time.Time `json:"ts"`
+	Raw      string    `json:"raw"`      // As received, with ANSI codes
+	Stripped string    `json:"stripped"` // Without ANSI codes
+	Kind     string    `json:"kind"`
+}
+
+// Logger writes MUD output as one JSON object per line
+type Logger struct {
+	mu       sync.Mutex
+	file     *os.File
+	encoder  *json.Encoder
+	path     string
+	redactor *redact.Redactor // Hides secrets in each entry (nil = none)
+}
+
+// Create opens a JSON lines log at path, appending if it already exists
+func Create(path string) (*Logger, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open JSON log: %w", err)
+	}
+
+	encoder := json.NewEncoder(file)
+	// Keep <, > and & readable; MUD output is full of them
+	encoder.SetEscapeHTML(false)
+
+	return &Logger{
+		file:    file,
+		encoder: encoder,
+		path:    path,
+	}, nil
+}
+
+// Path returns the file being written
+func (l *Logger) Path() string {
+	return l.path
+}
+
+// SetRedactor hides the redactor's secrets in entries written from now on;
+// nil turns redaction off
+func (l *Logger) SetRedactor(r *redact.Redactor) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.redactor = r
+}
+
+// Write appends an entry to the log
+func (l *Logger) Write(entry Entry) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.file == nil {
+		return fmt.Errorf("JSON log is closed")
+	}
+	entry.Raw = l.redactor.Redact(entry.Raw)
+	entry.Stripped = l.redactor.Redact(entry.Stripped)
+	if err := l.encoder.Encode(entry); err != nil {
+		return fmt.Errorf("failed to write JSON log: %w", err)
+	}
+	return nil
+}
+
+// Close closes the log file
+func (l *Logger) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.file == nil {
+		return nil
+	}
+	err := l.file.Close()
+	l.file = nil
+	return err
+}
@@ -0,0 +1,99 @@
+package jsonlog
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/anicolao/dikuclient/internal/redact"
+)
+
+func TestLoggerWritesOneObjectPerLine(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "output.jsonl")
+	logger, err := Create(path)
+	if err != nil {
+		t.Fatalf("Failed to create log: %v", err)
+	}
+
+	ts := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	entries := []Entry{
+		{TS: ts, Raw: "\x1b[33mTemple Square\x1b[0m", Stripped: "Temple Square", Kind: Other},
+		{TS: ts, Raw: "101H 132V <Exits:NS>", Stripped: "101H 132V <Exits:NS>", Kind: Prompt},
+	}
+	for _, entry := range entries {
+		if err := logger.Write(entry); err != nil {
+			t.Fatalf("Failed to write entry: %v", err)
+		}
+	}
+	if err := logger.Close(); err != nil {
+		t.Fatalf("Failed to close log: %v", err)
+	}
+	if err := logger.Write(entries[0]); err == nil {
+		t.Error("Expected writing to a closed log to fail")
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read log: %v", err)
+	}
+	if strings.Contains(string(data), `\u003c`) {
+		t.Error("Expected < and > to be written as is")
+	}
+
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != len(entries) {
+		t.Fatalf("Expected %d lines, got %d:\n%s", len(entries), len(lines), data)
+	}
+	for i, line := range lines {
+		var got Entry
+		if err := json.Unmarshal([]byte(line), &got); err != nil {
+			t.Fatalf("Line %d is not JSON: %v", i+1, err)
+		}
+		if got != entries[i] {
+			t.Errorf("Line %d = %+v, want %+v", i+1, got, entries[i])
+		}
+	}
+
+	// Starting again appends to the same file
+	logger, err = Create(path)
+	if err != nil {
+		t.Fatalf("Failed to reopen log: %v", err)
+	}
+	logger.Write(entries[0])
+	logger.Close()
+	data, _ = os.ReadFile(path)
+	if n := strings.Count(string(data), "\n"); n != 3 {
+		t.Errorf("Expected 3 lines after reopening, got %d", n)
+	}
+}
+
+func TestLoggerRedacts(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "output.jsonl")
+	logger, err := Create(path)
+	if err != nil {
+		t.Fatalf("Failed to create log: %v", err)
+	}
+	logger.SetRedactor(redact.New([]string{"Gandalf"}))
+	logger.Write(Entry{
+		TS:       time.Now(),
+		Raw:      "\x1b[36mGandalf tells you 'hi'\x1b[0m",
+		Stripped: "Gandalf tells you 'hi'",
+		Kind:     Tell,
+	})
+	logger.Close()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read log: %v", err)
+	}
+	var got Entry
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("Log is not JSON: %v", err)
+	}
+	if got.Stripped != "[REDACTED] tells you 'hi'" || got.Raw != "\x1b[36m[REDACTED] tells you 'hi'\x1b[0m" {
+		t.Errorf("Expected the name redacted and the rest kept, got %+v", got)
+	}
+}
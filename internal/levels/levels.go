@@ -0,0 +1,175 @@
+// Package levels records when the character gained each level, so /levels
+// can show how long levels take.
+package levels
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Milestone is one level gained
+type Milestone struct {
+	Level     int       `json:"level"`      // Level reached (0 when the MUD didn't say)
+	Time      time.Time `json:"time"`       // When the level was gained
+	SessionXP int       `json:"session_xp"` // XP gained in the session up to the level
+}
+
+// Log is the persistent list of levels gained, oldest first
+type Log struct {
+	Milestones []Milestone `json:"milestones"`
+	filePath   string      // Path to levels.json (not serialized)
+}
+
+// sameLevelUp is how close together two level-up messages must be to be
+// taken as one level, for MUDs that announce a level on two lines
+const sameLevelUp = 5 * time.Second
+
+// levelUpPattern matches a level-up message without the level number, such
+// as "You raise a level!"
+var levelUpPattern = regexp.MustCompile(`(?i)\byou (?:raise|rise|gain|have gained|advance|go up) (?:a|one) level\b`)
+
+// levelNumberPatterns match a level-up message with the new level
+var levelNumberPatterns = []*regexp.Regexp{
+	// You are now level 25.
+	regexp.MustCompile(`(?i)\byou are now (?:at )?level (\d+)\b`),
+	// Welcome to level 25!
+	regexp.MustCompile(`(?i)^welcome to level (\d+)\b`),
+	// You have reached level 25.
+	regexp.MustCompile(`(?i)\byou have (?:reached|attained|advanced to) level (\d+)\b`),
+}
+
+// ParseLevelUp checks a line for a level-up message, returning the new level
+// or 0 when the message doesn't say which level it is
+func ParseLevelUp(line string) (int, bool) {
+	line = strings.TrimSpace(line)
+	for _, pattern := range levelNumberPatterns {
+		if matches := pattern.FindStringSubmatch(line); matches != nil {
+			level, err := strconv.Atoi(matches[1])
+			if err == nil {
+				return level, true
+			}
+		}
+	}
+	if levelUpPattern.MatchString(line) {
+		return 0, true
+	}
+	return 0, false
+}
+
+// NewLog creates an empty level log
+func NewLog() *Log {
+	return &Log{}
+}
+
+// GetLevelsPath returns the path to the level log file
+func GetLevelsPath() (string, error) {
+	var configDir string
+
+	// Check for environment variable override
+	if envConfigDir := os.Getenv("DIKUCLIENT_CONFIG_DIR"); envConfigDir != "" {
+		configDir = envConfigDir
+	} else {
+		homeDir, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("failed to get home directory: %w", err)
+		}
+		configDir = filepath.Join(homeDir, ".config", "dikuclient")
+	}
+
+	if err := os.MkdirAll(configDir, 0700); err != nil {
+		return "", fmt.Errorf("failed to create config directory: %w", err)
+	}
+
+	return filepath.Join(configDir, "levels.json"), nil
+}
+
+// Load loads the level log from disk
+func Load() (*Log, error) {
+	levelsPath, err := GetLevelsPath()
+	if err != nil {
+		return nil, err
+	}
+
+	return LoadFromPath(levelsPath)
+}
+
+// LoadFromPath loads the level log from a specific path (useful for testing)
+func LoadFromPath(levelsPath string) (*Log, error) {
+	data, err := os.ReadFile(levelsPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			// Return an empty log if the file doesn't exist
+			l := NewLog()
+			l.filePath = levelsPath
+			return l, nil
+		}
+		return nil, fmt.Errorf("failed to read level log: %w", err)
+	}
+
+	var l Log
+	if err := json.Unmarshal(data, &l); err != nil {
+		return nil, fmt.Errorf("failed to parse level log: %w", err)
+	}
+	l.filePath = levelsPath
+	return &l, nil
+}
+
+// Save saves the level log to disk
+func (l *Log) Save() error {
+	if l.filePath == "" {
+		return fmt.Errorf("no file path set for level log")
+	}
+
+	data, err := json.MarshalIndent(l, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal level log: %w", err)
+	}
+
+	if err := os.WriteFile(l.filePath, data, 0600); err != nil {
+		return fmt.Errorf("failed to write level log: %w", err)
+	}
+
+	return nil
+}
+
+// Record adds a level gained. A level of 0 is taken to be one more than the
+// last level recorded. A second message right after the first is the same
+// level up, and only fills in the level. Returns the milestone recorded.
+func (l *Log) Record(level int, now time.Time, sessionXP int) Milestone {
+	if n := len(l.Milestones); n > 0 {
+		last := &l.Milestones[n-1]
+		if now.Sub(last.Time) < sameLevelUp {
+			if level != 0 {
+				last.Level = level
+			}
+			return *last
+		}
+		if level == 0 && last.Level != 0 {
+			level = last.Level + 1
+		}
+	}
+
+	milestone := Milestone{Level: level, Time: now, SessionXP: sessionXP}
+	l.Milestones = append(l.Milestones, milestone)
+	return milestone
+}
+
+// AverageTime returns the average time between the last count levels, or 0
+// when fewer than two levels have been recorded
+func (l *Log) AverageTime(count int) time.Duration {
+	n := len(l.Milestones)
+	if n < 2 {
+		return 0
+	}
+	if count > n-1 {
+		count = n - 1
+	}
+	first, last := l.Milestones[n-1-count], l.Milestones[n-1]
+	return last.Time.Sub(first.Time) / time.Duration(count)
+}
@@ -0,0 +1,78 @@
+package levels
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestParseLevelUp(t *testing.T) {
+	tests := []struct {
+		line  string
+		level int
+		ok    bool
+	}{
+		{"You raise a level! You are now level 25.", 25, true},
+		{"You raise a level!", 0, true},
+		{"You rise a level!", 0, true},
+		{"Welcome to level 12!", 12, true},
+		{"You have reached level 30.", 30, true},
+		{"You are now level 7.", 7, true},
+		{"Bob raises a level.", 0, false},
+		{"You receive 250 experience.", 0, false},
+	}
+
+	for _, tt := range tests {
+		level, ok := ParseLevelUp(tt.line)
+		if ok != tt.ok || level != tt.level {
+			t.Errorf("ParseLevelUp(%q) = (%d, %v), want (%d, %v)", tt.line, level, ok, tt.level, tt.ok)
+		}
+	}
+}
+
+func TestRecord(t *testing.T) {
+	l := NewLog()
+	start := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	l.Record(24, start, 1000)
+	l.Record(0, start.Add(time.Hour), 5000)
+	if got := l.Milestones[1]; got.Level != 25 || got.SessionXP != 5000 || !got.Time.Equal(start.Add(time.Hour)) {
+		t.Errorf("Expected level 25 after level 24, got %+v", got)
+	}
+
+	// A second message for the same level up only fills in the level
+	l.Record(0, start.Add(3*time.Hour), 9000)
+	l.Record(27, start.Add(3*time.Hour+time.Second), 9000)
+	if len(l.Milestones) != 3 || l.Milestones[2].Level != 27 {
+		t.Errorf("Expected the two messages recorded as level 27, got %+v", l.Milestones)
+	}
+
+	if got := l.AverageTime(5); got != 90*time.Minute {
+		t.Errorf("AverageTime(5) = %v, want 1h30m", got)
+	}
+	if got := l.AverageTime(1); got != 2*time.Hour {
+		t.Errorf("AverageTime(1) = %v, want 2h", got)
+	}
+}
+
+func TestSaveAndLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "levels.json")
+	l, err := LoadFromPath(path)
+	if err != nil {
+		t.Fatalf("LoadFromPath() error = %v", err)
+	}
+
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	l.Record(10, now, 1234)
+	if err := l.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	loaded, err := LoadFromPath(path)
+	if err != nil {
+		t.Fatalf("LoadFromPath() error = %v", err)
+	}
+	if len(loaded.Milestones) != 1 || loaded.Milestones[0].Level != 10 || loaded.Milestones[0].SessionXP != 1234 || !loaded.Milestones[0].Time.Equal(now) {
+		t.Errorf("Expected the milestone to be saved, got %+v", loaded.Milestones)
+	}
+}
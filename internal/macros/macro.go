@@ -0,0 +1,167 @@
+package macros
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Macro binds a key to a command
+type Macro struct {
+	Key     string `json:"key"`     // Key name as reported by the terminal (e.g., "f1", "alt+1")
+	Command string `json:"command"` // Command to run (may contain ';' and aliases)
+}
+
+// Manager manages all macros
+type Manager struct {
+	Macros   []*Macro `json:"macros"`
+	filePath string   // Path to macros.json (not serialized)
+}
+
+// keyPattern matches keys that can be bound: function keys F1-F20, and
+// alt+<key> combinations. Terminals report numpad keys as the ordinary
+// digits, so alt+digit is the way to bind a numpad-style key.
+var keyPattern = regexp.MustCompile(`^(f([1-9]|1[0-9]|20)|alt\+(f([1-9]|1[0-9]|20)|[a-z0-9]))$`)
+
+// NewManager creates a new macro manager
+func NewManager() *Manager {
+	return &Manager{
+		Macros: make([]*Macro, 0),
+	}
+}
+
+// GetMacrosPath returns the path to the macros file
+func GetMacrosPath() (string, error) {
+	var configDir string
+
+	// Check for environment variable override
+	if envConfigDir := os.Getenv("DIKUCLIENT_CONFIG_DIR"); envConfigDir != "" {
+		configDir = envConfigDir
+	} else {
+		homeDir, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("failed to get home directory: %w", err)
+		}
+		configDir = filepath.Join(homeDir, ".config", "dikuclient")
+	}
+
+	if err := os.MkdirAll(configDir, 0700); err != nil {
+		return "", fmt.Errorf("failed to create config directory: %w", err)
+	}
+
+	return filepath.Join(configDir, "macros.json"), nil
+}
+
+// Load loads macros from disk
+func Load() (*Manager, error) {
+	macrosPath, err := GetMacrosPath()
+	if err != nil {
+		return nil, err
+	}
+
+	return LoadFromPath(macrosPath)
+}
+
+// LoadFromPath loads macros from a specific path (useful for testing)
+func LoadFromPath(macrosPath string) (*Manager, error) {
+	data, err := os.ReadFile(macrosPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			// Return empty manager if file doesn't exist
+			m := NewManager()
+			m.filePath = macrosPath
+			return m, nil
+		}
+		return nil, fmt.Errorf("failed to read macros file: %w", err)
+	}
+
+	var m Manager
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("failed to parse macros file: %w", err)
+	}
+	m.filePath = macrosPath
+
+	return &m, nil
+}
+
+// Save saves macros to disk
+func (m *Manager) Save() error {
+	macrosPath := m.filePath
+	if macrosPath == "" {
+		var err error
+		macrosPath, err = GetMacrosPath()
+		if err != nil {
+			return err
+		}
+		m.filePath = macrosPath
+	}
+
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal macros: %w", err)
+	}
+
+	if err := os.WriteFile(macrosPath, data, 0600); err != nil {
+		return fmt.Errorf("failed to write macros file: %w", err)
+	}
+
+	return nil
+}
+
+// NormalizeKey converts a user-supplied key name (e.g., "F1", "Alt+8") to the
+// form reported by the terminal, or returns an error if it can't be bound
+func NormalizeKey(key string) (string, error) {
+	normalized := strings.ToLower(strings.TrimSpace(key))
+	if !keyPattern.MatchString(normalized) {
+		return "", fmt.Errorf("cannot bind '%s' (use F1-F20 or Alt+<key>)", key)
+	}
+	return normalized, nil
+}
+
+// Set binds a key to a command, replacing any existing binding for that key
+func (m *Manager) Set(key, command string) (*Macro, error) {
+	normalized, err := NormalizeKey(key)
+	if err != nil {
+		return nil, err
+	}
+
+	command = strings.TrimSpace(command)
+	if command == "" {
+		return nil, fmt.Errorf("macro command cannot be empty")
+	}
+
+	if macro := m.Get(normalized); macro != nil {
+		macro.Command = command
+		return macro, nil
+	}
+
+	macro := &Macro{
+		Key:     normalized,
+		Command: command,
+	}
+	m.Macros = append(m.Macros, macro)
+	return macro, nil
+}
+
+// Get returns the macro bound to a key, or nil if the key is unbound
+func (m *Manager) Get(key string) *Macro {
+	for _, macro := range m.Macros {
+		if macro.Key == key {
+			return macro
+		}
+	}
+	return nil
+}
+
+// Remove removes a macro by index (0-based)
+func (m *Manager) Remove(index int) error {
+	if index < 0 || index >= len(m.Macros) {
+		return fmt.Errorf("invalid macro index: %d", index)
+	}
+
+	m.Macros = append(m.Macros[:index], m.Macros[index+1:]...)
+	return nil
+}
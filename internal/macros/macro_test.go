@@ -0,0 +1,115 @@
+package macros
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestNormalizeKey(t *testing.T) {
+	tests := []struct {
+		key      string
+		expected string
+		valid    bool
+	}{
+		{"F1", "f1", true},
+		{"f12", "f12", true},
+		{"F20", "f20", true},
+		{"Alt+8", "alt+8", true},
+		{"alt+f3", "alt+f3", true},
+		{"F21", "", false},
+		{"a", "", false},
+		{"8", "", false},
+		{"ctrl+c", "", false},
+	}
+
+	for _, tt := range tests {
+		got, err := NormalizeKey(tt.key)
+		if tt.valid {
+			if err != nil {
+				t.Errorf("NormalizeKey(%q) returned error: %v", tt.key, err)
+			} else if got != tt.expected {
+				t.Errorf("NormalizeKey(%q) = %q, want %q", tt.key, got, tt.expected)
+			}
+		} else if err == nil {
+			t.Errorf("NormalizeKey(%q) should have failed, got %q", tt.key, got)
+		}
+	}
+}
+
+func TestSetAndGet(t *testing.T) {
+	m := NewManager()
+
+	if _, err := m.Set("F1", "cast 'cure light' self"); err != nil {
+		t.Fatalf("Failed to set macro: %v", err)
+	}
+
+	macro := m.Get("f1")
+	if macro == nil {
+		t.Fatal("Expected macro for f1")
+	}
+	if macro.Command != "cast 'cure light' self" {
+		t.Errorf("Expected command \"cast 'cure light' self\", got %q", macro.Command)
+	}
+
+	// Rebinding replaces the command rather than adding a duplicate
+	if _, err := m.Set("f1", "flee"); err != nil {
+		t.Fatalf("Failed to rebind macro: %v", err)
+	}
+	if len(m.Macros) != 1 {
+		t.Errorf("Expected 1 macro after rebinding, got %d", len(m.Macros))
+	}
+	if m.Get("f1").Command != "flee" {
+		t.Errorf("Expected rebound command 'flee', got %q", m.Get("f1").Command)
+	}
+
+	if m.Get("f2") != nil {
+		t.Error("Expected no macro for unbound key f2")
+	}
+
+	if _, err := m.Set("F2", "  "); err == nil {
+		t.Error("Expected error for empty command")
+	}
+}
+
+func TestRemove(t *testing.T) {
+	m := NewManager()
+	m.Set("F1", "north")
+	m.Set("F2", "south")
+
+	if err := m.Remove(0); err != nil {
+		t.Fatalf("Failed to remove macro: %v", err)
+	}
+	if len(m.Macros) != 1 || m.Macros[0].Key != "f2" {
+		t.Errorf("Expected only f2 to remain, got %+v", m.Macros)
+	}
+
+	if err := m.Remove(5); err == nil {
+		t.Error("Expected error for invalid index")
+	}
+}
+
+func TestSaveAndLoad(t *testing.T) {
+	macrosPath := filepath.Join(t.TempDir(), "macros.json")
+
+	m, err := LoadFromPath(macrosPath)
+	if err != nil {
+		t.Fatalf("Failed to load macros: %v", err)
+	}
+	m.Set("F1", "cast 'cure light' self")
+	m.Set("alt+8", "north")
+
+	if err := m.Save(); err != nil {
+		t.Fatalf("Failed to save macros: %v", err)
+	}
+
+	loaded, err := LoadFromPath(macrosPath)
+	if err != nil {
+		t.Fatalf("Failed to reload macros: %v", err)
+	}
+	if len(loaded.Macros) != 2 {
+		t.Fatalf("Expected 2 macros, got %d", len(loaded.Macros))
+	}
+	if loaded.Get("alt+8") == nil || loaded.Get("alt+8").Command != "north" {
+		t.Error("Expected alt+8 macro to be persisted")
+	}
+}
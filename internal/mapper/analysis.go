@@ -0,0 +1,178 @@
+package mapper
+
+import (
+	"container/heap"
+	"sort"
+)
+
+// UnexploredExit is an exit whose destination hasn't been seen yet
+type UnexploredExit struct {
+	Room      *Room
+	Direction string
+}
+
+// roomsInOrder returns every room in room number order, followed by any
+// room without a number
+func (m *Map) roomsInOrder() []*Room {
+	rooms := make([]*Room, 0, len(m.Rooms))
+	numbered := make(map[string]bool, len(m.RoomNumbering))
+	for _, id := range m.RoomNumbering {
+		if room := m.Rooms[id]; room != nil {
+			rooms = append(rooms, room)
+			numbered[id] = true
+		}
+	}
+
+	var rest []*Room
+	for id, room := range m.Rooms {
+		if !numbered[id] {
+			rest = append(rest, room)
+		}
+	}
+	sort.Slice(rest, func(i, j int) bool { return rest[i].ID < rest[j].ID })
+	return append(rooms, rest...)
+}
+
+// DeadEnds returns the rooms with a single exit, in room number order
+func (m *Map) DeadEnds() []*Room {
+	var rooms []*Room
+	for _, room := range m.roomsInOrder() {
+		if len(room.Exits) == 1 {
+			rooms = append(rooms, room)
+		}
+	}
+	return rooms
+}
+
+// UnexploredExits returns the exits that haven't been taken yet, so their
+// destination is unknown, in room number order and then by direction
+func (m *Map) UnexploredExits() []UnexploredExit {
+	var exits []UnexploredExit
+	for _, room := range m.roomsInOrder() {
+		var directions []string
+		for direction, destID := range room.Exits {
+			if destID == "" {
+				directions = append(directions, direction)
+			}
+		}
+		sort.Strings(directions)
+		for _, direction := range directions {
+			exits = append(exits, UnexploredExit{Room: room, Direction: direction})
+		}
+	}
+	return exits
+}
+
+// ExploredExits counts the exits on the map whose destination is known,
+// out of all the exits seen
+func (m *Map) ExploredExits() (explored, total int) {
+	for _, room := range m.Rooms {
+		for _, destID := range room.Exits {
+			total++
+			if destID != "" {
+				explored++
+			}
+		}
+	}
+	return explored, total
+}
+
+// NearestUnexploredExit finds the unexplored exit closest to the given room,
+// measured the way FindPathFrom measures paths (avoided rooms are a last
+// resort), and the path to the room it leaves from, which is empty when
+// that is the given room. ok is false when no unexplored exit can be
+// reached by known exits.
+func (m *Map) NearestUnexploredExit(fromRoomID string) (exit UnexploredExit, path []string, ok bool) {
+	if m.Rooms[fromRoomID] == nil {
+		return UnexploredExit{}, nil, false
+	}
+
+	dist := map[string]int{fromRoomID: 0}
+	done := make(map[string]bool)
+	queue := &pathQueue{{roomID: fromRoomID, cost: 0}}
+	for queue.Len() > 0 {
+		current := heap.Pop(queue).(pathQueueItem)
+		if done[current.roomID] {
+			continue
+		}
+		done[current.roomID] = true
+
+		room := m.Rooms[current.roomID]
+		if room == nil {
+			continue
+		}
+		if direction := firstUnexploredDirection(room); direction != "" {
+			return UnexploredExit{Room: room, Direction: direction}, m.FindPathFrom(fromRoomID, room.ID), true
+		}
+
+		// Visit exits in a stable order so equally near rooms are found in
+		// the same order every time
+		directions := make([]string, 0, len(room.Exits))
+		for direction := range room.Exits {
+			directions = append(directions, direction)
+		}
+		sort.Strings(directions)
+
+		for _, direction := range directions {
+			destID := room.Exits[direction]
+			destRoom := m.Rooms[destID]
+			if destRoom == nil {
+				continue
+			}
+			cost := current.cost + 1
+			if destRoom.Avoid {
+				cost += avoidCost
+			}
+			if old, seen := dist[destID]; !seen || cost < old {
+				dist[destID] = cost
+				heap.Push(queue, pathQueueItem{roomID: destID, cost: cost})
+			}
+		}
+	}
+	return UnexploredExit{}, nil, false
+}
+
+// firstUnexploredDirection returns the first exit of a room, in
+// alphabetical order, whose destination is unknown, or ""
+func firstUnexploredDirection(room *Room) string {
+	first := ""
+	for direction, destID := range room.Exits {
+		if destID == "" && (first == "" || direction < first) {
+			first = direction
+		}
+	}
+	return first
+}
+
+// Orphans returns the rooms that can't be reached from the given room by
+// following known exits, in room number order. These are often left over
+// from a teleport, a wrong link or a duplicate of another room.
+func (m *Map) Orphans(fromRoomID string) []*Room {
+	if m.Rooms[fromRoomID] == nil {
+		return nil
+	}
+
+	reached := map[string]bool{fromRoomID: true}
+	queue := []string{fromRoomID}
+	for len(queue) > 0 {
+		room := m.Rooms[queue[0]]
+		queue = queue[1:]
+		if room == nil {
+			continue
+		}
+		for _, destID := range room.Exits {
+			if destID != "" && !reached[destID] {
+				reached[destID] = true
+				queue = append(queue, destID)
+			}
+		}
+	}
+
+	var rooms []*Room
+	for _, room := range m.roomsInOrder() {
+		if !reached[room.ID] {
+			rooms = append(rooms, room)
+		}
+	}
+	return rooms
+}
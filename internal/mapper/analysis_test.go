@@ -0,0 +1,140 @@
+package mapper
+
+import (
+	"reflect"
+	"testing"
+)
+
+// buildAnalysisTestMap builds a square with a dead-end hall to the north,
+// a shop to the east with an unexplored way up, and a cave reached by
+// teleport that no exit leads to
+func buildAnalysisTestMap() (*Map, *Room) {
+	m := NewMap()
+
+	square := NewRoom("Temple Square", "A large square.", []string{"north", "east", "west"})
+	hall := NewRoom("Hall", "A narrow hall.", []string{"south"})
+	shop := NewRoom("Shop", "A small shop.", []string{"west", "up"})
+	cave := NewRoom("Cave", "A dark cave.", []string{"north"})
+
+	m.AddOrUpdateRoom(square)
+	walk(m, "north", hall)
+	walk(m, "south", square)
+	walk(m, "east", shop)
+	walk(m, "west", square)
+	walk(m, "", cave)
+	return m, square
+}
+
+func TestDeadEnds(t *testing.T) {
+	m, _ := buildAnalysisTestMap()
+
+	if got, want := roomTitles(m.DeadEnds()), []string{"Hall", "Cave"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected dead ends %v, got %v", want, got)
+	}
+}
+
+func TestUnexploredExits(t *testing.T) {
+	m, _ := buildAnalysisTestMap()
+
+	var got []string
+	for _, exit := range m.UnexploredExits() {
+		got = append(got, exit.Room.Title+" "+exit.Direction)
+	}
+	want := []string{"Temple Square west", "Shop up", "Cave north"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected unexplored exits %v, got %v", want, got)
+	}
+}
+
+func TestExploredExits(t *testing.T) {
+	m, _ := buildAnalysisTestMap()
+
+	// The square's north and east, the hall's south and the shop's west
+	// have been walked; the square's west, the shop's up and the cave's
+	// north haven't
+	if explored, total := m.ExploredExits(); explored != 4 || total != 7 {
+		t.Errorf("Expected 4 of 7 exits explored, got %d of %d", explored, total)
+	}
+}
+
+func TestOrphans(t *testing.T) {
+	m, square := buildAnalysisTestMap()
+
+	if got, want := roomTitles(m.Orphans(square.ID)), []string{"Cave"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected orphans %v, got %v", want, got)
+	}
+
+	// From the cave nothing else can be reached
+	if got, want := roomTitles(m.Orphans(m.CurrentRoomID)), []string{"Temple Square", "Hall", "Shop"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected orphans %v from the cave, got %v", want, got)
+	}
+
+	if rooms := m.Orphans("missing"); rooms != nil {
+		t.Errorf("Expected no result from an unknown room, got %v", roomTitles(rooms))
+	}
+}
+
+func TestNearestUnexploredExit(t *testing.T) {
+	m, square := buildAnalysisTestMap()
+	hall := m.Rooms[square.Exits["north"]]
+	shop := m.Rooms[square.Exits["east"]]
+
+	// From the hall the nearest is the square's west exit, one step away
+	exit, path, ok := m.NearestUnexploredExit(hall.ID)
+	if !ok || exit.Room.ID != square.ID || exit.Direction != "west" || !reflect.DeepEqual(path, []string{"south"}) {
+		t.Errorf("Expected Temple Square west via [south], got %v %q via %v (ok=%v)", exit.Room, exit.Direction, path, ok)
+	}
+
+	// In the shop the way up is unexplored right here
+	exit, path, ok = m.NearestUnexploredExit(shop.ID)
+	if !ok || exit.Room.ID != shop.ID || exit.Direction != "up" || len(path) != 0 {
+		t.Errorf("Expected Shop up with no walk, got %v %q via %v (ok=%v)", exit.Room, exit.Direction, path, ok)
+	}
+}
+
+func TestNearestUnexploredExitAvoidsRooms(t *testing.T) {
+	m := NewMap()
+
+	// Two steps east past a guard post, or three steps south
+	gate := NewRoom("Gate", "A gate.", []string{"east", "south"})
+	post := NewRoom("Guard Post", "Guards.", []string{"east", "west"})
+	tower := NewRoom("Tower", "A tower.", []string{"west", "up"})
+	road := NewRoom("Road", "A road.", []string{"north", "south"})
+	bridge := NewRoom("Bridge", "A bridge.", []string{"north", "south"})
+	ford := NewRoom("Ford", "A ford.", []string{"north", "east"})
+
+	m.AddOrUpdateRoom(gate)
+	walk(m, "east", post)
+	walk(m, "east", tower)
+	walk(m, "west", post)
+	walk(m, "west", gate)
+	walk(m, "south", road)
+	walk(m, "south", bridge)
+	walk(m, "south", ford)
+
+	exit, path, _ := m.NearestUnexploredExit(gate.ID)
+	if exit.Room.ID != tower.ID || !reflect.DeepEqual(path, []string{"east", "east"}) {
+		t.Errorf("Expected the tower two steps east, got %v via %v", exit.Room, path)
+	}
+
+	m.Rooms[post.ID].Avoid = true
+	exit, path, _ = m.NearestUnexploredExit(gate.ID)
+	if exit.Room.ID != ford.ID || exit.Direction != "east" || !reflect.DeepEqual(path, []string{"south", "south", "south"}) {
+		t.Errorf("Expected the ford's east exit around the guard post, got %v %q via %v", exit.Room, exit.Direction, path)
+	}
+}
+
+func TestNearestUnexploredExitFullyExplored(t *testing.T) {
+	m := NewMap()
+	hall := NewRoom("Hall", "A hall.", []string{"north"})
+	m.AddOrUpdateRoom(hall)
+	walk(m, "north", NewRoom("Study", "A study.", []string{"south"}))
+	walk(m, "south", hall)
+
+	if exit, path, ok := m.NearestUnexploredExit(hall.ID); ok {
+		t.Errorf("Expected no unexplored exit, got %v %q via %v", exit.Room, exit.Direction, path)
+	}
+	if _, _, ok := m.NearestUnexploredExit("missing"); ok {
+		t.Error("Expected no unexplored exit from an unknown room")
+	}
+}
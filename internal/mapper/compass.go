@@ -0,0 +1,176 @@
+package mapper
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Orientation is the compass direction drawn at the top of the map panel
+type Orientation int
+
+// Map panel orientations
+const (
+	NorthUp Orientation = iota
+	EastUp
+	SouthUp
+	WestUp
+)
+
+// orientationNames are the orientations by the direction at the top
+var orientationNames = []string{"north", "east", "south", "west"}
+
+// ParseOrientation returns the orientation with the named direction (or its
+// first letter) at the top
+func ParseOrientation(name string) (Orientation, bool) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	for i, full := range orientationNames {
+		if name == full || name == full[:1] {
+			return Orientation(i), true
+		}
+	}
+	return NorthUp, false
+}
+
+// String returns the direction at the top
+func (o Orientation) String() string {
+	if o < NorthUp || o > WestUp {
+		return orientationNames[NorthUp]
+	}
+	return orientationNames[o]
+}
+
+// orientation returns the map panel's orientation, north up by default
+func (m *Map) orientation() Orientation {
+	o, _ := ParseOrientation(m.Orientation)
+	return o
+}
+
+// screenSteps are the grid steps of the compass directions with north up
+var screenSteps = map[string]Coordinate{
+	"north":     {X: 0, Y: -1},
+	"northeast": {X: 1, Y: -1},
+	"east":      {X: 1, Y: 0},
+	"southeast": {X: 1, Y: 1},
+	"south":     {X: 0, Y: 1},
+	"southwest": {X: -1, Y: 1},
+	"west":      {X: -1, Y: 0},
+	"northwest": {X: -1, Y: -1},
+}
+
+// toScreen turns a position on the north-up grid into the position drawn
+func (o Orientation) toScreen(c Coordinate) Coordinate {
+	switch o {
+	case EastUp:
+		return Coordinate{X: c.Y, Y: -c.X}
+	case SouthUp:
+		return Coordinate{X: -c.X, Y: -c.Y}
+	case WestUp:
+		return Coordinate{X: -c.Y, Y: c.X}
+	}
+	return c
+}
+
+// compassFor returns the compass direction drawn towards a direction on the
+// screen, e.g. with east up, north is drawn towards the left ("west")
+func (o Orientation) compassFor(screen string) string {
+	want := screenSteps[screen]
+	for _, dir := range orientationNames {
+		if o.toScreen(screenSteps[dir]) == want {
+			return dir
+		}
+	}
+	return screen
+}
+
+// rotateGrid redraws a north-up grid with the orientation's direction up
+func (o Orientation) rotateGrid(grid map[Coordinate]*RoomMarker) map[Coordinate]*RoomMarker {
+	if o == NorthUp {
+		return grid
+	}
+	rotated := make(map[Coordinate]*RoomMarker, len(grid))
+	for coord, marker := range grid {
+		rotated[o.toScreen(coord)] = marker
+	}
+	return rotated
+}
+
+// isDirection reports whether an exit name is a cardinal direction, written
+// in full or as its first letter
+func isDirection(exit, direction string) bool {
+	return exit == direction || exit == direction[:1]
+}
+
+// compassNames expands the abbreviated directions shown on the compass
+var compassNames = map[string]string{
+	"n": "north", "e": "east", "s": "south", "w": "west",
+	"ne": "northeast", "nw": "northwest", "se": "southeast", "sw": "southwest",
+	"u": "up", "d": "down",
+}
+
+// CompassExits returns the compass and vertical directions a room has exits
+// in, by their full names
+func CompassExits(room *Room) map[string]bool {
+	exits := make(map[string]bool)
+	if room == nil {
+		return exits
+	}
+	for dir := range room.Exits {
+		dir = strings.ToLower(dir)
+		if full, ok := compassNames[dir]; ok {
+			dir = full
+		}
+		if _, ok := screenSteps[dir]; ok || dir == "up" || dir == "down" {
+			exits[dir] = true
+		}
+	}
+	return exits
+}
+
+// compassLabels are the letters each direction is shown by on the compass
+var compassLabels = map[string]string{
+	"north": "N", "northeast": "NE", "east": "E", "southeast": "SE",
+	"south": "S", "southwest": "SW", "west": "W", "northwest": "NW",
+	"up": "U", "down": "D",
+}
+
+// northArrows point to where north is drawn, by orientation
+var northArrows = []string{"↑", "←", "↓", "→"}
+
+// RenderCompass draws a compass of the exits: each direction with an exit
+// is shown by its letter where it is drawn on the map, and the others by a
+// dot. The arrow in the middle points north, and up and down are shown at
+// the right.
+func RenderCompass(exits map[string]bool, o Orientation) string {
+	litStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("226"))   // Yellow/gold, as the current room
+	unlitStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("240")) // Dark gray
+	cell := func(dir string) string {
+		if exits[dir] {
+			return litStyle.Render(compassLabels[dir] + strings.Repeat(" ", 2-len(compassLabels[dir])))
+		}
+		return unlitStyle.Render("· ")
+	}
+
+	rows := [3][4]string{}
+	for r := range rows {
+		for c := range rows[r] {
+			rows[r][c] = "  "
+		}
+	}
+	for dir, step := range screenSteps {
+		screen := o.toScreen(step)
+		rows[screen.Y+1][screen.X+1] = cell(dir)
+	}
+	rows[1][1] = northArrows[o] + " "
+	rows[0][3] = cell("up")
+	rows[2][3] = cell("down")
+
+	lines := make([]string, len(rows))
+	for r, row := range rows {
+		lines[r] = strings.TrimRight(strings.Join(row[:], " "), " ")
+	}
+	return strings.Join(lines, "\n")
+}
+
+// compassHeight is the number of lines the compass takes below the map
+const compassHeight = 3
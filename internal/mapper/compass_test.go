@@ -0,0 +1,101 @@
+package mapper
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestCompassLightsExits verifies the compass shows a letter for each exit
+// the room has and a dot for the rest
+func TestCompassLightsExits(t *testing.T) {
+	room := NewRoom("Crossroads", "Roads meet here.", []string{"n", "east", "sw", "up"})
+	exits := CompassExits(room)
+	for _, dir := range []string{"north", "east", "southwest", "up"} {
+		if !exits[dir] {
+			t.Errorf("Expected %s to be lit, got %v", dir, exits)
+		}
+	}
+	if len(exits) != 4 {
+		t.Errorf("Expected only the room's exits lit, got %v", exits)
+	}
+
+	want := strings.Join([]string{
+		"·  N  ·  U",
+		"·  ↑  E",
+		"SW ·  ·  ·",
+	}, "\n")
+	if got := RenderCompass(exits, NorthUp); got != want {
+		t.Errorf("RenderCompass() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+// TestCompassFollowsOrientation verifies the letters and the north arrow
+// turn with the map
+func TestCompassFollowsOrientation(t *testing.T) {
+	exits := map[string]bool{"north": true, "east": true}
+
+	want := strings.Join([]string{
+		"·  E  ·  ·",
+		"N  ←  ·",
+		"·  ·  ·  ·",
+	}, "\n")
+	if got := RenderCompass(exits, EastUp); got != want {
+		t.Errorf("RenderCompass(EastUp) =\n%s\nwant\n%s", got, want)
+	}
+
+	want = strings.Join([]string{
+		"·  ·  ·  ·",
+		"E  ↓  ·",
+		"·  N  ·  ·",
+	}, "\n")
+	if got := RenderCompass(exits, SouthUp); got != want {
+		t.Errorf("RenderCompass(SouthUp) =\n%s\nwant\n%s", got, want)
+	}
+}
+
+// TestRenderMapOrientation verifies a room to the north is drawn on the
+// side the orientation puts north, still connected to the current room
+func TestRenderMapOrientation(t *testing.T) {
+	m := NewMap()
+	center := NewRoom("Center Room", "You are at the center.", []string{"north"})
+	north := NewRoom("North Room", "You are in the north room.", []string{"south"})
+	center.UpdateExit("north", north.ID)
+	north.UpdateExit("south", center.ID)
+	m.AddOrUpdateRoom(center)
+	m.AddOrUpdateRoom(north)
+	m.CurrentRoomID = center.ID
+
+	tests := []struct {
+		orientation string
+		want        string
+	}{
+		{"north", "▢\n│\n▣"},
+		{"south", "▣\n│\n▢"},
+		{"east", "▢──▣"},
+		{"west", "▣──▢"},
+	}
+	for _, tt := range tests {
+		m.Orientation = tt.orientation
+		rendered, _ := m.RenderMap(9, 6)
+		var lines []string
+		for _, line := range strings.Split(rendered, "\n") {
+			if line = strings.TrimSpace(line); line != "" {
+				lines = append(lines, line)
+			}
+		}
+		if got := strings.Join(lines, "\n"); got != tt.want {
+			t.Errorf("With %s up got\n%s\nwant\n%s", tt.orientation, got, tt.want)
+		}
+	}
+}
+
+func TestParseOrientation(t *testing.T) {
+	for name, want := range map[string]Orientation{"north": NorthUp, "E": EastUp, "south": SouthUp, "w": WestUp} {
+		if got, ok := ParseOrientation(name); !ok || got != want {
+			t.Errorf("ParseOrientation(%q) = (%v, %v), want %v", name, got, ok, want)
+		}
+	}
+	if _, ok := ParseOrientation("up"); ok {
+		t.Error("Expected up to be rejected")
+	}
+}
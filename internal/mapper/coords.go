@@ -0,0 +1,224 @@
+package mapper
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Position is a room's location in map space: north is +Y, east is +X and
+// up is +Z
+type Position struct {
+	X int `json:"x"`
+	Y int `json:"y"`
+	Z int `json:"z"`
+}
+
+// String formats a position as "(x, y, z)"
+func (p Position) String() string {
+	return fmt.Sprintf("(%d, %d, %d)", p.X, p.Y, p.Z)
+}
+
+// Add returns the position moved by offset
+func (p Position) Add(offset Position) Position {
+	return Position{X: p.X + offset.X, Y: p.Y + offset.Y, Z: p.Z + offset.Z}
+}
+
+// Sub returns the position moved back by offset
+func (p Position) Sub(offset Position) Position {
+	return Position{X: p.X - offset.X, Y: p.Y - offset.Y, Z: p.Z - offset.Z}
+}
+
+// directionOffsets maps the compass and vertical directions to one step in
+// map space. Other exits (enter, portal, ...) don't have a position.
+var directionOffsets = map[string]Position{
+	"north":     {Y: 1},
+	"n":         {Y: 1},
+	"south":     {Y: -1},
+	"s":         {Y: -1},
+	"east":      {X: 1},
+	"e":         {X: 1},
+	"west":      {X: -1},
+	"w":         {X: -1},
+	"up":        {Z: 1},
+	"u":         {Z: 1},
+	"down":      {Z: -1},
+	"d":         {Z: -1},
+	"northeast": {X: 1, Y: 1},
+	"ne":        {X: 1, Y: 1},
+	"northwest": {X: -1, Y: 1},
+	"nw":        {X: -1, Y: 1},
+	"southeast": {X: 1, Y: -1},
+	"se":        {X: 1, Y: -1},
+	"southwest": {X: -1, Y: -1},
+	"sw":        {X: -1, Y: -1},
+}
+
+// DirectionOffset returns the step in map space for a direction
+func DirectionOffset(direction string) (Position, bool) {
+	offset, ok := directionOffsets[strings.ToLower(direction)]
+	return offset, ok
+}
+
+// regionGap separates an area reached without a known step (teleport, recall
+// to an unmapped room) from the rooms already placed
+const regionGap = 10
+
+// assignPosition places a room reached by moving direction from fromRoom.
+// Rooms keep the first position they are given, so walking a loop back to a
+// known room doesn't move it.
+func (m *Map) assignPosition(fromRoom, room *Room, direction string) {
+	offset, ok := DirectionOffset(direction)
+	if !ok {
+		return
+	}
+
+	if room.Position != nil {
+		// Walked out of an unplaced room into a placed one: work backwards
+		if fromRoom.Position == nil {
+			m.setPosition(fromRoom, room.Position.Sub(offset))
+		}
+		return
+	}
+
+	if fromRoom.Position == nil {
+		m.setPosition(fromRoom, m.newRegionOrigin())
+	}
+	m.setPosition(room, fromRoom.Position.Add(offset))
+}
+
+// setPosition places a room, flagging it and any room already there as
+// conflicting
+func (m *Map) setPosition(room *Room, pos Position) {
+	room.Position = &pos
+	for _, other := range m.Rooms {
+		if other != room && other.Position != nil && *other.Position == pos {
+			other.Conflict = true
+			room.Conflict = true
+		}
+	}
+}
+
+// hasPositions reports whether any room has been placed yet
+func (m *Map) hasPositions() bool {
+	for _, room := range m.Rooms {
+		if room.Position != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// newRegionOrigin returns a position east of every placed room, for an area
+// that isn't connected to them by a known step
+func (m *Map) newRegionOrigin() Position {
+	if !m.hasPositions() {
+		return Position{}
+	}
+	maxX := 0
+	first := true
+	for _, room := range m.Rooms {
+		if room.Position != nil && (first || room.Position.X > maxX) {
+			maxX = room.Position.X
+			first = false
+		}
+	}
+	return Position{X: maxX + regionGap}
+}
+
+// CoordinateConflicts returns the groups of rooms that share a position,
+// in room number order
+func (m *Map) CoordinateConflicts() [][]*Room {
+	byPosition := make(map[Position][]*Room)
+	for _, id := range m.sortedRoomIDs() {
+		room := m.Rooms[id]
+		if room.Position != nil {
+			byPosition[*room.Position] = append(byPosition[*room.Position], room)
+		}
+	}
+
+	var conflicts [][]*Room
+	for _, rooms := range byPosition {
+		if len(rooms) > 1 {
+			conflicts = append(conflicts, rooms)
+		}
+	}
+	sort.Slice(conflicts, func(i, j int) bool {
+		return m.roomOrder(conflicts[i][0].ID, conflicts[j][0].ID)
+	})
+	return conflicts
+}
+
+// sortedRoomIDs returns every room ID in room number order, with unnumbered
+// rooms last
+func (m *Map) sortedRoomIDs() []string {
+	ids := make([]string, 0, len(m.Rooms))
+	for id := range m.Rooms {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		return m.roomOrder(ids[i], ids[j])
+	})
+	return ids
+}
+
+// roomOrder reports whether room a sorts before room b by durable number
+func (m *Map) roomOrder(a, b string) bool {
+	numA, numB := m.GetRoomNumber(a), m.GetRoomNumber(b)
+	if numA != numB {
+		if numA == 0 || numB == 0 {
+			return numB == 0
+		}
+		return numA < numB
+	}
+	return a < b
+}
+
+// buildCoordinateGrid lays out the rooms on the current room's level by
+// their assigned positions, so loops close and overlapping areas don't push
+// each other around. Unexplored exits are shown where their cell is free.
+func (m *Map) buildCoordinateGrid(currentRoom *Room) map[Coordinate]*RoomMarker {
+	grid := make(map[Coordinate]*RoomMarker)
+	origin := *currentRoom.Position
+
+	// Screen Y grows downwards, so north (+Y) is up the screen
+	toGrid := func(pos Position) Coordinate {
+		return Coordinate{X: pos.X - origin.X, Y: origin.Y - pos.Y}
+	}
+
+	grid[Coordinate{0, 0}] = &RoomMarker{Room: currentRoom, Conflict: currentRoom.Conflict}
+
+	ids := m.sortedRoomIDs()
+	for _, id := range ids {
+		room := m.Rooms[id]
+		if room.Position == nil || room.Position.Z != origin.Z {
+			continue
+		}
+		coord := toGrid(*room.Position)
+		if grid[coord] == nil {
+			grid[coord] = &RoomMarker{Room: room, Conflict: room.Conflict}
+		}
+	}
+
+	for _, id := range ids {
+		room := m.Rooms[id]
+		if room.Position == nil || room.Position.Z != origin.Z {
+			continue
+		}
+		for direction, destID := range room.Exits {
+			offset, ok := DirectionOffset(direction)
+			if !ok || offset.Z != 0 {
+				continue
+			}
+			if destID != "" && m.Rooms[destID] != nil {
+				continue
+			}
+			coord := toGrid(room.Position.Add(offset))
+			if grid[coord] == nil {
+				grid[coord] = &RoomMarker{IsUnknown: true}
+			}
+		}
+	}
+
+	return grid
+}
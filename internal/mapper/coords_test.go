@@ -0,0 +1,196 @@
+package mapper
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// walk moves in direction and arrives at room
+func walk(m *Map, direction string, room *Room) {
+	m.SetLastDirection(direction)
+	m.AddOrUpdateRoom(room)
+}
+
+func TestPositionsAroundLoop(t *testing.T) {
+	m := NewMap()
+
+	square := NewRoom("Temple Square", "A large square.", []string{"north", "east"})
+	street := NewRoom("Market Street", "A busy street.", []string{"south", "east"})
+	corner := NewRoom("Street Corner", "A quiet corner.", []string{"west", "south"})
+	alley := NewRoom("Dark Alley", "A dark alley.", []string{"north", "west", "up"})
+	roof := NewRoom("Rooftop", "A windy rooftop.", []string{"down"})
+
+	m.AddOrUpdateRoom(square)
+	walk(m, "north", street)
+	walk(m, "east", corner)
+	walk(m, "south", alley)
+	walk(m, "west", square)
+
+	want := map[*Room]Position{
+		square: {0, 0, 0},
+		street: {0, 1, 0},
+		corner: {1, 1, 0},
+		alley:  {1, 0, 0},
+	}
+	for room, pos := range want {
+		if room.Position == nil || *room.Position != pos {
+			t.Errorf("%s at %v, want %v", room.Title, room.Position, pos)
+		}
+	}
+	if m.CurrentRoomID != square.ID {
+		t.Error("Expected the loop to return to Temple Square")
+	}
+	if conflicts := m.CoordinateConflicts(); len(conflicts) != 0 {
+		t.Errorf("Expected no conflicts around a closed loop, got %d", len(conflicts))
+	}
+
+	// Vertical moves change level
+	walk(m, "east", alley)
+	walk(m, "up", roof)
+	if roof.Position == nil || *roof.Position != (Position{1, 0, 1}) {
+		t.Errorf("Rooftop at %v, want (1, 0, 1)", roof.Position)
+	}
+}
+
+func TestPositionConflictIsFlagged(t *testing.T) {
+	m := NewMap()
+
+	hall := NewRoom("Hall", "A long hall.", []string{"north", "east"})
+	maze1 := NewRoom("Maze", "Twisty passages.", []string{"south", "east"})
+	maze2 := NewRoom("Maze", "Twisty little passages.", []string{"west", "south"})
+	maze3 := NewRoom("Maze", "Little twisty passages.", []string{"north", "west"})
+
+	m.AddOrUpdateRoom(hall)
+	walk(m, "north", maze1)
+	walk(m, "east", maze2)
+	walk(m, "south", maze3)
+	// West of maze3 is a room that looks new but would sit where the hall is
+	other := NewRoom("Maze", "Passages, twisty and little.", []string{"east"})
+	walk(m, "west", other)
+
+	if !other.Conflict || !hall.Conflict {
+		t.Error("Expected the rooms sharing (0, 0, 0) to be flagged")
+	}
+	if maze1.Conflict {
+		t.Error("Expected rooms with their own cell not to be flagged")
+	}
+
+	conflicts := m.CoordinateConflicts()
+	if len(conflicts) != 1 || len(conflicts[0]) != 2 || conflicts[0][0] != hall || conflicts[0][1] != other {
+		t.Errorf("Expected one conflict between the hall and the new room, got %v", conflicts)
+	}
+}
+
+func TestPositionsForUnplacedArea(t *testing.T) {
+	m := NewMap()
+
+	temple := NewRoom("Temple", "A temple.", []string{"east"})
+	shrine := NewRoom("Shrine", "A shrine.", []string{"west"})
+	m.AddOrUpdateRoom(temple)
+	walk(m, "east", shrine)
+
+	// Teleporting gives no direction, so the new area starts apart from the
+	// rooms already placed once we walk out of it
+	tower := NewRoom("Tower", "A tall tower.", []string{"north"})
+	top := NewRoom("Tower Top", "The top of the tower.", []string{"south"})
+	walk(m, "", tower)
+	if tower.Position != nil {
+		t.Errorf("Expected a teleport destination to stay unplaced, got %v", tower.Position)
+	}
+	walk(m, "north", top)
+	if tower.Position == nil || *tower.Position != (Position{11, 0, 0}) {
+		t.Errorf("Tower at %v, want (11, 0, 0)", tower.Position)
+	}
+	if top.Position == nil || *top.Position != (Position{11, 1, 0}) {
+		t.Errorf("Tower Top at %v, want (11, 1, 0)", top.Position)
+	}
+
+	// Non-compass exits don't place rooms
+	cellar := NewRoom("Cellar", "A cellar.", []string{"out"})
+	walk(m, "enter", cellar)
+	if cellar.Position != nil {
+		t.Errorf("Expected a room entered by a non-compass exit to stay unplaced, got %v", cellar.Position)
+	}
+}
+
+func TestPositionsPersist(t *testing.T) {
+	m := NewMap()
+	m.mapPath = filepath.Join(t.TempDir(), "map.json")
+	m.UseCoordinates = true
+
+	square := NewRoom("Temple Square", "A large square.", []string{"north"})
+	street := NewRoom("Market Street", "A busy street.", []string{"south"})
+	m.AddOrUpdateRoom(square)
+	walk(m, "north", street)
+	if err := m.Save(); err != nil {
+		t.Fatalf("Failed to save map: %v", err)
+	}
+
+	loaded, err := LoadFromPath(m.mapPath)
+	if err != nil {
+		t.Fatalf("Failed to load map: %v", err)
+	}
+	if !loaded.UseCoordinates {
+		t.Error("Expected coordinate rendering to persist")
+	}
+	if pos := loaded.Rooms[street.ID].Position; pos == nil || *pos != (Position{0, 1, 0}) {
+		t.Errorf("Market Street loaded at %v, want (0, 1, 0)", pos)
+	}
+}
+
+func TestCoordinateGridDrawsLoopAndLevels(t *testing.T) {
+	m := NewMap()
+
+	// A ring of six rooms around a block, with a cellar below one corner
+	a := NewRoom("A", "Room a.", []string{"north", "east"})
+	b := NewRoom("B", "Room b.", []string{"south", "north"})
+	c := NewRoom("C", "Room c.", []string{"south", "east"})
+	d := NewRoom("D", "Room d.", []string{"west", "south"})
+	e := NewRoom("E", "Room e.", []string{"north", "south"})
+	f := NewRoom("F", "Room f.", []string{"north", "west", "down"})
+	cellar := NewRoom("Cellar", "Room below.", []string{"up"})
+
+	m.AddOrUpdateRoom(a)
+	walk(m, "north", b)
+	walk(m, "north", c)
+	walk(m, "east", d)
+	walk(m, "south", e)
+	walk(m, "south", f)
+	walk(m, "west", a)
+	walk(m, "east", f)
+	walk(m, "down", cellar)
+	walk(m, "up", f)
+	walk(m, "west", a)
+
+	m.UseCoordinates = true
+	grid := m.roomGrid(a, 40, 20)
+
+	want := map[Coordinate]*Room{
+		{0, 0}:  a,
+		{0, -1}: b,
+		{0, -2}: c,
+		{1, -2}: d,
+		{1, -1}: e,
+		{1, 0}:  f,
+	}
+	for coord, room := range want {
+		if marker := grid[coord]; marker == nil || marker.Room != room {
+			t.Errorf("Expected %s at %v, got %+v", room.Title, coord, marker)
+		}
+	}
+	if len(grid) != len(want) {
+		t.Errorf("Expected only the ground floor in the grid, got %d cells", len(grid))
+	}
+
+	rendered, _ := m.RenderMap(40, 20)
+	if !strings.Contains(rendered, "⇲") {
+		t.Error("Expected the room with a down exit to be marked")
+	}
+
+	// Without coordinate rendering the exit-following layout is used
+	m.UseCoordinates = false
+	if grid := m.roomGrid(a, 40, 20); grid[Coordinate{1, 0}] == nil || grid[Coordinate{1, 0}].Room != f {
+		t.Error("Expected the exit-following layout to still place F east of A")
+	}
+}
@@ -0,0 +1,41 @@
+package mapper
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Dig creates a room in a direction from the current room and moves there,
+// for areas whose rooms can't be mapped from the MUD's output. The rooms
+// are linked both ways when the direction has an opposite. An exit that
+// already leads somewhere is left alone.
+func (m *Map) Dig(direction, title string) (*Room, error) {
+	from := m.GetCurrentRoom()
+	if from == nil {
+		return nil, fmt.Errorf("no current room to dig from")
+	}
+	direction = DetectMovement(direction)
+	if direction == "" {
+		return nil, fmt.Errorf("not a direction")
+	}
+	title = strings.TrimSpace(title)
+	if title == "" {
+		return nil, fmt.Errorf("the new room needs a title")
+	}
+	if destID := from.Exits[direction]; destID != "" {
+		if dest, ok := m.Rooms[destID]; ok {
+			return nil, fmt.Errorf("%s already leads to '%s'", direction, dest.Title)
+		}
+	}
+
+	var exits []string
+	if reverse := getReverseDirection(direction); reverse != "" {
+		exits = append(exits, reverse)
+	}
+	room := NewRoom(title, "", exits)
+
+	m.SetLastDirection(direction)
+	m.AddOrUpdateRoom(room)
+	m.SetLastDirection("")
+	return m.Rooms[room.ID], nil
+}
@@ -0,0 +1,59 @@
+package mapper
+
+import "testing"
+
+func TestDigCreatesLinkedRoom(t *testing.T) {
+	m := NewMap()
+	start := NewRoom("Cave Mouth", "A dark opening.", []string{"north"})
+	m.AddOrUpdateRoom(start)
+
+	room, err := m.Dig("n", "Twisty Passage")
+	if err != nil {
+		t.Fatalf("Failed to dig: %v", err)
+	}
+
+	if room.Title != "Twisty Passage" || m.Rooms[room.ID] != room {
+		t.Fatalf("Expected the new room in the map, got %+v", room)
+	}
+	if m.CurrentRoomID != room.ID {
+		t.Errorf("Expected to be moved into the new room, got %q", m.CurrentRoomID)
+	}
+	if start.Exits["north"] != room.ID {
+		t.Errorf("Expected north from the cave mouth to lead to the passage, got %q", start.Exits["north"])
+	}
+	if room.Exits["south"] != start.ID {
+		t.Errorf("Expected south from the passage to lead back, got %q", room.Exits["south"])
+	}
+	if room.Position == nil || *room.Position != (Position{Y: 1}) {
+		t.Errorf("Expected the passage one step north, got %v", room.Position)
+	}
+	if m.GetRoomNumber(room.ID) != 2 {
+		t.Errorf("Expected the passage to be room 2, got %d", m.GetRoomNumber(room.ID))
+	}
+	if m.LastDirection != "" {
+		t.Errorf("Expected no movement left to link, got %q", m.LastDirection)
+	}
+}
+
+func TestDigErrors(t *testing.T) {
+	m := NewMap()
+	if _, err := m.Dig("north", "Nowhere"); err == nil {
+		t.Error("Expected an error without a current room")
+	}
+
+	m.AddOrUpdateRoom(NewRoom("Hall", "A hall.", []string{"west"}))
+	if _, err := m.Dig("sideways", "Nowhere"); err == nil {
+		t.Error("Expected an error for an unknown direction")
+	}
+	if _, err := m.Dig("north", "  "); err == nil {
+		t.Error("Expected an error without a title")
+	}
+
+	library, _ := m.Dig("west", "Library")
+	if _, err := m.Dig("east", "Study"); err == nil {
+		t.Error("Expected an error for an exit that already leads somewhere")
+	}
+	if m.CurrentRoomID != library.ID {
+		t.Errorf("Expected a failed dig to stay put, got %q", m.CurrentRoomID)
+	}
+}
@@ -0,0 +1,133 @@
+package mapper
+
+import (
+	"sort"
+	"strings"
+)
+
+// fuzzyThreshold is the lowest similarity (0-1) at which a query term is
+// taken to be a misspelling of a word in a room title. At 0.6 a six-letter
+// word may have two letters wrong or swapped.
+const fuzzyThreshold = 0.6
+
+// SearchRooms finds rooms with FindRooms, falling back to FindRoomsFuzzy when
+// no room matches every term exactly. fuzzy reports whether the fallback was
+// used.
+func (m *Map) SearchRooms(query string) (rooms []*Room, fuzzy bool) {
+	if rooms := m.FindRooms(query); len(rooms) > 0 {
+		return rooms, false
+	}
+	rooms = m.FindRoomsFuzzy(query)
+	return rooms, len(rooms) > 0
+}
+
+// FindRoomsFuzzy finds rooms whose titles approximately match every query
+// term, so "tempel squre" finds "Temple Square". Results are ranked best
+// match first, then by room number.
+func (m *Map) FindRoomsFuzzy(query string) []*Room {
+	queryTerms := strings.Fields(strings.ToLower(query))
+	if len(queryTerms) == 0 {
+		return nil
+	}
+
+	scores := make(map[string]float64)
+	var matches []*Room
+	for _, room := range m.Rooms {
+		if score := fuzzyTitleScore(queryTerms, room.Title); score > 0 {
+			scores[room.ID] = score
+			matches = append(matches, room)
+		}
+	}
+
+	numbers := make(map[string]int, len(m.RoomNumbering))
+	for i, id := range m.RoomNumbering {
+		numbers[id] = i + 1
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		scoreI, scoreJ := scores[matches[i].ID], scores[matches[j].ID]
+		if scoreI != scoreJ {
+			return scoreI > scoreJ
+		}
+		numI, numJ := numbers[matches[i].ID], numbers[matches[j].ID]
+		if numI != numJ {
+			// Unnumbered rooms sort last
+			if numI == 0 || numJ == 0 {
+				return numJ == 0
+			}
+			return numI < numJ
+		}
+		return matches[i].ID < matches[j].ID
+	})
+
+	return matches
+}
+
+// fuzzyTitleScore returns how well the query terms match the words of a
+// title, as the average of each term's best similarity to a title word, or 0
+// when any term matches no word well enough
+func fuzzyTitleScore(queryTerms []string, title string) float64 {
+	words := strings.FieldsFunc(strings.ToLower(title), func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r > 127)
+	})
+	if len(words) == 0 {
+		return 0
+	}
+
+	total := 0.0
+	for _, term := range queryTerms {
+		best := 0.0
+		for _, word := range words {
+			if s := similarity(term, word); s > best {
+				best = s
+			}
+		}
+		if best < fuzzyThreshold {
+			return 0
+		}
+		total += best
+	}
+	return total / float64(len(queryTerms))
+}
+
+// similarity returns 1 for equal strings, falling towards 0 as the edit
+// distance between them approaches the length of the longer one
+func similarity(a, b string) float64 {
+	ra, rb := []rune(a), []rune(b)
+	longest := len(ra)
+	if len(rb) > longest {
+		longest = len(rb)
+	}
+	if longest == 0 {
+		return 1
+	}
+	return 1 - float64(editDistance(ra, rb))/float64(longest)
+}
+
+// editDistance returns the Levenshtein distance between a and b, counting two
+// swapped neighbouring letters as a single edit since that is the most
+// common typo
+func editDistance(a, b []rune) int {
+	// prev2, prev and cur are rows i-2, i-1 and i of the distance table
+	prev2 := make([]int, len(b)+1)
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				cur[j] = min(cur[j], prev2[j-2]+1)
+			}
+		}
+		prev2, prev, cur = prev, cur, prev2
+	}
+	return prev[len(b)]
+}
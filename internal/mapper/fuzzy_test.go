@@ -0,0 +1,91 @@
+package mapper
+
+import "testing"
+
+func newFuzzyTestMap() *Map {
+	m := NewMap()
+	for _, title := range []string{"Temple Square", "Market Street", "Temple Entrance", "Tempest Tower", "Dark Alley"} {
+		m.AddOrUpdateRoom(NewRoom(title, "A room in the city.", []string{"north"}))
+	}
+	return m
+}
+
+func TestEditDistance(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"temple", "temple", 0},
+		{"squre", "square", 1},  // Missing letter
+		{"tempel", "temple", 1}, // Swapped letters count once
+		{"markt", "market", 1},
+		{"alley", "valley", 1},
+		{"", "abc", 3},
+		{"kitten", "sitting", 3},
+	}
+	for _, tt := range tests {
+		if got := editDistance([]rune(tt.a), []rune(tt.b)); got != tt.want {
+			t.Errorf("editDistance(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestFindRoomsFuzzyMisspelledQuery(t *testing.T) {
+	m := newFuzzyTestMap()
+
+	results := m.FindRoomsFuzzy("tempel squre")
+	if len(results) == 0 || results[0].Title != "Temple Square" {
+		t.Fatalf("Expected Temple Square first, got %v", roomTitles(results))
+	}
+	if len(results) != 1 {
+		t.Errorf("Expected only Temple Square to match both terms, got %v", roomTitles(results))
+	}
+
+	if results := m.FindRoomsFuzzy("castel"); len(results) != 0 {
+		t.Errorf("Expected nothing close to 'castel', got %v", roomTitles(results))
+	}
+}
+
+func TestFindRoomsFuzzyRanksBestMatchFirst(t *testing.T) {
+	m := newFuzzyTestMap()
+
+	// "tempe" is one letter from temple and two from tempest
+	for i := 0; i < 20; i++ {
+		results := m.FindRoomsFuzzy("tempe")
+		titles := roomTitles(results)
+		if len(titles) != 3 || titles[2] != "Tempest Tower" {
+			t.Fatalf("Expected the temple rooms before Tempest Tower, got %v", titles)
+		}
+		// Equal scores fall back to room number order
+		if titles[0] != "Temple Square" || titles[1] != "Temple Entrance" {
+			t.Fatalf("Expected equal matches in room number order, got %v", titles)
+		}
+	}
+}
+
+func TestSearchRoomsPrefersExactMatches(t *testing.T) {
+	m := newFuzzyTestMap()
+
+	results, fuzzy := m.SearchRooms("temple")
+	if fuzzy || len(results) != 2 {
+		t.Errorf("Expected 2 exact matches, got %v (fuzzy %v)", roomTitles(results), fuzzy)
+	}
+
+	results, fuzzy = m.SearchRooms("tempel squre")
+	if !fuzzy || len(results) != 1 || results[0].Title != "Temple Square" {
+		t.Errorf("Expected the fuzzy fallback to find Temple Square, got %v (fuzzy %v)", roomTitles(results), fuzzy)
+	}
+
+	results, fuzzy = m.SearchRooms("castle")
+	if fuzzy || len(results) != 0 {
+		t.Errorf("Expected no matches, got %v (fuzzy %v)", roomTitles(results), fuzzy)
+	}
+}
+
+func roomTitles(rooms []*Room) []string {
+	titles := make([]string, len(rooms))
+	for i, room := range rooms {
+		titles[i] = room.Title
+	}
+	return titles
+}
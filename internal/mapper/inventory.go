@@ -3,6 +3,8 @@ package mapper
 import (
 	"regexp"
 	"strings"
+
+	"github.com/anicolao/dikuclient/internal/ansi"
 )
 
 // InventoryInfo contains parsed inventory information
@@ -11,66 +13,105 @@ type InventoryInfo struct {
 	DebugInfo string // Debug information about parsing
 }
 
+// EquipmentSlot is one worn or held item from the equipment listing
+type EquipmentSlot struct {
+	Slot string // Where it is worn, such as "worn on body" or "wielded"
+	Item string
+}
+
 // inventoryHeaderPattern matches "You are carrying:"
 var inventoryHeaderPattern = regexp.MustCompile(`(?i)^you are carrying:\s*$`)
 
+// equipmentHeaderPattern matches "You are using:", the header of the
+// equipment (eq) listing
+var equipmentHeaderPattern = regexp.MustCompile(`(?i)^you are using:\s*$`)
+
+// equipmentSlotPattern matches an equipment line such as
+// "<worn on body>      a bronze breastplate"
+var equipmentSlotPattern = regexp.MustCompile(`^<([^>]+)>\s*(.+)$`)
+
 // ParseInventoryInfo attempts to parse inventory information from MUD output
 // It looks for "You are carrying:" followed by item lines
 func ParseInventoryInfo(lines []string, enableDebug bool) *InventoryInfo {
-	if len(lines) == 0 {
+	return ParseInventoryInfoWithPrompt(lines, enableDebug, IsPromptLine)
+}
+
+// ParseInventoryInfoWithPrompt is ParseInventoryInfo for a MUD whose prompt
+// lines are recognized by isPrompt (see Map.IsPrompt)
+func ParseInventoryInfoWithPrompt(lines []string, enableDebug bool, isPrompt func(string) bool) *InventoryInfo {
+	items, ok := listingBeforePrompt(lines, inventoryHeaderPattern, isPrompt)
+	if !ok {
 		return nil
 	}
+	return &InventoryInfo{
+		Items: items,
+	}
+}
 
-	// Find the inventory header line by scanning backwards
+// ParseEquipmentWithPrompt parses the latest complete equipment listing,
+// "You are using:" followed by "<slot> item" lines and ended by a prompt,
+// into slot and item pairs in the order listed. It returns nil when there
+// is no complete listing, and an empty slice when nothing is worn.
+func ParseEquipmentWithPrompt(lines []string, isPrompt func(string) bool) []EquipmentSlot {
+	listing, ok := listingBeforePrompt(lines, equipmentHeaderPattern, isPrompt)
+	if !ok {
+		return nil
+	}
+	slots := []EquipmentSlot{}
+	for _, line := range listing {
+		// Lines without a slot, such as "Nothing.", aren't items
+		match := equipmentSlotPattern.FindStringSubmatch(line)
+		if match == nil {
+			continue
+		}
+		slots = append(slots, EquipmentSlot{
+			Slot: strings.TrimSpace(match[1]),
+			Item: strings.TrimSpace(match[2]),
+		})
+	}
+	return slots
+}
+
+// listingBeforePrompt finds the last line matching header and returns the
+// non-empty lines between it and the next prompt, without color codes.
+// It reports false when there is no header, or no prompt after it yet.
+func listingBeforePrompt(lines []string, header *regexp.Regexp, isPrompt func(string) bool) ([]string, bool) {
+	// Find the header line by scanning backwards
 	headerIdx := -1
 	for i := len(lines) - 1; i >= 0; i-- {
-		line := stripANSI(lines[i])
-		line = strings.TrimSpace(line)
-
-		if inventoryHeaderPattern.MatchString(line) {
+		line := strings.TrimSpace(ansi.Strip(lines[i]))
+		if header.MatchString(line) {
 			headerIdx = i
 			break
 		}
 	}
-
-	// If no header found, we can't parse the inventory
 	if headerIdx == -1 {
-		return nil
+		return nil, false
 	}
 
-	// Look for the prompt line after the header to know where inventory ends
+	// Look for the prompt line after the header to know where the listing ends
 	// Prompts typically end with > and contain stats (H, V, X, etc.)
 	promptIdx := -1
 	for i := headerIdx + 1; i < len(lines); i++ {
-		line := stripANSI(lines[i])
-		line = strings.TrimSpace(line)
-
-		if isPromptLine(line) {
+		line := strings.TrimSpace(ansi.Strip(lines[i]))
+		if isPrompt(line) {
 			promptIdx = i
 			break
 		}
 	}
 
-	// If no prompt found after header, inventory may still be incomplete
+	// If no prompt found after header, the listing may still be incomplete
 	if promptIdx == -1 {
-		return nil
+		return nil, false
 	}
 
-	// Collect all lines between header and prompt as inventory items
-	items := []string{}
+	listing := []string{}
 	for i := headerIdx + 1; i < promptIdx; i++ {
-		line := stripANSI(lines[i])
-		line = strings.TrimSpace(line)
-
-		// Skip empty lines
+		line := strings.TrimSpace(ansi.Strip(lines[i]))
 		if line == "" {
 			continue
 		}
-
-		items = append(items, line)
-	}
-
-	return &InventoryInfo{
-		Items: items,
+		listing = append(listing, line)
 	}
+	return listing, true
 }
@@ -101,3 +101,66 @@ func TestParseInventoryInfo(t *testing.T) {
 		})
 	}
 }
+
+func TestParseEquipment(t *testing.T) {
+	tests := []struct {
+		name     string
+		lines    []string
+		expected []EquipmentSlot
+	}{
+		{
+			name: "worn items by slot",
+			lines: []string{
+				"86H 109V 7563X 0.00% 79C T:3 Exits:D> eq",
+				"You are using:",
+				"<used as light>      a torch",
+				"<worn on body>       a bronze breastplate",
+				"\x1b[33m<worn around neck>\x1b[0m   a silver amulet (glowing)",
+				"<wielded>            a sharp short sword",
+				"",
+				"86H 109V 7563X 0.00% 79C T:2 Exits:D>",
+			},
+			expected: []EquipmentSlot{
+				{Slot: "used as light", Item: "a torch"},
+				{Slot: "worn on body", Item: "a bronze breastplate"},
+				{Slot: "worn around neck", Item: "a silver amulet (glowing)"},
+				{Slot: "wielded", Item: "a sharp short sword"},
+			},
+		},
+		{
+			name: "nothing worn",
+			lines: []string{
+				"You are using:",
+				"Nothing.",
+				"86H 109V 7563X 0.00% 79C T:2 Exits:D>",
+			},
+			expected: []EquipmentSlot{},
+		},
+		{
+			name: "inventory is not equipment",
+			lines: []string{
+				"You are carrying:",
+				"a torch",
+				"86H 109V 7563X 0.00% 79C T:2 Exits:D>",
+			},
+			expected: nil,
+		},
+		{
+			name: "incomplete listing (no closing prompt)",
+			lines: []string{
+				"You are using:",
+				"<worn on body>       a bronze breastplate",
+			},
+			expected: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ParseEquipmentWithPrompt(tt.lines, IsPromptLine)
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("Expected %#v, got %#v", tt.expected, got)
+			}
+		})
+	}
+}
@@ -3,6 +3,8 @@ package mapper
 import (
 	"strings"
 	"testing"
+
+	"github.com/charmbracelet/x/ansi"
 )
 
 // TestRenderMapWithLegend verifies that room numbers appear in the map when legend is provided
@@ -148,3 +150,68 @@ func TestFormatMapPanelWithLegend(t *testing.T) {
 		t.Errorf("Expected to find at least 3 room numbers in panel, found %d", foundNumbers)
 	}
 }
+
+// TestFormatMapPanelWithLegendPlacesNumbers verifies each legend number is
+// drawn right after its own room, and two-digit numbers keep the columns
+// lined up
+func TestFormatMapPanelWithLegendPlacesNumbers(t *testing.T) {
+	m := NewMap()
+
+	center := NewRoom("Center Plaza", "The center of the plaza.", []string{"north", "east"})
+	north := NewRoom("North Market", "A bustling northern market.", []string{"south"})
+	east := NewRoom("East Temple", "The eastern temple.", []string{"west"})
+
+	m.AddOrUpdateRoom(center)
+	m.SetLastDirection("north")
+	m.AddOrUpdateRoom(north)
+	m.SetLastDirection("south")
+	m.AddOrUpdateRoom(center)
+	m.SetLastDirection("east")
+	m.AddOrUpdateRoom(east)
+	m.SetLastDirection("west")
+	m.AddOrUpdateRoom(center)
+
+	legend := map[string]int{
+		center.ID: 3,
+		north.ID:  12,
+		east.ID:   7,
+	}
+	panel := ansi.Strip(m.FormatMapPanelWithLegend(30, 15, legend))
+	t.Logf("Formatted panel with legend:\n%s", panel)
+
+	lines := strings.Split(panel, "\n")
+	column := func(line, s string) int {
+		i := strings.Index(line, s)
+		if i < 0 {
+			return -1
+		}
+		return len([]rune(line[:i]))
+	}
+
+	centerLine, northLine := -1, -1
+	for i, line := range lines {
+		if strings.Contains(line, "▣3") {
+			centerLine = i
+		}
+		if strings.Contains(line, "▢12") {
+			northLine = i
+		}
+	}
+	if centerLine < 0 || northLine < 0 {
+		t.Fatalf("Expected the current room numbered 3 and the north room numbered 12")
+	}
+	if northLine != centerLine-2 {
+		t.Errorf("Expected room 12 two lines above room 3, got lines %d and %d", northLine, centerLine)
+	}
+	if !strings.Contains(lines[centerLine], "▣3───▢7") {
+		t.Errorf("Expected room 7 east of room 3 with the connection through the padding, got %q", lines[centerLine])
+	}
+
+	// The two-digit number doesn't shift its room out of its column
+	if column(lines[northLine], "▢12") != column(lines[centerLine], "▣3") {
+		t.Errorf("Expected room 12 above room 3:\n%s\n%s", lines[northLine], lines[centerLine])
+	}
+	if column(lines[centerLine-1], "│") != column(lines[centerLine], "▣3") {
+		t.Errorf("Expected the north connection below room 12 to line up:\n%s", lines[centerLine-1])
+	}
+}
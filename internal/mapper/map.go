@@ -1,23 +1,33 @@
 package mapper
 
 import (
+	"container/heap"
 	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
 	"sort"
 	"strings"
 )
 
 // Map represents the entire MUD world map
 type Map struct {
-	Rooms          map[string]*Room `json:"rooms"`            // roomID -> Room
-	CurrentRoomID  string           `json:"current_room_id"`  // ID of current room
-	PreviousRoomID string           `json:"previous_room_id"` // ID of previous room (for linking)
-	LastDirection  string           `json:"last_direction"`   // Last movement direction
-	RoomNumbering  []string         `json:"room_numbering"`   // Ordered list of room IDs for durable numbering
-	BarsoomMode    bool             `json:"barsoom_mode"`     // Whether this MUD uses Barsoom room format
+	Rooms          map[string]*Room `json:"rooms"`                     // roomID -> Room
+	CurrentRoomID  string           `json:"current_room_id"`           // ID of current room
+	PreviousRoomID string           `json:"previous_room_id"`          // ID of previous room (for linking)
+	LastDirection  string           `json:"last_direction"`            // Last movement direction
+	RoomNumbering  []string         `json:"room_numbering"`            // Ordered list of room IDs for durable numbering
+	BarsoomMode    bool             `json:"barsoom_mode"`              // Whether this MUD uses Barsoom room format
+	UseCoordinates bool             `json:"use_coordinates,omitempty"` // Render rooms at their assigned positions
+	Orientation    string           `json:"orientation,omitempty"`     // Direction at the top of the map panel ("" = north)
+	ShowCompass    bool             `json:"show_compass,omitempty"`    // Draw the current room's exits as a compass below the map
+	PromptPattern  string           `json:"prompt_pattern,omitempty"`  // Regular expression matching this MUD's prompt lines ("" = built-in check)
+	ReconnectOn    string           `json:"reconnect_on,omitempty"`    // Regular expression matching this MUD's reboot warning ("" = off)
+	ReconnectDelay int              `json:"reconnect_delay,omitempty"` // Seconds to wait after the warning before reconnecting
 	mapPath        string           // Path to the map file (not serialized)
+	promptRegex    *regexp.Regexp   // Compiled PromptPattern (not serialized)
+	reconnectRegex *regexp.Regexp   // Compiled ReconnectOn (not serialized)
 }
 
 // NewMap creates a new empty map
@@ -27,8 +37,8 @@ func NewMap() *Map {
 	}
 }
 
-// GetMapPath returns the path to the map file (legacy function for backward compatibility)
-func GetMapPath() (string, error) {
+// getConfigDir returns the directory holding the map files, creating it
+func getConfigDir() (string, error) {
 	var configDir string
 
 	// Check for environment variable override
@@ -46,26 +56,24 @@ func GetMapPath() (string, error) {
 		return "", fmt.Errorf("failed to create config directory: %w", err)
 	}
 
+	return configDir, nil
+}
+
+// GetMapPath returns the path to the map file (legacy function for backward compatibility)
+func GetMapPath() (string, error) {
+	configDir, err := getConfigDir()
+	if err != nil {
+		return "", err
+	}
+
 	return filepath.Join(configDir, "map.json"), nil
 }
 
 // GetMapPathForServer returns the path to the map file for a specific server
 func GetMapPathForServer(host string, port int) (string, error) {
-	var configDir string
-
-	// Check for environment variable override
-	if envConfigDir := os.Getenv("DIKUCLIENT_CONFIG_DIR"); envConfigDir != "" {
-		configDir = envConfigDir
-	} else {
-		homeDir, err := os.UserHomeDir()
-		if err != nil {
-			return "", fmt.Errorf("failed to get home directory: %w", err)
-		}
-		configDir = filepath.Join(homeDir, ".config", "dikuclient")
-	}
-
-	if err := os.MkdirAll(configDir, 0700); err != nil {
-		return "", fmt.Errorf("failed to create config directory: %w", err)
+	configDir, err := getConfigDir()
+	if err != nil {
+		return "", err
 	}
 
 	// Create filename: map.<hostname>.<port>.json
@@ -148,6 +156,12 @@ func (m *Map) Save() error {
 		}
 	}
 
+	return m.SaveTo(mapPath)
+}
+
+// SaveTo writes the map to the given file. Later saves still go to the
+// file the map was loaded from.
+func (m *Map) SaveTo(mapPath string) error {
 	data, err := json.MarshalIndent(m, "", "  ")
 	if err != nil {
 		return fmt.Errorf("failed to marshal map: %w", err)
@@ -188,6 +202,7 @@ func (m *Map) AddOrUpdateRoom(room *Room) {
 		// Link current room (where we are now) to new room (where we're going)
 		if fromRoom, exists := m.Rooms[m.CurrentRoomID]; exists {
 			fromRoom.UpdateExit(m.LastDirection, room.ID)
+			m.assignPosition(fromRoom, currentRoom, m.LastDirection)
 		}
 
 		// Link new room back to current room (reverse direction)
@@ -197,6 +212,11 @@ func (m *Map) AddOrUpdateRoom(room *Room) {
 		}
 	}
 
+	// The very first room anchors the coordinate system
+	if currentRoom.Position == nil && !m.hasPositions() {
+		currentRoom.Position = &Position{}
+	}
+
 	// Update current room tracking
 	m.PreviousRoomID = m.CurrentRoomID
 	m.CurrentRoomID = room.ID
@@ -221,129 +241,195 @@ func (m *Map) FindRooms(query string) []*Room {
 		}
 	}
 
+	// Return matches in durable room number order so numbered selection
+	// (e.g. /go 2 temple) is stable between searches
+	numbers := make(map[string]int, len(m.RoomNumbering))
+	for i, id := range m.RoomNumbering {
+		numbers[id] = i + 1
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		numI, numJ := numbers[matches[i].ID], numbers[matches[j].ID]
+		if numI != numJ {
+			// Unnumbered rooms sort last
+			if numI == 0 || numJ == 0 {
+				return numJ == 0
+			}
+			return numI < numJ
+		}
+		return matches[i].ID < matches[j].ID
+	})
+
 	return matches
 }
 
 // FindPath finds the shortest path from current room to target room
 func (m *Map) FindPath(targetRoomID string) []string {
-	if m.CurrentRoomID == "" || targetRoomID == "" {
+	return m.FindPathFrom(m.CurrentRoomID, targetRoomID)
+}
+
+// avoidCost is the extra cost of stepping into a room marked Avoid. It is large
+// enough that any safe route is preferred, but avoided rooms are still used
+// when there is no alternative.
+const avoidCost = 1000
+
+// pathQueueItem is an entry in the priority queue used by shortestPath
+type pathQueueItem struct {
+	roomID string
+	cost   int
+}
+
+// pathQueue is a min-heap of rooms ordered by path cost
+type pathQueue []pathQueueItem
+
+func (q pathQueue) Len() int            { return len(q) }
+func (q pathQueue) Less(i, j int) bool  { return q[i].cost < q[j].cost }
+func (q pathQueue) Swap(i, j int)       { q[i], q[j] = q[j], q[i] }
+func (q *pathQueue) Push(x interface{}) { *q = append(*q, x.(pathQueueItem)) }
+func (q *pathQueue) Pop() interface{} {
+	old := *q
+	item := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return item
+}
+
+// shortestPath finds the cheapest path between two rooms using Dijkstra's
+// algorithm. Every step costs 1, plus avoidCost when entering an avoided room
+// other than the target. Returns nil if no path exists.
+func (m *Map) shortestPath(startRoomID, targetRoomID string) []PathStep {
+	if startRoomID == "" || targetRoomID == "" {
 		return nil
 	}
 
-	if m.CurrentRoomID == targetRoomID {
-		return []string{} // Already at target
+	if startRoomID == targetRoomID {
+		return []PathStep{} // Already at target
 	}
 
-	// BFS to find shortest path
-	type queueItem struct {
-		roomID string
-		path   []string
-	}
+	dist := map[string]int{startRoomID: 0}
+	prevRoom := make(map[string]string)
+	prevDirection := make(map[string]string)
+	done := make(map[string]bool)
 
-	visited := make(map[string]bool)
-	queue := []queueItem{{roomID: m.CurrentRoomID, path: []string{}}}
-	visited[m.CurrentRoomID] = true
+	queue := &pathQueue{{roomID: startRoomID, cost: 0}}
+	for queue.Len() > 0 {
+		current := heap.Pop(queue).(pathQueueItem)
+		if done[current.roomID] {
+			continue
+		}
+		done[current.roomID] = true
 
-	for len(queue) > 0 {
-		current := queue[0]
-		queue = queue[1:]
+		if current.roomID == targetRoomID {
+			break
+		}
 
 		room := m.Rooms[current.roomID]
 		if room == nil {
 			continue
 		}
 
-		// Check all exits
-		for direction, destID := range room.Exits {
+		// Visit exits in a stable order so equal-cost paths are deterministic
+		directions := make([]string, 0, len(room.Exits))
+		for direction := range room.Exits {
+			directions = append(directions, direction)
+		}
+		sort.Strings(directions)
+
+		for _, direction := range directions {
+			destID := room.Exits[direction]
 			if destID == "" {
 				continue // Unknown destination
 			}
 
-			if destID == targetRoomID {
-				// Found target!
-				return append(current.path, direction)
+			destRoom := m.Rooms[destID]
+			if destRoom == nil && destID != targetRoomID {
+				continue
 			}
 
-			if !visited[destID] {
-				visited[destID] = true
-				newPath := make([]string, len(current.path)+1)
-				copy(newPath, current.path)
-				newPath[len(current.path)] = direction
-				queue = append(queue, queueItem{roomID: destID, path: newPath})
+			cost := current.cost + 1
+			if destRoom != nil && destRoom.Avoid && destID != targetRoomID {
+				cost += avoidCost
+			}
+
+			if old, seen := dist[destID]; !seen || cost < old {
+				dist[destID] = cost
+				prevRoom[destID] = current.roomID
+				prevDirection[destID] = direction
+				heap.Push(queue, pathQueueItem{roomID: destID, cost: cost})
 			}
 		}
 	}
 
-	return nil // No path found
+	if _, found := prevRoom[targetRoomID]; !found {
+		return nil // No path found
+	}
+
+	// Walk back from the target to build the path
+	var path []PathStep
+	for roomID := targetRoomID; roomID != startRoomID; roomID = prevRoom[roomID] {
+		step := PathStep{Direction: prevDirection[roomID], RoomID: roomID}
+		if room := m.Rooms[roomID]; room != nil {
+			step.RoomTitle = room.Title
+		}
+		path = append(path, step)
+	}
+	for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+		path[i], path[j] = path[j], path[i]
+	}
+
+	return path
+}
+
+// FindPathFrom finds the shortest path between two arbitrary rooms,
+// preferring routes that don't pass through avoided rooms
+func (m *Map) FindPathFrom(startRoomID, targetRoomID string) []string {
+	steps := m.shortestPath(startRoomID, targetRoomID)
+	if steps == nil {
+		return nil
+	}
+
+	path := make([]string, len(steps))
+	for i, step := range steps {
+		path[i] = step.Direction
+	}
+	return path
 }
 
 // PathStep represents a single step in a path with direction and destination room
 type PathStep struct {
 	Direction string
 	RoomTitle string
+	RoomID    string
 }
 
 // FindPathWithRooms finds the shortest path and returns steps with room information
 func (m *Map) FindPathWithRooms(targetRoomID string) []PathStep {
-	if m.CurrentRoomID == "" || targetRoomID == "" {
-		return nil
-	}
-
-	if m.CurrentRoomID == targetRoomID {
-		return []PathStep{} // Already at target
-	}
-
-	// BFS to find shortest path
-	type queueItem struct {
-		roomID string
-		path   []PathStep
-	}
-
-	visited := make(map[string]bool)
-	queue := []queueItem{{roomID: m.CurrentRoomID, path: []PathStep{}}}
-	visited[m.CurrentRoomID] = true
+	return m.FindPathWithRoomsFrom(m.CurrentRoomID, targetRoomID)
+}
 
-	for len(queue) > 0 {
-		current := queue[0]
-		queue = queue[1:]
+// FindPathWithRoomsFrom finds the shortest path between two arbitrary rooms
+// and returns steps with room information
+func (m *Map) FindPathWithRoomsFrom(startRoomID, targetRoomID string) []PathStep {
+	return m.shortestPath(startRoomID, targetRoomID)
+}
 
-		room := m.Rooms[current.roomID]
+// AvoidedRoomsOnPath returns the avoided rooms a path passes through on the
+// way to its destination (the destination itself is not included)
+func (m *Map) AvoidedRoomsOnPath(startRoomID string, path []string) []*Room {
+	var avoided []*Room
+	roomID := startRoomID
+	for i, direction := range path {
+		room := m.Rooms[roomID]
 		if room == nil {
-			continue
+			break
 		}
-
-		// Check all exits
-		for direction, destID := range room.Exits {
-			if destID == "" {
-				continue // Unknown destination
-			}
-
-			destRoom := m.Rooms[destID]
-			if destRoom == nil {
-				continue
-			}
-
-			step := PathStep{
-				Direction: direction,
-				RoomTitle: destRoom.Title,
-			}
-
-			if destID == targetRoomID {
-				// Found target!
-				return append(current.path, step)
-			}
-
-			if !visited[destID] {
-				visited[destID] = true
-				newPath := make([]PathStep, len(current.path)+1)
-				copy(newPath, current.path)
-				newPath[len(current.path)] = step
-				queue = append(queue, queueItem{roomID: destID, path: newPath})
-			}
+		roomID = room.Exits[direction]
+		if i == len(path)-1 {
+			break
+		}
+		if next := m.Rooms[roomID]; next != nil && next.Avoid {
+			avoided = append(avoided, next)
 		}
 	}
-
-	return nil // No path found
+	return avoided
 }
 
 // GetCurrentRoom returns the current room
@@ -71,6 +71,27 @@ func TestMapFindRooms(t *testing.T) {
 	}
 }
 
+func TestMapFindRoomsOrderedByRoomNumber(t *testing.T) {
+	m := NewMap()
+
+	for _, title := range []string{"Temple Square", "Market Street", "Temple Entrance", "Temple Garden"} {
+		m.AddOrUpdateRoom(NewRoom(title, "A room near the temple.", []string{"north"}))
+	}
+
+	// Map iteration order is random, so repeat to catch unstable ordering
+	for i := 0; i < 20; i++ {
+		results := m.FindRooms("temple")
+		if len(results) != 4 {
+			t.Fatalf("Found %d rooms with 'temple', want 4", len(results))
+		}
+		for j := 1; j < len(results); j++ {
+			if m.GetRoomNumber(results[j-1].ID) > m.GetRoomNumber(results[j].ID) {
+				t.Fatalf("Results not in room number order: %s before %s", results[j-1].Title, results[j].Title)
+			}
+		}
+	}
+}
+
 func TestMapPathfinding(t *testing.T) {
 	m := NewMap()
 
@@ -127,6 +148,58 @@ func TestMapPathfinding(t *testing.T) {
 	}
 }
 
+func TestFindPathFromNonCurrentRoom(t *testing.T) {
+	m := NewMap()
+
+	// Linear path: room1 -> room2 -> room3 -> room4, current room is room4
+	room1 := NewRoom("Room 1", "First room.", []string{"north"})
+	room2 := NewRoom("Room 2", "Second room.", []string{"north", "south"})
+	room3 := NewRoom("Room 3", "Third room.", []string{"north", "south"})
+	room4 := NewRoom("Room 4", "Fourth room.", []string{"south"})
+
+	m.AddOrUpdateRoom(room1)
+	m.SetLastDirection("north")
+	m.AddOrUpdateRoom(room2)
+	m.SetLastDirection("north")
+	m.AddOrUpdateRoom(room3)
+	m.SetLastDirection("north")
+	m.AddOrUpdateRoom(room4)
+
+	if m.CurrentRoomID != room4.ID {
+		t.Fatalf("Expected current room to be room4, got %q", m.CurrentRoomID)
+	}
+
+	// Path between two rooms that are not the current room
+	path := m.FindPathFrom(room1.ID, room3.ID)
+	if len(path) != 2 || path[0] != "north" || path[1] != "north" {
+		t.Errorf("FindPathFrom(room1, room3) = %v, want [north north]", path)
+	}
+
+	steps := m.FindPathWithRoomsFrom(room3.ID, room1.ID)
+	if len(steps) != 2 {
+		t.Fatalf("FindPathWithRoomsFrom(room3, room1) returned %d steps, want 2", len(steps))
+	}
+	if steps[0].Direction != "south" || steps[0].RoomTitle != "Room 2" {
+		t.Errorf("First step = %+v, want south -> Room 2", steps[0])
+	}
+	if steps[1].Direction != "south" || steps[1].RoomTitle != "Room 1" {
+		t.Errorf("Second step = %+v, want south -> Room 1", steps[1])
+	}
+
+	// Same room yields an empty path, unknown rooms yield nil
+	if path := m.FindPathFrom(room2.ID, room2.ID); path == nil || len(path) != 0 {
+		t.Errorf("FindPathFrom(room2, room2) = %v, want empty path", path)
+	}
+	if path := m.FindPathFrom("", room2.ID); path != nil {
+		t.Errorf("FindPathFrom with empty start = %v, want nil", path)
+	}
+
+	// Current room is unchanged
+	if m.CurrentRoomID != room4.ID {
+		t.Error("FindPathFrom should not change the current room")
+	}
+}
+
 func TestReverseDirection(t *testing.T) {
 	tests := []struct {
 		input    string
@@ -379,3 +452,95 @@ func TestBarsoomModePersistence(t *testing.T) {
 		t.Errorf("Expected 1 room, got %d", len(loaded.Rooms))
 	}
 }
+
+// buildAvoidTestMap builds a map with a short route through a danger room and
+// a longer safe detour:
+//
+//	Start -east-> Danger -east-> Goal
+//	Start -north-> Path1 -east-> Path2 -south-> Goal
+func buildAvoidTestMap() (*Map, *Room, *Room, *Room) {
+	m := NewMap()
+
+	start := NewRoom("Start", "The start.", []string{"east", "north"})
+	danger := NewRoom("Danger", "A dragon lair.", []string{"east", "west"})
+	goal := NewRoom("Goal", "The goal.", []string{"west", "north"})
+	path1 := NewRoom("Path 1", "A winding path.", []string{"east", "south"})
+	path2 := NewRoom("Path 2", "More winding path.", []string{"west", "south"})
+
+	for _, r := range []*Room{start, danger, goal, path1, path2} {
+		m.Rooms[r.ID] = r
+	}
+
+	start.Exits["east"] = danger.ID
+	danger.Exits["west"] = start.ID
+	danger.Exits["east"] = goal.ID
+	goal.Exits["west"] = danger.ID
+
+	start.Exits["north"] = path1.ID
+	path1.Exits["south"] = start.ID
+	path1.Exits["east"] = path2.ID
+	path2.Exits["west"] = path1.ID
+	path2.Exits["south"] = goal.ID
+	goal.Exits["north"] = path2.ID
+
+	m.CurrentRoomID = start.ID
+	return m, start, danger, goal
+}
+
+func TestFindPathPrefersSafeRoute(t *testing.T) {
+	m, start, danger, goal := buildAvoidTestMap()
+
+	// Without avoid, the direct route through the danger room is shortest
+	path := m.FindPath(goal.ID)
+	if len(path) != 2 || path[0] != "east" {
+		t.Fatalf("Expected direct path [east east], got %v", path)
+	}
+
+	danger.Avoid = true
+
+	path = m.FindPath(goal.ID)
+	expected := []string{"north", "east", "south"}
+	if len(path) != len(expected) {
+		t.Fatalf("Expected safe path %v, got %v", expected, path)
+	}
+	for i := range expected {
+		if path[i] != expected[i] {
+			t.Errorf("Step %d: expected %s, got %s", i, expected[i], path[i])
+		}
+	}
+	if avoided := m.AvoidedRoomsOnPath(start.ID, path); len(avoided) != 0 {
+		t.Errorf("Expected safe path to avoid all dangerous rooms, got %d", len(avoided))
+	}
+
+	steps := m.FindPathWithRooms(goal.ID)
+	if len(steps) != 3 || steps[2].RoomID != goal.ID {
+		t.Errorf("Expected FindPathWithRooms to take the safe route, got %+v", steps)
+	}
+}
+
+func TestFindPathUsesAvoidedRoomWhenNoAlternative(t *testing.T) {
+	m, start, danger, goal := buildAvoidTestMap()
+	danger.Avoid = true
+
+	// Cut off the safe detour
+	delete(start.Exits, "north")
+
+	path := m.FindPath(goal.ID)
+	if len(path) != 2 || path[0] != "east" || path[1] != "east" {
+		t.Fatalf("Expected path through avoided room [east east], got %v", path)
+	}
+
+	avoided := m.AvoidedRoomsOnPath(start.ID, path)
+	if len(avoided) != 1 || avoided[0].ID != danger.ID {
+		t.Errorf("Expected the danger room to be reported as avoided, got %v", avoided)
+	}
+
+	// An avoided destination is still reachable directly and not reported
+	path = m.FindPath(danger.ID)
+	if len(path) != 1 || path[0] != "east" {
+		t.Errorf("Expected direct path to avoided destination, got %v", path)
+	}
+	if avoided := m.AvoidedRoomsOnPath(start.ID, path); len(avoided) != 0 {
+		t.Errorf("Expected destination not to be reported as avoided, got %d", len(avoided))
+	}
+}
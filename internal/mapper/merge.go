@@ -0,0 +1,143 @@
+package mapper
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// MergeRooms folds the room duplicateID into keepID: exits are combined,
+// every exit leading to the duplicate is redirected to the kept room and
+// visit counts are added up. The duplicate is removed from the map and its
+// room number is retired, so every other room keeps its number.
+func (m *Map) MergeRooms(keepID, duplicateID string) error {
+	if keepID == duplicateID {
+		return fmt.Errorf("cannot merge a room with itself")
+	}
+	keep, ok := m.Rooms[keepID]
+	if !ok {
+		return fmt.Errorf("room not found: %s", keepID)
+	}
+	duplicate, ok := m.Rooms[duplicateID]
+	if !ok {
+		return fmt.Errorf("room not found: %s", duplicateID)
+	}
+
+	// Exits the kept room doesn't know about come from the duplicate
+	for direction, destID := range duplicate.Exits {
+		if destID == duplicateID {
+			destID = keepID
+		}
+		if existing, has := keep.Exits[direction]; !has || existing == "" {
+			keep.Exits[direction] = destID
+		}
+	}
+
+	// Redirect exits leading to the duplicate
+	for _, room := range m.Rooms {
+		for direction, destID := range room.Exits {
+			if destID == duplicateID {
+				room.Exits[direction] = keepID
+			}
+		}
+	}
+
+	keep.VisitCount += duplicate.VisitCount
+	keep.Avoid = keep.Avoid || duplicate.Avoid
+	if keep.Position == nil {
+		keep.Position = duplicate.Position
+	}
+
+	if m.CurrentRoomID == duplicateID {
+		m.CurrentRoomID = keepID
+	}
+	if m.PreviousRoomID == duplicateID {
+		m.PreviousRoomID = keepID
+	}
+
+	delete(m.Rooms, duplicateID)
+	for i, id := range m.RoomNumbering {
+		if id == duplicateID {
+			m.RoomNumbering[i] = ""
+		}
+	}
+
+	// The duplicate often shows up as a coordinate conflict with the room it
+	// duplicates; clear the flag from rooms that no longer share a cell
+	for _, pos := range []*Position{keep.Position, duplicate.Position} {
+		if pos != nil {
+			m.refreshConflicts(*pos)
+		}
+	}
+
+	return nil
+}
+
+// refreshConflicts clears the conflict flag from a room left alone at pos
+func (m *Map) refreshConflicts(pos Position) {
+	var rooms []*Room
+	for _, room := range m.Rooms {
+		if room.Position != nil && *room.Position == pos {
+			rooms = append(rooms, room)
+		}
+	}
+	if len(rooms) == 1 {
+		rooms[0].Conflict = false
+	}
+}
+
+// MergeCandidates returns pairs of rooms that are probably the same place:
+// they have the same title and the same exits, and no exit they both know
+// leads to different rooms. Pairs are in room number order.
+func (m *Map) MergeCandidates() [][2]*Room {
+	byKey := make(map[string][]*Room)
+	for _, id := range m.sortedRoomIDs() {
+		room := m.Rooms[id]
+		byKey[mergeKey(room)] = append(byKey[mergeKey(room)], room)
+	}
+
+	var candidates [][2]*Room
+	for _, rooms := range byKey {
+		for i := 0; i < len(rooms); i++ {
+			for j := i + 1; j < len(rooms); j++ {
+				if exitsAgree(rooms[i], rooms[j]) {
+					candidates = append(candidates, [2]*Room{rooms[i], rooms[j]})
+				}
+			}
+		}
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i][0] != candidates[j][0] {
+			return m.roomOrder(candidates[i][0].ID, candidates[j][0].ID)
+		}
+		return m.roomOrder(candidates[i][1].ID, candidates[j][1].ID)
+	})
+	return candidates
+}
+
+// mergeKey groups rooms by title and exit directions
+func mergeKey(room *Room) string {
+	directions := make([]string, 0, len(room.Exits))
+	for direction := range room.Exits {
+		directions = append(directions, direction)
+	}
+	sort.Strings(directions)
+	return strings.ToLower(room.Title) + "|" + strings.Join(directions, ",")
+}
+
+// exitsAgree reports whether no exit known in both rooms leads to different
+// places
+func exitsAgree(a, b *Room) bool {
+	for direction, destA := range a.Exits {
+		destB := b.Exits[direction]
+		if destA == "" || destB == "" || destA == destB {
+			continue
+		}
+		// Exits leading back into the pair itself agree too
+		if (destA == a.ID || destA == b.ID) && (destB == a.ID || destB == b.ID) {
+			continue
+		}
+		return false
+	}
+	return true
+}
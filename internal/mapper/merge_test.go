@@ -0,0 +1,142 @@
+package mapper
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestMergeRoomsRedirectsExits(t *testing.T) {
+	m := NewMap()
+
+	square := NewRoom("Temple Square", "A large square.", []string{"north", "east"})
+	street := NewRoom("Market Street", "A busy street.", []string{"south", "east"})
+	shop := NewRoom("Shop", "A small shop.", []string{"west"})
+	// The same square seen with a changed description, so it got its own ID
+	squareAgain := NewRoom("Temple Square", "A large square, full of pigeons.", []string{"north", "east"})
+	gate := NewRoom("Gate", "The east gate.", []string{"west"})
+
+	m.AddOrUpdateRoom(square)
+	walk(m, "north", street)
+	walk(m, "east", shop)
+	walk(m, "west", street)
+	walk(m, "south", squareAgain)
+	walk(m, "east", gate)
+
+	if street.Exits["south"] != squareAgain.ID {
+		t.Fatalf("Expected the street to lead to the duplicate, got %q", street.Exits["south"])
+	}
+	squareNumber, streetNumber, gateNumber := m.GetRoomNumber(square.ID), m.GetRoomNumber(street.ID), m.GetRoomNumber(gate.ID)
+	duplicateNumber := m.GetRoomNumber(squareAgain.ID)
+
+	if err := m.MergeRooms(square.ID, squareAgain.ID); err != nil {
+		t.Fatalf("Failed to merge rooms: %v", err)
+	}
+
+	if _, exists := m.Rooms[squareAgain.ID]; exists {
+		t.Error("Expected the duplicate to be removed")
+	}
+	if street.Exits["south"] != square.ID {
+		t.Errorf("Expected the street's exit to be redirected to the kept room, got %q", street.Exits["south"])
+	}
+	if gate.Exits["west"] != square.ID {
+		t.Errorf("Expected the gate's exit to be redirected to the kept room, got %q", gate.Exits["west"])
+	}
+	if square.Exits["north"] != street.ID || square.Exits["east"] != gate.ID {
+		t.Errorf("Expected the exits to be combined, got %v", square.Exits)
+	}
+	if square.VisitCount != 2 {
+		t.Errorf("Expected visit counts to be summed to 2, got %d", square.VisitCount)
+	}
+	if m.PreviousRoomID != square.ID {
+		t.Errorf("Expected the previous room to point at the kept room, got %q", m.PreviousRoomID)
+	}
+
+	// Numbers stay durable: the duplicate's is retired, the rest don't move
+	if m.GetRoomNumber(square.ID) != squareNumber || m.GetRoomNumber(street.ID) != streetNumber || m.GetRoomNumber(gate.ID) != gateNumber {
+		t.Error("Expected the other rooms to keep their numbers")
+	}
+	if m.GetRoomByNumber(duplicateNumber) != nil {
+		t.Errorf("Expected room #%d to be retired", duplicateNumber)
+	}
+
+	// Both squares sat at (0, 0, 0); the conflict is resolved
+	if square.Conflict {
+		t.Error("Expected the conflict to be cleared once the duplicate is gone")
+	}
+
+	// The merged map finds paths through the kept room
+	m.CurrentRoomID = gate.ID
+	if path := m.FindPath(street.ID); len(path) != 2 || path[0] != "west" || path[1] != "north" {
+		t.Errorf("Expected gate -> square -> street, got %v", path)
+	}
+}
+
+func TestMergeRoomsErrors(t *testing.T) {
+	m := NewMap()
+	room := NewRoom("Hall", "A hall.", []string{"north"})
+	m.AddOrUpdateRoom(room)
+
+	if err := m.MergeRooms(room.ID, room.ID); err == nil {
+		t.Error("Expected merging a room with itself to fail")
+	}
+	if err := m.MergeRooms(room.ID, "missing"); err == nil {
+		t.Error("Expected merging a missing room to fail")
+	}
+	if err := m.MergeRooms("missing", room.ID); err == nil {
+		t.Error("Expected merging into a missing room to fail")
+	}
+}
+
+func TestMergeCandidates(t *testing.T) {
+	m := NewMap()
+
+	hall := NewRoom("Hall", "A long hall.", []string{"north", "south"})
+	hallAgain := NewRoom("Hall", "A long, dusty hall.", []string{"north", "south"})
+	library := NewRoom("Library", "Books everywhere.", []string{"south"})
+	cellar := NewRoom("Cellar", "A damp cellar.", []string{"north"})
+	// Same title and exits, but its north exit leads somewhere else
+	otherHall := NewRoom("Hall", "Another hall.", []string{"north", "south"})
+	garden := NewRoom("Garden", "Flowers.", []string{"south"})
+
+	m.AddOrUpdateRoom(hall)
+	walk(m, "north", library)
+	walk(m, "south", hallAgain)
+	walk(m, "south", cellar)
+	m.AddOrUpdateRoom(otherHall)
+	walk(m, "north", garden)
+
+	candidates := m.MergeCandidates()
+	if len(candidates) != 1 {
+		t.Fatalf("Expected one candidate pair, got %d", len(candidates))
+	}
+	if candidates[0][0] != hall || candidates[0][1] != hallAgain {
+		t.Errorf("Expected the two halls leading to the library, got %s and %s", candidates[0][0].Description, candidates[0][1].Description)
+	}
+}
+
+func TestMergedMapPersists(t *testing.T) {
+	m := NewMap()
+	m.mapPath = filepath.Join(t.TempDir(), "map.json")
+
+	a := NewRoom("A", "Room a.", []string{"east"})
+	b := NewRoom("B", "Room b.", []string{"west"})
+	aAgain := NewRoom("A", "Room a, again.", []string{"east"})
+	m.AddOrUpdateRoom(a)
+	walk(m, "east", b)
+	walk(m, "west", aAgain)
+	m.MergeRooms(a.ID, aAgain.ID)
+	if err := m.Save(); err != nil {
+		t.Fatalf("Failed to save map: %v", err)
+	}
+
+	loaded, err := LoadFromPath(m.mapPath)
+	if err != nil {
+		t.Fatalf("Failed to load map: %v", err)
+	}
+	if len(loaded.Rooms) != 2 || loaded.GetRoomByNumber(3) != nil || loaded.GetRoomNumber(b.ID) != 2 {
+		t.Errorf("Expected two rooms with the third number retired, got %d rooms, numbering %q", len(loaded.Rooms), loaded.RoomNumbering)
+	}
+	if loaded.CurrentRoomID != a.ID {
+		t.Errorf("Expected the current room to be the kept room, got %q", loaded.CurrentRoomID)
+	}
+}
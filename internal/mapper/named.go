@@ -0,0 +1,99 @@
+package mapper
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// mapNamePattern matches the names allowed for named maps, which are used
+// as file names
+var mapNamePattern = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// ValidMapName reports whether name can be used for a named map
+func ValidMapName(name string) bool {
+	return mapNamePattern.MatchString(name)
+}
+
+// GetNamedMapsDir returns the directory holding the named maps, creating it
+func GetNamedMapsDir() (string, error) {
+	configDir, err := getConfigDir()
+	if err != nil {
+		return "", err
+	}
+
+	mapsDir := filepath.Join(configDir, "maps")
+	if err := os.MkdirAll(mapsDir, 0700); err != nil {
+		return "", fmt.Errorf("failed to create maps directory: %w", err)
+	}
+	return mapsDir, nil
+}
+
+// GetNamedMapPath returns the path to the file of a named map
+func GetNamedMapPath(name string) (string, error) {
+	if !ValidMapName(name) {
+		return "", fmt.Errorf("invalid map name '%s' (use letters, digits, - and _)", name)
+	}
+
+	mapsDir, err := GetNamedMapsDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(mapsDir, name+".json"), nil
+}
+
+// NamedMapExists reports whether a named map has been saved
+func NamedMapExists(name string) bool {
+	mapPath, err := GetNamedMapPath(name)
+	if err != nil {
+		return false
+	}
+	_, err = os.Stat(mapPath)
+	return err == nil
+}
+
+// ListNamedMaps returns the names of the saved named maps, sorted
+func ListNamedMaps() ([]string, error) {
+	mapsDir, err := GetNamedMapsDir()
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := os.ReadDir(mapsDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read maps directory: %w", err)
+	}
+
+	var names []string
+	for _, entry := range entries {
+		name := strings.TrimSuffix(entry.Name(), ".json")
+		if !entry.IsDir() && name != entry.Name() && ValidMapName(name) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// LoadNamed loads a named map. A map that hasn't been saved yet is empty and
+// is created by its first save.
+func LoadNamed(name string) (*Map, error) {
+	mapPath, err := GetNamedMapPath(name)
+	if err != nil {
+		return nil, err
+	}
+	return LoadFromPath(mapPath)
+}
+
+// SaveNamed writes a copy of the map as a named map. Later saves still go to
+// the file the map was loaded from.
+func (m *Map) SaveNamed(name string) error {
+	mapPath, err := GetNamedMapPath(name)
+	if err != nil {
+		return err
+	}
+	return m.SaveTo(mapPath)
+}
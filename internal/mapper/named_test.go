@@ -0,0 +1,52 @@
+package mapper
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNamedMaps(t *testing.T) {
+	t.Setenv("DIKUCLIENT_CONFIG_DIR", t.TempDir())
+
+	m := NewMap()
+	m.AddOrUpdateRoom(NewRoom("Temple", "A quiet temple.", []string{"south"}))
+	if err := m.SaveNamed("main"); err != nil {
+		t.Fatalf("SaveNamed() error = %v", err)
+	}
+	if err := NewMap().SaveNamed("experiment"); err != nil {
+		t.Fatalf("SaveNamed() error = %v", err)
+	}
+
+	names, err := ListNamedMaps()
+	if err != nil {
+		t.Fatalf("ListNamedMaps() error = %v", err)
+	}
+	if want := []string{"experiment", "main"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("ListNamedMaps() = %v, want %v", names, want)
+	}
+
+	loaded, err := LoadNamed("main")
+	if err != nil {
+		t.Fatalf("LoadNamed() error = %v", err)
+	}
+	if len(loaded.Rooms) != 1 {
+		t.Errorf("Expected the named map to keep its room, got %d rooms", len(loaded.Rooms))
+	}
+	if !NamedMapExists("main") || NamedMapExists("missing") {
+		t.Error("NamedMapExists() should report only saved maps")
+	}
+}
+
+func TestValidMapName(t *testing.T) {
+	for name, want := range map[string]bool{
+		"main":      true,
+		"alt-run_2": true,
+		"":          false,
+		"../secret": false,
+		"a b":       false,
+	} {
+		if got := ValidMapName(name); got != want {
+			t.Errorf("ValidMapName(%q) = %v, want %v", name, got, want)
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package mapper
+
+// OdometerWindow is how many of the latest moves the exploration rate covers
+const OdometerWindow = 20
+
+// Odometer counts the moves made and rooms entered in a session, and how
+// many of those rooms were new to the map. Fewer new rooms per move as an
+// area fills in shows there is less of it left to explore.
+type Odometer struct {
+	Moves    int   // Movement commands sent
+	Rooms    int   // Times a different room was entered
+	NewRooms int   // Rooms entered that weren't on the map yet
+	newAt    []int // Moves made when each recent new room was found
+}
+
+// Move counts a movement command
+func (o *Odometer) Move() {
+	o.Moves++
+	for len(o.newAt) > 0 && o.newAt[0] <= o.Moves-OdometerWindow {
+		o.newAt = o.newAt[1:]
+	}
+}
+
+// Enter counts entering a room, which isNew says wasn't on the map before
+func (o *Odometer) Enter(isNew bool) {
+	o.Rooms++
+	if isNew {
+		o.NewRooms++
+		o.newAt = append(o.newAt, o.Moves)
+	}
+}
+
+// RecentRate returns the new rooms found over the last OdometerWindow moves,
+// and how many moves that is (fewer early in a session)
+func (o *Odometer) RecentRate() (newRooms, moves int) {
+	moves = min(o.Moves, OdometerWindow)
+	for _, at := range o.newAt {
+		if at > o.Moves-moves {
+			newRooms++
+		}
+	}
+	return newRooms, moves
+}
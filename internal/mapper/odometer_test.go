@@ -0,0 +1,59 @@
+package mapper
+
+import "testing"
+
+func TestOdometerCountsMovesAndRooms(t *testing.T) {
+	m := NewMap()
+	var odometer Odometer
+
+	// enter adds a room to the map the way the client does, telling the
+	// odometer whether it was already known
+	enter := func(direction string, room *Room) {
+		odometer.Move()
+		_, known := m.Rooms[room.ID]
+		walk(m, direction, room)
+		odometer.Enter(!known)
+	}
+
+	square := NewRoom("Temple Square", "A large square.", []string{"north"})
+	hall := NewRoom("Hall", "A narrow hall.", []string{"south"})
+	enter("", square)
+	enter("north", hall)
+	enter("south", NewRoom("Temple Square", "A large square.", []string{"north"}))
+
+	// A move into a wall doesn't enter a room
+	odometer.Move()
+
+	if odometer.Moves != 4 {
+		t.Errorf("Expected 4 moves, got %d", odometer.Moves)
+	}
+	if odometer.Rooms != 3 {
+		t.Errorf("Expected 3 rooms entered, got %d", odometer.Rooms)
+	}
+	if odometer.NewRooms != 2 {
+		t.Errorf("Expected only the square and hall to be new, got %d", odometer.NewRooms)
+	}
+	if newRooms, moves := odometer.RecentRate(); newRooms != 2 || moves != 4 {
+		t.Errorf("Expected 2 new rooms in 4 moves, got %d in %d", newRooms, moves)
+	}
+}
+
+func TestOdometerRateCoversRecentMoves(t *testing.T) {
+	var odometer Odometer
+	for i := 0; i < 10; i++ {
+		odometer.Move()
+		odometer.Enter(true)
+	}
+	for i := 0; i < OdometerWindow-5; i++ {
+		odometer.Move()
+		odometer.Enter(false)
+	}
+
+	newRooms, moves := odometer.RecentRate()
+	if moves != OdometerWindow || newRooms != 5 {
+		t.Errorf("Expected 5 new rooms in the last %d moves, got %d in %d", OdometerWindow, newRooms, moves)
+	}
+	if odometer.NewRooms != 10 {
+		t.Errorf("Expected the session total to keep every new room, got %d", odometer.NewRooms)
+	}
+}
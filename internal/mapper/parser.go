@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"regexp"
 	"strings"
+
+	"github.com/anicolao/dikuclient/internal/ansi"
 )
 
 // RoomInfo contains parsed room information
@@ -52,7 +54,7 @@ func parseBarsoomRoom(lines []string, enableDebug bool, debugInfo *strings.Build
 
 	// First, find the end marker >-- (exits are on the same line)
 	for i := len(lines) - 1; i >= 0; i-- {
-		line := stripANSI(lines[i])
+		line := ansi.Strip(lines[i])
 		line = strings.TrimSpace(line)
 
 		// Find the end marker >-- (may have exits on the same line)
@@ -84,7 +86,7 @@ func parseBarsoomRoom(lines []string, enableDebug bool, debugInfo *strings.Build
 
 	// Now search backwards from the end marker to find the start marker
 	for i := endMarkerIdx - 1; i >= 0; i-- {
-		line := stripANSI(lines[i])
+		line := ansi.Strip(lines[i])
 		line = strings.TrimSpace(line)
 
 		// Find the start marker --<
@@ -106,7 +108,7 @@ func parseBarsoomRoom(lines []string, enableDebug bool, debugInfo *strings.Build
 	var title string
 	titleIdx := -1
 	for i := startMarkerIdx + 1; i < endMarkerIdx; i++ {
-		line := stripANSI(lines[i])
+		line := ansi.Strip(lines[i])
 		line = strings.TrimSpace(line)
 		if line != "" {
 			title = line
@@ -125,7 +127,7 @@ func parseBarsoomRoom(lines []string, enableDebug bool, debugInfo *strings.Build
 	// Collect description lines from after title to end marker
 	var descriptionLines []string
 	for i := titleIdx + 1; i < endMarkerIdx; i++ {
-		line := stripANSI(lines[i])
+		line := ansi.Strip(lines[i])
 		line = strings.TrimSpace(line)
 		if line != "" {
 			descriptionLines = append(descriptionLines, line)
@@ -168,6 +170,12 @@ func ParseBarsoomRoomOnly(lines []string, enableDebug bool) *RoomInfo {
 // New heuristic: search backwards for previous prompt, then forwards for first indented line
 // Also supports Barsoom MUD format with --< and >-- markers
 func ParseRoomInfo(lines []string, enableDebug bool) *RoomInfo {
+	return ParseRoomInfoWithPrompt(lines, enableDebug, IsPromptLine)
+}
+
+// ParseRoomInfoWithPrompt is ParseRoomInfo for a MUD whose prompt lines are
+// recognized by isPrompt (see Map.IsPrompt)
+func ParseRoomInfoWithPrompt(lines []string, enableDebug bool, isPrompt func(string) bool) *RoomInfo {
 	if len(lines) == 0 {
 		return nil
 	}
@@ -189,7 +197,7 @@ func ParseRoomInfo(lines []string, enableDebug bool) *RoomInfo {
 	exitsLineIdx := -1
 	var exits []string
 	for i := len(lines) - 1; i >= 0; i-- {
-		line := stripANSI(lines[i])
+		line := ansi.Strip(lines[i])
 		line = strings.TrimSpace(line)
 
 		if parsedExits := parseExitsLine(line); len(parsedExits) > 0 {
@@ -218,11 +226,11 @@ func ParseRoomInfo(lines []string, enableDebug bool) *RoomInfo {
 	previousPromptIdx := -1
 	previousExitsIdx := -1
 	for i := exitsLineIdx - 1; i >= 0; i-- {
-		line := stripANSI(lines[i])
+		line := ansi.Strip(lines[i])
 		line = strings.TrimSpace(line)
 
 		// A prompt line typically ends with > and contains stats (H, V, X, etc.)
-		if isPromptLine(line) {
+		if isPrompt(line) {
 			previousPromptIdx = i
 			if enableDebug {
 				debugInfo.WriteString(fmt.Sprintf("[MAPPER DEBUG] Found previous prompt at index %d: %q\n", i, line))
@@ -264,7 +272,7 @@ func ParseRoomInfo(lines []string, enableDebug bool) *RoomInfo {
 	firstIndentedIdx := -1
 	for i := startSearchIdx; i < exitsLineIdx; i++ {
 		line := lines[i] // Don't strip ANSI yet - we need to check original indentation
-		stripped := stripANSI(line)
+		stripped := ansi.Strip(line)
 
 		// Skip empty lines
 		if strings.TrimSpace(stripped) == "" {
@@ -288,7 +296,7 @@ func ParseRoomInfo(lines []string, enableDebug bool) *RoomInfo {
 	if firstIndentedIdx > startSearchIdx {
 		// Found indented line, so title is the line before it
 		for i := firstIndentedIdx - 1; i >= startSearchIdx; i-- {
-			line := stripANSI(lines[i])
+			line := ansi.Strip(lines[i])
 			line = strings.TrimSpace(line)
 
 			// Skip empty lines
@@ -319,7 +327,7 @@ func ParseRoomInfo(lines []string, enableDebug bool) *RoomInfo {
 	// Collect description from descriptionStartIdx until we hit exits or status/mob lines
 	var descriptionLines []string
 	for i := descriptionStartIdx; i < exitsLineIdx; i++ {
-		line := stripANSI(lines[i])
+		line := ansi.Strip(lines[i])
 		line = strings.TrimSpace(line)
 
 		// Skip empty lines
@@ -361,8 +369,8 @@ func ParseRoomInfo(lines []string, enableDebug bool) *RoomInfo {
 	}
 }
 
-// isPromptLine checks if a line looks like a MUD prompt
-func isPromptLine(line string) bool {
+// IsPromptLine checks if a line looks like a MUD prompt
+func IsPromptLine(line string) bool {
 	// Prompts typically end with > and contain stats like "119H 108V"
 	if !strings.HasSuffix(line, ">") {
 		return false
@@ -435,6 +443,11 @@ func isRoomTitle(line string) bool {
 	return true
 }
 
+// IsExitsLine checks if a line lists a room's exits
+func IsExitsLine(line string) bool {
+	return len(parseExitsLine(strings.TrimSpace(line))) > 0
+}
+
 // parseExitsLine extracts exit directions from an exits line
 func parseExitsLine(line string) []string {
 	// Try each pattern
@@ -513,12 +526,6 @@ func isValidDirection(dir string) bool {
 	return validDirections[strings.ToLower(dir)]
 }
 
-// stripANSI removes ANSI escape codes from a string
-func stripANSI(str string) string {
-	ansiRegex := regexp.MustCompile(`\x1b\[[0-9;]*[a-zA-Z]`)
-	return ansiRegex.ReplaceAllString(str, "")
-}
-
 // DetectMovement checks if a line represents a movement command
 func DetectMovement(line string) string {
 	line = strings.TrimSpace(strings.ToLower(line))
@@ -534,3 +541,27 @@ func DetectMovement(line string) string {
 
 	return ""
 }
+
+// forcedMovementPatterns match server messages for movement the player didn't
+// explicitly ask for (fleeing, teleports, being dragged or summoned)
+var forcedMovementPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`(?i)^you flee\b`),
+	regexp.MustCompile(`(?i)\bpanic,? and attempt to flee\b`),
+	regexp.MustCompile(`(?i)^you are magically transported\b`),
+	regexp.MustCompile(`(?i)^you are (dragged|pulled|summoned|teleported)\b`),
+	regexp.MustCompile(`(?i)^you have been (summoned|teleported|transported)\b`),
+	regexp.MustCompile(`(?i)^you feel yourself (being pulled|slipping away)\b`),
+	regexp.MustCompile(`(?i)\bdrags you\b`),
+}
+
+// DetectForcedMovement checks if a line of MUD output reports movement that
+// the player didn't initiate with a direction command
+func DetectForcedMovement(line string) bool {
+	line = strings.TrimSpace(ansi.Strip(line))
+	for _, pattern := range forcedMovementPatterns {
+		if pattern.MatchString(line) {
+			return true
+		}
+	}
+	return false
+}
@@ -538,3 +538,26 @@ func TestParseRoomInfo_ReceptionToInn(t *testing.T) {
 	t.Logf("Description: %q", info.Description)
 	t.Logf("Exits: %v", info.Exits)
 }
+
+func TestDetectForcedMovement(t *testing.T) {
+	tests := []struct {
+		line     string
+		expected bool
+	}{
+		{"You flee head over heels!", true},
+		{"You panic, and attempt to flee!", true},
+		{"You are magically transported to another place!", true},
+		{"\x1b[31mYou are dragged south by the troll.\x1b[0m", true},
+		{"You have been summoned!", true},
+		{"The troll drags you north.", true},
+		{"The goblin flees south.", false},
+		{"You say 'flee'", false},
+		{"Temple Square", false},
+	}
+
+	for _, tt := range tests {
+		if got := DetectForcedMovement(tt.line); got != tt.expected {
+			t.Errorf("DetectForcedMovement(%q) = %v, want %v", tt.line, got, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package mapper
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// SetPromptPattern sets the regular expression that recognizes this MUD's
+// prompt lines, for prompts the built-in check doesn't know. An empty
+// pattern goes back to the built-in check.
+func (m *Map) SetPromptPattern(pattern string) error {
+	if pattern == "" {
+		m.PromptPattern = ""
+		m.promptRegex = nil
+		return nil
+	}
+
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return fmt.Errorf("invalid prompt pattern: %w", err)
+	}
+	m.PromptPattern = pattern
+	m.promptRegex = re
+	return nil
+}
+
+// IsPrompt reports whether a line (without colors, trimmed) is a prompt,
+// using the prompt pattern when one is set and IsPromptLine otherwise
+func (m *Map) IsPrompt(line string) bool {
+	if m.PromptPattern == "" {
+		return IsPromptLine(line)
+	}
+
+	// A pattern loaded from disk is compiled on first use
+	if m.promptRegex == nil || m.promptRegex.String() != m.PromptPattern {
+		re, err := regexp.Compile(m.PromptPattern)
+		if err != nil {
+			return IsPromptLine(line)
+		}
+		m.promptRegex = re
+	}
+	return m.promptRegex.MatchString(line)
+}
@@ -0,0 +1,79 @@
+package mapper
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestPromptPatternRecognizesCustomPrompt(t *testing.T) {
+	m := NewMap()
+	prompt := "<120hp 80m 95mv>"
+	if m.IsPrompt(prompt) {
+		t.Fatal("Expected the built-in check to reject a prompt without H and V stats")
+	}
+
+	if err := m.SetPromptPattern(`^<\d+hp \d+m \d+mv>`); err != nil {
+		t.Fatalf("Failed to set prompt pattern: %v", err)
+	}
+	if !m.IsPrompt(prompt) {
+		t.Error("Expected the custom pattern to match the prompt")
+	}
+	if m.IsPrompt("119H 110V 3674X>") {
+		t.Error("Expected the custom pattern to replace the built-in check")
+	}
+
+	if err := m.SetPromptPattern(`<(\d+hp`); err == nil {
+		t.Error("Expected an invalid pattern to be rejected")
+	}
+	if m.PromptPattern != `^<\d+hp \d+m \d+mv>` {
+		t.Errorf("Expected an invalid pattern to leave the old one, got %q", m.PromptPattern)
+	}
+
+	m.SetPromptPattern("")
+	if m.IsPrompt(prompt) || !m.IsPrompt("119H 110V 3674X>") {
+		t.Error("Expected clearing the pattern to restore the built-in check")
+	}
+}
+
+func TestPromptPatternPersists(t *testing.T) {
+	mapPath := filepath.Join(t.TempDir(), "map.json")
+	m, err := LoadFromPath(mapPath)
+	if err != nil {
+		t.Fatalf("Failed to load map: %v", err)
+	}
+	m.SetPromptPattern(`^\[HP:\d+/\d+\]`)
+	if err := m.Save(); err != nil {
+		t.Fatalf("Failed to save map: %v", err)
+	}
+
+	loaded, err := LoadFromPath(mapPath)
+	if err != nil {
+		t.Fatalf("Failed to reload map: %v", err)
+	}
+	if !loaded.IsPrompt("[HP:50/100] [MV:30/30]") {
+		t.Errorf("Expected the loaded map to use the saved pattern %q", loaded.PromptPattern)
+	}
+}
+
+func TestParseRoomInfoWithCustomPrompt(t *testing.T) {
+	lines := []string{
+		"    The guard tells you the weather is fine.",
+		"<120hp 80m 95mv>",
+		"Market Street",
+		"    Stalls line the street.",
+		"Exits: south",
+	}
+
+	// The built-in check doesn't see the prompt, so the room seems to start
+	// with the guard's indented line and no title is found
+	if info := ParseRoomInfo(lines, false); info.Title != "" {
+		t.Fatalf("Expected the built-in check to miss the room, got %+v", info)
+	}
+
+	m := NewMap()
+	m.SetPromptPattern(`^<\d+hp \d+m \d+mv>`)
+	info := ParseRoomInfoWithPrompt(lines, false, m.IsPrompt)
+	if info.Title != "Market Street" || info.Description != "Stalls line the street." {
+		t.Errorf("Expected Market Street after the custom prompt, got %+v", info)
+	}
+}
@@ -0,0 +1,52 @@
+package mapper
+
+import (
+	"fmt"
+	"regexp"
+	"time"
+)
+
+// SetReconnectOn sets the regular expression that recognizes this MUD's
+// warning that it is about to reboot, and how many seconds to wait after it
+// before reconnecting. An empty pattern turns the reconnect off.
+func (m *Map) SetReconnectOn(pattern string, delaySeconds int) error {
+	if pattern == "" {
+		m.ReconnectOn = ""
+		m.ReconnectDelay = 0
+		m.reconnectRegex = nil
+		return nil
+	}
+
+	if delaySeconds < 0 {
+		return fmt.Errorf("invalid reconnect delay: %d", delaySeconds)
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return fmt.Errorf("invalid reconnect pattern: %w", err)
+	}
+	m.ReconnectOn = pattern
+	m.ReconnectDelay = delaySeconds
+	m.reconnectRegex = re
+	return nil
+}
+
+// ReconnectAfter checks a line (without colors) against the reconnect
+// pattern, returning how long to wait before reconnecting when it matches
+func (m *Map) ReconnectAfter(line string) (time.Duration, bool) {
+	if m.ReconnectOn == "" {
+		return 0, false
+	}
+
+	// A pattern loaded from disk is compiled on first use
+	if m.reconnectRegex == nil || m.reconnectRegex.String() != m.ReconnectOn {
+		re, err := regexp.Compile(m.ReconnectOn)
+		if err != nil {
+			return 0, false
+		}
+		m.reconnectRegex = re
+	}
+	if !m.reconnectRegex.MatchString(line) {
+		return 0, false
+	}
+	return time.Duration(m.ReconnectDelay) * time.Second, true
+}
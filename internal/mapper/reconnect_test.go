@@ -0,0 +1,58 @@
+package mapper
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestReconnectAfter(t *testing.T) {
+	m := NewMap()
+	warning := "The game is rebooting, please reconnect in 60 seconds."
+	if _, ok := m.ReconnectAfter(warning); ok {
+		t.Fatal("Expected no reconnect without a pattern")
+	}
+
+	if err := m.SetReconnectOn(`rebooting, please reconnect`, 60); err != nil {
+		t.Fatalf("Failed to set reconnect pattern: %v", err)
+	}
+	delay, ok := m.ReconnectAfter(warning)
+	if !ok || delay != 60*time.Second {
+		t.Errorf("Expected a reconnect after 60s, got %v, %v", delay, ok)
+	}
+	if _, ok := m.ReconnectAfter("The guard says 'no rebooting here'"); ok {
+		t.Error("Expected other lines not to match")
+	}
+
+	if err := m.SetReconnectOn(`reboot(`, 10); err == nil {
+		t.Error("Expected an invalid pattern to be rejected")
+	}
+	if m.ReconnectDelay != 60 {
+		t.Errorf("Expected an invalid pattern to leave the old setting, got %d", m.ReconnectDelay)
+	}
+
+	m.SetReconnectOn("", 0)
+	if _, ok := m.ReconnectAfter(warning); ok {
+		t.Error("Expected clearing the pattern to turn the reconnect off")
+	}
+}
+
+func TestReconnectOnPersists(t *testing.T) {
+	mapPath := filepath.Join(t.TempDir(), "map.json")
+	m, err := LoadFromPath(mapPath)
+	if err != nil {
+		t.Fatalf("Failed to load map: %v", err)
+	}
+	m.SetReconnectOn(`^Shutting down`, 30)
+	if err := m.Save(); err != nil {
+		t.Fatalf("Failed to save map: %v", err)
+	}
+
+	loaded, err := LoadFromPath(mapPath)
+	if err != nil {
+		t.Fatalf("Failed to reload map: %v", err)
+	}
+	if delay, ok := loaded.ReconnectAfter("Shutting down for a reboot."); !ok || delay != 30*time.Second {
+		t.Errorf("Expected the loaded map to use the saved pattern, got %v, %v", delay, ok)
+	}
+}
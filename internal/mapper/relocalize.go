@@ -0,0 +1,60 @@
+package mapper
+
+import (
+	"sort"
+	"strings"
+)
+
+// Relocalize moves the current position to the known room with the given
+// title and exits, for when we log back in somewhere other than the saved
+// CurrentRoomID. No room or link is created. When several rooms share the
+// title and exits, one whose first sentence matches the description wins,
+// then the saved current room, then the lowest room number. Returns the room
+// or nil when no known room matches, leaving the position unchanged.
+func (m *Map) Relocalize(title, description string, exits []string) *Room {
+	title = strings.ToLower(strings.TrimSpace(title))
+	firstSentence := strings.ToLower(extractFirstSentence(description))
+	wantExits := sortedExitList(exits)
+
+	var best *Room
+	bestScore := -1
+	for _, room := range m.Rooms {
+		if strings.ToLower(strings.TrimSpace(room.Title)) != title {
+			continue
+		}
+		roomExits := make([]string, 0, len(room.Exits))
+		for direction := range room.Exits {
+			roomExits = append(roomExits, direction)
+		}
+		if strings.Join(sortedExitList(roomExits), ",") != strings.Join(wantExits, ",") {
+			continue
+		}
+
+		score := 0
+		if firstSentence != "" && strings.ToLower(room.FirstSentence) == firstSentence {
+			score += 2
+		}
+		if room.ID == m.CurrentRoomID {
+			score++
+		}
+		if score > bestScore || score == bestScore && m.roomOrder(room.ID, best.ID) {
+			best, bestScore = room, score
+		}
+	}
+	if best == nil {
+		return nil
+	}
+
+	m.PreviousRoomID = ""
+	m.CurrentRoomID = best.ID
+	m.LastDirection = ""
+	return best
+}
+
+// sortedExitList returns the exits in sorted order
+func sortedExitList(exits []string) []string {
+	sorted := make([]string, len(exits))
+	copy(sorted, exits)
+	sort.Strings(sorted)
+	return sorted
+}
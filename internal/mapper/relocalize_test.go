@@ -0,0 +1,56 @@
+package mapper
+
+import "testing"
+
+func TestRelocalizeMatchesTitleAndExits(t *testing.T) {
+	m := NewMap()
+	temple := NewRoom("Temple Square", "A large temple square. Pigeons everywhere.", []string{"north", "south"})
+	m.AddOrUpdateRoom(temple)
+	m.SetLastDirection("north")
+	alley := NewRoom("Dark Alley", "A narrow alley.", []string{"south"})
+	m.AddOrUpdateRoom(alley)
+	rooms := len(m.Rooms)
+
+	// Logging in at the temple while the map still says the alley
+	room := m.Relocalize("temple square", "A large temple square. The pigeons have gone.", []string{"south", "north"})
+	if room != temple || m.CurrentRoomID != temple.ID {
+		t.Fatalf("Expected to relocalize to the temple, got %v", room)
+	}
+	if m.LastDirection != "" || m.PreviousRoomID != "" {
+		t.Errorf("Expected no movement to link from, got direction %q previous %q", m.LastDirection, m.PreviousRoomID)
+	}
+	if len(m.Rooms) != rooms || temple.VisitCount != 1 {
+		t.Errorf("Expected no room added or visited, got %d rooms, %d visits", len(m.Rooms), temple.VisitCount)
+	}
+
+	// Same title with different exits is another room
+	m.CurrentRoomID = alley.ID
+	if room := m.Relocalize("Temple Square", "", []string{"north"}); room != nil {
+		t.Errorf("Expected no match with different exits, got %v", room.ID)
+	}
+	if m.CurrentRoomID != alley.ID {
+		t.Errorf("Expected the position unchanged without a match, got %q", m.CurrentRoomID)
+	}
+}
+
+func TestRelocalizePrefersMatchingDescription(t *testing.T) {
+	m := NewMap()
+	first := NewRoom("Forest Path", "Tall oaks line the path.", []string{"east", "west"})
+	second := NewRoom("Forest Path", "Birches crowd the path.", []string{"east", "west"})
+	m.AddOrUpdateRoom(first)
+	m.AddOrUpdateRoom(second)
+	m.CurrentRoomID = ""
+
+	if room := m.Relocalize("Forest Path", "Birches crowd the path.", []string{"east", "west"}); room != second {
+		t.Errorf("Expected the room with the matching description, got %v", room)
+	}
+
+	// Without a description match the saved position wins, then room number
+	if room := m.Relocalize("Forest Path", "Something else.", []string{"east", "west"}); room != second {
+		t.Errorf("Expected the saved current room, got %v", room)
+	}
+	m.CurrentRoomID = ""
+	if room := m.Relocalize("Forest Path", "Something else.", []string{"east", "west"}); room != first {
+		t.Errorf("Expected the lowest room number, got %v", room)
+	}
+}
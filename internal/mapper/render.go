@@ -54,10 +54,10 @@ func (m *Map) RenderMap(width, height int) (string, string) {
 	}
 
 	// Build the room grid centered on current room
-	roomGrid := m.buildRoomGrid(currentRoom, width, height)
+	roomGrid := m.roomGrid(currentRoom, width, height)
 
 	// Render the grid to string
-	rendered := renderGrid(roomGrid, width, height, nil)
+	rendered := renderGrid(roomGrid, width, height, nil, m.orientation())
 
 	return rendered, currentRoom.Title
 }
@@ -71,10 +71,10 @@ func (m *Map) RenderMapWithLegend(width, height int, legend map[string]int) (str
 	}
 
 	// Build the room grid centered on current room
-	roomGrid := m.buildRoomGrid(currentRoom, width, height)
+	roomGrid := m.roomGrid(currentRoom, width, height)
 
 	// Render the grid to string with legend
-	rendered := renderGrid(roomGrid, width, height, legend)
+	rendered := renderGrid(roomGrid, width, height, legend, m.orientation())
 
 	return rendered, currentRoom.Title
 }
@@ -83,6 +83,17 @@ func (m *Map) RenderMapWithLegend(width, height int, legend map[string]int) (str
 type RoomMarker struct {
 	Room       *Room
 	IsUnknown  bool // True if this is an unexplored exit
+	Conflict   bool // True if another room was assigned the same position
+}
+
+// roomGrid lays out the rooms around the current room, by their assigned
+// positions when coordinate rendering is on and the current room is placed,
+// turned so the map's orientation is up
+func (m *Map) roomGrid(currentRoom *Room, width, height int) map[Coordinate]*RoomMarker {
+	if m.UseCoordinates && currentRoom.Position != nil {
+		return m.orientation().rotateGrid(m.buildCoordinateGrid(currentRoom))
+	}
+	return m.orientation().rotateGrid(m.buildRoomGrid(currentRoom, width, height))
 }
 
 // buildRoomGrid creates a 2D grid of rooms centered on the current room
@@ -184,7 +195,7 @@ func (m *Map) GetVisibleRoomIDs(width, height int) []string {
 	}
 
 	// Build the room grid to see what's visible
-	grid := m.buildRoomGrid(currentRoom, width, height)
+	grid := m.roomGrid(currentRoom, width, height)
 
 	// Extract room IDs from the grid
 	roomIDs := make([]string, 0)
@@ -204,35 +215,32 @@ func (m *Map) GetVisibleRoomIDs(width, height int) []string {
 
 // renderGrid converts the room grid to a visual string representation
 // If legend is provided, rooms in the legend will be shown with their number instead of symbol
-func renderGrid(grid map[Coordinate]*RoomMarker, width, height int, legend map[string]int) string {
+// The orientation says which exits connect rooms drawn side by side
+func renderGrid(grid map[Coordinate]*RoomMarker, width, height int, legend map[string]int, orientation Orientation) string {
+	// The exits drawn towards each side of the screen
+	eastExit, westExit := orientation.compassFor("east"), orientation.compassFor("west")
+	southExit, northExit := orientation.compassFor("south"), orientation.compassFor("north")
+
 	// Define styles for different room types
 	currentRoomStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("226")) // Yellow/gold
 	visitedRoomStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("255")) // White
 	unexploredRoomStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("240")) // Dark gray
+	conflictRoomStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("196")) // Red
 	connectionStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("240")) // Dark gray for connections
 
-	// Calculate how many characters we can fit
-	// When using legend, we need more space per room for numbers
-	charsPerRoom := 3 // room + double connector space
-	linesPerRoom := 2 // room line + connector line
-	
-	// If legend is active, adjust spacing for multi-digit numbers
-	if legend != nil && len(legend) > 0 {
-		// Find max number to determine spacing
-		maxNum := 0
-		for _, num := range legend {
-			if num > maxNum {
-				maxNum = num
-			}
-		}
-		// Adjust chars per room based on max number width
-		if maxNum >= 100 {
-			charsPerRoom = 5 // 3 digits + 2 connector
-		} else if maxNum >= 10 {
-			charsPerRoom = 4 // 2 digits + 2 connector
+	// With a legend, each room's number is drawn right after its symbol, in
+	// a space as wide as the longest number so the columns stay aligned
+	labelWidth := 0
+	for _, num := range legend {
+		if digits := len(fmt.Sprintf("%d", num)); digits > labelWidth {
+			labelWidth = digits
 		}
 	}
 
+	// Calculate how many characters we can fit
+	charsPerRoom := 3 + labelWidth // room + legend number + double connector space
+	linesPerRoom := 2              // room line + connector line
+
 	maxRoomsPerLine := width / charsPerRoom
 	maxRoomsPerHeight := height / linesPerRoom
 
@@ -257,7 +265,9 @@ func renderGrid(grid map[Coordinate]*RoomMarker, width, height int, legend map[s
 			coord := Coordinate{X: x, Y: y}
 			marker := grid[coord]
 
-			// Render the room symbol
+			// Render the room symbol, followed by its legend number
+			label := ""
+			labelStyle := visitedRoomStyle
 			if marker != nil {
 				if marker.IsUnknown {
 					// Unexplored room - always show as gray ▦
@@ -265,63 +275,51 @@ func renderGrid(grid map[Coordinate]*RoomMarker, width, height int, legend map[s
 				} else {
 					room := marker.Room
 					isCurrentRoom := (x == 0 && y == 0)
-					
-					// Check if this room is in the legend
-					if legend != nil {
-						if roomNum, inLegend := legend[room.ID]; inLegend {
-							// Show room number from legend
-							symbol := fmt.Sprintf("%d", roomNum)
-							if isCurrentRoom {
-								roomLine.WriteString(currentRoomStyle.Render(symbol))
-							} else {
-								roomLine.WriteString(visitedRoomStyle.Render(symbol))
-							}
-						} else {
-							// Not in legend, use regular symbol
-							if isCurrentRoom {
-								roomLine.WriteString(currentRoomStyle.Render("▣"))
-							} else {
-								roomLine.WriteString(visitedRoomStyle.Render("▢"))
-							}
-						}
-					} else {
-						// No legend, use symbols based on vertical exits
-						hasUp := false
-						hasDown := false
-						for direction := range room.Exits {
-							switch direction {
-							case "up", "u":
-								hasUp = true
-							case "down", "d":
-								hasDown = true
-							}
-						}
+					roomStyle := visitedRoomStyle
+					if marker.Conflict {
+						roomStyle = conflictRoomStyle
+					}
 
-						// Determine the symbol based on vertical exits
-						var symbol string
-						
-						if hasUp && hasDown {
-							symbol = "⇅" // Both up and down
-						} else if hasUp {
-							symbol = "⇱" // Up only
-						} else if hasDown {
-							symbol = "⇲" // Down only
-						} else {
-							// No vertical exits - use regular room symbols
-							if isCurrentRoom {
-								symbol = "▣" // Current room - filled square
-							} else {
-								symbol = "▢" // Visited room - hollow square
-							}
+					// Use symbols based on vertical exits
+					hasUp := false
+					hasDown := false
+					for direction := range room.Exits {
+						switch direction {
+						case "up", "u":
+							hasUp = true
+						case "down", "d":
+							hasDown = true
 						}
-						
-						// Apply color - current room is always yellow, others are white
+					}
+
+					// Determine the symbol based on vertical exits
+					var symbol string
+
+					if hasUp && hasDown {
+						symbol = "⇅" // Both up and down
+					} else if hasUp {
+						symbol = "⇱" // Up only
+					} else if hasDown {
+						symbol = "⇲" // Down only
+					} else {
+						// No vertical exits - use regular room symbols
 						if isCurrentRoom {
-							roomLine.WriteString(currentRoomStyle.Render(symbol))
+							symbol = "▣" // Current room - filled square
 						} else {
-							roomLine.WriteString(visitedRoomStyle.Render(symbol))
+							symbol = "▢" // Visited room - hollow square
 						}
 					}
+
+					// Apply color - current room is always yellow, others are white (red if conflicting)
+					if isCurrentRoom {
+						roomStyle = currentRoomStyle
+					}
+					roomLine.WriteString(roomStyle.Render(symbol))
+
+					if roomNum, inLegend := legend[room.ID]; inLegend {
+						label = fmt.Sprintf("%d", roomNum)
+						labelStyle = roomStyle
+					}
 				}
 			} else {
 				roomLine.WriteString(" ") // Empty space
@@ -339,7 +337,7 @@ func renderGrid(grid map[Coordinate]*RoomMarker, width, height int, legend map[s
 					if !marker.IsUnknown && marker.Room != nil {
 						// Check if current room has east exit
 						for dir, destID := range marker.Room.Exits {
-							if (dir == "east" || dir == "e") {
+							if isDirection(dir, eastExit) {
 								// Connection exists if:
 								// 1. East room is unexplored (destID is empty or room doesn't exist)
 								// 2. East room is known and IDs match
@@ -355,7 +353,7 @@ func renderGrid(grid map[Coordinate]*RoomMarker, width, height int, legend map[s
 					if !hasEastConnection && !eastMarker.IsUnknown && eastMarker.Room != nil {
 						// Check if east room has west exit pointing to current
 						for dir, destID := range eastMarker.Room.Exits {
-							if (dir == "west" || dir == "w") {
+							if isDirection(dir, westExit) {
 								if marker.IsUnknown ||
 								   (marker.Room != nil && destID == marker.Room.ID) {
 									hasEastConnection = true
@@ -366,11 +364,17 @@ func renderGrid(grid map[Coordinate]*RoomMarker, width, height int, legend map[s
 					}
 				}
 				
+				// A connection continues through the space of a missing number
+				if label != "" {
+					roomLine.WriteString(labelStyle.Render(label))
+				}
 				if hasEastConnection {
-					roomLine.WriteString(connectionStyle.Render("──"))
+					roomLine.WriteString(connectionStyle.Render(strings.Repeat("─", labelWidth-len(label)+2)))
 				} else {
-					roomLine.WriteString("  ")
+					roomLine.WriteString(strings.Repeat(" ", labelWidth-len(label)+2))
 				}
+			} else if label != "" {
+				roomLine.WriteString(labelStyle.Render(label))
 			}
 
 			// Render vertical connector (below this room)
@@ -385,7 +389,7 @@ func renderGrid(grid map[Coordinate]*RoomMarker, width, height int, legend map[s
 					if !marker.IsUnknown && marker.Room != nil {
 						// Check if current room has south exit
 						for dir, destID := range marker.Room.Exits {
-							if (dir == "south" || dir == "s") {
+							if isDirection(dir, southExit) {
 								// Connection exists if:
 								// 1. South room is unexplored (destID is empty or room doesn't exist)
 								// 2. South room is known and IDs match
@@ -401,7 +405,7 @@ func renderGrid(grid map[Coordinate]*RoomMarker, width, height int, legend map[s
 					if !hasSouthConnection && !southMarker.IsUnknown && southMarker.Room != nil {
 						// Check if south room has north exit pointing to current
 						for dir, destID := range southMarker.Room.Exits {
-							if (dir == "north" || dir == "n") {
+							if isDirection(dir, northExit) {
 								if marker.IsUnknown ||
 								   (marker.Room != nil && destID == marker.Room.ID) {
 									hasSouthConnection = true
@@ -419,9 +423,10 @@ func renderGrid(grid map[Coordinate]*RoomMarker, width, height int, legend map[s
 				}
 			}
 
-			// Add spacing for connector column in connector line (2 spaces for double connector)
+			// Add spacing for the legend number and connector column in
+			// connector line (2 spaces for double connector)
 			if x < viewMaxX && y < viewMaxY {
-				connLine.WriteString("  ")
+				connLine.WriteString(strings.Repeat(" ", labelWidth+2))
 			}
 		}
 
@@ -478,6 +483,14 @@ func (m *Map) FormatMapPanel(width, height int) string {
 
 // FormatMapPanelWithLegend formats the complete map panel with optional room number legend
 func (m *Map) FormatMapPanelWithLegend(width, height int, legend map[string]int) string {
+	// The compass of the current room's exits goes below the map when
+	// there is room for both
+	currentRoom := m.GetCurrentRoom()
+	if m.ShowCompass && currentRoom != nil && height > 2*compassHeight {
+		mapContent, _ := m.RenderMapWithLegend(width, height-compassHeight, legend)
+		return mapContent + "\n" + RenderCompass(CompassExits(currentRoom), m.orientation())
+	}
+
 	// Render the map with legend using the full available height
 	mapContent, _ := m.RenderMapWithLegend(width, height, legend)
 	return mapContent
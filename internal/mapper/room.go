@@ -7,12 +7,15 @@ import (
 
 // Room represents a single room in the MUD world
 type Room struct {
-	ID            string            `json:"id"`             // Unique identifier based on content
-	Title         string            `json:"title"`          // Room title
-	Description   string            `json:"description"`    // Full description
-	FirstSentence string            `json:"first_sentence"` // First sentence of description
-	Exits         map[string]string `json:"exits"`          // direction -> destination room ID
-	VisitCount    int               `json:"visit_count"`    // Number of times visited
+	ID            string            `json:"id"`                 // Unique identifier based on content
+	Title         string            `json:"title"`              // Room title
+	Description   string            `json:"description"`        // Full description
+	FirstSentence string            `json:"first_sentence"`     // First sentence of description
+	Exits         map[string]string `json:"exits"`              // direction -> destination room ID
+	VisitCount    int               `json:"visit_count"`        // Number of times visited
+	Avoid         bool              `json:"avoid,omitempty"`    // Pathfinding avoids this room when possible
+	Position      *Position         `json:"position,omitempty"` // Grid coordinates (nil until assigned)
+	Conflict      bool              `json:"conflict,omitempty"` // Another room was assigned the same position
 }
 
 // GenerateRoomID creates a unique ID from title, first sentence, and exits
@@ -155,3 +158,28 @@ func (r *Room) UpdateExit(direction, destinationID string) {
 func (r *Room) RemoveExit(direction string) {
 	delete(r.Exits, direction)
 }
+
+// SpecialExits returns the exits taken with a command such as "enter portal"
+// instead of a direction, sorted
+func (r *Room) SpecialExits() []string {
+	var commands []string
+	for direction := range r.Exits {
+		if !isValidDirection(direction) {
+			commands = append(commands, direction)
+		}
+	}
+	sort.Strings(commands)
+	return commands
+}
+
+// SpecialExit returns the special exit of the room taken with the given
+// command, ignoring case and extra spaces, or "" if there is none
+func (r *Room) SpecialExit(command string) string {
+	command = strings.Join(strings.Fields(command), " ")
+	for _, exit := range r.SpecialExits() {
+		if strings.EqualFold(exit, command) {
+			return exit
+		}
+	}
+	return ""
+}
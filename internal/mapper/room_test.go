@@ -128,3 +128,33 @@ func TestNewBarsoomRoom(t *testing.T) {
 		t.Errorf("FirstSentence = %q, want %q", room.FirstSentence, expectedFirstSentence)
 	}
 }
+
+func TestSpecialExits(t *testing.T) {
+	m := NewMap()
+	m.AddOrUpdateRoom(NewRoom("Clearing", "A portal shimmers here.", []string{"north"}))
+	m.SetLastDirection("enter portal")
+	m.AddOrUpdateRoom(NewRoom("Astral Plane", "Stars all around.", []string{"south"}))
+	astral := m.CurrentRoomID
+	clearing := m.PreviousRoomID
+
+	room := m.Rooms[clearing]
+	if got := room.SpecialExits(); len(got) != 1 || got[0] != "enter portal" {
+		t.Fatalf("SpecialExits() = %v, want [enter portal]", got)
+	}
+	if room.Exits["enter portal"] != astral {
+		t.Errorf("Expected the special exit to lead to the new room, got %q", room.Exits["enter portal"])
+	}
+	if got := room.SpecialExit("Enter  Portal"); got != "enter portal" {
+		t.Errorf("SpecialExit(\"Enter  Portal\") = %q, want \"enter portal\"", got)
+	}
+	if got := room.SpecialExit("north"); got != "" {
+		t.Errorf("SpecialExit(\"north\") = %q, want \"\"", got)
+	}
+	if len(m.Rooms[astral].SpecialExits()) != 0 || m.Rooms[astral].Exits["south"] != "" {
+		t.Errorf("Expected no way back to be assumed, got %v", m.Rooms[astral].Exits)
+	}
+
+	if path := m.FindPathFrom(clearing, astral); len(path) != 1 || path[0] != "enter portal" {
+		t.Errorf("FindPathFrom() = %v, want [enter portal]", path)
+	}
+}
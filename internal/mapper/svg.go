@@ -0,0 +1,158 @@
+package mapper
+
+import (
+	"fmt"
+	"html"
+	"sort"
+	"strings"
+)
+
+// SVG layout, in pixels
+const (
+	svgCell     = 60 // Distance between neighbouring rooms
+	svgRoomSize = 36 // Width and height of a room box
+	svgMargin   = 30 // Space around each level
+	svgLabel    = 24 // Height of a level's label
+)
+
+// svgStyle colours the map like the web client's terminal
+const svgStyle = `<style>
+svg { background: #1e1e1e; font-family: monospace; }
+.level-label { fill: #d4d4d4; font-size: 14px; }
+.exit { stroke: #808080; stroke-width: 2; }
+.room rect { fill: #2d2d2d; stroke: #4ec9b0; stroke-width: 2; }
+.room text { fill: #d4d4d4; font-size: 12px; text-anchor: middle; dominant-baseline: central; pointer-events: none; }
+.room.current rect { fill: #4ec9b0; }
+.room.current text { fill: #1e1e1e; }
+.room.avoid rect { stroke: #808080; stroke-dasharray: 4 2; }
+.room.conflict rect { stroke: #f14c4c; }
+.room { cursor: pointer; }
+.room:hover rect { stroke: #f5f543; }
+</style>`
+
+// RenderSVG draws every room with a position as an SVG graph, one level
+// after another from the top floor down. Each room is a group with class
+// "room" and a data-room attribute holding its durable room number, so a
+// page showing the map can act on clicks. Rooms without a position are left
+// out.
+func (m *Map) RenderSVG() string {
+	levels := make(map[int][]*Room)
+	for _, id := range m.sortedRoomIDs() {
+		room := m.Rooms[id]
+		if room.Position != nil {
+			levels[room.Position.Z] = append(levels[room.Position.Z], room)
+		}
+	}
+
+	if len(levels) == 0 {
+		return fmt.Sprintf(`<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d">`, 240, 2*svgMargin) + "\n" +
+			svgStyle + "\n" +
+			fmt.Sprintf(`<text class="level-label" x="%d" y="%d">No rooms mapped yet</text>`, svgMargin, svgMargin) + "\n" +
+			"</svg>\n"
+	}
+
+	zs := make([]int, 0, len(levels))
+	for z := range levels {
+		zs = append(zs, z)
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(zs)))
+
+	var body strings.Builder
+	width, height := 0, 0
+	for _, z := range zs {
+		levelWidth, levelHeight := m.renderSVGLevel(&body, z, levels[z], height)
+		if levelWidth > width {
+			width = levelWidth
+		}
+		height += levelHeight
+	}
+
+	var svg strings.Builder
+	fmt.Fprintf(&svg, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d">`, width, height, width, height)
+	svg.WriteString("\n" + svgStyle + "\n")
+	svg.WriteString(body.String())
+	svg.WriteString("</svg>\n")
+	return svg.String()
+}
+
+// renderSVGLevel draws the rooms of one level starting top pixels down and
+// returns the width and height it used
+func (m *Map) renderSVGLevel(b *strings.Builder, z int, rooms []*Room, top int) (int, int) {
+	minX, maxX := rooms[0].Position.X, rooms[0].Position.X
+	minY, maxY := rooms[0].Position.Y, rooms[0].Position.Y
+	for _, room := range rooms {
+		minX, maxX = min(minX, room.Position.X), max(maxX, room.Position.X)
+		minY, maxY = min(minY, room.Position.Y), max(maxY, room.Position.Y)
+	}
+
+	// North is up, so rows count down from the northernmost room
+	center := func(pos *Position) (int, int) {
+		x := svgMargin + (pos.X-minX)*svgCell + svgRoomSize/2
+		y := top + svgLabel + svgMargin + (maxY-pos.Y)*svgCell + svgRoomSize/2
+		return x, y
+	}
+
+	fmt.Fprintf(b, `<text class="level-label" x="%d" y="%d">Level %d</text>`+"\n", svgMargin, top+svgLabel, z)
+
+	// Exits between rooms on this level, each pair once
+	drawn := make(map[[2]string]bool)
+	for _, room := range rooms {
+		directions := make([]string, 0, len(room.Exits))
+		for direction := range room.Exits {
+			directions = append(directions, direction)
+		}
+		sort.Strings(directions)
+
+		for _, direction := range directions {
+			dest, ok := m.Rooms[room.Exits[direction]]
+			if !ok || dest == room || dest.Position == nil || dest.Position.Z != z {
+				continue
+			}
+			pair := [2]string{room.ID, dest.ID}
+			if dest.ID < room.ID {
+				pair = [2]string{dest.ID, room.ID}
+			}
+			if drawn[pair] {
+				continue
+			}
+			drawn[pair] = true
+
+			x1, y1 := center(room.Position)
+			x2, y2 := center(dest.Position)
+			fmt.Fprintf(b, `<line class="exit" x1="%d" y1="%d" x2="%d" y2="%d"/>`+"\n", x1, y1, x2, y2)
+		}
+	}
+
+	for _, room := range rooms {
+		classes := "room"
+		if room.ID == m.CurrentRoomID {
+			classes += " current"
+		}
+		if room.Avoid {
+			classes += " avoid"
+		}
+		if room.Conflict {
+			classes += " conflict"
+		}
+
+		number := m.GetRoomNumber(room.ID)
+		x, y := center(room.Position)
+		label := fmt.Sprint(number)
+		if _, up := room.Exits["up"]; up {
+			label += "▲"
+		}
+		if _, down := room.Exits["down"]; down {
+			label += "▼"
+		}
+
+		fmt.Fprintf(b, `<g class="%s" data-room="%d">`, classes, number)
+		fmt.Fprintf(b, `<title>%d. %s</title>`, number, html.EscapeString(room.Title))
+		fmt.Fprintf(b, `<rect x="%d" y="%d" width="%d" height="%d" rx="4"/>`, x-svgRoomSize/2, y-svgRoomSize/2, svgRoomSize, svgRoomSize)
+		fmt.Fprintf(b, `<text x="%d" y="%d">%s</text>`, x, y, label)
+		b.WriteString("</g>\n")
+	}
+
+	width := 2*svgMargin + (maxX-minX)*svgCell + svgRoomSize
+	height := svgLabel + 2*svgMargin + (maxY-minY)*svgCell + svgRoomSize
+	return width, height
+}
@@ -0,0 +1,72 @@
+package mapper
+
+import (
+	"strings"
+	"testing"
+)
+
+// newSVGTestMap builds a square and a room to its north, with a cellar below
+// the square
+func newSVGTestMap() *Map {
+	m := NewMap()
+	m.AddOrUpdateRoom(NewRoom("Town Square", "The centre of town.", []string{"north", "down"}))
+	m.SetLastDirection("north")
+	m.AddOrUpdateRoom(NewRoom("Bakery <Open>", "Smells of bread.", []string{"south"}))
+	m.SetLastDirection("south")
+	m.AddOrUpdateRoom(NewRoom("Town Square", "The centre of town.", []string{"north", "down"}))
+	m.SetLastDirection("down")
+	m.AddOrUpdateRoom(NewRoom("Cellar", "Dark and damp.", []string{"up"}))
+	return m
+}
+
+func TestRenderSVGRooms(t *testing.T) {
+	m := newSVGTestMap()
+	svg := m.RenderSVG()
+
+	if !strings.HasPrefix(svg, `<svg xmlns="http://www.w3.org/2000/svg"`) || !strings.HasSuffix(svg, "</svg>\n") {
+		t.Fatalf("Expected a complete SVG document, got %q", svg)
+	}
+	for _, want := range []string{
+		`<g class="room" data-room="1"><title>1. Town Square</title>`,
+		`<g class="room" data-room="2"><title>2. Bakery &lt;Open&gt;</title>`,
+		`<g class="room current" data-room="3"><title>3. Cellar</title>`,
+		">1▼</text>", // The square has a way down
+		">3▲</text>",
+	} {
+		if !strings.Contains(svg, want) {
+			t.Errorf("Expected %q in the SVG", want)
+		}
+	}
+
+	// Upper levels come first
+	if level0, level1 := strings.Index(svg, "Level 0"), strings.Index(svg, "Level -1"); level0 < 0 || level1 < level0 {
+		t.Errorf("Expected level 0 before level -1, got %q", svg)
+	}
+}
+
+func TestRenderSVGExitLines(t *testing.T) {
+	m := newSVGTestMap()
+	svg := m.RenderSVG()
+
+	// The square and bakery are linked both ways but drawn once; up and down
+	// are marked on the rooms instead
+	if got := strings.Count(svg, `<line class="exit"`); got != 1 {
+		t.Fatalf("Expected 1 exit line, got %d in %q", got, svg)
+	}
+
+	// North is up: the bakery is drawn one cell above the square, in the
+	// same column
+	if !strings.Contains(svg, `<title>1. Town Square</title><rect x="30" y="114"`) || !strings.Contains(svg, `<title>2. Bakery &lt;Open&gt;</title><rect x="30" y="54"`) {
+		t.Errorf("Expected the bakery above the square, got %q", svg)
+	}
+	if !strings.Contains(svg, `<line class="exit" x1="48" y1="132" x2="48" y2="72"/>`) {
+		t.Errorf("Expected a vertical line from the square to the bakery, got %q", svg)
+	}
+}
+
+func TestRenderSVGEmptyMap(t *testing.T) {
+	svg := NewMap().RenderSVG()
+	if !strings.Contains(svg, "No rooms mapped yet") || strings.Contains(svg, `class="room`) {
+		t.Errorf("Expected an empty map message, got %q", svg)
+	}
+}
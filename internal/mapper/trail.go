@@ -0,0 +1,101 @@
+package mapper
+
+import (
+	"fmt"
+	"time"
+)
+
+// DefaultTrailLength is how many rooms a trail keeps by default
+const DefaultTrailLength = 20
+
+// TrailStep is one room on a trail and how it was reached
+type TrailStep struct {
+	RoomID    string    // Room entered
+	Direction string    // Direction taken to enter it ("" = unknown, e.g. the first room or after a teleport)
+	Time      time.Time // When the room was entered
+}
+
+// Trail is the rooms visited most recently, oldest first, for retracing
+// steps and spotting bad links
+type Trail struct {
+	Steps []TrailStep
+	Max   int // Most steps kept; older ones are dropped
+}
+
+// NewTrail creates a trail that keeps the last max rooms
+func NewTrail(max int) *Trail {
+	return &Trail{Max: max}
+}
+
+// Record adds the room just entered. Entering the room the trail already
+// ends in (e.g. looking again) doesn't add a step.
+func (t *Trail) Record(roomID, direction string, now time.Time) {
+	if roomID == "" {
+		return
+	}
+	if n := len(t.Steps); n > 0 && t.Steps[n-1].RoomID == roomID {
+		return
+	}
+	t.Steps = append(t.Steps, TrailStep{RoomID: roomID, Direction: direction, Time: now})
+	if t.Max > 0 && len(t.Steps) > t.Max {
+		t.Steps = t.Steps[len(t.Steps)-t.Max:]
+	}
+}
+
+// Back returns the directions that walk the last n steps of the trail in
+// reverse, and the room they lead to. Each step back uses the map's exit
+// from the room to the one before it, so one-way or bent exits are
+// followed correctly; without one, it stops with an error.
+func (t *Trail) Back(m *Map, n int) ([]string, *Room, error) {
+	if len(t.Steps) < 2 {
+		return nil, nil, fmt.Errorf("the trail has no rooms to go back to")
+	}
+	if n <= 0 || n > len(t.Steps)-1 {
+		n = len(t.Steps) - 1
+	}
+
+	path := make([]string, 0, n)
+	for i := len(t.Steps) - 1; i >= len(t.Steps)-n; i-- {
+		from, to := t.Steps[i], t.Steps[i-1]
+		room := m.Rooms[from.RoomID]
+		if room == nil {
+			return nil, nil, fmt.Errorf("room %s on the trail is no longer on the map", from.RoomID)
+		}
+		direction := exitTo(room, to.RoomID, getReverseDirection(from.Direction))
+		if direction == "" {
+			title := to.RoomID
+			if target := m.Rooms[to.RoomID]; target != nil {
+				title = target.Title
+			}
+			return nil, nil, fmt.Errorf("no known exit from '%s' back to '%s'", room.Title, title)
+		}
+		path = append(path, direction)
+	}
+
+	return path, m.Rooms[t.Steps[len(t.Steps)-1-n].RoomID], nil
+}
+
+// exitTo returns the room's exit leading to the target room, trying the
+// preferred direction first. It returns "" when no exit leads there.
+func exitTo(room *Room, targetID, preferred string) string {
+	if preferred != "" && room.Exits[preferred] == targetID {
+		return preferred
+	}
+	directions := make([]string, 0, len(room.Exits))
+	for direction, destID := range room.Exits {
+		if destID == targetID {
+			directions = append(directions, direction)
+		}
+	}
+	if len(directions) == 0 {
+		return ""
+	}
+	// Map order is random, so pick the same exit every time
+	best := directions[0]
+	for _, direction := range directions[1:] {
+		if direction < best {
+			best = direction
+		}
+	}
+	return best
+}
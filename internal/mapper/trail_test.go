@@ -0,0 +1,92 @@
+package mapper
+
+import (
+	"testing"
+	"time"
+)
+
+// walkTrail moves through the map in a direction and records the step on the trail
+func walkTrail(m *Map, trail *Trail, direction string, room *Room) {
+	m.SetLastDirection(direction)
+	m.AddOrUpdateRoom(room)
+	trail.Record(m.CurrentRoomID, direction, time.Now())
+}
+
+func TestTrailRecordsTransitions(t *testing.T) {
+	trail := NewTrail(3)
+	now := time.Now()
+
+	trail.Record("hall", "", now)
+	trail.Record("corridor", "north", now)
+	trail.Record("corridor", "", now)
+	if len(trail.Steps) != 2 {
+		t.Fatalf("Expected entering the same room again not to add a step, got %+v", trail.Steps)
+	}
+	if trail.Steps[1].Direction != "north" {
+		t.Errorf("Expected the direction into the corridor, got %q", trail.Steps[1].Direction)
+	}
+
+	trail.Record("kitchen", "east", now)
+	trail.Record("pantry", "south", now)
+	if len(trail.Steps) != 3 || trail.Steps[0].RoomID != "corridor" || trail.Steps[2].RoomID != "pantry" {
+		t.Errorf("Expected the oldest step to be dropped, got %+v", trail.Steps)
+	}
+}
+
+func TestTrailBackReversesPath(t *testing.T) {
+	m := NewMap()
+	trail := NewTrail(DefaultTrailLength)
+
+	hall := NewRoom("Hall", "A hall.", []string{"north"})
+	m.AddOrUpdateRoom(hall)
+	trail.Record(m.CurrentRoomID, "", time.Now())
+	corridor := NewRoom("Corridor", "A corridor.", []string{"south", "east"})
+	walkTrail(m, trail, "north", corridor)
+	kitchen := NewRoom("Kitchen", "A kitchen.", []string{"west", "up"})
+	walkTrail(m, trail, "east", kitchen)
+
+	// The stairs bend: up from the kitchen, but west to come back down
+	attic := NewRoom("Attic", "An attic.", []string{"west"})
+	walkTrail(m, trail, "up", attic)
+	delete(attic.Exits, "down")
+	attic.Exits["west"] = kitchen.ID
+
+	path, to, err := trail.Back(m, 2)
+	if err != nil {
+		t.Fatalf("Failed to go back: %v", err)
+	}
+	if len(path) != 2 || path[0] != "west" || path[1] != "west" || to != corridor {
+		t.Errorf("Expected west, west to the corridor, got %v to %v", path, to)
+	}
+
+	// Without a count the whole trail is walked back
+	path, to, err = trail.Back(m, 0)
+	if err != nil {
+		t.Fatalf("Failed to go back: %v", err)
+	}
+	if len(path) != 3 || path[2] != "south" || to != hall {
+		t.Errorf("Expected the path back to the hall, got %v to %v", path, to)
+	}
+}
+
+func TestTrailBackErrors(t *testing.T) {
+	m := NewMap()
+	trail := NewTrail(DefaultTrailLength)
+	if _, _, err := trail.Back(m, 1); err == nil {
+		t.Error("Expected an error for an empty trail")
+	}
+
+	hall := NewRoom("Hall", "A hall.", []string{"north"})
+	m.AddOrUpdateRoom(hall)
+	trail.Record(m.CurrentRoomID, "", time.Now())
+
+	// A teleport leaves no exit back
+	m.SetLastDirection("")
+	temple := NewRoom("Temple", "A temple.", []string{"south"})
+	m.AddOrUpdateRoom(temple)
+	trail.Record(m.CurrentRoomID, "", time.Now())
+
+	if _, _, err := trail.Back(m, 1); err == nil {
+		t.Error("Expected an error without an exit back")
+	}
+}
@@ -0,0 +1,155 @@
+// Package mutes keeps the list of players whose tells, says and channel
+// messages are hidden, saved separately for each account.
+package mutes
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// List is the persistent list of muted players for one account
+type List struct {
+	Players  []string `json:"players"`
+	filePath string   // Path to the mutes file (not serialized)
+}
+
+// senderPatterns match the lines a player speaks in, with the speaker as
+// the first group
+var senderPatterns = []*regexp.Regexp{
+	// Bob tells you 'hi'
+	regexp.MustCompile(`^(\S+) tells you '`),
+	// Bob says 'hi', Bob gossips 'hi', Bob shouts, 'hi'
+	regexp.MustCompile(`^(\S+) (?:says|asks|exclaims|gossips|chats|shouts|yells|hollers|auctions|grats|questions|answers|narrates|OOCs|tells the group|tells your group)(?: to [^']*)?,? '`),
+	// [Gossip] Bob: hi
+	regexp.MustCompile(`^\[[A-Za-z ]+\] (\S+?): `),
+}
+
+// Sender returns the player who spoke a tell, say or channel line, or ""
+// when the line isn't one
+func Sender(line string) string {
+	line = strings.TrimSpace(line)
+	for _, pattern := range senderPatterns {
+		if matches := pattern.FindStringSubmatch(line); matches != nil {
+			return matches[1]
+		}
+	}
+	return ""
+}
+
+// NewList creates an empty mute list
+func NewList() *List {
+	return &List{Players: []string{}}
+}
+
+// GetMutesPath returns the path to the mute list for an account on a server
+func GetMutesPath(host string, port int, username string) (string, error) {
+	var configDir string
+
+	// Check for environment variable override
+	if envConfigDir := os.Getenv("DIKUCLIENT_CONFIG_DIR"); envConfigDir != "" {
+		configDir = envConfigDir
+	} else {
+		homeDir, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("failed to get home directory: %w", err)
+		}
+		configDir = filepath.Join(homeDir, ".config", "dikuclient")
+	}
+
+	if err := os.MkdirAll(configDir, 0700); err != nil {
+		return "", fmt.Errorf("failed to create config directory: %w", err)
+	}
+
+	filename := fmt.Sprintf("mutes_%s_%d.json", host, port)
+	if username != "" {
+		filename = fmt.Sprintf("mutes_%s_%d_%s.json", host, port, strings.ToLower(username))
+	}
+	return filepath.Join(configDir, filename), nil
+}
+
+// Load loads the mute list for an account from disk
+func Load(host string, port int, username string) (*List, error) {
+	mutesPath, err := GetMutesPath(host, port, username)
+	if err != nil {
+		return nil, err
+	}
+
+	return LoadFromPath(mutesPath)
+}
+
+// LoadFromPath loads a mute list from a specific path (useful for testing)
+func LoadFromPath(mutesPath string) (*List, error) {
+	data, err := os.ReadFile(mutesPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			// Return an empty list if the file doesn't exist
+			l := NewList()
+			l.filePath = mutesPath
+			return l, nil
+		}
+		return nil, fmt.Errorf("failed to read mute list: %w", err)
+	}
+
+	var l List
+	if err := json.Unmarshal(data, &l); err != nil {
+		return nil, fmt.Errorf("failed to parse mute list: %w", err)
+	}
+	l.filePath = mutesPath
+	return &l, nil
+}
+
+// Save saves the mute list to disk
+func (l *List) Save() error {
+	if l.filePath == "" {
+		return fmt.Errorf("no file path set for mute list")
+	}
+
+	data, err := json.MarshalIndent(l, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal mute list: %w", err)
+	}
+
+	if err := os.WriteFile(l.filePath, data, 0600); err != nil {
+		return fmt.Errorf("failed to write mute list: %w", err)
+	}
+
+	return nil
+}
+
+// IsMuted reports whether a player is muted, ignoring case
+func (l *List) IsMuted(player string) bool {
+	for _, muted := range l.Players {
+		if strings.EqualFold(muted, player) {
+			return true
+		}
+	}
+	return false
+}
+
+// Add mutes a player, returning false when they were already muted
+func (l *List) Add(player string) bool {
+	if player == "" || l.IsMuted(player) {
+		return false
+	}
+	l.Players = append(l.Players, player)
+	sort.Slice(l.Players, func(i, j int) bool {
+		return strings.ToLower(l.Players[i]) < strings.ToLower(l.Players[j])
+	})
+	return true
+}
+
+// Remove unmutes a player, returning false when they weren't muted
+func (l *List) Remove(player string) bool {
+	for i, muted := range l.Players {
+		if strings.EqualFold(muted, player) {
+			l.Players = append(l.Players[:i], l.Players[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,90 @@
+package mutes
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestSender(t *testing.T) {
+	tests := []struct {
+		line   string
+		sender string
+	}{
+		{"Bob tells you 'hi'", "Bob"},
+		{"Bob says 'hello there'", "Bob"},
+		{"Bob says to Alice, 'hello'", "Bob"},
+		{"Bob gossips 'anyone grouping?'", "Bob"},
+		{"Bob shouts, 'help!'", "Bob"},
+		{"Bob tells the group 'flee'", "Bob"},
+		{"[Gossip] Bob: anyone grouping?", "Bob"},
+		{"You say 'hi'", ""},
+		{"Bob arrives from the north.", ""},
+		{"A cityguard is standing here.", ""},
+	}
+
+	for _, tt := range tests {
+		if got := Sender(tt.line); got != tt.sender {
+			t.Errorf("Sender(%q) = %q, want %q", tt.line, got, tt.sender)
+		}
+	}
+}
+
+func TestAddRemove(t *testing.T) {
+	l := NewList()
+	if !l.Add("Bob") {
+		t.Fatal("Expected Bob to be added")
+	}
+	if l.Add("bob") {
+		t.Error("Expected muting bob again to be refused")
+	}
+	l.Add("alice")
+	if len(l.Players) != 2 || l.Players[0] != "alice" {
+		t.Errorf("Expected players sorted, got %v", l.Players)
+	}
+	if !l.IsMuted(Sender("BOB tells you 'buy my sword'")) {
+		t.Error("Expected a tell from a muted player to be filtered")
+	}
+	if l.IsMuted(Sender("Carol says 'hi'")) {
+		t.Error("Expected a say from an unmuted player to pass")
+	}
+	if !l.Remove("BOB") || l.Remove("bob") {
+		t.Error("Expected Bob to be removed exactly once")
+	}
+}
+
+func TestPersistence(t *testing.T) {
+	mutesPath := filepath.Join(t.TempDir(), "mutes.json")
+
+	l, err := LoadFromPath(mutesPath)
+	if err != nil {
+		t.Fatalf("Failed to load missing mute list: %v", err)
+	}
+	l.Add("Bob")
+	if err := l.Save(); err != nil {
+		t.Fatalf("Failed to save mute list: %v", err)
+	}
+
+	loaded, err := LoadFromPath(mutesPath)
+	if err != nil {
+		t.Fatalf("Failed to load mute list: %v", err)
+	}
+	if !loaded.IsMuted("bob") {
+		t.Errorf("Expected Bob to be muted after reload, got %v", loaded.Players)
+	}
+}
+
+func TestGetMutesPathPerAccount(t *testing.T) {
+	t.Setenv("DIKUCLIENT_CONFIG_DIR", t.TempDir())
+
+	first, err := GetMutesPath("mud.example.com", 4000, "Alice")
+	if err != nil {
+		t.Fatalf("GetMutesPath failed: %v", err)
+	}
+	second, _ := GetMutesPath("mud.example.com", 4000, "Bob")
+	if first == second {
+		t.Errorf("Expected different accounts to have different mute lists, both got %s", first)
+	}
+	if filepath.Base(first) != "mutes_mud.example.com_4000_alice.json" {
+		t.Errorf("Unexpected mute list name %s", filepath.Base(first))
+	}
+}
@@ -0,0 +1,127 @@
+// Package outpipe runs a command that is fed the MUD's output on its
+// standard input (--output-pipe), for speech synthesis or analysis tools.
+package outpipe
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"runtime"
+	"sync"
+	"time"
+)
+
+// bufferLines is how many lines may wait for a slow command before new
+// lines are dropped, so the client never waits on it
+const bufferLines = 1000
+
+// closeTimeout is how long Close waits for the command to finish reading
+// before killing it
+const closeTimeout = 2 * time.Second
+
+// Process is a running command whose standard input receives what is
+// written to it. Writes don't block; they fail once the command has exited.
+type Process struct {
+	cmd   *exec.Cmd
+	stdin io.WriteCloser
+	lines chan []byte
+	done  chan struct{} // Closed when the command has exited
+
+	mu     sync.Mutex
+	closed bool
+	err    error // Why the command exited
+}
+
+// Start runs command through the shell
+func Start(command string) (*Process, error) {
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.Command("cmd", "/C", command)
+	} else {
+		cmd = exec.Command("sh", "-c", command)
+	}
+	// The command's own output would garble the screen, so it is discarded
+
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create output pipe: %w", err)
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start output pipe command: %w", err)
+	}
+
+	p := &Process{
+		cmd:   cmd,
+		stdin: stdin,
+		lines: make(chan []byte, bufferLines),
+		done:  make(chan struct{}),
+	}
+	go p.copyLines()
+	go p.wait()
+	return p, nil
+}
+
+// copyLines writes queued lines to the command until the queue is closed
+// or the command stops reading
+func (p *Process) copyLines() {
+	defer p.stdin.Close()
+	for line := range p.lines {
+		if _, err := p.stdin.Write(line); err != nil {
+			return
+		}
+	}
+}
+
+// wait records how the command exited
+func (p *Process) wait() {
+	err := p.cmd.Wait()
+	if err == nil {
+		err = errors.New("command exited")
+	}
+	p.mu.Lock()
+	p.err = err
+	p.mu.Unlock()
+	close(p.done)
+}
+
+// Write queues b for the command's standard input. It fails once the
+// command has exited or the process was closed.
+func (p *Process) Write(b []byte) (int, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed {
+		return 0, errors.New("output pipe closed")
+	}
+	select {
+	case <-p.done:
+		return 0, p.err
+	default:
+	}
+
+	select {
+	case p.lines <- append([]byte(nil), b...):
+	default:
+		// The command isn't keeping up; drop the line rather than wait
+	}
+	return len(b), nil
+}
+
+// Close ends the command's input and waits briefly for it to finish,
+// killing it if it doesn't
+func (p *Process) Close() error {
+	p.mu.Lock()
+	if !p.closed {
+		p.closed = true
+		close(p.lines)
+	}
+	p.mu.Unlock()
+
+	select {
+	case <-p.done:
+	case <-time.After(closeTimeout):
+		p.cmd.Process.Kill()
+		<-p.done
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+package outpipe
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestLinesReachCommand(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a POSIX shell")
+	}
+	out := filepath.Join(t.TempDir(), "out.txt")
+
+	p, err := Start("cat > " + out)
+	if err != nil {
+		t.Fatalf("Failed to start: %v", err)
+	}
+	for _, line := range []string{"The goblin arrives.\n", "You get 10 gold coins.\n"} {
+		if _, err := p.Write([]byte(line)); err != nil {
+			t.Fatalf("Failed to write: %v", err)
+		}
+	}
+	p.Close()
+
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatalf("Failed to read output: %v", err)
+	}
+	if string(data) != "The goblin arrives.\nYou get 10 gold coins.\n" {
+		t.Errorf("Expected both lines, got %q", data)
+	}
+
+	if _, err := p.Write([]byte("late\n")); err == nil {
+		t.Error("Expected writing after Close to fail")
+	}
+}
+
+func TestWriteFailsAfterCommandExits(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a POSIX shell")
+	}
+
+	p, err := Start("exit 3")
+	if err != nil {
+		t.Fatalf("Failed to start: %v", err)
+	}
+	defer p.Close()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if _, err := p.Write([]byte("hello\n")); err != nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("Expected writes to fail once the command exited")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
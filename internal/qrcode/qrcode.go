@@ -0,0 +1,556 @@
+// Package qrcode generates QR codes for short strings such as share URLs.
+//
+// Only byte mode with error correction level L is supported, for versions
+// 1 through 10 (up to 271 bytes), which is plenty for URLs.
+package qrcode
+
+import (
+	"fmt"
+	"strings"
+)
+
+// MaxVersion is the largest QR code version this package can generate
+const MaxVersion = 10
+
+// blockLayout describes how a version's codewords are split into blocks
+type blockLayout struct {
+	ecPerBlock int // Error correction codewords per block
+	group1     int // Number of blocks in group 1
+	data1      int // Data codewords per group 1 block
+	group2     int // Number of blocks in group 2 (one more data codeword each)
+}
+
+// layoutsL holds block layouts for error correction level L, indexed by version
+var layoutsL = [MaxVersion + 1]blockLayout{
+	{},
+	{7, 1, 19, 0},
+	{10, 1, 34, 0},
+	{15, 1, 55, 0},
+	{20, 1, 80, 0},
+	{26, 1, 108, 0},
+	{18, 2, 68, 0},
+	{20, 2, 78, 0},
+	{24, 2, 97, 0},
+	{30, 2, 116, 0},
+	{18, 2, 68, 2},
+}
+
+// alignmentPositions holds the alignment pattern centre coordinates per version
+var alignmentPositions = [MaxVersion + 1][]int{
+	{},
+	{},
+	{6, 18},
+	{6, 22},
+	{6, 26},
+	{6, 30},
+	{6, 34},
+	{6, 22, 38},
+	{6, 24, 42},
+	{6, 26, 46},
+	{6, 28, 50},
+}
+
+// Code is a generated QR code
+type Code struct {
+	Version  int
+	Size     int
+	Mask     int
+	modules  [][]bool // true = dark module
+	function [][]bool // true = function pattern (not data)
+}
+
+// Encode generates a QR code for text using the smallest version that fits
+func Encode(text string) (*Code, error) {
+	data := []byte(text)
+
+	version := 0
+	for v := 1; v <= MaxVersion; v++ {
+		if len(data) <= byteCapacity(v) {
+			version = v
+			break
+		}
+	}
+	if version == 0 {
+		return nil, fmt.Errorf("text too long for QR code (%d bytes, max %d)", len(data), byteCapacity(MaxVersion))
+	}
+
+	c := newCode(version)
+	c.drawFunctionPatterns()
+	c.drawCodewords(addErrorCorrection(version, encodeData(version, data)))
+
+	// Pick the mask with the lowest penalty score
+	bestMask, bestPenalty := 0, -1
+	for mask := 0; mask < 8; mask++ {
+		c.applyMask(mask)
+		c.drawFormatBits(mask)
+		penalty := c.penalty()
+		if bestPenalty < 0 || penalty < bestPenalty {
+			bestMask, bestPenalty = mask, penalty
+		}
+		c.applyMask(mask) // Masks are XOR, so applying again undoes it
+	}
+	c.applyMask(bestMask)
+	c.drawFormatBits(bestMask)
+	c.Mask = bestMask
+
+	return c, nil
+}
+
+// IsDark reports whether the module at column x, row y is dark
+func (c *Code) IsDark(x, y int) bool {
+	if x < 0 || y < 0 || x >= c.Size || y >= c.Size {
+		return false // Quiet zone
+	}
+	return c.modules[y][x]
+}
+
+// Render draws the code with Unicode half blocks, two rows per line, with a
+// quiet zone of the given width. Light modules are drawn as filled blocks so
+// the code scans correctly on a dark terminal background.
+func (c *Code) Render(quiet int) []string {
+	var lines []string
+	for y := -quiet; y < c.Size+quiet; y += 2 {
+		var sb strings.Builder
+		for x := -quiet; x < c.Size+quiet; x++ {
+			topLight := !c.IsDark(x, y)
+			bottomLight := !c.IsDark(x, y+1) && y+1 < c.Size+quiet
+			switch {
+			case topLight && bottomLight:
+				sb.WriteString("█")
+			case topLight:
+				sb.WriteString("▀")
+			case bottomLight:
+				sb.WriteString("▄")
+			default:
+				sb.WriteString(" ")
+			}
+		}
+		lines = append(lines, sb.String())
+	}
+	return lines
+}
+
+// byteCapacity returns how many bytes fit in a version at level L
+func byteCapacity(version int) int {
+	bits := dataCodewords(version)*8 - 4 - countBits(version)
+	return bits / 8
+}
+
+// dataCodewords returns the total number of data codewords for a version
+func dataCodewords(version int) int {
+	l := layoutsL[version]
+	return l.group1*l.data1 + l.group2*(l.data1+1)
+}
+
+// countBits returns the width of the byte mode character count field
+func countBits(version int) int {
+	if version < 10 {
+		return 8
+	}
+	return 16
+}
+
+// encodeData builds the padded data codewords for byte mode
+func encodeData(version int, data []byte) []byte {
+	capacity := dataCodewords(version) * 8
+
+	var bits []bool
+	appendBits := func(value, n int) {
+		for i := n - 1; i >= 0; i-- {
+			bits = append(bits, (value>>i)&1 == 1)
+		}
+	}
+
+	appendBits(0x4, 4) // Byte mode indicator
+	appendBits(len(data), countBits(version))
+	for _, b := range data {
+		appendBits(int(b), 8)
+	}
+
+	// Terminator, then pad to a byte boundary
+	for i := 0; i < 4 && len(bits) < capacity; i++ {
+		bits = append(bits, false)
+	}
+	for len(bits)%8 != 0 {
+		bits = append(bits, false)
+	}
+
+	result := make([]byte, 0, dataCodewords(version))
+	for i := 0; i < len(bits); i += 8 {
+		var b byte
+		for j := 0; j < 8; j++ {
+			if bits[i+j] {
+				b |= 1 << (7 - j)
+			}
+		}
+		result = append(result, b)
+	}
+
+	// Alternate pad bytes until full
+	for pad := byte(0xEC); len(result) < dataCodewords(version); pad ^= 0xEC ^ 0x11 {
+		result = append(result, pad)
+	}
+
+	return result
+}
+
+// addErrorCorrection splits data into blocks, appends Reed-Solomon codewords
+// and interleaves the result in the order it is placed in the symbol
+func addErrorCorrection(version int, data []byte) []byte {
+	l := layoutsL[version]
+	divisor := rsDivisor(l.ecPerBlock)
+
+	var dataBlocks, ecBlocks [][]byte
+	offset := 0
+	for i := 0; i < l.group1+l.group2; i++ {
+		n := l.data1
+		if i >= l.group1 {
+			n++
+		}
+		block := data[offset : offset+n]
+		offset += n
+		dataBlocks = append(dataBlocks, block)
+		ecBlocks = append(ecBlocks, rsRemainder(block, divisor))
+	}
+
+	var result []byte
+	for i := 0; i < l.data1+1; i++ {
+		for _, block := range dataBlocks {
+			if i < len(block) {
+				result = append(result, block[i])
+			}
+		}
+	}
+	for i := 0; i < l.ecPerBlock; i++ {
+		for _, block := range ecBlocks {
+			result = append(result, block[i])
+		}
+	}
+	return result
+}
+
+// gfMultiply multiplies two elements of GF(256) modulo x^8+x^4+x^3+x^2+1
+func gfMultiply(a, b byte) byte {
+	var result byte
+	for i := 7; i >= 0; i-- {
+		carry := result&0x80 != 0
+		result <<= 1
+		if carry {
+			result ^= 0x1D
+		}
+		if (b>>i)&1 == 1 {
+			result ^= a
+		}
+	}
+	return result
+}
+
+// rsDivisor returns the Reed-Solomon generator polynomial of the given degree,
+// without its leading coefficient
+func rsDivisor(degree int) []byte {
+	result := make([]byte, degree)
+	result[degree-1] = 1
+	root := byte(1)
+	for i := 0; i < degree; i++ {
+		for j := range result {
+			result[j] = gfMultiply(result[j], root)
+			if j+1 < len(result) {
+				result[j] ^= result[j+1]
+			}
+		}
+		root = gfMultiply(root, 0x02)
+	}
+	return result
+}
+
+// rsRemainder computes the error correction codewords for a block
+func rsRemainder(data, divisor []byte) []byte {
+	result := make([]byte, len(divisor))
+	for _, b := range data {
+		factor := b ^ result[0]
+		copy(result, result[1:])
+		result[len(result)-1] = 0
+		for i, coef := range divisor {
+			result[i] ^= gfMultiply(coef, factor)
+		}
+	}
+	return result
+}
+
+// newCode creates an empty symbol for a version
+func newCode(version int) *Code {
+	size := version*4 + 17
+	c := &Code{Version: version, Size: size}
+	c.modules = make([][]bool, size)
+	c.function = make([][]bool, size)
+	for y := 0; y < size; y++ {
+		c.modules[y] = make([]bool, size)
+		c.function[y] = make([]bool, size)
+	}
+	return c
+}
+
+// setFunction sets a function module and marks it as reserved
+func (c *Code) setFunction(x, y int, dark bool) {
+	c.modules[y][x] = dark
+	c.function[y][x] = true
+}
+
+// drawFunctionPatterns draws finder, timing and alignment patterns and
+// reserves the format and version information areas
+func (c *Code) drawFunctionPatterns() {
+	// Timing patterns
+	for i := 0; i < c.Size; i++ {
+		c.setFunction(6, i, i%2 == 0)
+		c.setFunction(i, 6, i%2 == 0)
+	}
+
+	// Finder patterns with their separators
+	c.drawFinder(3, 3)
+	c.drawFinder(c.Size-4, 3)
+	c.drawFinder(3, c.Size-4)
+
+	// Alignment patterns, skipping the three that overlap finders
+	positions := alignmentPositions[c.Version]
+	last := len(positions) - 1
+	for i, ax := range positions {
+		for j, ay := range positions {
+			if (i == 0 && j == 0) || (i == 0 && j == last) || (i == last && j == 0) {
+				continue
+			}
+			for dy := -2; dy <= 2; dy++ {
+				for dx := -2; dx <= 2; dx++ {
+					dist := max(abs(dx), abs(dy))
+					c.setFunction(ax+dx, ay+dy, dist != 1)
+				}
+			}
+		}
+	}
+
+	// Reserve format areas; real bits are drawn once the mask is chosen
+	c.drawFormatBits(0)
+	c.drawVersion()
+}
+
+// drawFinder draws a finder pattern and separator centred on (cx, cy)
+func (c *Code) drawFinder(cx, cy int) {
+	for dy := -4; dy <= 4; dy++ {
+		for dx := -4; dx <= 4; dx++ {
+			x, y := cx+dx, cy+dy
+			if x < 0 || y < 0 || x >= c.Size || y >= c.Size {
+				continue
+			}
+			dist := max(abs(dx), abs(dy))
+			c.setFunction(x, y, dist != 2 && dist != 4)
+		}
+	}
+}
+
+// formatBits returns the 15-bit format information for level L and a mask
+func formatBits(mask int) int {
+	data := 1<<3 | mask // Level L is 01
+	rem := data
+	for i := 0; i < 10; i++ {
+		rem = (rem << 1) ^ ((rem >> 9) * 0x537)
+	}
+	return (data<<10 | rem) ^ 0x5412
+}
+
+// drawFormatBits draws both copies of the format information
+func (c *Code) drawFormatBits(mask int) {
+	bits := formatBits(mask)
+	bit := func(i int) bool { return (bits>>i)&1 == 1 }
+
+	// Copy around the top-left finder
+	for i := 0; i <= 5; i++ {
+		c.setFunction(8, i, bit(i))
+	}
+	c.setFunction(8, 7, bit(6))
+	c.setFunction(8, 8, bit(7))
+	c.setFunction(7, 8, bit(8))
+	for i := 9; i < 15; i++ {
+		c.setFunction(14-i, 8, bit(i))
+	}
+
+	// Copy split between the top-right and bottom-left finders
+	for i := 0; i < 8; i++ {
+		c.setFunction(c.Size-1-i, 8, bit(i))
+	}
+	for i := 8; i < 15; i++ {
+		c.setFunction(8, c.Size-15+i, bit(i))
+	}
+	c.setFunction(8, c.Size-8, true) // Always-dark module
+}
+
+// versionBits returns the 18-bit version information for a version
+func versionBits(version int) int {
+	rem := version
+	for i := 0; i < 12; i++ {
+		rem = (rem << 1) ^ ((rem >> 11) * 0x1F25)
+	}
+	return version<<12 | rem
+}
+
+// drawVersion draws the version information blocks (version 7 and up)
+func (c *Code) drawVersion() {
+	if c.Version < 7 {
+		return
+	}
+
+	bits := versionBits(c.Version)
+
+	for i := 0; i < 18; i++ {
+		dark := (bits>>i)&1 == 1
+		a, b := c.Size-11+i%3, i/3
+		c.setFunction(a, b, dark)
+		c.setFunction(b, a, dark)
+	}
+}
+
+// drawCodewords places codewords in the zigzag data area
+func (c *Code) drawCodewords(data []byte) {
+	i := 0
+	for right := c.Size - 1; right >= 1; right -= 2 {
+		if right == 6 {
+			right = 5 // Skip the vertical timing pattern
+		}
+		for vert := 0; vert < c.Size; vert++ {
+			for j := 0; j < 2; j++ {
+				x := right - j
+				y := vert
+				if (right+1)&2 == 0 {
+					y = c.Size - 1 - vert // Moving upwards
+				}
+				if c.function[y][x] {
+					continue
+				}
+				// Remainder bits past the end of the data stay light
+				if i < len(data)*8 {
+					c.modules[y][x] = (data[i>>3]>>(7-(i&7)))&1 == 1
+					i++
+				}
+			}
+		}
+	}
+}
+
+// maskBit reports whether a mask pattern inverts the module at (x, y)
+func maskBit(mask, x, y int) bool {
+	switch mask {
+	case 0:
+		return (x+y)%2 == 0
+	case 1:
+		return y%2 == 0
+	case 2:
+		return x%3 == 0
+	case 3:
+		return (x+y)%3 == 0
+	case 4:
+		return (x/3+y/2)%2 == 0
+	case 5:
+		return x*y%2+x*y%3 == 0
+	case 6:
+		return (x*y%2+x*y%3)%2 == 0
+	default:
+		return ((x+y)%2+x*y%3)%2 == 0
+	}
+}
+
+// applyMask XORs a mask pattern over the data modules
+func (c *Code) applyMask(mask int) {
+	for y := 0; y < c.Size; y++ {
+		for x := 0; x < c.Size; x++ {
+			if !c.function[y][x] && maskBit(mask, x, y) {
+				c.modules[y][x] = !c.modules[y][x]
+			}
+		}
+	}
+}
+
+// penalty scores the symbol using the standard mask evaluation rules
+func (c *Code) penalty() int {
+	score := 0
+	dark := 0
+
+	for y := 0; y < c.Size; y++ {
+		for x := 0; x < c.Size; x++ {
+			if c.modules[y][x] {
+				dark++
+			}
+			// 2x2 blocks of the same colour
+			if x+1 < c.Size && y+1 < c.Size {
+				v := c.modules[y][x]
+				if c.modules[y][x+1] == v && c.modules[y+1][x] == v && c.modules[y+1][x+1] == v {
+					score += 3
+				}
+			}
+		}
+	}
+
+	// Runs and finder-like patterns in rows and columns
+	finderLike := []bool{true, false, true, true, true, false, true}
+	for i := 0; i < c.Size; i++ {
+		row := make([]bool, c.Size)
+		col := make([]bool, c.Size)
+		for j := 0; j < c.Size; j++ {
+			row[j] = c.modules[i][j]
+			col[j] = c.modules[j][i]
+		}
+		for _, line := range [][]bool{row, col} {
+			run := 1
+			for j := 1; j <= len(line); j++ {
+				if j < len(line) && line[j] == line[j-1] {
+					run++
+					continue
+				}
+				if run >= 5 {
+					score += 3 + run - 5
+				}
+				run = 1
+			}
+			for j := 0; j+7 <= len(line); j++ {
+				if !matches(line[j:j+7], finderLike) {
+					continue
+				}
+				if lightRun(line, j-4, j) || lightRun(line, j+7, j+11) {
+					score += 40
+				}
+			}
+		}
+	}
+
+	// Balance of dark and light modules
+	total := c.Size * c.Size
+	deviation := abs(dark*20-total*10) / total
+	score += deviation * 10
+
+	return score
+}
+
+// matches reports whether two module runs are identical
+func matches(a, b []bool) bool {
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// lightRun reports whether line[from:to] is all light, treating positions
+// outside the symbol as light quiet zone
+func lightRun(line []bool, from, to int) bool {
+	for i := from; i < to; i++ {
+		if i >= 0 && i < len(line) && line[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
@@ -0,0 +1,271 @@
+package qrcode
+
+import (
+	"strings"
+	"testing"
+)
+
+const testURL = "http://localhost:8080/?id=3f2b8c1e-9d4a-4e7b-a6c5-0123456789ab"
+
+// decode reads a code back into its payload the way a scanner would: it reads
+// the format information, unmasks the data area, checks every Reed-Solomon
+// block for errors and parses the byte mode segment
+func decode(t *testing.T, c *Code) string {
+	t.Helper()
+
+	// Read the format bits from the copy around the top-left finder
+	var bits int
+	for i := 0; i <= 5; i++ {
+		if c.IsDark(8, i) {
+			bits |= 1 << i
+		}
+	}
+	if c.IsDark(8, 7) {
+		bits |= 1 << 6
+	}
+	if c.IsDark(8, 8) {
+		bits |= 1 << 7
+	}
+	if c.IsDark(7, 8) {
+		bits |= 1 << 8
+	}
+	for i := 9; i < 15; i++ {
+		if c.IsDark(14-i, 8) {
+			bits |= 1 << i
+		}
+	}
+
+	// The second copy must agree with the first
+	var bits2 int
+	for i := 0; i < 8; i++ {
+		if c.IsDark(c.Size-1-i, 8) {
+			bits2 |= 1 << i
+		}
+	}
+	for i := 8; i < 15; i++ {
+		if c.IsDark(8, c.Size-15+i) {
+			bits2 |= 1 << i
+		}
+	}
+	if bits != bits2 {
+		t.Fatalf("Format information copies differ: %015b vs %015b", bits, bits2)
+	}
+
+	mask := -1
+	for candidate := 0; candidate < 8; candidate++ {
+		if formatBits(candidate) == bits {
+			mask = candidate
+		}
+	}
+	if mask < 0 {
+		t.Fatalf("Format bits %015b are not a valid level L format", bits)
+	}
+
+	// Reserved areas come from a fresh symbol of the same version
+	reference := newCode(c.Version)
+	reference.drawFunctionPatterns()
+
+	var codewords []byte
+	var current byte
+	count := 0
+	for right := c.Size - 1; right >= 1; right -= 2 {
+		if right == 6 {
+			right = 5
+		}
+		for vert := 0; vert < c.Size; vert++ {
+			for j := 0; j < 2; j++ {
+				x := right - j
+				y := vert
+				if (right+1)&2 == 0 {
+					y = c.Size - 1 - vert
+				}
+				if reference.function[y][x] {
+					continue
+				}
+				bit := c.IsDark(x, y) != maskBit(mask, x, y)
+				current <<= 1
+				if bit {
+					current |= 1
+				}
+				count++
+				if count%8 == 0 {
+					codewords = append(codewords, current)
+					current = 0
+				}
+			}
+		}
+	}
+
+	// De-interleave into blocks
+	l := layoutsL[c.Version]
+	numBlocks := l.group1 + l.group2
+	blocks := make([][]byte, numBlocks)
+	pos := 0
+	for i := 0; i < l.data1+1; i++ {
+		for b := 0; b < numBlocks; b++ {
+			if i < l.data1 || b >= l.group1 {
+				blocks[b] = append(blocks[b], codewords[pos])
+				pos++
+			}
+		}
+	}
+	for i := 0; i < l.ecPerBlock; i++ {
+		for b := 0; b < numBlocks; b++ {
+			blocks[b] = append(blocks[b], codewords[pos])
+			pos++
+		}
+	}
+
+	// Every syndrome must be zero for an error-free block
+	var data []byte
+	for b, block := range blocks {
+		alpha := byte(1)
+		for i := 0; i < l.ecPerBlock; i++ {
+			var syndrome byte
+			for _, cw := range block {
+				syndrome = gfMultiply(syndrome, alpha) ^ cw
+			}
+			if syndrome != 0 {
+				t.Fatalf("Block %d has non-zero syndrome %d", b, i)
+			}
+			alpha = gfMultiply(alpha, 0x02)
+		}
+		data = append(data, block[:len(block)-l.ecPerBlock]...)
+	}
+
+	// Parse the byte mode segment
+	readBits := func(offset, n int) int {
+		value := 0
+		for i := 0; i < n; i++ {
+			bit := (data[(offset+i)/8] >> (7 - (offset+i)%8)) & 1
+			value = value<<1 | int(bit)
+		}
+		return value
+	}
+	if mode := readBits(0, 4); mode != 0x4 {
+		t.Fatalf("Expected byte mode indicator, got %04b", mode)
+	}
+	length := readBits(4, countBits(c.Version))
+	offset := 4 + countBits(c.Version)
+	payload := make([]byte, length)
+	for i := range payload {
+		payload[i] = byte(readBits(offset+i*8, 8))
+	}
+	return string(payload)
+}
+
+// checkFinder verifies a 7x7 finder pattern with its top-left corner at (x, y)
+func checkFinder(t *testing.T, c *Code, x, y int) {
+	t.Helper()
+	for dy := 0; dy < 7; dy++ {
+		for dx := 0; dx < 7; dx++ {
+			ring := max(abs(dx-3), abs(dy-3))
+			want := ring != 2
+			if c.IsDark(x+dx, y+dy) != want {
+				t.Fatalf("Finder at (%d,%d) wrong at offset (%d,%d)", x, y, dx, dy)
+			}
+		}
+	}
+}
+
+func TestEncodeURLIsScannable(t *testing.T) {
+	c, err := Encode(testURL)
+	if err != nil {
+		t.Fatalf("Encode failed: %v", err)
+	}
+
+	if c.Version != 4 || c.Size != 33 {
+		t.Errorf("Expected version 4 (33x33) for a %d byte URL, got version %d (%dx%d)", len(testURL), c.Version, c.Size, c.Size)
+	}
+
+	checkFinder(t, c, 0, 0)
+	checkFinder(t, c, c.Size-7, 0)
+	checkFinder(t, c, 0, c.Size-7)
+
+	// Timing patterns alternate between the finders
+	for i := 8; i < c.Size-8; i++ {
+		if c.IsDark(i, 6) != (i%2 == 0) || c.IsDark(6, i) != (i%2 == 0) {
+			t.Fatalf("Timing pattern broken at %d", i)
+		}
+	}
+
+	if got := decode(t, c); got != testURL {
+		t.Errorf("Decoded %q, want %q", got, testURL)
+	}
+}
+
+func TestEncodeAllVersions(t *testing.T) {
+	for version := 1; version <= MaxVersion; version++ {
+		text := strings.Repeat("x", byteCapacity(version))
+		c, err := Encode(text)
+		if err != nil {
+			t.Fatalf("Encode failed for version %d: %v", version, err)
+		}
+		if c.Version != version {
+			t.Errorf("Expected %d bytes to need version %d, got %d", len(text), version, c.Version)
+		}
+		if got := decode(t, c); got != text {
+			t.Errorf("Version %d: decoded %q, want %q", version, got, text)
+		}
+	}
+}
+
+func TestEncodeTooLong(t *testing.T) {
+	if _, err := Encode(strings.Repeat("x", byteCapacity(MaxVersion)+1)); err == nil {
+		t.Error("Expected error for text longer than the maximum capacity")
+	}
+}
+
+func TestRender(t *testing.T) {
+	c, err := Encode("hello")
+	if err != nil {
+		t.Fatalf("Encode failed: %v", err)
+	}
+
+	lines := c.Render(2)
+	width := c.Size + 4
+	if len(lines) != (width+1)/2 {
+		t.Errorf("Expected %d lines, got %d", (width+1)/2, len(lines))
+	}
+	for i, line := range lines {
+		if n := len([]rune(line)); n != width {
+			t.Errorf("Line %d has width %d, want %d", i, n, width)
+		}
+	}
+
+	// The quiet zone is light all the way across
+	if strings.Trim(lines[0], "█") != "" {
+		t.Errorf("Expected first line to be quiet zone, got %q", lines[0])
+	}
+}
+
+func TestFormatAndVersionBits(t *testing.T) {
+	// Level L format information from the QR code specification
+	expected := []int{
+		0b111011111000100,
+		0b111001011110011,
+		0b111110110101010,
+		0b111100010011101,
+		0b110011000101111,
+		0b110001100011000,
+		0b110110001000001,
+		0b110100101110110,
+	}
+	for mask, want := range expected {
+		if got := formatBits(mask); got != want {
+			t.Errorf("formatBits(%d) = %015b, want %015b", mask, got, want)
+		}
+	}
+
+	versions := map[int]int{
+		7:  0b000111110010010100,
+		8:  0b001000010110111100,
+		9:  0b001001101010011001,
+		10: 0b001010010011010011,
+	}
+	for version, want := range versions {
+		if got := versionBits(version); got != want {
+			t.Errorf("versionBits(%d) = %018b, want %018b", version, got, want)
+		}
+	}
+}
@@ -0,0 +1,51 @@
+// Package redact hides secrets such as login names and passwords in the
+// client's logs, so a log can be attached to a bug report as it is.
+package redact
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Placeholder replaces each redacted string
+const Placeholder = "[REDACTED]"
+
+// MinLength is the shortest string redacted; hiding every "a" or "no"
+// would leave nothing readable
+const MinLength = 3
+
+// Redactor replaces a set of strings wherever they appear, ignoring case.
+// A nil *Redactor leaves text unchanged.
+type Redactor struct {
+	pattern *regexp.Regexp
+}
+
+// New creates a redactor for secrets, skipping those shorter than
+// MinLength. It returns nil when there is nothing to redact.
+func New(secrets []string) *Redactor {
+	var quoted []string
+	seen := make(map[string]bool)
+	for _, secret := range secrets {
+		key := strings.ToLower(secret)
+		if len(secret) < MinLength || seen[key] {
+			continue
+		}
+		seen[key] = true
+		quoted = append(quoted, regexp.QuoteMeta(secret))
+	}
+	if len(quoted) == 0 {
+		return nil
+	}
+	// Try longer secrets first so one containing another is hidden whole
+	sort.SliceStable(quoted, func(i, j int) bool { return len(quoted[i]) > len(quoted[j]) })
+	return &Redactor{pattern: regexp.MustCompile("(?i)" + strings.Join(quoted, "|"))}
+}
+
+// Redact returns text with every secret replaced by Placeholder
+func (r *Redactor) Redact(text string) string {
+	if r == nil {
+		return text
+	}
+	return r.pattern.ReplaceAllLiteralString(text, Placeholder)
+}
@@ -0,0 +1,39 @@
+package redact
+
+import "testing"
+
+func TestRedactReplacesSecrets(t *testing.T) {
+	r := New([]string{"Gandalf", "hunter2", "hunter2x", "ok", ""})
+
+	tests := []struct {
+		input, want string
+	}{
+		{"Gandalf tells you 'hi'", "[REDACTED] tells you 'hi'"},
+		{"Welcome back, GANDALF.", "Welcome back, [REDACTED]."},
+		{"password: hunter2x", "password: [REDACTED]"},
+		{"sent hunter2", "sent [REDACTED]"},
+		{"ok, nothing secret here", "ok, nothing secret here"},
+	}
+	for _, tt := range tests {
+		if got := r.Redact(tt.input); got != tt.want {
+			t.Errorf("Redact(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestRedactNothing(t *testing.T) {
+	r := New([]string{"", "ab"})
+	if r != nil {
+		t.Fatalf("Expected no redactor for strings too short to redact, got %v", r)
+	}
+	if got := r.Redact("ab cd"); got != "ab cd" {
+		t.Errorf("Expected a nil redactor to leave text alone, got %q", got)
+	}
+}
+
+func TestRedactQuotesPatterns(t *testing.T) {
+	r := New([]string{"a.b*c"})
+	if got := r.Redact("axbbbc a.b*c"); got != "axbbbc [REDACTED]" {
+		t.Errorf("Expected the secret matched literally, got %q", got)
+	}
+}
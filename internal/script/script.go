@@ -0,0 +1,64 @@
+package script
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Step is one line of a command script: a command to send (or a client
+// command starting with /), or a pause
+type Step struct {
+	Command string        // Command to run, empty for a wait
+	Wait    time.Duration // How long to pause before the next step
+}
+
+// LoadFile reads a command script from a file
+func LoadFile(path string) ([]Step, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open command file: %w", err)
+	}
+	defer file.Close()
+
+	return Parse(file)
+}
+
+// Parse reads a command script: one command per line, with blank lines and
+// lines starting with # ignored, and "wait <seconds>" pausing the script
+func Parse(r io.Reader) ([]Step, error) {
+	var steps []Step
+	scanner := bufio.NewScanner(r)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		if strings.ToLower(fields[0]) == "wait" {
+			if len(fields) != 2 {
+				return nil, fmt.Errorf("line %d: usage: wait <seconds>", lineNum)
+			}
+			seconds, err := strconv.ParseFloat(fields[1], 64)
+			if err != nil || seconds <= 0 {
+				return nil, fmt.Errorf("line %d: invalid wait time %q", lineNum, fields[1])
+			}
+			steps = append(steps, Step{Wait: time.Duration(seconds * float64(time.Second))})
+			continue
+		}
+
+		steps = append(steps, Step{Command: line})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read command file: %w", err)
+	}
+
+	return steps, nil
+}
@@ -0,0 +1,78 @@
+package script
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseSkipsCommentsAndBlankLines(t *testing.T) {
+	input := `# Login routine
+wear all
+
+  # channels
+join gossip
+/set target orc
+WAIT 2
+wait 0.5
+say ready
+`
+	steps, err := Parse(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Failed to parse script: %v", err)
+	}
+
+	expected := []Step{
+		{Command: "wear all"},
+		{Command: "join gossip"},
+		{Command: "/set target orc"},
+		{Wait: 2 * time.Second},
+		{Wait: 500 * time.Millisecond},
+		{Command: "say ready"},
+	}
+	if len(steps) != len(expected) {
+		t.Fatalf("Expected %d steps, got %d: %+v", len(expected), len(steps), steps)
+	}
+	for i := range expected {
+		if steps[i] != expected[i] {
+			t.Errorf("Step %d: expected %+v, got %+v", i, expected[i], steps[i])
+		}
+	}
+}
+
+func TestParseRejectsBadWait(t *testing.T) {
+	for _, input := range []string{"wait", "wait soon", "wait -1", "wait 1 2"} {
+		if _, err := Parse(strings.NewReader("wear all\n" + input)); err == nil {
+			t.Errorf("Expected %q to be rejected", input)
+		} else if !strings.Contains(err.Error(), "line 2") {
+			t.Errorf("Expected the error to name line 2, got %v", err)
+		}
+	}
+
+	// "waiting" is an ordinary command, not a wait directive
+	steps, err := Parse(strings.NewReader("waiting room"))
+	if err != nil || len(steps) != 1 || steps[0].Command != "waiting room" {
+		t.Errorf("Expected 'waiting room' to be a command, got %+v (%v)", steps, err)
+	}
+}
+
+func TestLoadFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "login.txt")
+	if err := os.WriteFile(path, []byte("wear all\nwait 1\n"), 0600); err != nil {
+		t.Fatalf("Failed to write script: %v", err)
+	}
+
+	steps, err := LoadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to load script: %v", err)
+	}
+	if len(steps) != 2 || steps[0].Command != "wear all" || steps[1].Wait != time.Second {
+		t.Errorf("Unexpected steps: %+v", steps)
+	}
+
+	if _, err := LoadFile(filepath.Join(t.TempDir(), "missing.txt")); err == nil {
+		t.Error("Expected an error for a missing file")
+	}
+}
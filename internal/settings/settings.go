@@ -0,0 +1,195 @@
+package settings
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// DefaultWalkDelayMs is the default delay between auto-walk steps in milliseconds
+const DefaultWalkDelayMs = 1000
+
+// MinWalkDelayMs is the smallest walk delay allowed, to avoid flooding the server
+const MinWalkDelayMs = 100
+
+// DefaultAfkCommand is sent after the AFK idle time when no command is set
+const DefaultAfkCommand = "rest"
+
+// DefaultAutoLootCommand is queued after a kill when auto-loot has no command set
+const DefaultAutoLootCommand = "get all corpse"
+
+// DefaultSplitRatio is the percentage of the split view's height given to
+// the scrolled-back output above the live output
+const DefaultSplitRatio = 67
+
+// The split ratio is kept within these percentages so both panes stay usable
+const (
+	MinSplitRatio = 10
+	MaxSplitRatio = 90
+)
+
+// MinInventorySeconds is the shortest inventory auto-refresh interval, so
+// the refresh can't flood the MUD
+const MinInventorySeconds = 10
+
+// Manager holds persistent client settings
+type Manager struct {
+	WalkDelayMs      int               `json:"walk_delay_ms,omitempty"`     // Delay between auto-walk steps (0 = default)
+	FastWalk         bool              `json:"fast_walk,omitempty"`         // Send the whole /go path at once
+	NumpadWalk       bool              `json:"numpad_walk,omitempty"`       // Numpad/arrow keys walk when the input is empty
+	HidePrompt       bool              `json:"hide_prompt,omitempty"`       // Show the stat prompt in the status bar instead of the output
+	AffectAction     string            `json:"affect_action,omitempty"`     // Command run when an affect is about to wear off (<affect> = name)
+	AfkSeconds       int               `json:"afk_seconds,omitempty"`       // Idle time before the AFK command is sent (0 = off)
+	AfkCommand       string            `json:"afk_command,omitempty"`       // Command sent when idle (empty = default)
+	ThrottleMs       int               `json:"throttle_ms,omitempty"`       // Minimum delay between commands sent to the MUD (0 = off)
+	PlainText        bool              `json:"plain_text,omitempty"`        // Strip all ANSI colors from the output (/ansi off)
+	PromptNewline    bool              `json:"prompt_newline,omitempty"`    // Start a new line after a prompt that doesn't end with one
+	AutoLoot         bool              `json:"auto_loot,omitempty"`         // Queue a loot command when a creature dies
+	AutoLootCommand  string            `json:"auto_loot_command,omitempty"` // Loot command(s); <creature> = name of what died (empty = default)
+	CollapseBlanks   bool              `json:"collapse_blanks,omitempty"`   // Show a run of blank lines from the MUD as a single blank line
+	LinkURLs         bool              `json:"link_urls,omitempty"`         // Make web addresses in MUD output clickable (OSC 8 hyperlinks)
+	TrailPanel       bool              `json:"trail_panel,omitempty"`       // Show the rooms visited most recently above the map
+	SplitRatio       int               `json:"split_ratio,omitempty"`       // Percent of the split view for scrolled-back output (0 = default)
+	SplitPinned      bool              `json:"split_pinned,omitempty"`      // Always split the output, not only while scrolled back
+	InventorySeconds int               `json:"inventory_seconds,omitempty"` // Re-send "inventory" this often to refresh the panel (0 = off)
+	Wimpy            string            `json:"wimpy,omitempty"`             // Flee below these hit points, or percent with a % (empty = off)
+	LogRedactions    []string          `json:"log_redactions,omitempty"`    // Strings replaced by [REDACTED] in the logs (/log redact)
+	Theme            map[string]string `json:"theme,omitempty"`             // Colors of the client's messages by kind (e.g. "error": "196")
+	filePath         string            // Path to settings.json (not serialized)
+}
+
+// NewManager creates a new settings manager with default values
+func NewManager() *Manager {
+	return &Manager{}
+}
+
+// GetSettingsPath returns the path to the settings file
+func GetSettingsPath() (string, error) {
+	var configDir string
+
+	// Check for environment variable override
+	if envConfigDir := os.Getenv("DIKUCLIENT_CONFIG_DIR"); envConfigDir != "" {
+		configDir = envConfigDir
+	} else {
+		homeDir, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("failed to get home directory: %w", err)
+		}
+		configDir = filepath.Join(homeDir, ".config", "dikuclient")
+	}
+
+	if err := os.MkdirAll(configDir, 0700); err != nil {
+		return "", fmt.Errorf("failed to create config directory: %w", err)
+	}
+
+	return filepath.Join(configDir, "settings.json"), nil
+}
+
+// Load loads settings from disk
+func Load() (*Manager, error) {
+	settingsPath, err := GetSettingsPath()
+	if err != nil {
+		return nil, err
+	}
+
+	return LoadFromPath(settingsPath)
+}
+
+// LoadFromPath loads settings from a specific path (useful for testing)
+func LoadFromPath(settingsPath string) (*Manager, error) {
+	data, err := os.ReadFile(settingsPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			// Return default settings if file doesn't exist
+			m := NewManager()
+			m.filePath = settingsPath
+			return m, nil
+		}
+		return nil, fmt.Errorf("failed to read settings file: %w", err)
+	}
+
+	var m Manager
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("failed to parse settings file: %w", err)
+	}
+	m.filePath = settingsPath
+
+	return &m, nil
+}
+
+// Save saves settings to disk
+func (m *Manager) Save() error {
+	settingsPath := m.filePath
+	if settingsPath == "" {
+		var err error
+		settingsPath, err = GetSettingsPath()
+		if err != nil {
+			return err
+		}
+		m.filePath = settingsPath
+	}
+
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal settings: %w", err)
+	}
+
+	if err := os.WriteFile(settingsPath, data, 0600); err != nil {
+		return fmt.Errorf("failed to write settings file: %w", err)
+	}
+
+	return nil
+}
+
+// GetWalkDelayMs returns the auto-walk delay, falling back to the default
+// and never going below the minimum
+func (m *Manager) GetWalkDelayMs() int {
+	if m.WalkDelayMs == 0 {
+		return DefaultWalkDelayMs
+	}
+	return ClampWalkDelayMs(m.WalkDelayMs)
+}
+
+// SetWalkDelayMs sets the auto-walk delay, clamped to the minimum
+func (m *Manager) SetWalkDelayMs(ms int) {
+	m.WalkDelayMs = ClampWalkDelayMs(ms)
+}
+
+// ClampWalkDelayMs enforces the minimum walk delay
+func ClampWalkDelayMs(ms int) int {
+	if ms < MinWalkDelayMs {
+		return MinWalkDelayMs
+	}
+	return ms
+}
+
+// GetAutoLootCommand returns the auto-loot command, falling back to the default
+func (m *Manager) GetAutoLootCommand() string {
+	if m.AutoLootCommand == "" {
+		return DefaultAutoLootCommand
+	}
+	return m.AutoLootCommand
+}
+
+// GetAfkCommand returns the AFK command, falling back to the default
+func (m *Manager) GetAfkCommand() string {
+	if m.AfkCommand == "" {
+		return DefaultAfkCommand
+	}
+	return m.AfkCommand
+}
+
+// GetSplitRatio returns the percentage of the split view for scrolled-back
+// output, or the default if none is set
+func (m *Manager) GetSplitRatio() int {
+	if m.SplitRatio == 0 {
+		return DefaultSplitRatio
+	}
+	return ClampSplitRatio(m.SplitRatio)
+}
+
+// ClampSplitRatio keeps a split ratio between the minimum and maximum
+func ClampSplitRatio(percent int) int {
+	return min(max(percent, MinSplitRatio), MaxSplitRatio)
+}
@@ -0,0 +1,90 @@
+package settings
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestDefaultWalkDelay(t *testing.T) {
+	m := NewManager()
+	if got := m.GetWalkDelayMs(); got != DefaultWalkDelayMs {
+		t.Errorf("Expected default walk delay %d, got %d", DefaultWalkDelayMs, got)
+	}
+}
+
+func TestSetWalkDelayClamps(t *testing.T) {
+	m := NewManager()
+
+	m.SetWalkDelayMs(10)
+	if got := m.GetWalkDelayMs(); got != MinWalkDelayMs {
+		t.Errorf("Expected walk delay to be clamped to %d, got %d", MinWalkDelayMs, got)
+	}
+
+	m.SetWalkDelayMs(250)
+	if got := m.GetWalkDelayMs(); got != 250 {
+		t.Errorf("Expected walk delay 250, got %d", got)
+	}
+}
+
+func TestSaveAndLoad(t *testing.T) {
+	tmpDir := t.TempDir()
+	settingsPath := filepath.Join(tmpDir, "settings.json")
+
+	m, err := LoadFromPath(settingsPath)
+	if err != nil {
+		t.Fatalf("Failed to load settings: %v", err)
+	}
+	m.SetWalkDelayMs(300)
+	m.FastWalk = true
+
+	if err := m.Save(); err != nil {
+		t.Fatalf("Failed to save settings: %v", err)
+	}
+
+	loaded, err := LoadFromPath(settingsPath)
+	if err != nil {
+		t.Fatalf("Failed to reload settings: %v", err)
+	}
+	if loaded.WalkDelayMs != 300 {
+		t.Errorf("Expected walk delay 300, got %d", loaded.WalkDelayMs)
+	}
+	if !loaded.FastWalk {
+		t.Error("Expected fast walk to be persisted")
+	}
+}
+
+func TestLoadMissingFile(t *testing.T) {
+	tmpDir := t.TempDir()
+	m, err := LoadFromPath(filepath.Join(tmpDir, "missing.json"))
+	if err != nil {
+		t.Fatalf("Expected no error for missing file, got %v", err)
+	}
+	if m.WalkDelayMs != 0 || m.FastWalk {
+		t.Error("Expected default settings for missing file")
+	}
+}
+
+func TestAfkCommandDefault(t *testing.T) {
+	m := NewManager()
+	if got := m.GetAfkCommand(); got != DefaultAfkCommand {
+		t.Errorf("Expected default AFK command %q, got %q", DefaultAfkCommand, got)
+	}
+
+	m.AfkCommand = "sit;rest"
+	if got := m.GetAfkCommand(); got != "sit;rest" {
+		t.Errorf("Expected AFK command 'sit;rest', got %q", got)
+	}
+}
+
+func TestSplitRatioDefaultAndClamp(t *testing.T) {
+	m := NewManager()
+	if got := m.GetSplitRatio(); got != DefaultSplitRatio {
+		t.Errorf("Expected default split ratio %d, got %d", DefaultSplitRatio, got)
+	}
+	for set, want := range map[int]int{50: 50, 5: MinSplitRatio, 95: MaxSplitRatio} {
+		m.SplitRatio = set
+		if got := m.GetSplitRatio(); got != want {
+			t.Errorf("Split ratio %d: got %d, want %d", set, got, want)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+// Package stopwatch times runs through a zone or the wait for a respawn,
+// with laps, for /stopwatch.
+package stopwatch
+
+import (
+	"fmt"
+	"time"
+)
+
+// Stopwatch measures the time since it was started, split into laps. The
+// zero value is stopped and ready to start. Times are passed in so callers
+// and tests control the clock.
+type Stopwatch struct {
+	start   time.Time       // When the stopwatch was started
+	end     time.Time       // When it was stopped (zero while running)
+	lapEnds []time.Duration // Total time at the end of each lap
+}
+
+// Running reports whether the stopwatch has been started and not stopped
+func (s *Stopwatch) Running() bool {
+	return !s.start.IsZero() && s.end.IsZero()
+}
+
+// Start starts timing from now, dropping any earlier run and its laps
+func (s *Stopwatch) Start(now time.Time) {
+	*s = Stopwatch{start: now}
+}
+
+// Elapsed returns the time since the stopwatch was started, or the total of
+// the last run once stopped
+func (s *Stopwatch) Elapsed(now time.Time) time.Duration {
+	switch {
+	case s.start.IsZero():
+		return 0
+	case !s.end.IsZero():
+		return s.end.Sub(s.start)
+	}
+	return now.Sub(s.start)
+}
+
+// Lap ends the current lap, returning its time and the total so far. It
+// does nothing unless the stopwatch is running.
+func (s *Stopwatch) Lap(now time.Time) (lap, total time.Duration, ok bool) {
+	if !s.Running() {
+		return 0, 0, false
+	}
+	total = s.Elapsed(now)
+	lap = total - s.lastLapEnd()
+	s.lapEnds = append(s.lapEnds, total)
+	return lap, total, true
+}
+
+// Stop stops the stopwatch, returning the total time and the time since
+// the last lap. It does nothing unless the stopwatch is running.
+func (s *Stopwatch) Stop(now time.Time) (total, lastLap time.Duration, ok bool) {
+	if !s.Running() {
+		return 0, 0, false
+	}
+	total = s.Elapsed(now)
+	s.end = now
+	return total, total - s.lastLapEnd(), true
+}
+
+// Laps returns the time of each lap taken, first lap first
+func (s *Stopwatch) Laps() []time.Duration {
+	laps := make([]time.Duration, len(s.lapEnds))
+	previous := time.Duration(0)
+	for i, end := range s.lapEnds {
+		laps[i] = end - previous
+		previous = end
+	}
+	return laps
+}
+
+// lastLapEnd returns the total time when the last lap ended, or 0
+func (s *Stopwatch) lastLapEnd() time.Duration {
+	if len(s.lapEnds) == 0 {
+		return 0
+	}
+	return s.lapEnds[len(s.lapEnds)-1]
+}
+
+// Format shows a duration as minutes and seconds to a tenth, with hours in
+// front when there are any: 2:05.3 or 1:02:05.3
+func Format(d time.Duration) string {
+	tenths := int64(d.Round(100*time.Millisecond) / (100 * time.Millisecond))
+	hours := tenths / 36000
+	minutes := tenths / 600 % 60
+	seconds := tenths / 10 % 60
+	if hours > 0 {
+		return fmt.Sprintf("%d:%02d:%02d.%d", hours, minutes, seconds, tenths%10)
+	}
+	return fmt.Sprintf("%d:%02d.%d", minutes, seconds, tenths%10)
+}
+
+// FormatShort shows a duration to the whole second, for a display updated
+// once a second: 2:05 or 1:02:05
+func FormatShort(d time.Duration) string {
+	full := Format(d.Truncate(time.Second))
+	return full[:len(full)-2]
+}
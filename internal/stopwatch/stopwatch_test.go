@@ -0,0 +1,75 @@
+package stopwatch
+
+import (
+	"testing"
+	"time"
+)
+
+func TestElapsedAndLaps(t *testing.T) {
+	start := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	var s Stopwatch
+	if s.Running() || s.Elapsed(start) != 0 {
+		t.Fatal("Expected a new stopwatch stopped at zero")
+	}
+	if _, _, ok := s.Lap(start); ok {
+		t.Error("Expected no lap before starting")
+	}
+
+	s.Start(start)
+	if got := s.Elapsed(start.Add(90 * time.Second)); got != 90*time.Second {
+		t.Errorf("Elapsed = %v, want 1m30s", got)
+	}
+
+	lap, total, ok := s.Lap(start.Add(45 * time.Second))
+	if !ok || lap != 45*time.Second || total != 45*time.Second {
+		t.Errorf("First lap = %v of %v (%v), want 45s of 45s", lap, total, ok)
+	}
+	lap, total, _ = s.Lap(start.Add(2 * time.Minute))
+	if lap != 75*time.Second || total != 2*time.Minute {
+		t.Errorf("Second lap = %v of %v, want 1m15s of 2m0s", lap, total)
+	}
+
+	total, last, ok := s.Stop(start.Add(2*time.Minute + 10*time.Second))
+	if !ok || total != 130*time.Second || last != 10*time.Second {
+		t.Errorf("Stop = %v, last lap %v (%v), want 2m10s and 10s", total, last, ok)
+	}
+	if s.Running() || s.Elapsed(start.Add(time.Hour)) != 130*time.Second {
+		t.Error("Expected a stopped stopwatch to keep its total")
+	}
+	if _, _, ok := s.Stop(start.Add(time.Hour)); ok {
+		t.Error("Expected stopping twice to do nothing")
+	}
+
+	laps := s.Laps()
+	if len(laps) != 2 || laps[0] != 45*time.Second || laps[1] != 75*time.Second {
+		t.Errorf("Laps = %v, want [45s 1m15s]", laps)
+	}
+
+	s.Start(start.Add(time.Hour))
+	if !s.Running() || len(s.Laps()) != 0 {
+		t.Error("Expected starting again to clear the laps")
+	}
+}
+
+func TestFormat(t *testing.T) {
+	tests := map[time.Duration]string{
+		0:                                    "0:00.0",
+		4*time.Second + 260*time.Millisecond: "0:04.3",
+		2*time.Minute + 5*time.Second:        "2:05.0",
+		time.Hour + 2*time.Minute + 5*time.Second: "1:02:05.0",
+	}
+	for d, want := range tests {
+		if got := Format(d); got != want {
+			t.Errorf("Format(%v) = %q, want %q", d, got, want)
+		}
+	}
+}
+
+func TestFormatShort(t *testing.T) {
+	if got := FormatShort(2*time.Minute + 5*time.Second + 900*time.Millisecond); got != "2:05" {
+		t.Errorf("FormatShort = %q, want 2:05 without rounding up", got)
+	}
+	if got := FormatShort(time.Hour + 5*time.Second); got != "1:00:05" {
+		t.Errorf("FormatShort = %q, want 1:00:05", got)
+	}
+}
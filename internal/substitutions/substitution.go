@@ -0,0 +1,154 @@
+package substitutions
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Substitution rewrites the start of an outgoing command
+type Substitution struct {
+	Prefix      string `json:"prefix"`      // Text the command must start with (e.g., "'")
+	Replacement string `json:"replacement"` // Text the prefix is replaced by (e.g., "say ")
+}
+
+// Manager manages all outgoing substitutions
+type Manager struct {
+	Substitutions []*Substitution `json:"substitutions"`
+	filePath      string          // Path to substitutions.json (not serialized)
+}
+
+// NewManager creates a new substitution manager
+func NewManager() *Manager {
+	return &Manager{
+		Substitutions: make([]*Substitution, 0),
+	}
+}
+
+// GetSubstitutionsPath returns the path to the substitutions file
+func GetSubstitutionsPath() (string, error) {
+	var configDir string
+
+	// Check for environment variable override
+	if envConfigDir := os.Getenv("DIKUCLIENT_CONFIG_DIR"); envConfigDir != "" {
+		configDir = envConfigDir
+	} else {
+		homeDir, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("failed to get home directory: %w", err)
+		}
+		configDir = filepath.Join(homeDir, ".config", "dikuclient")
+	}
+
+	if err := os.MkdirAll(configDir, 0700); err != nil {
+		return "", fmt.Errorf("failed to create config directory: %w", err)
+	}
+
+	return filepath.Join(configDir, "substitutions.json"), nil
+}
+
+// Load loads substitutions from disk
+func Load() (*Manager, error) {
+	substitutionsPath, err := GetSubstitutionsPath()
+	if err != nil {
+		return nil, err
+	}
+
+	return LoadFromPath(substitutionsPath)
+}
+
+// LoadFromPath loads substitutions from a specific path (useful for testing)
+func LoadFromPath(substitutionsPath string) (*Manager, error) {
+	data, err := os.ReadFile(substitutionsPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			// Return empty manager if file doesn't exist
+			m := NewManager()
+			m.filePath = substitutionsPath
+			return m, nil
+		}
+		return nil, fmt.Errorf("failed to read substitutions file: %w", err)
+	}
+
+	var m Manager
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("failed to parse substitutions file: %w", err)
+	}
+	m.filePath = substitutionsPath
+
+	return &m, nil
+}
+
+// Save saves substitutions to disk
+func (m *Manager) Save() error {
+	substitutionsPath := m.filePath
+	if substitutionsPath == "" {
+		var err error
+		substitutionsPath, err = GetSubstitutionsPath()
+		if err != nil {
+			return err
+		}
+		m.filePath = substitutionsPath
+	}
+
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal substitutions: %w", err)
+	}
+
+	if err := os.WriteFile(substitutionsPath, data, 0600); err != nil {
+		return fmt.Errorf("failed to write substitutions file: %w", err)
+	}
+
+	return nil
+}
+
+// Set adds a substitution, replacing the one for the same prefix if there is one
+func (m *Manager) Set(prefix, replacement string) (*Substitution, error) {
+	if strings.TrimSpace(prefix) == "" {
+		return nil, fmt.Errorf("substitution prefix cannot be empty")
+	}
+
+	for _, sub := range m.Substitutions {
+		if sub.Prefix == prefix {
+			sub.Replacement = replacement
+			return sub, nil
+		}
+	}
+
+	sub := &Substitution{
+		Prefix:      prefix,
+		Replacement: replacement,
+	}
+	m.Substitutions = append(m.Substitutions, sub)
+	return sub, nil
+}
+
+// Remove removes a substitution by index (0-based)
+func (m *Manager) Remove(index int) error {
+	if index < 0 || index >= len(m.Substitutions) {
+		return fmt.Errorf("invalid substitution index: %d", index)
+	}
+
+	m.Substitutions = append(m.Substitutions[:index], m.Substitutions[index+1:]...)
+	return nil
+}
+
+// Apply rewrites the start of command using the substitution with the
+// longest matching prefix. Only one substitution is applied, so a
+// replacement can't trigger another. Returns the command unchanged and false
+// if no prefix matches.
+func (m *Manager) Apply(command string) (string, bool) {
+	var match *Substitution
+	for _, sub := range m.Substitutions {
+		if strings.HasPrefix(command, sub.Prefix) && (match == nil || len(sub.Prefix) > len(match.Prefix)) {
+			match = sub
+		}
+	}
+	if match == nil {
+		return command, false
+	}
+	return match.Replacement + command[len(match.Prefix):], true
+}
@@ -0,0 +1,90 @@
+package substitutions
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestApply(t *testing.T) {
+	m := NewManager()
+	m.Set("'", "say ")
+	m.Set("gt ", "group tell ")
+	m.Set("gtt ", "gtell ")
+
+	tests := []struct {
+		command  string
+		expected string
+		applied  bool
+	}{
+		{"'hello there", "say hello there", true},
+		{"gt ready?", "group tell ready?", true},
+		{"gtt ready?", "gtell ready?", true},
+		{"look", "look", false},
+		{"say 'quoted'", "say 'quoted'", false},
+		{"gtx", "gtx", false},
+		{"", "", false},
+	}
+
+	for _, tt := range tests {
+		got, applied := m.Apply(tt.command)
+		if got != tt.expected || applied != tt.applied {
+			t.Errorf("Apply(%q) = %q, %v; want %q, %v", tt.command, got, applied, tt.expected, tt.applied)
+		}
+	}
+}
+
+func TestApplyUsesLongestPrefix(t *testing.T) {
+	m := NewManager()
+	// Defined shortest first: the longer prefix still wins
+	m.Set("g", "get ")
+	m.Set("gt ", "group tell ")
+
+	if got, _ := m.Apply("gt hi"); got != "group tell hi" {
+		t.Errorf("Expected the longer prefix to win, got %q", got)
+	}
+	if got, _ := m.Apply("gsword"); got != "get sword" {
+		t.Errorf("Expected the shorter prefix for other commands, got %q", got)
+	}
+}
+
+func TestSetReplacesAndRemove(t *testing.T) {
+	m := NewManager()
+
+	if _, err := m.Set("  ", "x"); err == nil {
+		t.Error("Expected an empty prefix to be rejected")
+	}
+
+	m.Set("'", "say ")
+	m.Set("'", "gossip ")
+	if len(m.Substitutions) != 1 || m.Substitutions[0].Replacement != "gossip " {
+		t.Errorf("Expected the replacement to be updated in place, got %+v", m.Substitutions)
+	}
+
+	if err := m.Remove(1); err == nil {
+		t.Error("Expected an invalid index to fail")
+	}
+	if err := m.Remove(0); err != nil || len(m.Substitutions) != 0 {
+		t.Errorf("Expected the substitution to be removed, got %v", err)
+	}
+}
+
+func TestSaveAndLoad(t *testing.T) {
+	substitutionsPath := filepath.Join(t.TempDir(), "substitutions.json")
+
+	m, err := LoadFromPath(substitutionsPath)
+	if err != nil {
+		t.Fatalf("Failed to load missing file: %v", err)
+	}
+	m.Set("'", "say ")
+	if err := m.Save(); err != nil {
+		t.Fatalf("Failed to save substitutions: %v", err)
+	}
+
+	loaded, err := LoadFromPath(substitutionsPath)
+	if err != nil {
+		t.Fatalf("Failed to load substitutions: %v", err)
+	}
+	if got, _ := loaded.Apply("'hi"); got != "say hi" {
+		t.Errorf("Expected the loaded substitution to apply, got %q", got)
+	}
+}
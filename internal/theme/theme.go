@@ -0,0 +1,157 @@
+// Package theme holds the colors of the client's own messages (errors,
+// warnings, notices and listings), so users can change them with /theme
+// and they can be turned off together with /ansi off.
+package theme
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+)
+
+// Theme is the style of each kind of client message
+type Theme struct {
+	Info      lipgloss.Style // Confirmations and headings
+	Warn      lipgloss.Style // Warnings and usage hints
+	Error     lipgloss.Style // Errors
+	Debug     lipgloss.Style // Details, hints and debug output
+	Highlight lipgloss.Style // Items in listings and values
+}
+
+// DefaultColors is the color of each kind of message when not overridden,
+// as ANSI color numbers (0-255)
+var DefaultColors = map[string]string{
+	"info":      "10", // Bright green
+	"warn":      "11", // Bright yellow
+	"error":     "9",  // Bright red
+	"debug":     "8",  // Gray
+	"highlight": "14", // Bright cyan
+}
+
+// hexColor matches a #rgb or #rrggbb color
+var hexColor = regexp.MustCompile(`^#([0-9a-fA-F]{3}|[0-9a-fA-F]{6})$`)
+
+// renderer writes colors exactly as given: messages are added to the
+// output buffer, not written to the terminal, so there is nothing to
+// detect, and ANSI colors 0-15 keep their usual escape codes.
+var renderer = newRenderer(termenv.TrueColor)
+
+// plain is the theme used when colors are off
+var plain = func() *Theme {
+	r := newRenderer(termenv.Ascii)
+	return &Theme{
+		Info:      newStyle(r, ""),
+		Warn:      newStyle(r, ""),
+		Error:     newStyle(r, ""),
+		Debug:     newStyle(r, ""),
+		Highlight: newStyle(r, ""),
+	}
+}()
+
+func newRenderer(profile termenv.Profile) *lipgloss.Renderer {
+	r := lipgloss.NewRenderer(io.Discard)
+	r.SetColorProfile(profile)
+	return r
+}
+
+// newStyle creates a style in the given color. Tabs are kept so
+// messages line up the same with and without a theme.
+func newStyle(r *lipgloss.Renderer, color string) lipgloss.Style {
+	style := r.NewStyle().TabWidth(lipgloss.NoTabConversion)
+	if color != "" {
+		style = style.Foreground(lipgloss.Color(color))
+	}
+	return style
+}
+
+// Names returns the kinds of message that can be colored, sorted
+func Names() []string {
+	names := make([]string, 0, len(DefaultColors))
+	for name := range DefaultColors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ValidName reports whether name is a kind of message that can be colored
+func ValidName(name string) bool {
+	_, ok := DefaultColors[name]
+	return ok
+}
+
+// ParseColor checks a color given as an ANSI color number (0-255) or as
+// #rgb/#rrggbb and returns it in the form lipgloss expects
+func ParseColor(color string) (string, error) {
+	if hexColor.MatchString(color) {
+		return strings.ToLower(color), nil
+	}
+	n, err := strconv.Atoi(color)
+	if err != nil || n < 0 || n > 255 {
+		return "", fmt.Errorf("invalid color '%s' (use 0-255 or #rrggbb)", color)
+	}
+	return strconv.Itoa(n), nil
+}
+
+// New creates a theme from the default colors with the given overrides
+// (kind of message to color). Unknown kinds and invalid colors are ignored.
+func New(overrides map[string]string) *Theme {
+	colors := make(map[string]string, len(DefaultColors))
+	for name, color := range DefaultColors {
+		colors[name] = color
+	}
+	for name, color := range overrides {
+		if !ValidName(name) {
+			continue
+		}
+		if parsed, err := ParseColor(color); err == nil {
+			colors[name] = parsed
+		}
+	}
+
+	return &Theme{
+		Info:      newStyle(renderer, colors["info"]),
+		Warn:      newStyle(renderer, colors["warn"]),
+		Error:     newStyle(renderer, colors["error"]),
+		Debug:     newStyle(renderer, colors["debug"]),
+		Highlight: newStyle(renderer, colors["highlight"]),
+	}
+}
+
+// Plain returns the theme that leaves messages uncolored
+func Plain() *Theme {
+	return plain
+}
+
+// SGR returns the escape sequence a style starts its text with, or "" for
+// a plain style, to color part of a line that has colors of its own
+func SGR(style lipgloss.Style) string {
+	start, _, found := strings.Cut(style.Render("x"), "x")
+	if !found {
+		return ""
+	}
+	return start
+}
+
+// Style returns the style for a kind of message, or nil for an unknown kind
+func (t *Theme) Style(name string) *lipgloss.Style {
+	switch name {
+	case "info":
+		return &t.Info
+	case "warn":
+		return &t.Warn
+	case "error":
+		return &t.Error
+	case "debug":
+		return &t.Debug
+	case "highlight":
+		return &t.Highlight
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+package theme
+
+import "testing"
+
+func TestDefaultColors(t *testing.T) {
+	th := New(nil)
+	tests := []struct {
+		name string
+		got  string
+		want string
+	}{
+		{"info", th.Info.Render("ok"), "\x1b[92mok\x1b[0m"},
+		{"warn", th.Warn.Render("ok"), "\x1b[93mok\x1b[0m"},
+		{"error", th.Error.Render("ok"), "\x1b[91mok\x1b[0m"},
+		{"debug", th.Debug.Render("ok"), "\x1b[90mok\x1b[0m"},
+		{"highlight", th.Highlight.Render("ok"), "\x1b[96mok\x1b[0m"},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("%s: expected %q, got %q", tt.name, tt.want, tt.got)
+		}
+	}
+}
+
+func TestOverrides(t *testing.T) {
+	th := New(map[string]string{"error": "196", "info": "#00FF00", "warn": "nope", "bogus": "1"})
+
+	if got, want := th.Error.Render("x"), "\x1b[38;5;196mx\x1b[0m"; got != want {
+		t.Errorf("Expected error in color 196 (%q), got %q", want, got)
+	}
+	if got, want := th.Info.Render("x"), "\x1b[38;2;0;255;0mx\x1b[0m"; got != want {
+		t.Errorf("Expected info in #00ff00 (%q), got %q", want, got)
+	}
+	if got, want := th.Warn.Render("x"), "\x1b[93mx\x1b[0m"; got != want {
+		t.Errorf("Expected an invalid color to keep the default (%q), got %q", want, got)
+	}
+}
+
+func TestSGR(t *testing.T) {
+	th := New(map[string]string{"error": "#00FF00"})
+	if got := SGR(th.Highlight); got != "\x1b[96m" {
+		t.Errorf("Expected the highlight sequence, got %q", got)
+	}
+	if got := SGR(th.Error); got != "\x1b[38;2;0;255;0m" {
+		t.Errorf("Expected the overridden error sequence, got %q", got)
+	}
+	if got := SGR(Plain().Highlight); got != "" {
+		t.Errorf("Expected no sequence for a plain style, got %q", got)
+	}
+}
+
+func TestPlainKeepsText(t *testing.T) {
+	if got := Plain().Error.Render("a\tb"); got != "a\tb" {
+		t.Errorf("Expected plain text unchanged, got %q", got)
+	}
+	if got := New(nil).Debug.Render("a\tb"); got != "\x1b[90ma\tb\x1b[0m" {
+		t.Errorf("Expected tabs to be kept, got %q", got)
+	}
+}
+
+func TestParseColor(t *testing.T) {
+	valid := map[string]string{"0": "0", "196": "196", "255": "255", "#ABC": "#abc", "#a0b1c2": "#a0b1c2"}
+	for in, want := range valid {
+		got, err := ParseColor(in)
+		if err != nil || got != want {
+			t.Errorf("ParseColor(%q) = %q, %v; expected %q", in, got, err, want)
+		}
+	}
+	for _, in := range []string{"", "256", "-1", "red", "#12", "#1234567"} {
+		if _, err := ParseColor(in); err == nil {
+			t.Errorf("Expected ParseColor(%q) to fail", in)
+		}
+	}
+}
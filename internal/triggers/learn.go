@@ -0,0 +1,116 @@
+package triggers
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/anicolao/dikuclient/internal/ansi"
+)
+
+// Placeholder names Generalize uses for the parts of a line that change
+const (
+	LearnAmount = "amount" // A number, such as the experience or gold received
+	LearnName   = "name"   // A proper noun, such as a player or monster
+)
+
+// sentenceStarters are capitalised words that begin ordinary MUD messages
+// rather than naming someone, so they are kept when they start a sentence
+var sentenceStarters = map[string]bool{
+	"A": true, "An": true, "The": true, "You": true, "Your": true, "Yours": true,
+	"It": true, "Its": true, "He": true, "She": true, "They": true, "We": true,
+	"His": true, "Her": true, "Their": true, "Our": true, "This": true, "That": true,
+	"These": true, "Those": true, "There": true, "Some": true, "What": true, "Who": true,
+	"Suddenly": true, "Nothing": true, "Someone": true, "Something": true,
+}
+
+// learnToken splits a line into numbers, words and everything else
+var learnToken = regexp.MustCompile(`[+-]?\d[\d,]*(?:\.\d+)?|\p{L}+(?:'\p{L}+)*|[^\p{L}\d]+`)
+
+// Generalize turns a line of MUD output into a trigger pattern by replacing
+// the parts likely to change: numbers become <amount> and proper nouns
+// (capitalised words not starting a sentence, or names starting one) become
+// <name>, except in quoted speech. A run of capitalised words is one name, and later placeholders are
+// numbered (<amount2>, <name2>) so each can be used in the action. Color
+// codes are removed; the result has no placeholders when nothing varies.
+func Generalize(line string) string {
+	text := strings.TrimSpace(ansi.Strip(line))
+	tokens := learnToken.FindAllString(text, -1)
+
+	var b strings.Builder
+	counts := make(map[string]int)
+	placeholder := func(name string) string {
+		counts[name]++
+		if counts[name] == 1 {
+			return "<" + name + ">"
+		}
+		return fmt.Sprintf("<%s%d>", name, counts[name])
+	}
+
+	sentenceStart := true
+	quoted := false // Inside speech such as 'Meet me at the inn.', where capitals don't mark names
+	for i := 0; i < len(tokens); i++ {
+		token := tokens[i]
+		switch {
+		case isNumber(token):
+			b.WriteString(placeholder(LearnAmount))
+			sentenceStart = false
+		case isWord(token):
+			if quoted || !isProperNoun(token, sentenceStart) {
+				b.WriteString(token)
+				sentenceStart = false
+				continue
+			}
+			// Swallow the rest of a multi-word name such as "Grand Master Elrond"
+			for i+2 < len(tokens) && tokens[i+1] == " " && isWord(tokens[i+2]) && isCapitalised(tokens[i+2]) {
+				i += 2
+			}
+			b.WriteString(placeholder(LearnName))
+			if strings.HasSuffix(tokens[i], "'s") {
+				// Keep the possessive out of the name: "<name>'s sword"
+				b.WriteString("'s")
+			}
+			sentenceStart = false
+		default:
+			b.WriteString(token)
+			// Apostrophes inside words are part of the word token, so any
+			// here is a quote mark
+			if strings.Count(token, "'")%2 == 1 || strings.Count(token, "\"")%2 == 1 {
+				quoted = !quoted
+			}
+			if strings.ContainsAny(token, ".!?") {
+				sentenceStart = true
+			}
+		}
+	}
+	return b.String()
+}
+
+// isNumber reports whether a token is a number
+func isNumber(token string) bool {
+	return strings.IndexFunc(token, unicode.IsDigit) >= 0
+}
+
+// isWord reports whether a token is a word
+func isWord(token string) bool {
+	r := []rune(token)
+	return len(r) > 0 && unicode.IsLetter(r[0])
+}
+
+// isCapitalised reports whether a word starts with a capital followed by a
+// lower case letter, leaving out "I" and words in capitals such as "HP"
+func isCapitalised(word string) bool {
+	r := []rune(word)
+	return len(r) > 1 && unicode.IsUpper(r[0]) && unicode.IsLower(r[1])
+}
+
+// isProperNoun reports whether a word looks like a name. At the start of a
+// sentence every word is capitalised, so only those that don't usually
+// start a message count.
+func isProperNoun(word string, sentenceStart bool) bool {
+	if !isCapitalised(word) {
+		return false
+	}
+	return !sentenceStart || !sentenceStarters[word]
+}
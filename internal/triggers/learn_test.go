@@ -0,0 +1,42 @@
+package triggers
+
+import "testing"
+
+func TestGeneralize(t *testing.T) {
+	tests := []struct {
+		line string
+		want string
+	}{
+		{"You receive 42 experience.", "You receive <amount> experience."},
+		{"Gandalf tells you 'Meet me at the inn.'", "<name> tells you 'Meet me at the inn.'"},
+		{"The orc hits you hard.", "The orc hits you hard."},
+		{"You get 1,234 gold coins from the corpse of Grand Master Elrond.", "You get <amount> gold coins from the corpse of <name>."},
+		{"Frodo gives you 5 coins and Sam gives you 10.", "<name> gives you <amount> coins and <name2> gives you <amount2>."},
+		{"You are wielding Gandalf's staff.", "You are wielding <name>'s staff."},
+		{"I see HP regen in the AREA.", "I see HP regen in the AREA."},
+		{"\x1b[1;32mYou receive 42 experience.\x1b[0m", "You receive <amount> experience."},
+	}
+
+	for _, tt := range tests {
+		if got := Generalize(tt.line); got != tt.want {
+			t.Errorf("Generalize(%q) = %q, want %q", tt.line, got, tt.want)
+		}
+	}
+}
+
+// TestGeneralizedPatternMatches verifies a learned pattern is a working
+// trigger that matches the same message with other values
+func TestGeneralizedPatternMatches(t *testing.T) {
+	m := NewManager()
+	if _, err := m.Add(Generalize("Gandalf gives you 42 gold coins."), "thank <name>"); err != nil {
+		t.Fatalf("Failed to add the learned trigger: %v", err)
+	}
+
+	actions := m.Match("Bilbo Baggins gives you 7 gold coins.")
+	if len(actions) != 1 || actions[0] != "thank Bilbo.Baggins" {
+		t.Errorf("Expected the learned trigger to thank Bilbo.Baggins, got %v", actions)
+	}
+	if actions := m.Match("You give Gandalf 42 gold coins."); len(actions) != 0 {
+		t.Errorf("Expected no match for a different message, got %v", actions)
+	}
+}
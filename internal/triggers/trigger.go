@@ -6,21 +6,61 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
+	"sort"
 	"strings"
+	"time"
+
+	"github.com/anicolao/dikuclient/internal/ansi"
 )
 
+// MaxLines is the most recent lines a multi-line trigger can match against
+const MaxLines = 30
+
+// ModeGlob marks a trigger whose pattern uses * and ? wildcards. Each * is
+// captured and can be used in the action as <1>, <2>, ...
+const ModeGlob = "glob"
+
+// ModeRegex marks a trigger whose pattern is a regular expression. Its
+// groups can be used in the action as <1>, <2>, ..., and named groups
+// (?P<name>...) as <name> as well.
+const ModeRegex = "regex"
+
+// ActionType says what a trigger does with a line it matches
+type ActionType string
+
+const (
+	ActionCommand   ActionType = "command"   // Send the action to the MUD (the default)
+	ActionGag       ActionType = "gag"       // Hide the line
+	ActionHighlight ActionType = "highlight" // Show the line highlighted
+)
+
+// PanelActionPrefix starts an action of the form panel:<name>:<text>, which
+// shows the text in a named panel instead of sending it to the MUD
+const PanelActionPrefix = "panel:"
+
 // Trigger represents a pattern-action pair
 type Trigger struct {
-	ID      string         `json:"id"`      // Unique identifier
-	Pattern string         `json:"pattern"` // Pattern to match (may contain <variable> placeholders)
-	Action  string         `json:"action"`  // Action to execute (may contain <variable> placeholders)
-	regex   *regexp.Regexp // Compiled regex (not serialized)
+	ID         string         `json:"id"`                    // Unique identifier
+	Pattern    string         `json:"pattern"`               // Pattern to match (may contain <variable> placeholders)
+	Action     string         `json:"action"`                // Action to execute (may contain <variable> placeholders)
+	ActionType ActionType     `json:"action_type,omitempty"` // What the trigger does: ActionCommand ("" too), ActionGag or ActionHighlight
+	Color      string         `json:"color,omitempty"`       // For ActionHighlight, one of ansi.ColorNames to color just the match ("" = the whole line)
+	Mode       string         `json:"mode,omitempty"`        // Pattern syntax: "" for <variable> placeholders, ModeGlob or ModeRegex
+	Cooldown   time.Duration  `json:"cooldown,omitempty"`    // Minimum time between firings (0 = no cooldown)
+	Lines      int            `json:"lines,omitempty"`       // Match against the last N lines joined by spaces (0 or 1 = one line)
+	Group      string         `json:"group,omitempty"`       // Group the trigger belongs to ("" = none)
+	Raw        bool           `json:"raw,omitempty"`         // Match the line with its color codes instead of the plain text
+	Disabled   bool           `json:"disabled,omitempty"`    // Kept but doesn't fire
+	Bell       bool           `json:"bell,omitempty"`        // Ring the bell when the trigger fires
+	regex      *regexp.Regexp // Compiled regex (not serialized)
+	lastFired  time.Time      // When the trigger last fired (not serialized)
 }
 
 // Manager manages all triggers
 type Manager struct {
-	Triggers []*Trigger `json:"triggers"`
-	filePath string     // Path to triggers.json (not serialized)
+	Triggers       []*Trigger `json:"triggers"`
+	DisabledGroups []string   `json:"disabled_groups,omitempty"` // Groups whose triggers don't fire
+	filePath       string     // Path to triggers.json (not serialized)
 }
 
 // NewManager creates a new trigger manager
@@ -117,16 +157,28 @@ func (m *Manager) Save() error {
 
 // Add adds a new trigger
 func (m *Manager) Add(pattern, action string) (*Trigger, error) {
-	// Generate a unique ID
-	id := fmt.Sprintf("trigger_%d", len(m.Triggers)+1)
-	for m.getTriggerByID(id) != nil {
-		id = fmt.Sprintf("trigger_%d_%d", len(m.Triggers)+1, len(m.Triggers))
-	}
+	return m.add(pattern, action, "")
+}
+
+// AddGlob adds a new trigger whose pattern uses * and ? wildcards
+func (m *Manager) AddGlob(pattern, action string) (*Trigger, error) {
+	return m.add(pattern, action, ModeGlob)
+}
 
+// AddRegex adds a new trigger whose pattern is a regular expression. A
+// pattern that doesn't compile is an error rather than a trigger that never
+// fires.
+func (m *Manager) AddRegex(pattern, action string) (*Trigger, error) {
+	return m.add(pattern, action, ModeRegex)
+}
+
+// add adds a new trigger with the given pattern mode
+func (m *Manager) add(pattern, action, mode string) (*Trigger, error) {
 	trigger := &Trigger{
-		ID:      id,
+		ID:      m.newID(),
 		Pattern: pattern,
 		Action:  action,
+		Mode:    mode,
 	}
 
 	if err := trigger.compilePattern(); err != nil {
@@ -137,6 +189,26 @@ func (m *Manager) Add(pattern, action string) (*Trigger, error) {
 	return trigger, nil
 }
 
+// newID generates an ID no trigger has yet
+func (m *Manager) newID() string {
+	id := fmt.Sprintf("trigger_%d", len(m.Triggers)+1)
+	for n := len(m.Triggers); m.getTriggerByID(id) != nil; n++ {
+		id = fmt.Sprintf("trigger_%d_%d", len(m.Triggers)+1, n)
+	}
+	return id
+}
+
+// Replace sets the whole list of triggers, e.g. after editing, giving an ID
+// to each new one. The patterns must already be compiled with SetPattern.
+func (m *Manager) Replace(list []*Trigger) {
+	m.Triggers = list
+	for _, trigger := range list {
+		if trigger.ID == "" {
+			trigger.ID = m.newID()
+		}
+	}
+}
+
 // Remove removes a trigger by index (0-based)
 func (m *Manager) Remove(index int) error {
 	if index < 0 || index >= len(m.Triggers) {
@@ -147,6 +219,17 @@ func (m *Manager) Remove(index int) error {
 	return nil
 }
 
+// SetEnabled turns a trigger on or off by index (0-based) without removing
+// it. A trigger in a disabled group stays off until the group is enabled.
+func (m *Manager) SetEnabled(index int, enabled bool) error {
+	if index < 0 || index >= len(m.Triggers) {
+		return fmt.Errorf("invalid trigger index: %d", index)
+	}
+
+	m.Triggers[index].Disabled = !enabled
+	return nil
+}
+
 // getTriggerByID finds a trigger by its ID
 func (m *Manager) getTriggerByID(id string) *Trigger {
 	for _, trigger := range m.Triggers {
@@ -157,12 +240,75 @@ func (m *Manager) getTriggerByID(id string) *Trigger {
 	return nil
 }
 
-// Match checks if a line matches any trigger and returns the action to execute
+// MatchResult describes a trigger that matched a line
+type MatchResult struct {
+	Index    int               // Index of the trigger (0-based)
+	Trigger  *Trigger          // The trigger that matched
+	Captures map[string]string // Captured values by placeholder name
+	Action   string            // Action with the captures substituted
+}
+
+// Test reports every trigger that matches a line, with its captures and the
+// action it would run, without running anything. The line is given as
+// received; triggers match it without color codes unless they are raw.
+func (m *Manager) Test(line string) []MatchResult {
+	return m.TestLines([]string{line})
+}
+
+// TestLines is Test for the newest of a list of recent lines (oldest first).
+// Multi-line triggers match against their last Lines lines joined by
+// spaces, and only when the match reaches into the newest line, so a match
+// fires once rather than again for every line while it stays in range.
+func (m *Manager) TestLines(lines []string) []MatchResult {
+	results := make([]MatchResult, 0)
+	if len(lines) == 0 {
+		return results
+	}
+
+	var clean []string
+	for i, trigger := range m.Triggers {
+		if !m.Enabled(trigger) {
+			continue
+		}
+		matchLines := lines
+		if !trigger.Raw {
+			if clean == nil {
+				clean = stripLines(lines)
+			}
+			matchLines = clean
+		}
+		text, newest := joinRecent(matchLines, trigger.Lines)
+		varMap, ok := trigger.capturesEndingAfter(text, newest)
+		if !ok {
+			continue
+		}
+		results = append(results, MatchResult{
+			Index:    i,
+			Trigger:  trigger,
+			Captures: keywordCaptures(varMap),
+			Action:   trigger.expand(varMap),
+		})
+	}
+
+	return results
+}
+
+// Match checks if a line matches any trigger and returns the action to
+// execute. Like Test, triggers that aren't raw ignore color codes. Gag and
+// highlight triggers have no action to execute and are left out.
 func (m *Manager) Match(line string) []string {
 	actions := make([]string, 0)
+	clean := ansi.Strip(line)
 
 	for _, trigger := range m.Triggers {
-		if action := trigger.match(line); action != "" {
+		if !m.Enabled(trigger) || trigger.Type() != ActionCommand {
+			continue
+		}
+		text := clean
+		if trigger.Raw {
+			text = line
+		}
+		if action := trigger.match(text); action != "" {
 			actions = append(actions, action)
 		}
 	}
@@ -170,11 +316,192 @@ func (m *Manager) Match(line string) []string {
 	return actions
 }
 
+// LineAction reports how a line should be shown: ActionGag when an enabled
+// gag trigger matches it, ActionHighlight when a highlight trigger does and
+// ActionCommand (shown as received) otherwise. Gags and highlights match a
+// single line, whatever their Lines. Highlights with a Color only color
+// their match, which Colorize does.
+func (m *Manager) LineAction(line string) ActionType {
+	clean := ansi.Strip(line)
+	result := ActionCommand
+	for _, trigger := range m.Triggers {
+		kind := trigger.Type()
+		if kind == ActionCommand || trigger.Color != "" || !m.Enabled(trigger) {
+			continue
+		}
+		text := clean
+		if trigger.Raw {
+			text = line
+		}
+		if _, ok := trigger.captures(text); !ok {
+			continue
+		}
+		if kind == ActionGag {
+			return ActionGag
+		}
+		result = kind
+	}
+	return result
+}
+
+// Colorize wraps the text each enabled highlight trigger with a Color
+// matches in that color, keeping the line's own color codes: after a match
+// the line's colors are restored. Raw triggers color the whole line, as
+// their match includes color codes. Where matches overlap, the trigger
+// listed first wins.
+func (m *Manager) Colorize(line string) string {
+	clean := ansi.Strip(line)
+	for i := len(m.Triggers) - 1; i >= 0; i-- {
+		trigger := m.Triggers[i]
+		if trigger.Type() != ActionHighlight || trigger.Color == "" || !m.Enabled(trigger) || trigger.regex == nil {
+			continue
+		}
+		sgr, ok := ansi.NamedColor(trigger.Color)
+		if !ok {
+			continue
+		}
+		if trigger.Raw {
+			if trigger.regex.MatchString(line) {
+				line = ansi.Highlight(line, 0, len(clean), sgr)
+			}
+			continue
+		}
+		if loc := trigger.regex.FindStringIndex(clean); loc != nil {
+			line = ansi.Highlight(line, loc[0], loc[1], sgr)
+		}
+	}
+	return line
+}
+
+// GroupEnabled reports whether triggers in a group fire. Triggers without a
+// group always do.
+func (m *Manager) GroupEnabled(group string) bool {
+	for _, disabled := range m.DisabledGroups {
+		if disabled == group {
+			return false
+		}
+	}
+	return true
+}
+
+// Enabled reports whether a trigger fires: it isn't disabled itself and its
+// group isn't either
+func (m *Manager) Enabled(trigger *Trigger) bool {
+	return !trigger.Disabled && m.GroupEnabled(trigger.Group)
+}
+
+// SetGroupEnabled enables or disables every trigger in a group
+func (m *Manager) SetGroupEnabled(group string, enabled bool) {
+	if group == "" {
+		return
+	}
+	groups := m.DisabledGroups[:0]
+	for _, disabled := range m.DisabledGroups {
+		if disabled != group {
+			groups = append(groups, disabled)
+		}
+	}
+	if !enabled {
+		groups = append(groups, group)
+		sort.Strings(groups)
+	}
+	m.DisabledGroups = groups
+}
+
+// Groups returns the names of the groups that have triggers, sorted
+func (m *Manager) Groups() []string {
+	seen := make(map[string]bool)
+	var groups []string
+	for _, trigger := range m.Triggers {
+		if trigger.Group != "" && !seen[trigger.Group] {
+			seen[trigger.Group] = true
+			groups = append(groups, trigger.Group)
+		}
+	}
+	sort.Strings(groups)
+	return groups
+}
+
+// stripLines returns the lines without color codes
+func stripLines(lines []string) []string {
+	clean := make([]string, len(lines))
+	for i, line := range lines {
+		clean[i] = ansi.Strip(line)
+	}
+	return clean
+}
+
+// SetPattern changes the trigger's pattern, keeping the old one if the new
+// one doesn't compile
+func (t *Trigger) SetPattern(pattern string) error {
+	old := t.Pattern
+	t.Pattern = pattern
+	if err := t.compilePattern(); err != nil {
+		t.Pattern = old
+		t.compilePattern()
+		return err
+	}
+	return nil
+}
+
+// SetRaw makes the trigger match lines with their color codes, or without
+// them. In a raw pattern \e stands for the escape character that starts a
+// color code.
+func (t *Trigger) SetRaw(raw bool) error {
+	t.Raw = raw
+	return t.compilePattern()
+}
+
+// Type returns what the trigger does with a line it matches, ActionCommand
+// unless it gags or highlights the line
+func (t *Trigger) Type() ActionType {
+	if t.ActionType == "" {
+		return ActionCommand
+	}
+	return t.ActionType
+}
+
+// IsRegex reports whether the trigger's pattern is a regular expression
+func (t *Trigger) IsRegex() bool {
+	return t.Mode == ModeRegex
+}
+
+// CoolingDown reports whether the trigger fired less than its cooldown ago
+func (t *Trigger) CoolingDown(now time.Time) bool {
+	return t.Cooldown > 0 && !t.lastFired.IsZero() && now.Sub(t.lastFired) < t.Cooldown
+}
+
+// MarkFired records that the trigger fired, starting its cooldown
+func (t *Trigger) MarkFired(now time.Time) {
+	t.lastFired = now
+}
+
 // compilePattern compiles the pattern into a regex
 // Converts <variable> placeholders to regex capture groups
 func (t *Trigger) compilePattern() error {
-	// Escape special regex characters except for our placeholders
+	// Escape characters can't be typed, so raw patterns spell them \e
 	pattern := t.Pattern
+	if t.Raw {
+		pattern = strings.ReplaceAll(pattern, `\e`, "\x1b")
+	}
+
+	if t.Mode == ModeRegex {
+		regex, err := regexp.Compile(pattern)
+		if err != nil {
+			return fmt.Errorf("invalid regular expression: %w", err)
+		}
+		t.regex = regex
+		return nil
+	}
+
+	if t.Mode == ModeGlob {
+		regex, err := regexp.Compile(globToRegex(pattern))
+		if err != nil {
+			return err
+		}
+		t.regex = regex
+		return nil
+	}
 
 	// Find all <variable> placeholders
 	placeholderRegex := regexp.MustCompile(`<(\w+)>`)
@@ -200,44 +527,141 @@ func (t *Trigger) compilePattern() error {
 	return nil
 }
 
+// globToRegex converts a glob pattern into an anchored regex. * matches any
+// text and is captured, ? matches a single character, and a backslash makes
+// the next character literal (e.g. \* for an asterisk).
+func globToRegex(pattern string) string {
+	var b strings.Builder
+	b.WriteString("^")
+	runes := []rune(pattern)
+	for i := 0; i < len(runes); i++ {
+		switch runes[i] {
+		case '*':
+			b.WriteString("(.*?)")
+		case '?':
+			b.WriteString(".")
+		case '\\':
+			if i+1 < len(runes) {
+				i++
+			}
+			b.WriteString(regexp.QuoteMeta(string(runes[i])))
+		default:
+			b.WriteString(regexp.QuoteMeta(string(runes[i])))
+		}
+	}
+	b.WriteString("$")
+	return b.String()
+}
+
 // match checks if a line matches this trigger and returns the action with substitutions
 func (t *Trigger) match(line string) string {
-	if t.regex == nil {
+	varMap, ok := t.captures(line)
+	if !ok {
 		return ""
 	}
+	return t.expand(varMap)
+}
 
-	matches := t.regex.FindStringSubmatch(line)
-	if matches == nil {
-		return ""
+// joinRecent joins the last n lines with spaces and returns where the
+// newest line starts in the result
+func joinRecent(lines []string, n int) (string, int) {
+	if n < 1 {
+		n = 1
 	}
+	if n > len(lines) {
+		n = len(lines)
+	}
+	recent := lines[len(lines)-n:]
+	text := strings.Join(recent, " ")
+	return text, len(text) - len(recent[len(recent)-1])
+}
 
-	// matches[0] is the full match, matches[1:] are the capture groups
-	capturedValues := matches[1:]
+// captures matches a line against this trigger and returns the captured
+// values by placeholder name (or group number for glob and regex triggers),
+// as they appear in the line
+func (t *Trigger) captures(line string) (map[string]string, bool) {
+	return t.capturesEndingAfter(line, 0)
+}
 
-	// Find variable names in the pattern
-	placeholderRegex := regexp.MustCompile(`<(\w+)>`)
-	varNames := placeholderRegex.FindAllStringSubmatch(t.Pattern, -1)
+// capturesEndingAfter is captures for a match that must end after offset
+// minEnd in text
+func (t *Trigger) capturesEndingAfter(text string, minEnd int) (map[string]string, bool) {
+	if t.regex == nil {
+		return nil, false
+	}
 
-	if len(varNames) != len(capturedValues) {
-		return ""
+	loc := t.regex.FindStringSubmatchIndex(text)
+	if loc == nil || (minEnd > 0 && loc[1] <= minEnd) {
+		return nil, false
+	}
+
+	// loc[0:2] is the full match, the rest are the capture groups
+	capturedValues := make([]string, 0, len(loc)/2-1)
+	for g := 2; g < len(loc); g += 2 {
+		if loc[g] < 0 {
+			capturedValues = append(capturedValues, "")
+			continue
+		}
+		capturedValues = append(capturedValues, text[loc[g]:loc[g+1]])
 	}
 
 	// Build a map of variable name to captured value
 	varMap := make(map[string]string)
-	for i, varName := range varNames {
-		if i < len(capturedValues) {
-			// Replace spaces with dots in the captured value
-			value := strings.ReplaceAll(capturedValues[i], " ", ".")
-			varMap[varName[1]] = value // varName[1] is the variable name without <>
+	if t.Mode == ModeGlob || t.Mode == ModeRegex {
+		// Wildcard and group captures are numbered in order, and named
+		// regex groups can be used by name too
+		names := t.regex.SubexpNames()
+		for i, value := range capturedValues {
+			varMap[fmt.Sprintf("%d", i+1)] = value
+			if name := names[i+1]; name != "" {
+				varMap[name] = value
+			}
+		}
+	} else {
+		// Find variable names in the pattern
+		placeholderRegex := regexp.MustCompile(`<(\w+)>`)
+		varNames := placeholderRegex.FindAllStringSubmatch(t.Pattern, -1)
+
+		if len(varNames) != len(capturedValues) {
+			return nil, false
+		}
+
+		for i, varName := range varNames {
+			if i < len(capturedValues) {
+				varMap[varName[1]] = capturedValues[i] // varName[1] is the variable name without <>
+			}
 		}
 	}
 
-	// Substitute variables in the action
-	action := t.Action
+	return varMap, true
+}
+
+// keywordCaptures returns the captured values with spaces replaced by dots,
+// so a multi-word name can be used as a MUD keyword (e.g. kill big.orc)
+func keywordCaptures(varMap map[string]string) map[string]string {
+	keywords := make(map[string]string, len(varMap))
 	for varName, value := range varMap {
-		placeholder := fmt.Sprintf("<%s>", varName)
-		action = strings.ReplaceAll(action, placeholder, value)
+		keywords[varName] = strings.ReplaceAll(value, " ", ".")
+	}
+	return keywords
+}
+
+// expand substitutes captured values into the action. Commands get the
+// values as keywords; panel actions get them as they appeared in the line.
+func (t *Trigger) expand(varMap map[string]string) string {
+	keywords := keywordCaptures(varMap)
+	commands := strings.Split(t.Action, ";")
+	for i, command := range commands {
+		values := keywords
+		if strings.HasPrefix(strings.TrimSpace(command), PanelActionPrefix) {
+			values = varMap
+		}
+		for varName, value := range values {
+			placeholder := fmt.Sprintf("<%s>", varName)
+			command = strings.ReplaceAll(command, placeholder, value)
+		}
+		commands[i] = command
 	}
 
-	return action
+	return strings.Join(commands, ";")
 }
@@ -3,7 +3,11 @@ package triggers
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/anicolao/dikuclient/internal/ansi"
 )
 
 func TestTriggerMatching(t *testing.T) {
@@ -206,3 +210,721 @@ func TestPersistence(t *testing.T) {
 		t.Errorf("Loaded trigger with variable did not match correctly")
 	}
 }
+
+func TestGlobTriggerMatching(t *testing.T) {
+	tests := []struct {
+		name     string
+		pattern  string
+		action   string
+		input    string
+		expected string
+	}{
+		{
+			name:     "Wildcards captured in order",
+			pattern:  "You receive * gold*",
+			action:   "say got <1> gold",
+			input:    "You receive 25 gold coins.",
+			expected: "say got 25 gold",
+		},
+		{
+			name:     "Second capture",
+			pattern:  "* tells you '*'",
+			action:   "tell <1> I got '<2>'",
+			input:    "Gandalf tells you 'run away'",
+			expected: "tell Gandalf I got 'run.away'",
+		},
+		{
+			name:     "Glob must match the whole line",
+			pattern:  "You are hungry",
+			action:   "eat bread",
+			input:    "You are hungry and tired",
+			expected: "",
+		},
+		{
+			name:     "Question mark matches one character",
+			pattern:  "Exits: ?",
+			action:   "go",
+			input:    "Exits: N",
+			expected: "go",
+		},
+		{
+			name:     "Question mark doesn't match two characters",
+			pattern:  "Exits: ?",
+			action:   "go",
+			input:    "Exits: NS",
+			expected: "",
+		},
+		{
+			name:     "Escaped asterisk is literal",
+			pattern:  `\*\*\* * \*\*\*`,
+			action:   "say <1>",
+			input:    "*** ALERT ***",
+			expected: "say ALERT",
+		},
+		{
+			name:     "Escaped asterisk doesn't act as a wildcard",
+			pattern:  `Score: 5\*`,
+			action:   "cheer",
+			input:    "Score: 50",
+			expected: "",
+		},
+		{
+			name:     "Regex characters are literal",
+			pattern:  "[Hero:*] (*)",
+			action:   "say <1> <2>",
+			input:    "[Hero:Good] (fighting)",
+			expected: "say Good fighting",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			trigger := &Trigger{
+				ID:      "test",
+				Pattern: tt.pattern,
+				Action:  tt.action,
+				Mode:    ModeGlob,
+			}
+
+			err := trigger.compilePattern()
+			if err != nil {
+				t.Fatalf("Failed to compile pattern: %v", err)
+			}
+
+			result := trigger.match(tt.input)
+			if result != tt.expected {
+				t.Errorf("Expected '%s', got '%s'", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestGlobPersistence(t *testing.T) {
+	triggersPath := filepath.Join(t.TempDir(), "triggers.json")
+
+	manager := NewManager()
+	manager.filePath = triggersPath
+	if _, err := manager.AddGlob("You receive * gold*", "split <1>"); err != nil {
+		t.Fatalf("Failed to add glob trigger: %v", err)
+	}
+	if err := manager.Save(); err != nil {
+		t.Fatalf("Failed to save triggers: %v", err)
+	}
+
+	loadedManager, err := LoadFromPath(triggersPath)
+	if err != nil {
+		t.Fatalf("Failed to load triggers: %v", err)
+	}
+	if loadedManager.Triggers[0].Mode != ModeGlob {
+		t.Errorf("Expected glob mode to be saved, got %q", loadedManager.Triggers[0].Mode)
+	}
+
+	results := loadedManager.Match("You receive 120 gold coins.")
+	if len(results) != 1 || results[0] != "split 120" {
+		t.Errorf("Expected 'split 120', got %v", results)
+	}
+}
+
+func TestManagerTest(t *testing.T) {
+	manager := NewManager()
+	manager.Add("hungry", "eat bread")
+	manager.Add("The <subject> dies", "get <subject>")
+	manager.AddGlob("The * dies", "say bye <1>")
+
+	results := manager.Test("The small goblin dies")
+	if len(results) != 2 {
+		t.Fatalf("Expected 2 matching triggers, got %d", len(results))
+	}
+	if results[0].Index != 1 || results[0].Captures["subject"] != "small.goblin" || results[0].Action != "get small.goblin" {
+		t.Errorf("Unexpected placeholder result %+v", results[0])
+	}
+	if results[1].Index != 2 || results[1].Captures["1"] != "small.goblin" || results[1].Action != "say bye small.goblin" {
+		t.Errorf("Unexpected glob result %+v", results[1])
+	}
+
+	if results := manager.Test("Nothing happens"); len(results) != 0 {
+		t.Errorf("Expected no matches, got %+v", results)
+	}
+}
+
+func TestTriggerCooldown(t *testing.T) {
+	manager := NewManager()
+	trigger, _ := manager.Add("You are bleeding", "cast heal")
+	now := time.Now()
+
+	if trigger.CoolingDown(now) {
+		t.Error("Expected a trigger without a cooldown never to be cooling down")
+	}
+
+	trigger.Cooldown = 10 * time.Second
+	if trigger.CoolingDown(now) {
+		t.Error("Expected a trigger that never fired not to be cooling down")
+	}
+
+	trigger.MarkFired(now)
+	if !trigger.CoolingDown(now.Add(9 * time.Second)) {
+		t.Error("Expected the trigger to be suppressed within its cooldown")
+	}
+	if trigger.CoolingDown(now.Add(10 * time.Second)) {
+		t.Error("Expected the trigger to fire again once the cooldown elapsed")
+	}
+}
+
+func TestCooldownPersistence(t *testing.T) {
+	triggersPath := filepath.Join(t.TempDir(), "triggers.json")
+
+	manager, _ := LoadFromPath(triggersPath)
+	trigger, _ := manager.Add("You are bleeding", "cast heal")
+	trigger.Cooldown = 15 * time.Second
+	if err := manager.Save(); err != nil {
+		t.Fatalf("Failed to save triggers: %v", err)
+	}
+
+	loaded, err := LoadFromPath(triggersPath)
+	if err != nil {
+		t.Fatalf("Failed to load triggers: %v", err)
+	}
+	if loaded.Triggers[0].Cooldown != 15*time.Second {
+		t.Errorf("Expected a 15s cooldown after loading, got %v", loaded.Triggers[0].Cooldown)
+	}
+}
+
+func TestBellPersistence(t *testing.T) {
+	triggersPath := filepath.Join(t.TempDir(), "triggers.json")
+
+	manager, _ := LoadFromPath(triggersPath)
+	trigger, _ := manager.Add("tells you", "")
+	trigger.Bell = true
+	if err := manager.Save(); err != nil {
+		t.Fatalf("Failed to save triggers: %v", err)
+	}
+
+	loaded, err := LoadFromPath(triggersPath)
+	if err != nil {
+		t.Fatalf("Failed to load triggers: %v", err)
+	}
+	if !loaded.Triggers[0].Bell {
+		t.Error("Expected the bell flag to be kept after loading")
+	}
+}
+
+func TestMultiLineTrigger(t *testing.T) {
+	manager := NewManager()
+	hungry, _ := manager.AddGlob("*Time passes.*You are hungry.*", "eat bread")
+	hungry.Lines = 2
+	manager.Add("You are hungry", "say food")
+
+	// The pattern spans the previous and the newest line
+	results := manager.TestLines([]string{"A goblin arrives.", "Time passes.", "You are hungry."})
+	if len(results) != 2 || results[0].Action != "eat bread" || results[1].Action != "say food" {
+		t.Fatalf("Expected both triggers to match, got %+v", results)
+	}
+
+	// Too far apart for a 2-line window
+	results = manager.TestLines([]string{"Time passes.", "A goblin arrives.", "You are hungry."})
+	if len(results) != 1 || results[0].Action != "say food" {
+		t.Errorf("Expected only the single-line trigger, got %+v", results)
+	}
+
+	// Once the match no longer reaches the newest line it doesn't fire again
+	results = manager.TestLines([]string{"Time passes.", "You are hungry.", "A goblin leaves."})
+	if len(results) != 0 {
+		t.Errorf("Expected no matches after the newest line moved on, got %+v", results)
+	}
+
+	// Fewer recent lines than the window is fine
+	if results := manager.TestLines([]string{"Time passes. You are hungry."}); len(results) != 2 {
+		t.Errorf("Expected a short buffer to be matched as is, got %+v", results)
+	}
+	if results := manager.TestLines(nil); len(results) != 0 {
+		t.Errorf("Expected no matches without lines, got %+v", results)
+	}
+}
+
+func TestMultiLineCaptures(t *testing.T) {
+	manager := NewManager()
+	trigger, _ := manager.Add("<who> gives you a scroll. It reads: <text>", "say thanks <who>")
+	trigger.Lines = 3
+
+	results := manager.TestLines([]string{"Gandalf gives you a scroll.", "It reads:", "Beware the bridge"})
+	if len(results) != 1 || results[0].Action != "say thanks Gandalf" || results[0].Captures["text"] != "B" {
+		t.Errorf("Expected captures across lines, got %+v", results)
+	}
+}
+
+func TestGroupEnableDisable(t *testing.T) {
+	triggersPath := filepath.Join(t.TempDir(), "triggers.json")
+	manager, _ := LoadFromPath(triggersPath)
+	arrive, _ := manager.Add("<player> has arrived", "wave <player>")
+	arrive.Group = "explore"
+	leave, _ := manager.Add("<player> leaves", "say bye <player>")
+	leave.Group = "explore"
+	manager.Add("You are hungry", "eat bread")
+
+	manager.SetGroupEnabled("explore", false)
+	if results := manager.Test("Bob has arrived"); len(results) != 0 {
+		t.Errorf("Expected disabled triggers not to match, got %+v", results)
+	}
+	if actions := manager.Match("Bob leaves"); len(actions) != 0 {
+		t.Errorf("Expected disabled triggers not to match, got %v", actions)
+	}
+	if results := manager.Test("You are hungry"); len(results) != 1 {
+		t.Errorf("Expected triggers without a group to keep matching, got %+v", results)
+	}
+
+	if err := manager.Save(); err != nil {
+		t.Fatalf("Failed to save triggers: %v", err)
+	}
+	loaded, err := LoadFromPath(triggersPath)
+	if err != nil {
+		t.Fatalf("Failed to load triggers: %v", err)
+	}
+	if loaded.GroupEnabled("explore") || loaded.Triggers[0].Group != "explore" {
+		t.Errorf("Expected the group and its state to persist, got %v", loaded.DisabledGroups)
+	}
+
+	loaded.SetGroupEnabled("explore", true)
+	if results := loaded.Test("Bob has arrived"); len(results) != 1 || results[0].Action != "wave Bob" {
+		t.Errorf("Expected the trigger to fire again once enabled, got %+v", results)
+	}
+}
+
+func TestPanelActionKeepsSpaces(t *testing.T) {
+	manager := NewManager()
+	manager.AddGlob("Quest: *", "panel:Quest:<1>; say <1>")
+
+	results := manager.Test("Quest: 3 of 10 rats")
+	if len(results) != 1 {
+		t.Fatalf("Expected 1 match, got %d", len(results))
+	}
+	// Panel text reads as it did in the line; commands still get keywords
+	if results[0].Action != "panel:Quest:3 of 10 rats; say 3.of.10.rats" {
+		t.Errorf("Unexpected action %q", results[0].Action)
+	}
+	if results[0].Captures["1"] != "3.of.10.rats" {
+		t.Errorf("Expected keyword captures, got %q", results[0].Captures["1"])
+	}
+}
+
+func TestMatchIgnoresColorsByDefault(t *testing.T) {
+	manager := NewManager()
+	manager.Add("The <mob> dies", "get all corpse")
+	manager.AddGlob("You feel *", "say <1>")
+
+	// The color change lands in the middle of the text
+	line := "The \x1b[1;31morc\x1b[0m dies"
+	if actions := manager.Match(line); len(actions) != 1 || actions[0] != "get all corpse" {
+		t.Errorf("Expected the colored line to match, got %v", actions)
+	}
+	results := manager.Test("\x1b[32mYou feel \x1b[1mbetter.\x1b[0m")
+	if len(results) != 1 || results[0].Action != "say better." {
+		t.Errorf("Expected captures without color codes, got %+v", results)
+	}
+}
+
+func TestRawTriggerMatchesColorCodes(t *testing.T) {
+	manager := NewManager()
+	trigger, _ := manager.AddGlob(`\e[1;31m*\e[0m`, "say Red: <1>")
+	if err := trigger.SetRaw(true); err != nil {
+		t.Fatalf("Failed to make the trigger raw: %v", err)
+	}
+
+	results := manager.Test("\x1b[1;31mThe dragon breathes fire!\x1b[0m")
+	if len(results) != 1 || results[0].Action != "say Red: The.dragon.breathes.fire!" {
+		t.Errorf("Expected the red line to match, got %+v", results)
+	}
+	if results := manager.Test("\x1b[1;32mThe dragon breathes fire!\x1b[0m"); len(results) != 0 {
+		t.Errorf("Expected a green line not to match, got %+v", results)
+	}
+	if results := manager.Test("The dragon breathes fire!"); len(results) != 0 {
+		t.Errorf("Expected a line without colors not to match, got %+v", results)
+	}
+
+	// The raw setting and pattern survive a save and load
+	triggersPath := filepath.Join(t.TempDir(), "triggers.json")
+	manager.filePath = triggersPath
+	if err := manager.Save(); err != nil {
+		t.Fatalf("Failed to save triggers: %v", err)
+	}
+	loaded, err := LoadFromPath(triggersPath)
+	if err != nil {
+		t.Fatalf("Failed to load triggers: %v", err)
+	}
+	if results := loaded.Test("\x1b[1;31mFire!\x1b[0m"); len(results) != 1 {
+		t.Errorf("Expected the loaded raw trigger to match, got %+v", results)
+	}
+}
+
+func TestDisabledTriggerDoesNotFire(t *testing.T) {
+	manager := NewManager()
+	trigger, _ := manager.Add("You are hungry.", "eat bread")
+	trigger.Disabled = true
+
+	if results := manager.Test("You are hungry."); len(results) != 0 {
+		t.Errorf("Expected a disabled trigger not to match, got %+v", results)
+	}
+	if actions := manager.Match("You are hungry."); len(actions) != 0 {
+		t.Errorf("Expected no actions from a disabled trigger, got %v", actions)
+	}
+
+	trigger.Disabled = false
+	if results := manager.Test("You are hungry."); len(results) != 1 {
+		t.Errorf("Expected the trigger to match once enabled, got %+v", results)
+	}
+}
+
+func TestSetPatternRecompiles(t *testing.T) {
+	manager := NewManager()
+	trigger, _ := manager.Add("You are hungry.", "eat bread")
+
+	if err := trigger.SetPattern("<who> is thirsty."); err != nil {
+		t.Fatalf("Failed to set pattern: %v", err)
+	}
+	if results := manager.Test("Bob is thirsty."); len(results) != 1 || results[0].Captures["who"] != "Bob" {
+		t.Errorf("Expected the new pattern to match, got %+v", results)
+	}
+	if results := manager.Test("You are hungry."); len(results) != 0 {
+		t.Errorf("Expected the old pattern not to match, got %+v", results)
+	}
+}
+
+func TestReplaceGivesNewTriggersIDs(t *testing.T) {
+	manager := NewManager()
+	first, _ := manager.Add("You are hungry.", "eat bread")
+
+	added := &Trigger{Action: "drink water"}
+	if err := added.SetPattern("You are thirsty."); err != nil {
+		t.Fatalf("Failed to set pattern: %v", err)
+	}
+	manager.Replace([]*Trigger{added, first})
+
+	if len(manager.Triggers) != 2 || manager.Triggers[1] != first {
+		t.Fatalf("Expected the new order, got %+v", manager.Triggers)
+	}
+	if added.ID == "" || added.ID == first.ID {
+		t.Errorf("Expected a new unique ID, got %q", added.ID)
+	}
+	if results := manager.Test("You are thirsty."); len(results) != 1 || results[0].Index != 0 {
+		t.Errorf("Expected the new trigger to match first, got %+v", results)
+	}
+}
+
+func TestRegexTrigger(t *testing.T) {
+	tests := []struct {
+		name     string
+		pattern  string
+		action   string
+		input    string
+		expected string
+	}{
+		{
+			name:     "anchored group",
+			pattern:  `^(\w+) hits you`,
+			action:   "flee",
+			input:    "Orc hits you very hard.",
+			expected: "flee",
+		},
+		{
+			name:     "anchor stops a match mid-line",
+			pattern:  `^(\w+) hits you`,
+			action:   "flee",
+			input:    "The orc hits you.",
+			expected: "",
+		},
+		{
+			name:     "numbered group",
+			pattern:  `(\d+) gold coins`,
+			action:   "get <1> coins",
+			input:    "There are 42 gold coins here.",
+			expected: "get 42 coins",
+		},
+		{
+			name:     "named group",
+			pattern:  `(?P<mob>.+) is dead! R\.I\.P\.`,
+			action:   "get all corpse;say bye <mob>",
+			input:    "The big orc is dead! R.I.P.",
+			expected: "get all corpse;say bye The.big.orc",
+		},
+		{
+			name:     "angle brackets are literal in a regex",
+			pattern:  `^<(\d+)hp`,
+			action:   "say <1>",
+			input:    "<90hp 10m>",
+			expected: "say 90",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			manager := NewManager()
+			trigger, err := manager.AddRegex(tt.pattern, tt.action)
+			if err != nil {
+				t.Fatalf("Failed to add regex trigger: %v", err)
+			}
+			if !trigger.IsRegex() {
+				t.Errorf("Expected a regex trigger, got mode %q", trigger.Mode)
+			}
+
+			result := trigger.match(tt.input)
+			if result != tt.expected {
+				t.Errorf("Expected '%s', got '%s'", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestRegexTriggerInvalid(t *testing.T) {
+	manager := NewManager()
+	_, err := manager.AddRegex(`(\d+ gold`, "get <1> coins")
+	if err == nil || !strings.Contains(err.Error(), "invalid regular expression") {
+		t.Fatalf("Expected an invalid regular expression error, got %v", err)
+	}
+	if len(manager.Triggers) != 0 {
+		t.Errorf("Expected the invalid trigger not to be added, got %d", len(manager.Triggers))
+	}
+
+	// The same text is fine as a plain pattern
+	if _, err := manager.Add(`(\d+ gold`, "say odd"); err != nil {
+		t.Errorf("Expected a plain pattern to accept the text, got %v", err)
+	}
+}
+
+func TestRegexPersistence(t *testing.T) {
+	triggersPath := filepath.Join(t.TempDir(), "triggers.json")
+
+	manager := NewManager()
+	manager.filePath = triggersPath
+	if _, err := manager.AddRegex(`(\d+) gold coins`, "get <1> coins"); err != nil {
+		t.Fatalf("Failed to add regex trigger: %v", err)
+	}
+	manager.Add("hungry", "eat bread")
+	if err := manager.Save(); err != nil {
+		t.Fatalf("Failed to save triggers: %v", err)
+	}
+
+	data, err := os.ReadFile(triggersPath)
+	if err != nil {
+		t.Fatalf("Failed to read triggers file: %v", err)
+	}
+	if !strings.Contains(string(data), `"mode": "regex"`) {
+		t.Errorf("Expected the regex mode in the file, got %s", data)
+	}
+
+	loadedManager, err := LoadFromPath(triggersPath)
+	if err != nil {
+		t.Fatalf("Failed to load triggers: %v", err)
+	}
+	if !loadedManager.Triggers[0].IsRegex() || loadedManager.Triggers[1].IsRegex() {
+		t.Errorf("Expected only the first trigger to be a regex, got %+v", loadedManager.Triggers)
+	}
+
+	results := loadedManager.Match("You get 120 gold coins.")
+	if len(results) != 1 || results[0] != "get 120 coins" {
+		t.Errorf("Expected 'get 120 coins', got %v", results)
+	}
+
+	// A file edited by hand with a broken regex fails to load
+	if err := os.WriteFile(triggersPath, []byte(`{"triggers": [{"id": "t", "pattern": "(", "action": "x", "mode": "regex"}]}`), 0600); err != nil {
+		t.Fatalf("Failed to write triggers file: %v", err)
+	}
+	if _, err := LoadFromPath(triggersPath); err == nil {
+		t.Error("Expected an invalid regex in the file to fail loading")
+	}
+}
+
+func TestLineAction(t *testing.T) {
+	manager := NewManager()
+	gag, _ := manager.Add("The sun rises", "")
+	gag.ActionType = ActionGag
+	highlight, _ := manager.AddGlob("* enters the game.", "")
+	highlight.ActionType = ActionHighlight
+	manager.Add("The <mob> enters the game.", "kill <mob>")
+
+	tests := []struct {
+		line     string
+		expected ActionType
+	}{
+		{"The sun rises in the east.", ActionGag},
+		{"\x1b[33mThe sun\x1b[0m rises.", ActionGag},
+		{"Bob enters the game.", ActionHighlight},
+		{"The orc arrives.", ActionCommand},
+	}
+	for _, tt := range tests {
+		if got := manager.LineAction(tt.line); got != tt.expected {
+			t.Errorf("LineAction(%q) = %q, expected %q", tt.line, got, tt.expected)
+		}
+	}
+
+	// Only the command trigger has an action to execute
+	if actions := manager.Match("The orc enters the game."); len(actions) != 1 || actions[0] != "kill orc" {
+		t.Errorf("Expected only 'kill orc', got %v", actions)
+	}
+
+	// A gag wins over a highlight, and disabled triggers do nothing
+	gag2, _ := manager.AddGlob("Bob *", "")
+	gag2.ActionType = ActionGag
+	if got := manager.LineAction("Bob enters the game."); got != ActionGag {
+		t.Errorf("Expected the gag to win, got %q", got)
+	}
+	gag2.Disabled = true
+	if got := manager.LineAction("Bob enters the game."); got != ActionHighlight {
+		t.Errorf("Expected the disabled gag to be ignored, got %q", got)
+	}
+}
+
+func TestActionTypePersistence(t *testing.T) {
+	triggersPath := filepath.Join(t.TempDir(), "triggers.json")
+
+	manager := NewManager()
+	manager.filePath = triggersPath
+	gag, _ := manager.Add("The sun rises", "")
+	gag.ActionType = ActionGag
+	manager.Add("hungry", "eat bread")
+	if err := manager.Save(); err != nil {
+		t.Fatalf("Failed to save triggers: %v", err)
+	}
+
+	data, err := os.ReadFile(triggersPath)
+	if err != nil {
+		t.Fatalf("Failed to read triggers file: %v", err)
+	}
+	if strings.Count(string(data), `"action_type"`) != 1 {
+		t.Errorf("Expected only the gag to save an action type, got %s", data)
+	}
+
+	loadedManager, err := LoadFromPath(triggersPath)
+	if err != nil {
+		t.Fatalf("Failed to load triggers: %v", err)
+	}
+	if loadedManager.Triggers[0].Type() != ActionGag || loadedManager.Triggers[1].Type() != ActionCommand {
+		t.Errorf("Expected a gag and a command trigger, got %+v", loadedManager.Triggers)
+	}
+	if got := loadedManager.LineAction("The sun rises in the east."); got != ActionGag {
+		t.Errorf("Expected the loaded gag to hide the line, got %q", got)
+	}
+}
+
+func TestColorize(t *testing.T) {
+	const yellow = "\x1b[33m"
+
+	manager := NewManager()
+	tells, _ := manager.Add("<name> tells you", "")
+	tells.ActionType = ActionHighlight
+	tells.Color = "yellow"
+
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name:     "plain line",
+			input:    "Bob tells you 'hi'",
+			expected: yellow + "Bob tells you" + ansi.Reset + " 'hi'",
+		},
+		{
+			name:     "restores the line's color after the match",
+			input:    "\x1b[32mBob tells you 'hi'\x1b[0m",
+			expected: "\x1b[32m" + yellow + "Bob tells you" + ansi.Reset + "\x1b[32m 'hi'\x1b[0m",
+		},
+		{
+			name:     "reapplies over a color inside the match",
+			input:    "\x1b[1;36mBob\x1b[0m tells you 'hi'",
+			expected: "\x1b[1;36m" + yellow + "Bob\x1b[0m" + yellow + " tells you" + ansi.Reset + " 'hi'",
+		},
+		{
+			name:     "no match",
+			input:    "\x1b[32mBob says 'hi'\x1b[0m",
+			expected: "\x1b[32mBob says 'hi'\x1b[0m",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := manager.Colorize(tt.input)
+			if got != tt.expected {
+				t.Errorf("Colorize() = %q, want %q", got, tt.expected)
+			}
+			if ansi.Strip(got) != ansi.Strip(tt.input) {
+				t.Errorf("Colorize changed the visible text: %q", ansi.Strip(got))
+			}
+		})
+	}
+
+	// A highlight with a color only colors its match, so the line itself
+	// isn't reported as highlighted
+	if got := manager.LineAction("Bob tells you 'hi'"); got != ActionCommand {
+		t.Errorf("Expected a colored highlight to leave the line as it is, got %q", got)
+	}
+
+	// Matches of several triggers are each colored
+	dies, _ := manager.AddGlob("* dies*", "")
+	dies.ActionType = ActionHighlight
+	dies.Color = "bright-red"
+	got := manager.Colorize("Bob tells you the orc dies")
+	expected := "\x1b[91m" + yellow + "Bob tells you" + ansi.Reset + "\x1b[91m the orc dies" + ansi.Reset
+	if got != expected {
+		t.Errorf("Colorize() = %q, want %q", got, expected)
+	}
+
+	// The color is saved with the trigger
+	triggersPath := filepath.Join(t.TempDir(), "triggers.json")
+	manager.filePath = triggersPath
+	if err := manager.Save(); err != nil {
+		t.Fatalf("Failed to save triggers: %v", err)
+	}
+	loadedManager, err := LoadFromPath(triggersPath)
+	if err != nil {
+		t.Fatalf("Failed to load triggers: %v", err)
+	}
+	if loadedManager.Triggers[0].Color != "yellow" || loadedManager.Colorize("Bob tells you 'hi'") != yellow+"Bob tells you"+ansi.Reset+" 'hi'" {
+		t.Errorf("Expected the color to be saved, got %+v", loadedManager.Triggers[0])
+	}
+}
+
+func TestSetEnabled(t *testing.T) {
+	triggersPath := filepath.Join(t.TempDir(), "triggers.json")
+
+	manager := NewManager()
+	manager.filePath = triggersPath
+	manager.Add("hungry", "eat bread")
+	manager.Add("thirsty", "drink water")
+
+	if err := manager.SetEnabled(0, false); err != nil {
+		t.Fatalf("Failed to disable trigger: %v", err)
+	}
+	if err := manager.SetEnabled(2, false); err == nil {
+		t.Error("Expected an error for an invalid index")
+	}
+	if actions := manager.Match("You are hungry and thirsty."); len(actions) != 1 || actions[0] != "drink water" {
+		t.Errorf("Expected the disabled trigger to be skipped, got %v", actions)
+	}
+
+	if err := manager.Save(); err != nil {
+		t.Fatalf("Failed to save triggers: %v", err)
+	}
+	loadedManager, err := LoadFromPath(triggersPath)
+	if err != nil {
+		t.Fatalf("Failed to load triggers: %v", err)
+	}
+	if loadedManager.Enabled(loadedManager.Triggers[0]) || !loadedManager.Enabled(loadedManager.Triggers[1]) {
+		t.Errorf("Expected only the first trigger to load disabled, got %+v", loadedManager.Triggers)
+	}
+
+	loadedManager.SetEnabled(0, true)
+	if actions := loadedManager.Match("You are hungry."); len(actions) != 1 || actions[0] != "eat bread" {
+		t.Errorf("Expected the enabled trigger to fire again, got %v", actions)
+	}
+
+	// Files from before triggers could be turned off load with them on
+	if err := os.WriteFile(triggersPath, []byte(`{"triggers": [{"id": "t", "pattern": "hungry", "action": "eat bread"}]}`), 0600); err != nil {
+		t.Fatalf("Failed to write triggers file: %v", err)
+	}
+	oldManager, err := LoadFromPath(triggersPath)
+	if err != nil {
+		t.Fatalf("Failed to load triggers: %v", err)
+	}
+	if !oldManager.Enabled(oldManager.Triggers[0]) || len(oldManager.Match("You are hungry.")) != 1 {
+		t.Errorf("Expected a trigger without the field to be enabled, got %+v", oldManager.Triggers[0])
+	}
+}
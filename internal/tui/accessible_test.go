@@ -0,0 +1,89 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/anicolao/dikuclient/internal/mapper"
+	"github.com/anicolao/dikuclient/internal/ticktimer"
+)
+
+// newAccessibleTestModel creates a model in screen reader mode
+func newAccessibleTestModel() *Model {
+	m := &Model{
+		output:           []string{},
+		worldMap:         mapper.NewMap(),
+		xpTracking:       make(map[string]*XPStat),
+		tickTimerManager: ticktimer.NewManager(0),
+	}
+	m.SetAccessible(true)
+	return m
+}
+
+func TestAccessibleOutputPrintsNewLinesOnce(t *testing.T) {
+	m := newAccessibleTestModel()
+
+	m.Update(mudMsg("The Temple\nYou are in a temple.\n" + testPrompt + " "))
+	if !m.accessiblePrinting {
+		t.Fatal("Expected new output to be printed")
+	}
+	if m.accessiblePrinted != 2 {
+		t.Errorf("Expected the two lines before the prompt to be printed, got %d", m.accessiblePrinted)
+	}
+
+	// The prompt stays on the managed input line with what's being typed
+	m.currentInput = "loo"
+	if view := m.View(); view != testPrompt+" loo" {
+		t.Errorf("Expected the prompt and input in the view, got %q", view)
+	}
+
+	// More output waits until the first batch has been printed
+	m.Update(mudMsg("\nA goblin arrives.\n" + testPrompt + " "))
+	if m.accessiblePrinted != 2 {
+		t.Errorf("Expected printing to wait for the previous batch, got %d", m.accessiblePrinted)
+	}
+
+	_, cmd := m.Update(accessiblePrintedMsg{})
+	if cmd == nil || !m.accessiblePrinting {
+		t.Fatal("Expected the waiting lines to be printed")
+	}
+	if m.accessiblePrinted != len(m.output)-1 {
+		t.Errorf("Expected everything but the prompt to be printed, got %d of %d", m.accessiblePrinted, len(m.output))
+	}
+
+	// Nothing new: nothing to print
+	_, cmd = m.Update(accessiblePrintedMsg{})
+	if cmd != nil || m.accessiblePrinting {
+		t.Error("Expected nothing more to print")
+	}
+}
+
+func TestTakeAccessibleLines(t *testing.T) {
+	m := newAccessibleTestModel()
+
+	m.output = []string{"one", "two", "prompt> "}
+	if lines := m.takeAccessibleLines(); strings.Join(lines, "|") != "one|two" {
+		t.Errorf("Expected the lines before the prompt, got %q", lines)
+	}
+	if lines := m.takeAccessibleLines(); lines != nil {
+		t.Errorf("Expected no lines to be printed twice, got %q", lines)
+	}
+
+	// The prompt line finishes and more output follows it
+	m.output = append(m.output, "three", "prompt> ")
+	if lines := m.takeAccessibleLines(); strings.Join(lines, "|") != "prompt> |three" {
+		t.Errorf("Expected the finished prompt and the new line, got %q", lines)
+	}
+
+}
+
+func TestAccessibleViewHidesPasswords(t *testing.T) {
+	m := newAccessibleTestModel()
+	m.output = []string{"Password: "}
+	m.currentInput = "secret"
+	m.echoSuppressed = true
+
+	if view := m.View(); view != "Password: ******" {
+		t.Errorf("Expected the password to be masked, got %q", view)
+	}
+}
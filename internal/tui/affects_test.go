@@ -0,0 +1,83 @@
+package tui
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/anicolao/dikuclient/internal/affects"
+	"github.com/anicolao/dikuclient/internal/mapper"
+	"github.com/anicolao/dikuclient/internal/settings"
+	"github.com/charmbracelet/bubbles/viewport"
+)
+
+func TestAffectsListingShownInSidebar(t *testing.T) {
+	m := &Model{
+		output:        []string{},
+		worldMap:      mapper.NewMap(),
+		xpTracking:    make(map[string]*XPStat),
+		tellsViewport: viewport.New(56, 10),
+	}
+
+	m.Update(mudMsg("You are affected by:\narmor (12 hours)\nbless (2 hours)\n101H 132V 1000X> "))
+
+	if m.affectTracker == nil || len(m.affectTracker.Affects) != 2 {
+		t.Fatalf("Expected 2 affects to be tracked, got %+v", m.affectTracker)
+	}
+
+	sidebar := m.renderSidebar(60, 40)
+	if !strings.Contains(sidebar, "Affects") || !strings.Contains(sidebar, "armor") || !strings.Contains(sidebar, "bless") {
+		t.Errorf("Expected Affects panel with armor and bless, got:\n%s", sidebar)
+	}
+
+	// The affects panel shares the tells slot, so the sidebar stays the same height
+	empty := &Model{tellsViewport: viewport.New(56, 10), worldMap: mapper.NewMap()}
+	if got, want := strings.Count(sidebar, "\n"), strings.Count(empty.renderSidebar(60, 40), "\n"); got != want {
+		t.Errorf("Expected sidebar height %d with affects, got %d", want, got)
+	}
+}
+
+func TestAffectExpiryAction(t *testing.T) {
+	settingsManager, err := settings.LoadFromPath(filepath.Join(t.TempDir(), "settings.json"))
+	if err != nil {
+		t.Fatalf("Failed to load settings: %v", err)
+	}
+
+	m := &Model{
+		output:          []string{},
+		settingsManager: settingsManager,
+		affectTracker:   affects.NewTracker(),
+	}
+	m.handleAffectsCommand(`affects expire "cast '<affect>'"`)
+	if settingsManager.AffectAction != "cast '<affect>'" {
+		t.Fatalf("Expected expiry action to be saved, got %q", settingsManager.AffectAction)
+	}
+
+	now := time.Now()
+	m.affectTracker.Affects = []*affects.Affect{
+		{Name: "armor", Hours: 0, Expires: now.Add(30 * time.Second)},
+		{Name: "bless", Hours: 10, Expires: now.Add(time.Hour)},
+		{Name: "haste", Hours: 0, Expires: now.Add(-time.Second)},
+	}
+
+	if cmd := m.checkAffects(); cmd == nil {
+		t.Error("Expected the expiry action to start the command queue")
+	}
+	if len(m.pendingCommands) != 2 || m.pendingCommands[0] != "cast 'armor'" || m.pendingCommands[1] != "cast 'haste'" {
+		t.Errorf("Expected recasts of armor and haste, got %v", m.pendingCommands)
+	}
+	if !strings.Contains(strings.Join(m.output, "\n"), "armor is about to wear off") {
+		t.Errorf("Expected expiry warning, got %v", m.output)
+	}
+	if len(m.affectTracker.Affects) != 2 {
+		t.Errorf("Expected worn off haste to be removed, got %+v", m.affectTracker.Affects)
+	}
+
+	// Warnings are given once
+	m.pendingCommands = nil
+	m.checkAffects()
+	if len(m.pendingCommands) != 0 {
+		t.Errorf("Expected no repeated expiry action, got %v", m.pendingCommands)
+	}
+}
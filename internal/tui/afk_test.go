@@ -0,0 +1,81 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestAfkCommandFiresWhenIdle(t *testing.T) {
+	m, _ := newConnectedTestModel(t)
+	m.handleAfkCommand(`afk 60 "sit;rest"`)
+	if m.settingsManager.AfkSeconds != 60 || m.settingsManager.AfkCommand != "sit;rest" {
+		t.Fatalf("Expected AFK settings to be saved, got %d %q", m.settingsManager.AfkSeconds, m.settingsManager.AfkCommand)
+	}
+
+	// Not idle long enough yet
+	m.lastInputTime = time.Now().Add(-30 * time.Second)
+	m.Update(tickTimerMsg{})
+	if len(m.pendingCommands) != 0 {
+		t.Fatalf("Expected no AFK command before the idle time, got %v", m.pendingCommands)
+	}
+
+	m.lastInputTime = time.Now().Add(-61 * time.Second)
+	m.Update(tickTimerMsg{})
+	if strings.Join(m.pendingCommands, ";") != "sit;rest" {
+		t.Fatalf("Expected AFK commands to be queued, got %v", m.pendingCommands)
+	}
+	if !strings.Contains(strings.Join(m.output, "\n"), "[AFK: idle for") {
+		t.Errorf("Expected AFK notice in output, got %q", m.output)
+	}
+
+	// Sent once per idle stretch
+	m.pendingCommands = nil
+	m.Update(tickTimerMsg{})
+	if len(m.pendingCommands) != 0 {
+		t.Errorf("Expected the AFK command to be sent only once, got %v", m.pendingCommands)
+	}
+}
+
+func TestAfkTimerResetsOnKeystroke(t *testing.T) {
+	m, _ := newConnectedTestModel(t)
+	m.handleAfkCommand("afk 60")
+	m.lastInputTime = time.Now().Add(-2 * time.Minute)
+	m.afkSentFor = m.lastInputTime
+
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("l")})
+	if time.Since(m.lastInputTime) > time.Second {
+		t.Fatalf("Expected keystroke to reset the idle timer, got %v", m.lastInputTime)
+	}
+	m.Update(tickTimerMsg{})
+	if len(m.pendingCommands) != 0 {
+		t.Fatalf("Expected no AFK command right after a keystroke, got %v", m.pendingCommands)
+	}
+
+	// Idle again after coming back fires again, with the default command
+	m.lastInputTime = time.Now().Add(-61 * time.Second)
+	m.Update(tickTimerMsg{})
+	if len(m.pendingCommands) != 1 || m.pendingCommands[0] != "rest" {
+		t.Errorf("Expected default 'rest' to be queued, got %v", m.pendingCommands)
+	}
+
+	m.handleAfkCommand("afk off")
+	m.pendingCommands = nil
+	m.lastInputTime = time.Now().Add(-time.Hour)
+	m.Update(tickTimerMsg{})
+	if len(m.pendingCommands) != 0 {
+		t.Errorf("Expected nothing queued with AFK off, got %v", m.pendingCommands)
+	}
+}
+
+func TestIdleWarningFromMud(t *testing.T) {
+	m, _ := newConnectedTestModel(t)
+	m.xpTracking = make(map[string]*XPStat)
+
+	m.Update(mudMsg("You have been idle, and are pulled into a void.\n"))
+	if !strings.Contains(strings.Join(m.output, "\n"), "about to disconnect you for idling") {
+		t.Errorf("Expected idle warning, got %q", m.output)
+	}
+}
@@ -1,8 +1,11 @@
 package tui
 
 import (
+	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"math/rand"
 	"os"
 	"regexp"
@@ -10,17 +13,41 @@ import (
 	"strings"
 	"time"
 
+	"github.com/anicolao/dikuclient/internal/affects"
+	"github.com/anicolao/dikuclient/internal/ansi"
 	"github.com/anicolao/dikuclient/internal/aliases"
+	"github.com/anicolao/dikuclient/internal/autosave"
+	"github.com/anicolao/dikuclient/internal/captures"
 	"github.com/anicolao/dikuclient/internal/client"
+	"github.com/anicolao/dikuclient/internal/combat"
+	"github.com/anicolao/dikuclient/internal/config"
+	"github.com/anicolao/dikuclient/internal/debuglog"
+	"github.com/anicolao/dikuclient/internal/editor"
 	"github.com/anicolao/dikuclient/internal/history"
+	"github.com/anicolao/dikuclient/internal/items"
+	"github.com/anicolao/dikuclient/internal/jsonlog"
+	"github.com/anicolao/dikuclient/internal/levels"
+	"github.com/anicolao/dikuclient/internal/macros"
 	"github.com/anicolao/dikuclient/internal/mapper"
+	"github.com/anicolao/dikuclient/internal/mutes"
+	"github.com/anicolao/dikuclient/internal/qrcode"
+	"github.com/anicolao/dikuclient/internal/script"
+	"github.com/anicolao/dikuclient/internal/redact"
+	"github.com/anicolao/dikuclient/internal/settings"
+	"github.com/anicolao/dikuclient/internal/stopwatch"
+	"github.com/anicolao/dikuclient/internal/substitutions"
+	"github.com/anicolao/dikuclient/internal/theme"
 	"github.com/anicolao/dikuclient/internal/ticktimer"
 	"github.com/anicolao/dikuclient/internal/triggers"
+	"github.com/anicolao/dikuclient/internal/version"
+	"github.com/anicolao/dikuclient/internal/vitals"
+	"github.com/anicolao/dikuclient/internal/wealth"
 	"github.com/anicolao/dikuclient/internal/xpstats"
 	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/charmbracelet/lipgloss/table"
+	xansi "github.com/charmbracelet/x/ansi"
 )
 
 // Model represents the application state
@@ -39,12 +66,24 @@ type Model struct {
 	err                    error
 	mudLogFile             *os.File
 	tuiLogFile             *os.File
-	telnetDebugLog         *os.File // Debug log for telnet/UTF-8 processing
+	telnetDebugLog         *debuglog.Log // Debug log for telnet/UTF-8 processing (nil when off)
+	mudLog                 *debuglog.Log // Buffered writer for mudLogFile
+	tuiLog                 *debuglog.Log // Buffered writer for tuiLogFile
+	jsonLog                *jsonlog.Logger // Classified MUD output, one JSON object per line (nil when off)
+	outputPipe             io.Writer       // Receives each MUD line without colors (--output-pipe, nil when off)
 	echoSuppressed         bool     // Server has disabled echo (e.g., for passwords)
 	username               string
 	password               string
 	autoLoginState         int                // 0=idle, 1=sent username, 2=sent password
+	loginMenu              []loginMenuStep    // Menus answered after auto-login sends the password
+	loginMenuStep          int                // Next menu in loginMenu to answer
+	offlineCommands        []string           // Commands typed before the connection was up, sent after login
+	reconnectAt            time.Time          // When the reconnect scheduled by the MUD's reboot warning runs (/reconnect-on)
+	reconnecting           bool               // A reconnect is under way, so the tick timer is already running
 	worldMap               *mapper.Map        // World map for navigation
+	mapName                string             // Named map in use (/map switch), "" for the server's own map
+	trail                  *mapper.Trail      // Rooms visited most recently (/trail)
+	odometer               mapper.Odometer    // Moves made and rooms found this session (/stats)
 	recentOutput           []string           // Buffer for recent output to detect rooms
 	pendingMovement        string             // Last movement command sent
 	mapDebug               bool               // Enable mapper debug output
@@ -53,23 +92,51 @@ type Model struct {
 	autoWalkIndex          int                // Current step in auto-walk
 	lastRoomSearch         []*mapper.Room     // Last room search results for disambiguation
 	triggerManager         *triggers.Manager  // Trigger manager
+	triggerEditor          *editor.Model      // Full-screen trigger editor from /edit triggers (nil when closed)
 	aliasManager           *aliases.Manager   // Alias manager
+	macroManager           *macros.Manager    // Function key macro manager
+	substitutionManager    *substitutions.Manager // Rewrites of the start of outgoing commands
+	captureManager         *captures.Manager  // Patterns filling records such as quests (/capture)
+	itemManager            *items.Manager     // Remembered stats of identified items
 	inventory              []string           // Current inventory items
 	inventoryTime          time.Time          // Time when inventory was last updated
+	inventorySentAt        time.Time          // When /inventory auto last sent "inventory"
+	equipment              []mapper.EquipmentSlot // Worn items from the last equipment listing
+	equipmentTime          time.Time          // Time when equipment was last updated
 	inventoryViewport      viewport.Model     // Viewport for scrollable inventory
+	userPanels             map[string][]string // Lines routed to named sidebar panels by trigger panel: actions
+	captureRecords         map[string]*captures.Record // Records filled by /capture this session, by name
 	tells                  []string           // Recent tells received
+	muteList               *mutes.List        // Players whose tells and says are hidden, saved per account (/mute)
+	sightings              map[string]*playerSighting // Where players were last seen, by lower-case name (/whereis)
 	tellsViewport          viewport.Model     // Viewport for scrollable tells
 	skipNextRoomDetection  bool               // Skip next room detection (e.g., after recall teleport)
 	autoWalkTarget         string             // Target room title for auto-walk (for recovery)
 	mapLegend              map[string]int     // Room ID to number mapping for map legend display
 	mapLegendRooms         []*mapper.Room     // Rooms in the current legend (for /go command)
+	roomLines              map[int]string     // Output line index to the room listed on it (/nearby, /legend), for clicking
 	xpTracking             map[string]*XPStat // XP/s tracking per creature (current session)
 	pendingKill            string             // Last kill command target
+	target                 string             // Current combat target, highlighted and substituted for <target> (/target)
+	targetPattern          *regexp.Regexp     // Matches the target's name, compiled when it is set
+	autoFollowLeader       string             // Leader whose group invitations are accepted (/autofollow)
+	autoFollowCommand      string             // Commands sent to accept an invitation (empty = default)
+	following              string             // Leader currently followed, from the MUD's follow messages
+	autoAssistLeader       string             // Tank whose fights are joined (/autoassist)
+	autoAssistCommand      string             // Commands sent to join a fight (empty = default)
+	assisting              bool               // An assist was sent and the fight hasn't ended
+	assistSentAt           time.Time          // When the last assist was sent
+	lastCombatPrompt       time.Time          // When a combat prompt was last seen
+	retryCast              *retryCast         // Cast resent when it fails (/retrycast)
 	killTime               time.Time          // Time when kill command was sent
 	xpViewport             viewport.Model     // Viewport for scrollable XP stats
 	xpStatsManager         *xpstats.Manager   // Persistent XP stats manager
+	xpSaves                *autosave.Scheduler // Limits how often XP stats are written after kills
+	mapSaves               *autosave.Scheduler // Limits how often the map is written while walking
 	webSessionID           string             // Web session ID for sharing (empty if not in web mode)
 	webServerURL           string             // Web server URL for sharing (empty if not in web mode)
+	webViewID              string             // ID for the read-only /share view link (empty if not in web mode)
+	typedPasswords         []string           // Passwords typed at a password prompt, hidden in the logs
 	historyManager         *history.Manager   // Persistent command history manager
 	commandHistory         []string           // Command history for readline-style navigation (in-memory cache)
 	historyIndex           int                // Current position in command history (-1 = not navigating)
@@ -90,11 +157,168 @@ type Model struct {
 	lastRenderedSidebar    string             // Last rendered sidebar (for testing)
 	pendingCommands        []string             // Queue of commands to send (from triggers, aliases, or /go)
 	commandQueueActive     bool                 // Currently processing command queue
+	fastWalkQueue          []string             // Fast-walk steps not yet sent, a batch per tick
+	pendingPaste           []string             // Large multi-line paste waiting for Enter to confirm
+	pendingRepeat          []string             // Commands of a large /repeat waiting for Enter to confirm
 	lastViewportContent    string               // Last content set on viewport (to avoid unnecessary updates)
+	wrapped                wrapCache            // Output lines wrapped to the viewport for the last redraw
 	forceScrollToBottom    bool                 // Force viewport to scroll to bottom on next update
 	tickTimerManager       *ticktimer.Manager   // Tick timer manager
 	lastFiredTickTime      int                  // Last tick time when triggers were fired (to avoid duplicates)
 	lastTriggerAction      string               // Last trigger action string enqueued (to avoid duplicate trigger actions)
+	skipTriggers           bool                 // The next MUD output isn't matched against triggers (/notrig)
+	serverPromptEnd        bool                 // The MUD output being handled ends in a prompt marked by telnet GA or EOR
+	learnPattern           string               // Trigger pattern /learn is previewing ("" = none)
+	learnAction            string               // Action for the trigger /learn is previewing
+	settingsManager        *settings.Manager    // Persistent client settings
+	theme                  *theme.Theme         // Colors of the client's own messages (/theme)
+	walkDelayOverride      time.Duration        // Per-walk step delay from /go -speed (0 = use settings)
+	locationUncertain      bool                 // Position unknown after forced movement (flee, teleport)
+	relocalizePending      bool                 // First room after connecting is matched against the map instead of linked
+	affectTracker          *affects.Tracker     // Spell/skill affects read from the affects listing
+	combatManager          *combat.Manager      // Custom damage message patterns
+	combatLog              *combat.Log          // Damage dealt and taken in the current and last fight
+	wealthTracker          *wealth.Tracker      // Gold picked up and carried over the session (/wealth)
+	vitalsTracker          *vitals.Tracker      // Hit points read from GMCP, MSDP and the prompt (/wimpy)
+	wimpyFled              bool                 // Wimpy fled and waits for the hit points to recover
+	levelLog               *levels.Log          // Levels gained, saved between sessions (/levels)
+	sessionXP              int                  // Experience gained this session (/levels)
+	stopwatch              stopwatch.Stopwatch  // Timer for zone runs and respawns (/stopwatch)
+	sessionXPStart         time.Time            // When the first experience of the session was gained
+	lastInputTime          time.Time            // Time of the last keystroke, for the AFK idle timer
+	afkSentFor             time.Time            // lastInputTime when the AFK command was last sent
+	currentPrompt          string               // Latest stat prompt, shown in the status bar when prompts are hidden
+	syntheticInputLine     bool                 // Last output line is an empty line added for input while prompts are hidden
+	promptLineBreak        bool                 // An input line was started after the last prompt (/promptnewline)
+	blankLineEnd           int                  // Length of output when it last ended in a blank line from the MUD (/collapse)
+	tabs                   outputTabs           // Output sorted into the Game, Chat and System tabs
+	focus                  *regexp.Regexp       // Lines shown normally while focusing, others dimmed (/focus)
+	focusHide              bool                 // Hide lines not matching the focus instead of dimming them
+	variables              map[string]string    // Named variables set with /set and substituted for @name
+	sessions               []*Session           // All sessions once /connect opens a second one (nil = single session)
+	activeSession          int                  // Index of the session shown on screen
+	accessible             bool                 // Print output as scrolling lines for screen readers instead of the full-screen layout
+	startupScript          []script.Step        // Commands queued once connected and logged in (--command-file)
+	replayPath             string               // Recording played back instead of connecting (--replay)
+	replayChunks           []client.RecordedChunk
+	replaySpeed            float64              // Replay speed multiplier (0 = no delays)
+	charset                client.Charset       // Encoding the MUD uses (--charset)
+	lineEnding             client.LineEnding    // What ends each command sent (--line-ending)
+	accessiblePrinted      int                  // Lines of output already printed in accessible mode
+	accessiblePrinting     bool                 // A batch of lines is on its way to the terminal
+	copyMode               bool                 // Ctrl+Space: dragging the mouse selects output text and y copies it
+	selecting              bool                 // The mouse button is held down in copy mode
+	hasSelection           bool                 // A region of output has been selected in copy mode
+	selectionStart         cellPos              // Where the selection drag started
+	selectionEnd           cellPos              // Where the selection drag is now
+	clipboard              io.Writer            // Where copied text is sent as OSC 52 (nil = standard output)
+	bell                   io.Writer            // Where the terminal bell is rung (nil = standard output)
+	lastBell               time.Time            // When a trigger last rang the bell
+}
+
+// cellPos is a position in the main viewport's content: a content line and
+// a column, counted in characters without colors
+type cellPos struct {
+	line, col int
+}
+
+// Session holds the per-connection state of one MUD session. The active
+// session's state lives in the Model's own fields; the others are parked
+// here until Ctrl+Tab switches to them.
+type Session struct {
+	conn                   *client.Connection
+	connected              bool
+	host                   string
+	port                   int
+	err                    error
+	output                 []string
+	currentInput           string
+	cursorPos              int
+	echoSuppressed         bool
+	username               string
+	password               string
+	autoLoginState         int
+	loginMenu              []loginMenuStep
+	loginMenuStep          int
+	offlineCommands        []string
+	reconnectAt            time.Time
+	reconnecting           bool
+	worldMap               *mapper.Map
+	mapName                string
+	trail                  *mapper.Trail
+	odometer               mapper.Odometer
+	recentOutput           []string
+	pendingMovement        string
+	autoWalking            bool
+	autoWalkPath           []string
+	autoWalkIndex          int
+	autoWalkTarget         string
+	walkDelayOverride      time.Duration
+	lastRoomSearch         []*mapper.Room
+	skipNextRoomDetection  bool
+	locationUncertain      bool
+	relocalizePending      bool
+	mapLegend              map[string]int
+	mapLegendRooms         []*mapper.Room
+	roomLines              map[int]string
+	inventory              []string
+	inventoryTime          time.Time
+	inventorySentAt        time.Time
+	equipment              []mapper.EquipmentSlot
+	equipmentTime          time.Time
+	userPanels             map[string][]string
+	captureRecords         map[string]*captures.Record
+	tells                  []string
+	muteList               *mutes.List
+	sightings              map[string]*playerSighting
+	xpTracking             map[string]*XPStat
+	pendingKill            string
+	target                 string
+	autoFollowLeader       string
+	autoFollowCommand      string
+	following              string
+	autoAssistLeader       string
+	autoAssistCommand      string
+	retryCast              *retryCast
+	killTime               time.Time
+	currentRoomDescription string
+	hasDescriptionSplit    bool
+	currentBarsoomTitle    string
+	currentBarsoomExits    []string
+	barsoomMode            bool
+	pendingCommands        []string
+	commandQueueActive     bool
+	fastWalkQueue          []string
+	lastTriggerAction      string
+	skipTriggers           bool
+	tabs                   outputTabs
+	tickTimerManager       *ticktimer.Manager
+	lastFiredTickTime      int
+	currentPrompt          string
+	syntheticInputLine     bool
+	promptLineBreak        bool
+	blankLineEnd           int
+	focus                  *regexp.Regexp
+	focusHide              bool
+	affectTracker          *affects.Tracker
+	combatLog              *combat.Log
+	wealthTracker          *wealth.Tracker
+	vitalsTracker          *vitals.Tracker
+	wimpyFled              bool
+	sessionXP              int
+	sessionXPStart         time.Time
+	mapSaves               *autosave.Scheduler
+	afkSentFor             time.Time
+	activity               bool // New output arrived while in the background
+	accessiblePrinted      int
+}
+
+// playerSighting is where and when a player was last seen
+type playerSighting struct {
+	Name      string    // Player name as the MUD showed it
+	RoomID    string    // Current room when seen ("" if not mapped)
+	RoomTitle string    // Title of that room, kept in case the room is merged away
+	Time      time.Time // When the player was seen
 }
 
 // XPStat represents XP per second statistics for a creature
@@ -120,23 +344,61 @@ var (
 			BorderForeground(lipgloss.Color("62")).
 			Padding(1)
 
+	inactiveTabStyle = lipgloss.NewStyle().
+				Foreground(lipgloss.Color("250")).
+				Background(lipgloss.Color("237")).
+				Padding(0, 1)
+
+	promptStatusStyle = lipgloss.NewStyle().
+				Foreground(lipgloss.Color("229")).
+				Padding(0, 1)
+
 	emptyPanelStyle = lipgloss.NewStyle().
 			Foreground(lipgloss.Color("241")).
 			Italic(true)
 )
 
 type mudMsg string
+type mudPromptMsg string // MUD output ending in a prompt the server marked with GA or EOR
 type errMsg error
 type echoStateMsg bool // true if echo suppressed (password mode)
+type subnegotiationMsg client.Subnegotiation // MSDP data from the server
+type gmcpMsg client.GMCPMessage               // A GMCP message from the server
 type autoWalkTickMsg struct{}
+type fastWalkTickMsg struct{}
 type commandQueueTickMsg struct{}
 type tickTimerMsg struct{}
 
+// reconnectMsg runs the reconnect scheduled for the given time, unless it
+// has since been cancelled or replaced
+type reconnectMsg struct {
+	at time.Time
+}
+
+// sessionMsg tags a message with the index of the session it belongs to.
+// Messages for the first session are left untagged.
+type sessionMsg struct {
+	session int
+	msg     tea.Msg
+}
+
+// switchSessionMsg makes the session with this index the active one
+type switchSessionMsg int
+
 // NewModel creates a new application model
 func NewModel(host string, port int, mudLogFile, tuiLogFile *os.File) Model {
 	return NewModelWithAuth(host, port, "", "", mudLogFile, tuiLogFile, nil, false)
 }
 
+// newBufferedLog wraps a log file so it is written in blocks and flushed
+// every second rather than synced after every line (nil when not logging)
+func newBufferedLog(file *os.File) *debuglog.Log {
+	if file == nil {
+		return nil
+	}
+	return debuglog.New(file, 0, debuglog.DefaultInterval)
+}
+
 // NewModelWithAuth creates a new application model with authentication credentials
 func NewModelWithAuth(host string, port int, username, password string, mudLogFile, tuiLogFile, telnetDebugLog *os.File, mapDebug bool) Model {
 	vp := viewport.New(0, 0)
@@ -163,6 +425,34 @@ func NewModelWithAuth(host string, port int, username, password string, mudLogFi
 		aliasManager = aliases.NewManager()
 	}
 
+	// Load or create macro manager
+	macroManager, err := macros.Load()
+	if err != nil {
+		// If we can't load macros, create a new manager
+		macroManager = macros.NewManager()
+	}
+
+	// Load or create substitution manager
+	substitutionManager, err := substitutions.Load()
+	if err != nil {
+		// If we can't load substitutions, create a new manager
+		substitutionManager = substitutions.NewManager()
+	}
+
+	// Load or create capture manager
+	captureManager, err := captures.Load()
+	if err != nil {
+		// If we can't load captures, create a new manager
+		captureManager = captures.NewManager()
+	}
+
+	// Load or create item database
+	itemManager, err := items.Load()
+	if err != nil {
+		// If we can't load items, create a new manager
+		itemManager = items.NewManager()
+	}
+
 	// Load or create XP stats manager
 	xpStatsManager, err := xpstats.Load()
 	if err != nil {
@@ -184,6 +474,20 @@ func NewModelWithAuth(host string, port int, username, password string, mudLogFi
 		tickTimerManager = ticktimer.NewManager(0)
 	}
 
+	// Load or create settings manager
+	settingsManager, err := settings.Load()
+	if err != nil {
+		// If we can't load settings, use defaults
+		settingsManager = settings.NewManager()
+	}
+
+	// Load or create combat pattern manager
+	combatManager, err := combat.Load()
+	if err != nil {
+		// If we can't load combat patterns, use the built-in ones only
+		combatManager = combat.NewManager()
+	}
+
 	inventoryVp := viewport.New(0, 0)
 	tellsVp := viewport.New(0, 0)
 	xpVp := viewport.New(0, 0)
@@ -192,8 +496,9 @@ func NewModelWithAuth(host string, port int, username, password string, mudLogFi
 	// Read web session information from environment variables
 	webSessionID := os.Getenv("DIKUCLIENT_WEB_SESSION_ID")
 	webServerURL := os.Getenv("DIKUCLIENT_WEB_SERVER_URL")
+	webViewID := os.Getenv("DIKUCLIENT_WEB_VIEW_ID")
 
-	return Model{
+	m := Model{
 		viewport:             vp,
 		output:               []string{},
 		currentInput:         "",
@@ -203,7 +508,9 @@ func NewModelWithAuth(host string, port int, username, password string, mudLogFi
 		sidebarWidth:         60, // Doubled from 30 to 60
 		mudLogFile:           mudLogFile,
 		tuiLogFile:           tuiLogFile,
-		telnetDebugLog:       telnetDebugLog,
+		telnetDebugLog:       newBufferedLog(telnetDebugLog),
+		mudLog:               newBufferedLog(mudLogFile),
+		tuiLog:               newBufferedLog(tuiLogFile),
 		username:             username,
 		password:             password,
 		autoLoginState:       0,
@@ -212,6 +519,10 @@ func NewModelWithAuth(host string, port int, username, password string, mudLogFi
 		mapDebug:             mapDebug,
 		triggerManager:       triggerManager,
 		aliasManager:         aliasManager,
+		macroManager:         macroManager,
+		itemManager:          itemManager,
+		substitutionManager:  substitutionManager,
+		captureManager:       captureManager,
 		inventoryViewport:    inventoryVp,
 		tellsViewport:        tellsVp,
 		xpTracking:           make(map[string]*XPStat),
@@ -219,6 +530,7 @@ func NewModelWithAuth(host string, port int, username, password string, mudLogFi
 		xpStatsManager:       xpStatsManager,
 		webSessionID:         webSessionID,
 		webServerURL:         webServerURL,
+		webViewID:            webViewID,
 		historyManager:       historyManager,
 		commandHistory:       historyManager.GetCommands(),
 		historyIndex:         -1,
@@ -232,7 +544,12 @@ func NewModelWithAuth(host string, port int, username, password string, mudLogFi
 		barsoomMode:          worldMap.BarsoomMode, // Load Barsoom mode from map
 		tickTimerManager:     tickTimerManager,
 		lastFiredTickTime:    0,
+		settingsManager:      settingsManager,
+		combatManager:        combatManager,
+		lastInputTime:        time.Now(),
 	}
+	m.applyLogRedaction()
+	return m
 }
 
 // Init initializes the application
@@ -244,12 +561,16 @@ func (m *Model) Init() tea.Cmd {
 func (m *Model) connect() tea.Msg {
 	if m.webSessionID != "" {
 	}
-	conn, err := client.NewConnectionWithDebug(m.host, m.port, m.telnetDebugLog)
+	if m.replayPath != "" {
+		return client.NewReplayConnection(m.replayChunks, m.replaySpeed, m.charset)
+	}
+	conn, err := client.NewConnectionWithCharset(m.host, m.port, m.telnetDebugLog, m.charset)
 	if err != nil {
 		if m.webSessionID != "" {
 		}
 		return errMsg(err)
 	}
+	conn.SetLineEnding(m.lineEnding)
 	if m.webSessionID != "" {
 	}
 	return conn
@@ -257,6 +578,55 @@ func (m *Model) connect() tea.Msg {
 
 // Update handles messages and updates the model
 func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if !m.accessible {
+		return m.route(msg)
+	}
+
+	if _, ok := msg.(accessiblePrintedMsg); ok {
+		m.accessiblePrinting = false
+		return m, m.printAccessibleOutput()
+	}
+	_, cmd := m.route(msg)
+	return m, tea.Batch(cmd, m.printAccessibleOutput())
+}
+
+// route passes a message to the session it belongs to
+func (m *Model) route(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if len(m.sessions) == 0 {
+		return m.update(msg)
+	}
+
+	if index, ok := msg.(switchSessionMsg); ok {
+		m.switchSession(int(index))
+		return m, nil
+	}
+
+	// Ctrl+Tab cycles to the next session
+	if isCtrlTab(msg) {
+		m.switchSession((m.activeSession + 1) % len(m.sessions))
+		return m, nil
+	}
+
+	// Route connection and timer messages to the session they belong to;
+	// everything else (keys, resizes, mouse) goes to the active session
+	index := m.activeSession
+	switch inner := msg.(type) {
+	case sessionMsg:
+		index = inner.session
+		msg = inner.msg
+	case mudMsg, mudPromptMsg, errMsg, echoStateMsg, subnegotiationMsg, gmcpMsg, *client.Connection, autoWalkTickMsg, fastWalkTickMsg, commandQueueTickMsg, tickTimerMsg, reconnectMsg:
+		index = 0
+	}
+
+	if index == m.activeSession {
+		_, cmd := m.update(msg)
+		return m, tagSessionCmd(index, cmd)
+	}
+	return m, m.updateBackgroundSession(index, msg)
+}
+
+// update handles messages for the active session
+func (m *Model) update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	var (
 		cmd  tea.Cmd
 		cmds []tea.Cmd
@@ -264,16 +634,66 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
+		// Any keystroke means the player is back
+		m.lastInputTime = time.Now()
+
+		// The trigger editor takes every key until it is closed
+		if m.triggerEditor != nil {
+			m.handleTriggerEditorKey(msg)
+			return m, nil
+		}
+
+		// Copy mode takes every key until it is left
+		if m.copyMode {
+			m.handleCopyModeKey(msg)
+			return m, nil
+		}
+		if msg.Type == tea.KeyCtrlAt {
+			m.copyMode = true
+			m.hasSelection = false
+			return m, nil
+		}
+
+		// A large paste waits for Enter before anything is sent
+		if m.pendingPaste != nil {
+			return m, m.confirmPaste(msg)
+		}
+		if m.pendingRepeat != nil {
+			return m, m.confirmRepeat(msg)
+		}
+
 		// Handle history search mode separately
 		if m.historySearchMode {
 			return m.handleHistorySearchKey(msg)
 		}
 
+		// Macro keys fire even while typing, since they never produce text
+		if cmd, ok := m.handleMacroKey(msg.String()); ok {
+			return m, cmd
+		}
+
+		// Ctrl or Alt with 1, 2 or 3 switches the output tab
+		if tab, ok := tabKeys[msg.String()]; ok {
+			m.switchTab(tab)
+			return m, nil
+		}
+
+		// In numpad walk mode, movement keys walk when the input line is empty
+		if direction := m.numpadWalkDirection(msg); direction != "" {
+			m.sendMovement(direction)
+			return m, nil
+		}
+
 		switch msg.Type {
 		case tea.KeyCtrlC, tea.KeyEsc:
 			if m.conn != nil {
 				m.conn.Close()
 			}
+			for i, session := range m.sessions {
+				if i != m.activeSession && session.conn != nil {
+					session.conn.Close()
+				}
+			}
 			return m, tea.Quit
 
 		case tea.KeyCtrlR:
@@ -303,8 +723,9 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			if m.conn != nil && m.connected {
 				command := m.currentInput
 
-				// Add non-empty command to history (unless it's a password prompt)
-				if command != "" && !m.isPasswordPrompt() {
+				// Add non-empty command to history (unless it's a password
+				// prompt or the server has hidden the input)
+				if command != "" && !m.echoSuppressed && !m.isPasswordPrompt() {
 					// Don't add duplicate consecutive commands
 					if len(m.commandHistory) == 0 || m.commandHistory[len(m.commandHistory)-1] != command {
 						m.commandHistory = append(m.commandHistory, command)
@@ -327,6 +748,19 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 						go m.savePasswordForWebClient(command)
 					}
 				}
+				if command != "" && (m.echoSuppressed || m.isPasswordPrompt()) {
+					m.notePassword(command)
+				}
+
+				// An alias that expands to a single client command runs like a typed one
+				if !strings.HasPrefix(command, "/") {
+					if expanded, ok := m.aliasManager.Expand(command); ok {
+						expanded = strings.TrimSpace(expanded)
+						if strings.HasPrefix(expanded, "/") && !strings.Contains(expanded, ";") {
+							command = expanded
+						}
+					}
+				}
 
 				// Check if this is a client command (starts with /)
 				if strings.HasPrefix(command, "/") {
@@ -335,7 +769,7 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					if len(m.output) > 0 {
 						savedPrompt = m.output[len(m.output)-1]
 						// Replace the prompt line with the command
-						m.output[len(m.output)-1] = savedPrompt + "\x1b[93m" + command + "\x1b[0m"
+						m.output[len(m.output)-1] = savedPrompt + m.echoed(command)
 					}
 
 					clientCmd := m.handleClientCommand(command)
@@ -374,7 +808,7 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					// Show original command in output
 					if !m.echoSuppressed && !m.isPasswordPrompt() && command != "" {
 						if len(m.output) > 0 {
-							m.output[len(m.output)-1] = m.output[len(m.output)-1] + "\x1b[93m" + command + "\x1b[0m"
+							m.output[len(m.output)-1] = m.output[len(m.output)-1] + m.echoed(command)
 						}
 					}
 					// Reset input
@@ -388,10 +822,15 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				if len(nonEmptyCommands) == 1 {
 					command = nonEmptyCommands[0]
 				}
+				if !m.echoSuppressed && !m.isPasswordPrompt() {
+					command = m.substituteOutgoing(command)
+				}
+				command = m.substituteVariables(command)
 
 				// Check if this is a movement command
-				if movement := mapper.DetectMovement(command); movement != "" {
+				if movement := m.movementFor(command); movement != "" {
 					m.pendingMovement = movement
+					m.odometer.Move()
 					// Clear map legend on movement
 					m.mapLegend = nil
 					m.mapLegendRooms = nil
@@ -408,7 +847,7 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					// This preserves it even when new output arrives
 					if len(m.output) > 0 {
 						// Modify the last line to include the command
-						m.output[len(m.output)-1] = m.output[len(m.output)-1] + "\x1b[93m" + command + "\x1b[0m"
+						m.output[len(m.output)-1] = m.output[len(m.output)-1] + m.echoed(command)
 					}
 				} else if (m.echoSuppressed || m.isPasswordPrompt()) && command != "" {
 					// For password input, show obfuscated bullets with random length
@@ -430,6 +869,22 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.cursorPos = 0
 				// Update display immediately
 				m.updateViewport()
+			} else if m.conn == nil && m.err == nil && m.currentInput != "" && !strings.HasPrefix(m.currentInput, "/") {
+				// Still connecting: hold the command until logged in
+				m.bufferOfflineCommand(m.currentInput)
+				m.currentInput = ""
+				m.cursorPos = 0
+				m.updateViewport()
+			} else if strings.HasPrefix(m.currentInput, "/") {
+				// A closed session still runs client commands so you can switch
+				// away or /reconnect
+				command := m.currentInput
+				m.output = append(m.output, m.echoed(command))
+				clientCmd := m.handleClientCommand(command)
+				m.currentInput = ""
+				m.cursorPos = 0
+				m.updateViewport()
+				return m, clientCmd
 			}
 			return m, nil
 
@@ -477,7 +932,8 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m, nil
 
 		case tea.KeyUp:
-			// Navigate backward through command history
+			// Navigate backward through command history. Text typed before
+			// navigating only recalls commands starting with it.
 			if len(m.commandHistory) > 0 {
 				// If not currently navigating history, save the current input
 				if m.historyIndex == -1 {
@@ -485,12 +941,15 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					m.historyIndex = len(m.commandHistory)
 				}
 
-				// Move to previous command in history
-				if m.historyIndex > 0 {
-					m.historyIndex--
-					m.currentInput = m.commandHistory[m.historyIndex]
-					m.cursorPos = len(m.currentInput)
-					m.updateViewport()
+				// Move to previous matching command in history
+				for i := m.historyIndex - 1; i >= 0; i-- {
+					if strings.HasPrefix(m.commandHistory[i], m.historySavedInput) {
+						m.historyIndex = i
+						m.currentInput = m.commandHistory[i]
+						m.cursorPos = len(m.currentInput)
+						m.updateViewport()
+						break
+					}
 				}
 			}
 			return m, nil
@@ -499,6 +958,9 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			// Navigate forward through command history
 			if m.historyIndex != -1 {
 				m.historyIndex++
+				for m.historyIndex < len(m.commandHistory) && !strings.HasPrefix(m.commandHistory[m.historyIndex], m.historySavedInput) {
+					m.historyIndex++
+				}
 
 				// If we've gone past the end of history, restore saved input
 				if m.historyIndex >= len(m.commandHistory) {
@@ -521,6 +983,11 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.historyIndex = -1
 				m.historySavedInput = ""
 
+				// Multi-line pastes become one queued command per line
+				if text := string(msg.Runes); strings.ContainsAny(text, "\r\n") {
+					return m, m.handlePaste(text)
+				}
+
 				// Insert character at cursor position
 				m.currentInput = m.currentInput[:m.cursorPos] + string(msg.Runes) + m.currentInput[m.cursorPos:]
 				m.cursorPos += len(msg.Runes)
@@ -532,6 +999,10 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case tea.WindowSizeMsg:
 		m.width = msg.Width
 		m.height = msg.Height
+		m.wrapped.reset()
+		if m.triggerEditor != nil {
+			m.triggerEditor.SetSize(msg.Width, msg.Height)
+		}
 
 		headerHeight := 5
 		sidebarWidth := m.sidebarWidth
@@ -566,6 +1037,22 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, nil
 
 	case tea.MouseMsg:
+		// In copy mode the left button selects text instead
+		if m.copyMode && msg.Button == tea.MouseButtonLeft {
+			m.handleCopyModeMouse(msg)
+			m.updateViewport()
+			return m, nil
+		}
+
+		// Clicking a room in a /nearby or /legend listing walks there
+		if msg.Action == tea.MouseActionPress && msg.Button == tea.MouseButtonLeft {
+			if room := m.roomAtPosition(msg.X, msg.Y); room != nil {
+				cmd := m.handleRoomClick(room)
+				m.updateViewport()
+				return m, cmd
+			}
+		}
+
 		// Handle mouse wheel scrolling on main viewport
 		if msg.Action == tea.MouseActionPress {
 			if msg.Button == tea.MouseButtonWheelUp {
@@ -583,18 +1070,53 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case *client.Connection:
 		m.conn = msg
 		m.connected = true
-		m.output = append(m.output, fmt.Sprintf("Connected to %s:%d", m.host, m.port))
+		// Connecting by hand first cancels a scheduled reconnect
+		m.reconnectAt = time.Time{}
+		reconnected := m.reconnecting
+		m.reconnecting = false
+		// The saved position may not be where the MUD puts us after login
+		m.relocalizePending = true
+		// Hit points from the last connection are out of date
+		m.vitalsTracker = nil
+		m.wimpyFled = false
+		if m.settingsManager != nil {
+			m.conn.SetSendInterval(time.Duration(m.settingsManager.ThrottleMs) * time.Millisecond)
+			if m.settingsManager.Wimpy != "" {
+				m.requestVitals()
+			}
+		}
+		if m.replayPath != "" {
+			m.output = append(m.output, m.colors().Debug.Render(fmt.Sprintf("[Replaying %s - commands are not sent anywhere]", m.replayPath)))
+		} else {
+			m.output = append(m.output, fmt.Sprintf("Connected to %s:%d", m.host, m.port))
+		}
+		// Without auto-login the command file starts right away (it can do
+		// the login itself); otherwise it waits for the password to be sent
+		var scriptCmd tea.Cmd
+		if m.password == "" {
+			scriptCmd = m.loggedIn()
+		}
 		m.updateViewport()
 		if m.webSessionID != "" {
 		}
+		// The tick timer started with the first connection is still running
+		if reconnected {
+			return m, tea.Batch(m.listenForMessages(), scriptCmd)
+		}
 		// Start tick timer
 		return m, tea.Batch(
-			m.listenForMessages,
+			m.listenForMessages(),
 			tea.Tick(time.Second, func(t time.Time) tea.Msg {
 				return tickTimerMsg{}
 			}),
+			scriptCmd,
 		)
 
+	case mudPromptMsg:
+		// Handled as any other output, with its last line taken as a prompt
+		m.serverPromptEnd = true
+		return m.update(mudMsg(msg))
+
 	case mudMsg:
 		// Add message to output - it already has proper line endings
 		msgStr := string(msg)
@@ -608,13 +1130,47 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 
 		// Log raw MUD output if logging enabled
-		if m.mudLogFile != nil {
-			fmt.Fprintf(m.mudLogFile, "[%s] %s", time.Now().Format("15:04:05.000"), msgStr)
-			m.mudLogFile.Sync()
+		if m.mudLog != nil {
+			fmt.Fprintf(m.mudLog, "[%s] %s", time.Now().Format("15:04:05.000"), msgStr)
 		}
 
 		var autoWalkCmd tea.Cmd
 
+		// Output answering a /notrig command is kept from the triggers
+		skipTriggers := m.skipTriggers
+		m.skipTriggers = false
+
+		// The server marked the end of this message as a prompt
+		serverPrompt := m.serverPromptEnd
+		m.serverPromptEnd = false
+
+		// With prompts hidden, the empty input line added after the last
+		// message is dropped again unless something was typed on it
+		hidePrompt := m.settingsManager != nil && m.settingsManager.HidePrompt
+		promptNewline := m.settingsManager != nil && m.settingsManager.PromptNewline
+		collapseBlanks := m.settingsManager != nil && m.settingsManager.CollapseBlanks
+		linkURLs := m.settingsManager != nil && m.settingsManager.LinkURLs && !m.plainText()
+
+		// After a line break forced after the prompt, a command typed there
+		// is already on a line of its own, so the newline the MUD starts its
+		// reply with would only add a blank line
+		if m.promptLineBreak {
+			if n := len(m.output); n > 0 && m.output[n-1] != "" {
+				msgStr = strings.TrimPrefix(msgStr, "\n")
+			}
+			m.promptLineBreak = false
+		}
+		if m.syntheticInputLine {
+			if n := len(m.output); n > 0 && m.output[n-1] == "" {
+				m.output = m.output[:n-1]
+				m.tabs.routed = min(m.tabs.routed, n-1)
+			}
+			m.syntheticInputLine = false
+		}
+		lastLineHidden := false
+		lastLinePrompt := false
+		var seenPlayers []string
+
 		// Split into lines and add them individually to preserve formatting
 		lines := strings.Split(msgStr, "\n")
 		for i, line := range lines {
@@ -623,8 +1179,31 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				continue
 			}
 			
+			// Classify the line once for the JSON log and the detectors below
+			cleanLine := ansi.Strip(line)
+			kind := m.classifyLine(cleanLine)
+			markedPrompt := serverPrompt && i == len(lines)-1
+			if markedPrompt {
+				kind = jsonlog.Prompt
+			}
+			m.writeJSONLog(line, cleanLine, kind)
+
+			// Lines from muted players are logged but shown nowhere
+			if m.isMutedLine(cleanLine) {
+				continue
+			}
+
+			// Lines a /gag trigger matches aren't shown either, but stay in
+			// recentOutput so room and inventory detection still see them
+			lineAction := triggers.ActionCommand
+			if m.triggerManager != nil {
+				lineAction = m.triggerManager.LineAction(line)
+			}
+			if lineAction != triggers.ActionGag {
+				m.writeOutputPipe(cleanLine)
+			}
+
 			// Check if this is a Barsoom marker line and suppress it
-			cleanLine := stripANSI(line)
 			trimmedLine := strings.TrimSpace(cleanLine)
 			// Match --< or >-- (with or without exits on the same line)
 			if trimmedLine == "--<" || strings.HasPrefix(trimmedLine, ">--") {
@@ -633,20 +1212,111 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				continue
 			}
 			
-			m.output = append(m.output, line)
+			// Hidden prompts still update state below, but only show in the
+			// status bar. Only prompts matching the pattern are hidden, as a
+			// line the server marks could be a login question.
+			lastLinePrompt = markedPrompt || m.isPromptLine(trimmedLine)
+			lastLineHidden = hidePrompt && m.isPromptLine(trimmedLine)
+			if lastLineHidden {
+				m.currentPrompt = trimmedLine
+			} else if lineAction == triggers.ActionGag {
+				// Gagged by a trigger
+			} else if trimmedLine == "" && collapseBlanks && m.followsBlankLine() {
+				// Another blank line in a run from the MUD is left out
+			} else {
+				// With colors off, only what is shown loses them; -raw
+				// triggers and the detectors still get the MUD's line
+				shown := line
+				if m.plainText() {
+					shown = cleanLine
+				}
+				if m.triggerManager != nil && !m.plainText() {
+					// A highlighted line takes the highlight color over its
+					// own colors, which come back after it
+					if lineAction == triggers.ActionHighlight {
+						shown = ansi.Highlight(shown, 0, len(cleanLine), theme.SGR(m.colors().Highlight))
+					}
+					// Highlight triggers with a color wrap just their match
+					shown = m.triggerManager.Colorize(shown)
+				}
+				if linkURLs {
+					// Only the shown line gets links; triggers still see the MUD's text
+					shown = ansi.LinkURLs(shown)
+				}
+				m.appendGameLine(shown, kind == jsonlog.Tell || mutes.Sender(cleanLine) != "")
+				if trimmedLine == "" {
+					m.blankLineEnd = len(m.output)
+				}
+			}
 			m.recentOutput = append(m.recentOutput, line)
 
 			// Check if this line is a tell message
-			m.detectAndParseTell(line)
+			if kind == jsonlog.Tell {
+				m.detectAndParseTell(line)
+			}
+
+			// Players seen in the room are recorded once the room is known
+			if name := parseSighting(cleanLine); name != "" {
+				seenPlayers = append(seenPlayers, name)
+			}
 
 			// Check for tick time in prompt
 			m.detectTickPrompt(line)
 
-			// Check for combat prompt to track XP/s
-			m.detectCombatPrompt(line)
+			// Check for the affects/spells listing
+			m.detectAffects(line)
+
+			// Check for identify output to remember the item's stats
+			m.detectIdentify(cleanLine, kind == jsonlog.Prompt)
+
+			// Check for money picked up and the coins carried
+			m.detectWealth(cleanLine, kind == jsonlog.Prompt)
+
+			// Check for a level gained (/levels)
+			m.detectLevelUp(cleanLine, time.Now())
+
+			// Check for lines filling a /capture record
+			m.detectCaptures(cleanLine)
+
+			// Check for combat prompt to track XP/s, and hit points for /wimpy
+			if kind == jsonlog.Prompt {
+				m.detectCombatPrompt(line)
+				m.detectPromptVitals(cleanLine)
+			}
+
+			if kind == jsonlog.Combat {
+				// Check for XP tracking events (death message and XP gain),
+				// which also queue the /autoloot commands
+				if cmd := m.detectXPEvents(line); cmd != nil {
+					autoWalkCmd = cmd
+				}
+
+				// Check for damage messages and the end of a fight
+				m.detectCombat(line)
+			}
+
+			// Check for the MUD warning that it will disconnect an idle player
+			m.detectIdleWarning(cleanLine)
+
+			// Check for the MUD warning that it is about to reboot (/reconnect-on)
+			if cmd := m.detectReconnectWarning(cleanLine, time.Now()); cmd != nil {
+				autoWalkCmd = cmd
+			}
+
+			// Check for group invitations and following (/autofollow)
+			if cmd := m.detectFollow(cleanLine); cmd != nil {
+				autoWalkCmd = cmd
+			}
+
+			// Check for the /autoassist leader starting a fight
+			if cmd := m.detectAutoAssist(cleanLine, time.Now()); cmd != nil {
+				autoWalkCmd = cmd
+			}
 
-			// Check for XP tracking events (death message and XP gain)
-			m.detectXPEvents(line)
+			// Check for a failed /retrycast spell
+			if cmd := m.detectRetryCast(cleanLine, time.Now()); cmd != nil {
+				autoWalkCmd = cmd
+			}
 
 			// Check for recall command (which causes teleportation)
 			// cleanLine already defined above
@@ -654,10 +1324,13 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				// Set flag to skip next room detection to avoid creating bad links
 				m.skipNextRoomDetection = true
 				if m.mapDebug {
-					m.output = append(m.output, "\x1b[90m[Mapper: Detected 'recall' - will skip next room detection]\x1b[0m")
+					m.output = append(m.output, m.colors().Debug.Render("[Mapper: Detected 'recall' - will skip next room detection]"))
 				}
 			}
 
+			// Check for forced movement (flee, teleport, being dragged)
+			m.detectForcedMovement(cleanLine)
+
 			// Check for "Alas, you cannot go that way..." during auto-walk
 			if m.autoWalking && (strings.Contains(cleanLine, "Alas, you cannot go that way") ||
 				strings.Contains(cleanLine, "cannot go that way")) {
@@ -665,12 +1338,31 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				autoWalkCmd = m.handleAutoWalkFailure()
 			}
 
-			// Check if this line matches any triggers
-			if m.triggerManager != nil && m.conn != nil {
-				actions := m.triggerManager.Match(line)
-				for _, action := range actions {
-					// Skip if this is the same action as the last one (coalesce duplicate trigger actions)
-					if action == m.lastTriggerAction {
+			// Check if this line matches any triggers (without colors unless
+			// a trigger is raw, so glob patterns can match the whole line).
+			// Multi-line triggers also see the lines before it.
+			if m.triggerManager != nil && m.conn != nil && !skipTriggers {
+				now := time.Now()
+				for _, result := range m.triggerManager.TestLines(m.recentLines(triggers.MaxLines)) {
+					action := result.Action
+					if result.Trigger.Cooldown > 0 {
+						// A trigger with a cooldown is limited by it instead of
+						// by coalescing, so it can repeat the same action
+						if result.Trigger.CoolingDown(now) {
+							continue
+						}
+						result.Trigger.MarkFired(now)
+					}
+					// Gags and highlights ring their bell too
+					if result.Trigger.Bell {
+						m.ringBell(now)
+					}
+					if result.Trigger.Type() != triggers.ActionCommand {
+						// Gags and highlights were applied when the line was shown
+						continue
+					}
+					if result.Trigger.Cooldown == 0 && action == m.lastTriggerAction {
+						// Skip if this is the same action as the last one (coalesce duplicate trigger actions)
 						continue
 					}
 					m.lastTriggerAction = action
@@ -680,15 +1372,18 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					for i := range commands {
 						commands[i] = strings.TrimSpace(commands[i])
 					}
-					// Filter out empty commands
+					// Filter out empty commands, and route panel:<name>:<text>
+					// to its sidebar panel instead of the MUD
 					var nonEmptyCommands []string
 					for _, cmd := range commands {
-						if cmd != "" {
+						if name, text, ok := parsePanelAction(cmd); ok {
+							m.appendToUserPanel(name, text)
+						} else if cmd != "" {
 							nonEmptyCommands = append(nonEmptyCommands, cmd)
 						}
 					}
 					if len(nonEmptyCommands) > 0 {
-						m.output = append(m.output, fmt.Sprintf("\x1b[90m[Trigger: %s]\x1b[0m", action))
+						m.output = append(m.output, m.colors().Debug.Render(fmt.Sprintf("[Trigger: %s]", action)))
 						// Only update autoWalkCmd if enqueueCommands returns a non-nil command
 						// This ensures we preserve the first command that starts the queue
 						if cmd := m.enqueueCommands(nonEmptyCommands); cmd != nil {
@@ -699,6 +1394,19 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 		}
 
+		// Typed input normally attaches to the prompt line, so give it an
+		// empty line of its own when the prompt was hidden
+		if lastLineHidden {
+			m.appendGameLine("", false)
+			m.syntheticInputLine = true
+		} else if promptNewline && lastLinePrompt && !strings.HasSuffix(msgStr, "\n") {
+			// With /promptnewline on, the same goes for a prompt the MUD
+			// didn't end with a newline
+			m.appendGameLine("", false)
+			m.syntheticInputLine = true
+			m.promptLineBreak = true
+		}
+
 		// Keep recentOutput to last 30 lines for room detection
 		if len(m.recentOutput) > 30 {
 			m.recentOutput = m.recentOutput[len(m.recentOutput)-30:]
@@ -707,15 +1415,17 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		// Try to detect room information from recent output
 		m.detectAndUpdateRoom()
 
+		// Players listed with a room belong to the room just entered
+		for _, name := range seenPlayers {
+			m.recordSighting(name, time.Now())
+		}
+
 		// Try to detect inventory information from recent output
 		m.detectAndUpdateInventory()
 
 		// Check for auto-login prompts
 		if m.username != "" && m.autoLoginState < 2 {
-			lastLine := ""
-			if len(m.output) > 0 {
-				lastLine = strings.ToLower(strings.TrimSpace(m.output[len(m.output)-1]))
-			}
+			lastLine := strings.ToLower(strings.TrimSpace(m.lastOutputLine()))
 
 			// Check for username prompt
 			if m.autoLoginState == 0 && (strings.Contains(lastLine, "name") ||
@@ -725,22 +1435,30 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				// Send username automatically
 				m.conn.Send(m.username)
 				m.autoLoginState = 1
-				m.output = append(m.output, fmt.Sprintf("\x1b[90m[Auto-login: sending username '%s']\x1b[0m", m.username))
+				m.output = append(m.output, m.colors().Debug.Render(fmt.Sprintf("[Auto-login: sending username '%s']", m.username)))
 			}
 		}
 
 		if m.password != "" && m.autoLoginState == 1 {
-			lastLine := ""
-			if len(m.output) > 0 {
-				lastLine = strings.ToLower(strings.TrimSpace(m.output[len(m.output)-1]))
-			}
+			lastLine := strings.ToLower(strings.TrimSpace(m.lastOutputLine()))
 
 			// Check for password prompt
 			if strings.Contains(lastLine, "password") || strings.Contains(lastLine, "pass") {
 				// Send password automatically
 				m.conn.Send(m.password)
 				m.autoLoginState = 2
-				m.output = append(m.output, "\x1b[90m[Auto-login: sending password]\x1b[0m")
+				m.output = append(m.output, m.colors().Debug.Render("[Auto-login: sending password]"))
+				// With a login menu, the command file waits until the menus
+				// have been answered
+				if len(m.loginMenu) == 0 {
+					if cmd := m.loggedIn(); cmd != nil {
+						autoWalkCmd = cmd
+					}
+				}
+			}
+		} else if m.autoLoginState == 2 && m.loginMenuStep < len(m.loginMenu) {
+			if cmd := m.answerLoginMenu(lines); cmd != nil {
+				autoWalkCmd = cmd
 			}
 		}
 
@@ -748,21 +1466,44 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 		// If we have an auto-walk command (from recovery), execute it along with listening
 		if autoWalkCmd != nil {
-			return m, tea.Batch(m.listenForMessages, autoWalkCmd)
+			return m, tea.Batch(m.listenForMessages(), autoWalkCmd)
 		}
-		return m, m.listenForMessages
+		return m, m.listenForMessages()
 
 	case echoStateMsg:
-		// Update echo suppression state (true = suppressed/password mode)
+		// Update echo suppression state (true = suppressed/password mode).
+		// Servers toggle this at any time, not only during login.
 		m.echoSuppressed = bool(msg)
 		m.updateViewport()
-		return m, m.listenForMessages
+		return m, m.listenForMessages()
+
+	case subnegotiationMsg:
+		// MSDP vitals take precedence over the prompt for /wimpy
+		if msg.Option == client.TELOPT_MSDP {
+			m.vitals().UpdateMSDP(msg.Data)
+		}
+		m.checkWimpy()
+		m.updateViewport()
+		return m, m.listenForMessages()
+
+	case gmcpMsg:
+		// Char.Vitals fills the hit point gauge and takes precedence over
+		// MSDP and the prompt for /wimpy
+		m.vitals().UpdateGMCPMessage(msg.Package, msg.JSON)
+		m.checkWimpy()
+		m.updateViewport()
+		return m, m.listenForMessages()
 
 	case errMsg:
 		if m.webSessionID != "" {
 		}
 		m.err = msg
-		m.output = append(m.output, fmt.Sprintf("Error: %v", msg))
+		var connErr *client.ConnectionError
+		if errors.As(msg, &connErr) {
+			m.output = append(m.output, m.colors().Error.Render(connErr.Friendly()))
+		} else {
+			m.output = append(m.output, fmt.Sprintf("Error: %v", msg))
+		}
 		m.updateViewport()
 
 		// If connection closed shortly after auto-login, it might be wrong password
@@ -772,11 +1513,56 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.savePasswordForWebClient("")
 		}
 
+		// A reboot announced by the MUD, or a failed reconnect, leaves the
+		// session open to connect again
+		if !m.reconnectAt.IsZero() || m.reconnecting {
+			m.connected = false
+			m.reconnecting = false
+			if m.reconnectAt.IsZero() {
+				m.output = append(m.output, m.colors().Warn.Render("[Reconnect failed - use /reconnect to try again]"))
+			} else {
+				m.output = append(m.output, m.colors().Warn.Render(fmt.Sprintf("[Connection closed - reconnecting in %s]", time.Until(m.reconnectAt).Round(time.Second))))
+			}
+			m.updateViewport()
+			return m, nil
+		}
+
+		// A server that is down or unreachable may be back soon
+		if connErr != nil && connErr.Recoverable() {
+			m.connected = false
+			m.output = append(m.output, m.colors().Warn.Render("[Not connected - /reconnect to try again]"))
+			m.updateViewport()
+			return m, nil
+		}
+
+		// With other sessions open, only this session ends
+		if len(m.sessions) > 1 {
+			m.connected = false
+			m.output = append(m.output, m.colors().Warn.Render("[Session closed - Ctrl+Tab or /sessions <n> to switch]"))
+			m.updateViewport()
+			return m, nil
+		}
+
 		// When MUD closes connection, TUI should exit
 		if m.webSessionID != "" {
 		}
 		return m, tea.Quit
 
+	case reconnectMsg:
+		// A later warning or a manual reconnect replaces this one
+		if !msg.at.Equal(m.reconnectAt) {
+			return m, nil
+		}
+		m.reconnectAt = time.Time{}
+		if m.connected && m.conn != nil && !m.conn.IsClosed() {
+			m.output = append(m.output, m.colors().Warn.Render("[Still connected - automatic reconnect skipped]"))
+			m.updateViewport()
+			return m, nil
+		}
+		cmd := m.reconnect()
+		m.updateViewport()
+		return m, cmd
+
 	case autoWalkTickMsg:
 		// Process next step in auto-walk
 		if m.autoWalking && m.autoWalkIndex < len(m.autoWalkPath) {
@@ -787,13 +1573,14 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			if m.conn != nil && m.connected {
 				m.conn.Send(direction)
 				m.pendingMovement = direction
-				m.output = append(m.output, fmt.Sprintf("\x1b[90m[Auto-walk: %s (%d/%d)]\x1b[0m", direction, m.autoWalkIndex, len(m.autoWalkPath)))
+				m.odometer.Move()
+				m.output = append(m.output, m.colors().Debug.Render(fmt.Sprintf("[Auto-walk: %s (%d/%d)]", direction, m.autoWalkIndex, len(m.autoWalkPath))))
 				m.updateViewport()
 			}
 
 			// If more steps remain, schedule next tick
 			if m.autoWalkIndex < len(m.autoWalkPath) {
-				return m, tea.Tick(time.Second, func(t time.Time) tea.Msg {
+				return m, tea.Tick(m.walkInterval(), func(t time.Time) tea.Msg {
 					return autoWalkTickMsg{}
 				})
 			} else {
@@ -801,12 +1588,17 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.autoWalking = false
 				m.autoWalkPath = nil
 				m.autoWalkIndex = 0
-				m.output = append(m.output, "\x1b[92m[Auto-walk complete!]\x1b[0m")
+				m.walkDelayOverride = 0
+				m.output = append(m.output, m.colors().Info.Render("[Auto-walk complete!]"))
 				m.updateViewport()
 			}
 		}
 		return m, nil
 
+	case fastWalkTickMsg:
+		// Send the next batch of a fast walk
+		return m, m.sendFastWalkBatch()
+
 	case tickTimerMsg:
 		// Check if any tick triggers should fire
 		if m.tickTimerManager != nil && m.tickTimerManager.TickInterval > 0 {
@@ -847,15 +1639,57 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 		}
 		
+		// Warn about affects wearing off and drop the ones that have
+		if cmd := m.checkAffects(); cmd != nil {
+			cmds = append(cmds, cmd)
+		}
+
+		// Send the AFK command once the player has been idle long enough
+		if cmd := m.checkIdle(); cmd != nil {
+			cmds = append(cmds, cmd)
+		}
+
+		// Refresh the inventory panel when /inventory auto is on
+		if cmd := m.checkInventoryRefresh(time.Now()); cmd != nil {
+			cmds = append(cmds, cmd)
+		}
+
+		// Forget a /retrycast that hasn't failed in time
+		m.expireRetryCast(time.Now())
+
+		// Get ready to assist again once the fight is over
+		m.expireAutoAssist(time.Now())
+
+		// Write the map and XP stats if they changed a while ago
+		m.autosave(time.Now())
+
 		// Schedule next tick timer check (every second)
 		return m, tea.Batch(append(cmds, tea.Tick(time.Second, func(t time.Time) tea.Msg {
 			return tickTimerMsg{}
 		}))...)
 
 	case commandQueueTickMsg:
+		// Client commands (e.g. /set from a trigger) run straight away
+		// rather than waiting for a tick of their own
+		for m.commandQueueActive && len(m.pendingCommands) > 0 && strings.HasPrefix(m.pendingCommands[0], "/") {
+			command := m.pendingCommands[0]
+			m.pendingCommands = m.pendingCommands[1:]
+			// A wait from a command file pauses the queue
+			if wait, ok := parseQueueWait(command); ok {
+				cmds = append(cmds, tea.Tick(wait, func(t time.Time) tea.Msg {
+					return commandQueueTickMsg{}
+				}))
+				return m, tea.Batch(cmds...)
+			}
+			if cmd := m.handleClientCommand(command); cmd != nil {
+				cmds = append(cmds, cmd)
+			}
+			m.updateViewport()
+		}
+
 		// Process next command in queue
 		if m.commandQueueActive && len(m.pendingCommands) > 0 {
-			command := m.pendingCommands[0]
+			command := m.substituteVariables(m.substituteOutgoing(m.pendingCommands[0]))
 			m.pendingCommands = m.pendingCommands[1:]
 
 			// Send the command
@@ -866,31 +1700,33 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				if m.autoWalking && m.autoWalkIndex < len(m.autoWalkPath) {
 					m.autoWalkIndex++
 					m.pendingMovement = command
-					m.output = append(m.output, fmt.Sprintf("\x1b[90m[Auto-walk: %s (%d/%d)]\x1b[0m", command, m.autoWalkIndex, len(m.autoWalkPath)))
+					m.odometer.Move()
+					m.output = append(m.output, m.colors().Debug.Render(fmt.Sprintf("[Auto-walk: %s (%d/%d)]", command, m.autoWalkIndex, len(m.autoWalkPath))))
 				} else {
-					m.output = append(m.output, fmt.Sprintf("\x1b[90m[Queue: %s]\x1b[0m", command))
+					m.output = append(m.output, m.colors().Debug.Render(fmt.Sprintf("[Queue: %s]", command)))
 				}
 				m.updateViewport()
 			}
+		}
 
-			// If more commands remain, schedule next tick
-			if len(m.pendingCommands) > 0 {
-				return m, tea.Tick(time.Second, func(t time.Time) tea.Msg {
-					return commandQueueTickMsg{}
-				})
-			} else {
-				// Queue complete
-				m.commandQueueActive = false
-				if m.autoWalking {
-					m.autoWalking = false
-					m.autoWalkPath = nil
-					m.autoWalkIndex = 0
-					m.output = append(m.output, "\x1b[92m[Auto-walk complete!]\x1b[0m")
-					m.updateViewport()
-				}
+		// If more commands remain, schedule next tick
+		if m.commandQueueActive && len(m.pendingCommands) > 0 {
+			cmds = append(cmds, tea.Tick(m.queueInterval(), func(t time.Time) tea.Msg {
+				return commandQueueTickMsg{}
+			}))
+		} else if m.commandQueueActive {
+			// Queue complete
+			m.commandQueueActive = false
+			if m.autoWalking {
+				m.autoWalking = false
+				m.autoWalkPath = nil
+				m.autoWalkIndex = 0
+				m.walkDelayOverride = 0
+				m.output = append(m.output, m.colors().Info.Render("[Auto-walk complete!]"))
+				m.updateViewport()
 			}
 		}
-		return m, nil
+		return m, tea.Batch(cmds...)
 	}
 
 	m.viewport, cmd = m.viewport.Update(msg)
@@ -916,16 +1752,22 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 // updateViewport updates the viewport content with output and current input
 func (m *Model) updateViewport() {
-	// Always append input to the last line (all lines are treated as potential prompts)
-	var content string
-	if len(m.output) > 0 {
-		lastLine := m.output[len(m.output)-1]
+	plainText := m.plainText()
+
+	// While focusing, lines not matching the focus are dimmed or hidden, and
+	// the /target's name stands out
+	output := m.highlightTarget(m.focusedOutput(m.tabOutput()))
+
+	// Always append input to the last line (all lines are treated as potential prompts).
+	// The lines before it are the body; the last line and what is drawn
+	// after it are the tail.
+	var body, tail []string
+	if len(output) > 0 {
+		body = output[:len(output)-1]
+		lastLine := output[len(output)-1]
 
 		// Handle history search mode display
 		if m.historySearchMode {
-			lines := make([]string, len(m.output)-1)
-			copy(lines, m.output[:len(m.output)-1])
-
 			// Add search prompt
 			searchPrompt := fmt.Sprintf("(reverse-i-search)`%s': ", m.historySearchQuery)
 
@@ -933,23 +1775,19 @@ func (m *Model) updateViewport() {
 			if len(m.historySearchResults) > 0 && m.historySearchIndex < len(m.historySearchResults) {
 				resultIdx := m.historySearchResults[m.historySearchIndex]
 				matchedCmd := m.commandHistory[resultIdx]
-				lines = append(lines, lastLine+"\x1b[96m"+searchPrompt+"\x1b[93m"+matchedCmd+"█\x1b[0m")
+				tail = append(tail, lastLine+"\x1b[96m"+searchPrompt+"\x1b[93m"+matchedCmd+"█\x1b[0m")
 			} else {
-				lines = append(lines, lastLine+"\x1b[96m"+searchPrompt+"█\x1b[0m")
+				tail = append(tail, lastLine+"\x1b[96m"+searchPrompt+"█\x1b[0m")
 			}
 
 			// Add search results summary
 			if len(m.historySearchResults) > 0 {
-				lines = append(lines, fmt.Sprintf("\x1b[90m[%d/%d matches - Up/Down to navigate, Enter to select, Esc to cancel]\x1b[0m",
-					m.historySearchIndex+1, len(m.historySearchResults)))
+				tail = append(tail, m.colors().Debug.Render(fmt.Sprintf("[%d/%d matches - Up/Down to navigate, Enter to select, Esc to cancel]", m.historySearchIndex+1, len(m.historySearchResults))))
 			} else if m.historySearchQuery != "" {
-				lines = append(lines, "\x1b[90m[No matches found]\x1b[0m")
+				tail = append(tail, m.colors().Debug.Render("[No matches found]"))
 			} else {
-				lines = append(lines, fmt.Sprintf("\x1b[90m[%d commands - Type to search, Enter to select, Esc to cancel]\x1b[0m",
-					len(m.commandHistory)))
+				tail = append(tail, m.colors().Debug.Render(fmt.Sprintf("[%d commands - Type to search, Enter to select, Esc to cancel]", len(m.commandHistory))))
 			}
-
-			content = strings.Join(lines, "\n")
 		} else if (m.currentInput != "" || m.connected) && !m.echoSuppressed && !m.isPasswordPrompt() {
 			// Build input line with cursor (only if echo is not suppressed and not a password prompt)
 			inputLine := m.currentInput
@@ -963,19 +1801,13 @@ func (m *Model) updateViewport() {
 
 			// Append input inline to the last line with yellow color
 			// Use bright yellow (93) for better visibility
-			lines := make([]string, len(m.output)-1)
-			copy(lines, m.output[:len(m.output)-1])
-			lines = append(lines, lastLine+"\x1b[93m"+inputLine+"\x1b[0m")
-			content = strings.Join(lines, "\n")
+			tail = append(tail, lastLine+"\x1b[93m"+inputLine+"\x1b[0m")
 		} else if (m.echoSuppressed || m.isPasswordPrompt()) && m.connected {
 			// In password mode, show bullets for each character typed
 			bullets := strings.Repeat("•", len(m.currentInput))
-			lines := make([]string, len(m.output)-1)
-			copy(lines, m.output[:len(m.output)-1])
-			lines = append(lines, lastLine+bullets+"█")
-			content = strings.Join(lines, "\n")
+			tail = append(tail, lastLine+bullets+"█")
 		} else {
-			content = strings.Join(m.output, "\n")
+			tail = append(tail, lastLine)
 		}
 	} else {
 		// No output yet, just show cursor if connected
@@ -988,15 +1820,35 @@ func (m *Model) updateViewport() {
 					inputLine = m.currentInput + "█"
 				}
 				// Use bright yellow for better visibility
-				content = "\x1b[93m" + inputLine + "\x1b[0m"
+				tail = append(tail, "\x1b[93m"+inputLine+"\x1b[0m")
 			} else {
 				// Password mode - show bullets for each character typed
 				bullets := strings.Repeat("•", len(m.currentInput))
-				content = bullets + "█"
+				tail = append(tail, bullets+"█")
 			}
 		}
 	}
 
+	// The input line and search prompt are drawn in color
+	tailText := strings.Join(tail, "\n")
+	if plainText {
+		tailText = ansi.Strip(tailText)
+	}
+
+	// Long lines are wrapped as they are shown rather than when they
+	// arrive, so a resize reflows what was already printed. Only body
+	// lines that changed since the last redraw are wrapped again.
+	shown := m.wrapped.wrap(body, m.viewport.Width, plainText)
+	content := m.wrapToViewport(tailText)
+	if len(shown) > 0 {
+		content = strings.Join(shown, "\n") + "\n" + content
+	}
+
+	// The copy mode selection is shown in reverse video
+	if m.copyMode && m.hasSelection {
+		content = highlightSelection(content, m.selectionStart, m.selectionEnd)
+	}
+
 	// Only update viewport content if it actually changed
 	// This avoids unnecessary screen refreshes and viewport jumps during typing
 	if content != m.lastViewportContent {
@@ -1007,8 +1859,11 @@ func (m *Model) updateViewport() {
 		m.lastViewportContent = content
 
 		// If not in split mode or if viewport is already at bottom, go to bottom
-		// This preserves scroll position when in split mode
-		if !m.isSplit {
+		// This preserves scroll position when in split mode. In copy mode
+		// the text being selected stays where it is.
+		if m.copyMode {
+			// Scrolled only by the copy mode keys
+		} else if !m.isSplit {
 			m.viewport.GotoBottom()
 		} else if wasAtBottom {
 			// If user was already at bottom and new content arrived, exit split mode
@@ -1022,46 +1877,65 @@ func (m *Model) updateViewport() {
 	}
 
 	// Log TUI content if logging enabled
-	if m.tuiLogFile != nil {
-		fmt.Fprintf(m.tuiLogFile, "[%s] === TUI Update ===\n%s\n\n", time.Now().Format("15:04:05.000"), content)
-		m.tuiLogFile.Sync()
+	if m.tuiLog != nil {
+		fmt.Fprintf(m.tuiLog, "[%s] === TUI Update ===\n%s\n\n", time.Now().Format("15:04:05.000"), content)
 	}
 }
 
-// listenForMessages listens for messages from the MUD server
-func (m *Model) listenForMessages() tea.Msg {
-	webSessionID := os.Getenv("DIKUCLIENT_WEB_SESSION_ID")
+// listenForMessages listens for messages from the MUD server. The connection
+// is captured when the command is created, so it keeps listening to the same
+// session after the active session changes.
+func (m *Model) listenForMessages() tea.Cmd {
+	conn := m.conn
+	return func() tea.Msg {
+		webSessionID := os.Getenv("DIKUCLIENT_WEB_SESSION_ID")
+
+		if conn == nil || conn.IsClosed() {
+			// Connection is closed, return error to trigger quit
+			return errMsg(fmt.Errorf("connection closed"))
+		}
 
-	if m.conn == nil || m.conn.IsClosed() {
-		// Connection is closed, return error to trigger quit
-		return errMsg(fmt.Errorf("connection closed"))
-	}
-
-	if webSessionID != "" {
-	}
-
-	select {
-	case msg := <-m.conn.Receive():
-		if webSessionID != "" {
-		}
-		return mudMsg(msg)
-	case echoSuppressed := <-m.conn.EchoState():
 		if webSessionID != "" {
 		}
-		return echoStateMsg(echoSuppressed)
-	case err := <-m.conn.Errors():
-		if webSessionID != "" {
+
+		select {
+		case msg := <-conn.Receive():
+			if webSessionID != "" {
+			}
+			if msg.Prompt {
+				return mudPromptMsg(msg.Text)
+			}
+			return mudMsg(msg.Text)
+		case echoSuppressed := <-conn.EchoState():
+			if webSessionID != "" {
+			}
+			return echoStateMsg(echoSuppressed)
+		case sub := <-conn.Subnegotiations():
+			return subnegotiationMsg(sub)
+		case message := <-conn.GMCP():
+			return gmcpMsg(message)
+		case err := <-conn.Errors():
+			if webSessionID != "" {
+			}
+			return errMsg(err)
 		}
-		return errMsg(err)
 	}
 }
 
 // View renders the application
 func (m *Model) View() string {
+	if m.accessible {
+		return m.renderAccessibleInput()
+	}
+
 	if m.width == 0 {
 		return "Loading..."
 	}
 
+	if m.triggerEditor != nil {
+		return m.triggerEditor.View()
+	}
+
 	// Status bar
 	status := m.renderStatusBar()
 
@@ -1079,11 +1953,329 @@ func (m *Model) renderStatusBar() string {
 	statusText := "Disconnected"
 	if m.connected {
 		statusText = fmt.Sprintf("Connected to %s:%d", m.host, m.port)
+		if m.replayPath != "" {
+			statusText = fmt.Sprintf("Replaying %s", m.replayPath)
+		}
+		// The server can hide input for more than the login password, e.g.
+		// a new password or a confirmation, so say why typing is masked
+		if m.echoSuppressed && !m.isPasswordPrompt() {
+			statusText += " (hidden input)"
+		}
 	}
 
 	status := statusStyle.Render(statusText)
-	line := strings.Repeat("─", max(0, m.width-lipgloss.Width(status)))
-	return lipgloss.JoinHorizontal(lipgloss.Left, status, line)
+	if m.copyMode {
+		status = statusStyle.Render("COPY MODE: drag to select, y to copy, Esc to leave")
+	} else if len(m.sessions) > 1 {
+		status = m.renderSessionTabs()
+	}
+
+	// Latency and the clock sit at the right, if there's room
+	info := statusStyle.Render(m.statusInfo(time.Now()))
+	if lipgloss.Width(status)+lipgloss.Width(info) > m.width {
+		info = ""
+	}
+
+	if m.settingsManager != nil && m.settingsManager.HidePrompt && m.currentPrompt != "" {
+		prompt := promptStatusStyle.MaxWidth(max(0, m.width-lipgloss.Width(status)-lipgloss.Width(info))).Render(m.currentPrompt)
+		status = lipgloss.JoinHorizontal(lipgloss.Left, status, prompt)
+	}
+	line := strings.Repeat("─", max(0, m.width-lipgloss.Width(status)-lipgloss.Width(info)))
+	return lipgloss.JoinHorizontal(lipgloss.Left, status, line, info)
+}
+
+// statusInfo formats the running /stopwatch, the round-trip time to the
+// server and the clock
+func (m *Model) statusInfo(now time.Time) string {
+	clock := now.Format("15:04:05")
+	if m.stopwatch.Running() {
+		clock = fmt.Sprintf("Stopwatch: %s  %s", stopwatch.FormatShort(m.stopwatch.Elapsed(now)), clock)
+	}
+	if gauge := m.vitalsGauge(); gauge != "" {
+		clock = gauge + "  " + clock
+	}
+	if m.connected && m.conn != nil {
+		if rtt, ok := m.conn.RTT(); ok {
+			return fmt.Sprintf("RTT: %dms  %s", rtt.Milliseconds(), clock)
+		}
+	}
+	return clock
+}
+
+// vitalsGaugeWidth is the number of cells in the status bar's hit point bar
+const vitalsGaugeWidth = 10
+
+// vitalsGauge shows the hit points from GMCP Char.Vitals as a bar, such as
+// "HP [████░░░░░░] 40/100". It is empty until the server sends them.
+func (m *Model) vitalsGauge() string {
+	if m.vitalsTracker == nil {
+		return ""
+	}
+	reading, ok := m.vitalsTracker.Reading(vitals.SourceGMCP)
+	if !ok || !reading.HaveHP || reading.MaxHP <= 0 {
+		return ""
+	}
+	filled := min(max(reading.HP*vitalsGaugeWidth/reading.MaxHP, 0), vitalsGaugeWidth)
+	bar := strings.Repeat("█", filled) + strings.Repeat("░", vitalsGaugeWidth-filled)
+	return fmt.Sprintf("HP [%s] %d/%d", bar, reading.HP, reading.MaxHP)
+}
+
+// accessiblePrintedMsg reports that a batch of lines printed in accessible
+// mode has reached the terminal, so the next batch can follow it
+type accessiblePrintedMsg struct{}
+
+// SetStartupScript sets commands to queue once the connection is made and
+// auto-login has sent the password (--command-file)
+func (m *Model) SetStartupScript(steps []script.Step) {
+	m.startupScript = steps
+}
+
+// SetCharset sets the encoding the MUD sends and expects (--charset). It
+// applies to connections made after the call.
+func (m *Model) SetCharset(charset client.Charset) {
+	m.charset = charset
+}
+
+// SetLineEnding sets what ends each command sent to the MUD (--line-ending).
+// It applies to connections made after the call.
+func (m *Model) SetLineEnding(lineEnding client.LineEnding) {
+	m.lineEnding = lineEnding
+}
+
+// serverEncoding returns the charset and line ending to use for a server:
+// the ones saved for it in accounts.json, or else the model's
+func (m *Model) serverEncoding(host string, port int) (client.Charset, client.LineEnding) {
+	charset, lineEnding := m.charset, m.lineEnding
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return charset, lineEnding
+	}
+	savedCharset, savedLineEnding := cfg.Encoding(host, port)
+	if parsed, err := client.ParseCharset(savedCharset); err == nil && savedCharset != "" {
+		charset = parsed
+	}
+	if parsed, err := client.ParseLineEnding(savedLineEnding); err == nil && savedLineEnding != "" {
+		lineEnding = parsed
+	}
+	return charset, lineEnding
+}
+
+// SetReplay plays back the session recording read from path instead of
+// connecting to the server (--replay)
+func (m *Model) SetReplay(path string, chunks []client.RecordedChunk, speed float64) {
+	m.replayPath = path
+	m.replayChunks = chunks
+	m.replaySpeed = speed
+}
+
+// loginMenuStep is a menu shown after the password and the answer to it
+type loginMenuStep struct {
+	pattern  *regexp.Regexp
+	response string
+}
+
+// SetLoginMenu sets the menus auto-login answers, in order, after sending
+// the password, e.g. a "1) Enter the game" menu. Patterns are regular
+// expressions matched against each line without colors, ignoring case.
+func (m *Model) SetLoginMenu(steps []config.MenuStep) error {
+	menu := make([]loginMenuStep, 0, len(steps))
+	for _, step := range steps {
+		pattern, err := regexp.Compile("(?i)" + step.Pattern)
+		if err != nil {
+			return fmt.Errorf("invalid login menu pattern %q: %w", step.Pattern, err)
+		}
+		menu = append(menu, loginMenuStep{pattern: pattern, response: step.Response})
+	}
+	m.loginMenu = menu
+	m.loginMenuStep = 0
+	return nil
+}
+
+// answerLoginMenu sends the response to the next login menu if one of the
+// lines just received shows it. Once the last menu is answered the command
+// file starts.
+func (m *Model) answerLoginMenu(lines []string) tea.Cmd {
+	step := m.loginMenu[m.loginMenuStep]
+	matched := false
+	for _, line := range lines {
+		if step.pattern.MatchString(ansi.Strip(line)) {
+			matched = true
+			break
+		}
+	}
+	if !matched {
+		return nil
+	}
+
+	m.conn.Send(step.response)
+	m.loginMenuStep++
+	m.output = append(m.output, m.colors().Debug.Render(fmt.Sprintf("[Auto-login: answering menu with '%s']", step.response)))
+	if m.loginMenuStep < len(m.loginMenu) {
+		return nil
+	}
+	return m.loggedIn()
+}
+
+// loggedIn queues what waits for the login to finish: the startup script,
+// then the commands typed while not connected
+func (m *Model) loggedIn() tea.Cmd {
+	scriptCmd := m.runStartupScript()
+	if len(m.offlineCommands) == 0 {
+		return scriptCmd
+	}
+
+	var commands []string
+	for _, line := range m.offlineCommands {
+		if m.aliasManager != nil {
+			if expanded, ok := m.aliasManager.Expand(line); ok {
+				line = expanded
+			}
+		}
+		for _, command := range strings.Split(line, ";") {
+			if command = strings.TrimSpace(command); command != "" {
+				commands = append(commands, command)
+			}
+		}
+	}
+	m.offlineCommands = nil
+	m.output = append(m.output, m.colors().Debug.Render(fmt.Sprintf("[Sending %d commands typed while not connected]", len(commands))))
+	return tea.Batch(scriptCmd, m.enqueueCommands(commands))
+}
+
+// maxOfflineCommands is the most commands held while not connected
+const maxOfflineCommands = 20
+
+// bufferOfflineCommand holds a command typed before the connection is up
+// until the login has finished
+func (m *Model) bufferOfflineCommand(command string) {
+	if len(m.offlineCommands) >= maxOfflineCommands {
+		m.output = append(m.output, m.colors().Error.Render(fmt.Sprintf("[Not connected - %d commands already held, '%s' dropped]", maxOfflineCommands, command)))
+		return
+	}
+	m.offlineCommands = append(m.offlineCommands, command)
+	m.output = append(m.output, m.colors().Warn.Render(fmt.Sprintf("[Not connected - '%s' held, %d to send after login]", command, len(m.offlineCommands))))
+}
+
+// runStartupScript queues the startup script, once
+func (m *Model) runStartupScript() tea.Cmd {
+	if len(m.startupScript) == 0 {
+		return nil
+	}
+	steps := m.startupScript
+	m.startupScript = nil
+
+	commands := make([]string, 0, len(steps))
+	for _, step := range steps {
+		if step.Command == "" {
+			commands = append(commands, queueWaitCommand(step.Wait))
+		} else {
+			commands = append(commands, step.Command)
+		}
+	}
+	m.output = append(m.output, m.colors().Debug.Render(fmt.Sprintf("[Command file: queued %d commands]", len(commands))))
+	return m.enqueueCommands(commands)
+}
+
+// queueWaitCommand is the command queue entry for a pause
+func queueWaitCommand(wait time.Duration) string {
+	return fmt.Sprintf("/wait %g", wait.Seconds())
+}
+
+// parseQueueWait reads a pause in the command queue ("/wait <seconds>")
+func parseQueueWait(command string) (time.Duration, bool) {
+	fields := strings.Fields(command)
+	if len(fields) != 2 || fields[0] != "/wait" {
+		return 0, false
+	}
+	var seconds float64
+	if _, err := fmt.Sscanf(fields[1], "%g", &seconds); err != nil || seconds <= 0 {
+		return 0, false
+	}
+	return time.Duration(seconds * float64(time.Second)), true
+}
+
+// SetAccessible prints output as plain scrolling lines above the input
+// instead of drawing the full-screen layout, for use with screen readers.
+// The program must be run without the alternate screen.
+func (m *Model) SetAccessible(accessible bool) {
+	m.accessible = accessible
+}
+
+// takeAccessibleLines returns the output lines that haven't been printed yet.
+// The last line is held back because it's the prompt being typed on, and
+// the rest of it may still arrive.
+func (m *Model) takeAccessibleLines() []string {
+	if m.accessiblePrinted > len(m.output) {
+		m.accessiblePrinted = len(m.output)
+	}
+	end := len(m.output) - 1
+	if m.accessiblePrinted >= end {
+		return nil
+	}
+	lines := append([]string(nil), m.output[m.accessiblePrinted:end]...)
+	m.accessiblePrinted = end
+	return lines
+}
+
+// printAccessibleOutput prints new output above the input line. Only one
+// batch is in flight at a time so lines can't reach the terminal out of order.
+func (m *Model) printAccessibleOutput() tea.Cmd {
+	if m.accessiblePrinting {
+		return nil
+	}
+	lines := m.takeAccessibleLines()
+	if len(lines) == 0 {
+		return nil
+	}
+	m.accessiblePrinting = true
+	return tea.Sequence(
+		tea.Println(strings.Join(lines, "\n")),
+		func() tea.Msg { return accessiblePrintedMsg{} },
+	)
+}
+
+// renderAccessibleInput draws the only managed part of the screen in
+// accessible mode: the unprinted prompt and what's being typed after it
+func (m *Model) renderAccessibleInput() string {
+	prompt := ""
+	if n := len(m.output); n > m.accessiblePrinted {
+		prompt = m.output[n-1]
+	}
+
+	input := m.currentInput
+	if m.historySearchMode {
+		match := ""
+		if len(m.historySearchResults) > 0 && m.historySearchIndex < len(m.historySearchResults) {
+			match = m.commandHistory[m.historySearchResults[m.historySearchIndex]]
+		}
+		input = fmt.Sprintf("(reverse-i-search)`%s': %s", m.historySearchQuery, match)
+	} else if m.echoSuppressed || m.isPasswordPrompt() {
+		input = strings.Repeat("*", len(input))
+	}
+	return prompt + input
+}
+
+// splitHeights divides the main content height between the scrolled-back
+// pane and the live pane below it, giving the top pane ratio percent. The
+// border between them takes a line, so top+bottom+1 is always height, and
+// each pane keeps room for its borders and a line of output.
+func splitHeights(height, ratio int) (top, bottom int) {
+	top = min(max(height*ratio/100, 2), height-4)
+	return top, height - top - 1
+}
+
+// splitShown reports whether the output is split: while scrolled back, or
+// always with /split on
+func (m *Model) splitShown() bool {
+	return m.isSplit || (m.settingsManager != nil && m.settingsManager.SplitPinned)
+}
+
+// splitRatio returns the percentage of the split view for scrolled-back output
+func (m *Model) splitRatio() int {
+	if m.settingsManager == nil {
+		return settings.DefaultSplitRatio
+	}
+	return m.settingsManager.GetSplitRatio()
 }
 
 func (m *Model) renderMainContent() string {
@@ -1128,6 +2320,13 @@ func (m *Model) renderMainContent() string {
 		}
 	}
 
+	// The tab bar leads the title
+	if mainTitle != "" {
+		mainTitle = m.renderTabBar() + " ── " + mainTitle
+	} else {
+		mainTitle = m.renderTabBar()
+	}
+
 	// Create custom border with title embedded in top border
 	customBorder := lipgloss.RoundedBorder()
 	if mainTitle != "" {
@@ -1147,7 +2346,7 @@ func (m *Model) renderMainContent() string {
 
 	var gameOutput string
 
-	if m.hasDescriptionSplit && m.isSplit {
+	if m.hasDescriptionSplit && m.splitShown() {
 		// Three-way split: description at top, scrollable in middle, live at bottom
 		descHeight := 6 // Fixed height for description
 		liveHeight := actualContentHeight / 4 // Live output takes 1/4
@@ -1266,18 +2465,24 @@ func (m *Model) renderMainContent() string {
 			Render(m.viewport.View())
 		
 		gameOutput = lipgloss.JoinVertical(lipgloss.Left, descView, bottomView)
-	} else if m.isSplit {
-		// Split mode: 2/3 for user scrolled position, 1/3 for live output at bottom
+	} else if m.splitShown() {
+		// Split mode: the /split ratio (2/3 by default) for user scrolled position,
+		// the rest for live output at bottom
 		// When stacking two boxes vertically, we need to account for the extra border line
 		// where they meet (the separator between them)
-		topHeight := (actualContentHeight * 2) / 3
-		bottomHeight := actualContentHeight - topHeight - 1 // -1 for separator border
+		topHeight, bottomHeight := splitHeights(actualContentHeight, m.splitRatio())
 
 		// Adjust viewport heights to match the split heights
 		// Subtract border heights: topHeight has 1 border (top), bottomHeight has 2 borders (top+bottom)
 		m.viewport.Height = topHeight - 1
 		m.splitViewport.Height = bottomHeight - 2
 
+		// Pinned on but not scrolled back, the top pane shows the lines
+		// just above the live output rather than repeating it
+		if !m.isSplit && !m.copyMode {
+			m.viewport.SetYOffset(m.viewport.TotalLineCount() - m.viewport.Height - m.splitViewport.Height)
+		}
+
 		// Top viewport (user's scrolled position)
 		topBorderStyle := lipgloss.NewStyle().
 			BorderStyle(customBorder).
@@ -1390,6 +2595,19 @@ func (m *Model) renderSidebar(width, height int) string {
 	}
 	m.tellsViewport.SetContent(tellsContent)
 
+	// Affects share the tells slot while any are active
+	tellsHeight := panelHeight
+	affectsPanel := ""
+	if m.affectTracker != nil && len(m.affectTracker.Affects) > 0 && panelHeight >= 3 {
+		affectsHeight := max(1, min(len(m.affectTracker.Affects), (panelHeight-1)/2))
+		tellsHeight = panelHeight - 1 - affectsHeight
+		affectsPanel = m.renderAffectsPanel(width, affectsHeight)
+	}
+	tellsView := m.tellsViewport
+	if tellsHeight != panelHeight {
+		tellsView.Height = tellsHeight
+	}
+
 	tellsBorder := createBorderWithTitle("Tells", width, "top") // Top panel uses ┬ for top-right corner
 	tellsStyle := lipgloss.NewStyle().
 		BorderStyle(tellsBorder).
@@ -1403,8 +2621,11 @@ func (m *Model) renderSidebar(width, height int) string {
 
 	tellsPanel := tellsStyle.
 		Width(width - 2).
-		Height(panelHeight).
-		Render(m.tellsViewport.View())
+		Height(tellsHeight).
+		Render(tellsView.View())
+	if affectsPanel != "" {
+		tellsPanel = lipgloss.JoinVertical(lipgloss.Left, tellsPanel, affectsPanel)
+	}
 
 	// XP/s panel with scrollable viewport - shows persistent averaged stats
 	var xpContent string
@@ -1453,6 +2674,19 @@ func (m *Model) renderSidebar(width, height int) string {
 	}
 	m.xpViewport.SetContent(xpContent)
 
+	// The current fight shares the XP slot while combat is under way
+	xpHeight := panelHeight
+	combatPanel := ""
+	if m.combatLog != nil && m.combatLog.Current != nil && panelHeight >= 5 {
+		combatHeight := 3
+		xpHeight = panelHeight - 1 - combatHeight
+		combatPanel = m.renderCombatPanel(width, combatHeight)
+	}
+	xpView := m.xpViewport
+	if xpHeight != panelHeight {
+		xpView.Height = xpHeight
+	}
+
 	xpBorder := createBorderWithTitle("XP/s (avg)", width, "middle") // Middle panel uses T-junction corners
 	xpStyle := lipgloss.NewStyle().
 		BorderStyle(xpBorder).
@@ -1466,8 +2700,11 @@ func (m *Model) renderSidebar(width, height int) string {
 
 	xpPanel := xpStyle.
 		Width(width - 2).
-		Height(panelHeight).
-		Render(m.xpViewport.View())
+		Height(xpHeight).
+		Render(xpView.View())
+	if combatPanel != "" {
+		xpPanel = lipgloss.JoinVertical(lipgloss.Left, xpPanel, combatPanel)
+	}
 
 	// Inventory panel with scrollable viewport
 	var inventoryContent string
@@ -1475,7 +2712,7 @@ func (m *Model) renderSidebar(width, height int) string {
 	if len(m.inventory) > 0 {
 		timeStr := m.inventoryTime.Format("15:04:05")
 		inventoryTitle = "Inventory (" + timeStr + ")"
-		inventoryContent = strings.Join(m.inventory, "\n")
+		inventoryContent = strings.Join(m.inventoryLines(), "\n")
 	} else {
 		inventoryContent = emptyPanelStyle.Render("(not populated)")
 	}
@@ -1492,10 +2729,44 @@ func (m *Model) renderSidebar(width, height int) string {
 		PaddingLeft(1).
 		PaddingRight(1)
 
+	// Panels filled by trigger panel: actions share the inventory slot
+	inventoryHeight := panelHeight
+	userPanels := ""
+	if len(m.userPanels) > 0 && panelHeight >= 4 {
+		userPanels, inventoryHeight = m.renderUserPanels(width, panelHeight)
+	}
+
+	// So does the equipment once an equipment listing has been seen
+	equipmentPanel := ""
+	if len(m.equipment) > 0 && inventoryHeight >= 6 {
+		equipmentHeight := max(1, min(len(m.equipment), (inventoryHeight-1)/2))
+		inventoryHeight -= 1 + equipmentHeight
+		equipmentPanel = m.renderEquipmentPanel(width, equipmentHeight)
+	}
+	inventoryView := m.inventoryViewport
+	if inventoryHeight != panelHeight {
+		inventoryView.Height = inventoryHeight
+	}
+
 	inventoryPanel := inventoryStyle.
 		Width(width - 2).
-		Height(panelHeight).
-		Render(m.inventoryViewport.View())
+		Height(inventoryHeight).
+		Render(inventoryView.View())
+	if equipmentPanel != "" {
+		inventoryPanel = lipgloss.JoinVertical(lipgloss.Left, inventoryPanel, equipmentPanel)
+	}
+	if userPanels != "" {
+		inventoryPanel = lipgloss.JoinVertical(lipgloss.Left, inventoryPanel, userPanels)
+	}
+
+	// The trail shares the map slot when its panel is on
+	mapSlotHeight := panelHeight
+	trailPanel := ""
+	if m.settingsManager != nil && m.settingsManager.TrailPanel && m.trail != nil && len(m.trail.Steps) > 0 && panelHeight >= 6 {
+		trailHeight := max(1, min(len(m.trail.Steps), (panelHeight-1)/3))
+		mapSlotHeight = panelHeight - 1 - trailHeight
+		trailPanel = m.renderTrailPanel(width, trailHeight)
+	}
 
 	// Map panel
 	var mapContent string
@@ -1505,12 +2776,14 @@ func (m *Model) renderSidebar(width, height int) string {
 		mapContent = emptyPanelStyle.Render("(not implemented)")
 	} else {
 		currentRoom := m.worldMap.GetCurrentRoom()
-		if currentRoom == nil {
+		if m.locationUncertain {
+			mapContent = emptyPanelStyle.Render("(location uncertain)")
+		} else if currentRoom == nil {
 			mapContent = emptyPanelStyle.Render("(exploring...)")
 		} else {
 			mapTitle = currentRoom.Title
 			// Calculate available height for map content
-			mapHeight := panelHeight - 2
+			mapHeight := mapSlotHeight - 2
 			mapContent = m.worldMap.FormatMapPanelWithLegend(width-4, mapHeight, m.mapLegend)
 		}
 	}
@@ -1528,8 +2801,11 @@ func (m *Model) renderSidebar(width, height int) string {
 
 	mapPanel := mapStyle.
 		Width(width - 2).
-		Height(panelHeight).
+		Height(mapSlotHeight).
 		Render(mapContent)
+	if trailPanel != "" {
+		mapPanel = lipgloss.JoinVertical(lipgloss.Left, trailPanel, mapPanel)
+	}
 
 	return lipgloss.JoinVertical(
 		lipgloss.Left,
@@ -1540,6 +2816,265 @@ func (m *Model) renderSidebar(width, height int) string {
 	)
 }
 
+// userPanelMaxLines is how many lines a panel filled by trigger panel:
+// actions keeps
+const userPanelMaxLines = 50
+
+// parsePanelAction splits a trigger action of the form panel:<name>:<text>
+// into the panel name and the text to show in it
+func parsePanelAction(action string) (name, text string, ok bool) {
+	rest, found := strings.CutPrefix(action, triggers.PanelActionPrefix)
+	if !found {
+		return "", "", false
+	}
+	name, text, found = strings.Cut(rest, ":")
+	name = strings.TrimSpace(name)
+	if !found || name == "" {
+		return "", "", false
+	}
+	return name, strings.TrimSpace(text), true
+}
+
+// appendToUserPanel adds a line to a named sidebar panel, creating the panel
+// the first time it is used
+func (m *Model) appendToUserPanel(name, text string) {
+	if m.userPanels == nil {
+		m.userPanels = make(map[string][]string)
+	}
+	lines := append(m.userPanels[name], text)
+	if len(lines) > userPanelMaxLines {
+		lines = lines[len(lines)-userPanelMaxLines:]
+	}
+	m.userPanels[name] = lines
+}
+
+// renderUserPanels renders the panels filled by trigger panel: actions, in
+// name order with their newest lines, taking up to half of a sidebar slot.
+// It returns the panels and the height left for the slot's own panel.
+func (m *Model) renderUserPanels(width, slotHeight int) (string, int) {
+	names := make([]string, 0, len(m.userPanels))
+	for name := range m.userPanels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	// Each panel has a title line above its content
+	contentHeight := max(1, slotHeight/2/len(names)-1)
+	lineWidth := max(1, width-4)
+	var panels []string
+	remaining := slotHeight
+	for _, name := range names {
+		if remaining-(contentHeight+1) < 1 {
+			break
+		}
+		remaining -= contentHeight + 1
+
+		lines := m.userPanels[name]
+		if len(lines) > contentHeight {
+			lines = lines[len(lines)-contentHeight:]
+		}
+		shown := make([]string, len(lines))
+		for i, line := range lines {
+			if runes := []rune(line); len(runes) > lineWidth {
+				line = string(runes[:lineWidth])
+			}
+			shown[i] = line
+		}
+
+		panelBorder := createBorderWithTitle(name, width, "middle") // Middle panel uses T-junction corners
+		panelStyle := lipgloss.NewStyle().
+			BorderStyle(panelBorder).
+			BorderForeground(lipgloss.Color("62")).
+			BorderTop(true).
+			BorderLeft(true).
+			BorderRight(true).
+			BorderBottom(false).
+			PaddingLeft(1).
+			PaddingRight(1)
+
+		panels = append(panels, panelStyle.
+			Width(width-2).
+			Height(contentHeight).
+			Render(strings.Join(shown, "\n")))
+	}
+
+	return lipgloss.JoinVertical(lipgloss.Left, panels...), remaining
+}
+
+// renderTrailPanel renders the rooms visited most recently, newest first,
+// with the direction taken into each
+func (m *Model) renderTrailPanel(width, height int) string {
+	lineWidth := max(1, width-4)
+	lines := make([]string, 0, height)
+	for i := len(m.trail.Steps) - 1; i >= 0 && len(lines) < height; i-- {
+		line := m.formatTrailStep(m.trail.Steps[i])
+		if len(line) > lineWidth {
+			line = line[:lineWidth]
+		}
+		lines = append(lines, line)
+	}
+
+	trailBorder := createBorderWithTitle("Trail", width, "middle") // Middle panel uses T-junction corners
+	trailStyle := lipgloss.NewStyle().
+		BorderStyle(trailBorder).
+		BorderForeground(lipgloss.Color("62")).
+		BorderTop(true).
+		BorderLeft(true).
+		BorderRight(true).
+		BorderBottom(false).
+		PaddingLeft(1).
+		PaddingRight(1)
+
+	return trailStyle.
+		Width(width - 2).
+		Height(height).
+		Render(strings.Join(lines, "\n"))
+}
+
+// formatTrailStep describes a room on the trail by the direction taken into
+// it and its title
+func (m *Model) formatTrailStep(step mapper.TrailStep) string {
+	direction := step.Direction
+	if direction == "" {
+		direction = "?"
+	}
+	title := "(unknown room)"
+	if room := m.worldMap.Rooms[step.RoomID]; room != nil {
+		title = room.Title
+	}
+	return fmt.Sprintf("%-5s %s", direction, title)
+}
+
+// renderEquipmentPanel renders the worn items from the last equipment
+// listing, one "slot: item" line each
+func (m *Model) renderEquipmentPanel(width, height int) string {
+	lineWidth := max(1, width-4)
+	lines := make([]string, 0, height)
+	for _, worn := range m.equipment {
+		if len(lines) == height {
+			break
+		}
+		line := []rune(shortEquipmentSlot(worn.Slot) + ": " + worn.Item)
+		if len(line) > lineWidth {
+			line = line[:lineWidth]
+		}
+		lines = append(lines, string(line))
+	}
+
+	title := "Equipment (" + m.equipmentTime.Format("15:04:05") + ")"
+	equipmentBorder := createBorderWithTitle(title, width, "middle") // Middle panel uses T-junction corners
+	equipmentStyle := lipgloss.NewStyle().
+		BorderStyle(equipmentBorder).
+		BorderForeground(lipgloss.Color("62")).
+		BorderTop(true).
+		BorderLeft(true).
+		BorderRight(true).
+		BorderBottom(false).
+		PaddingLeft(1).
+		PaddingRight(1)
+
+	return equipmentStyle.
+		Width(width - 2).
+		Height(height).
+		Render(strings.Join(lines, "\n"))
+}
+
+// shortEquipmentSlot drops the wording DikuMUDs put before the body part,
+// so "worn on body" becomes "body" and "used as light" becomes "light"
+func shortEquipmentSlot(slot string) string {
+	for _, prefix := range []string{"worn on ", "worn around ", "worn about ", "worn as ", "used as "} {
+		if strings.HasPrefix(slot, prefix) {
+			return strings.TrimPrefix(slot, prefix)
+		}
+	}
+	return slot
+}
+
+// renderCombatPanel renders damage dealt and taken in the current fight
+func (m *Model) renderCombatPanel(width, height int) string {
+	fight := m.combatLog.Current
+	now := time.Now()
+
+	target := fight.Target
+	if target == "" {
+		target = "?"
+	}
+	elapsed := fight.Duration(now).Round(time.Second)
+	timeStr := fmt.Sprintf("%d:%02d", int(elapsed.Minutes()), int(elapsed.Seconds())%60)
+	targetWidth := max(1, width-4-len(timeStr)-4)
+	if len(target) > targetWidth {
+		target = target[:targetWidth]
+	}
+
+	lines := []string{
+		fmt.Sprintf("vs %-*s %s", targetWidth, target, timeStr),
+		"Dealt " + fight.Dealt.String(),
+		"Taken " + fight.Taken.String(),
+	}
+	if len(lines) > height {
+		lines = lines[:height]
+	}
+
+	combatBorder := createBorderWithTitle("Combat", width, "middle") // Middle panel uses T-junction corners
+	combatStyle := lipgloss.NewStyle().
+		BorderStyle(combatBorder).
+		BorderForeground(lipgloss.Color("62")).
+		BorderTop(true).
+		BorderLeft(true).
+		BorderRight(true).
+		BorderBottom(false).
+		PaddingLeft(1).
+		PaddingRight(1)
+
+	return combatStyle.
+		Width(width - 2).
+		Height(height).
+		Render(strings.Join(lines, "\n"))
+}
+
+// renderAffectsPanel renders active affects with the time left on each,
+// soonest to wear off first
+func (m *Model) renderAffectsPanel(width, height int) string {
+	active := make([]*affects.Affect, len(m.affectTracker.Affects))
+	copy(active, m.affectTracker.Affects)
+	sort.SliceStable(active, func(i, j int) bool {
+		if active[i].Permanent() != active[j].Permanent() {
+			return !active[i].Permanent()
+		}
+		return active[i].Expires.Before(active[j].Expires)
+	})
+
+	now := time.Now()
+	nameWidth := max(1, width-4-6)
+	lines := make([]string, 0, height)
+	for _, affect := range active {
+		if len(lines) == height {
+			break
+		}
+		name := affect.Name
+		if len(name) > nameWidth {
+			name = name[:nameWidth]
+		}
+		lines = append(lines, fmt.Sprintf("%-*s %5s", nameWidth, name, affect.FormatRemaining(now)))
+	}
+
+	affectsBorder := createBorderWithTitle("Affects", width, "middle") // Middle panel uses T-junction corners
+	affectsStyle := lipgloss.NewStyle().
+		BorderStyle(affectsBorder).
+		BorderForeground(lipgloss.Color("62")).
+		BorderTop(true).
+		BorderLeft(true).
+		BorderRight(true).
+		BorderBottom(false).
+		PaddingLeft(1).
+		PaddingRight(1)
+
+	return affectsStyle.
+		Width(width - 2).
+		Height(height).
+		Render(strings.Join(lines, "\n"))
+}
+
 func max(a, b int) int {
 	if a > b {
 		return a
@@ -1565,19 +3100,23 @@ func (m *Model) savePasswordForWebClient(password string) {
 		return
 	}
 
-	// FIFO path - TUI runs inside .websessions/<sessionID> so just use relative path
-	fifoPath := "./.password_hint_fifo"
-
 	// Determine account key from current connection
 	account := fmt.Sprintf("%s:%d:%s", m.host, m.port, m.username)
 
-	hint := map[string]string{
+	writeWebClientHint(map[string]string{
 		"account":  account,
 		"password": password, // Empty password means delete
-	}
+	})
+}
 
-	hintJSON, err := json.Marshal(hint)
-	if err != nil {
+// writeWebClientHint writes a JSON hint to the FIFO read by the web server,
+// which forwards it to the browser over the data WebSocket
+func writeWebClientHint(hint map[string]string) {
+	// FIFO path - TUI runs inside .websessions/<sessionID> so just use relative path
+	fifoPath := "./.password_hint_fifo"
+
+	hintJSON, err := json.Marshal(hint)
+	if err != nil {
 		return
 	}
 
@@ -1614,7 +3153,7 @@ func (m *Model) detectAndUpdateRoom() {
 				m.barsoomMode = true
 				m.worldMap.BarsoomMode = true // Persist Barsoom mode in map
 				if m.mapDebug {
-					m.output = append(m.output, "\x1b[92m[Mapper: Switched to Barsoom room parsing mode]\x1b[0m")
+					m.output = append(m.output, m.colors().Info.Render("[Mapper: Switched to Barsoom room parsing mode]"))
 				}
 			}
 			
@@ -1685,29 +3224,43 @@ func (m *Model) detectAndUpdateRoom() {
 			m.skipNextRoomDetection = false
 			m.pendingMovement = "" // Clear pending movement
 			if m.mapDebug {
-				m.output = append(m.output, "\x1b[90m[Mapper: Skipped room detection due to recall]\x1b[0m")
+				m.output = append(m.output, m.colors().Debug.Render("[Mapper: Skipped room detection due to recall]"))
 			}
 			return
 		}
 
+		if m.relocalizePending {
+			m.relocalizePending = false
+			if m.relocalize(barsoomRoomInfo.Title, barsoomRoomInfo.Description, barsoomRoomInfo.Exits) {
+				m.pendingMovement = ""
+				return
+			}
+			// A room we haven't mapped yet: add it without linking it to the
+			// saved position
+			m.locationUncertain = true
+		}
+
 		// Create or update room in map (use full description for Barsoom rooms)
 		// Always add the current room to the map when we see it
 		room := mapper.NewBarsoomRoom(barsoomRoomInfo.Title, barsoomRoomInfo.Description, barsoomRoomInfo.Exits)
 
 		// Set the movement direction if we have a pending movement (for linking)
-		if m.pendingMovement != "" {
+		if m.locationUncertain {
+			// We don't know where we came from, so don't link rooms
+			m.worldMap.SetLastDirection("")
+			m.pendingMovement = ""
+			m.locationUncertain = false
+		} else if m.pendingMovement != "" {
 			m.worldMap.SetLastDirection(m.pendingMovement)
 			m.pendingMovement = ""
 		}
 
-		m.worldMap.AddOrUpdateRoom(room)
-
-		// Save map periodically (every room visit)
-		m.worldMap.Save()
+		m.enterRoom(room)
+		m.mapChanged()
 
 		// Notify user that room was added (only if debug enabled)
 		if m.mapDebug {
-			m.output = append(m.output, fmt.Sprintf("\x1b[92m[Mapper: Added room '%s' with exits: %v]\x1b[0m", room.Title, barsoomRoomInfo.Exits))
+			m.output = append(m.output, m.colors().Info.Render(fmt.Sprintf("[Mapper: Added room '%s' with exits: %v]", room.Title, barsoomRoomInfo.Exits)))
 		}
 		return
 	}
@@ -1722,14 +3275,17 @@ func (m *Model) detectAndUpdateRoom() {
 		return
 	}
 	
-	// For non-Barsoom rooms, only detect when we have a pending movement
+	// For non-Barsoom rooms, only detect when we have a pending movement or
+	// are looking for the room we logged in to
 	if m.pendingMovement == "" {
 		// Clear description split if no Barsoom room
 		m.hasDescriptionSplit = false
 		m.currentRoomDescription = ""
 		m.currentBarsoomTitle = ""
 		m.currentBarsoomExits = nil
-		return
+		if !m.relocalizePending {
+			return
+		}
 	}
 
 	// Skip room detection if flag is set (e.g., after recall teleport)
@@ -1737,20 +3293,20 @@ func (m *Model) detectAndUpdateRoom() {
 		m.skipNextRoomDetection = false
 		m.pendingMovement = "" // Clear pending movement
 		if m.mapDebug {
-			m.output = append(m.output, "\x1b[90m[Mapper: Skipped room detection due to recall]\x1b[0m")
+			m.output = append(m.output, m.colors().Debug.Render("[Mapper: Skipped room detection due to recall]"))
 		}
 		return
 	}
 
 	// Try to parse room info from recent output (non-Barsoom)
-	roomInfo := mapper.ParseRoomInfo(m.recentOutput, m.mapDebug)
+	roomInfo := mapper.ParseRoomInfoWithPrompt(m.recentOutput, m.mapDebug, m.isPromptLine)
 
 	// Only display debug info if mapDebug flag is enabled
 	if m.mapDebug && roomInfo != nil && roomInfo.DebugInfo != "" {
 		// Add debug info to output
 		debugLines := strings.Split(strings.TrimSpace(roomInfo.DebugInfo), "\n")
 		for _, line := range debugLines {
-			m.output = append(m.output, "\x1b[90m"+line+"\x1b[0m") // Gray color for debug
+			m.output = append(m.output, m.colors().Debug.Render(line))
 		}
 	}
 
@@ -1764,21 +3320,79 @@ func (m *Model) detectAndUpdateRoom() {
 	m.currentBarsoomTitle = ""
 	m.currentBarsoomExits = nil
 
+	if m.relocalizePending {
+		if m.relocalize(roomInfo.Title, roomInfo.Description, roomInfo.Exits) {
+			m.relocalizePending = false
+			m.pendingMovement = ""
+			return
+		}
+		if m.pendingMovement == "" {
+			// Without a movement this may not be a room at all, so keep
+			// looking rather than adding it
+			return
+		}
+		// A room we haven't mapped yet: add it without linking it to the
+		// saved position
+		m.relocalizePending = false
+		m.locationUncertain = true
+	}
+
 	// Create or update room in map
 	room := mapper.NewRoom(roomInfo.Title, roomInfo.Description, roomInfo.Exits)
 
-	// Set the movement direction
-	m.worldMap.SetLastDirection(m.pendingMovement)
+	// Set the movement direction (unless a forced move left our position unknown)
+	if m.locationUncertain {
+		m.worldMap.SetLastDirection("")
+		m.locationUncertain = false
+	} else {
+		m.worldMap.SetLastDirection(m.pendingMovement)
+	}
 	m.pendingMovement = ""
 
-	m.worldMap.AddOrUpdateRoom(room)
-
-	// Save map periodically (every room visit)
-	m.worldMap.Save()
+	m.enterRoom(room)
+	m.mapChanged()
 
 	// Notify user that room was added (only if debug enabled)
 	if m.mapDebug {
-		m.output = append(m.output, fmt.Sprintf("\x1b[92m[Mapper: Added room '%s' with exits: %v]\x1b[0m", room.Title, roomInfo.Exits))
+		m.output = append(m.output, m.colors().Info.Render(fmt.Sprintf("[Mapper: Added room '%s' with exits: %v]", room.Title, roomInfo.Exits)))
+	}
+}
+
+// relocalize sets the position to the known room matching the one we logged
+// in to, reporting whether one was found
+func (m *Model) relocalize(title, description string, exits []string) bool {
+	room := m.worldMap.Relocalize(title, description, exits)
+	if room == nil {
+		return false
+	}
+	m.locationUncertain = false
+	m.recordTrail("")
+	m.mapChanged()
+
+	if m.mapDebug {
+		m.output = append(m.output, m.colors().Info.Render(fmt.Sprintf("[Mapper: Relocalized to room %d '%s']", m.worldMap.GetRoomNumber(room.ID), room.Title)))
+	}
+	return true
+}
+
+// detectForcedMovement marks the position as uncertain when the server moves
+// us without a direction command (fleeing, teleports, being dragged)
+func (m *Model) detectForcedMovement(cleanLine string) {
+	if !mapper.DetectForcedMovement(cleanLine) {
+		return
+	}
+
+	m.locationUncertain = true
+	m.pendingMovement = ""
+
+	// Our remaining auto-walk path no longer starts from where we are
+	if m.autoWalking {
+		m.stopCommandQueue()
+		m.output = append(m.output, m.colors().Warn.Render("[Auto-walk: Stopped - position lost after forced movement]"))
+	}
+
+	if m.mapDebug {
+		m.output = append(m.output, m.colors().Debug.Render("[Mapper: Detected forced movement - location uncertain until next room]"))
 	}
 }
 
@@ -1788,8 +3402,14 @@ func (m *Model) detectAndUpdateInventory() {
 		return // Need at least a few lines to detect inventory
 	}
 
+	// Worn items come from the equipment listing, which has its own header
+	if equipment := mapper.ParseEquipmentWithPrompt(m.recentOutput, m.isPromptLine); equipment != nil {
+		m.equipment = equipment
+		m.equipmentTime = time.Now()
+	}
+
 	// Try to parse inventory info from recent output
-	invInfo := mapper.ParseInventoryInfo(m.recentOutput, false)
+	invInfo := mapper.ParseInventoryInfoWithPrompt(m.recentOutput, false, m.isPromptLine)
 
 	if invInfo == nil {
 		return // No valid inventory detected
@@ -1806,7 +3426,7 @@ var tellRegex = regexp.MustCompile(`^(.+?) tells you '(.*)'$`)
 // detectAndParseTell tries to detect and parse a tell message from a line
 func (m *Model) detectAndParseTell(line string) {
 	// Strip ANSI codes for pattern matching
-	cleanLine := stripANSI(line)
+	cleanLine := ansi.Strip(line)
 
 	matches := tellRegex.FindStringSubmatch(cleanLine)
 	if matches == nil || len(matches) != 3 {
@@ -1815,6 +3435,12 @@ func (m *Model) detectAndParseTell(line string) {
 
 	player := matches[1]
 	content := matches[2]
+	if m.mutes().IsMuted(player) {
+		return
+	}
+
+	// Remember where we were when the player got in touch, for /whereis
+	m.recordSighting(player, time.Now())
 
 	// Format as "Player: content" for the tells panel
 	tellEntry := fmt.Sprintf("%s: %s", player, content)
@@ -1826,10 +3452,60 @@ func (m *Model) detectAndParseTell(line string) {
 	}
 }
 
-// stripANSI removes ANSI escape codes from a string
-func stripANSI(s string) string {
-	ansiRegex := regexp.MustCompile(`\x1b\[[0-9;]*m`)
-	return ansiRegex.ReplaceAllString(s, "")
+// mutes returns the account's list of muted players, loading it when first
+// needed
+func (m *Model) mutes() *mutes.List {
+	if m.muteList == nil {
+		muteList, err := mutes.Load(m.host, m.port, m.username)
+		if err != nil {
+			muteList = mutes.NewList()
+		}
+		m.muteList = muteList
+	}
+	return m.muteList
+}
+
+// isMutedLine reports whether a line is a tell, say or channel message from
+// a muted player
+func (m *Model) isMutedLine(cleanLine string) bool {
+	sender := mutes.Sender(cleanLine)
+	return sender != "" && m.mutes().IsMuted(sender)
+}
+
+// sightingRegex matches lines showing a player in the room: a say, an
+// arrival or the player standing (or resting, ...) here. Player names are a
+// single capitalized word, which leaves out mobs like "The guard".
+var sightingRegex = regexp.MustCompile(`^([A-Z][A-Za-z]+) (?:says '|has arrived\.|is (?:standing|sitting|resting|sleeping|fighting .+) here)`)
+
+// playerNameRegex matches a name that can be a player's
+var playerNameRegex = regexp.MustCompile(`^[A-Z][A-Za-z]+$`)
+
+// parseSighting returns the name of a player a line shows in the room
+func parseSighting(cleanLine string) string {
+	matches := sightingRegex.FindStringSubmatch(strings.TrimSpace(cleanLine))
+	if matches == nil || matches[1] == "Someone" {
+		return ""
+	}
+	return matches[1]
+}
+
+// recordSighting remembers that a player was seen in the current room
+func (m *Model) recordSighting(name string, now time.Time) {
+	if !playerNameRegex.MatchString(name) || name == "Someone" {
+		return
+	}
+	if m.sightings == nil {
+		m.sightings = make(map[string]*playerSighting)
+	}
+
+	sighting := &playerSighting{Name: name, Time: now}
+	if m.worldMap != nil {
+		if room := m.worldMap.GetCurrentRoom(); room != nil {
+			sighting.RoomID = room.ID
+			sighting.RoomTitle = room.Title
+		}
+	}
+	m.sightings[strings.ToLower(name)] = sighting
 }
 
 // combatPromptRegex matches combat prompts in format: [Hero:Status] [Target:Status]
@@ -1852,7 +3528,7 @@ func (m *Model) detectTickPrompt(line string) {
 		return
 	}
 
-	cleanLine := stripANSI(line)
+	cleanLine := ansi.Strip(line)
 	matches := tickPromptRegex.FindStringSubmatch(cleanLine)
 	if matches != nil && len(matches) == 2 {
 		// matches[1] is the tick time (e.g., "24")
@@ -1871,1306 +3547,8202 @@ func (m *Model) detectTickPrompt(line string) {
 	}
 }
 
-// detectCombatPrompt detects combat status in the prompt
-func (m *Model) detectCombatPrompt(line string) {
-	cleanLine := stripANSI(line)
-	matches := combatPromptRegex.FindStringSubmatch(cleanLine)
-	if matches != nil && len(matches) == 3 {
-		// matches[1] is the hero name, matches[2] is the target name
-		target := strings.ToLower(strings.TrimSpace(matches[2]))
-
-		// Only start tracking if we don't have a pending kill or if this is a new target
-		if m.pendingKill == "" || m.pendingKill != target {
-			m.pendingKill = target
-			// note the pending kill to the client
-			m.output = append(m.output, fmt.Sprintf("\x1b[90m[XP Tracker: Pending kill on '%s']\x1b[0m\n", target))
+// detectAffects feeds a line to the affects tracker
+func (m *Model) detectAffects(line string) {
+	if m.affectTracker == nil {
+		m.affectTracker = affects.NewTracker()
+	}
+	m.affectTracker.ProcessLine(ansi.Strip(line), time.Now(), m.affectHourLength())
+}
 
-			m.killTime = time.Now()
-		}
+// detectIdentify feeds a line to the item database, saving it when the
+// stats of an identified item have been read
+func (m *Model) detectIdentify(cleanLine string, isPrompt bool) {
+	if m.itemManager == nil {
+		m.itemManager = items.NewManager()
+	}
+	item := m.itemManager.ProcessLine(cleanLine, isPrompt, time.Now())
+	if item == nil {
+		return
+	}
+	m.output = append(m.output, m.colors().Debug.Render(fmt.Sprintf("[Item: remembered stats for %s - /stat to recall]", item.Name)))
+	if err := m.itemManager.Save(); err != nil {
+		m.output = append(m.output, m.colors().Error.Render(fmt.Sprintf("Error saving items: %v", err)))
 	}
 }
 
-// detectXPEvents detects death messages and XP gains to calculate XP/s
-func (m *Model) detectXPEvents(line string) {
-	cleanLine := stripANSI(line)
+// detectWealth feeds a line to the wealth tracker
+func (m *Model) detectWealth(cleanLine string, isPrompt bool) {
+	if m.wealthTracker == nil {
+		m.wealthTracker = wealth.NewTracker()
+	}
+	m.wealthTracker.ProcessLine(cleanLine, isPrompt, time.Now())
+}
 
-	// Check for death message
-	if m.pendingKill != "" {
-		matches := deathMessageRegex.FindStringSubmatch(cleanLine)
-		if matches != nil && len(matches) == 3 {
-			// matches[1] is the article (The/A/An), matches[2] is the creature name
-			creatureName := strings.ToLower(strings.TrimSpace(matches[2]))
-			// Check if this matches our pending kill
-			if strings.Contains(creatureName, m.pendingKill) {
-				// Store the death time, but don't finalize yet - wait for XP gain
-				m.pendingKill = creatureName
-			}
+// levels returns the log of levels gained, loading it when first needed
+func (m *Model) levels() *levels.Log {
+	if m.levelLog == nil {
+		levelLog, err := levels.Load()
+		if err != nil {
+			levelLog = levels.NewLog()
 		}
+		m.levelLog = levelLog
 	}
+	return m.levelLog
+}
 
-	// Check for XP gain
-	if m.pendingKill != "" {
-		matches := xpGainRegex.FindStringSubmatch(cleanLine)
-		if matches != nil && len(matches) == 2 {
-			xp := 0
-			fmt.Sscanf(matches[1], "%d", &xp)
+// detectLevelUp records a level gained, with the session's experience so
+// far, in the level log
+func (m *Model) detectLevelUp(cleanLine string, now time.Time) {
+	level, ok := levels.ParseLevelUp(cleanLine)
+	if !ok {
+		return
+	}
 
-			// Calculate time elapsed
-			deathTime := time.Now()
-			seconds := deathTime.Sub(m.killTime).Seconds()
+	levelLog := m.levels()
+	recorded := len(levelLog.Milestones)
+	milestone := levelLog.Record(level, now, m.sessionXP)
+	if err := levelLog.Save(); err != nil {
+		m.output = append(m.output, m.colors().Error.Render(fmt.Sprintf("Error saving level log: %v", err)))
+	}
+	if len(levelLog.Milestones) > recorded && milestone.Level != 0 {
+		m.output = append(m.output, m.colors().Info.Render(fmt.Sprintf("[Level %d reached - /levels shows your progress]", milestone.Level)))
+	}
+}
 
-			// Calculate XP/s
-			xpPerSecond := 0.0
-			if seconds > 0 {
-				xpPerSecond = float64(xp) / seconds
-			}
+// formatHoursMinutes formats a duration as hours and minutes, like 2h05m
+func formatHoursMinutes(d time.Duration) string {
+	d = d.Round(time.Minute)
+	return fmt.Sprintf("%dh%02dm", int(d.Hours()), int(d.Minutes())%60)
+}
 
-			// Store in current session tracking
-			m.xpTracking[m.pendingKill] = &XPStat{
-				CreatureName: m.pendingKill,
-				XP:           xp,
-				Seconds:      seconds,
-				XPPerSecond:  xpPerSecond,
-			}
+// levelsShown is how many of the latest levels /levels lists
+const levelsShown = 10
 
-			// Update persistent stats with EMA
-			if m.xpStatsManager != nil {
-				m.xpStatsManager.UpdateStat(m.pendingKill, xpPerSecond)
-				// Save to disk (ignore errors to not disrupt gameplay)
-				_ = m.xpStatsManager.Save()
-			}
+// handleStopwatchCommand starts, laps and stops the stopwatch, or shows the
+// time and laps so far
+func (m *Model) handleStopwatchCommand(args []string, now time.Time) {
+	if len(args) > 1 {
+		m.output = append(m.output, m.colors().Error.Render("Usage: /stopwatch [start|lap|stop]"))
+		return
+	}
 
-			// Clear pending kill
-			m.output = append(m.output, fmt.Sprintf("\x1b[90m[XP Tracker: Recorded kill on '%s' - %d XP in %.1f seconds (%.2f XP/s)]\x1b[0m\n", m.pendingKill, xp, seconds, xpPerSecond))
-			m.pendingKill = ""
+	subcommand := ""
+	if len(args) == 1 {
+		subcommand = strings.ToLower(args[0])
+	}
+	switch subcommand {
+	case "start":
+		restarted := m.stopwatch.Running()
+		m.stopwatch.Start(now)
+		if restarted {
+			m.output = append(m.output, m.colors().Info.Render("Stopwatch restarted from 0:00.0."))
+		} else {
+			m.output = append(m.output, m.colors().Info.Render("Stopwatch started."))
+		}
+	case "lap":
+		lap, total, ok := m.stopwatch.Lap(now)
+		if !ok {
+			m.output = append(m.output, m.colors().Warn.Render("The stopwatch isn't running. Use /stopwatch start."))
+			return
+		}
+		m.output = append(m.output, m.colors().Info.Render(fmt.Sprintf("Lap %d: %s  (total %s)", len(m.stopwatch.Laps()), stopwatch.Format(lap), stopwatch.Format(total))))
+	case "stop":
+		total, lastLap, ok := m.stopwatch.Stop(now)
+		if !ok {
+			m.output = append(m.output, m.colors().Warn.Render("The stopwatch isn't running. Use /stopwatch start."))
+			return
 		}
+		m.output = append(m.output, m.colors().Info.Render(fmt.Sprintf("Stopwatch stopped at %s.", stopwatch.Format(total))))
+		if laps := m.stopwatch.Laps(); len(laps) > 0 {
+			m.showStopwatchLaps(append(laps, lastLap))
+		}
+	case "":
+		if m.stopwatch.Elapsed(now) == 0 {
+			m.output = append(m.output, m.colors().Info.Render("The stopwatch hasn't been started. Use /stopwatch start."))
+			return
+		}
+		state := "running"
+		if !m.stopwatch.Running() {
+			state = "stopped"
+		}
+		m.output = append(m.output, m.colors().Info.Render(fmt.Sprintf("Stopwatch %s: %s", state, stopwatch.Format(m.stopwatch.Elapsed(now)))))
+		m.showStopwatchLaps(m.stopwatch.Laps())
+	default:
+		m.output = append(m.output, m.colors().Error.Render("Usage: /stopwatch [start|lap|stop]"))
 	}
 }
 
-// handleClientCommand processes client-side commands starting with /
-func (m *Model) handleClientCommand(command string) tea.Cmd {
-	command = strings.TrimSpace(command)
-	if !strings.HasPrefix(command, "/") {
-		return nil
+// showStopwatchLaps lists lap times, marking the fastest
+func (m *Model) showStopwatchLaps(laps []time.Duration) {
+	fastest := 0
+	for i, lap := range laps {
+		if lap < laps[fastest] {
+			fastest = i
+		}
+	}
+	for i, lap := range laps {
+		line := fmt.Sprintf("  Lap %d: %s", i+1, stopwatch.Format(lap))
+		if i == fastest && len(laps) > 1 {
+			line += "  " + m.colors().Highlight.Render("(fastest)")
+		}
+		m.output = append(m.output, line)
 	}
+}
 
-	// Remove the leading /
-	command = strings.TrimPrefix(command, "/")
-	parts := strings.Fields(command)
+// handleLevelsCommand lists the levels gained with the time each took, the
+// experience rate this session and an estimate of the time to the next level
+func (m *Model) handleLevelsCommand(args []string) {
+	if len(args) > 0 {
+		if strings.ToLower(args[0]) != "clear" || len(args) > 1 {
+			m.output = append(m.output, m.colors().Error.Render("Usage: /levels [clear]"))
+			return
+		}
+		levelLog := m.levels()
+		levelLog.Milestones = nil
+		if err := levelLog.Save(); err != nil {
+			m.output = append(m.output, m.colors().Error.Render(fmt.Sprintf("Error saving level log: %v", err)))
+			return
+		}
+		m.output = append(m.output, m.colors().Info.Render("Level log cleared."))
+		return
+	}
 
-	if len(parts) == 0 {
-		m.output = append(m.output, "\x1b[91mError: Empty command\x1b[0m")
-		return nil
+	now := time.Now()
+	milestones := m.levels().Milestones
+	m.output = append(m.output, m.colors().Info.Render("=== Levels ==="))
+	if len(milestones) == 0 {
+		m.output = append(m.output, m.colors().Warn.Render("No levels gained yet. Messages such as 'You raise a level! You are now level 25.' are recorded."))
 	}
 
-	cmd := strings.ToLower(parts[0])
-	args := parts[1:]
+	start := len(milestones) - levelsShown
+	if start < 0 {
+		start = 0
+	}
+	for i := start; i < len(milestones); i++ {
+		milestone := milestones[i]
+		level := "?"
+		if milestone.Level != 0 {
+			level = fmt.Sprintf("%d", milestone.Level)
+		}
+		line := fmt.Sprintf("  Level %s  %s  %d XP this session", m.colors().Highlight.Render(level), milestone.Time.Format("2006-01-02 15:04"), milestone.SessionXP)
+		if i > 0 {
+			line += fmt.Sprintf("  (took %s)", formatHoursMinutes(milestone.Time.Sub(milestones[i-1].Time)))
+		}
+		m.output = append(m.output, line)
+	}
 
-	// Clear map legend unless we're executing nearby or legend commands
-	if cmd != "nearby" && cmd != "legend" {
-		m.mapLegend = nil
-		m.mapLegendRooms = nil
+	if m.sessionXP > 0 {
+		rate := ""
+		if hours := now.Sub(m.sessionXPStart).Hours(); hours > 0 {
+			rate = fmt.Sprintf(" (%.0f XP/hour)", float64(m.sessionXP)/hours)
+		}
+		m.output = append(m.output, fmt.Sprintf("  This session: %s%s", m.colors().Highlight.Render(fmt.Sprintf("%d XP", m.sessionXP)), rate))
+	}
+	if average := m.levels().AverageTime(3); average > 0 {
+		next := milestones[len(milestones)-1].Time.Add(average).Sub(now)
+		estimate := fmt.Sprintf("about %s", formatHoursMinutes(next))
+		if next <= 0 {
+			estimate = "any time now"
+		}
+		m.output = append(m.output, fmt.Sprintf("  Recent levels took %s each; next level in %s", m.colors().Highlight.Render(formatHoursMinutes(average)), estimate))
 	}
+}
 
-	switch cmd {
-	case "point":
-		m.handlePointCommand(args)
-		return nil
-	case "wayfind":
-		m.handleWayfindCommand(args)
-		return nil
-	case "map":
-		m.handleMapCommand(args)
-		return nil
-	case "rooms":
-		m.handleRoomsCommand(args)
-		return nil
-	case "nearby":
-		m.handleNearbyCommand()
-		return nil
-	case "legend":
-		m.handleLegendCommand()
-		return nil
-	case "go":
-		return m.handleGoCommand(args)
-	case "stop":
-		m.handleStopCommand()
-		return nil
-	case "trigger":
-		m.handleTriggerCommand(command)
-		return nil
-	case "triggers":
-		m.handleTriggersCommand(args)
-		return nil
-	case "alias":
-		m.handleAliasCommand(command)
-		return nil
-	case "aliases":
-		m.handleAliasesCommand(args)
-		return nil
-	case "ticktrigger":
-		m.handleTickTriggerCommand(command)
-		return nil
-	case "ticktriggers":
-		m.handleTickTriggersCommand(args)
-		return nil
-	case "share":
-		m.handleShareCommand()
-		return nil
-	case "help":
-		m.handleHelpCommand(args)
-		return nil
-	default:
-		m.output = append(m.output, fmt.Sprintf("\x1b[91mError: Unknown command '/%s'. Type /help for available commands.\x1b[0m", cmd))
-		return nil
+// inventoryLines returns the inventory for the panel, marking items whose
+// stats are remembered
+func (m *Model) inventoryLines() []string {
+	if m.itemManager == nil {
+		return m.inventory
+	}
+	lines := make([]string, len(m.inventory))
+	for i, line := range m.inventory {
+		lines[i] = line
+		if m.itemManager.Get(line) != nil {
+			lines[i] += " ✓"
+		}
 	}
+	return lines
 }
 
-// handlePointCommand shows the next direction to reach a destination
-func (m *Model) handlePointCommand(args []string) {
+// handleWhereisCommand reports where a player was last seen, or lists every
+// player seen this session
+func (m *Model) handleWhereisCommand(args []string) {
+	now := time.Now()
+
 	if len(args) == 0 {
-		m.output = append(m.output, "\x1b[91mUsage: /point <room search terms> or /point <number> [search terms]\x1b[0m")
+		if len(m.sightings) == 0 {
+			m.output = append(m.output, m.colors().Warn.Render("No players seen yet. Players are noted when they send you a tell or you see them in a room."))
+			return
+		}
+
+		sightings := make([]*playerSighting, 0, len(m.sightings))
+		for _, sighting := range m.sightings {
+			sightings = append(sightings, sighting)
+		}
+		sort.Slice(sightings, func(i, j int) bool {
+			return sightings[i].Time.After(sightings[j].Time)
+		})
+
+		m.output = append(m.output, m.colors().Info.Render("=== Players Last Seen ==="))
+		for _, sighting := range sightings {
+			m.output = append(m.output, fmt.Sprintf("  %s - %s", m.colors().Highlight.Render(sighting.Name), m.describeSighting(sighting, now)))
+		}
 		return
 	}
 
-	var rooms []*mapper.Room
-	var query string
+	sighting, ok := m.sightings[strings.ToLower(args[0])]
+	if !ok {
+		m.output = append(m.output, m.colors().Warn.Render(fmt.Sprintf("Haven't seen %s this session.", args[0])))
+		return
+	}
+	m.output = append(m.output, m.colors().Info.Render(fmt.Sprintf("%s was %s.", sighting.Name, m.describeSighting(sighting, now))))
+}
 
-	// Check if first argument is a number for room selection
-	if roomNum, err := fmt.Sscanf(args[0], "%d", new(int)); err == nil && roomNum == 1 {
-		var index int
-		fmt.Sscanf(args[0], "%d", &index)
+// handleMuteCommand hides a player's tells, says and channel messages, or
+// lists the muted players
+func (m *Model) handleMuteCommand(args []string) {
+	muteList := m.mutes()
 
-		// If only a number is provided, use lastRoomSearch
-		if len(args) == 1 {
-			if len(m.lastRoomSearch) == 0 {
-				m.output = append(m.output, "\x1b[91mNo previous room search to select from. Use /rooms to see all rooms.\x1b[0m")
-				return
-			}
-			if index < 1 || index > len(m.lastRoomSearch) {
-				m.output = append(m.output, fmt.Sprintf("\x1b[91mInvalid room number. Must be between 1 and %d.\x1b[0m", len(m.lastRoomSearch)))
-				return
-			}
-			rooms = []*mapper.Room{m.lastRoomSearch[index-1]}
-		} else {
-			// Number followed by search terms - search first, then select by index
-			query = strings.Join(args[1:], " ")
-			allMatches := m.worldMap.FindRooms(query)
-
-			if len(allMatches) == 0 {
-				m.output = append(m.output, fmt.Sprintf("\x1b[91mNo rooms found matching '%s'\x1b[0m", query))
-				return
-			}
+	if len(args) == 0 || strings.ToLower(args[0]) == "list" {
+		if len(muteList.Players) == 0 {
+			m.output = append(m.output, m.colors().Warn.Render("No players muted."))
+			m.output = append(m.output, m.colors().Warn.Render("Use /mute <player> to hide a player's tells and says."))
+			return
+		}
+		m.output = append(m.output, m.colors().Info.Render("=== Muted Players ==="))
+		for _, player := range muteList.Players {
+			m.output = append(m.output, "  "+m.colors().Highlight.Render(player))
+		}
+		return
+	}
 
-			if index < 1 || index > len(allMatches) {
-				m.output = append(m.output, fmt.Sprintf("\x1b[91mInvalid room number. Found %d rooms matching '%s'. Must be between 1 and %d.\x1b[0m", len(allMatches), query, len(allMatches)))
-				return
-			}
+	player := args[0]
+	if !muteList.Add(player) {
+		m.output = append(m.output, m.colors().Warn.Render(fmt.Sprintf("%s is already muted.", player)))
+		return
+	}
+	if err := muteList.Save(); err != nil {
+		m.output = append(m.output, m.colors().Error.Render(fmt.Sprintf("Error saving mute list: %v", err)))
+		return
+	}
 
-			rooms = []*mapper.Room{allMatches[index-1]}
-			m.lastRoomSearch = allMatches
+	// Tells already in the panel go too
+	tells := m.tells[:0]
+	for _, tell := range m.tells {
+		if sender, _, ok := strings.Cut(tell, ": "); !ok || !strings.EqualFold(sender, player) {
+			tells = append(tells, tell)
 		}
-	} else {
-		// Regular search without numeric selection
-		query = strings.Join(args, " ")
-		rooms = m.worldMap.FindRooms(query)
 	}
+	m.tells = tells
 
-	if len(rooms) == 0 {
-		m.output = append(m.output, fmt.Sprintf("\x1b[91mNo rooms found matching '%s'\x1b[0m", query))
+	m.output = append(m.output, m.colors().Info.Render(fmt.Sprintf("Muted %s. Their tells and says are still logged.", player)))
+}
+
+// handleUnmuteCommand shows a muted player's messages again
+func (m *Model) handleUnmuteCommand(args []string) {
+	if len(args) == 0 {
+		m.output = append(m.output, m.colors().Error.Render("Usage: /unmute <player>"))
 		return
 	}
 
-	if len(rooms) > 1 {
-		// Store results for later disambiguation
-		m.lastRoomSearch = rooms
+	player := args[0]
+	muteList := m.mutes()
+	if !muteList.Remove(player) {
+		m.output = append(m.output, m.colors().Warn.Render(fmt.Sprintf("%s is not muted.", player)))
+		return
+	}
+	if err := muteList.Save(); err != nil {
+		m.output = append(m.output, m.colors().Error.Render(fmt.Sprintf("Error saving mute list: %v", err)))
+		return
+	}
+	m.output = append(m.output, m.colors().Info.Render(fmt.Sprintf("Unmuted %s.", player)))
+}
 
-		m.output = append(m.output, fmt.Sprintf("\x1b[93mFound %d rooms matching '%s':\x1b[0m", len(rooms), query))
-		for i, room := range rooms {
-			if i >= 5 {
-				m.output = append(m.output, fmt.Sprintf("  \x1b[90m... and %d more\x1b[0m", len(rooms)-5))
-				break
+// describeSighting says where and how long ago a player was seen, e.g.
+// "last seen in Temple Square 3 minutes ago"
+func (m *Model) describeSighting(sighting *playerSighting, now time.Time) string {
+	where := "in an unmapped room"
+	if sighting.RoomTitle != "" {
+		where = "in " + sighting.RoomTitle
+		if m.worldMap != nil {
+			if number := m.worldMap.GetRoomNumber(sighting.RoomID); number > 0 {
+				where += fmt.Sprintf(" (#%d)", number)
 			}
-			m.output = append(m.output, fmt.Sprintf("  \x1b[96m%d. %s\x1b[0m", i+1, room.Title))
 		}
-		m.output = append(m.output, "\x1b[93mPlease be more specific, or use /point <number> to select a room.\x1b[0m")
-		return
 	}
+	return fmt.Sprintf("last seen %s %s", where, formatAgo(now.Sub(sighting.Time)))
+}
 
-	// Find path to the room
-	targetRoom := rooms[0]
-	path := m.worldMap.FindPath(targetRoom.ID)
+// formatAgo describes how long ago something happened, in the largest whole
+// unit
+func formatAgo(d time.Duration) string {
+	plural := func(n int, unit string) string {
+		if n == 1 {
+			return fmt.Sprintf("1 %s ago", unit)
+		}
+		return fmt.Sprintf("%d %ss ago", n, unit)
+	}
 
-	if path == nil {
-		m.output = append(m.output, fmt.Sprintf("\x1b[91mNo path found to '%s'\x1b[0m", targetRoom.Title))
+	switch {
+	case d < 10*time.Second:
+		return "just now"
+	case d < time.Minute:
+		return plural(int(d/time.Second), "second")
+	case d < time.Hour:
+		return plural(int(d/time.Minute), "minute")
+	default:
+		return plural(int(d/time.Hour), "hour")
+	}
+}
+
+// handleStatCommand lists remembered items or shows one item's stats
+func (m *Model) handleStatCommand(args []string) {
+	if m.itemManager == nil {
+		m.itemManager = items.NewManager()
+	}
+
+	if len(args) == 0 {
+		if len(m.itemManager.Items) == 0 {
+			m.output = append(m.output, m.colors().Warn.Render("No items remembered yet. Identify an item, or use /remember after examining one."))
+			return
+		}
+		m.output = append(m.output, m.colors().Info.Render(fmt.Sprintf("=== Remembered Items (%d) ===", len(m.itemManager.Items))))
+		for _, item := range m.itemManager.Find("") {
+			m.output = append(m.output, "  "+m.colors().Highlight.Render(item.Name))
+		}
 		return
 	}
 
-	if len(path) == 0 {
-		m.output = append(m.output, "\x1b[92mYou are already at that location!\x1b[0m")
+	if strings.EqualFold(args[0], "forget") && len(args) > 1 {
+		name := strings.Join(args[1:], " ")
+		if !m.itemManager.Forget(name) {
+			m.output = append(m.output, m.colors().Error.Render(fmt.Sprintf("No remembered item named '%s'", name)))
+			return
+		}
+		m.output = append(m.output, m.colors().Info.Render(fmt.Sprintf("Forgot %s", name)))
+		if err := m.itemManager.Save(); err != nil {
+			m.output = append(m.output, m.colors().Error.Render(fmt.Sprintf("Error saving items: %v", err)))
+		}
 		return
 	}
 
-	m.output = append(m.output, fmt.Sprintf("\x1b[92mTo reach '%s', go: %s\x1b[0m", targetRoom.Title, path[0]))
+	query := strings.Join(args, " ")
+	found := m.itemManager.Find(query)
+	switch len(found) {
+	case 0:
+		m.output = append(m.output, m.colors().Warn.Render(fmt.Sprintf("No remembered item matches '%s'", query)))
+	case 1:
+		item := found[0]
+		m.output = append(m.output, fmt.Sprintf("%s %s", m.colors().Info.Render(fmt.Sprintf("=== %s ===", item.Name)), m.colors().Debug.Render(fmt.Sprintf("(remembered %s)", item.Remembered.Format("2006-01-02 15:04")))))
+		for _, line := range item.Stats {
+			m.output = append(m.output, "  "+line)
+		}
+	default:
+		m.output = append(m.output, m.colors().Warn.Render(fmt.Sprintf("%d items match '%s':", len(found), query)))
+		for _, item := range found {
+			m.output = append(m.output, "  "+m.colors().Highlight.Render(item.Name))
+		}
+	}
 }
 
-// handleWayfindCommand shows the full path to reach a destination
-func (m *Model) handleWayfindCommand(args []string) {
-	if len(args) == 0 {
-		m.output = append(m.output, "\x1b[91mUsage: /wayfind <room search terms> or /wayfind <number> [search terms]\x1b[0m")
+// handleRememberCommand stores the MUD's last response as an item's stats
+func (m *Model) handleRememberCommand(args []string) {
+	if m.itemManager == nil {
+		m.itemManager = items.NewManager()
+	}
+
+	stats := m.lastResponse()
+	if len(stats) == 0 {
+		m.output = append(m.output, m.colors().Error.Render("Nothing to remember: no output from the MUD since the last prompt"))
 		return
 	}
 
-	var rooms []*mapper.Room
-	var query string
+	name := strings.Join(args, " ")
+	if name == "" {
+		objectName, ok := items.ParseObjectName(stats[0])
+		if !ok {
+			m.output = append(m.output, m.colors().Warn.Render("Usage: /remember <item name>"))
+			return
+		}
+		name = objectName
+	}
 
-	// Check if first argument is a number for room selection
-	if roomNum, err := fmt.Sscanf(args[0], "%d", new(int)); err == nil && roomNum == 1 {
-		var index int
-		fmt.Sscanf(args[0], "%d", &index)
+	item, err := m.itemManager.Remember(name, stats, time.Now())
+	if err != nil {
+		m.output = append(m.output, m.colors().Error.Render(fmt.Sprintf("Error: %v", err)))
+		return
+	}
+	m.output = append(m.output, m.colors().Info.Render(fmt.Sprintf("Remembered %d line(s) of stats for %s", len(item.Stats), item.Name)))
+	if err := m.itemManager.Save(); err != nil {
+		m.output = append(m.output, m.colors().Error.Render(fmt.Sprintf("Error saving items: %v", err)))
+	}
+}
 
-		// If only a number is provided, use lastRoomSearch
-		if len(args) == 1 {
-			if len(m.lastRoomSearch) == 0 {
-				m.output = append(m.output, "\x1b[91mNo previous room search to select from. Use /rooms to see all rooms.\x1b[0m")
-				return
-			}
-			if index < 1 || index > len(m.lastRoomSearch) {
-				m.output = append(m.output, fmt.Sprintf("\x1b[91mInvalid room number. Must be between 1 and %d.\x1b[0m", len(m.lastRoomSearch)))
-				return
-			}
-			rooms = []*mapper.Room{m.lastRoomSearch[index-1]}
-		} else {
-			// Number followed by search terms - search first, then select by index
-			query = strings.Join(args[1:], " ")
-			allMatches := m.worldMap.FindRooms(query)
+// lastResponse returns the MUD output between the last two prompts, without
+// ANSI codes or surrounding blank lines
+func (m *Model) lastResponse() []string {
+	lines := m.recentOutput
+	end := len(lines)
+	for end > 0 {
+		line := strings.TrimSpace(ansi.Strip(lines[end-1]))
+		if line != "" && !m.isPromptLine(line) {
+			break
+		}
+		end--
+	}
 
-			if len(allMatches) == 0 {
-				m.output = append(m.output, fmt.Sprintf("\x1b[91mNo rooms found matching '%s'\x1b[0m", query))
-				return
-			}
+	start := end
+	for start > 0 && !m.isPromptLine(strings.TrimSpace(ansi.Strip(lines[start-1]))) {
+		start--
+	}
 
-			if index < 1 || index > len(allMatches) {
-				m.output = append(m.output, fmt.Sprintf("\x1b[91mInvalid room number. Found %d rooms matching '%s'. Must be between 1 and %d.\x1b[0m", len(allMatches), query, len(allMatches)))
-				return
+	var response []string
+	for _, line := range lines[start:end] {
+		line = strings.TrimRight(ansi.Strip(line), " \t\r")
+		if len(response) == 0 && strings.TrimSpace(line) == "" {
+			continue
+		}
+		response = append(response, line)
+	}
+	return response
+}
+
+// affectHourLength returns the real time length of a game hour, which is one tick
+func (m *Model) affectHourLength() time.Duration {
+	if m.tickTimerManager != nil && m.tickTimerManager.TickInterval > 0 {
+		return time.Duration(m.tickTimerManager.TickInterval) * time.Second
+	}
+	// Same default as the tick timer uses before it has measured the interval
+	return 75 * time.Second
+}
+
+// checkAffects warns about affects in their last game hour, runs the
+// configured expiry action for them and drops affects that have worn off
+func (m *Model) checkAffects() tea.Cmd {
+	if m.affectTracker == nil {
+		return nil
+	}
+
+	now := time.Now()
+	expiring := m.affectTracker.Expiring(now, m.affectHourLength())
+	var commands []string
+	for _, affect := range expiring {
+		m.output = append(m.output, m.colors().Warn.Render(fmt.Sprintf("[Affect: %s is about to wear off]", affect.Name)))
+		if m.settingsManager == nil || m.settingsManager.AffectAction == "" {
+			continue
+		}
+		action := strings.ReplaceAll(m.settingsManager.AffectAction, "<affect>", affect.Name)
+		for _, command := range strings.Split(action, ";") {
+			if command = strings.TrimSpace(command); command != "" {
+				commands = append(commands, command)
 			}
+		}
+	}
+	m.affectTracker.RemoveExpired(now)
 
-			rooms = []*mapper.Room{allMatches[index-1]}
-			m.lastRoomSearch = allMatches
+	if len(expiring) > 0 {
+		m.updateViewport()
+	}
+	if len(commands) == 0 {
+		return nil
+	}
+	return m.enqueueCommands(commands)
+}
+
+// handleAffectsCommand lists tracked affects or configures the expiry action
+func (m *Model) handleAffectsCommand(command string) {
+	args := strings.Fields(command)[1:]
+	if m.settingsManager == nil {
+		m.settingsManager = settings.NewManager()
+	}
+
+	if len(args) == 0 {
+		if m.affectTracker == nil || len(m.affectTracker.Affects) == 0 {
+			m.output = append(m.output, m.colors().Warn.Render("No affects tracked. Type 'affects' (or 'spells') to list them from the MUD."))
+			return
 		}
-	} else {
-		// Regular search without numeric selection
-		query = strings.Join(args, " ")
-		rooms = m.worldMap.FindRooms(query)
+		now := time.Now()
+		m.output = append(m.output, m.colors().Info.Render("=== Affects ==="))
+		for _, affect := range m.affectTracker.Affects {
+			m.output = append(m.output, fmt.Sprintf("  %s - %s", m.colors().Highlight.Render(affect.Name), affect.FormatRemaining(now)))
+		}
+		if m.settingsManager.AffectAction != "" {
+			m.output = append(m.output, m.colors().Debug.Render(fmt.Sprintf("Expiry action: \"%s\"", m.settingsManager.AffectAction)))
+		}
+		return
 	}
 
-	if len(rooms) == 0 {
-		m.output = append(m.output, fmt.Sprintf("\x1b[91mNo rooms found matching '%s'\x1b[0m", query))
+	switch strings.ToLower(args[0]) {
+	case "clear":
+		m.affectTracker = affects.NewTracker()
+		m.output = append(m.output, m.colors().Info.Render("Cleared tracked affects."))
 		return
+	case "expire":
+		action := strings.TrimSpace(command[strings.Index(command, args[0])+len(args[0]):])
+		if action == "" {
+			if m.settingsManager.AffectAction == "" {
+				m.output = append(m.output, m.colors().Info.Render("No expiry action set."))
+			} else {
+				m.output = append(m.output, m.colors().Info.Render(fmt.Sprintf("Expiry action: \"%s\"", m.settingsManager.AffectAction)))
+			}
+			return
+		}
+		if strings.EqualFold(action, "off") {
+			m.settingsManager.AffectAction = ""
+			m.output = append(m.output, m.colors().Info.Render("Expiry action removed."))
+		} else {
+			if len(action) >= 2 && strings.HasPrefix(action, "\"") && strings.HasSuffix(action, "\"") {
+				action = action[1 : len(action)-1]
+			}
+			m.settingsManager.AffectAction = action
+			m.output = append(m.output, m.colors().Info.Render(fmt.Sprintf("Expiry action set: \"%s\"", action)))
+		}
+		if err := m.settingsManager.Save(); err != nil {
+			m.output = append(m.output, m.colors().Error.Render(fmt.Sprintf("Error saving settings: %v", err)))
+		}
+	default:
+		m.output = append(m.output, m.colors().Error.Render("Usage: /affects [clear | expire \"cmd\" | expire off]"))
 	}
+}
 
-	if len(rooms) > 1 {
-		// Store results for later disambiguation
-		m.lastRoomSearch = rooms
+// handleInventoryCommand shows when the inventory and equipment panels were
+// last updated, or turns the periodic inventory refresh on or off
+func (m *Model) handleInventoryCommand(args []string) {
+	if m.settingsManager == nil {
+		m.settingsManager = settings.NewManager()
+	}
 
-		m.output = append(m.output, fmt.Sprintf("\x1b[93mFound %d rooms matching '%s':\x1b[0m", len(rooms), query))
-		for i, room := range rooms {
-			if i >= 5 {
-				m.output = append(m.output, fmt.Sprintf("  \x1b[90m... and %d more\x1b[0m", len(rooms)-5))
-				break
-			}
-			m.output = append(m.output, fmt.Sprintf("  \x1b[96m%d. %s\x1b[0m", i+1, room.Title))
+	if len(args) == 0 {
+		if m.settingsManager.InventorySeconds > 0 {
+			m.output = append(m.output, m.colors().Info.Render(fmt.Sprintf("Inventory auto-refresh: every %d seconds.", m.settingsManager.InventorySeconds)))
+		} else {
+			m.output = append(m.output, m.colors().Info.Render("Inventory auto-refresh is off."))
+		}
+		if m.inventoryTime.IsZero() {
+			m.output = append(m.output, "  Inventory: not seen yet (type inventory or i)")
+		} else {
+			m.output = append(m.output, fmt.Sprintf("  Inventory: %d items at %s", len(m.inventory), m.inventoryTime.Format("15:04:05")))
+		}
+		if m.equipmentTime.IsZero() {
+			m.output = append(m.output, "  Equipment: not seen yet (type equipment or eq)")
+		} else {
+			m.output = append(m.output, fmt.Sprintf("  Equipment: %d items worn at %s", len(m.equipment), m.equipmentTime.Format("15:04:05")))
 		}
-		m.output = append(m.output, "\x1b[93mPlease be more specific, or use /wayfind <number> to select a room.\x1b[0m")
 		return
 	}
 
-	// Find path to the room
-	targetRoom := rooms[0]
-	pathSteps := m.worldMap.FindPathWithRooms(targetRoom.ID)
+	if !strings.EqualFold(args[0], "auto") || len(args) != 2 {
+		m.output = append(m.output, m.colors().Error.Render("Usage: /inventory [auto <seconds> | auto off]"))
+		return
+	}
+	if strings.EqualFold(args[1], "off") {
+		m.settingsManager.InventorySeconds = 0
+		m.output = append(m.output, m.colors().Info.Render("Inventory auto-refresh off."))
+	} else {
+		var seconds int
+		if _, err := fmt.Sscanf(args[1], "%d", &seconds); err != nil || seconds <= 0 {
+			m.output = append(m.output, m.colors().Error.Render("Usage: /inventory [auto <seconds> | auto off]"))
+			return
+		}
+		if seconds < settings.MinInventorySeconds {
+			m.output = append(m.output, m.colors().Error.Render(fmt.Sprintf("The refresh interval must be at least %d seconds.", settings.MinInventorySeconds)))
+			return
+		}
+		m.settingsManager.InventorySeconds = seconds
+		// Count the interval from now rather than refreshing straight away
+		m.inventorySentAt = time.Now()
+		m.output = append(m.output, m.colors().Info.Render(fmt.Sprintf("Inventory auto-refresh on: sending \"inventory\" every %d seconds.", seconds)))
+	}
 
-	if pathSteps == nil {
-		m.output = append(m.output, fmt.Sprintf("\x1b[91mNo path found to '%s'\x1b[0m", targetRoom.Title))
+	if err := m.settingsManager.Save(); err != nil {
+		m.output = append(m.output, m.colors().Error.Render(fmt.Sprintf("Error saving settings: %v", err)))
+	}
+}
+
+// handleWealthCommand shows the money picked up and carried this session
+func (m *Model) handleWealthCommand(args []string) {
+	if m.wealthTracker == nil {
+		m.wealthTracker = wealth.NewTracker()
+	}
+
+	if len(args) > 0 {
+		if strings.ToLower(args[0]) != "reset" {
+			m.output = append(m.output, m.colors().Error.Render("Usage: /wealth [reset]"))
+			return
+		}
+		m.wealthTracker = wealth.NewTracker()
+		m.output = append(m.output, m.colors().Info.Render("Wealth tracking restarted."))
 		return
 	}
 
-	if len(pathSteps) == 0 {
-		m.output = append(m.output, "\x1b[92mYou are already at that location!\x1b[0m")
+	tracker := m.wealthTracker
+	if !tracker.HaveBalance && tracker.Earned == 0 {
+		m.output = append(m.output, m.colors().Warn.Render("No money seen yet. Gold messages such as 'You get 150 gold coins.' and the prompt's coins field (e.g. 570C) are tracked."))
 		return
 	}
 
-	m.output = append(m.output, fmt.Sprintf("\x1b[92mPath to '%s' (%d steps):\x1b[0m", targetRoom.Title, len(pathSteps)))
-	for i, step := range pathSteps {
-		m.output = append(m.output, fmt.Sprintf("  \x1b[96m%d. %s -> %s\x1b[0m", i+1, step.Direction, step.RoomTitle))
+	now := time.Now()
+	elapsed := now.Sub(tracker.Start).Round(time.Minute)
+	m.output = append(m.output, m.colors().Info.Render("=== Wealth ==="))
+	if tracker.HaveBalance {
+		m.output = append(m.output, "  Carrying: "+m.colors().Highlight.Render(fmt.Sprintf("%d gold", tracker.Balance)))
 	}
+	m.output = append(m.output, fmt.Sprintf("  This session: %s (%d picked up)", m.colors().Highlight.Render(fmt.Sprintf("%+d gold", tracker.Net())), tracker.Earned))
+	m.output = append(m.output, fmt.Sprintf("  Rate: %s over %dh%02dm", m.colors().Highlight.Render(fmt.Sprintf("%.0f gold/hour", tracker.PerHour(now))), int(elapsed.Hours()), int(elapsed.Minutes())%60))
 }
 
-// handleMapCommand shows information about the current map
-func (m *Model) handleMapCommand(args []string) {
-	current := m.worldMap.GetCurrentRoom()
+// handleCombatCommand shows the combat log or manages custom damage patterns
+func (m *Model) handleCombatCommand(command string) {
+	args := strings.Fields(command)[1:]
+	if m.combatManager == nil {
+		m.combatManager = combat.NewManager()
+	}
+	if m.combatLog == nil {
+		m.combatLog = combat.NewLog()
+	}
 
-	m.output = append(m.output, "\x1b[92m=== Map Information ===\x1b[0m")
-	m.output = append(m.output, fmt.Sprintf("Total rooms explored: \x1b[96m%d\x1b[0m", len(m.worldMap.Rooms)))
+	if len(args) == 0 {
+		now := time.Now()
+		if m.combatLog.Current != nil {
+			m.output = append(m.output, m.colors().Info.Render(fmt.Sprintf("Current fight: %s", m.combatLog.Current.Summary(now))))
+		}
+		if m.combatLog.Last != nil {
+			m.output = append(m.output, m.colors().Info.Render(fmt.Sprintf("Last fight: %s", m.combatLog.Last.Summary(now))))
+		}
+		if m.combatLog.Current == nil && m.combatLog.Last == nil {
+			m.output = append(m.output, m.colors().Warn.Render("No fights recorded yet."))
+		}
+		return
+	}
 
-	if current != nil {
-		m.output = append(m.output, fmt.Sprintf("Current room: \x1b[96m%s\x1b[0m", current.Title))
-		if len(current.Exits) > 0 {
-			exits := []string{}
-			for dir := range current.Exits {
-				exits = append(exits, dir)
+	switch strings.ToLower(args[0]) {
+	case "reset":
+		m.combatLog = combat.NewLog()
+		m.output = append(m.output, m.colors().Info.Render("Combat log cleared."))
+	case "patterns":
+		if len(m.combatManager.Patterns) == 0 {
+			m.output = append(m.output, m.colors().Warn.Render("No custom damage patterns. The built-in DikuMUD and ROM patterns are used."))
+			return
+		}
+		m.output = append(m.output, m.colors().Info.Render("=== Custom Damage Patterns ==="))
+		for i, pattern := range m.combatManager.Patterns {
+			m.output = append(m.output, "  "+m.colors().Highlight.Render(fmt.Sprintf("%d. [%s] %s", i+1, pattern.Direction, pattern.Pattern)))
+		}
+	case "pattern":
+		if len(args) >= 2 && strings.ToLower(args[1]) == "remove" {
+			var index int
+			if len(args) < 3 {
+				m.output = append(m.output, m.colors().Error.Render("Usage: /combat pattern remove <n>"))
+				return
+			}
+			if _, err := fmt.Sscanf(args[2], "%d", &index); err != nil {
+				m.output = append(m.output, m.colors().Error.Render("Error: Invalid index"))
+				return
+			}
+			if err := m.combatManager.Remove(index - 1); err != nil {
+				m.output = append(m.output, m.colors().Error.Render("Error: Invalid pattern index. Use /combat patterns to see them."))
+				return
+			}
+			m.output = append(m.output, m.colors().Info.Render(fmt.Sprintf("Damage pattern %d removed.", index)))
+		} else {
+			if len(args) < 3 {
+				m.output = append(m.output, m.colors().Error.Render("Usage: /combat pattern dealt|taken \"regex\""))
+				return
+			}
+			direction := strings.ToLower(args[1])
+			pattern := strings.TrimSpace(command[strings.Index(command, args[1])+len(args[1]):])
+			if len(pattern) >= 2 && strings.HasPrefix(pattern, "\"") && strings.HasSuffix(pattern, "\"") {
+				pattern = pattern[1 : len(pattern)-1]
+			}
+			added, err := m.combatManager.Add(direction, pattern)
+			if err != nil {
+				m.output = append(m.output, m.colors().Error.Render(fmt.Sprintf("Error adding damage pattern: %v", err)))
+				return
 			}
-			m.output = append(m.output, fmt.Sprintf("Exits: \x1b[96m%s\x1b[0m", strings.Join(exits, ", ")))
+			m.output = append(m.output, m.colors().Info.Render(fmt.Sprintf("Damage pattern added: [%s] %s", added.Direction, added.Pattern)))
 		}
-	} else {
-		m.output = append(m.output, "\x1b[90mNo current room detected yet\x1b[0m")
+		if err := m.combatManager.Save(); err != nil {
+			m.output = append(m.output, m.colors().Error.Render(fmt.Sprintf("Error saving damage patterns: %v", err)))
+		}
+	default:
+		m.output = append(m.output, m.colors().Error.Render("Usage: /combat [reset | patterns | pattern dealt|taken \"regex\" | pattern remove <n>]"))
 	}
 }
 
-// handleShareCommand generates a shareable URL for web sessions
-func (m *Model) handleShareCommand() {
-	if m.webSessionID == "" || m.webServerURL == "" {
-		m.output = append(m.output, "\x1b[91mError: /share command is only available in web mode\x1b[0m")
-		m.output = append(m.output, "\x1b[90mStart the client with --web flag to enable session sharing\x1b[0m")
-		return
+// checkInventoryRefresh sends "inventory" when /inventory auto is on and
+// neither a refresh nor a listing from the player has come within the interval
+func (m *Model) checkInventoryRefresh(now time.Time) tea.Cmd {
+	if m.settingsManager == nil || m.settingsManager.InventorySeconds <= 0 || m.conn == nil || !m.connected {
+		return nil
+	}
+	interval := time.Duration(m.settingsManager.InventorySeconds) * time.Second
+	if now.Sub(m.inventoryTime) < interval || now.Sub(m.inventorySentAt) < interval {
+		return nil
 	}
+	m.inventorySentAt = now
+	return m.enqueueCommands([]string{"inventory"})
+}
 
-	shareURL := fmt.Sprintf("%s/?id=%s", m.webServerURL, m.webSessionID)
-	m.output = append(m.output, "\x1b[92m=== Share This Session ===\x1b[0m")
-	m.output = append(m.output, fmt.Sprintf("\x1b[96m%s\x1b[0m", shareURL))
-	m.output = append(m.output, "")
-	m.output = append(m.output, "\x1b[90mAnyone who opens this URL will see and control the same session\x1b[0m")
+// idleWarningRegex matches MUD messages about idle players being moved to
+// the void or disconnected
+var idleWarningRegex = regexp.MustCompile(`(?i)(you have been idle|pulled into (a|the) void|idle (too long|timeout)|disconnected for idling|autoquit|auto-quit)`)
+
+// checkIdle sends the AFK command once per idle stretch after the configured
+// idle time without keystrokes
+func (m *Model) checkIdle() tea.Cmd {
+	if m.settingsManager == nil || m.settingsManager.AfkSeconds <= 0 || m.conn == nil || !m.connected {
+		return nil
+	}
+	if m.lastInputTime.IsZero() {
+		m.lastInputTime = time.Now()
+		return nil
+	}
+
+	idle := time.Since(m.lastInputTime)
+	if idle < time.Duration(m.settingsManager.AfkSeconds)*time.Second || m.afkSentFor.Equal(m.lastInputTime) {
+		return nil
+	}
+	m.afkSentFor = m.lastInputTime
+
+	action := m.settingsManager.GetAfkCommand()
+	m.output = append(m.output, m.colors().Warn.Render(fmt.Sprintf("[AFK: idle for %s, sending \"%s\"]", idle.Round(time.Second), action)))
+	m.updateViewport()
+
+	var commands []string
+	for _, command := range strings.Split(action, ";") {
+		if command = strings.TrimSpace(command); command != "" {
+			commands = append(commands, command)
+		}
+	}
+	return m.enqueueCommands(commands)
 }
 
-// handleHelpCommand shows available client commands or detailed help for a specific command
-func (m *Model) handleHelpCommand(args []string) {
-	// If a specific command is requested, show detailed help
-	if len(args) > 0 {
-		m.showDetailedHelp(strings.ToLower(args[0]))
-		return
+// detectIdleWarning warns when the MUD says it is about to disconnect the
+// player for idling
+func (m *Model) detectIdleWarning(cleanLine string) {
+	if idleWarningRegex.MatchString(cleanLine) {
+		m.output = append(m.output, m.colors().Error.Render("[AFK: the MUD is about to disconnect you for idling - press a key or send a command]"))
 	}
+}
 
-	// Show general help
-	m.output = append(m.output, "\x1b[92m=== Client Commands ===\x1b[0m")
-	m.output = append(m.output, "  \x1b[96m/point <room>\x1b[0m            - Show next direction to reach a room")
-	m.output = append(m.output, "  \x1b[96m/wayfind <room>\x1b[0m         - Show full path to reach a room")
-	m.output = append(m.output, "  \x1b[96m/go <room>\x1b[0m              - Auto-walk to a room (one step per second)")
-	m.output = append(m.output, "  \x1b[96m/stop\x1b[0m                   - Stop auto-walk or command queue")
-	m.output = append(m.output, "  \x1b[96m/map\x1b[0m                    - Show map information")
-	m.output = append(m.output, "  \x1b[96m/rooms [filter]\x1b[0m         - List all known rooms (optionally filtered)")
-	m.output = append(m.output, "  \x1b[96m/nearby\x1b[0m                 - List all rooms within 5 steps")
-	m.output = append(m.output, "  \x1b[96m/legend\x1b[0m                 - List all rooms currently on the map")
-	m.output = append(m.output, "  \x1b[96m/trigger \"pat\" \"act\"\x1b[0m - Add a trigger (pattern can use <var>)")
-	m.output = append(m.output, "  \x1b[96m/triggers list\x1b[0m          - List all triggers")
-	m.output = append(m.output, "  \x1b[96m/triggers remove <n>\x1b[0m    - Remove trigger by number")
-	m.output = append(m.output, "  \x1b[96m/ticktrigger # \"cmd\"\x1b[0m  - Add a tick trigger (fires at T:#)")
-	m.output = append(m.output, "  \x1b[96m/ticktriggers list\x1b[0m     - List all tick triggers")
-	m.output = append(m.output, "  \x1b[96m/ticktriggers remove <n>\x1b[0m - Remove tick trigger by number")
-	m.output = append(m.output, "  \x1b[96m/alias \"name\" \"tmpl\"\x1b[0m  - Add an alias (template can use <var>)")
-	m.output = append(m.output, "  \x1b[96m/aliases list\x1b[0m           - List all aliases")
-	m.output = append(m.output, "  \x1b[96m/aliases remove <n>\x1b[0m     - Remove alias by number")
-	m.output = append(m.output, "  \x1b[96m/share\x1b[0m                  - Get shareable URL (web mode only)")
-	m.output = append(m.output, "  \x1b[96m/help [command]\x1b[0m         - Show this help or detailed help for a command")
-	m.output = append(m.output, "")
-	m.output = append(m.output, "\x1b[92m=== Keyboard Shortcuts ===\x1b[0m")
-	m.output = append(m.output, "  \x1b[96mUp/Down Arrow\x1b[0m           - Navigate command history")
-	m.output = append(m.output, "  \x1b[96mCtrl+R\x1b[0m                  - Search command history (type to filter)")
-	m.output = append(m.output, "")
-	m.output = append(m.output, "\x1b[90mUse /help <command> for detailed help on a specific command\x1b[0m")
-	m.output = append(m.output, "\x1b[90mRoom search matches all terms in room title, description, or exits\x1b[0m")
-	m.output = append(m.output, "\x1b[90mTriggers match output lines and execute actions (supports <variable> capture)\x1b[0m")
-	m.output = append(m.output, "\x1b[90mAliases expand commands with parameters (e.g., /alias \"gat\" \"give all <target>\")\x1b[0m")
-	m.output = append(m.output, "\x1b[90mTriggers and aliases support multiple commands separated by ';' (e.g., \"cmd1;cmd2;cmd3\")\x1b[0m")
+// retryCastWindow is how long after a /retrycast cast a failure message
+// still counts as the cast failing; after that it is taken to have worked
+const retryCastWindow = 5 * time.Second
+
+// defaultRetryCastTries is how many times /retrycast casts when no limit
+// is given
+const defaultRetryCastTries = 3
+
+// retryCast is a spell being cast with /retrycast
+type retryCast struct {
+	command  string         // Cast command to send
+	failure  *regexp.Regexp // Line saying the cast failed
+	maxTries int            // Most casts to send
+	tries    int            // Casts sent so far
+	sentAt   time.Time      // When the last cast was queued
 }
 
-// showDetailedHelp shows detailed help for a specific command
-func (m *Model) showDetailedHelp(cmd string) {
-	switch cmd {
-	case "point":
-		m.output = append(m.output, "\x1b[92m=== /point - Show Next Direction ===\x1b[0m")
-		m.output = append(m.output, "")
-		m.output = append(m.output, "\x1b[96mUsage:\x1b[0m")
-		m.output = append(m.output, "  /point <room search terms>")
-		m.output = append(m.output, "  /point <number> [search terms]")
-		m.output = append(m.output, "")
-		m.output = append(m.output, "\x1b[96mDescription:\x1b[0m")
-		m.output = append(m.output, "  Shows the next direction to take to reach a destination room.")
-		m.output = append(m.output, "  Searches for rooms matching all provided terms (case-insensitive).")
-		m.output = append(m.output, "")
-		m.output = append(m.output, "\x1b[96mExamples:\x1b[0m")
-		m.output = append(m.output, "  /point temple square       - Find room matching 'temple' and 'square'")
-		m.output = append(m.output, "  /point market              - Find room matching 'market'")
-		m.output = append(m.output, "  /point 2                   - Select 2nd room from previous search")
-		m.output = append(m.output, "  /point 1 temple            - Select 1st room matching 'temple'")
-		m.output = append(m.output, "")
-		m.output = append(m.output, "\x1b[90mSee also: /help wayfind, /help go\x1b[0m")
+// handleRetryCastCommand casts a spell and arranges for it to be cast
+// again when the failure message is seen
+func (m *Model) handleRetryCastCommand(command string) tea.Cmd {
+	args := strings.TrimSpace(strings.TrimPrefix(strings.TrimPrefix(command, "/"), "retrycast"))
 
-	case "wayfind":
-		m.output = append(m.output, "\x1b[92m=== /wayfind - Show Full Path ===\x1b[0m")
-		m.output = append(m.output, "")
-		m.output = append(m.output, "\x1b[96mUsage:\x1b[0m")
-		m.output = append(m.output, "  /wayfind <room search terms>")
-		m.output = append(m.output, "  /wayfind <number> [search terms]")
-		m.output = append(m.output, "")
-		m.output = append(m.output, "\x1b[96mDescription:\x1b[0m")
-		m.output = append(m.output, "  Shows the complete path from your current location to the destination.")
-		m.output = append(m.output, "  Lists each step with the direction and destination room name.")
-		m.output = append(m.output, "")
-		m.output = append(m.output, "\x1b[96mExamples:\x1b[0m")
-		m.output = append(m.output, "  /wayfind temple square     - Show full path to 'temple square'")
-		m.output = append(m.output, "  /wayfind 3                 - Show path to 3rd room from previous search")
-		m.output = append(m.output, "")
-		m.output = append(m.output, "\x1b[90mSee also: /help point, /help go\x1b[0m")
+	switch strings.ToLower(args) {
+	case "":
+		if m.retryCast == nil {
+			m.output = append(m.output, m.colors().Info.Render("No cast is being retried."))
+		} else {
+			m.output = append(m.output, m.colors().Info.Render(fmt.Sprintf("Retrying \"%s\": try %d of %d.", m.retryCast.command, m.retryCast.tries, m.retryCast.maxTries)))
+		}
+		return nil
+	case "stop", "off":
+		m.retryCast = nil
+		m.output = append(m.output, m.colors().Info.Render("Stopped retrying the cast."))
+		return nil
+	}
 
-	case "go":
-		m.output = append(m.output, "\x1b[92m=== /go - Auto-Walk to Room ===\x1b[0m")
-		m.output = append(m.output, "")
-		m.output = append(m.output, "\x1b[96mUsage:\x1b[0m")
-		m.output = append(m.output, "  /go <room search terms>")
-		m.output = append(m.output, "  /go <number> [search terms]")
-		m.output = append(m.output, "")
-		m.output = append(m.output, "\x1b[96mDescription:\x1b[0m")
-		m.output = append(m.output, "  Automatically walks to a destination room, sending one movement command")
-		m.output = append(m.output, "  per second. The client will follow the shortest path to the destination.")
-		m.output = append(m.output, "")
-		m.output = append(m.output, "\x1b[96mExamples:\x1b[0m")
-		m.output = append(m.output, "  /go temple square          - Auto-walk to 'temple square'")
-		m.output = append(m.output, "  /go 1                      - Auto-walk to 1st room from previous search")
-		m.output = append(m.output, "")
-		m.output = append(m.output, "\x1b[90mUse /stop to cancel auto-walk\x1b[0m")
-		m.output = append(m.output, "\x1b[90mSee also: /help stop, /help point, /help wayfind\x1b[0m")
+	castCommand, pattern, err := parseQuotedArgs(args)
+	if err != nil || castCommand == "" || pattern == "" {
+		m.output = append(m.output, m.colors().Error.Render("Usage: /retrycast \"<cast command>\" \"<failure pattern>\" [<max tries>]"))
+		return nil
+	}
+	maxTries := defaultRetryCastTries
+	if rest := strings.TrimSpace(args[strings.LastIndex(args, "\"")+1:]); rest != "" {
+		if _, err := fmt.Sscanf(rest, "%d", &maxTries); err != nil || maxTries < 1 {
+			m.output = append(m.output, m.colors().Error.Render(fmt.Sprintf("Error: invalid number of tries '%s'", rest)))
+			return nil
+		}
+	}
+	failure, err := regexp.Compile("(?i)" + pattern)
+	if err != nil {
+		m.output = append(m.output, m.colors().Error.Render(fmt.Sprintf("Error: invalid failure pattern: %v", err)))
+		return nil
+	}
+	if m.conn == nil || !m.connected {
+		m.output = append(m.output, m.colors().Error.Render("Not connected"))
+		return nil
+	}
 
-	case "stop":
-		m.output = append(m.output, "\x1b[92m=== /stop - Stop Auto-Walk or Command Queue ===\x1b[0m")
-		m.output = append(m.output, "")
-		m.output = append(m.output, "\x1b[96mUsage:\x1b[0m")
-		m.output = append(m.output, "  /stop")
-		m.output = append(m.output, "")
-		m.output = append(m.output, "\x1b[96mDescription:\x1b[0m")
-		m.output = append(m.output, "  Stops any active auto-walk operation or command queue.")
-		m.output = append(m.output, "  This includes auto-walk from /go, trigger actions, and alias multi-commands.")
-		m.output = append(m.output, "")
-		m.output = append(m.output, "\x1b[90mSee also: /help go\x1b[0m")
+	m.retryCast = &retryCast{command: castCommand, failure: failure, maxTries: maxTries, tries: 1, sentAt: time.Now()}
+	m.output = append(m.output, m.colors().Info.Render(fmt.Sprintf("Casting \"%s\", up to %d tries. /stop cancels.", castCommand, maxTries)))
+	return m.enqueueCommands([]string{castCommand})
+}
 
-	case "map":
-		m.output = append(m.output, "\x1b[92m=== /map - Show Map Information ===\x1b[0m")
-		m.output = append(m.output, "")
-		m.output = append(m.output, "\x1b[96mUsage:\x1b[0m")
-		m.output = append(m.output, "  /map")
-		m.output = append(m.output, "")
-		m.output = append(m.output, "\x1b[96mDescription:\x1b[0m")
-		m.output = append(m.output, "  Shows information about the current map, including:")
-		m.output = append(m.output, "  - Total number of rooms discovered")
-		m.output = append(m.output, "  - Total number of connections between rooms")
-		m.output = append(m.output, "  - Current room information (if known)")
-		m.output = append(m.output, "")
-		m.output = append(m.output, "\x1b[90mThe map is automatically saved to ~/.config/dikuclient/map.json\x1b[0m")
-		m.output = append(m.output, "\x1b[90mSee also: /help rooms, /help nearby, /help legend\x1b[0m")
+// detectRetryCast casts the /retrycast spell again when its failure
+// message is seen, until the tries run out. The command that starts the
+// queue is returned.
+func (m *Model) detectRetryCast(cleanLine string, now time.Time) tea.Cmd {
+	m.expireRetryCast(now)
+	cast := m.retryCast
+	if cast == nil || !cast.failure.MatchString(cleanLine) {
+		return nil
+	}
 
-	case "rooms":
-		m.output = append(m.output, "\x1b[92m=== /rooms - List Known Rooms ===\x1b[0m")
-		m.output = append(m.output, "")
-		m.output = append(m.output, "\x1b[96mUsage:\x1b[0m")
-		m.output = append(m.output, "  /rooms [filter terms]")
-		m.output = append(m.output, "")
-		m.output = append(m.output, "\x1b[96mDescription:\x1b[0m")
-		m.output = append(m.output, "  Lists all known rooms in the map. When filter terms are provided,")
-		m.output = append(m.output, "  only rooms matching all terms (case-insensitive) are shown.")
-		m.output = append(m.output, "  Searches room titles, descriptions, and exit information.")
-		m.output = append(m.output, "")
-		m.output = append(m.output, "\x1b[96mExamples:\x1b[0m")
-		m.output = append(m.output, "  /rooms                     - List all known rooms")
-		m.output = append(m.output, "  /rooms temple              - List rooms containing 'temple'")
-		m.output = append(m.output, "  /rooms market square       - List rooms with both 'market' and 'square'")
-		m.output = append(m.output, "")
-		m.output = append(m.output, "\x1b[90mSee also: /help nearby, /help legend\x1b[0m")
+	if cast.tries >= cast.maxTries {
+		m.output = append(m.output, m.colors().Warn.Render(fmt.Sprintf("[Retry cast: \"%s\" failed %d times, giving up]", cast.command, cast.tries)))
+		m.retryCast = nil
+		return nil
+	}
+	cast.tries++
+	cast.sentAt = now
+	m.output = append(m.output, m.colors().Debug.Render(fmt.Sprintf("[Retry cast: %s (try %d/%d)]", cast.command, cast.tries, cast.maxTries)))
+	return m.enqueueCommands([]string{cast.command})
+}
 
-	case "nearby":
-		m.output = append(m.output, "\x1b[92m=== /nearby - List Nearby Rooms ===\x1b[0m")
-		m.output = append(m.output, "")
-		m.output = append(m.output, "\x1b[96mUsage:\x1b[0m")
-		m.output = append(m.output, "  /nearby")
-		m.output = append(m.output, "")
-		m.output = append(m.output, "\x1b[96mDescription:\x1b[0m")
-		m.output = append(m.output, "  Lists all rooms within 5 steps of your current location.")
-		m.output = append(m.output, "  Rooms are shown with their distance and can be selected with /point,")
-		m.output = append(m.output, "  /wayfind, or /go using their number from the list.")
-		m.output = append(m.output, "")
-		m.output = append(m.output, "\x1b[96mExample:\x1b[0m")
-		m.output = append(m.output, "  > /nearby")
-		m.output = append(m.output, "  1. Market Square (2 steps)")
-		m.output = append(m.output, "  2. Temple Entrance (3 steps)")
-		m.output = append(m.output, "  > /go 1")
-		m.output = append(m.output, "")
-		m.output = append(m.output, "\x1b[90mSee also: /help legend, /help rooms\x1b[0m")
+// expireRetryCast forgets the /retrycast spell once no failure has been
+// seen for a while, as the last cast worked
+func (m *Model) expireRetryCast(now time.Time) {
+	if m.retryCast != nil && now.Sub(m.retryCast.sentAt) > retryCastWindow {
+		m.retryCast = nil
+	}
+}
 
-	case "legend":
-		m.output = append(m.output, "\x1b[92m=== /legend - List Rooms on Map ===\x1b[0m")
-		m.output = append(m.output, "")
-		m.output = append(m.output, "\x1b[96mUsage:\x1b[0m")
-		m.output = append(m.output, "  /legend")
-		m.output = append(m.output, "")
-		m.output = append(m.output, "\x1b[96mDescription:\x1b[0m")
-		m.output = append(m.output, "  Lists all rooms currently displayed in the map panel.")
-		m.output = append(m.output, "  Shows rooms with their coordinates and allows selection by number")
-		m.output = append(m.output, "  for use with /point, /wayfind, or /go commands.")
-		m.output = append(m.output, "")
-		m.output = append(m.output, "\x1b[90mSee also: /help nearby, /help rooms\x1b[0m")
+// defaultAutoFollowCommand is sent to accept a group invitation when
+// /autofollow has no command set (<leader> = the leader's name)
+const defaultAutoFollowCommand = "follow <leader>;group"
 
-	case "trigger", "triggers":
-		m.output = append(m.output, "\x1b[92m=== Triggers - Automated Responses ===\x1b[0m")
-		m.output = append(m.output, "")
-		m.output = append(m.output, "\x1b[96mUsage:\x1b[0m")
-		m.output = append(m.output, "  /trigger \"pattern\" \"action\"")
-		m.output = append(m.output, "  /triggers list")
-		m.output = append(m.output, "  /triggers remove <number>")
-		m.output = append(m.output, "")
-		m.output = append(m.output, "\x1b[96mDescription:\x1b[0m")
-		m.output = append(m.output, "  Triggers automatically execute commands when MUD output matches a pattern.")
-		m.output = append(m.output, "  Patterns support variable capture with <varname> syntax.")
-		m.output = append(m.output, "  Actions can include multiple commands separated by semicolons (;).")
-		m.output = append(m.output, "")
-		m.output = append(m.output, "\x1b[96mExamples:\x1b[0m")
-		m.output = append(m.output, "  /trigger \"You are hungry\" \"eat bread\"")
-		m.output = append(m.output, "  /trigger \"<player> has arrived\" \"say Hello <player>\"")
-		m.output = append(m.output, "  /trigger \"Low health!\" \"drink potion;flee\"")
-		m.output = append(m.output, "  /triggers list                 - List all triggers")
-		m.output = append(m.output, "  /triggers remove 1             - Remove trigger #1")
-		m.output = append(m.output, "")
-		m.output = append(m.output, "\x1b[90mMulti-command actions execute sequentially with 1-second delay\x1b[0m")
-		m.output = append(m.output, "\x1b[90mSee also: /help alias, /help stop\x1b[0m")
+// Follow and group messages, as sent by DikuMUD derivatives
+var (
+	groupInviteRegex = regexp.MustCompile(`(?i)^(\w+) invites you to join (?:the|his|her|their|its) group`)
+	followStartRegex = regexp.MustCompile(`(?i)^You now follow (\w+)`)
+	followStopRegex  = regexp.MustCompile(`(?i)^You stop following\b`)
+)
 
-	case "ticktrigger", "ticktriggers":
-		m.output = append(m.output, "\x1b[92m=== Tick Triggers - Time-Based Automation ===\x1b[0m")
-		m.output = append(m.output, "")
-		m.output = append(m.output, "\x1b[96mUsage:\x1b[0m")
-		m.output = append(m.output, "  /ticktrigger <tick_time> \"commands\"")
-		m.output = append(m.output, "  /ticktriggers list")
-		m.output = append(m.output, "  /ticktriggers remove <number>")
-		m.output = append(m.output, "")
-		m.output = append(m.output, "\x1b[96mDescription:\x1b[0m")
-		m.output = append(m.output, "  Tick triggers execute commands at a specific tick time (T:#).")
-		m.output = append(m.output, "  The client tracks tick times from prompts and projects when triggers should fire.")
-		m.output = append(m.output, "  Triggers fire even if no new prompt appears, based on internal timer.")
-		m.output = append(m.output, "  Commands can include multiple commands separated by semicolons (;).")
-		m.output = append(m.output, "")
-		m.output = append(m.output, "\x1b[96mExamples:\x1b[0m")
-		m.output = append(m.output, "  /ticktrigger 5 \"cast 'heal'\"")
-		m.output = append(m.output, "  /ticktrigger 4 \"cast 'bless';say Ready!\"")
-		m.output = append(m.output, "  /ticktrigger 10 \"get all from corpse\"")
-		m.output = append(m.output, "  /ticktriggers list             - List all tick triggers")
-		m.output = append(m.output, "  /ticktriggers remove 1         - Remove tick trigger #1")
-		m.output = append(m.output, "")
-		m.output = append(m.output, "\x1b[90mNote: Tick interval is auto-detected from prompts (typically 60 or 75 seconds)\x1b[0m")
-		m.output = append(m.output, "\x1b[90mMulti-command actions execute sequentially with 1-second delay\x1b[0m")
-		m.output = append(m.output, "\x1b[90mSee also: /help trigger, /help stop\x1b[0m")
+// detectFollow accepts a group invitation from the /autofollow leader and
+// keeps track of who is being followed. The command that starts the queue
+// is returned.
+func (m *Model) detectFollow(cleanLine string) tea.Cmd {
+	if matches := followStartRegex.FindStringSubmatch(cleanLine); matches != nil {
+		m.following = matches[1]
+		return nil
+	}
+	if followStopRegex.MatchString(cleanLine) {
+		m.following = ""
+		return nil
+	}
 
-	case "alias", "aliases":
-		m.output = append(m.output, "\x1b[92m=== Aliases - Command Shortcuts ===\x1b[0m")
-		m.output = append(m.output, "")
-		m.output = append(m.output, "\x1b[96mUsage:\x1b[0m")
-		m.output = append(m.output, "  /alias \"name\" \"template\"")
-		m.output = append(m.output, "  /aliases list")
-		m.output = append(m.output, "  /aliases remove <number>")
-		m.output = append(m.output, "")
-		m.output = append(m.output, "\x1b[96mDescription:\x1b[0m")
-		m.output = append(m.output, "  Aliases create command shortcuts with parameter substitution.")
-		m.output = append(m.output, "  Use <varname> in the template to capture parameters from the alias command.")
-		m.output = append(m.output, "  Templates can include multiple commands separated by semicolons (;).")
-		m.output = append(m.output, "")
-		m.output = append(m.output, "\x1b[96mExamples:\x1b[0m")
-		m.output = append(m.output, "  /alias \"gat\" \"give all <target>\"")
-		m.output = append(m.output, "  > gat mary                     - Sends: give all mary")
-		m.output = append(m.output, "")
-		m.output = append(m.output, "  /alias \"k\" \"kill <target>\"")
-		m.output = append(m.output, "  > k goblin                     - Sends: kill goblin")
-		m.output = append(m.output, "")
-		m.output = append(m.output, "  /alias \"prep\" \"get all from corpse;sacrifice corpse\"")
-		m.output = append(m.output, "  > prep                         - Sends both commands with delay")
-		m.output = append(m.output, "")
-		m.output = append(m.output, "  /aliases list                  - List all aliases")
-		m.output = append(m.output, "  /aliases remove 1              - Remove alias #1")
-		m.output = append(m.output, "")
-		m.output = append(m.output, "\x1b[90mMulti-command aliases execute sequentially with 1-second delay\x1b[0m")
-		m.output = append(m.output, "\x1b[90mSee also: /help trigger, /help stop\x1b[0m")
+	matches := groupInviteRegex.FindStringSubmatch(cleanLine)
+	if matches == nil || m.autoFollowLeader == "" || !strings.EqualFold(matches[1], m.autoFollowLeader) || m.conn == nil {
+		return nil
+	}
 
-	case "share":
-		m.output = append(m.output, "\x1b[92m=== /share - Share Web Session ===\x1b[0m")
-		m.output = append(m.output, "")
-		m.output = append(m.output, "\x1b[96mUsage:\x1b[0m")
-		m.output = append(m.output, "  /share")
-		m.output = append(m.output, "")
-		m.output = append(m.output, "\x1b[96mDescription:\x1b[0m")
-		m.output = append(m.output, "  Generates a shareable URL for the current web session.")
-		m.output = append(m.output, "  Anyone who opens this URL will see and control the same session.")
-		m.output = append(m.output, "")
-		m.output = append(m.output, "\x1b[90mNote: Only available in web mode\x1b[0m")
-		m.output = append(m.output, "\x1b[90mStart web mode with: dikuclient --web\x1b[0m")
+	var commands []string
+	for _, cmd := range strings.Split(m.autoFollowAction(), ";") {
+		if cmd = strings.TrimSpace(cmd); cmd != "" {
+			commands = append(commands, cmd)
+		}
+	}
+	if len(commands) == 0 {
+		return nil
+	}
 
-	case "help":
-		m.output = append(m.output, "\x1b[92m=== /help - Show Help Information ===\x1b[0m")
-		m.output = append(m.output, "")
-		m.output = append(m.output, "\x1b[96mUsage:\x1b[0m")
-		m.output = append(m.output, "  /help [command]")
-		m.output = append(m.output, "")
-		m.output = append(m.output, "\x1b[96mDescription:\x1b[0m")
-		m.output = append(m.output, "  Shows available client commands and keyboard shortcuts.")
-		m.output = append(m.output, "  When a command name is provided, shows detailed help for that command.")
-		m.output = append(m.output, "")
-		m.output = append(m.output, "\x1b[96mExamples:\x1b[0m")
-		m.output = append(m.output, "  /help                  - Show all available commands")
-		m.output = append(m.output, "  /help point            - Show detailed help for /point")
-		m.output = append(m.output, "  /help trigger          - Show detailed help for triggers")
+	m.output = append(m.output, m.colors().Debug.Render(fmt.Sprintf("[Auto-follow: %s]", strings.Join(commands, "; "))))
+	return m.enqueueCommands(commands)
+}
 
-	default:
-		m.output = append(m.output, fmt.Sprintf("\x1b[91mUnknown command: %s\x1b[0m", cmd))
-		m.output = append(m.output, "")
-		m.output = append(m.output, "Available commands for detailed help:")
-		m.output = append(m.output, "  point, wayfind, go, stop, map, rooms, nearby, legend,")
-		m.output = append(m.output, "  trigger, triggers, ticktrigger, ticktriggers, alias, aliases, share, help")
-		m.output = append(m.output, "")
-		m.output = append(m.output, "Use /help to see all commands")
+// handleAutoFollowCommand sets the leader whose group invitations are
+// accepted, or shows who is being followed
+func (m *Model) handleAutoFollowCommand(command string) {
+	args := strings.Fields(command)[1:]
+
+	if len(args) == 0 {
+		if m.autoFollowLeader == "" {
+			m.output = append(m.output, m.colors().Info.Render("Auto-follow is off."))
+		} else {
+			m.output = append(m.output, m.colors().Info.Render(fmt.Sprintf("Auto-follow: sending \"%s\" when %s invites you.", m.autoFollowAction(), m.autoFollowLeader)))
+		}
+		if m.following != "" {
+			m.output = append(m.output, fmt.Sprintf("Following %s.", m.colors().Highlight.Render(m.following)))
+		} else {
+			m.output = append(m.output, "Not following anyone.")
+		}
+		return
 	}
+
+	if strings.EqualFold(args[0], "off") && len(args) == 1 {
+		m.autoFollowLeader = ""
+		m.autoFollowCommand = ""
+		m.output = append(m.output, m.colors().Info.Render("Auto-follow off."))
+		return
+	}
+
+	m.autoFollowLeader = args[0]
+	m.autoFollowCommand = ""
+	if action := strings.TrimSpace(command[strings.Index(command, args[0])+len(args[0]):]); action != "" {
+		if len(action) >= 2 && strings.HasPrefix(action, "\"") && strings.HasSuffix(action, "\"") {
+			action = action[1 : len(action)-1]
+		}
+		m.autoFollowCommand = action
+	}
+	m.output = append(m.output, m.colors().Info.Render(fmt.Sprintf("Auto-follow on: sending \"%s\" when %s invites you to the group.", m.autoFollowAction(), m.autoFollowLeader)))
 }
 
-// handleRoomsCommand lists all known rooms or filters by search terms
-func (m *Model) handleRoomsCommand(args []string) {
-	var roomsToDisplay []*mapper.Room
-	var headerText string
+// autoFollowAction returns the commands that accept an invitation from the
+// /autofollow leader
+func (m *Model) autoFollowAction() string {
+	action := m.autoFollowCommand
+	if action == "" {
+		action = defaultAutoFollowCommand
+	}
+	return strings.ReplaceAll(action, "<leader>", m.autoFollowLeader)
+}
+
+// defaultAutoAssistCommand is sent to join the leader's fight when
+// /autoassist has no command set (<leader> = the leader's name, <target> =
+// what the leader attacked)
+const defaultAutoAssistCommand = "assist <leader>"
+
+// autoAssistCooldown is the least time between assists, so a burst of
+// attack messages sends one
+const autoAssistCooldown = 5 * time.Second
+
+// assistCombatEnd is how long without a combat prompt before a fight is
+// taken to be over and the leader's next fight is joined
+const assistCombatEnd = 5 * time.Second
+
+// leaderAttackRegex matches another character attacking, capturing who
+// attacked and what: "Gandalf hits the orc.", "Gandalf's slash misses the
+// orc.", "Gandalf massacres the orc to small fragments with his slash."
+var leaderAttackRegex = regexp.MustCompile(`(?i)^(\w+)(?:'s [\w' -]+?)? (?:barely )?(?:attacks|misses|tickles|massacres|hits|pounds|pierces|slashes|whips|claws|bites|stings|crushes|scratches|grazes|injures|wounds|mauls|decimates|devastates|maims|mutilates|disembowels|dismembers|mangles|demolishes|obliterates|annihilates|eradicates) (.+?)(?: as \w+ \w+ \w+| to small fragments.*| with .+| hard| very hard| extremely hard)?[.!]`)
+
+// detectAutoAssist joins the /autoassist leader's fight when they attack
+// something, unless already fighting or an assist was sent moments ago. The
+// command that starts the queue is returned.
+func (m *Model) detectAutoAssist(cleanLine string, now time.Time) tea.Cmd {
+	if m.autoAssistLeader == "" || m.assisting || m.conn == nil {
+		return nil
+	}
+	matches := leaderAttackRegex.FindStringSubmatch(cleanLine)
+	if matches == nil || !strings.EqualFold(matches[1], m.autoAssistLeader) {
+		return nil
+	}
+	victim := strings.TrimSpace(matches[2])
+	if strings.EqualFold(victim, "you") {
+		return nil
+	}
+	for _, article := range []string{"the ", "a ", "an "} {
+		if len(victim) > len(article) && strings.EqualFold(victim[:len(article)], article) {
+			victim = victim[len(article):]
+			break
+		}
+	}
+	if now.Sub(m.lastCombatPrompt) < assistCombatEnd || now.Sub(m.assistSentAt) < autoAssistCooldown {
+		return nil
+	}
+
+	var commands []string
+	for _, cmd := range strings.Split(m.autoAssistAction(victim), ";") {
+		if cmd = strings.TrimSpace(cmd); cmd != "" {
+			commands = append(commands, cmd)
+		}
+	}
+	if len(commands) == 0 {
+		return nil
+	}
+
+	m.assisting = true
+	m.assistSentAt = now
+	m.output = append(m.output, m.colors().Debug.Render(fmt.Sprintf("[Auto-assist: %s]", strings.Join(commands, "; "))))
+	return m.enqueueCommands(commands)
+}
+
+// expireAutoAssist ends the assisted fight once no combat prompt has been
+// seen for a while, so the leader's next fight is joined
+func (m *Model) expireAutoAssist(now time.Time) {
+	if !m.assisting {
+		return
+	}
+	last := m.assistSentAt
+	if m.lastCombatPrompt.After(last) {
+		last = m.lastCombatPrompt
+	}
+	if now.Sub(last) > assistCombatEnd {
+		m.assisting = false
+	}
+}
+
+// handleAutoAssistCommand sets the leader whose fights are joined, or shows
+// the setting
+func (m *Model) handleAutoAssistCommand(command string) {
+	args := strings.Fields(command)[1:]
 
 	if len(args) == 0 {
-		// No filter - show all rooms
-		allRooms := m.worldMap.GetAllRooms()
+		if m.autoAssistLeader == "" {
+			m.output = append(m.output, m.colors().Info.Render("Auto-assist is off."))
+		} else {
+			m.output = append(m.output, m.colors().Info.Render(fmt.Sprintf("Auto-assist: sending \"%s\" when %s starts fighting.", m.autoAssistAction("<target>"), m.autoAssistLeader)))
+			if m.assisting {
+				m.output = append(m.output, "Assisting in the current fight.")
+			}
+		}
+		return
+	}
+
+	if strings.EqualFold(args[0], "off") && len(args) == 1 {
+		m.autoAssistLeader = ""
+		m.autoAssistCommand = ""
+		m.assisting = false
+		m.output = append(m.output, m.colors().Info.Render("Auto-assist off."))
+		return
+	}
+
+	m.autoAssistLeader = args[0]
+	m.autoAssistCommand = ""
+	m.assisting = false
+	if action := strings.TrimSpace(command[strings.Index(command, args[0])+len(args[0]):]); action != "" {
+		if len(action) >= 2 && strings.HasPrefix(action, "\"") && strings.HasSuffix(action, "\"") {
+			action = action[1 : len(action)-1]
+		}
+		m.autoAssistCommand = action
+	}
+	m.output = append(m.output, m.colors().Info.Render(fmt.Sprintf("Auto-assist on: sending \"%s\" when %s starts fighting.", m.autoAssistAction("<target>"), m.autoAssistLeader)))
+}
+
+// autoAssistAction returns the commands that join the /autoassist leader's
+// fight against victim
+func (m *Model) autoAssistAction(victim string) string {
+	action := m.autoAssistCommand
+	if action == "" {
+		action = defaultAutoAssistCommand
+	}
+	action = strings.ReplaceAll(action, "<leader>", m.autoAssistLeader)
+	return strings.ReplaceAll(action, "<target>", victim)
+}
+
+// handleAfkCommand shows or configures the AFK idle command
+func (m *Model) handleAfkCommand(command string) {
+	args := strings.Fields(command)[1:]
+	if m.settingsManager == nil {
+		m.settingsManager = settings.NewManager()
+	}
+
+	if len(args) == 0 {
+		if m.settingsManager.AfkSeconds <= 0 {
+			m.output = append(m.output, m.colors().Info.Render("AFK is off."))
+		} else {
+			m.output = append(m.output, m.colors().Info.Render(fmt.Sprintf("AFK: sending \"%s\" after %d seconds without input.", m.settingsManager.GetAfkCommand(), m.settingsManager.AfkSeconds)))
+		}
+		return
+	}
+
+	if strings.EqualFold(args[0], "off") {
+		m.settingsManager.AfkSeconds = 0
+		m.output = append(m.output, m.colors().Info.Render("AFK off."))
+	} else {
+		var seconds int
+		if _, err := fmt.Sscanf(args[0], "%d", &seconds); err != nil || seconds <= 0 {
+			m.output = append(m.output, m.colors().Error.Render("Usage: /afk [<seconds> [\"command\"] | off]"))
+			return
+		}
+		m.settingsManager.AfkSeconds = seconds
+		if action := strings.TrimSpace(command[strings.Index(command, args[0])+len(args[0]):]); action != "" {
+			if len(action) >= 2 && strings.HasPrefix(action, "\"") && strings.HasSuffix(action, "\"") {
+				action = action[1 : len(action)-1]
+			}
+			m.settingsManager.AfkCommand = action
+		}
+		m.output = append(m.output, m.colors().Info.Render(fmt.Sprintf("AFK on: sending \"%s\" after %d seconds without input.", m.settingsManager.GetAfkCommand(), seconds)))
+	}
+
+	if err := m.settingsManager.Save(); err != nil {
+		m.output = append(m.output, m.colors().Error.Render(fmt.Sprintf("Error saving settings: %v", err)))
+	}
+}
+
+// handleAutoLootCommand turns looting after a kill on or off and sets the
+// loot command
+func (m *Model) handleAutoLootCommand(command string) {
+	args := strings.Fields(command)[1:]
+	if m.settingsManager == nil {
+		m.settingsManager = settings.NewManager()
+	}
+
+	if len(args) == 0 {
+		if m.settingsManager.AutoLoot {
+			m.output = append(m.output, m.colors().Info.Render(fmt.Sprintf("Auto-loot: sending \"%s\" after a kill.", m.settingsManager.GetAutoLootCommand())))
+		} else {
+			m.output = append(m.output, m.colors().Info.Render("Auto-loot is off."))
+		}
+		return
+	}
+
+	switch strings.ToLower(args[0]) {
+	case "off":
+		m.settingsManager.AutoLoot = false
+		m.output = append(m.output, m.colors().Info.Render("Auto-loot off."))
+	case "on":
+		m.settingsManager.AutoLoot = true
+		if action := strings.TrimSpace(command[strings.Index(command, args[0])+len(args[0]):]); action != "" {
+			if len(action) >= 2 && strings.HasPrefix(action, "\"") && strings.HasSuffix(action, "\"") {
+				action = action[1 : len(action)-1]
+			}
+			m.settingsManager.AutoLootCommand = action
+		}
+		m.output = append(m.output, m.colors().Info.Render(fmt.Sprintf("Auto-loot on: sending \"%s\" after a kill.", m.settingsManager.GetAutoLootCommand())))
+	default:
+		m.output = append(m.output, m.colors().Error.Render("Usage: /autoloot [on [\"command\"] | off]"))
+		return
+	}
+
+	if err := m.settingsManager.Save(); err != nil {
+		m.output = append(m.output, m.colors().Error.Render(fmt.Sprintf("Error saving settings: %v", err)))
+	}
+}
+
+// wimpyCommand is sent when the hit points drop below the /wimpy threshold
+const wimpyCommand = "flee"
+
+// vitals returns the hit point tracker, creating it when first needed
+func (m *Model) vitals() *vitals.Tracker {
+	if m.vitalsTracker == nil {
+		m.vitalsTracker = vitals.NewTracker()
+	}
+	return m.vitalsTracker
+}
+
+// requestVitals asks the server for GMCP and MSDP hit points. Servers that
+// don't support them ignore the request and the prompt is used instead.
+// GMCP's Char package, which carries Char.Vitals, is subscribed to by the
+// connection's handshake once the server agrees.
+func (m *Model) requestVitals() {
+	if m.conn == nil || m.replayPath != "" {
+		return
+	}
+	m.conn.RequestGMCP()
+	m.conn.SendRaw(client.EncodeNegotiation(client.DO, client.TELOPT_MSDP))
+	m.conn.SendRaw(client.EncodeSubnegotiation(client.TELOPT_MSDP, vitals.MSDPReport()))
+}
+
+// detectPromptVitals reads the hit points from a prompt for /wimpy
+func (m *Model) detectPromptVitals(cleanLine string) {
+	if m.vitals().UpdatePrompt(strings.TrimSpace(cleanLine)) {
+		m.checkWimpy()
+	}
+}
+
+// checkWimpy flees once when the hit points drop below the /wimpy
+// threshold, judged from the best source that knows them, and waits for
+// them to recover before it will flee again
+func (m *Model) checkWimpy() {
+	if m.settingsManager == nil || m.settingsManager.Wimpy == "" {
+		return
+	}
+	threshold, err := vitals.ParseThreshold(m.settingsManager.Wimpy)
+	if err != nil {
+		return
+	}
+	below, reading, ok := m.vitals().Below(threshold)
+	if !ok {
+		return
+	}
+	if !below {
+		m.wimpyFled = false
+		return
+	}
+	if m.wimpyFled || m.conn == nil || !m.connected {
+		return
+	}
+
+	m.wimpyFled = true
+	m.conn.Send(wimpyCommand)
+	m.output = append(m.output, m.colors().Warn.Render(fmt.Sprintf("[Wimpy: %s is below %s - sending \"%s\"]", reading, threshold, wimpyCommand)))
+}
+
+// handleWimpyCommand shows or sets the hit points below which the client
+// flees
+func (m *Model) handleWimpyCommand(args []string) {
+	if m.settingsManager == nil {
+		m.settingsManager = settings.NewManager()
+	}
+
+	if len(args) == 0 {
+		if m.settingsManager.Wimpy == "" {
+			m.output = append(m.output, m.colors().Info.Render("Wimpy is off. Usage: /wimpy <hp|percent%> | off"))
+		} else {
+			m.output = append(m.output, m.colors().Info.Render(fmt.Sprintf("Wimpy: sending \"%s\" below %s.", wimpyCommand, m.settingsManager.Wimpy)))
+		}
+		if reading, ok := m.vitals().Best(); ok {
+			m.output = append(m.output, m.colors().Info.Render(fmt.Sprintf("Hit points: %s", reading)))
+		} else {
+			m.output = append(m.output, m.colors().Info.Render("Hit points: not seen yet (no GMCP, MSDP or prompt hit points)"))
+		}
+		return
+	}
+
+	if len(args) == 1 && strings.EqualFold(args[0], "off") {
+		m.settingsManager.Wimpy = ""
+		m.output = append(m.output, m.colors().Info.Render("Wimpy off."))
+	} else {
+		threshold, err := vitals.ParseThreshold(strings.Join(args, ""))
+		if err != nil {
+			m.output = append(m.output, m.colors().Error.Render(fmt.Sprintf("Error: %v", err)))
+			m.output = append(m.output, m.colors().Error.Render("Usage: /wimpy <hp|percent%> | off"))
+			return
+		}
+		m.settingsManager.Wimpy = threshold.String()
+		m.wimpyFled = false
+		m.output = append(m.output, m.colors().Info.Render(fmt.Sprintf("Wimpy on: sending \"%s\" when hit points drop below %s.", wimpyCommand, threshold)))
+		m.requestVitals()
+	}
+
+	if err := m.settingsManager.Save(); err != nil {
+		m.output = append(m.output, m.colors().Error.Render(fmt.Sprintf("Error saving settings: %v", err)))
+	}
+}
+
+// detectCombatPrompt detects combat status in the prompt
+func (m *Model) detectCombatPrompt(line string) {
+	cleanLine := ansi.Strip(line)
+	matches := combatPromptRegex.FindStringSubmatch(cleanLine)
+	if matches != nil && len(matches) == 3 {
+		// matches[1] is the hero name, matches[2] is the target name
+		target := strings.ToLower(strings.TrimSpace(matches[2]))
+
+		m.lastCombatPrompt = time.Now()
+
+		// The fight's target becomes the /target unless one was set
+		if m.target == "" {
+			m.setTarget(strings.TrimSpace(matches[2]))
+		}
+
+		// Only start tracking if we don't have a pending kill or if this is a new target
+		if m.pendingKill == "" || m.pendingKill != target {
+			m.pendingKill = target
+			// note the pending kill to the client
+			m.output = append(m.output, m.colors().Debug.Render(fmt.Sprintf("[XP Tracker: Pending kill on '%s']", target))+"\n")
+
+			m.killTime = time.Now()
+		}
+
+		if m.combatLog == nil {
+			m.combatLog = combat.NewLog()
+		}
+		m.combatLog.Start(target, time.Now())
+	}
+}
+
+// detectXPEvents detects death messages and XP gains to calculate XP/s. A
+// death also queues the auto-loot commands, and the command that starts the
+// queue is returned.
+func (m *Model) detectXPEvents(line string) tea.Cmd {
+	cleanLine := ansi.Strip(line)
+
+	// Loot whatever died (/autoloot)
+	var lootCmd tea.Cmd
+	if matches := deathMessageRegex.FindStringSubmatch(cleanLine); matches != nil {
+		creature := strings.ToLower(strings.TrimSpace(matches[2]))
+		lootCmd = m.autoLoot(creature)
+
+		// The /target is dead
+		if m.target != "" && strings.Contains(creature, strings.ToLower(m.target)) {
+			m.output = append(m.output, m.colors().Debug.Render(fmt.Sprintf("[Target '%s' is dead]", m.target)))
+			m.setTarget("")
+		}
+	}
+
+	// Count the session's experience (/levels)
+	if matches := xpGainRegex.FindStringSubmatch(cleanLine); matches != nil {
+		xp := 0
+		fmt.Sscanf(matches[1], "%d", &xp)
+		if m.sessionXPStart.IsZero() {
+			m.sessionXPStart = time.Now()
+		}
+		m.sessionXP += xp
+	}
+
+	// Check for death message
+	if m.pendingKill != "" {
+		matches := deathMessageRegex.FindStringSubmatch(cleanLine)
+		if matches != nil && len(matches) == 3 {
+			// matches[1] is the article (The/A/An), matches[2] is the creature name
+			creatureName := strings.ToLower(strings.TrimSpace(matches[2]))
+			// Check if this matches our pending kill
+			if strings.Contains(creatureName, m.pendingKill) {
+				// Store the death time, but don't finalize yet - wait for XP gain
+				m.pendingKill = creatureName
+			}
+		}
+	}
+
+	// Check for XP gain
+	if m.pendingKill != "" {
+		matches := xpGainRegex.FindStringSubmatch(cleanLine)
+		if matches != nil && len(matches) == 2 {
+			xp := 0
+			fmt.Sscanf(matches[1], "%d", &xp)
+
+			// Calculate time elapsed
+			deathTime := time.Now()
+			seconds := deathTime.Sub(m.killTime).Seconds()
+
+			// Calculate XP/s
+			xpPerSecond := 0.0
+			if seconds > 0 {
+				xpPerSecond = float64(xp) / seconds
+			}
+
+			// Store in current session tracking
+			m.xpTracking[m.pendingKill] = &XPStat{
+				CreatureName: m.pendingKill,
+				XP:           xp,
+				Seconds:      seconds,
+				XPPerSecond:  xpPerSecond,
+			}
+
+			// Update persistent stats with EMA
+			if m.xpStatsManager != nil {
+				m.xpStatsManager.UpdateStat(m.pendingKill, xpPerSecond)
+				m.xpStatsChanged()
+			}
+
+			// Clear pending kill
+			m.output = append(m.output, m.colors().Debug.Render(fmt.Sprintf("[XP Tracker: Recorded kill on '%s' - %d XP in %.1f seconds (%.2f XP/s)]", m.pendingKill, xp, seconds, xpPerSecond))+"\n")
+			m.pendingKill = ""
+		}
+	}
+
+	return lootCmd
+}
+
+// autoLoot queues the /autoloot commands for a creature that died, with
+// <creature> replaced by its name
+func (m *Model) autoLoot(creature string) tea.Cmd {
+	if m.settingsManager == nil || !m.settingsManager.AutoLoot || m.conn == nil {
+		return nil
+	}
+
+	action := strings.ReplaceAll(m.settingsManager.GetAutoLootCommand(), "<creature>", creature)
+	var commands []string
+	for _, cmd := range strings.Split(action, ";") {
+		if cmd = strings.TrimSpace(cmd); cmd != "" {
+			commands = append(commands, cmd)
+		}
+	}
+	if len(commands) == 0 {
+		return nil
+	}
+
+	m.output = append(m.output, m.colors().Debug.Render(fmt.Sprintf("[Auto-loot: %s]", strings.Join(commands, "; "))))
+	return m.enqueueCommands(commands)
+}
+
+// detectCombat records damage messages in the combat log and summarizes the
+// fight when the target dies
+func (m *Model) detectCombat(line string) {
+	if m.combatManager == nil {
+		m.combatManager = combat.NewManager()
+	}
+	if m.combatLog == nil {
+		m.combatLog = combat.NewLog()
+	}
+
+	cleanLine := strings.TrimSpace(ansi.Strip(line))
+	now := time.Now()
+	if hit, ok := m.combatManager.Parse(cleanLine); ok {
+		m.combatLog.Record(hit, now)
+		return
+	}
+
+	if m.combatLog.Current != nil && deathMessageRegex.MatchString(cleanLine) {
+		fight := m.combatLog.EndFight(now)
+		m.output = append(m.output, m.colors().Debug.Render(fmt.Sprintf("[Combat: %s]", fight.Summary(now))))
+	}
+}
+
+// classifyLine sorts a line of MUD output (without colors) into the kinds the
+// detectors look for: prompts, tells, combat messages and room exits
+func (m *Model) classifyLine(cleanLine string) string {
+	if m.combatManager == nil {
+		m.combatManager = combat.NewManager()
+	}
+
+	trimmed := strings.TrimSpace(cleanLine)
+	switch {
+	case m.isPromptLine(trimmed) || combatPromptRegex.MatchString(trimmed):
+		return jsonlog.Prompt
+	case tellRegex.MatchString(cleanLine):
+		return jsonlog.Tell
+	case deathMessageRegex.MatchString(trimmed) || xpGainRegex.MatchString(trimmed):
+		return jsonlog.Combat
+	}
+	if _, ok := m.combatManager.Parse(trimmed); ok {
+		return jsonlog.Combat
+	}
+	if mapper.IsExitsLine(trimmed) {
+		return jsonlog.Room
+	}
+	return jsonlog.Other
+}
+
+// writeJSONLog records a line of MUD output in the JSON log, if one is open
+func (m *Model) writeJSONLog(line, cleanLine, kind string) {
+	if m.jsonLog == nil {
+		return
+	}
+	entry := jsonlog.Entry{
+		TS:       time.Now(),
+		Raw:      line,
+		Stripped: cleanLine,
+		Kind:     kind,
+	}
+	if err := m.jsonLog.Write(entry); err != nil {
+		m.output = append(m.output, m.colors().Error.Render(fmt.Sprintf("Error writing JSON log, stopping: %v", err)))
+		m.jsonLog.Close()
+		m.jsonLog = nil
+	}
+}
+
+// writeOutputPipe copies a line of MUD output to the --output-pipe command.
+// Once the command has gone away the pipe is dropped and play goes on.
+func (m *Model) writeOutputPipe(cleanLine string) {
+	if m.outputPipe == nil {
+		return
+	}
+	if _, err := io.WriteString(m.outputPipe, cleanLine+"\n"); err != nil {
+		m.output = append(m.output, m.colors().Warn.Render(fmt.Sprintf("[Output pipe stopped: %v]", err)))
+		m.outputPipe = nil
+	}
+}
+
+// SetOutputPipe copies each line of MUD output, without colors, to w
+// (--output-pipe)
+func (m *Model) SetOutputPipe(w io.Writer) {
+	m.outputPipe = w
+}
+
+// StartJSONLog starts writing classified MUD output to path as JSON lines
+func (m *Model) StartJSONLog(path string) error {
+	logger, err := jsonlog.Create(path)
+	if err != nil {
+		return err
+	}
+	if m.jsonLog != nil {
+		m.jsonLog.Close()
+	}
+	m.jsonLog = logger
+	m.applyLogRedaction()
+	return nil
+}
+
+// logSecrets returns the strings hidden in the logs: those added with
+// /log redact, every session's login name and password, passwords typed at
+// a password prompt and the web session's share IDs
+func (m *Model) logSecrets() []string {
+	var secrets []string
+	if m.settingsManager != nil {
+		secrets = append(secrets, m.settingsManager.LogRedactions...)
+	}
+	secrets = append(secrets, m.username, m.password, m.webSessionID, m.webViewID)
+	for _, s := range m.sessions {
+		secrets = append(secrets, s.username, s.password)
+	}
+	return append(secrets, m.typedPasswords...)
+}
+
+// applyLogRedaction hides the current log secrets in everything the logs
+// write from now on
+func (m *Model) applyLogRedaction() {
+	redactor := redact.New(m.logSecrets())
+	for _, log := range []*debuglog.Log{m.mudLog, m.tuiLog, m.telnetDebugLog} {
+		log.SetRedactor(redactor)
+	}
+	if m.jsonLog != nil {
+		m.jsonLog.SetRedactor(redactor)
+	}
+}
+
+// notePassword hides a password typed at a password prompt in the logs
+func (m *Model) notePassword(password string) {
+	for _, typed := range m.typedPasswords {
+		if typed == password {
+			return
+		}
+	}
+	m.typedPasswords = append(m.typedPasswords, password)
+	m.applyLogRedaction()
+}
+
+// handleLogRedactCommand lists, adds or removes the strings /log redact
+// hides in the logs
+func (m *Model) handleLogRedactCommand(args []string) {
+	if m.settingsManager == nil {
+		m.settingsManager = settings.NewManager()
+	}
+	usage := "Usage: /log redact [add <text> | remove <text>]"
+
+	if len(args) == 0 {
+		if len(m.settingsManager.LogRedactions) == 0 {
+			m.output = append(m.output, m.colors().Info.Render("No strings added to redact from the logs."))
+		} else {
+			m.output = append(m.output, m.colors().Info.Render("Redacted from the logs:"))
+			for _, text := range m.settingsManager.LogRedactions {
+				m.output = append(m.output, "  "+text)
+			}
+		}
+		m.output = append(m.output, "Your login name, passwords and /share IDs are always redacted.")
+		return
+	}
+
+	text := strings.TrimSpace(strings.Join(args[1:], " "))
+	if len(text) >= 2 && strings.HasPrefix(text, "\"") && strings.HasSuffix(text, "\"") {
+		text = text[1 : len(text)-1]
+	}
+	if text == "" {
+		m.output = append(m.output, m.colors().Error.Render(usage))
+		return
+	}
+
+	switch strings.ToLower(args[0]) {
+	case "add":
+		if len(text) < redact.MinLength {
+			m.output = append(m.output, m.colors().Error.Render(fmt.Sprintf("Redacted text must be at least %d characters long.", redact.MinLength)))
+			return
+		}
+		for _, redacted := range m.settingsManager.LogRedactions {
+			if redacted == text {
+				m.output = append(m.output, m.colors().Warn.Render(fmt.Sprintf("'%s' is already redacted.", text)))
+				return
+			}
+		}
+		m.settingsManager.LogRedactions = append(m.settingsManager.LogRedactions, text)
+		m.output = append(m.output, m.colors().Info.Render(fmt.Sprintf("'%s' will be written to the logs as %s.", text, redact.Placeholder)))
+	case "remove":
+		var kept []string
+		for _, redacted := range m.settingsManager.LogRedactions {
+			if redacted != text {
+				kept = append(kept, redacted)
+			}
+		}
+		if len(kept) == len(m.settingsManager.LogRedactions) {
+			m.output = append(m.output, m.colors().Error.Render(fmt.Sprintf("'%s' is not in the redact list.", text)))
+			return
+		}
+		m.settingsManager.LogRedactions = kept
+		m.output = append(m.output, m.colors().Info.Render(fmt.Sprintf("'%s' is no longer redacted.", text)))
+	default:
+		m.output = append(m.output, m.colors().Error.Render(usage))
+		return
+	}
+
+	m.applyLogRedaction()
+	if err := m.settingsManager.Save(); err != nil {
+		m.output = append(m.output, m.colors().Error.Render(fmt.Sprintf("Error saving settings: %v", err)))
+	}
+}
+
+// handleLogCommand starts and stops the JSON lines log of MUD output
+func (m *Model) handleLogCommand(args []string) {
+	if len(args) == 0 {
+		if m.jsonLog != nil {
+			m.output = append(m.output, m.colors().Info.Render(fmt.Sprintf("JSON log: writing to %s", m.jsonLog.Path())))
+		} else {
+			m.output = append(m.output, m.colors().Info.Render("JSON log: off"))
+		}
+		return
+	}
+
+	if args[0] == "redact" {
+		m.handleLogRedactCommand(args[1:])
+		return
+	}
+
+	if args[0] != "json" || len(args) < 2 || len(args) > 3 {
+		m.output = append(m.output, m.colors().Warn.Render("Usage: /log json start [file] | /log json stop | /log redact [add|remove <text>]"))
+		return
+	}
+
+	switch args[1] {
+	case "start":
+		path := fmt.Sprintf("mud-output-%s.jsonl", time.Now().Format("20060102-150405"))
+		if len(args) == 3 {
+			path = args[2]
+		}
+		if err := m.StartJSONLog(path); err != nil {
+			m.output = append(m.output, m.colors().Error.Render(fmt.Sprintf("Error starting JSON log: %v", err)))
+			return
+		}
+		m.output = append(m.output, m.colors().Info.Render(fmt.Sprintf("Writing MUD output to %s", path)))
+	case "stop":
+		if m.jsonLog == nil {
+			m.output = append(m.output, m.colors().Warn.Render("JSON log is not running"))
+			return
+		}
+		path := m.jsonLog.Path()
+		m.jsonLog.Close()
+		m.jsonLog = nil
+		m.output = append(m.output, m.colors().Info.Render(fmt.Sprintf("Stopped JSON log (%s)", path)))
+	default:
+		m.output = append(m.output, m.colors().Warn.Render("Usage: /log json start [file] | /log json stop"))
+	}
+}
+
+// handleRecordCommand starts and stops recording the raw MUD stream of the
+// current session for replay with --replay
+func (m *Model) handleRecordCommand(args []string) {
+	if len(args) == 0 {
+		if m.conn != nil && m.conn.Recording() != nil {
+			m.output = append(m.output, m.colors().Info.Render(fmt.Sprintf("Recording: writing to %s", m.conn.Recording().Path())))
+		} else {
+			m.output = append(m.output, m.colors().Info.Render("Recording: off"))
+		}
+		return
+	}
+
+	switch {
+	case args[0] == "session" && len(args) <= 2:
+		if m.conn == nil {
+			m.output = append(m.output, m.colors().Error.Render("Error: Not connected"))
+			return
+		}
+		path := fmt.Sprintf("session-%s.rec", time.Now().Format("20060102-150405"))
+		if len(args) == 2 {
+			path = args[1]
+		}
+		recorder, err := client.CreateRecording(path)
+		if err != nil {
+			m.output = append(m.output, m.colors().Error.Render(fmt.Sprintf("Error starting recording: %v", err)))
+			return
+		}
+		m.conn.StartRecording(recorder)
+		m.output = append(m.output, m.colors().Info.Render(fmt.Sprintf("Recording session to %s", path)))
+	case args[0] == "stop" && len(args) == 1:
+		if m.conn == nil || m.conn.Recording() == nil {
+			m.output = append(m.output, m.colors().Warn.Render("Not recording"))
+			return
+		}
+		path := m.conn.Recording().Path()
+		if err := m.conn.StopRecording(); err != nil {
+			m.output = append(m.output, m.colors().Error.Render(fmt.Sprintf("Error closing recording: %v", err)))
+			return
+		}
+		m.output = append(m.output, m.colors().Info.Render(fmt.Sprintf("Stopped recording (%s)", path)))
+	default:
+		m.output = append(m.output, m.colors().Warn.Render("Usage: /record session [file] | /record stop"))
+	}
+}
+
+// searchRooms finds rooms for /point, /wayfind and /go. When no room
+// matches every term, the closest titles are used instead, best first.
+func (m *Model) searchRooms(query string) []*mapper.Room {
+	rooms, fuzzy := m.worldMap.SearchRooms(query)
+	if fuzzy {
+		m.output = append(m.output, m.colors().Debug.Render(fmt.Sprintf("[No exact match for '%s', showing the closest rooms]", query)))
+	}
+	return rooms
+}
+
+// handleClientCommand processes client-side commands starting with /
+// clientCommands are the names handleClientCommand dispatches on, in the
+// order of its switch. A unique prefix of one, such as /way, runs it.
+var clientCommands = []string{
+	"point", "wayfind", "path", "avoid", "merge", "dig", "map", "rooms", "nearby", "trail", "stats",
+	"remember-exit", "legend", "go", "explore", "stop", "walkspeed", "numpadwalk",
+	"hideprompt", "promptnewline", "split", "promptpattern", "reconnect", "reconnect-on",
+	"collapse", "links", "focus", "tab", "ansi", "theme", "telnet", "affects", "whereis",
+	"stat", "remember", "inventory", "wealth", "levels", "stopwatch", "combat", "target", "afk",
+	"autofollow", "autoassist", "retrycast", "autoloot", "wimpy", "throttle", "repeat",
+	"notrig", "learn", "log", "record", "trigger", "group", "triggers", "edit", "alias",
+	"aliases", "sub", "subs", "capture", "captures", "mute", "unmute", "macro", "macros",
+	"ticktrigger", "ticktriggers", "share", "echo", "set", "unset", "reload", "connect",
+	"sessions", "debug", "version", "help",
+}
+
+// resolveClientCommand finds the client command a name stands for: the
+// command itself, or the only one it is a prefix of. Otherwise the name is
+// returned unchanged along with every command it could be, if more than one.
+func resolveClientCommand(name string) (string, []string) {
+	var matches []string
+	for _, command := range clientCommands {
+		if command == name {
+			return name, nil
+		}
+		if strings.HasPrefix(command, name) {
+			matches = append(matches, command)
+		}
+	}
+	if len(matches) == 1 {
+		return matches[0], nil
+	}
+	return name, matches
+}
+
+func (m *Model) handleClientCommand(command string) tea.Cmd {
+	command = strings.TrimSpace(command)
+	if !strings.HasPrefix(command, "/") {
+		return nil
+	}
+
+	// Remove the leading /
+	command = strings.TrimPrefix(command, "/")
+	parts := strings.Fields(command)
+
+	if len(parts) == 0 {
+		m.output = append(m.output, m.colors().Error.Render("Error: Empty command"))
+		return nil
+	}
+
+	cmd := strings.ToLower(parts[0])
+	args := parts[1:]
+
+	// Accept an unambiguous abbreviation, as MUDs do for their commands.
+	// Handlers that read the whole command see it with the name written out.
+	full, candidates := resolveClientCommand(cmd)
+	if len(candidates) > 1 {
+		for i, candidate := range candidates {
+			candidates[i] = "/" + candidate
+		}
+		m.output = append(m.output, m.colors().Error.Render(fmt.Sprintf("Error: '/%s' is ambiguous: %s", cmd, strings.Join(candidates, ", "))))
+		return nil
+	}
+	if full != cmd {
+		command = full + command[len(parts[0]):]
+		parts[0] = full
+		cmd = full
+	}
+
+	switch cmd {
+	case "point":
+		m.handlePointCommand(args)
+		return nil
+	case "wayfind":
+		m.handleWayfindCommand(args)
+		return nil
+	case "path":
+		m.handlePathCommand(args)
+		return nil
+	case "avoid":
+		m.handleAvoidCommand(args)
+		return nil
+	case "merge":
+		m.handleMergeCommand(args)
+		return nil
+	case "dig":
+		m.handleDigCommand(strings.TrimSpace(command[len(parts[0]):]))
+		return nil
+	case "map":
+		m.handleMapCommand(args)
+		return nil
+	case "rooms":
+		m.handleRoomsCommand(args)
+		return nil
+	case "nearby":
+		m.handleNearbyCommand()
+		return nil
+	case "trail":
+		return m.handleTrailCommand(args)
+	case "stats":
+		m.handleStatsCommand(args)
+		return nil
+	case "remember-exit":
+		m.handleRememberExitCommand(command)
+		return nil
+	case "legend":
+		m.handleLegendCommand(args)
+		return nil
+	case "go":
+		return m.handleGoCommand(args)
+	case "explore":
+		return m.handleExploreCommand()
+	case "stop":
+		m.handleStopCommand()
+		return nil
+	case "walkspeed":
+		m.handleWalkSpeedCommand(args)
+		return nil
+	case "numpadwalk":
+		m.handleNumpadWalkCommand(args)
+		return nil
+	case "hideprompt":
+		m.handleHidePromptCommand(args)
+		return nil
+	case "promptnewline":
+		m.handlePromptNewlineCommand(args)
+		return nil
+	case "split":
+		m.handleSplitCommand(args)
+		return nil
+	case "promptpattern":
+		m.handlePromptPatternCommand(strings.TrimSpace(command[len(parts[0]):]))
+		return nil
+	case "reconnect":
+		return m.handleReconnectCommand()
+	case "reconnect-on":
+		m.handleReconnectOnCommand(strings.TrimSpace(command[len(parts[0]):]))
+		return nil
+	case "collapse":
+		m.handleCollapseCommand(args)
+		return nil
+	case "links":
+		m.handleLinksCommand(args)
+		return nil
+	case "focus":
+		m.handleFocusCommand(command)
+		return nil
+	case "tab":
+		m.handleTabCommand(args)
+		return nil
+	case "ansi":
+		m.handleAnsiCommand(args)
+		return nil
+	case "theme":
+		m.handleThemeCommand(args)
+		return nil
+	case "telnet":
+		m.handleTelnetCommand(args)
+		return nil
+	case "affects":
+		m.handleAffectsCommand(command)
+		return nil
+	case "whereis":
+		m.handleWhereisCommand(args)
+		return nil
+	case "stat":
+		m.handleStatCommand(args)
+		return nil
+	case "remember":
+		m.handleRememberCommand(args)
+		return nil
+	case "inventory":
+		m.handleInventoryCommand(args)
+		return nil
+	case "wealth":
+		m.handleWealthCommand(args)
+		return nil
+	case "levels":
+		m.handleLevelsCommand(args)
+		return nil
+	case "stopwatch":
+		m.handleStopwatchCommand(args, time.Now())
+		return nil
+	case "combat":
+		m.handleCombatCommand(command)
+		return nil
+	case "target":
+		m.handleTargetCommand(args)
+		return nil
+	case "afk":
+		m.handleAfkCommand(command)
+		return nil
+	case "autofollow":
+		m.handleAutoFollowCommand(command)
+		return nil
+	case "autoassist":
+		m.handleAutoAssistCommand(command)
+		return nil
+	case "retrycast":
+		return m.handleRetryCastCommand(command)
+	case "autoloot":
+		m.handleAutoLootCommand(command)
+		return nil
+	case "wimpy":
+		m.handleWimpyCommand(args)
+		return nil
+	case "throttle":
+		m.handleThrottleCommand(args)
+		return nil
+	case "repeat":
+		return m.handleRepeatCommand(strings.TrimSpace(command[len(parts[0]):]))
+	case "notrig":
+		m.handleNotrigCommand(strings.TrimSpace(command[len(parts[0]):]))
+		return nil
+	case "learn":
+		m.handleLearnCommand(strings.TrimSpace(command[len(parts[0]):]))
+		return nil
+	case "log":
+		m.handleLogCommand(args)
+		return nil
+	case "record":
+		m.handleRecordCommand(args)
+		return nil
+	case "trigger":
+		m.handleTriggerCommand(command)
+		return nil
+	case "group":
+		m.handleGroupCommand(args)
+		return nil
+	case "triggers":
+		m.handleTriggersCommand(args)
+		return nil
+	case "edit":
+		m.handleEditCommand(args)
+		return nil
+	case "alias":
+		m.handleAliasCommand(command)
+		return nil
+	case "aliases":
+		m.handleAliasesCommand(args)
+		return nil
+	case "sub":
+		m.handleSubCommand(command)
+		return nil
+	case "subs":
+		m.handleSubsCommand(args)
+		return nil
+	case "capture":
+		m.handleCaptureCommand(command)
+		return nil
+	case "captures":
+		m.handleCapturesCommand(args)
+		return nil
+	case "mute":
+		m.handleMuteCommand(args)
+		return nil
+	case "unmute":
+		m.handleUnmuteCommand(args)
+		return nil
+	case "macro":
+		m.handleMacroCommand(command)
+		return nil
+	case "macros":
+		m.handleMacrosCommand(args)
+		return nil
+	case "ticktrigger":
+		m.handleTickTriggerCommand(command)
+		return nil
+	case "ticktriggers":
+		m.handleTickTriggersCommand(args)
+		return nil
+	case "share":
+		m.handleShareCommand(args)
+		return nil
+	case "echo":
+		m.handleEchoCommand(strings.TrimSpace(command[len(parts[0]):]))
+		return nil
+	case "set":
+		m.handleSetCommand(args)
+		return nil
+	case "unset":
+		m.handleUnsetCommand(args)
+		return nil
+	case "reload":
+		m.handleReloadCommand(args)
+		return nil
+	case "connect":
+		return m.handleConnectCommand(args)
+	case "sessions":
+		return m.handleSessionsCommand(args)
+	case "debug":
+		m.handleDebugCommand(args)
+		return nil
+	case "version":
+		m.handleVersionCommand()
+		return nil
+	case "help":
+		m.handleHelpCommand(args)
+		return nil
+	default:
+		m.output = append(m.output, m.colors().Error.Render(fmt.Sprintf("Error: Unknown command '/%s'. Type /help for available commands.", cmd)))
+		return nil
+	}
+}
+
+// handlePointCommand shows the next direction to reach a destination
+func (m *Model) handlePointCommand(args []string) {
+	if len(args) == 0 {
+		m.output = append(m.output, m.colors().Error.Render("Usage: /point <room search terms> or /point <number> [search terms]"))
+		return
+	}
+
+	var rooms []*mapper.Room
+	var query string
+
+	// Check if first argument is a number for room selection
+	if roomNum, err := fmt.Sscanf(args[0], "%d", new(int)); err == nil && roomNum == 1 {
+		var index int
+		fmt.Sscanf(args[0], "%d", &index)
+
+		// If only a number is provided, use lastRoomSearch
+		if len(args) == 1 {
+			if len(m.lastRoomSearch) == 0 {
+				m.output = append(m.output, m.colors().Error.Render("No previous room search to select from. Use /rooms to see all rooms."))
+				return
+			}
+			if index < 1 || index > len(m.lastRoomSearch) {
+				m.output = append(m.output, m.colors().Error.Render(fmt.Sprintf("Invalid room number. Must be between 1 and %d.", len(m.lastRoomSearch))))
+				return
+			}
+			rooms = []*mapper.Room{m.lastRoomSearch[index-1]}
+		} else {
+			// Number followed by search terms - search first, then select by index
+			query = strings.Join(args[1:], " ")
+			allMatches := m.searchRooms(query)
+
+			if len(allMatches) == 0 {
+				m.output = append(m.output, m.colors().Error.Render(fmt.Sprintf("No rooms found matching '%s'", query)))
+				return
+			}
+
+			if index < 1 || index > len(allMatches) {
+				m.output = append(m.output, m.colors().Error.Render(fmt.Sprintf("Invalid room number. Found %d rooms matching '%s'. Must be between 1 and %d.", len(allMatches), query, len(allMatches))))
+				return
+			}
+
+			rooms = []*mapper.Room{allMatches[index-1]}
+			m.setRoomSearch(allMatches)
+		}
+	} else {
+		// Regular search without numeric selection
+		query = strings.Join(args, " ")
+		rooms = m.searchRooms(query)
+	}
+
+	if len(rooms) == 0 {
+		m.output = append(m.output, m.colors().Error.Render(fmt.Sprintf("No rooms found matching '%s'", query)))
+		return
+	}
+
+	if len(rooms) > 1 {
+		// Store results for later disambiguation
+		m.setRoomSearch(rooms)
+
+		m.output = append(m.output, m.colors().Warn.Render(fmt.Sprintf("Found %d rooms matching '%s':", len(rooms), query)))
+		for i, room := range rooms {
+			if i >= 5 {
+				m.output = append(m.output, "  "+m.colors().Debug.Render(fmt.Sprintf("... and %d more", len(rooms)-5)))
+				break
+			}
+			m.output = append(m.output, "  "+m.colors().Highlight.Render(fmt.Sprintf("%d. %s", i+1, room.Title)))
+		}
+		m.output = append(m.output, m.colors().Warn.Render("Please be more specific, or use /point <number> to select a room."))
+		return
+	}
+
+	// Find path to the room
+	targetRoom := rooms[0]
+	path := m.worldMap.FindPath(targetRoom.ID)
+
+	if path == nil {
+		m.output = append(m.output, m.colors().Error.Render(fmt.Sprintf("No path found to '%s'", targetRoom.Title)))
+		return
+	}
+
+	if len(path) == 0 {
+		m.output = append(m.output, m.colors().Info.Render("You are already at that location!"))
+		return
+	}
+
+	m.output = append(m.output, m.colors().Info.Render(fmt.Sprintf("To reach '%s', go: %s", targetRoom.Title, path[0])))
+	m.warnAvoidedRooms(m.worldMap.AvoidedRoomsOnPath(m.worldMap.CurrentRoomID, path))
+}
+
+// handleWayfindCommand shows the full path to reach a destination
+func (m *Model) handleWayfindCommand(args []string) {
+	if len(args) == 0 {
+		m.output = append(m.output, m.colors().Error.Render("Usage: /wayfind <room search terms> or /wayfind <number> [search terms]"))
+		return
+	}
+
+	var rooms []*mapper.Room
+	var query string
+
+	// Check if first argument is a number for room selection
+	if roomNum, err := fmt.Sscanf(args[0], "%d", new(int)); err == nil && roomNum == 1 {
+		var index int
+		fmt.Sscanf(args[0], "%d", &index)
+
+		// If only a number is provided, use lastRoomSearch
+		if len(args) == 1 {
+			if len(m.lastRoomSearch) == 0 {
+				m.output = append(m.output, m.colors().Error.Render("No previous room search to select from. Use /rooms to see all rooms."))
+				return
+			}
+			if index < 1 || index > len(m.lastRoomSearch) {
+				m.output = append(m.output, m.colors().Error.Render(fmt.Sprintf("Invalid room number. Must be between 1 and %d.", len(m.lastRoomSearch))))
+				return
+			}
+			rooms = []*mapper.Room{m.lastRoomSearch[index-1]}
+		} else {
+			// Number followed by search terms - search first, then select by index
+			query = strings.Join(args[1:], " ")
+			allMatches := m.searchRooms(query)
+
+			if len(allMatches) == 0 {
+				m.output = append(m.output, m.colors().Error.Render(fmt.Sprintf("No rooms found matching '%s'", query)))
+				return
+			}
+
+			if index < 1 || index > len(allMatches) {
+				m.output = append(m.output, m.colors().Error.Render(fmt.Sprintf("Invalid room number. Found %d rooms matching '%s'. Must be between 1 and %d.", len(allMatches), query, len(allMatches))))
+				return
+			}
+
+			rooms = []*mapper.Room{allMatches[index-1]}
+			m.setRoomSearch(allMatches)
+		}
+	} else {
+		// Regular search without numeric selection
+		query = strings.Join(args, " ")
+		rooms = m.searchRooms(query)
+	}
+
+	if len(rooms) == 0 {
+		m.output = append(m.output, m.colors().Error.Render(fmt.Sprintf("No rooms found matching '%s'", query)))
+		return
+	}
+
+	if len(rooms) > 1 {
+		// Store results for later disambiguation
+		m.setRoomSearch(rooms)
+
+		m.output = append(m.output, m.colors().Warn.Render(fmt.Sprintf("Found %d rooms matching '%s':", len(rooms), query)))
+		for i, room := range rooms {
+			if i >= 5 {
+				m.output = append(m.output, "  "+m.colors().Debug.Render(fmt.Sprintf("... and %d more", len(rooms)-5)))
+				break
+			}
+			m.output = append(m.output, "  "+m.colors().Highlight.Render(fmt.Sprintf("%d. %s", i+1, room.Title)))
+		}
+		m.output = append(m.output, m.colors().Warn.Render("Please be more specific, or use /wayfind <number> to select a room."))
+		return
+	}
+
+	// Find path to the room
+	targetRoom := rooms[0]
+	pathSteps := m.worldMap.FindPathWithRooms(targetRoom.ID)
+
+	if pathSteps == nil {
+		m.output = append(m.output, m.colors().Error.Render(fmt.Sprintf("No path found to '%s'", targetRoom.Title)))
+		return
+	}
+
+	if len(pathSteps) == 0 {
+		m.output = append(m.output, m.colors().Info.Render("You are already at that location!"))
+		return
+	}
+
+	m.output = append(m.output, m.colors().Info.Render(fmt.Sprintf("Path to '%s' (%d steps):", targetRoom.Title, len(pathSteps))))
+	for i, step := range pathSteps {
+		m.output = append(m.output, "  "+m.colors().Highlight.Render(fmt.Sprintf("%d. %s -> %s", i+1, step.Direction, step.RoomTitle)))
+	}
+	m.warnAvoidedRooms(m.worldMap.AvoidedRoomsOnPath(m.worldMap.CurrentRoomID, stepDirections(pathSteps)))
+}
+
+// handlePathCommand shows the full path between two rooms given by durable room numbers
+func (m *Model) handlePathCommand(args []string) {
+	if len(args) != 2 {
+		m.output = append(m.output, m.colors().Error.Render("Usage: /path <from-number> <to-number>"))
+		return
+	}
+
+	var fromNum, toNum int
+	if _, err := fmt.Sscanf(args[0], "%d", &fromNum); err != nil {
+		m.output = append(m.output, m.colors().Error.Render(fmt.Sprintf("Error: Invalid room number '%s'", args[0])))
+		return
+	}
+	if _, err := fmt.Sscanf(args[1], "%d", &toNum); err != nil {
+		m.output = append(m.output, m.colors().Error.Render(fmt.Sprintf("Error: Invalid room number '%s'", args[1])))
+		return
+	}
+
+	fromRoom := m.worldMap.GetRoomByNumber(fromNum)
+	if fromRoom == nil {
+		m.output = append(m.output, m.colors().Error.Render(fmt.Sprintf("No room with number %d. Use /rooms to see room numbers.", fromNum)))
+		return
+	}
+	toRoom := m.worldMap.GetRoomByNumber(toNum)
+	if toRoom == nil {
+		m.output = append(m.output, m.colors().Error.Render(fmt.Sprintf("No room with number %d. Use /rooms to see room numbers.", toNum)))
+		return
+	}
+
+	pathSteps := m.worldMap.FindPathWithRoomsFrom(fromRoom.ID, toRoom.ID)
+
+	if pathSteps == nil {
+		m.output = append(m.output, m.colors().Error.Render(fmt.Sprintf("No path found from '%s' to '%s'", fromRoom.Title, toRoom.Title)))
+		return
+	}
+
+	if len(pathSteps) == 0 {
+		m.output = append(m.output, m.colors().Info.Render("Those are the same room!"))
+		return
+	}
+
+	m.output = append(m.output, m.colors().Info.Render(fmt.Sprintf("Path from '%s' to '%s' (%d steps):", fromRoom.Title, toRoom.Title, len(pathSteps))))
+	for i, step := range pathSteps {
+		m.output = append(m.output, "  "+m.colors().Highlight.Render(fmt.Sprintf("%d. %s -> %s", i+1, step.Direction, step.RoomTitle)))
+	}
+	m.warnAvoidedRooms(m.worldMap.AvoidedRoomsOnPath(fromRoom.ID, stepDirections(pathSteps)))
+}
+
+// handleAvoidCommand toggles or lists rooms that pathfinding should avoid
+func (m *Model) handleAvoidCommand(args []string) {
+	if len(args) > 0 && strings.ToLower(args[0]) == "list" {
+		var avoided []*mapper.Room
+		for _, room := range m.worldMap.GetAllRooms() {
+			if room.Avoid {
+				avoided = append(avoided, room)
+			}
+		}
+
+		if len(avoided) == 0 {
+			m.output = append(m.output, m.colors().Warn.Render("No rooms are marked as avoided."))
+			return
+		}
+
+		sort.Slice(avoided, func(i, j int) bool {
+			return m.worldMap.GetRoomNumber(avoided[i].ID) < m.worldMap.GetRoomNumber(avoided[j].ID)
+		})
+
+		m.output = append(m.output, m.colors().Info.Render(fmt.Sprintf("=== Avoided Rooms (%d) ===", len(avoided))))
+		for _, room := range avoided {
+			m.output = append(m.output, "  "+m.colors().Highlight.Render(fmt.Sprintf("%d. %s", m.worldMap.GetRoomNumber(room.ID), room.Title)))
+		}
+		return
+	}
+
+	var room *mapper.Room
+	if len(args) > 0 {
+		var roomNum int
+		if _, err := fmt.Sscanf(args[0], "%d", &roomNum); err != nil {
+			m.output = append(m.output, m.colors().Error.Render("Usage: /avoid [room-number|list]"))
+			return
+		}
+		room = m.worldMap.GetRoomByNumber(roomNum)
+		if room == nil {
+			m.output = append(m.output, m.colors().Error.Render(fmt.Sprintf("No room with number %d. Use /rooms to see room numbers.", roomNum)))
+			return
+		}
+	} else {
+		room = m.worldMap.GetCurrentRoom()
+		if room == nil {
+			m.output = append(m.output, m.colors().Error.Render("No current room. You need to be in a mapped location."))
+			return
+		}
+	}
+
+	room.Avoid = !room.Avoid
+	m.worldMap.Save()
+
+	if room.Avoid {
+		m.output = append(m.output, m.colors().Info.Render(fmt.Sprintf("Marked '%s' as avoided. Paths will route around it when possible.", room.Title)))
+	} else {
+		m.output = append(m.output, m.colors().Info.Render(fmt.Sprintf("'%s' is no longer avoided.", room.Title)))
+	}
+}
+
+// handleMergeCommand merges a duplicate room into another, or lists likely
+// duplicates
+func (m *Model) handleMergeCommand(args []string) {
+	if m.worldMap == nil {
+		m.output = append(m.output, m.colors().Error.Render("Map not available"))
+		return
+	}
+
+	if len(args) == 1 && strings.ToLower(args[0]) == "suggest" {
+		candidates := m.worldMap.MergeCandidates()
+		if len(candidates) == 0 {
+			m.output = append(m.output, m.colors().Warn.Render("No likely duplicate rooms found."))
+			return
+		}
+		m.output = append(m.output, m.colors().Info.Render(fmt.Sprintf("=== Likely Duplicate Rooms (%d) ===", len(candidates))))
+		for _, pair := range candidates {
+			m.output = append(m.output, fmt.Sprintf("  %s  %s", m.colors().Highlight.Render(fmt.Sprintf("/merge %d %d", m.worldMap.GetRoomNumber(pair[0].ID), m.worldMap.GetRoomNumber(pair[1].ID))), pair[0].Title))
+		}
+		return
+	}
+
+	if len(args) != 2 {
+		m.output = append(m.output, m.colors().Warn.Render("Usage: /merge <room-number> <duplicate-number> or /merge suggest"))
+		return
+	}
+
+	var rooms [2]*mapper.Room
+	for i, arg := range args {
+		var roomNum int
+		if _, err := fmt.Sscanf(arg, "%d", &roomNum); err != nil {
+			m.output = append(m.output, m.colors().Warn.Render("Usage: /merge <room-number> <duplicate-number> or /merge suggest"))
+			return
+		}
+		rooms[i] = m.worldMap.GetRoomByNumber(roomNum)
+		if rooms[i] == nil {
+			m.output = append(m.output, m.colors().Error.Render(fmt.Sprintf("No room with number %d. Use /rooms to see room numbers.", roomNum)))
+			return
+		}
+	}
+
+	keep, duplicate := rooms[0], rooms[1]
+	keepNum, duplicateNum := m.worldMap.GetRoomNumber(keep.ID), m.worldMap.GetRoomNumber(duplicate.ID)
+	if err := m.worldMap.MergeRooms(keep.ID, duplicate.ID); err != nil {
+		m.output = append(m.output, m.colors().Error.Render(fmt.Sprintf("Error: %v", err)))
+		return
+	}
+	if err := m.worldMap.Save(); err != nil {
+		m.output = append(m.output, m.colors().Error.Render(fmt.Sprintf("Error saving map: %v", err)))
+	}
+
+	// Legend numbers may refer to the removed room
+	m.mapLegend = nil
+	m.mapLegendRooms = nil
+
+	m.output = append(m.output, m.colors().Info.Render(fmt.Sprintf("Merged room #%d into #%d '%s' (%d exits)", duplicateNum, keepNum, keep.Title, len(keep.Exits))))
+}
+
+// handleDigCommand creates a room in a direction from the current room, for
+// areas the mapper can't record on its own
+func (m *Model) handleDigCommand(arg string) {
+	if m.worldMap == nil {
+		m.output = append(m.output, m.colors().Error.Render("Map not available"))
+		return
+	}
+
+	direction, title, _ := strings.Cut(arg, " ")
+	title = strings.TrimSpace(title)
+	if len(title) >= 2 && strings.HasPrefix(title, "\"") && strings.HasSuffix(title, "\"") {
+		title = title[1 : len(title)-1]
+	}
+	if direction == "" || strings.TrimSpace(title) == "" {
+		m.output = append(m.output, m.colors().Warn.Render("Usage: /dig <direction> \"<room title>\""))
+		return
+	}
+
+	room, err := m.worldMap.Dig(direction, title)
+	if err != nil {
+		m.output = append(m.output, m.colors().Error.Render(fmt.Sprintf("Error: %v", err)))
+		return
+	}
+	if err := m.worldMap.Save(); err != nil {
+		m.output = append(m.output, m.colors().Error.Render(fmt.Sprintf("Error saving map: %v", err)))
+	}
+
+	m.output = append(m.output, m.colors().Info.Render(fmt.Sprintf("Dug %s to room #%d '%s'", mapper.DetectMovement(direction), m.worldMap.GetRoomNumber(room.ID), room.Title)))
+}
+
+// warnAvoidedRooms warns when the only path found passes through avoided rooms
+func (m *Model) warnAvoidedRooms(avoided []*mapper.Room) {
+	if len(avoided) == 0 {
+		return
+	}
+
+	titles := make([]string, len(avoided))
+	for i, room := range avoided {
+		titles[i] = room.Title
+	}
+	m.output = append(m.output, m.colors().Warn.Render(fmt.Sprintf("Warning: no safe route found, path passes through avoided rooms: %s", strings.Join(titles, ", "))))
+}
+
+// stepDirections extracts the movement directions from a list of path steps
+func stepDirections(steps []mapper.PathStep) []string {
+	directions := make([]string, len(steps))
+	for i, step := range steps {
+		directions[i] = step.Direction
+	}
+	return directions
+}
+
+// handleMapCommand shows information about the current map
+func (m *Model) handleMapCommand(args []string) {
+	if len(args) > 0 {
+		switch args[0] {
+		case "list":
+			if len(args) == 1 {
+				m.listNamedMaps()
+				return
+			}
+		case "save", "load", "switch":
+			if len(args) == 2 {
+				m.handleNamedMapCommand(args[0], args[1])
+			} else {
+				m.output = append(m.output, m.colors().Warn.Render(fmt.Sprintf("Usage: /map %s <name>", args[0])))
+			}
+			return
+		case "orient", "compass":
+			m.handleMapViewCommand(args)
+			return
+		}
+	}
+	if len(args) == 1 {
+		switch args[0] {
+		case "html":
+			m.handleMapHTMLCommand()
+			return
+		case "deadends", "find-deadends":
+			m.showDeadEnds()
+			return
+		case "unexplored":
+			m.showUnexploredExits()
+			return
+		case "orphans":
+			m.showOrphans()
+			return
+		}
+	}
+	if len(args) > 0 {
+		m.handleMapGridCommand(args)
+		return
+	}
+
+	current := m.worldMap.GetCurrentRoom()
+
+	m.output = append(m.output, m.colors().Info.Render("=== Map Information ==="))
+	if m.mapName != "" {
+		m.output = append(m.output, "Map: "+m.colors().Highlight.Render(m.mapName))
+	}
+	m.output = append(m.output, "Total rooms explored: "+m.colors().Highlight.Render(fmt.Sprintf("%d", len(m.worldMap.Rooms))))
+
+	if current != nil {
+		m.output = append(m.output, "Current room: "+m.colors().Highlight.Render(current.Title))
+		if len(current.Exits) > 0 {
+			exits := []string{}
+			for dir := range current.Exits {
+				exits = append(exits, dir)
+			}
+			m.output = append(m.output, "Exits: "+m.colors().Highlight.Render(strings.Join(exits, ", ")))
+		}
+		if current.Position != nil {
+			m.output = append(m.output, "Position: "+m.colors().Highlight.Render(current.Position.String()))
+		}
+	} else {
+		m.output = append(m.output, m.colors().Debug.Render("No current room detected yet"))
+	}
+
+	if conflicts := m.worldMap.CoordinateConflicts(); len(conflicts) > 0 {
+		m.output = append(m.output, m.colors().Warn.Render(fmt.Sprintf("Coordinate conflicts: %d (rooms sharing a grid cell, shown in red)", len(conflicts))))
+		for _, rooms := range conflicts {
+			titles := make([]string, len(rooms))
+			for i, room := range rooms {
+				titles[i] = room.Title
+			}
+			m.output = append(m.output, fmt.Sprintf("  %s: %s", m.colors().Highlight.Render(rooms[0].Position.String()), strings.Join(titles, ", ")))
+		}
+	}
+}
+
+// defaultMapName names the server's own map in /map load and /map switch
+const defaultMapName = "default"
+
+// listNamedMaps lists the maps saved with /map save, marking the one in use
+func (m *Model) listNamedMaps() {
+	names, err := mapper.ListNamedMaps()
+	if err != nil {
+		m.output = append(m.output, m.colors().Error.Render(fmt.Sprintf("Error listing maps: %v", err)))
+		return
+	}
+
+	m.output = append(m.output, m.colors().Info.Render("=== Maps ==="))
+	for _, name := range append([]string{defaultMapName}, names...) {
+		line := "  " + m.colors().Highlight.Render(name)
+		if name == m.mapName || (name == defaultMapName && m.mapName == "") {
+			line += " (in use)"
+		}
+		m.output = append(m.output, line)
+	}
+	if len(names) == 0 {
+		m.output = append(m.output, m.colors().Debug.Render("No named maps yet. /map save <name> saves a copy of this one."))
+	}
+}
+
+// handleNamedMapCommand saves a copy of the map under a name, or swaps the
+// map in use for a named one. /map load only loads maps that were saved;
+// /map switch saves the map in use first and starts an empty map for a
+// new name. The default map is the server's own.
+func (m *Model) handleNamedMapCommand(action, name string) {
+	if name != defaultMapName && !mapper.ValidMapName(name) {
+		m.output = append(m.output, m.colors().Error.Render(fmt.Sprintf("Error: invalid map name '%s' (use letters, digits, - and _)", name)))
+		return
+	}
+
+	if action == "save" {
+		if name == defaultMapName {
+			m.output = append(m.output, m.colors().Error.Render(fmt.Sprintf("Error: '%s' is the server's own map; pick another name", defaultMapName)))
+			return
+		}
+		if err := m.worldMap.SaveNamed(name); err != nil {
+			m.output = append(m.output, m.colors().Error.Render(fmt.Sprintf("Error saving map: %v", err)))
+			return
+		}
+		m.output = append(m.output, m.colors().Info.Render(fmt.Sprintf("Saved a copy of the map (%d rooms) as '%s'", len(m.worldMap.Rooms), name)))
+		return
+	}
+
+	if action == "load" && name != defaultMapName && !mapper.NamedMapExists(name) {
+		m.output = append(m.output, m.colors().Error.Render(fmt.Sprintf("Error: no map named '%s' (see /map list)", name)))
+		return
+	}
+
+	if action == "switch" {
+		m.flushMap()
+		if err := m.worldMap.Save(); err != nil {
+			m.output = append(m.output, m.colors().Error.Render(fmt.Sprintf("Error saving map: %v", err)))
+			return
+		}
+	}
+
+	var worldMap *mapper.Map
+	var err error
+	if name == defaultMapName {
+		worldMap, err = mapper.LoadForServer(m.host, m.port)
+	} else {
+		worldMap, err = mapper.LoadNamed(name)
+	}
+	if err != nil {
+		m.output = append(m.output, m.colors().Error.Render(fmt.Sprintf("Error loading map: %v", err)))
+		return
+	}
+	if name != defaultMapName && !mapper.NamedMapExists(name) {
+		// A new name: create its file so /map list shows it
+		if err := worldMap.Save(); err != nil {
+			m.output = append(m.output, m.colors().Error.Render(fmt.Sprintf("Error saving map: %v", err)))
+			return
+		}
+	}
+
+	m.worldMap = worldMap
+	m.mapName = name
+	if name == defaultMapName {
+		m.mapName = ""
+	}
+	if worldMap.BarsoomMode {
+		m.barsoomMode = true
+	}
+
+	// Where we are in the new map isn't known until the next room is seen
+	m.pendingMovement = ""
+	m.relocalizePending = true
+	m.trail = nil
+	m.mapLegend = nil
+	m.mapLegendRooms = nil
+	m.output = append(m.output, m.colors().Info.Render(fmt.Sprintf("Using map '%s' with %d room(s)", name, len(worldMap.Rooms))))
+}
+
+// showDeadEnds lists the rooms with a single exit
+func (m *Model) showDeadEnds() {
+	rooms := m.worldMap.DeadEnds()
+	if len(rooms) == 0 {
+		m.output = append(m.output, m.colors().Warn.Render("No dead ends found."))
+		return
+	}
+	m.output = append(m.output, m.colors().Info.Render(fmt.Sprintf("=== Dead Ends (%d) ===", len(rooms))))
+	for _, room := range rooms {
+		// Show the one way out
+		for direction := range room.Exits {
+			m.output = append(m.output, fmt.Sprintf("  %s %s", m.colors().Highlight.Render(fmt.Sprintf("%d. %s", m.worldMap.GetRoomNumber(room.ID), room.Title)), m.colors().Debug.Render(fmt.Sprintf("[%s]", direction))))
+		}
+		m.tagRoomLine(room.ID)
+	}
+}
+
+// showUnexploredExits lists the exits whose destination hasn't been seen,
+// as a checklist of where to explore next
+func (m *Model) showUnexploredExits() {
+	exits := m.worldMap.UnexploredExits()
+	if len(exits) == 0 {
+		m.output = append(m.output, m.colors().Warn.Render("No unexplored exits: every known exit has been taken."))
+		return
+	}
+	m.output = append(m.output, m.colors().Info.Render(fmt.Sprintf("=== Unexplored Exits (%d) ===", len(exits))))
+	for _, exit := range exits {
+		m.output = append(m.output, fmt.Sprintf("  %s %s", m.colors().Highlight.Render(fmt.Sprintf("%d. %s", m.worldMap.GetRoomNumber(exit.Room.ID), exit.Room.Title)), m.colors().Debug.Render(fmt.Sprintf("[%s]", exit.Direction))))
+		m.tagRoomLine(exit.Room.ID)
+	}
+	m.output = append(m.output, m.colors().Debug.Render("Click a room or use /go <n> to walk there."))
+}
+
+// showOrphans lists the rooms that can't be reached from the current room
+func (m *Model) showOrphans() {
+	if m.worldMap.GetCurrentRoom() == nil {
+		m.output = append(m.output, m.colors().Error.Render("No current room detected yet."))
+		return
+	}
+	rooms := m.worldMap.Orphans(m.worldMap.CurrentRoomID)
+	if len(rooms) == 0 {
+		m.output = append(m.output, m.colors().Info.Render("Every room can be reached from here."))
+		return
+	}
+	m.output = append(m.output, m.colors().Info.Render(fmt.Sprintf("=== Unreachable Rooms (%d) ===", len(rooms))))
+	for _, room := range rooms {
+		m.output = append(m.output, "  "+m.colors().Highlight.Render(fmt.Sprintf("%d. %s", m.worldMap.GetRoomNumber(room.ID), room.Title)))
+	}
+	m.output = append(m.output, m.colors().Debug.Render("No known exits lead here from the current room. Use /merge or /dig to link them."))
+}
+
+// handleMapGridCommand switches the map panel between following exits from
+// the current room and drawing rooms at their assigned coordinates
+func (m *Model) handleMapGridCommand(args []string) {
+	if args[0] != "grid" || len(args) > 2 {
+		m.output = append(m.output, m.colors().Warn.Render("Usage: /map [grid [on|off] | orient [north|east|south|west] | compass [on|off] | html | deadends | unexplored | orphans | list | save|load|switch <name>]"))
+		return
+	}
+
+	if len(args) == 2 {
+		switch args[1] {
+		case "on":
+			m.worldMap.UseCoordinates = true
+		case "off":
+			m.worldMap.UseCoordinates = false
+		default:
+			m.output = append(m.output, m.colors().Warn.Render("Usage: /map grid [on|off]"))
+			return
+		}
+		m.worldMap.Save()
+	}
+
+	if m.worldMap.UseCoordinates {
+		m.output = append(m.output, m.colors().Info.Render("Grid map: on (rooms are drawn at their coordinates, one level at a time)"))
+	} else {
+		m.output = append(m.output, m.colors().Info.Render("Grid map: off (rooms are laid out by following exits)"))
+	}
+}
+
+// handleMapViewCommand turns the map panel so another direction is up, or
+// draws the current room's exits as a compass below it
+func (m *Model) handleMapViewCommand(args []string) {
+	if len(args) > 2 {
+		m.output = append(m.output, m.colors().Warn.Render("Usage: /map orient [north|east|south|west] | /map compass [on|off]"))
+		return
+	}
+
+	if args[0] == "orient" {
+		if len(args) == 2 {
+			orientation, ok := mapper.ParseOrientation(args[1])
+			if !ok {
+				m.output = append(m.output, m.colors().Warn.Render("Usage: /map orient [north|east|south|west]"))
+				return
+			}
+			m.worldMap.Orientation = ""
+			if orientation != mapper.NorthUp {
+				m.worldMap.Orientation = orientation.String()
+			}
+			m.worldMap.Save()
+		}
+		orientation, _ := mapper.ParseOrientation(m.worldMap.Orientation)
+		m.output = append(m.output, m.colors().Info.Render(fmt.Sprintf("Map orientation: %s is up", orientation)))
+		return
+	}
+
+	if len(args) == 2 {
+		switch args[1] {
+		case "on":
+			m.worldMap.ShowCompass = true
+		case "off":
+			m.worldMap.ShowCompass = false
+		default:
+			m.output = append(m.output, m.colors().Warn.Render("Usage: /map compass [on|off]"))
+			return
+		}
+		m.worldMap.Save()
+	}
+
+	if m.worldMap.ShowCompass {
+		m.output = append(m.output, m.colors().Info.Render("Compass: on (the current room's exits are shown below the map)"))
+	} else {
+		m.output = append(m.output, m.colors().Info.Render("Compass: off"))
+	}
+}
+
+// handleMapHTMLCommand draws the whole map for the web client, which shows
+// it in a panel where clicking a room walks there with /go
+func (m *Model) handleMapHTMLCommand() {
+	if m.webSessionID == "" {
+		m.output = append(m.output, m.colors().Error.Render("Error: /map html is only available in web mode"))
+		m.output = append(m.output, m.colors().Debug.Render("Start the client with --web flag to view the map in a browser"))
+		return
+	}
+
+	// The TUI runs in the session directory, where the web server reads it
+	if err := os.WriteFile("map.svg", []byte(m.worldMap.RenderSVG()), 0600); err != nil {
+		m.output = append(m.output, m.colors().Error.Render(fmt.Sprintf("Error writing map: %v", err)))
+		return
+	}
+
+	m.output = append(m.output, m.colors().Info.Render(fmt.Sprintf("Showing the map of %d room(s) in the browser", len(m.worldMap.Rooms))))
+	m.output = append(m.output, m.colors().Debug.Render("Click a room to walk there; run /map html again to refresh it"))
+
+	writeWebClientHint(map[string]string{
+		"type": "map_html",
+		"url":  fmt.Sprintf("/map?id=%s", m.webSessionID),
+	})
+}
+
+// handleShareCommand generates a shareable URL for web sessions; with
+// "view", a spectator URL that shows the session without giving control
+func (m *Model) handleShareCommand(args []string) {
+	if m.webSessionID == "" || m.webServerURL == "" {
+		m.output = append(m.output, m.colors().Error.Render("Error: /share command is only available in web mode"))
+		m.output = append(m.output, m.colors().Debug.Render("Start the client with --web flag to enable session sharing"))
+		return
+	}
+
+	spectator := false
+	if len(args) > 0 {
+		if strings.ToLower(args[0]) != "view" {
+			m.output = append(m.output, m.colors().Error.Render("Usage: /share [view]"))
+			return
+		}
+		if m.webViewID == "" {
+			m.output = append(m.output, m.colors().Error.Render("Error: this web server doesn't offer spectator links"))
+			return
+		}
+		spectator = true
+	}
+
+	if !spectator {
+		// Spectators watch this same screen, so the link that gives
+		// control only goes to the browser's share banner
+		m.output = append(m.output, m.colors().Info.Render("=== Share This Session ==="))
+		m.output = append(m.output, m.colors().Debug.Render("The link is shown above the terminal with a copy button, and not here, where spectators could see it"))
+		m.output = append(m.output, m.colors().Debug.Render("Anyone who opens it will see and control the same session"))
+		m.output = append(m.output, m.colors().Debug.Render("Use /share view for a link that can only watch"))
+		writeWebClientHint(map[string]string{
+			"type": "share_url",
+			"url":  fmt.Sprintf("%s/?id=%s", m.webServerURL, m.webSessionID),
+		})
+		return
+	}
+
+	shareURL := fmt.Sprintf("%s/?view=%s", m.webServerURL, m.webViewID)
+	m.output = append(m.output, m.colors().Info.Render("=== Share This Session (View Only) ==="))
+	m.output = append(m.output, m.hyperlink(shareURL, m.colors().Highlight.Render(shareURL)))
+	m.output = append(m.output, "")
+
+	// Show a QR code so the session can be opened from a phone
+	if code, err := qrcode.Encode(shareURL); err == nil {
+		for _, line := range code.Render(2) {
+			if !m.plainText() {
+				line = "\x1b[97;40m" + line + "\x1b[0m"
+			}
+			m.output = append(m.output, line)
+		}
+		m.output = append(m.output, "")
+	}
+
+	m.output = append(m.output, m.colors().Debug.Render("Anyone who opens this URL can watch the session, but what they type is ignored"))
+
+	// Let the browser offer a copy button for the URL
+	writeWebClientHint(map[string]string{
+		"type": "share_url",
+		"url":  shareURL,
+	})
+}
+
+// handleHelpCommand shows available client commands or detailed help for a specific command
+func (m *Model) handleHelpCommand(args []string) {
+	// If a specific command is requested, show detailed help
+	if len(args) > 0 {
+		m.showDetailedHelp(strings.ToLower(args[0]))
+		return
+	}
+
+	// Show general help
+	m.output = append(m.output, m.colors().Info.Render("=== Client Commands ==="))
+	m.output = append(m.output, "  "+m.colors().Highlight.Render("/point <room>")+"            - Show next direction to reach a room")
+	m.output = append(m.output, "  "+m.colors().Highlight.Render("/wayfind <room>")+"         - Show full path to reach a room")
+	m.output = append(m.output, "  "+m.colors().Highlight.Render("/path <from> <to>")+"       - Show path between two numbered rooms")
+	m.output = append(m.output, "  "+m.colors().Highlight.Render("/avoid [n|list]")+"         - Toggle whether pathfinding avoids a room")
+	m.output = append(m.output, "  "+m.colors().Highlight.Render("/merge <n1> <n2>|suggest")+" - Merge duplicate room n2 into n1, or list likely duplicates")
+	m.output = append(m.output, "  "+m.colors().Highlight.Render("/dig <dir> \"<title>\"")+" - Create a room and exits by hand where mapping doesn't work")
+	m.output = append(m.output, "  "+m.colors().Highlight.Render("/remember-exit \"<cmd>\"")+" - Map a command like 'enter portal' as an exit of this room")
+	m.output = append(m.output, "  "+m.colors().Highlight.Render("/go <room>")+"              - Auto-walk to a room (see /walkspeed)")
+	m.output = append(m.output, "  "+m.colors().Highlight.Render("/explore")+"                - Auto-walk to the nearest exit not taken yet")
+	m.output = append(m.output, "  "+m.colors().Highlight.Render("/stop")+"                   - Stop auto-walk or command queue")
+	m.output = append(m.output, "  "+m.colors().Highlight.Render("/repeat <n> <command>")+"   - Send a command n times through the command queue")
+	m.output = append(m.output, "  "+m.colors().Highlight.Render("/walkspeed [ms|fast]")+"    - Show or set the auto-walk speed")
+	m.output = append(m.output, "  "+m.colors().Highlight.Render("/numpadwalk [on|off]")+"    - Walk with numpad/arrow keys on an empty input")
+	m.output = append(m.output, "  "+m.colors().Highlight.Render("/hideprompt [on|off]")+"    - Show the stat prompt in the status bar, not the output")
+	m.output = append(m.output, "  "+m.colors().Highlight.Render("/promptnewline [on|off]")+" - Put typed commands on a new line after the prompt")
+	m.output = append(m.output, "  "+m.colors().Highlight.Render("/promptpattern [\"re\"|off]")+" - Recognize this MUD's prompt with a regular expression")
+	m.output = append(m.output, "  "+m.colors().Highlight.Render("/collapse [on|off]")+"      - Show runs of blank lines from the MUD as a single blank line")
+	m.output = append(m.output, "  "+m.colors().Highlight.Render("/links [on|off]")+"         - Make web addresses in MUD output clickable")
+	m.output = append(m.output, "  "+m.colors().Highlight.Render("/focus \"pattern\" [hide]")+" - Dim (or hide) lines not matching a pattern until /focus off")
+	m.output = append(m.output, "  "+m.colors().Highlight.Render("/tab [game|chat|system]")+" - Show all output, only chat, or only the client's messages")
+	m.output = append(m.output, "  "+m.colors().Highlight.Render("/split [on|off|ratio <n>]")+" - Keep scrollback above live output, or set its share of the height")
+	m.output = append(m.output, "  "+m.colors().Highlight.Render("/ansi [on|off]")+"          - Turn off to show all output as plain text without colors")
+	m.output = append(m.output, "  "+m.colors().Highlight.Render("/theme [set <kind> <color>]")+" - Show or change the colors of the client's messages")
+	m.output = append(m.output, "  "+m.colors().Highlight.Render("/affects [clear|expire]")+" - List tracked affects or set an expiry action")
+	m.output = append(m.output, "  "+m.colors().Highlight.Render("/whereis [player]")+"       - Show where a player was last seen (from tells and rooms)")
+	m.output = append(m.output, "  "+m.colors().Highlight.Render("/mute [player|list]")+"     - Hide a player's tells, says and channel messages")
+	m.output = append(m.output, "  "+m.colors().Highlight.Render("/unmute <player>")+"        - Show a muted player's messages again")
+	m.output = append(m.output, "  "+m.colors().Highlight.Render("/stat [item]")+"            - Show the remembered stats of an identified item")
+	m.output = append(m.output, "  "+m.colors().Highlight.Render("/remember [name]")+"        - Remember the MUD's last response as an item's stats")
+	m.output = append(m.output, "  "+m.colors().Highlight.Render("/inventory [auto secs]")+"  - Show the inventory and equipment panels' status, or auto-refresh")
+	m.output = append(m.output, "  "+m.colors().Highlight.Render("/combat [patterns]")+"      - Show damage dealt and taken, or manage damage patterns")
+	m.output = append(m.output, "  "+m.colors().Highlight.Render("/target [name|off]")+"      - Set the combat target, highlighted and sent for <target>")
+	m.output = append(m.output, "  "+m.colors().Highlight.Render("/wealth [reset]")+"         - Show gold picked up and carried this session, and gold per hour")
+	m.output = append(m.output, "  "+m.colors().Highlight.Render("/levels [clear]")+"         - Show when each level was gained and estimate the next one")
+	m.output = append(m.output, "  "+m.colors().Highlight.Render("/stopwatch [start|lap|stop]")+" - Time a zone run or respawn, with laps")
+	m.output = append(m.output, "  "+m.colors().Highlight.Render("/afk [secs [cmd]|off]")+"   - Send a command (e.g. rest) when idle for a while")
+	m.output = append(m.output, "  "+m.colors().Highlight.Render("/autoloot [on [cmd]|off]")+" - Queue a loot command (e.g. get all corpse) after a kill")
+	m.output = append(m.output, "  "+m.colors().Highlight.Render("/wimpy [hp|pct%|off]")+"    - Flee when hit points drop low (GMCP/MSDP, else the prompt)")
+	m.output = append(m.output, "  "+m.colors().Highlight.Render("/autofollow [leader|off]")+" - Follow and group when the leader invites you")
+	m.output = append(m.output, "  "+m.colors().Highlight.Render("/autoassist [leader|off]")+" - Assist the leader when they start a fight")
+	m.output = append(m.output, "  "+m.colors().Highlight.Render("/retrycast \"cast\" \"fail\" [n]")+" - Cast a spell, casting again when it fails")
+	m.output = append(m.output, "  "+m.colors().Highlight.Render("/throttle [ms|off]")+"      - Space out commands sent to the MUD (flood protection)")
+	m.output = append(m.output, "  "+m.colors().Highlight.Render("/log json start|stop")+"    - Write MUD output to a JSON lines file for analysis")
+	m.output = append(m.output, "  "+m.colors().Highlight.Render("/log redact add <text>")+"  - Write text (e.g. a name) to the logs as [REDACTED]")
+	m.output = append(m.output, "  "+m.colors().Highlight.Render("/record session [file]")+"  - Record the raw MUD stream for replay with --replay")
+	m.output = append(m.output, "  "+m.colors().Highlight.Render("/telnet <cmd> <option>")+"  - Send a raw telnet negotiation (for debugging)")
+	m.output = append(m.output, "  "+m.colors().Highlight.Render("/map [grid on|off|html]")+" - Show map information, draw it from coordinates, or in the browser")
+	m.output = append(m.output, "  "+m.colors().Highlight.Render("/map deadends|unexplored|orphans")+" - List dead ends, exits not yet taken, or unreachable rooms")
+	m.output = append(m.output, "  "+m.colors().Highlight.Render("/map list|save|load|switch [name]")+" - Keep several named maps and swap between them")
+	m.output = append(m.output, "  "+m.colors().Highlight.Render("/rooms [filter]")+"         - List all known rooms (optionally filtered)")
+	m.output = append(m.output, "  "+m.colors().Highlight.Render("/nearby")+"                 - List all rooms within 5 steps")
+	m.output = append(m.output, "  "+m.colors().Highlight.Render("/trail [back [n]]")+"       - List the last rooms visited, or walk back along them")
+	m.output = append(m.output, "  "+m.colors().Highlight.Render("/stats [reset]")+"          - Show moves and new rooms this session, and how much is explored")
+	m.output = append(m.output, "  "+m.colors().Highlight.Render("/legend [clear]")+"         - List all rooms currently on the map")
+	m.output = append(m.output, "  "+m.colors().Highlight.Render("/trigger \"pat\" \"act\"")+" - Add a trigger (pattern can use <var>)")
+	m.output = append(m.output, "  "+m.colors().Highlight.Render("/trigger test \"line\"")+"    - Dry-run a line against the triggers")
+	m.output = append(m.output, "  "+m.colors().Highlight.Render("/triggers list")+"          - List all triggers")
+	m.output = append(m.output, "  "+m.colors().Highlight.Render("/triggers remove <n>")+"    - Remove trigger by number")
+	m.output = append(m.output, "  "+m.colors().Highlight.Render("/triggers disable <n>")+"   - Turn a trigger off without removing it (enable turns it back on)")
+	m.output = append(m.output, "  "+m.colors().Highlight.Render("/edit triggers")+"          - Edit, reorder and switch triggers on/off full screen")
+	m.output = append(m.output, "  "+m.colors().Highlight.Render("/notrig <command>")+"       - Send a command without triggers firing on its reply")
+	m.output = append(m.output, "  "+m.colors().Highlight.Render("/learn [n action]")+"       - Make a trigger from a recent line, previewed before saving")
+	m.output = append(m.output, "  "+m.colors().Highlight.Render("/ticktrigger # \"cmd\"")+"  - Add a tick trigger (fires at T:#)")
+	m.output = append(m.output, "  "+m.colors().Highlight.Render("/ticktriggers list")+"     - List all tick triggers")
+	m.output = append(m.output, "  "+m.colors().Highlight.Render("/ticktriggers remove <n>")+" - Remove tick trigger by number")
+	m.output = append(m.output, "  "+m.colors().Highlight.Render("/alias \"name\" \"tmpl\"")+"  - Add an alias (template can use <var>)")
+	m.output = append(m.output, "  "+m.colors().Highlight.Render("/aliases list")+"           - List all aliases")
+	m.output = append(m.output, "  "+m.colors().Highlight.Render("/aliases remove <n>")+"     - Remove alias by number")
+	m.output = append(m.output, "  "+m.colors().Highlight.Render("/group enable|disable <g>")+" - Turn a group of aliases and triggers on or off")
+	m.output = append(m.output, "  "+m.colors().Highlight.Render("/sub \"prefix\" \"text\"")+"    - Rewrite commands starting with prefix (e.g. ' to say)")
+	m.output = append(m.output, "  "+m.colors().Highlight.Render("/subs list")+"              - List all substitutions")
+	m.output = append(m.output, "  "+m.colors().Highlight.Render("/subs remove <n>")+"        - Remove substitution by number")
+	m.output = append(m.output, "  "+m.colors().Highlight.Render("/capture <name> \"pat\"")+"  - Fill a sidebar record (e.g. quests) from matching lines")
+	m.output = append(m.output, "  "+m.colors().Highlight.Render("/captures list")+"          - List all captures")
+	m.output = append(m.output, "  "+m.colors().Highlight.Render("/captures remove <n>")+"    - Remove capture by number")
+	m.output = append(m.output, "  "+m.colors().Highlight.Render("/macro <key> \"cmd\"")+"     - Bind a function key (F1-F20, Alt+key)")
+	m.output = append(m.output, "  "+m.colors().Highlight.Render("/macros list")+"            - List all macros")
+	m.output = append(m.output, "  "+m.colors().Highlight.Render("/macros remove <n>")+"      - Remove macro by number")
+	m.output = append(m.output, "  "+m.colors().Highlight.Render("/echo <text>")+"            - Print text locally (e.g. status messages in aliases)")
+	m.output = append(m.output, "  "+m.colors().Highlight.Render("/set [name] [value]")+"     - Set a variable used as @name (or list them)")
+	m.output = append(m.output, "  "+m.colors().Highlight.Render("/unset <name>")+"           - Remove a variable")
+	m.output = append(m.output, "  "+m.colors().Highlight.Render("/reload [what]")+"          - Re-read triggers, aliases and/or the map from disk")
+	m.output = append(m.output, "  "+m.colors().Highlight.Render("/share [view]")+"           - Get shareable URL and QR code, or a watch-only one (web mode only)")
+	m.output = append(m.output, "  "+m.colors().Highlight.Render("/connect <host> <port>")+"  - Open another MUD session alongside this one")
+	m.output = append(m.output, "  "+m.colors().Highlight.Render("/sessions [n]")+"           - List open sessions or switch to one")
+	m.output = append(m.output, "  "+m.colors().Highlight.Render("/reconnect")+"              - Connect a closed session to its server again")
+	m.output = append(m.output, "  "+m.colors().Highlight.Render("/reconnect-on [\"re\" s]")+" - Reconnect s seconds after the MUD's reboot warning")
+	m.output = append(m.output, "  "+m.colors().Highlight.Render("/version")+"                - Show the client's version and build details (for bug reports)")
+	m.output = append(m.output, "  "+m.colors().Highlight.Render("/debug dump [file]")+"      - Write the mapper, walk and queue state to a file (for bug reports)")
+	m.output = append(m.output, "  "+m.colors().Highlight.Render("/debug flush")+"            - Write out what the --log-all logs have buffered")
+	m.output = append(m.output, "  "+m.colors().Highlight.Render("/debug level [lvl]")+"      - Show or set the telnet debug log detail: negotiation or all")
+	m.output = append(m.output, "  "+m.colors().Highlight.Render("/help [command]")+"         - Show this help or detailed help for a command")
+	m.output = append(m.output, "")
+	m.output = append(m.output, m.colors().Info.Render("=== Keyboard Shortcuts ==="))
+	m.output = append(m.output, "  "+m.colors().Highlight.Render("Up/Down Arrow")+"           - Navigate command history (only commands starting with typed text)")
+	m.output = append(m.output, "  "+m.colors().Highlight.Render("Ctrl+R")+"                  - Search command history (type to filter)")
+	m.output = append(m.output, "  "+m.colors().Highlight.Render("Ctrl+Tab")+"                - Switch to the next session (see /connect)")
+	m.output = append(m.output, "  "+m.colors().Highlight.Render("Alt+1/2/3")+"               - Show the Game, Chat or System tab (Ctrl+1/2/3 where the terminal sends them)")
+	m.output = append(m.output, "  "+m.colors().Highlight.Render("Ctrl+Space")+"              - Copy mode: drag to select output, y to copy it, Esc to leave")
+	m.output = append(m.output, "")
+	m.output = append(m.output, m.colors().Debug.Render("Use /help <command> for detailed help on a specific command"))
+	m.output = append(m.output, m.colors().Debug.Render("Commands can be shortened to any unambiguous start (e.g., /way for /wayfind)"))
+	m.output = append(m.output, m.colors().Debug.Render("Room search matches all terms in room title, description, or exits"))
+	m.output = append(m.output, m.colors().Debug.Render("Triggers match output lines and execute actions (supports <variable> capture)"))
+	m.output = append(m.output, m.colors().Debug.Render("Aliases expand commands with parameters (e.g., /alias \"gat\" \"give all <target>\")"))
+	m.output = append(m.output, m.colors().Debug.Render("Triggers and aliases support multiple commands separated by ';' (e.g., \"cmd1;cmd2;cmd3\")"))
+	m.output = append(m.output, m.colors().Debug.Render("Commands you send can use @name to insert a variable set with /set"))
+}
+
+// showDetailedHelp shows detailed help for a specific command
+func (m *Model) showDetailedHelp(cmd string) {
+	switch cmd {
+	case "point":
+		m.output = append(m.output, m.colors().Info.Render("=== /point - Show Next Direction ==="))
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Highlight.Render("Usage:"))
+		m.output = append(m.output, "  /point <room search terms>")
+		m.output = append(m.output, "  /point <number> [search terms]")
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Highlight.Render("Description:"))
+		m.output = append(m.output, "  Shows the next direction to take to reach a destination room.")
+		m.output = append(m.output, "  Searches for rooms matching all provided terms (case-insensitive).")
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Highlight.Render("Examples:"))
+		m.output = append(m.output, "  /point temple square       - Find room matching 'temple' and 'square'")
+		m.output = append(m.output, "  /point market              - Find room matching 'market'")
+		m.output = append(m.output, "  /point 2                   - Select 2nd room from previous search")
+		m.output = append(m.output, "  /point 1 temple            - Select 1st room matching 'temple'")
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Debug.Render("See also: /help wayfind, /help go"))
+
+	case "wayfind":
+		m.output = append(m.output, m.colors().Info.Render("=== /wayfind - Show Full Path ==="))
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Highlight.Render("Usage:"))
+		m.output = append(m.output, "  /wayfind <room search terms>")
+		m.output = append(m.output, "  /wayfind <number> [search terms]")
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Highlight.Render("Description:"))
+		m.output = append(m.output, "  Shows the complete path from your current location to the destination.")
+		m.output = append(m.output, "  Lists each step with the direction and destination room name.")
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Highlight.Render("Examples:"))
+		m.output = append(m.output, "  /wayfind temple square     - Show full path to 'temple square'")
+		m.output = append(m.output, "  /wayfind 3                 - Show path to 3rd room from previous search")
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Debug.Render("See also: /help point, /help go"))
+
+	case "path":
+		m.output = append(m.output, m.colors().Info.Render("=== /path - Plan a Route Between Rooms ==="))
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Highlight.Render("Usage:"))
+		m.output = append(m.output, "  /path <from-number> <to-number>")
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Highlight.Render("Description:"))
+		m.output = append(m.output, "  Shows the complete path between two known rooms using their durable")
+		m.output = append(m.output, "  room numbers from /rooms. Unlike /wayfind, neither room has to be your")
+		m.output = append(m.output, "  current location, so you can plan trips while idle.")
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Highlight.Render("Examples:"))
+		m.output = append(m.output, "  /path 3 17                 - Show path from room #3 to room #17")
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Debug.Render("See also: /help rooms, /help wayfind"))
+
+	case "avoid":
+		m.output = append(m.output, m.colors().Info.Render("=== /avoid - Avoid Dangerous Rooms ==="))
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Highlight.Render("Usage:"))
+		m.output = append(m.output, "  /avoid")
+		m.output = append(m.output, "  /avoid <room-number>")
+		m.output = append(m.output, "  /avoid list")
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Highlight.Render("Description:"))
+		m.output = append(m.output, "  Toggles the avoid flag on the current room, or on a room given by its")
+		m.output = append(m.output, "  number from /rooms. Pathfinding (/point, /wayfind, /path and /go) routes")
+		m.output = append(m.output, "  around avoided rooms when a safe path exists, even if it is longer.")
+		m.output = append(m.output, "  If the only path goes through an avoided room it is still used, with a")
+		m.output = append(m.output, "  warning. The destination itself is never avoided.")
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Highlight.Render("Examples:"))
+		m.output = append(m.output, "  /avoid                     - Toggle avoid on the current room")
+		m.output = append(m.output, "  /avoid 12                  - Toggle avoid on room #12")
+		m.output = append(m.output, "  /avoid list                - List all avoided rooms")
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Debug.Render("See also: /help go, /help rooms"))
+
+	case "merge":
+		m.output = append(m.output, m.colors().Info.Render("=== /merge - Merge Duplicate Rooms ==="))
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Highlight.Render("Usage:"))
+		m.output = append(m.output, "  /merge <room-number> <duplicate-number>")
+		m.output = append(m.output, "  /merge suggest")
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Highlight.Render("Description:"))
+		m.output = append(m.output, "  The mapper can record one place as two rooms, for instance when its")
+		m.output = append(m.output, "  description changes. Merging folds the second room into the first:")
+		m.output = append(m.output, "  exits are combined, exits leading to the duplicate are redirected and")
+		m.output = append(m.output, "  visit counts are added up. The duplicate's number is retired, so other")
+		m.output = append(m.output, "  rooms keep their numbers. /merge suggest lists rooms with the same title")
+		m.output = append(m.output, "  and exits whose known exits don't contradict each other.")
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Highlight.Render("Examples:"))
+		m.output = append(m.output, "  /merge suggest             - List likely duplicates")
+		m.output = append(m.output, "  /merge 12 40               - Merge room #40 into room #12")
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Debug.Render("See also: /help rooms, /help map"))
+
+	case "dig":
+		m.output = append(m.output, m.colors().Info.Render("=== /dig - Create Rooms by Hand ==="))
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Highlight.Render("Usage:"))
+		m.output = append(m.output, "  /dig <direction> \"<room title>\"")
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Highlight.Render("Description:"))
+		m.output = append(m.output, "  Some areas can't be mapped automatically, for instance when rooms have")
+		m.output = append(m.output, "  no exits line or the room text isn't recognized. /dig creates a room in")
+		m.output = append(m.output, "  the given direction from the current room, adds the exit there and the")
+		m.output = append(m.output, "  exit back, and moves your position on the map into the new room. An exit")
+		m.output = append(m.output, "  that already leads somewhere is not changed. The map is saved.")
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Highlight.Render("Examples:"))
+		m.output = append(m.output, "  /dig north \"Dark Tunnel\"     - Room north of here, with an exit south back")
+		m.output = append(m.output, "  /dig u \"Tree Top\"           - Short directions work too")
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Debug.Render("See also: /help merge, /help map"))
+
+	case "remember-exit":
+		m.output = append(m.output, m.colors().Info.Render("=== /remember-exit - Special Exits ==="))
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Highlight.Render("Usage:"))
+		m.output = append(m.output, "  /remember-exit \"<command>\"")
+		m.output = append(m.output, "  /remember-exit")
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Highlight.Render("Description:"))
+		m.output = append(m.output, "  Some exits aren't directions: 'enter portal', 'climb tree', 'push button'.")
+		m.output = append(m.output, "  /remember-exit sends the command and records the room it leads to as an")
+		m.output = append(m.output, "  exit of the current room, named by the command. No way back is assumed.")
+		m.output = append(m.output, "  /go and other paths then send the command to take that exit, and typing")
+		m.output = append(m.output, "  the command yourself keeps your place on the map. Without a command,")
+		m.output = append(m.output, "  lists the special exits of the current room.")
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Highlight.Render("Examples:"))
+		m.output = append(m.output, "  /remember-exit \"enter portal\"")
+		m.output = append(m.output, "  /remember-exit \"climb tree\"")
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Debug.Render("See also: /help dig, /help go"))
+
+	case "go":
+		m.output = append(m.output, m.colors().Info.Render("=== /go - Auto-Walk to Room ==="))
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Highlight.Render("Usage:"))
+		m.output = append(m.output, "  /go <room search terms>")
+		m.output = append(m.output, "  /go <number> [search terms]")
+		m.output = append(m.output, "  /go -speed <ms> <room search terms>")
+		m.output = append(m.output, "  /go -fast <room search terms>")
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Highlight.Render("Description:"))
+		m.output = append(m.output, "  Automatically walks to a destination room, sending one movement command")
+		m.output = append(m.output, "  per step (one second by default, see /walkspeed). The client will follow")
+		m.output = append(m.output, "  the shortest path to the destination.")
+		m.output = append(m.output, "  -speed overrides the step delay for this walk only.")
+		m.output = append(m.output, "  -fast sends the whole path at once, for MUDs that queue movement.")
+		m.output = append(m.output, "  When no room matches every search term, rooms with similar titles are")
+		m.output = append(m.output, "  used instead, closest first, so typos like 'tempel squre' still work.")
+		m.output = append(m.output, "  The same goes for /point and /wayfind.")
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Highlight.Render("Examples:"))
+		m.output = append(m.output, "  /go temple square          - Auto-walk to 'temple square'")
+		m.output = append(m.output, "  /go tempel squre           - Same, despite the typos")
+		m.output = append(m.output, "  /go 1                      - Auto-walk to 1st room from previous search")
+		m.output = append(m.output, "  /go -speed 300 market      - Auto-walk to 'market' at 300ms per step")
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Debug.Render("Use /stop to cancel auto-walk"))
+		m.output = append(m.output, m.colors().Debug.Render("See also: /help stop, /help walkspeed, /help point, /help wayfind"))
+
+	case "explore":
+		m.output = append(m.output, m.colors().Info.Render("=== /explore - Walk to Unexplored Exits ==="))
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Highlight.Render("Usage:"))
+		m.output = append(m.output, "  /explore")
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Highlight.Render("Description:"))
+		m.output = append(m.output, "  Finds the nearest room with an exit you haven't taken yet (see /map")
+		m.output = append(m.output, "  unexplored) and auto-walks there like /go, avoiding rooms marked with")
+		m.output = append(m.output, "  /avoid when it can. The walk stops in that room so you can step into")
+		m.output = append(m.output, "  the unknown yourself. Repeating /explore fills in the map one exit at a")
+		m.output = append(m.output, "  time. When no exit you can reach is unexplored, it says so.")
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Highlight.Render("Examples:"))
+		m.output = append(m.output, "  /explore")
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Debug.Render("See also: /help go, /help map, /help avoid"))
+
+	case "walkspeed":
+		m.output = append(m.output, m.colors().Info.Render("=== /walkspeed - Auto-Walk Speed ==="))
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Highlight.Render("Usage:"))
+		m.output = append(m.output, "  /walkspeed")
+		m.output = append(m.output, "  /walkspeed <ms>")
+		m.output = append(m.output, "  /walkspeed fast")
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Highlight.Render("Description:"))
+		m.output = append(m.output, "  Shows or sets the delay between /go steps. The setting is saved between")
+		m.output = append(m.output, fmt.Sprintf("  sessions. The minimum delay is %dms.", settings.MinWalkDelayMs))
+		m.output = append(m.output, "  'fast' sends the whole path at once. This only works on MUDs that queue")
+		m.output = append(m.output, "  movement server-side and may fail where movement points are limited.")
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Highlight.Render("Examples:"))
+		m.output = append(m.output, "  /walkspeed 250             - Walk at 250ms per step")
+		m.output = append(m.output, "  /walkspeed 1000            - Restore the default speed")
+		m.output = append(m.output, "  /walkspeed fast            - Send whole paths at once")
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Debug.Render("See also: /help go"))
+
+	case "numpadwalk":
+		m.output = append(m.output, m.colors().Info.Render("=== /numpadwalk - Walk With Numpad and Arrow Keys ==="))
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Highlight.Render("Usage:"))
+		m.output = append(m.output, "  /numpadwalk")
+		m.output = append(m.output, "  /numpadwalk on")
+		m.output = append(m.output, "  /numpadwalk off")
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Highlight.Render("Description:"))
+		m.output = append(m.output, "  When on and the input line is empty, numpad digits and arrow keys send")
+		m.output = append(m.output, "  movement commands immediately: 8 north, 2 south, 4 west, 6 east,")
+		m.output = append(m.output, "  7 northwest, 9 northeast, 1 southwest, 3 southeast, and the arrow keys")
+		m.output = append(m.output, "  for the four compass directions. Once you have typed something the keys")
+		m.output = append(m.output, "  behave normally. The setting is saved between sessions.")
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Debug.Render("Terminals send numpad digits like ordinary digits, so turn this off to"))
+		m.output = append(m.output, m.colors().Debug.Render("answer numbered menus. Up/Down history still works once text is typed."))
+		m.output = append(m.output, m.colors().Debug.Render("See also: /help macro"))
+
+	case "hideprompt":
+		m.output = append(m.output, m.colors().Info.Render("=== /hideprompt - Move the Prompt to the Status Bar ==="))
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Highlight.Render("Usage:"))
+		m.output = append(m.output, "  /hideprompt")
+		m.output = append(m.output, "  /hideprompt on")
+		m.output = append(m.output, "  /hideprompt off")
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Highlight.Render("Description:"))
+		m.output = append(m.output, "  When on, stat prompt lines (e.g. '101H 132V 1000X Exits:NS>') are left out")
+		m.output = append(m.output, "  of the main window so they don't clutter scrollback. The latest prompt is")
+		m.output = append(m.output, "  shown in the status bar, and prompts still drive the tick timer, XP")
+		m.output = append(m.output, "  tracking, the mapper and triggers. Typed commands go on a line of their")
+		m.output = append(m.output, "  own. The setting is saved between sessions.")
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Debug.Render("Only lines ending in '>' that show H and V stats are treated as prompts"))
+		m.output = append(m.output, m.colors().Debug.Render("See also: /help promptpattern"))
+
+	case "promptnewline":
+		m.output = append(m.output, m.colors().Info.Render("=== /promptnewline - Line Break After the Prompt ==="))
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Highlight.Render("Usage:"))
+		m.output = append(m.output, "  /promptnewline")
+		m.output = append(m.output, "  /promptnewline on")
+		m.output = append(m.output, "  /promptnewline off")
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Highlight.Render("Description:"))
+		m.output = append(m.output, "  Most MUDs send the prompt without a newline, so the command you type is")
+		m.output = append(m.output, "  echoed on the prompt line. When on, a new line is started after such a")
+		m.output = append(m.output, "  prompt: your command goes on a line of its own and the MUD's reply starts")
+		m.output = append(m.output, "  right below it. Prompts that already end with a newline are left alone,")
+		m.output = append(m.output, "  so nothing is double-spaced. Besides lines matching /promptpattern, any")
+		m.output = append(m.output, "  line the MUD marks with telnet GA or EOR counts as a prompt here. The")
+		m.output = append(m.output, "  setting is saved between sessions.")
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Debug.Render("See also: /help hideprompt"))
+
+	case "split":
+		m.output = append(m.output, m.colors().Info.Render("=== /split - Split Output View ==="))
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Highlight.Render("Usage:"))
+		m.output = append(m.output, "  /split                   - Show the split settings")
+		m.output = append(m.output, "  /split on                - Always show scrollback above the live output")
+		m.output = append(m.output, "  /split off               - Split only while scrolled back (the default)")
+		m.output = append(m.output, "  /split ratio <percent>   - Give scrollback this share of the height")
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Highlight.Render("Description:"))
+		m.output = append(m.output, "  Scrolling back with Page Up or the mouse wheel splits the output: what")
+		m.output = append(m.output, "  you scrolled to stays on top while the live output carries on below.")
+		m.output = append(m.output, "  With /split on the split stays when you scroll back down, and the top")
+		m.output = append(m.output, "  pane then shows the lines just above the live pane. The ratio is the")
+		m.output = append(m.output, "  top pane's share, from 10 to 90 percent (67 by default). Both")
+		m.output = append(m.output, "  settings are saved between sessions.")
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Highlight.Render("Examples:"))
+		m.output = append(m.output, "  /split ratio 50")
+		m.output = append(m.output, "  /split on")
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Debug.Render("See also: /help tab, /help focus"))
+
+	case "promptpattern":
+		m.output = append(m.output, m.colors().Info.Render("=== /promptpattern - Custom Prompt Format ==="))
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Highlight.Render("Usage:"))
+		m.output = append(m.output, "  /promptpattern")
+		m.output = append(m.output, "  /promptpattern \"<regex>\"")
+		m.output = append(m.output, "  /promptpattern off")
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Highlight.Render("Description:"))
+		m.output = append(m.output, "  By default a line is a prompt when it ends in '>' and shows H and V stats,")
+		m.output = append(m.output, "  like '101H 132V 1000X>'. For a MUD with another prompt, give a regular")
+		m.output = append(m.output, "  expression that matches its prompt lines (without colors). The mapper uses")
+		m.output = append(m.output, "  prompts to find where a room starts, and /hideprompt, /promptnewline and")
+		m.output = append(m.output, "  the inventory panel rely on them too. The pattern is saved with the map")
+		m.output = append(m.output, "  of the server you are connected to; off goes back to the built-in check.")
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Highlight.Render("Examples:"))
+		m.output = append(m.output, "  /promptpattern \"^<\\d+hp \\d+m \\d+mv>\"   - For prompts like <120hp 80m 95mv>")
+		m.output = append(m.output, "  /promptpattern \"^\\[HP:\\d+/\\d+\\]\"       - For prompts like [HP:50/100] ...")
+		m.output = append(m.output, "  /promptpattern off")
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Debug.Render("See also: /help hideprompt, /help map"))
+
+	case "collapse":
+		m.output = append(m.output, m.colors().Info.Render("=== /collapse - Collapse Blank Lines ==="))
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Highlight.Render("Usage:"))
+		m.output = append(m.output, "  /collapse")
+		m.output = append(m.output, "  /collapse on")
+		m.output = append(m.output, "  /collapse off")
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Highlight.Render("Description:"))
+		m.output = append(m.output, "  Some MUDs send several blank lines in a row. When on, a run of blank")
+		m.output = append(m.output, "  lines from the MUD is shown as a single blank line, so a lone blank line")
+		m.output = append(m.output, "  between paragraphs is kept. Triggers and the mapper still see every line.")
+		m.output = append(m.output, "  The setting is saved between sessions.")
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Highlight.Render("Examples:"))
+		m.output = append(m.output, "  /collapse on")
+		m.output = append(m.output, "  /collapse off")
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Debug.Render("See also: /help promptnewline"))
+
+	case "links":
+		m.output = append(m.output, m.colors().Info.Render("=== /links - Clickable Web Addresses ==="))
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Highlight.Render("Usage:"))
+		m.output = append(m.output, "  /links")
+		m.output = append(m.output, "  /links on")
+		m.output = append(m.output, "  /links off")
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Highlight.Render("Description:"))
+		m.output = append(m.output, "  When on, http:// and https:// addresses in the MUD output are wrapped in")
+		m.output = append(m.output, "  OSC 8 hyperlinks, so terminals that support them (and the web client)")
+		m.output = append(m.output, "  open the address when it is clicked. Other terminals show the text as")
+		m.output = append(m.output, "  before. The /share URL is always a link. With /ansi off, no links are")
+		m.output = append(m.output, "  added. The setting is saved between sessions.")
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Highlight.Render("Examples:"))
+		m.output = append(m.output, "  /links on")
+		m.output = append(m.output, "  /links off")
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Debug.Render("See also: /help ansi, /help share"))
+
+	case "focus":
+		m.output = append(m.output, m.colors().Info.Render("=== /focus - Focus on Matching Lines ==="))
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Highlight.Render("Usage:"))
+		m.output = append(m.output, "  /focus \"<pattern>\" [hide]")
+		m.output = append(m.output, "  /focus")
+		m.output = append(m.output, "  /focus off")
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Highlight.Render("Description:"))
+		m.output = append(m.output, "  Dims every line not matching the pattern (a case-insensitive regex),")
+		m.output = append(m.output, "  so the lines you care about stand out in a busy fight. With hide the")
+		m.output = append(m.output, "  other lines are left out of view instead. Unlike a gag nothing is")
+		m.output = append(m.output, "  removed: /focus off shows every line again. Dimming needs colors on;")
+		m.output = append(m.output, "  with /ansi off use hide.")
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Highlight.Render("Examples:"))
+		m.output = append(m.output, "  /focus \"hits|misses|massacres\"")
+		m.output = append(m.output, "  /focus \"^Gandalf tells you\" hide")
+		m.output = append(m.output, "  /focus off")
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Debug.Render("See also: /help collapse, /help sub, /help tab"))
+
+	case "tab":
+		m.output = append(m.output, m.colors().Info.Render("=== /tab - Output Tabs ==="))
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Highlight.Render("Usage:"))
+		m.output = append(m.output, "  /tab [game|chat|system]")
+		m.output = append(m.output, "  Alt+1, Alt+2, Alt+3 (or Ctrl+1/2/3 where the terminal sends them)")
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Highlight.Render("Description:"))
+		m.output = append(m.output, "  The main window has three tabs, named in its top border. Game shows")
+		m.output = append(m.output, "  everything, Chat only tells, says and channel messages, and System only")
+		m.output = append(m.output, "  the client's own messages: triggers firing, mapper notices and the")
+		m.output = append(m.output, "  replies to / commands. The prompt and what you type are shown in every")
+		m.output = append(m.output, "  tab. A tab not on screen shows how many lines arrived since you last")
+		m.output = append(m.output, "  looked at it. /tab on its own lists the tabs.")
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Highlight.Render("Examples:"))
+		m.output = append(m.output, "  /tab chat")
+		m.output = append(m.output, "  /tab 1")
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Debug.Render("See also: /help focus, /help mute"))
+
+	case "ansi":
+		m.output = append(m.output, m.colors().Info.Render("=== /ansi - Plain Text Output ==="))
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Highlight.Render("Usage:"))
+		m.output = append(m.output, "  /ansi")
+		m.output = append(m.output, "  /ansi on")
+		m.output = append(m.output, "  /ansi off")
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Highlight.Render("Description:"))
+		m.output = append(m.output, "  When off, all color and other escape codes are removed from the MUD's")
+		m.output = append(m.output, "  output, the client's own messages and your echoed commands, for screen")
+		m.output = append(m.output, "  readers and terminals that can't show color. Output already on screen")
+		m.output = append(m.output, "  is converted too. Triggers, the mapper and logs are unaffected. The")
+		m.output = append(m.output, "  setting is saved between sessions.")
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Highlight.Render("Examples:"))
+		m.output = append(m.output, "  /ansi off")
+		m.output = append(m.output, "  /ansi on")
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Debug.Render("See also: /help theme"))
+
+	case "theme":
+		m.output = append(m.output, m.colors().Info.Render("=== /theme - Message Colors ==="))
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Highlight.Render("Usage:"))
+		m.output = append(m.output, "  /theme                      - Show the color of each kind of message")
+		m.output = append(m.output, "  /theme set <kind> <color>   - Change a color")
+		m.output = append(m.output, "  /theme reset [<kind>]       - Go back to the default color(s)")
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Highlight.Render("Description:"))
+		m.output = append(m.output, "  Sets the colors of the client's own messages. The kinds are info")
+		m.output = append(m.output, "  (confirmations and headings), warn (warnings and usage), error, debug")
+		m.output = append(m.output, "  ([Mapper], [Trigger] and other notes) and highlight (items in listings).")
+		m.output = append(m.output, "  A color is an ANSI color number from 0 to 255 or #rrggbb. Colors are")
+		m.output = append(m.output, "  saved between sessions; /ansi off turns them all off.")
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Highlight.Render("Examples:"))
+		m.output = append(m.output, "  /theme set error 196")
+		m.output = append(m.output, "  /theme set debug #808080")
+		m.output = append(m.output, "  /theme reset")
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Debug.Render("See also: /help ansi"))
+
+	case "telnet":
+		m.output = append(m.output, m.colors().Info.Render("=== /telnet - Send Raw Telnet Sequences ==="))
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Highlight.Render("Usage:"))
+		m.output = append(m.output, "  /telnet <will|wont|do|dont> <option>")
+		m.output = append(m.output, "  /telnet sb <option> <hexbytes>")
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Highlight.Render("Description:"))
+		m.output = append(m.output, "  Sends a telnet negotiation (IAC <cmd> <option>) or subnegotiation")
+		m.output = append(m.output, "  (IAC SB <option> <bytes> IAC SE) straight to the MUD, for debugging")
+		m.output = append(m.output, "  option support. Options can be named (gmcp, msdp, naws, ttype, ...) or")
+		m.output = append(m.output, "  given as a number. Subnegotiation data is written in hex, with or")
+		m.output = append(m.output, "  without spaces; 0xFF bytes are escaped for you. What's sent is also")
+		m.output = append(m.output, "  written to the telnet debug log when one is open.")
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Highlight.Render("Examples:"))
+		m.output = append(m.output, "  /telnet do gmcp")
+		m.output = append(m.output, "  /telnet wont 24")
+		m.output = append(m.output, "  /telnet sb naws 0050 0018")
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Debug.Render("The client doesn't track options negotiated this way"))
+
+	case "affects":
+		m.output = append(m.output, m.colors().Info.Render("=== /affects - Track Spell and Skill Affects ==="))
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Highlight.Render("Usage:"))
+		m.output = append(m.output, "  /affects                 - List tracked affects and time left")
+		m.output = append(m.output, "  /affects clear           - Forget tracked affects")
+		m.output = append(m.output, "  /affects expire \"cmd\"    - Run cmd when an affect is about to wear off")
+		m.output = append(m.output, "  /affects expire off      - Stop running an expiry command")
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Highlight.Render("Description:"))
+		m.output = append(m.output, "  When the MUD's affects or spells listing scrolls by (e.g. 'You are affected")
+		m.output = append(m.output, "  by:' followed by 'armor (12 hours)'), each affect is tracked in the Affects")
+		m.output = append(m.output, "  panel with a countdown. A game hour is one tick, taken from the tick timer.")
+		m.output = append(m.output, "  In an affect's last hour a warning is shown and the expiry command, if set,")
+		m.output = append(m.output, "  is queued with <affect> replaced by the affect's name.")
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Highlight.Render("Examples:"))
+		m.output = append(m.output, "  /affects expire \"cast '<affect>'\"")
+		m.output = append(m.output, "  /affects expire \"/echo <affect> is running out!\"")
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Debug.Render("See also: /help ticktrigger"))
+
+	case "mute", "unmute":
+		m.output = append(m.output, m.colors().Info.Render("=== /mute - Hide Players ==="))
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Highlight.Render("Usage:"))
+		m.output = append(m.output, "  /mute <player>")
+		m.output = append(m.output, "  /mute list")
+		m.output = append(m.output, "  /unmute <player>")
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Highlight.Render("Description:"))
+		m.output = append(m.output, "  Tells, says and channel messages (gossip, shout, auction, ...) from a muted")
+		m.output = append(m.output, "  player are dropped from the output and kept out of the Tells panel. They")
+		m.output = append(m.output, "  are still written to the MUD and JSON logs. Names match without regard to")
+		m.output = append(m.output, "  case. The list is saved for each account on each server.")
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Highlight.Render("Examples:"))
+		m.output = append(m.output, "  /mute Spammer     - Hide everything Spammer says")
+		m.output = append(m.output, "  /mute list        - Show who is muted")
+		m.output = append(m.output, "  /unmute Spammer   - Show Spammer's messages again")
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Debug.Render("See also: /whereis"))
+
+	case "whereis":
+		m.output = append(m.output, m.colors().Info.Render("=== /whereis - Where Players Were Last Seen ==="))
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Highlight.Render("Usage:"))
+		m.output = append(m.output, "  /whereis")
+		m.output = append(m.output, "  /whereis <player>")
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Highlight.Render("Description:"))
+		m.output = append(m.output, "  Players are noted against the room you're in when they send you a tell,")
+		m.output = append(m.output, "  say something, arrive or are listed in the room. /whereis <player> tells")
+		m.output = append(m.output, "  you where and how long ago you last saw them; without a name, every")
+		m.output = append(m.output, "  player seen is listed, most recent first. Sightings last for the session.")
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Highlight.Render("Examples:"))
+		m.output = append(m.output, "  /whereis Gandalf    - Gandalf was last seen in Temple Square (#1) 3 minutes ago.")
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Debug.Render("Only single-word capitalized names count as players, so mobs like 'The guard' are skipped"))
+
+	case "stat":
+		m.output = append(m.output, m.colors().Info.Render("=== /stat - Recall Identified Items ==="))
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Highlight.Render("Usage:"))
+		m.output = append(m.output, "  /stat                    - List remembered items")
+		m.output = append(m.output, "  /stat <item>             - Show an item's remembered stats")
+		m.output = append(m.output, "  /stat forget <item>      - Forget an item")
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Highlight.Render("Description:"))
+		m.output = append(m.output, "  When you identify an item and the MUD's output starts with a line such as")
+		m.output = append(m.output, "  'Object 'a long sword', Item type: WEAPON', everything up to the next")
+		m.output = append(m.output, "  prompt is remembered as the item's stats. /stat shows them again later,")
+		m.output = append(m.output, "  matching the full name or words from it. Remembered items are marked")
+		m.output = append(m.output, "  with a ✓ in the Inventory panel. The database is saved between sessions.")
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Highlight.Render("Examples:"))
+		m.output = append(m.output, "  /stat a long sword")
+		m.output = append(m.output, "  /stat sword")
+		m.output = append(m.output, "  /stat forget a long sword")
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Debug.Render("See also: /help remember"))
+
+	case "remember":
+		m.output = append(m.output, m.colors().Info.Render("=== /remember - Remember Item Stats ==="))
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Highlight.Render("Usage:"))
+		m.output = append(m.output, "  /remember [name]")
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Highlight.Render("Description:"))
+		m.output = append(m.output, "  Stores the MUD's last response (the lines between the last two prompts)")
+		m.output = append(m.output, "  as the stats of the named item, for MUDs whose identify or examine")
+		m.output = append(m.output, "  output isn't recognized automatically. The name can be left out when")
+		m.output = append(m.output, "  the response starts with an 'Object '...'' line.")
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Highlight.Render("Examples:"))
+		m.output = append(m.output, "  examine sword")
+		m.output = append(m.output, "  /remember a long sword")
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Debug.Render("See also: /help stat"))
+
+	case "inventory":
+		m.output = append(m.output, m.colors().Info.Render("=== /inventory - Inventory and Equipment Panels ==="))
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Highlight.Render("Usage:"))
+		m.output = append(m.output, "  /inventory")
+		m.output = append(m.output, "  /inventory auto <seconds>")
+		m.output = append(m.output, "  /inventory auto off")
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Highlight.Render("Description:"))
+		m.output = append(m.output, "  The Inventory panel fills in whenever the MUD lists what you carry")
+		m.output = append(m.output, "  (\"You are carrying:\"), and an Equipment panel below it shows what you")
+		m.output = append(m.output, "  wear (\"You are using:\" followed by \"<worn on body>  a breastplate\" lines).")
+		m.output = append(m.output, "  Without arguments, shows when each was last updated.")
+		m.output = append(m.output, "")
+		m.output = append(m.output, fmt.Sprintf("  With auto, sends \"inventory\" every <seconds> (at least %d) while", settings.MinInventorySeconds))
+		m.output = append(m.output, "  connected, unless you listed it yourself within that time. The interval")
+		m.output = append(m.output, "  is saved in your settings.")
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Highlight.Render("Examples:"))
+		m.output = append(m.output, "  /inventory auto 60")
+		m.output = append(m.output, "  /inventory auto off")
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Debug.Render("See also: /help stat"))
+
+	case "stop":
+		m.output = append(m.output, m.colors().Info.Render("=== /stop - Stop Auto-Walk or Command Queue ==="))
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Highlight.Render("Usage:"))
+		m.output = append(m.output, "  /stop")
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Highlight.Render("Description:"))
+		m.output = append(m.output, "  Stops any active auto-walk operation or command queue.")
+		m.output = append(m.output, "  This includes auto-walk from /go, trigger actions, and alias multi-commands.")
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Debug.Render("See also: /help go"))
+
+	case "map":
+		m.output = append(m.output, m.colors().Info.Render("=== /map - Show Map Information ==="))
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Highlight.Render("Usage:"))
+		m.output = append(m.output, "  /map")
+		m.output = append(m.output, "  /map grid [on|off]")
+		m.output = append(m.output, "  /map orient [north|east|south|west]")
+		m.output = append(m.output, "  /map compass [on|off]")
+		m.output = append(m.output, "  /map html")
+		m.output = append(m.output, "  /map deadends")
+		m.output = append(m.output, "  /map unexplored")
+		m.output = append(m.output, "  /map orphans")
+		m.output = append(m.output, "  /map list")
+		m.output = append(m.output, "  /map save|load|switch <name>")
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Highlight.Render("Description:"))
+		m.output = append(m.output, "  Shows information about the current map, including:")
+		m.output = append(m.output, "  - Total number of rooms discovered")
+		m.output = append(m.output, "  - Total number of connections between rooms")
+		m.output = append(m.output, "  - Current room information (if known)")
+		m.output = append(m.output, "  - Rooms that were given the same coordinates")
+		m.output = append(m.output, "")
+		m.output = append(m.output, "  Every room reached by a compass or up/down move gets X/Y/Z coordinates")
+		m.output = append(m.output, "  one step from the room you came from. With /map grid on, the map panel")
+		m.output = append(m.output, "  draws rooms at those coordinates, so loops close and overlapping areas")
+		m.output = append(m.output, "  stay put. Only the current level is drawn; ⇱ ⇲ ⇅ mark rooms with up")
+		m.output = append(m.output, "  and down exits, and rooms sharing a cell are shown in red.")
+		m.output = append(m.output, "")
+		m.output = append(m.output, "  /map orient turns the map panel so another direction is at the top.")
+		m.output = append(m.output, "  /map compass on draws the current room's exits below the map: each exit")
+		m.output = append(m.output, "  is shown by its letter on the side it is drawn, other directions by a")
+		m.output = append(m.output, "  dot, up and down at the right, and the arrow in the middle points north.")
+		m.output = append(m.output, "")
+		m.output = append(m.output, "  In web mode, /map html shows every level of the map in a panel in the")
+		m.output = append(m.output, "  browser. Clicking a room there walks to it with /go.")
+		m.output = append(m.output, "")
+		m.output = append(m.output, "  For exploring, /map deadends lists rooms with a single exit, /map")
+		m.output = append(m.output, "  unexplored lists exits you haven't taken yet, and /map orphans lists")
+		m.output = append(m.output, "  rooms no known exit leads to from the current room.")
+		m.output = append(m.output, "")
+		m.output = append(m.output, "  To keep separate maps of the same MUD, /map save <name> saves a copy")
+		m.output = append(m.output, "  of the map in use under a name and /map list lists them. /map load")
+		m.output = append(m.output, "  <name> changes to a saved map; /map switch <name> saves the map in use")
+		m.output = append(m.output, "  first, and starts an empty map if the name is new. Changes are then")
+		m.output = append(m.output, "  saved to that map. The name default is the server's own map.")
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Highlight.Render("Examples:"))
+		m.output = append(m.output, "  /map grid on    - Draw the map from room coordinates")
+		m.output = append(m.output, "  /map grid off   - Lay the map out by following exits (default)")
+		m.output = append(m.output, "  /map orient east - Draw the map with east at the top")
+		m.output = append(m.output, "  /map compass on - Show the current room's exits as a compass")
+		m.output = append(m.output, "  /map html       - Show the whole map in the browser (web mode)")
+		m.output = append(m.output, "  /map unexplored - List exits that haven't been taken yet")
+		m.output = append(m.output, "  /map switch alt  - Use the map named alt, saving this one")
+		m.output = append(m.output, "  /map switch default - Go back to the server's own map")
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Debug.Render("The map is automatically saved to ~/.config/dikuclient/map.json"))
+		m.output = append(m.output, m.colors().Debug.Render("See also: /help rooms, /help nearby, /help legend"))
+
+	case "rooms":
+		m.output = append(m.output, m.colors().Info.Render("=== /rooms - List Known Rooms ==="))
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Highlight.Render("Usage:"))
+		m.output = append(m.output, "  /rooms [filter terms]")
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Highlight.Render("Description:"))
+		m.output = append(m.output, "  Lists all known rooms in the map. When filter terms are provided,")
+		m.output = append(m.output, "  only rooms matching all terms (case-insensitive) are shown.")
+		m.output = append(m.output, "  Searches room titles, descriptions, and exit information.")
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Highlight.Render("Examples:"))
+		m.output = append(m.output, "  /rooms                     - List all known rooms")
+		m.output = append(m.output, "  /rooms temple              - List rooms containing 'temple'")
+		m.output = append(m.output, "  /rooms market square       - List rooms with both 'market' and 'square'")
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Debug.Render("See also: /help nearby, /help legend"))
+
+	case "nearby":
+		m.output = append(m.output, m.colors().Info.Render("=== /nearby - List Nearby Rooms ==="))
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Highlight.Render("Usage:"))
+		m.output = append(m.output, "  /nearby")
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Highlight.Render("Description:"))
+		m.output = append(m.output, "  Lists all rooms within 5 steps of your current location.")
+		m.output = append(m.output, "  Rooms are shown with their distance and can be selected with /point,")
+		m.output = append(m.output, "  /wayfind, or /go using their number from the list, or clicked with the")
+		m.output = append(m.output, "  mouse to walk there.")
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Highlight.Render("Example:"))
+		m.output = append(m.output, "  > /nearby")
+		m.output = append(m.output, "  1. Market Square (2 steps)")
+		m.output = append(m.output, "  2. Temple Entrance (3 steps)")
+		m.output = append(m.output, "  > /go 1")
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Debug.Render("See also: /help legend, /help rooms"))
+
+	case "trail":
+		m.output = append(m.output, m.colors().Info.Render("=== /trail - Recent Rooms ==="))
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Highlight.Render("Usage:"))
+		m.output = append(m.output, "  /trail                      - List the last rooms visited, oldest first")
+		m.output = append(m.output, "  /trail back [<steps>]       - Auto-walk back along the trail")
+		m.output = append(m.output, "  /trail clear                - Forget the trail")
+		m.output = append(m.output, "  /trail panel on|off         - Show the trail above the map")
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Highlight.Render("Description:"))
+		m.output = append(m.output, fmt.Sprintf("  Keeps the last %d rooms you entered with the direction taken into each,", mapper.DefaultTrailLength))
+		m.output = append(m.output, "  so you can retrace your steps or spot where the map linked a room wrongly.")
+		m.output = append(m.output, "  /trail back walks the given number of steps back (the whole trail by")
+		m.output = append(m.output, "  default) using the map's exits between the rooms. Click a listed room")
+		m.output = append(m.output, "  to walk to it.")
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Highlight.Render("Examples:"))
+		m.output = append(m.output, "  /trail")
+		m.output = append(m.output, "  /trail back 3")
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Debug.Render("See also: /help go, /help nearby"))
+
+	case "stats":
+		m.output = append(m.output, m.colors().Info.Render("=== /stats - Travel Odometer ==="))
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Highlight.Render("Usage:"))
+		m.output = append(m.output, "  /stats")
+		m.output = append(m.output, "  /stats reset")
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Highlight.Render("Description:"))
+		m.output = append(m.output, "  Shows the movement commands sent this session, the rooms entered and")
+		m.output = append(m.output, "  how many of those were new to the map. The exploration rate is the")
+		m.output = append(m.output, fmt.Sprintf("  new rooms found over the last %d moves: as it drops, there is less of", mapper.OdometerWindow))
+		m.output = append(m.output, "  the area left to find. The share of the map's exits already walked")
+		m.output = append(m.output, "  shows how much remains. /stats reset starts the counts again.")
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Highlight.Render("Examples:"))
+		m.output = append(m.output, "  /stats")
+		m.output = append(m.output, "  /stats reset")
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Debug.Render("See also: /help explore, /help trail"))
+
+	case "legend":
+		m.output = append(m.output, m.colors().Info.Render("=== /legend - List Rooms on Map ==="))
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Highlight.Render("Usage:"))
+		m.output = append(m.output, "  /legend")
+		m.output = append(m.output, "  /legend clear")
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Highlight.Render("Description:"))
+		m.output = append(m.output, "  Lists all rooms currently displayed in the map panel.")
+		m.output = append(m.output, "  Shows rooms with their coordinates and allows selection by number")
+		m.output = append(m.output, "  for use with /point, /wayfind, or /go commands. Click a listed room")
+		m.output = append(m.output, "  with the mouse to walk there.")
+		m.output = append(m.output, "  Each room's number is drawn next to it on the map until you move,")
+		m.output = append(m.output, "  list other rooms (/rooms, /wayfind) or use /legend clear.")
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Debug.Render("See also: /help nearby, /help rooms"))
+
+	case "trigger", "triggers":
+		m.output = append(m.output, m.colors().Info.Render("=== Triggers - Automated Responses ==="))
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Highlight.Render("Usage:"))
+		m.output = append(m.output, "  /trigger \"pattern\" \"action\"")
+		m.output = append(m.output, "  /trigger -glob \"pattern\" \"action\"")
+		m.output = append(m.output, "  /trigger -re \"regex\" \"action\"")
+		m.output = append(m.output, "  /trigger -cooldown <sec> \"pattern\" \"action\"")
+		m.output = append(m.output, "  /trigger -multiline <n> \"pattern\" \"action\"")
+		m.output = append(m.output, "  /trigger -group <name> \"pattern\" \"action\"")
+		m.output = append(m.output, "  /trigger -raw \"pattern\" \"action\"")
+		m.output = append(m.output, "  /trigger -bell \"pattern\" \"action\"")
+		m.output = append(m.output, "  /trigger \"pattern\" /gag")
+		m.output = append(m.output, "  /trigger \"pattern\" /highlight [color]")
+		m.output = append(m.output, "  /trigger test \"line\"")
+		m.output = append(m.output, "  /triggers list")
+		m.output = append(m.output, "  /triggers remove <number>")
+		m.output = append(m.output, "  /triggers enable <number>")
+		m.output = append(m.output, "  /triggers disable <number>")
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Highlight.Render("Description:"))
+		m.output = append(m.output, "  Triggers automatically execute commands when MUD output matches a pattern.")
+		m.output = append(m.output, "  Patterns support variable capture with <varname> syntax.")
+		m.output = append(m.output, "  With -glob, * matches any text and ? one character, and the pattern")
+		m.output = append(m.output, "  must match the whole line. Each * is captured as <1>, <2>, ... in the")
+		m.output = append(m.output, "  action. Write \\* or \\? to match a literal asterisk or question mark.")
+		m.output = append(m.output, "  With -re, the pattern is a regular expression that can match anywhere in")
+		m.output = append(m.output, "  the line (use ^ and $ to anchor it). Its groups are <1>, <2>, ... in the")
+		m.output = append(m.output, "  action, and a named group (?P<name>...) is <name> too. A pattern that")
+		m.output = append(m.output, "  isn't a valid regular expression is rejected when it is added.")
+		m.output = append(m.output, "  Actions can include multiple commands separated by semicolons (;).")
+		m.output = append(m.output, "  With -cooldown, the trigger doesn't fire again until that many seconds")
+		m.output = append(m.output, "  have passed, even if more matching lines arrive.")
+		m.output = append(m.output, "  With -multiline, the pattern is matched against the last n lines joined")
+		m.output = append(m.output, "  by spaces, and fires when a match reaches the newest line.")
+		m.output = append(m.output, "  With -group, the trigger joins a group that /group can switch off.")
+		m.output = append(m.output, "  /triggers disable turns one trigger off without removing it, and")
+		m.output = append(m.output, "  /triggers enable turns it back on; /triggers list shows [on] or [off].")
+		m.output = append(m.output, "  Patterns match the text without color codes, so a color change in the")
+		m.output = append(m.output, "  middle of a line doesn't stop a match. With -raw, the pattern matches")
+		m.output = append(m.output, "  the line with its color codes instead; write \\e for the escape")
+		m.output = append(m.output, "  character that starts each code.")
+		m.output = append(m.output, "  With -bell, the terminal bell rings (a beep in the browser) when the")
+		m.output = append(m.output, "  trigger fires, at most once every few seconds. The action may be \"\".")
+		m.output = append(m.output, "  An action of the form panel:<name>:<text> shows the text in a sidebar")
+		m.output = append(m.output, "  panel with that name instead of sending it, creating the panel if needed.")
+		m.output = append(m.output, "  With /gag in place of the action, matching lines are hidden instead; they")
+		m.output = append(m.output, "  are still logged and the mapper still sees them. /highlight shows them")
+		m.output = append(m.output, "  in the highlight color instead, or with a color, colors just the text the")
+		m.output = append(m.output, "  pattern matches, keeping the MUD's colors around it. Colors: black, red,")
+		m.output = append(m.output, "  green, yellow, blue, magenta, cyan, white and bright-<color> for each.")
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Highlight.Render("Examples:"))
+		m.output = append(m.output, "  /trigger \"You are hungry\" \"eat bread\"")
+		m.output = append(m.output, "  /trigger \"<player> has arrived\" \"say Hello <player>\"")
+		m.output = append(m.output, "  /trigger \"Low health!\" \"drink potion;flee\"")
+		m.output = append(m.output, "  /trigger -glob \"You receive * gold*\" \"split <1>\"")
+		m.output = append(m.output, "  /trigger -re \"^(\\w+) hits you\" \"flee\"")
+		m.output = append(m.output, "  /trigger -re \"(\\d+) gold coins\" \"get <1> coins\"")
+		m.output = append(m.output, "  /trigger -cooldown 10 \"You are bleeding\" \"cast 'heal' self\"")
+		m.output = append(m.output, "  /trigger -glob -multiline 2 \"*Time passes.*You are hungry.*\" \"eat bread\"")
+		m.output = append(m.output, "  /trigger -glob \"Quest: *\" \"panel:Quest:<1>\"")
+		m.output = append(m.output, "  /trigger -raw -glob \"\\e[1;31m*\" \"say Red alert: <1>\"")
+		m.output = append(m.output, "  /trigger -bell -glob \"* tells you *\" \"\"")
+		m.output = append(m.output, "  /trigger \"The sun rises\" /gag")
+		m.output = append(m.output, "  /trigger -glob \"* enters the game.\" /highlight")
+		m.output = append(m.output, "  /trigger \"<name> tells you\" /highlight yellow")
+		m.output = append(m.output, "  /trigger test \"The goblin dies\"  - Show what would fire, without sending")
+		m.output = append(m.output, "  /triggers list                 - List all triggers")
+		m.output = append(m.output, "  /triggers remove 1             - Remove trigger #1")
+		m.output = append(m.output, "  /triggers disable 2            - Turn trigger #2 off, keeping it")
+		m.output = append(m.output, "  /triggers enable 2             - Turn trigger #2 back on")
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Debug.Render("Multi-command actions execute sequentially with 1-second delay"))
+		m.output = append(m.output, m.colors().Debug.Render("See also: /help edit, /help learn, /help alias, /help group, /help stop"))
+
+	case "edit":
+		m.output = append(m.output, m.colors().Info.Render("=== /edit - Trigger Editor ==="))
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Highlight.Render("Usage:"))
+		m.output = append(m.output, "  /edit triggers")
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Highlight.Render("Description:"))
+		m.output = append(m.output, "  Opens the triggers in a full-screen list instead of quoting them in")
+		m.output = append(m.output, "  /trigger commands. Triggers are tried from the top, so order matters.")
+		m.output = append(m.output, "    Up/Down, PgUp/PgDn     Select a trigger")
+		m.output = append(m.output, "    a                      Add a trigger below the selected one")
+		m.output = append(m.output, "    Enter or e             Edit the pattern, then Enter to edit the action")
+		m.output = append(m.output, "    d or Delete            Delete the selected trigger")
+		m.output = append(m.output, "    Space                  Switch the selected trigger on or off")
+		m.output = append(m.output, "    K/J or Shift+Up/Down   Move the selected trigger up or down")
+		m.output = append(m.output, "    Esc                    Save the changes and close")
+		m.output = append(m.output, "    Ctrl+C                 Close without saving")
+		m.output = append(m.output, "  While typing a pattern or action, Esc drops what was typed.")
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Highlight.Render("Examples:"))
+		m.output = append(m.output, "  /edit triggers")
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Debug.Render("See also: /help trigger, /help group"))
+
+	case "notrig":
+		m.output = append(m.output, m.colors().Info.Render("=== /notrig - Send Without Triggers ==="))
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Highlight.Render("Usage:"))
+		m.output = append(m.output, "  /notrig <command>")
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Highlight.Render("Description:"))
+		m.output = append(m.output, "  Sends the command right away and doesn't match the next output from the")
+		m.output = append(m.output, "  MUD against the triggers, so doing by hand what a trigger reacts to")
+		m.output = append(m.output, "  doesn't set it off (or start a trigger loop). Triggers work again on the")
+		m.output = append(m.output, "  output after that.")
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Highlight.Render("Examples:"))
+		m.output = append(m.output, "  /notrig say I am hungry")
+		m.output = append(m.output, "  /notrig get all corpse")
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Debug.Render("See also: /help trigger, /help group"))
+
+	case "learn":
+		m.output = append(m.output, m.colors().Info.Render("=== /learn - Make a Trigger From a Line ==="))
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Highlight.Render("Usage:"))
+		m.output = append(m.output, "  /learn")
+		m.output = append(m.output, "  /learn <number> <action>")
+		m.output = append(m.output, "  /learn save")
+		m.output = append(m.output, "  /learn cancel")
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Highlight.Render("Description:"))
+		m.output = append(m.output, fmt.Sprintf("  /learn lists the last %d lines from the MUD, newest first. Pick one by", learnLines))
+		m.output = append(m.output, "  number and give the action, and the line is turned into a trigger")
+		m.output = append(m.output, "  pattern: numbers become <amount> and names become <name> (later ones")
+		m.output = append(m.output, "  <amount2>, <name2>), so the trigger also fires when they change. The")
+		m.output = append(m.output, "  action can use the same placeholders. The trigger is shown with what it")
+		m.output = append(m.output, "  would send on that line, and only added once you type /learn save.")
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Highlight.Render("Examples:"))
+		m.output = append(m.output, "  /learn")
+		m.output = append(m.output, "  /learn 3 split <amount>        - For \"You receive 42 gold coins.\"")
+		m.output = append(m.output, "  /learn 1 say Welcome back, <name>!")
+		m.output = append(m.output, "  /learn save")
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Debug.Render("See also: /help trigger, /help edit"))
+
+	case "ticktrigger", "ticktriggers":
+		m.output = append(m.output, m.colors().Info.Render("=== Tick Triggers - Time-Based Automation ==="))
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Highlight.Render("Usage:"))
+		m.output = append(m.output, "  /ticktrigger <tick_time> \"commands\"")
+		m.output = append(m.output, "  /ticktriggers list")
+		m.output = append(m.output, "  /ticktriggers remove <number>")
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Highlight.Render("Description:"))
+		m.output = append(m.output, "  Tick triggers execute commands at a specific tick time (T:#).")
+		m.output = append(m.output, "  The client tracks tick times from prompts and projects when triggers should fire.")
+		m.output = append(m.output, "  Triggers fire even if no new prompt appears, based on internal timer.")
+		m.output = append(m.output, "  Commands can include multiple commands separated by semicolons (;).")
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Highlight.Render("Examples:"))
+		m.output = append(m.output, "  /ticktrigger 5 \"cast 'heal'\"")
+		m.output = append(m.output, "  /ticktrigger 4 \"cast 'bless';say Ready!\"")
+		m.output = append(m.output, "  /ticktrigger 10 \"get all from corpse\"")
+		m.output = append(m.output, "  /ticktriggers list             - List all tick triggers")
+		m.output = append(m.output, "  /ticktriggers remove 1         - Remove tick trigger #1")
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Debug.Render("Note: Tick interval is auto-detected from prompts (typically 60 or 75 seconds)"))
+		m.output = append(m.output, m.colors().Debug.Render("Multi-command actions execute sequentially with 1-second delay"))
+		m.output = append(m.output, m.colors().Debug.Render("See also: /help trigger, /help stop"))
+
+	case "alias", "aliases":
+		m.output = append(m.output, m.colors().Info.Render("=== Aliases - Command Shortcuts ==="))
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Highlight.Render("Usage:"))
+		m.output = append(m.output, "  /alias \"name\" \"template\"")
+		m.output = append(m.output, "  /alias -group <name> \"name\" \"template\"")
+		m.output = append(m.output, "  /aliases list")
+		m.output = append(m.output, "  /aliases remove <number>")
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Highlight.Render("Description:"))
+		m.output = append(m.output, "  Aliases create command shortcuts with parameter substitution.")
+		m.output = append(m.output, "  Use <varname> in the template to capture parameters from the alias command.")
+		m.output = append(m.output, "  Templates can include multiple commands separated by semicolons (;).")
+		m.output = append(m.output, "  With -group, the alias joins a group that /group can switch off.")
+		m.output = append(m.output, "  Different groups can each have an alias with the same name.")
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Highlight.Render("Examples:"))
+		m.output = append(m.output, "  /alias \"gat\" \"give all <target>\"")
+		m.output = append(m.output, "  > gat mary                     - Sends: give all mary")
+		m.output = append(m.output, "")
+		m.output = append(m.output, "  /alias \"k\" \"kill <target>\"")
+		m.output = append(m.output, "  > k goblin                     - Sends: kill goblin")
+		m.output = append(m.output, "")
+		m.output = append(m.output, "  /alias \"prep\" \"get all from corpse;sacrifice corpse\"")
+		m.output = append(m.output, "  > prep                         - Sends both commands with delay")
+		m.output = append(m.output, "")
+		m.output = append(m.output, "  /aliases list                  - List all aliases")
+		m.output = append(m.output, "  /aliases remove 1              - Remove alias #1")
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Debug.Render("Multi-command aliases execute sequentially with 1-second delay"))
+		m.output = append(m.output, m.colors().Debug.Render("See also: /help sub, /help trigger, /help group, /help stop"))
+
+	case "group":
+		m.output = append(m.output, m.colors().Info.Render("=== /group - Alias and Trigger Groups ==="))
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Highlight.Render("Usage:"))
+		m.output = append(m.output, "  /group list")
+		m.output = append(m.output, "  /group enable <name>")
+		m.output = append(m.output, "  /group disable <name>")
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Highlight.Render("Description:"))
+		m.output = append(m.output, "  Aliases and triggers added with -group <name> belong to that group.")
+		m.output = append(m.output, "  Disabling a group stops all of its aliases expanding and its triggers")
+		m.output = append(m.output, "  firing until it is enabled again, e.g. to switch between characters")
+		m.output = append(m.output, "  or play styles. Groups and their state are saved between sessions.")
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Highlight.Render("Examples:"))
+		m.output = append(m.output, "  /alias -group mage \"k\" \"cast 'magic missile' <target>\"")
+		m.output = append(m.output, "  /trigger -group explore \"<player> has arrived\" \"wave <player>\"")
+		m.output = append(m.output, "  /group disable explore")
+		m.output = append(m.output, "  /group enable mage")
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Debug.Render("See also: /help alias, /help trigger"))
+
+	case "capture", "captures":
+		m.output = append(m.output, m.colors().Info.Render("=== Captures - Records Filled From MUD Output ==="))
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Highlight.Render("Usage:"))
+		m.output = append(m.output, "  /capture <name> \"pattern\" [\"format\"]")
+		m.output = append(m.output, "  /captures list")
+		m.output = append(m.output, "  /captures remove <number>")
+		m.output = append(m.output, "  /captures clear <name>")
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Highlight.Render("Description:"))
+		m.output = append(m.output, "  Each line matching the regular expression adds an entry to the record")
+		m.output = append(m.output, "  with that name, shown in a sidebar panel (quest is shown as Quests).")
+		m.output = append(m.output, "  The first group in parentheses is the entry's key, so a later line for")
+		m.output = append(m.output, "  the same quest updates its entry instead of adding another. The format")
+		m.output = append(m.output, "  shows the groups as <1>, <2>, ...; without one the matched text is shown.")
+		m.output = append(m.output, "  Captures are saved; what they capture lasts for the session.")
+		m.output = append(m.output, "  /captures clear empties a record and closes its panel.")
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Highlight.Render("Examples:"))
+		m.output = append(m.output, "  /capture quest \"Quest: (.+) - (\\d+)/(\\d+)\" \"<1>: <2>/<3>\"")
+		m.output = append(m.output, "  > Quest: Slay the rats - 3/10   - Quests panel: Slay the rats: 3/10")
+		m.output = append(m.output, "  /capture task \"^Task: (.+)$\"")
+		m.output = append(m.output, "  /captures clear quest           - Start the quest list afresh")
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Debug.Render("See also: /help trigger"))
+
+	case "sub", "subs":
+		m.output = append(m.output, m.colors().Info.Render("=== Substitutions - Rewrite Outgoing Commands ==="))
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Highlight.Render("Usage:"))
+		m.output = append(m.output, "  /sub \"prefix\" \"replacement\"")
+		m.output = append(m.output, "  /subs list")
+		m.output = append(m.output, "  /subs remove <number>")
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Highlight.Render("Description:"))
+		m.output = append(m.output, "  A command sent to the MUD that starts with prefix has that prefix replaced.")
+		m.output = append(m.output, "  Unlike aliases, which match the whole first word, the prefix is matched as")
+		m.output = append(m.output, "  plain text, so it can be a symbol or include a space. Substitutions apply")
+		m.output = append(m.output, "  after aliases are expanded, to each command separated by ';', and to")
+		m.output = append(m.output, "  trigger and macro commands. The longest matching prefix wins and only one")
+		m.output = append(m.output, "  substitution is applied. Passwords are never rewritten.")
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Highlight.Render("Examples:"))
+		m.output = append(m.output, "  /sub \"'\" \"say \"")
+		m.output = append(m.output, "  > 'hello                       - Sends: say hello")
+		m.output = append(m.output, "")
+		m.output = append(m.output, "  /sub \"gt \" \"group tell \"")
+		m.output = append(m.output, "  > gt ready?                    - Sends: group tell ready?")
+		m.output = append(m.output, "")
+		m.output = append(m.output, "  /sub \"'\" \"gossip \"            - Replaces the ' substitution")
+		m.output = append(m.output, "  /subs list                     - List all substitutions")
+		m.output = append(m.output, "  /subs remove 1                 - Remove substitution #1")
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Debug.Render("See also: /help alias"))
+
+	case "macro", "macros":
+		m.output = append(m.output, m.colors().Info.Render("=== Macros - Function Key Bindings ==="))
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Highlight.Render("Usage:"))
+		m.output = append(m.output, "  /macro <key> \"commands\"")
+		m.output = append(m.output, "  /macro <key>")
+		m.output = append(m.output, "  /macros list")
+		m.output = append(m.output, "  /macros remove <number>")
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Highlight.Render("Description:"))
+		m.output = append(m.output, "  Binds a key to commands that run when the key is pressed, even while")
+		m.output = append(m.output, "  you are typing. Keys can be F1-F20 or Alt+<key>. Terminals send numpad")
+		m.output = append(m.output, "  keys as ordinary digits, so use Alt+<digit> for numpad-style bindings.")
+		m.output = append(m.output, "  Commands can use aliases and multiple commands separated by semicolons.")
+		m.output = append(m.output, "  Binding a key again replaces its command.")
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Highlight.Render("Examples:"))
+		m.output = append(m.output, "  /macro F1 \"cast 'cure light' self\"")
+		m.output = append(m.output, "  /macro Alt+8 \"north\"")
+		m.output = append(m.output, "  /macro F1                      - Show what F1 is bound to")
+		m.output = append(m.output, "  /macros list                   - List all macros")
+		m.output = append(m.output, "  /macros remove 1               - Remove macro #1")
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Debug.Render("See also: /help alias"))
+
+	case "share":
+		m.output = append(m.output, m.colors().Info.Render("=== /share - Share Web Session ==="))
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Highlight.Render("Usage:"))
+		m.output = append(m.output, "  /share")
+		m.output = append(m.output, "  /share view")
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Highlight.Render("Description:"))
+		m.output = append(m.output, "  Generates a shareable URL for the current web session.")
+		m.output = append(m.output, "  Anyone who opens this URL will see and control the same session.")
+		m.output = append(m.output, "  The browser displays the URL above the terminal with a copy button;")
+		m.output = append(m.output, "  it isn't printed in the terminal, which spectators can see.")
+		m.output = append(m.output, "")
+		m.output = append(m.output, "  /share view gives a spectator URL instead, for streaming a session")
+		m.output = append(m.output, "  without handing over the keyboard. It doesn't contain the session ID,")
+		m.output = append(m.output, "  and the web server drops everything a spectator types. It is printed")
+		m.output = append(m.output, "  with a QR code so it can be scanned from a phone.")
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Debug.Render("Note: Only available in web mode"))
+		m.output = append(m.output, m.colors().Debug.Render("Start web mode with: dikuclient --web"))
+
+	case "wealth":
+		m.output = append(m.output, m.colors().Info.Render("=== /wealth - Gold Income ==="))
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Highlight.Render("Usage:"))
+		m.output = append(m.output, "  /wealth                  - Show gold carried, gained and gold per hour")
+		m.output = append(m.output, "  /wealth reset            - Start counting again from now")
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Highlight.Render("Description:"))
+		m.output = append(m.output, "  Money messages such as 'You get 150 gold coins.', 'There were 23 coins.'")
+		m.output = append(m.output, "  and 'You have 3 platinum, 20 gold.' are tracked, along with the coins field")
+		m.output = append(m.output, "  of the prompt (e.g. 570C). Once the gold carried is known, the session total")
+		m.output = append(m.output, "  is the change in it, so spending counts too; otherwise it is the gold picked")
+		m.output = append(m.output, "  up. The rate is averaged since the session started. A platinum coin counts")
+		m.output = append(m.output, "  as 10 gold.")
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Debug.Render("See also: /help combat, /help levels"))
+
+	case "stopwatch":
+		m.output = append(m.output, m.colors().Info.Render("=== /stopwatch - Time Runs and Respawns ==="))
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Highlight.Render("Usage:"))
+		m.output = append(m.output, "  /stopwatch               - Show the time and laps so far")
+		m.output = append(m.output, "  /stopwatch start         - Start timing (again from zero if running)")
+		m.output = append(m.output, "  /stopwatch lap           - End a lap and show its time")
+		m.output = append(m.output, "  /stopwatch stop          - Stop and show the total and every lap")
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Highlight.Render("Description:"))
+		m.output = append(m.output, "  A stopwatch for timing a run through a zone or how long a monster")
+		m.output = append(m.output, "  takes to respawn. Times are shown to a tenth of a second, and the")
+		m.output = append(m.output, "  fastest lap is marked. While it runs, the status bar shows the time.")
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Highlight.Render("Examples:"))
+		m.output = append(m.output, "  /stopwatch start")
+		m.output = append(m.output, "  /stopwatch lap")
+		m.output = append(m.output, "  /stopwatch stop")
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Debug.Render("See also: /help levels, /help ticktrigger"))
+
+	case "levels":
+		m.output = append(m.output, m.colors().Info.Render("=== /levels - Level Milestones ==="))
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Highlight.Render("Usage:"))
+		m.output = append(m.output, "  /levels                  - List levels gained and estimate the next one")
+		m.output = append(m.output, "  /levels clear            - Forget the levels recorded")
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Highlight.Render("Description:"))
+		m.output = append(m.output, "  Level-up messages such as 'You raise a level! You are now level 25.' or")
+		m.output = append(m.output, "  'Welcome to level 12!' are recorded with the time and the experience")
+		m.output = append(m.output, "  gained this session, and kept between sessions in levels.json. /levels")
+		m.output = append(m.output, fmt.Sprintf("  lists the last %d with the time each took, this session's XP per hour,", levelsShown))
+		m.output = append(m.output, "  and when the next level is due at the pace of the last few.")
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Debug.Render("See also: /help wealth, /help combat"))
+
+	case "combat":
+		m.output = append(m.output, m.colors().Info.Render("=== /combat - Combat Log ==="))
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Highlight.Render("Usage:"))
+		m.output = append(m.output, "  /combat                              - Show the current and last fight")
+		m.output = append(m.output, "  /combat reset                        - Clear the combat log")
+		m.output = append(m.output, "  /combat patterns                     - List custom damage patterns")
+		m.output = append(m.output, "  /combat pattern dealt|taken \"regex\"  - Add a custom damage pattern")
+		m.output = append(m.output, "  /combat pattern remove <n>           - Remove a custom pattern")
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Highlight.Render("Description:"))
+		m.output = append(m.output, "  Damage messages are tallied over each fight and shown in a Combat panel")
+		m.output = append(m.output, "  under XP/s. When the target dies, a summary is printed. DikuMUD and ROM")
+		m.output = append(m.output, "  messages are recognised out of the box, with damage estimated from the")
+		m.output = append(m.output, "  verb (\"hits very hard\", \"*** DEMOLISHES ***\").")
+		m.output = append(m.output, "  Custom patterns are tried first. A dealt pattern needs a (?P<target>...)")
+		m.output = append(m.output, "  group, a taken pattern an (?P<attacker>...) group, and either may capture")
+		m.output = append(m.output, "  an exact (?P<amount>...) for MUDs that print damage numbers.")
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Highlight.Render("Examples:"))
+		m.output = append(m.output, "  /combat pattern dealt \"^You strike (?P<target>.+) for (?P<amount>\\d+) damage\"")
+		m.output = append(m.output, "  /combat pattern taken \"^(?P<attacker>.+) bites you\"")
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Debug.Render("See also: /help affects, /help target"))
+
+	case "target":
+		m.output = append(m.output, m.colors().Info.Render("=== /target - Combat Target ==="))
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Highlight.Render("Usage:"))
+		m.output = append(m.output, "  /target                  - Show the current target")
+		m.output = append(m.output, "  /target <name>           - Set the target")
+		m.output = append(m.output, "  /target off              - Clear the target")
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Highlight.Render("Description:"))
+		m.output = append(m.output, "  The target's name is shown bold and underlined in the output, and")
+		m.output = append(m.output, "  <target> in anything you send (typed commands, aliases, triggers and")
+		m.output = append(m.output, "  macros) is replaced by it. Without a target set, the one named by the")
+		m.output = append(m.output, "  combat prompt becomes the target. It is cleared when it dies.")
+		m.output = append(m.output, "  An alias's <target> takes the target when the alias is given no")
+		m.output = append(m.output, "  argument for it, so k below kills the target and k goblin the goblin.")
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Highlight.Render("Examples:"))
+		m.output = append(m.output, "  /target orc")
+		m.output = append(m.output, "  /alias \"k\" \"kill <target>\"")
+		m.output = append(m.output, "  cast 'magic missile' <target>")
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Debug.Render("See also: /help combat, /help alias, /help set"))
+
+	case "afk":
+		m.output = append(m.output, m.colors().Info.Render("=== /afk - Go Safe When Idle ==="))
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Highlight.Render("Usage:"))
+		m.output = append(m.output, "  /afk")
+		m.output = append(m.output, "  /afk <seconds> [\"command\"]")
+		m.output = append(m.output, "  /afk off")
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Highlight.Render("Description:"))
+		m.output = append(m.output, "  After the given number of seconds without a keystroke, sends a command")
+		m.output = append(m.output, "  to put your character somewhere safe (default: rest). It is sent once")
+		m.output = append(m.output, "  per idle stretch; any key starts the timer again. Separate several")
+		m.output = append(m.output, "  commands with semicolons. When the MUD says it is about to disconnect")
+		m.output = append(m.output, "  you for idling, a warning is shown.")
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Highlight.Render("Examples:"))
+		m.output = append(m.output, "  /afk 300")
+		m.output = append(m.output, "  /afk 120 \"sit;rest\"")
+		m.output = append(m.output, "  /afk off")
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Debug.Render("Note: Unlike a keepalive, this changes your character's state in the game"))
+
+	case "autoloot":
+		m.output = append(m.output, m.colors().Info.Render("=== /autoloot - Loot After a Kill ==="))
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Highlight.Render("Usage:"))
+		m.output = append(m.output, "  /autoloot")
+		m.output = append(m.output, "  /autoloot on [\"command\"]")
+		m.output = append(m.output, "  /autoloot off")
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Highlight.Render("Description:"))
+		m.output = append(m.output, "  When a death message ('The orc is dead!') is seen, queues a command to")
+		m.output = append(m.output, "  loot the corpse (default: get all corpse). <creature> in the command is")
+		m.output = append(m.output, "  replaced by the name of what died, for MUDs that name corpses after it.")
+		m.output = append(m.output, "  Separate several commands with semicolons. They go through the command")
+		m.output = append(m.output, "  queue, so /stop cancels them. The setting is saved between sessions.")
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Highlight.Render("Examples:"))
+		m.output = append(m.output, "  /autoloot on")
+		m.output = append(m.output, "  /autoloot on \"get all corpse;get all\"")
+		m.output = append(m.output, "  /autoloot on \"get all 'corpse of the <creature>'\"")
+		m.output = append(m.output, "  /autoloot off")
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Debug.Render("See also: /help stop"))
+
+	case "wimpy":
+		m.output = append(m.output, m.colors().Info.Render("=== /wimpy - Flee When Hurt ==="))
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Highlight.Render("Usage:"))
+		m.output = append(m.output, "  /wimpy")
+		m.output = append(m.output, "  /wimpy <hp>")
+		m.output = append(m.output, "  /wimpy <percent>%")
+		m.output = append(m.output, "  /wimpy off")
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Highlight.Render("Description:"))
+		m.output = append(m.output, "  Sends flee once when your hit points drop below the threshold, and again")
+		m.output = append(m.output, "  only after they have recovered. Hit points are read from the best source")
+		m.output = append(m.output, "  available: GMCP Char.Vitals first, then MSDP HEALTH, then the prompt")
+		m.output = append(m.output, "  (120/150hp, [HP:50/100], 85%hp or 101H). A source that can't be compared")
+		m.output = append(m.output, "  with the threshold, such as a percent prompt for a hit point threshold")
+		m.output = append(m.output, "  with no maximum known, is skipped. /wimpy alone shows the setting and the")
+		m.output = append(m.output, "  hit points last seen with their source. The setting is saved.")
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Highlight.Render("Examples:"))
+		m.output = append(m.output, "  /wimpy 50")
+		m.output = append(m.output, "  /wimpy 25%")
+		m.output = append(m.output, "  /wimpy off")
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Debug.Render("See also: /help promptpattern, /help telnet"))
+
+	case "autofollow":
+		m.output = append(m.output, m.colors().Info.Render("=== /autofollow - Accept Group Invitations ==="))
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Highlight.Render("Usage:"))
+		m.output = append(m.output, "  /autofollow")
+		m.output = append(m.output, "  /autofollow <leader> [\"command\"]")
+		m.output = append(m.output, "  /autofollow off")
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Highlight.Render("Description:"))
+		m.output = append(m.output, "  When the leader invites you ('Gandalf invites you to join the group.'),")
+		m.output = append(m.output, "  queues a command to accept (default: follow <leader>;group). <leader> in")
+		m.output = append(m.output, "  the command is replaced by the leader's name. Invitations from anyone")
+		m.output = append(m.output, "  else are left alone. Who you follow is tracked from 'You now follow ...'")
+		m.output = append(m.output, "  and 'You stop following ...' and shown by /autofollow. The setting lasts")
+		m.output = append(m.output, "  for this session only.")
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Highlight.Render("Examples:"))
+		m.output = append(m.output, "  /autofollow gandalf")
+		m.output = append(m.output, "  /autofollow gandalf \"follow <leader>;group;say Ready!\"")
+		m.output = append(m.output, "  /autofollow off")
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Debug.Render("See also: /help autoassist, /help group, /help trigger"))
+
+	case "autoassist":
+		m.output = append(m.output, m.colors().Info.Render("=== /autoassist - Join the Tank's Fights ==="))
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Highlight.Render("Usage:"))
+		m.output = append(m.output, "  /autoassist")
+		m.output = append(m.output, "  /autoassist <leader> [\"command\"]")
+		m.output = append(m.output, "  /autoassist off")
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Highlight.Render("Description:"))
+		m.output = append(m.output, "  When the leader attacks something ('Gandalf hits the orc.', 'Gandalf's")
+		m.output = append(m.output, "  slash misses the orc.'), queues a command to join in (default: assist")
+		m.output = append(m.output, "  <leader>). <leader> is replaced by the leader's name and <target> by what")
+		m.output = append(m.output, "  they attacked. Nothing is sent while you are already fighting, and only")
+		m.output = append(m.output, "  once per fight: the next fight is joined after no combat prompt has been")
+		m.output = append(m.output, "  seen for a few seconds. The setting lasts for this session only.")
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Highlight.Render("Examples:"))
+		m.output = append(m.output, "  /autoassist gandalf")
+		m.output = append(m.output, "  /autoassist gandalf \"kill <target>\"")
+		m.output = append(m.output, "  /autoassist off")
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Debug.Render("See also: /help autofollow, /help target, /help combat"))
+
+	case "retrycast":
+		m.output = append(m.output, m.colors().Info.Render("=== /retrycast - Cast Until It Works ==="))
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Highlight.Render("Usage:"))
+		m.output = append(m.output, "  /retrycast \"<cast command>\" \"<failure pattern>\" [<max tries>]")
+		m.output = append(m.output, "  /retrycast")
+		m.output = append(m.output, "  /retrycast stop")
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Highlight.Render("Description:"))
+		m.output = append(m.output, "  Sends the cast command. If a line matching the failure pattern (a")
+		m.output = append(m.output, fmt.Sprintf("  case-insensitive regex) arrives within %d seconds, the cast is sent", int(retryCastWindow/time.Second)))
+		m.output = append(m.output, fmt.Sprintf("  again, up to max tries casts in all (default %d). When no failure is", defaultRetryCastTries))
+		m.output = append(m.output, "  seen in time the cast is taken to have worked. /retrycast shows the")
+		m.output = append(m.output, "  cast in progress; /retrycast stop or /stop gives up on it.")
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Highlight.Render("Examples:"))
+		m.output = append(m.output, "  /retrycast \"cast 'sanctuary'\" \"You lost your concentration\" 5")
+		m.output = append(m.output, "  /retrycast \"cast 'bless' gandalf\" \"lost your concentration\"")
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Debug.Render("See also: /help trigger, /help stop"))
+
+	case "throttle":
+		m.output = append(m.output, m.colors().Info.Render("=== /throttle - Command Spacing ==="))
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Highlight.Render("Usage:"))
+		m.output = append(m.output, "  /throttle")
+		m.output = append(m.output, "  /throttle <ms>")
+		m.output = append(m.output, "  /throttle off")
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Highlight.Render("Description:"))
+		m.output = append(m.output, "  Sets the minimum time between any two commands sent to the MUD, for")
+		m.output = append(m.output, "  servers that disconnect players who send too fast. Typed commands,")
+		m.output = append(m.output, "  macros, triggers and auto-walk all share the limit; commands sent in")
+		m.output = append(m.output, "  a burst wait their turn instead of being dropped.")
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Highlight.Render("Examples:"))
+		m.output = append(m.output, "  /throttle 250")
+		m.output = append(m.output, "  /throttle off")
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Debug.Render("See also: /help walkspeed"))
+
+	case "repeat":
+		m.output = append(m.output, m.colors().Info.Render("=== /repeat - Repeat a Command ==="))
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Highlight.Render("Usage:"))
+		m.output = append(m.output, "  /repeat <n> <command>")
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Highlight.Render("Description:"))
+		m.output = append(m.output, "  Queues the command n times. The commands go out one per tick through")
+		m.output = append(m.output, "  the command queue, like a paste, and /stop cancels the rest. Aliases")
+		m.output = append(m.output, fmt.Sprintf("  are expanded and semicolons separate commands. More than %d repeats", repeatConfirmCount))
+		m.output = append(m.output, fmt.Sprintf("  wait for Enter before anything is queued; at most %d are allowed.", maxRepeatCount))
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Highlight.Render("Examples:"))
+		m.output = append(m.output, "  /repeat 5 cast 'magic missile' orc")
+		m.output = append(m.output, "  /repeat 3 get all corpse;sacrifice corpse")
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Debug.Render("See also: /help throttle, /stop"))
+
+	case "log":
+		m.output = append(m.output, m.colors().Info.Render("=== /log - Structured Output Log ==="))
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Highlight.Render("Usage:"))
+		m.output = append(m.output, "  /log")
+		m.output = append(m.output, "  /log json start [file]")
+		m.output = append(m.output, "  /log json stop")
+		m.output = append(m.output, "  /log redact [add <text> | remove <text>]")
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Highlight.Render("Description:"))
+		m.output = append(m.output, "  Writes every line of MUD output to a file as one JSON object per line,")
+		m.output = append(m.output, "  for stats tools and later analysis. Each object has the time (ts), the")
+		m.output = append(m.output, "  line as received (raw), the line without colors (stripped) and its")
+		m.output = append(m.output, "  kind: room, prompt, tell, combat or other.")
+		m.output = append(m.output, "  Without a file name the log goes to mud-output-<timestamp>.jsonl in the")
+		m.output = append(m.output, "  current directory; an existing file is appended to.")
+		m.output = append(m.output, "")
+		m.output = append(m.output, "  So logs can be attached to bug reports, text added with /log redact is")
+		m.output = append(m.output, "  written to every log (the JSON log and the -log-all and telnet debug")
+		m.output = append(m.output, fmt.Sprintf("  logs) as %s, ignoring case. Your login name, passwords typed at", redact.Placeholder))
+		m.output = append(m.output, "  a password prompt and the /share IDs are always redacted. The list is")
+		m.output = append(m.output, fmt.Sprintf("  saved; text needs at least %d characters. /record recordings are kept", redact.MinLength))
+		m.output = append(m.output, "  byte for byte and are not redacted.")
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Highlight.Render("Examples:"))
+		m.output = append(m.output, "  /log json start")
+		m.output = append(m.output, "  /log json start session.jsonl")
+		m.output = append(m.output, "  /log json stop")
+		m.output = append(m.output, "  /log redact add Gandalf")
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Debug.Render("Start the client with -log-json to log from the first line"))
+
+	case "record":
+		m.output = append(m.output, m.colors().Info.Render("=== /record - Session Recording ==="))
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Highlight.Render("Usage:"))
+		m.output = append(m.output, "  /record")
+		m.output = append(m.output, "  /record session [file]")
+		m.output = append(m.output, "  /record stop")
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Highlight.Render("Description:"))
+		m.output = append(m.output, "  Records everything the MUD sends, byte for byte and with its timing,")
+		m.output = append(m.output, "  so a session can be shared or a display problem reproduced later.")
+		m.output = append(m.output, "  Play a recording back with: dikuclient --replay <file>")
+		m.output = append(m.output, "  Add --replay-speed 4 to play it four times as fast, or 0 for no delays.")
+		m.output = append(m.output, "  Without a file name the recording goes to session-<timestamp>.rec in")
+		m.output = append(m.output, "  the current directory. Your own commands are not recorded.")
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Highlight.Render("Examples:"))
+		m.output = append(m.output, "  /record session")
+		m.output = append(m.output, "  /record session bug.rec")
+		m.output = append(m.output, "  /record stop")
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Debug.Render("See also: /help log"))
+
+	case "echo":
+		m.output = append(m.output, m.colors().Info.Render("=== /echo - Print a Local Message ==="))
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Highlight.Render("Usage:"))
+		m.output = append(m.output, "  /echo <text>")
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Highlight.Render("Description:"))
+		m.output = append(m.output, "  Prints text in the main window without sending anything to the MUD.")
+		m.output = append(m.output, "  Useful for status messages inside multi-command aliases and triggers.")
+		m.output = append(m.output, "  @name variables are replaced, and alias <placeholders> are filled in")
+		m.output = append(m.output, "  when the alias expands. The text is shown in the info color (see /theme).")
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Highlight.Render("Examples:"))
+		m.output = append(m.output, "  /echo Starting buff routine...")
+		m.output = append(m.output, "  /alias \"buff\" \"/echo Buffing...;cast 'armor';cast 'shield'\"")
+		m.output = append(m.output, "  /echo Current target: @target")
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Debug.Render("See also: /help alias, /help set"))
+
+	case "set", "unset":
+		m.output = append(m.output, m.colors().Info.Render("=== /set - Variables ==="))
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Highlight.Render("Usage:"))
+		m.output = append(m.output, "  /set                    - List all variables")
+		m.output = append(m.output, "  /set <name>             - Show a variable")
+		m.output = append(m.output, "  /set <name> <value>     - Set a variable")
+		m.output = append(m.output, "  /unset <name>           - Remove a variable")
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Highlight.Render("Description:"))
+		m.output = append(m.output, "  Variables hold values that commands can use by writing @name.")
+		m.output = append(m.output, "  @name is replaced in every command sent to the MUD, whether typed,")
+		m.output = append(m.output, "  expanded from an alias or run by a trigger. Unset variables are left as is.")
+		m.output = append(m.output, "  Trigger and alias actions can run /set, so a trigger can store a")
+		m.output = append(m.output, "  <capture> for later triggers to use.")
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Highlight.Render("Examples:"))
+		m.output = append(m.output, "  /set target orc")
+		m.output = append(m.output, "  kill @target                         - Sends 'kill orc'")
+		m.output = append(m.output, "  /trigger \"<who> attacks you\" \"/set target <who>\"")
+		m.output = append(m.output, "  /trigger \"You feel better\" \"kill @target\"")
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Debug.Render("Note: Variables are kept until the client exits"))
+		m.output = append(m.output, m.colors().Debug.Render("See also: /help trigger, /help alias"))
+
+	case "reload":
+		m.output = append(m.output, m.colors().Info.Render("=== /reload - Reload Files from Disk ==="))
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Highlight.Render("Usage:"))
+		m.output = append(m.output, "  /reload [triggers|aliases|map|all]")
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Highlight.Render("Description:"))
+		m.output = append(m.output, "  Re-reads triggers.json, aliases.json and/or this server's map from the")
+		m.output = append(m.output, "  config directory without disconnecting. Use it after editing the files")
+		m.output = append(m.output, "  by hand or syncing them from another machine. Defaults to all.")
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Highlight.Render("Examples:"))
+		m.output = append(m.output, "  /reload")
+		m.output = append(m.output, "  /reload triggers")
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Debug.Render("Note: If a file can't be read, the current settings are kept"))
+		m.output = append(m.output, m.colors().Debug.Render("See also: /help trigger, /help alias, /help map"))
+
+	case "connect", "sessions":
+		m.output = append(m.output, m.colors().Info.Render("=== /connect - Multiple Sessions ==="))
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Highlight.Render("Usage:"))
+		m.output = append(m.output, "  /connect <host> <port>")
+		m.output = append(m.output, "  /sessions")
+		m.output = append(m.output, "  /sessions <n>")
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Highlight.Render("Description:"))
+		m.output = append(m.output, "  Opens an additional connection and switches to it. Each session keeps")
+		m.output = append(m.output, "  its own output, input line, map, inventory, tells and XP panels, while")
+		m.output = append(m.output, "  triggers, aliases, macros and history are shared.")
+		m.output = append(m.output, "  Ctrl+Tab cycles through sessions. Sessions with unseen output are")
+		m.output = append(m.output, "  marked with * in the status bar.")
+		m.output = append(m.output, "  /sessions lists open sessions; /sessions <n> switches to session n.")
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Highlight.Render("Examples:"))
+		m.output = append(m.output, "  /connect aardmud.org 4000   - Open a second session")
+		m.output = append(m.output, "  /sessions 1                 - Switch back to the first session")
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Debug.Render("Note: Ctrl+Tab needs a terminal that reports it (xterm modifyOtherKeys"))
+		m.output = append(m.output, m.colors().Debug.Render("or CSI u); otherwise use /sessions <n>"))
+
+	case "reconnect", "reconnect-on":
+		m.output = append(m.output, m.colors().Info.Render("=== /reconnect-on - Reconnect After a Reboot ==="))
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Highlight.Render("Usage:"))
+		m.output = append(m.output, "  /reconnect-on \"<regex>\" <delay-seconds>")
+		m.output = append(m.output, "  /reconnect-on off")
+		m.output = append(m.output, "  /reconnect-on")
+		m.output = append(m.output, "  /reconnect")
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Highlight.Render("Description:"))
+		m.output = append(m.output, "  When a line from the MUD matches the pattern, a reconnect is scheduled")
+		m.output = append(m.output, "  for the given number of seconds later. The session stays open when the")
+		m.output = append(m.output, "  MUD closes the connection, then connects again and logs in with the")
+		m.output = append(m.output, "  saved account. If still connected when the time comes, nothing is done.")
+		m.output = append(m.output, "  /reconnect connects a closed session right away and cancels the")
+		m.output = append(m.output, "  scheduled reconnect. The pattern is saved per server with the map.")
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Highlight.Render("Examples:"))
+		m.output = append(m.output, "  /reconnect-on \"rebooting, please reconnect\" 60")
+		m.output = append(m.output, "  /reconnect-on \"^Shutting down\" 30")
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Debug.Render("See also: /connect, /sessions"))
+
+	case "version":
+		m.output = append(m.output, m.colors().Info.Render("=== /version - Client Version ==="))
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Highlight.Render("Usage:"))
+		m.output = append(m.output, "  /version")
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Highlight.Render("Description:"))
+		m.output = append(m.output, "  Shows the client's version, the commit and date it was built from, the Go")
+		m.output = append(m.output, "  version and the color profile in use. Please include this in bug reports.")
+		m.output = append(m.output, "  The same line is written at the top of --log-all logs, and")
+		m.output = append(m.output, "  dikuclient --version prints it without starting the client.")
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Debug.Render("See also: /help debug"))
+
+	case "debug":
+		m.output = append(m.output, m.colors().Info.Render("=== /debug - Diagnostics ==="))
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Highlight.Render("Usage:"))
+		m.output = append(m.output, "  /debug dump [file]")
+		m.output = append(m.output, "  /debug flush")
+		m.output = append(m.output, "  /debug level [negotiation|all]")
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Highlight.Render("Description:"))
+		m.output = append(m.output, "  /debug dump writes the client's state to a file to attach to a bug report: the")
+		m.output = append(m.output, "  version, current room and exits, pending movement, auto-walk path and")
+		m.output = append(m.output, "  command queue, the latest prompt, how many triggers, aliases and so on")
+		m.output = append(m.output, fmt.Sprintf("  are loaded, the room detection buffer and the last %d output lines.", debugDumpLines))
+		m.output = append(m.output, "  Your login name and password are replaced by [redacted]. The default")
+		m.output = append(m.output, "  file is dikuclient-debug-<date>-<time>.txt in the current directory.")
+		m.output = append(m.output, "")
+		m.output = append(m.output, "  With --log-all, the MUD output, TUI and telnet debug logs are buffered")
+		m.output = append(m.output, "  and written out every second. /debug flush writes them out now, for")
+		m.output = append(m.output, "  reading a log while the client runs. /debug level sets how much the")
+		m.output = append(m.output, "  telnet debug log records: negotiation (connection events and telnet")
+		m.output = append(m.output, "  option negotiation) or all (also every buffer read, in hex). The")
+		m.output = append(m.output, "  --debug-level flag sets it at startup; the default is all.")
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Highlight.Render("Examples:"))
+		m.output = append(m.output, "  /debug dump")
+		m.output = append(m.output, "  /debug dump /tmp/mapper-bug.txt")
+		m.output = append(m.output, "  /debug flush")
+		m.output = append(m.output, "  /debug level negotiation")
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Debug.Render("See also: /help version, /help record"))
+
+	case "help":
+		m.output = append(m.output, m.colors().Info.Render("=== /help - Show Help Information ==="))
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Highlight.Render("Usage:"))
+		m.output = append(m.output, "  /help [command]")
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Highlight.Render("Description:"))
+		m.output = append(m.output, "  Shows available client commands and keyboard shortcuts.")
+		m.output = append(m.output, "  When a command name is provided, shows detailed help for that command.")
+		m.output = append(m.output, "")
+		m.output = append(m.output, m.colors().Highlight.Render("Examples:"))
+		m.output = append(m.output, "  /help                  - Show all available commands")
+		m.output = append(m.output, "  /help point            - Show detailed help for /point")
+		m.output = append(m.output, "  /help trigger          - Show detailed help for triggers")
+
+	default:
+		m.output = append(m.output, m.colors().Error.Render(fmt.Sprintf("Unknown command: %s", cmd)))
+		m.output = append(m.output, "")
+		m.output = append(m.output, "Available commands for detailed help:")
+		m.output = append(m.output, "  point, wayfind, path, avoid, merge, dig, remember-exit, go, explore, stop, walkspeed,")
+		m.output = append(m.output, "  numpadwalk, map, rooms, nearby, trail, stats, legend, trigger, triggers, edit, notrig, learn,")
+		m.output = append(m.output, "  ticktrigger, ticktriggers, alias, aliases, group, sub, subs, capture, captures, macro, macros,")
+		m.output = append(m.output, "  hideprompt, promptnewline, promptpattern, collapse, links, focus, tab, split, ansi, theme,")
+		m.output = append(m.output, "  affects, whereis, mute, stat, remember, inventory, combat, target, wealth, levels, stopwatch,")
+		m.output = append(m.output, "  afk, autoloot, wimpy, autofollow, autoassist, retrycast, throttle, repeat, log, record, telnet,")
+		m.output = append(m.output, "  echo, set, unset, reload, share, connect, sessions, reconnect-on, debug, version, help")
+		m.output = append(m.output, "")
+		m.output = append(m.output, "Use /help to see all commands")
+	}
+}
+
+// handleRoomsCommand lists all known rooms or filters by search terms
+func (m *Model) handleRoomsCommand(args []string) {
+	var roomsToDisplay []*mapper.Room
+	var headerText string
+
+	if len(args) == 0 {
+		// No filter - show all rooms
+		allRooms := m.worldMap.GetAllRooms()
+
+		if len(allRooms) == 0 {
+			m.output = append(m.output, m.colors().Warn.Render("No rooms have been explored yet."))
+			return
+		}
+
+		roomsToDisplay = make([]*mapper.Room, 0, len(allRooms))
+		for _, room := range allRooms {
+			roomsToDisplay = append(roomsToDisplay, room)
+		}
+		headerText = m.colors().Info.Render(fmt.Sprintf("=== Known Rooms (%d) ===", len(roomsToDisplay)))
+	} else {
+		// Filter by search terms
+		query := strings.Join(args, " ")
+		roomsToDisplay = m.worldMap.FindRooms(query)
+
+		if len(roomsToDisplay) == 0 {
+			m.output = append(m.output, m.colors().Warn.Render(fmt.Sprintf("No rooms found matching '%s'", query)))
+			return
+		}
+
+		headerText = m.colors().Info.Render(fmt.Sprintf("=== Rooms matching '%s' (%d) ===", query, len(roomsToDisplay)))
+	}
+
+	m.output = append(m.output, headerText)
+
+	// Sort rooms by durable room number for consistent display
+	sort.Slice(roomsToDisplay, func(i, j int) bool {
+		numI := m.worldMap.GetRoomNumber(roomsToDisplay[i].ID)
+		numJ := m.worldMap.GetRoomNumber(roomsToDisplay[j].ID)
+		return numI < numJ
+	})
+
+	// Store results for later disambiguation
+	m.setRoomSearch(roomsToDisplay)
+
+	// Display rooms with durable numbers
+	for _, room := range roomsToDisplay {
+		exitList := make([]string, 0, len(room.Exits))
+		for dir := range room.Exits {
+			exitList = append(exitList, dir)
+		}
+		sort.Strings(exitList)
+
+		exitsStr := strings.Join(exitList, ", ")
+		if exitsStr == "" {
+			exitsStr = "none"
+		}
+
+		// Use durable room number
+		roomNum := m.worldMap.GetRoomNumber(room.ID)
+		avoidTag := ""
+		if room.Avoid {
+			avoidTag = " " + m.colors().Error.Render("(avoid)")
+		}
+		m.output = append(m.output, fmt.Sprintf("  %s %s%s", m.colors().Highlight.Render(fmt.Sprintf("%d. %s", roomNum, room.Title)), m.colors().Debug.Render(fmt.Sprintf("[%s]", exitsStr)), avoidTag))
+	}
+}
+
+// handleNearbyCommand lists all rooms within 5 steps of current location
+func (m *Model) handleNearbyCommand() {
+	currentRoom := m.worldMap.GetCurrentRoom()
+	if currentRoom == nil {
+		m.output = append(m.output, m.colors().Error.Render("No current room. You need to be in a mapped location."))
+		return
+	}
+
+	nearby := m.worldMap.FindNearbyRooms(5)
+
+	if len(nearby) == 0 {
+		m.output = append(m.output, m.colors().Warn.Render("No nearby rooms found within 5 steps."))
+		return
+	}
+
+	// Get visible room IDs on the map display
+	// Use typical sidebar dimensions for determining visibility
+	visibleRoomIDs := m.worldMap.GetVisibleRoomIDs(30, 15)
+	visibleSet := make(map[string]bool)
+	for _, id := range visibleRoomIDs {
+		visibleSet[id] = true
+	}
+
+	// Filter nearby rooms to only those visible on the map
+	var filteredNearby []mapper.NearbyRoom
+	for _, nr := range nearby {
+		if visibleSet[nr.Room.ID] {
+			filteredNearby = append(filteredNearby, nr)
+		}
+	}
+
+	if len(filteredNearby) == 0 {
+		m.output = append(m.output, m.colors().Warn.Render("No nearby rooms are currently visible on the map."))
+		return
+	}
+
+	m.output = append(m.output, m.colors().Info.Render(fmt.Sprintf("=== Nearby Rooms (%d visible on map) ===", len(filteredNearby))))
+
+	// Build room legend mapping for map display and store rooms for /go
+	m.mapLegend = make(map[string]int)
+	m.mapLegendRooms = make([]*mapper.Room, 0, len(filteredNearby))
+
+	currentDistance := -1
+	for i, nr := range filteredNearby {
+		// Show distance header when it changes
+		if nr.Distance != currentDistance {
+			currentDistance = nr.Distance
+			stepLabel := "step"
+			if currentDistance > 1 {
+				stepLabel = "steps"
+			}
+			m.output = append(m.output, m.colors().Warn.Render(fmt.Sprintf("%d %s away:", currentDistance, stepLabel)))
+		}
+
+		// Get exits for display
+		exitList := make([]string, 0, len(nr.Room.Exits))
+		for dir := range nr.Room.Exits {
+			exitList = append(exitList, dir)
+		}
+		sort.Strings(exitList)
+
+		exitsStr := strings.Join(exitList, ", ")
+		if exitsStr == "" {
+			exitsStr = "none"
+		}
+
+		m.output = append(m.output, fmt.Sprintf("  %s %s", m.colors().Highlight.Render(fmt.Sprintf("%d. %s", i+1, nr.Room.Title)), m.colors().Debug.Render(fmt.Sprintf("[%s]", exitsStr))))
+		m.tagRoomLine(nr.Room.ID)
+
+		// Add to legend mapping and store room
+		m.mapLegend[nr.Room.ID] = i + 1
+		m.mapLegendRooms = append(m.mapLegendRooms, nr.Room)
+	}
+}
+
+// handleLegendCommand lists all rooms currently on the map using durable
+// room numbers, or clears the numbers from the map
+func (m *Model) handleLegendCommand(args []string) {
+	if len(args) > 0 && strings.ToLower(args[0]) == "clear" {
+		m.mapLegend = nil
+		m.mapLegendRooms = nil
+		m.output = append(m.output, m.colors().Info.Render("Map legend cleared."))
+		return
+	}
+
+	allRooms := m.worldMap.GetAllRooms()
+
+	if len(allRooms) == 0 {
+		m.output = append(m.output, m.colors().Warn.Render("No rooms have been explored yet."))
+		return
+	}
+
+	// Get visible room IDs on the map display
+	// Use typical sidebar dimensions for determining visibility
+	visibleRoomIDs := m.worldMap.GetVisibleRoomIDs(30, 15)
+	visibleSet := make(map[string]bool)
+	for _, id := range visibleRoomIDs {
+		visibleSet[id] = true
+	}
+
+	// Build list of visible rooms with their durable numbers
+	type roomWithNumber struct {
+		room   *mapper.Room
+		number int
+	}
+	var visibleRooms []roomWithNumber
+
+	// Iterate through room numbering to maintain order
+	for _, roomID := range m.worldMap.RoomNumbering {
+		if visibleSet[roomID] {
+			if room := m.worldMap.Rooms[roomID]; room != nil {
+				number := m.worldMap.GetRoomNumber(roomID)
+				visibleRooms = append(visibleRooms, roomWithNumber{room: room, number: number})
+			}
+		}
+	}
+
+	if len(visibleRooms) == 0 {
+		m.output = append(m.output, m.colors().Warn.Render("No rooms are currently visible on the map."))
+		return
+	}
+
+	m.output = append(m.output, m.colors().Info.Render(fmt.Sprintf("=== Rooms on Map (%d visible) ===", len(visibleRooms))))
+
+	// Build room legend mapping for map display using durable numbers
+	m.mapLegend = make(map[string]int)
+	m.mapLegendRooms = make([]*mapper.Room, 0)
+
+	// Track mapping from display position to room for /go command
+	displayPosToRoom := make(map[int]*mapper.Room)
+
+	for i, rn := range visibleRooms {
+		// Get exits for display
+		exitList := make([]string, 0, len(rn.room.Exits))
+		for dir := range rn.room.Exits {
+			exitList = append(exitList, dir)
+		}
+		sort.Strings(exitList)
+
+		exitsStr := strings.Join(exitList, ", ")
+		if exitsStr == "" {
+			exitsStr = "none"
+		}
+
+		// Display with durable room number
+		m.output = append(m.output, fmt.Sprintf("  %s %s", m.colors().Highlight.Render(fmt.Sprintf("%d. %s", rn.number, rn.room.Title)), m.colors().Debug.Render(fmt.Sprintf("[%s]", exitsStr))))
+		m.tagRoomLine(rn.room.ID)
+
+		// Use durable number for legend mapping
+		m.mapLegend[rn.room.ID] = rn.number
+
+		// Map display position (1-indexed) to room for /go command
+		displayPosToRoom[i+1] = rn.room
+	}
+
+	// Store rooms in display order for /go to use
+	for i := 1; i <= len(displayPosToRoom); i++ {
+		if room, ok := displayPosToRoom[i]; ok {
+			m.mapLegendRooms = append(m.mapLegendRooms, room)
+		}
+	}
+}
+
+// legendRoom returns the room shown with a number in the map legend, or nil
+func (m *Model) legendRoom(number int) *mapper.Room {
+	for _, room := range m.mapLegendRooms {
+		if m.mapLegend[room.ID] == number {
+			return room
+		}
+	}
+	return nil
+}
+
+// setRoomSearch keeps a numbered list of rooms for /go <n>. Its numbers
+// replace the legend's, which is taken off the map.
+func (m *Model) setRoomSearch(rooms []*mapper.Room) {
+	m.lastRoomSearch = rooms
+	m.mapLegend = nil
+	m.mapLegendRooms = nil
+}
+
+// enterRoom adds the room just entered to the map, the trail and the
+// odometer, which counts it as new only if it wasn't on the map already
+func (m *Model) enterRoom(room *mapper.Room) {
+	previousID := m.worldMap.CurrentRoomID
+	_, known := m.worldMap.Rooms[room.ID]
+	m.worldMap.AddOrUpdateRoom(room)
+	m.recordTrail(m.worldMap.LastDirection)
+	if room.ID != previousID {
+		m.odometer.Enter(!known)
+	}
+}
+
+// recordTrail adds the room just entered to the trail, with the direction
+// taken into it ("" when unknown)
+func (m *Model) recordTrail(direction string) {
+	if m.trail == nil {
+		m.trail = mapper.NewTrail(mapper.DefaultTrailLength)
+	}
+	m.trail.Record(m.worldMap.CurrentRoomID, direction, time.Now())
+}
+
+// movementFor returns the exit a command takes from the current room: a
+// direction, or a special exit remembered with /remember-exit. It returns ""
+// for commands that don't move.
+func (m *Model) movementFor(command string) string {
+	if movement := mapper.DetectMovement(command); movement != "" {
+		return movement
+	}
+	if m.worldMap != nil {
+		if room := m.worldMap.GetCurrentRoom(); room != nil {
+			return room.SpecialExit(command)
+		}
+	}
+	return ""
+}
+
+// handleRememberExitCommand sends a command such as "enter portal" and
+// records the room it leads to as an exit of the current room, so paths
+// can use it. Without a command it lists the current room's special exits.
+func (m *Model) handleRememberExitCommand(command string) {
+	exitCommand := strings.TrimSpace(strings.TrimPrefix(strings.TrimPrefix(command, "/"), "remember-exit"))
+	if len(exitCommand) >= 2 && strings.HasPrefix(exitCommand, "\"") && strings.HasSuffix(exitCommand, "\"") {
+		exitCommand = strings.TrimSpace(exitCommand[1 : len(exitCommand)-1])
+	}
+	exitCommand = strings.Join(strings.Fields(exitCommand), " ")
+
+	if m.worldMap == nil || m.worldMap.GetCurrentRoom() == nil {
+		m.output = append(m.output, m.colors().Error.Render("Current room unknown. Move around first so the mapper knows where you are."))
+		return
+	}
+	room := m.worldMap.GetCurrentRoom()
+
+	if exitCommand == "" {
+		exits := room.SpecialExits()
+		if len(exits) == 0 {
+			m.output = append(m.output, m.colors().Info.Render("No special exits in this room."))
+			m.output = append(m.output, m.colors().Debug.Render("Usage: /remember-exit \"<command>\""))
+			return
+		}
+		m.output = append(m.output, m.colors().Info.Render(fmt.Sprintf("Special exits from '%s':", room.Title)))
+		for _, exit := range exits {
+			destination := "unknown"
+			if dest := m.worldMap.Rooms[room.Exits[exit]]; dest != nil {
+				destination = dest.Title
+			}
+			m.output = append(m.output, fmt.Sprintf("  %s -> %s", m.colors().Highlight.Render(exit), destination))
+		}
+		return
+	}
+
+	if mapper.DetectMovement(exitCommand) != "" {
+		m.output = append(m.output, m.colors().Error.Render(fmt.Sprintf("'%s' is a direction; directions are mapped as you walk.", exitCommand)))
+		return
+	}
+	if m.conn == nil || !m.connected {
+		m.output = append(m.output, m.colors().Error.Render("Not connected"))
+		return
+	}
+
+	m.pendingMovement = exitCommand
+	m.odometer.Move()
+	m.mapLegend = nil
+	m.mapLegendRooms = nil
+	m.conn.Send(exitCommand)
+	m.output = append(m.output, m.colors().Info.Render(fmt.Sprintf("Sent '%s'; the room it leads to will be remembered as an exit of '%s'.", exitCommand, room.Title)))
+}
+
+// handleStatsCommand shows how far you have traveled this session and how
+// much of the map is left to explore
+func (m *Model) handleStatsCommand(args []string) {
+	if len(args) > 0 {
+		if len(args) != 1 || !strings.EqualFold(args[0], "reset") {
+			m.output = append(m.output, m.colors().Error.Render("Usage: /stats [reset]"))
+			return
+		}
+		m.odometer = mapper.Odometer{}
+		m.output = append(m.output, m.colors().Info.Render("Travel stats reset."))
+		return
+	}
+
+	odometer := m.odometer
+	m.output = append(m.output, m.colors().Info.Render("=== Travel This Session ==="))
+	m.output = append(m.output, fmt.Sprintf("  Moves: %s   Rooms entered: %s   New rooms: %s",
+		m.colors().Highlight.Render(fmt.Sprintf("%d", odometer.Moves)),
+		m.colors().Highlight.Render(fmt.Sprintf("%d", odometer.Rooms)),
+		m.colors().Highlight.Render(fmt.Sprintf("%d", odometer.NewRooms))))
+	if newRooms, moves := odometer.RecentRate(); moves > 0 {
+		m.output = append(m.output, fmt.Sprintf("  Exploration rate: %d new rooms in the last %d moves (%d%%)", newRooms, moves, newRooms*100/moves))
+	}
+
+	if m.worldMap != nil && len(m.worldMap.Rooms) > 0 {
+		explored, total := m.worldMap.ExploredExits()
+		line := fmt.Sprintf("  Map: %d rooms", len(m.worldMap.Rooms))
+		if total > 0 {
+			line += fmt.Sprintf(", %d%% of exits explored (%d left, see /explore)", explored*100/total, total-explored)
+		}
+		m.output = append(m.output, line)
+	}
+}
+
+// handleTrailCommand lists the rooms visited most recently, walks back
+// along them, or shows them in a sidebar panel
+func (m *Model) handleTrailCommand(args []string) tea.Cmd {
+	if len(args) == 0 {
+		m.showTrail()
+		return nil
+	}
+
+	switch strings.ToLower(args[0]) {
+	case "back":
+		steps := 0
+		if len(args) > 1 {
+			if _, err := fmt.Sscanf(args[1], "%d", &steps); err != nil || steps <= 0 {
+				m.output = append(m.output, m.colors().Error.Render("Usage: /trail back [<steps>]"))
+				return nil
+			}
+		}
+		return m.walkBackTrail(steps)
+	case "clear":
+		m.trail = nil
+		m.output = append(m.output, m.colors().Info.Render("Trail cleared."))
+	case "panel":
+		if len(args) < 2 || (strings.ToLower(args[1]) != "on" && strings.ToLower(args[1]) != "off") {
+			m.output = append(m.output, m.colors().Error.Render("Usage: /trail panel on|off"))
+			return nil
+		}
+		if m.settingsManager == nil {
+			m.settingsManager = settings.NewManager()
+		}
+		m.settingsManager.TrailPanel = strings.ToLower(args[1]) == "on"
+		if m.settingsManager.TrailPanel {
+			m.output = append(m.output, m.colors().Info.Render("Trail panel on. The last rooms visited show above the map."))
+		} else {
+			m.output = append(m.output, m.colors().Info.Render("Trail panel off."))
+		}
+		if err := m.settingsManager.Save(); err != nil {
+			m.output = append(m.output, m.colors().Error.Render(fmt.Sprintf("Error saving settings: %v", err)))
+		}
+	default:
+		m.output = append(m.output, m.colors().Error.Render("Usage: /trail [back [<steps>]|clear|panel on|off]"))
+	}
+	return nil
+}
+
+// showTrail lists the rooms on the trail, oldest first. Each line can be
+// clicked to walk to that room.
+func (m *Model) showTrail() {
+	if m.trail == nil || len(m.trail.Steps) == 0 {
+		m.output = append(m.output, m.colors().Warn.Render("The trail is empty. Rooms are added to it as you walk."))
+		return
+	}
+
+	m.output = append(m.output, m.colors().Info.Render(fmt.Sprintf("=== Trail (last %d rooms) ===", len(m.trail.Steps))))
+	for i, step := range m.trail.Steps {
+		here := ""
+		if i == len(m.trail.Steps)-1 {
+			here = " " + m.colors().Debug.Render("(here)")
+		}
+		number := m.worldMap.GetRoomNumber(step.RoomID)
+		m.output = append(m.output, "  "+m.colors().Highlight.Render(fmt.Sprintf("%s  #%d %s%s", step.Time.Format("15:04:05"), number, m.formatTrailStep(step), here)))
+		m.tagRoomLine(step.RoomID)
+	}
+	m.output = append(m.output, m.colors().Debug.Render("The direction is the one taken into each room (? = unknown). /trail back retraces it."))
+}
+
+// walkBackTrail auto-walks back along the last steps of the trail (all of
+// it when steps is 0)
+func (m *Model) walkBackTrail(steps int) tea.Cmd {
+	if m.trail == nil {
+		m.output = append(m.output, m.colors().Error.Render("The trail is empty. Rooms are added to it as you walk."))
+		return nil
+	}
+	if m.autoWalking || m.commandQueueActive || len(m.pendingCommands) > 0 {
+		m.output = append(m.output, m.colors().Error.Render("Already walking. Type /stop first."))
+		return nil
+	}
+
+	path, target, err := m.trail.Back(m.worldMap, steps)
+	if err != nil {
+		m.output = append(m.output, m.colors().Error.Render(fmt.Sprintf("Can't go back: %v", err)))
+		return nil
+	}
+	return m.startAutoWalk(target, path, 0)
+}
+
+// tagRoomLine marks the last output line as listing a room, so clicking it
+// walks there
+func (m *Model) tagRoomLine(roomID string) {
+	if m.roomLines == nil {
+		m.roomLines = make(map[int]string)
+	}
+	m.roomLines[len(m.output)-1] = roomID
+}
+
+// viewportTop returns the screen row of the main viewport's first line:
+// below the status bar and the top border, and below the room description
+// when it is split off (its border, 6 lines and the separator)
+func (m *Model) viewportTop() int {
+	if m.hasDescriptionSplit {
+		return 1 + 1 + 6 + 1
+	}
+	return 1 + 1
+}
+
+// wrapToViewport wraps text to the width of the main viewport, breaking
+// long lines at spaces where it can
+func (m *Model) wrapToViewport(s string) string {
+	return wrapToWidth(s, m.viewport.Width)
+}
+
+// wrapToWidth wraps text to a width, leaving it as it is without one
+func wrapToWidth(s string, width int) string {
+	if width <= 0 {
+		return s
+	}
+	return xansi.Wrap(s, width, "")
+}
+
+// wrapCache keeps the output lines wrapped for the last redraw. The output
+// only grows at its end, so a redraw wraps just the lines added or changed
+// since, and a new width or /ansi setting wraps them all again.
+type wrapCache struct {
+	width   int
+	plain   bool
+	lines   []string // The lines as given, to tell which changed
+	wrapped []string
+	heights []int // How many screen lines each wrapped line takes
+}
+
+// wrap returns the lines wrapped to width, stripped of colors when plain
+func (c *wrapCache) wrap(lines []string, width int, plain bool) []string {
+	if width != c.width || plain != c.plain {
+		*c = wrapCache{width: width, plain: plain}
+	}
+	for i, line := range lines {
+		if i < len(c.lines) && c.lines[i] == line {
+			continue
+		}
+		shown := line
+		if plain {
+			shown = ansi.Strip(shown)
+		}
+		shown = wrapToWidth(shown, width)
+		height := strings.Count(shown, "\n") + 1
+		if i < len(c.lines) {
+			c.lines[i], c.wrapped[i], c.heights[i] = line, shown, height
+		} else {
+			c.lines = append(c.lines, line)
+			c.wrapped = append(c.wrapped, shown)
+			c.heights = append(c.heights, height)
+		}
+	}
+	c.lines = c.lines[:len(lines)]
+	c.wrapped = c.wrapped[:len(lines)]
+	c.heights = c.heights[:len(lines)]
+	return c.wrapped
+}
+
+// reset drops the wrapped lines, for when the viewport is resized
+func (c *wrapCache) reset() {
+	*c = wrapCache{}
+}
+
+// roomAtPosition returns the room listed on the output line shown at a
+// screen position, or nil if there is no room listing there
+func (m *Model) roomAtPosition(x, y int) *mapper.Room {
+	mainWidth := m.width - m.sidebarWidth - 1
+	row := y - m.viewportTop()
+	if x < 1 || x > mainWidth || row < 0 || row >= m.viewport.Height {
+		return nil
+	}
+
+	// Output entries can hold several lines, and long ones are wrapped, so
+	// walk the line counts of the last redraw to find the entry shown on
+	// the clicked content line
+	contentLine := m.viewport.YOffset + row
+	if contentLine >= m.viewport.TotalLineCount() {
+		return nil
+	}
+	// Below the wrapped lines is the last entry, drawn with the input line
+	entry := len(m.wrapped.heights)
+	line := 0
+	for i, height := range m.wrapped.heights {
+		line += height
+		if contentLine < line {
+			entry = i
+			break
+		}
+	}
+	roomID, ok := m.roomLines[entry]
+	if !ok || m.worldMap == nil {
+		return nil
+	}
+	return m.worldMap.Rooms[roomID]
+}
+
+// cellAtPosition returns the content position shown at a screen position in
+// the main viewport. Positions outside it are moved to its nearest edge, so
+// a selection can be dragged past the border.
+func (m *Model) cellAtPosition(x, y int) cellPos {
+	mainWidth := m.width - m.sidebarWidth - 1
+	row := min(max(y-m.viewportTop(), 0), max(m.viewport.Height-1, 0))
+	col := min(max(x-1, 0), max(mainWidth-1, 0))
+	return cellPos{line: m.viewport.YOffset + row, col: col}
+}
+
+// handleCopyModeMouse selects output text by dragging with the left button
+func (m *Model) handleCopyModeMouse(msg tea.MouseMsg) {
+	pos := m.cellAtPosition(msg.X, msg.Y)
+	switch msg.Action {
+	case tea.MouseActionPress:
+		m.selecting = true
+		m.hasSelection = true
+		m.selectionStart = pos
+		m.selectionEnd = pos
+	case tea.MouseActionMotion:
+		if m.selecting {
+			m.selectionEnd = pos
+		}
+	case tea.MouseActionRelease:
+		if m.selecting {
+			m.selectionEnd = pos
+			m.selecting = false
+		}
+	}
+}
+
+// handleCopyModeKey copies the selection with y, scrolls with the arrow and
+// page keys, and leaves copy mode with Esc or Ctrl+Space
+func (m *Model) handleCopyModeKey(msg tea.KeyMsg) {
+	switch msg.Type {
+	case tea.KeyEsc, tea.KeyCtrlAt, tea.KeyCtrlC:
+		m.leaveCopyMode()
+	case tea.KeyPgUp:
+		m.viewport.PageUp()
+	case tea.KeyPgDown:
+		m.viewport.PageDown()
+	case tea.KeyUp:
+		m.viewport.ScrollUp(1)
+	case tea.KeyDown:
+		m.viewport.ScrollDown(1)
+	case tea.KeyRunes:
+		if !strings.EqualFold(string(msg.Runes), "y") {
+			return
+		}
+		text := ""
+		if m.hasSelection {
+			text = selectedText(strings.Split(ansi.Strip(m.lastViewportContent), "\n"), m.selectionStart, m.selectionEnd)
+		}
+		m.leaveCopyMode()
+		if text == "" {
+			m.output = append(m.output, m.colors().Warn.Render("Nothing selected to copy."))
+		} else {
+			m.copyToClipboard(text)
+			m.output = append(m.output, m.colors().Info.Render(fmt.Sprintf("Copied %d characters.", len([]rune(text)))))
+		}
+		m.updateViewport()
+	}
+}
+
+// leaveCopyMode drops the selection and follows the output again
+func (m *Model) leaveCopyMode() {
+	m.copyMode = false
+	m.selecting = false
+	m.hasSelection = false
+	m.updateViewport()
+	if !m.isSplit {
+		m.viewport.GotoBottom()
+	}
+}
+
+// copyToClipboard sends text to the terminal's clipboard with an OSC 52
+// escape sequence, which works over SSH and in most terminal emulators
+func (m *Model) copyToClipboard(text string) {
+	w := m.clipboard
+	if w == nil {
+		w = os.Stdout
+	}
+	fmt.Fprintf(w, "\x1b]52;c;%s\a", base64.StdEncoding.EncodeToString([]byte(text)))
+}
+
+// bellInterval is the least time between bells, so a trigger matching a
+// burst of lines rings once rather than sounding an alarm
+const bellInterval = 3 * time.Second
+
+// ringBell rings the terminal bell, or in web mode asks the browser to play
+// an alert sound, unless the bell rang less than bellInterval ago. Reports
+// whether it rang.
+func (m *Model) ringBell(now time.Time) bool {
+	if !m.lastBell.IsZero() && now.Sub(m.lastBell) < bellInterval {
+		return false
+	}
+	m.lastBell = now
+
+	if m.webSessionID != "" {
+		writeWebClientHint(map[string]string{"type": "bell"})
+		return true
+	}
+	w := m.bell
+	if w == nil {
+		w = os.Stdout
+	}
+	fmt.Fprint(w, "\a")
+	return true
+}
+
+// orderedSelection returns the ends of a selection in reading order, since
+// it can be dragged backwards
+func orderedSelection(a, b cellPos) (cellPos, cellPos) {
+	if b.line < a.line || b.line == a.line && b.col < a.col {
+		return b, a
+	}
+	return a, b
+}
+
+// selectionColumns returns the columns [from, to) selected on a line of the
+// given length. Lines between the first and last are selected in full.
+func selectionColumns(start, end cellPos, line, length int) (int, int) {
+	from, to := 0, length
+	if line == start.line {
+		from = start.col
+	}
+	if line == end.line {
+		to = end.col + 1
+	}
+	from = min(from, length)
+	to = max(min(to, length), from)
+	return from, to
+}
+
+// selectedText returns the text between two positions (in either order) of
+// the content lines, without colors, like a terminal's own selection:
+// trailing spaces are dropped and lines are joined with newlines
+func selectedText(lines []string, a, b cellPos) string {
+	start, end := orderedSelection(a, b)
+	var selected []string
+	for line := max(start.line, 0); line <= end.line && line < len(lines); line++ {
+		runes := []rune(ansi.Strip(lines[line]))
+		from, to := selectionColumns(start, end, line, len(runes))
+		selected = append(selected, strings.TrimRight(string(runes[from:to]), " "))
+	}
+	return strings.Join(selected, "\n")
+}
+
+// highlightSelection shows the selected part of content in reverse video.
+// Selected lines lose their colors, which keeps the columns exact.
+func highlightSelection(content string, a, b cellPos) string {
+	start, end := orderedSelection(a, b)
+	lines := strings.Split(content, "\n")
+	for line := max(start.line, 0); line <= end.line && line < len(lines); line++ {
+		runes := []rune(ansi.Strip(lines[line]))
+		from, to := selectionColumns(start, end, line, len(runes))
+		lines[line] = string(runes[:from]) + "\x1b[7m" + string(runes[from:to]) + "\x1b[0m" + string(runes[to:])
+	}
+	return strings.Join(lines, "\n")
+}
+
+// handleRoomClick walks to a room clicked in a /nearby or /legend listing,
+// replacing any walk in progress
+func (m *Model) handleRoomClick(room *mapper.Room) tea.Cmd {
+	if m.autoWalking || m.commandQueueActive || len(m.pendingCommands) > 0 {
+		m.stopCommandQueue()
+	}
+	fastWalk := m.settingsManager != nil && m.settingsManager.FastWalk
+	return m.walkTo(room, fastWalk, 0)
+}
+
+// handleGoCommand starts auto-walking to a destination
+func (m *Model) handleGoCommand(args []string) tea.Cmd {
+	// Parse leading options: -speed <ms> and -fast
+	var speedOverride time.Duration
+	fastWalk := m.settingsManager != nil && m.settingsManager.FastWalk
+	for len(args) > 0 && strings.HasPrefix(args[0], "-") {
+		switch strings.ToLower(args[0]) {
+		case "-speed":
+			if len(args) < 2 {
+				m.output = append(m.output, m.colors().Error.Render("Usage: /go -speed <ms> <room search terms>"))
+				return nil
+			}
+			var ms int
+			if _, err := fmt.Sscanf(args[1], "%d", &ms); err != nil || ms <= 0 {
+				m.output = append(m.output, m.colors().Error.Render(fmt.Sprintf("Error: Invalid speed '%s' (milliseconds expected)", args[1])))
+				return nil
+			}
+			if ms < settings.MinWalkDelayMs {
+				m.output = append(m.output, m.colors().Warn.Render(fmt.Sprintf("Walk delay raised to the minimum of %dms.", settings.MinWalkDelayMs)))
+			}
+			speedOverride = time.Duration(settings.ClampWalkDelayMs(ms)) * time.Millisecond
+			fastWalk = false
+			args = args[2:]
+		case "-fast":
+			fastWalk = true
+			args = args[1:]
+		default:
+			m.output = append(m.output, m.colors().Error.Render(fmt.Sprintf("Error: Unknown option '%s'", args[0])))
+			m.output = append(m.output, m.colors().Warn.Render("Usage: /go [-speed <ms>|-fast] <room search terms>"))
+			return nil
+		}
+	}
+
+	// If no args provided
+	if len(args) == 0 {
+		// If auto-walking or queue is active, stop it
+		if m.autoWalking || m.commandQueueActive || len(m.pendingCommands) > 0 {
+			m.stopCommandQueue()
+			m.output = append(m.output, m.colors().Warn.Render("Auto-walk cancelled."))
+			return nil
+		}
+		// Otherwise show usage
+		m.output = append(m.output, m.colors().Error.Render("Usage: /go <room search terms> or /go <number> [search terms]"))
+		return nil
+	}
+
+	// If already auto-walking or queue is active, stop it first
+	if m.autoWalking || m.commandQueueActive || len(m.pendingCommands) > 0 {
+		m.stopCommandQueue()
+		m.output = append(m.output, m.colors().Warn.Render("Auto-walk cancelled. Start a new /go to navigate."))
+		return nil
+	}
+
+	var rooms []*mapper.Room
+	var query string
+
+	// Check if first argument is a number for room selection
+	if roomNum, err := fmt.Sscanf(args[0], "%d", new(int)); err == nil && roomNum == 1 {
+		var index int
+		fmt.Sscanf(args[0], "%d", &index)
+
+		// If only a number is provided, try different sources
+		if len(args) == 1 {
+			// Try the numbers drawn on the map first (from /nearby or /legend)
+			if len(m.mapLegendRooms) > 0 {
+				room := m.legendRoom(index)
+				if room == nil {
+					m.output = append(m.output, m.colors().Error.Render(fmt.Sprintf("Invalid room number. Room %d is not in the map legend (/legend clear to forget it).", index)))
+					return nil
+				}
+				rooms = []*mapper.Room{room}
+			} else if len(m.lastRoomSearch) > 0 {
+				// Use lastRoomSearch from /wayfind, /go, /point disambiguation lists
+				// Treat the number as a simple list index
+				if index < 1 || index > len(m.lastRoomSearch) {
+					m.output = append(m.output, m.colors().Error.Render(fmt.Sprintf("Invalid room number. Must be between 1 and %d.", len(m.lastRoomSearch))))
+					return nil
+				}
+				rooms = []*mapper.Room{m.lastRoomSearch[index-1]}
+			} else {
+				// Try durable room number lookup as last resort
+				room := m.worldMap.GetRoomByNumber(index)
+				if room != nil {
+					rooms = []*mapper.Room{room}
+				} else {
+					m.output = append(m.output, m.colors().Error.Render("No previous room search to select from. Use /rooms, /nearby, or /legend to see room listings."))
+					return nil
+				}
+			}
+		} else {
+			// Number followed by search terms - search first, then select by index
+			query = strings.Join(args[1:], " ")
+			allMatches := m.searchRooms(query)
+
+			if len(allMatches) == 0 {
+				m.output = append(m.output, m.colors().Error.Render(fmt.Sprintf("No rooms found matching '%s'", query)))
+				return nil
+			}
+
+			if index < 1 || index > len(allMatches) {
+				m.output = append(m.output, m.colors().Error.Render(fmt.Sprintf("Invalid room number. Found %d rooms matching '%s'. Must be between 1 and %d.", len(allMatches), query, len(allMatches))))
+				return nil
+			}
+
+			rooms = []*mapper.Room{allMatches[index-1]}
+			m.setRoomSearch(allMatches)
+		}
+	} else {
+		// Regular search without numeric selection
+		query = strings.Join(args, " ")
+		rooms = m.searchRooms(query)
+	}
+
+	if len(rooms) == 0 {
+		m.output = append(m.output, m.colors().Error.Render(fmt.Sprintf("No rooms found matching '%s'", query)))
+		return nil
+	}
+
+	if len(rooms) > 1 {
+		// Store results for later disambiguation
+		m.setRoomSearch(rooms)
+
+		m.output = append(m.output, m.colors().Warn.Render(fmt.Sprintf("Found %d rooms matching '%s':", len(rooms), query)))
+		for i, room := range rooms {
+			if i >= 5 {
+				m.output = append(m.output, "  "+m.colors().Debug.Render(fmt.Sprintf("... and %d more", len(rooms)-5)))
+				break
+			}
+			m.output = append(m.output, "  "+m.colors().Highlight.Render(fmt.Sprintf("%d. %s", i+1, room.Title)))
+		}
+		m.output = append(m.output, m.colors().Warn.Render("Please be more specific, or use /go <number> to select a room."))
+		return nil
+	}
+
+	return m.walkTo(rooms[0], fastWalk, speedOverride)
+}
+
+// handleExploreCommand auto-walks to the room with the nearest unexplored
+// exit and stops there, so the next step goes somewhere new
+func (m *Model) handleExploreCommand() tea.Cmd {
+	if m.worldMap.GetCurrentRoom() == nil {
+		m.output = append(m.output, m.colors().Error.Render("Current room unknown. Move around first so the mapper knows where you are."))
+		return nil
+	}
+
+	// Like /go, exploring while walking stops the walk instead
+	if m.autoWalking || m.commandQueueActive || len(m.pendingCommands) > 0 {
+		m.stopCommandQueue()
+		m.output = append(m.output, m.colors().Warn.Render("Auto-walk cancelled. Type /explore again to explore from here."))
+		return nil
+	}
+
+	exit, path, ok := m.worldMap.NearestUnexploredExit(m.worldMap.CurrentRoomID)
+	if !ok {
+		m.output = append(m.output, m.colors().Info.Render("Map fully explored from here: every exit you can reach has been taken."))
+		return nil
+	}
+	if len(path) == 0 {
+		m.output = append(m.output, m.colors().Info.Render(fmt.Sprintf("Unexplored exit here: go %s to explore.", exit.Direction)))
+		return nil
+	}
+
+	m.output = append(m.output, m.colors().Info.Render(fmt.Sprintf("Nearest unexplored exit: %s from '%s', %d steps away.", exit.Direction, exit.Room.Title, len(path))))
+	fastWalk := m.settingsManager != nil && m.settingsManager.FastWalk
+	return m.walkTo(exit.Room, fastWalk, 0)
+}
+
+// walkTo starts auto-walking (or fast-walking) to a room
+func (m *Model) walkTo(targetRoom *mapper.Room, fastWalk bool, speedOverride time.Duration) tea.Cmd {
+	// Find path to the room
+	path := m.worldMap.FindPath(targetRoom.ID)
+
+	if path == nil {
+		m.output = append(m.output, m.colors().Error.Render(fmt.Sprintf("No path found to '%s'", targetRoom.Title)))
+		return nil
+	}
+
+	if len(path) == 0 {
+		m.output = append(m.output, m.colors().Info.Render("You are already at that location!"))
+		return nil
+	}
+
+	m.warnAvoidedRooms(m.worldMap.AvoidedRoomsOnPath(m.worldMap.CurrentRoomID, path))
+
+	if fastWalk {
+		return m.fastWalk(targetRoom, path)
+	}
+
+	return m.startAutoWalk(targetRoom, path, speedOverride)
+}
+
+// startAutoWalk queues a path to a room one step at a time
+func (m *Model) startAutoWalk(targetRoom *mapper.Room, path []string, speedOverride time.Duration) tea.Cmd {
+	m.autoWalking = true // Keep this for compatibility with failure detection
+	m.autoWalkPath = path
+	m.autoWalkIndex = 0
+	m.autoWalkTarget = targetRoom.Title // Store target for recovery
+	m.walkDelayOverride = speedOverride
+	m.output = append(m.output, m.colors().Info.Render(fmt.Sprintf("Auto-walking to '%s' (%d steps, %dms per step). Type /stop to cancel.", targetRoom.Title, len(path), m.walkInterval().Milliseconds())))
+
+	// Enqueue all the movement commands
+	return m.enqueueCommands(path)
+}
+
+// fastWalk sends the entire path at once for MUDs that queue movement server-side
+func (m *Model) fastWalk(targetRoom *mapper.Room, path []string) tea.Cmd {
+	m.output = append(m.output, m.colors().Info.Render(fmt.Sprintf("Fast-walking to '%s' (%d steps sent at once).", targetRoom.Title, len(path))))
+	m.output = append(m.output, m.colors().Warn.Render("Warning: fast walk may fail on MUDs that limit movement per command."))
+
+	// Room output will arrive faster than we can attribute it to individual
+	// steps, so don't link rooms during the walk and assume we arrived
+	m.pendingMovement = ""
+	m.worldMap.SetLastDirection("")
+	m.worldMap.PreviousRoomID = m.worldMap.CurrentRoomID
+	m.worldMap.CurrentRoomID = targetRoom.ID
+
+	m.fastWalkQueue = path
+	return m.sendFastWalkBatch()
+}
+
+// fastWalkBatch is how many fast-walk steps are sent per tick, well under
+// the connection's 100-command buffer, so a long path doesn't hold up the UI
+const fastWalkBatch = 50
+
+// fastWalkBatchDelay is the time between batches of a fast walk
+const fastWalkBatchDelay = 10 * time.Millisecond
+
+// sendFastWalkBatch sends the next batch of fast-walk steps and schedules
+// the one after, if any
+func (m *Model) sendFastWalkBatch() tea.Cmd {
+	n := min(fastWalkBatch, len(m.fastWalkQueue))
+	if m.conn != nil && m.connected {
+		for _, direction := range m.fastWalkQueue[:n] {
+			m.conn.Send(direction)
+		}
+	}
+	m.fastWalkQueue = m.fastWalkQueue[n:]
+	if len(m.fastWalkQueue) == 0 {
+		m.fastWalkQueue = nil
+		return nil
+	}
+	return tea.Tick(fastWalkBatchDelay, func(t time.Time) tea.Msg {
+		return fastWalkTickMsg{}
+	})
+}
+
+// handleWalkSpeedCommand shows or sets the persistent auto-walk speed
+func (m *Model) handleWalkSpeedCommand(args []string) {
+	if m.settingsManager == nil {
+		m.settingsManager = settings.NewManager()
+	}
+
+	if len(args) == 0 {
+		if m.settingsManager.FastWalk {
+			m.output = append(m.output, m.colors().Info.Render("Walk speed: fast (whole path sent at once)"))
+		} else {
+			m.output = append(m.output, m.colors().Info.Render(fmt.Sprintf("Walk speed: %dms per step", m.settingsManager.GetWalkDelayMs())))
+		}
+		return
+	}
+
+	if strings.ToLower(args[0]) == "fast" {
+		m.settingsManager.FastWalk = true
+		m.output = append(m.output, m.colors().Info.Render("Walk speed set to fast (whole path sent at once)."))
+		m.output = append(m.output, m.colors().Warn.Render("Warning: fast walk may fail on MUDs that limit movement per command."))
+	} else {
+		var ms int
+		if _, err := fmt.Sscanf(args[0], "%d", &ms); err != nil || ms <= 0 {
+			m.output = append(m.output, m.colors().Error.Render("Usage: /walkspeed [<ms>|fast]"))
+			return
+		}
+		m.settingsManager.FastWalk = false
+		m.settingsManager.SetWalkDelayMs(ms)
+		m.output = append(m.output, m.colors().Info.Render(fmt.Sprintf("Walk speed set to %dms per step.", m.settingsManager.GetWalkDelayMs())))
+	}
+
+	if err := m.settingsManager.Save(); err != nil {
+		m.output = append(m.output, m.colors().Error.Render(fmt.Sprintf("Error saving settings: %v", err)))
+	}
+}
+
+// numpadKeyDirections maps numpad digits to movement directions
+var numpadKeyDirections = map[string]string{
+	"8": "north",
+	"2": "south",
+	"4": "west",
+	"6": "east",
+	"7": "northwest",
+	"9": "northeast",
+	"1": "southwest",
+	"3": "southeast",
+}
+
+// arrowKeyDirections maps arrow keys to movement directions
+var arrowKeyDirections = map[tea.KeyType]string{
+	tea.KeyUp:    "north",
+	tea.KeyDown:  "south",
+	tea.KeyLeft:  "west",
+	tea.KeyRight: "east",
+}
+
+// numpadWalkDirection returns the direction a key walks in numpad walk mode,
+// or "" if the key should be handled normally. Keys only walk when the input
+// line is empty, so they still edit text once something has been typed.
+func (m *Model) numpadWalkDirection(msg tea.KeyMsg) string {
+	if m.settingsManager == nil || !m.settingsManager.NumpadWalk {
+		return ""
+	}
+	if m.currentInput != "" || m.echoSuppressed || m.isPasswordPrompt() {
+		return ""
+	}
+	if msg.Alt {
+		return ""
+	}
+
+	if msg.Type == tea.KeyRunes && len(msg.Runes) == 1 {
+		return numpadKeyDirections[string(msg.Runes)]
+	}
+	return arrowKeyDirections[msg.Type]
+}
+
+// sendMovement sends a movement command directly, as if it had been typed
+func (m *Model) sendMovement(direction string) {
+	if m.conn == nil || !m.connected {
+		return
+	}
+
+	m.pendingMovement = direction
+	m.odometer.Move()
+	// Clear map legend on movement
+	m.mapLegend = nil
+	m.mapLegendRooms = nil
+
+	m.conn.Send(direction)
+
+	// Show the command on the prompt line like a typed command
+	if len(m.output) > 0 {
+		m.output[len(m.output)-1] = m.output[len(m.output)-1] + m.echoed(direction)
+	}
+	m.updateViewport()
+}
+
+// handleNumpadWalkCommand shows or toggles numpad walk mode
+func (m *Model) handleNumpadWalkCommand(args []string) {
+	if m.settingsManager == nil {
+		m.settingsManager = settings.NewManager()
+	}
+
+	if len(args) == 0 {
+		if m.settingsManager.NumpadWalk {
+			m.output = append(m.output, m.colors().Info.Render("Numpad walk is on."))
+		} else {
+			m.output = append(m.output, m.colors().Info.Render("Numpad walk is off."))
+		}
+		return
+	}
+
+	switch strings.ToLower(args[0]) {
+	case "on":
+		m.settingsManager.NumpadWalk = true
+		m.output = append(m.output, m.colors().Info.Render("Numpad walk on. With an empty input line, numpad digits and arrow keys move you."))
+	case "off":
+		m.settingsManager.NumpadWalk = false
+		m.output = append(m.output, m.colors().Info.Render("Numpad walk off."))
+	default:
+		m.output = append(m.output, m.colors().Error.Render("Usage: /numpadwalk [on|off]"))
+		return
+	}
+
+	if err := m.settingsManager.Save(); err != nil {
+		m.output = append(m.output, m.colors().Error.Render(fmt.Sprintf("Error saving settings: %v", err)))
+	}
+}
+
+// handleHidePromptCommand turns moving the stat prompt to the status bar on or off
+func (m *Model) handleHidePromptCommand(args []string) {
+	if m.settingsManager == nil {
+		m.settingsManager = settings.NewManager()
+	}
+
+	if len(args) == 0 {
+		if m.settingsManager.HidePrompt {
+			m.output = append(m.output, m.colors().Info.Render("Prompt hiding is on."))
+		} else {
+			m.output = append(m.output, m.colors().Info.Render("Prompt hiding is off."))
+		}
+		return
+	}
+
+	switch strings.ToLower(args[0]) {
+	case "on":
+		m.settingsManager.HidePrompt = true
+		m.output = append(m.output, m.colors().Info.Render("Prompt hiding on. Stat prompts are shown in the status bar instead of the output."))
+	case "off":
+		m.settingsManager.HidePrompt = false
+		m.output = append(m.output, m.colors().Info.Render("Prompt hiding off."))
+	default:
+		m.output = append(m.output, m.colors().Error.Render("Usage: /hideprompt [on|off]"))
+		return
+	}
+
+	if err := m.settingsManager.Save(); err != nil {
+		m.output = append(m.output, m.colors().Error.Render(fmt.Sprintf("Error saving settings: %v", err)))
+	}
+}
+
+// handleSplitCommand shows or sets how the output is split: pinned on so
+// scrollback is always shown above the live output, and the share of the
+// height the scrollback gets. Both are saved in the settings.
+func (m *Model) handleSplitCommand(args []string) {
+	if m.settingsManager == nil {
+		m.settingsManager = settings.NewManager()
+	}
+
+	if len(args) == 0 {
+		state := "only while scrolled back"
+		if m.settingsManager.SplitPinned {
+			state = "always on"
+		}
+		m.output = append(m.output, m.colors().Info.Render(fmt.Sprintf("Split view: %s, %d%% for scrollback.", state, m.settingsManager.GetSplitRatio())))
+		return
+	}
+
+	switch strings.ToLower(args[0]) {
+	case "on":
+		m.settingsManager.SplitPinned = true
+		m.output = append(m.output, m.colors().Info.Render("Split view on. Scrollback is always shown above the live output."))
+	case "off":
+		m.settingsManager.SplitPinned = false
+		m.output = append(m.output, m.colors().Info.Render("Split view off. The output splits only while scrolled back."))
+	case "ratio":
+		var percent int
+		if len(args) != 2 {
+			m.output = append(m.output, m.colors().Error.Render("Usage: /split ratio <percent>"))
+			return
+		}
+		if _, err := fmt.Sscanf(strings.TrimSuffix(args[1], "%"), "%d", &percent); err != nil {
+			m.output = append(m.output, m.colors().Error.Render(fmt.Sprintf("Invalid percentage: %s", args[1])))
+			return
+		}
+		m.settingsManager.SplitRatio = settings.ClampSplitRatio(percent)
+		m.output = append(m.output, m.colors().Info.Render(fmt.Sprintf("Split view gives scrollback %d%% of the height.", m.settingsManager.SplitRatio)))
+	default:
+		m.output = append(m.output, m.colors().Error.Render("Usage: /split [on|off|ratio <percent>]"))
+		return
+	}
+
+	if err := m.settingsManager.Save(); err != nil {
+		m.output = append(m.output, m.colors().Error.Render(fmt.Sprintf("Error saving settings: %v", err)))
+	}
+}
+
+// handlePromptNewlineCommand turns the line break after prompts on or off
+func (m *Model) handlePromptNewlineCommand(args []string) {
+	if m.settingsManager == nil {
+		m.settingsManager = settings.NewManager()
+	}
+
+	if len(args) == 0 {
+		if m.settingsManager.PromptNewline {
+			m.output = append(m.output, m.colors().Info.Render("Newline after prompt is on."))
+		} else {
+			m.output = append(m.output, m.colors().Info.Render("Newline after prompt is off."))
+		}
+		return
+	}
+
+	switch strings.ToLower(args[0]) {
+	case "on":
+		m.settingsManager.PromptNewline = true
+		m.output = append(m.output, m.colors().Info.Render("Newline after prompt on. Typed commands start on a line of their own."))
+	case "off":
+		m.settingsManager.PromptNewline = false
+		m.output = append(m.output, m.colors().Info.Render("Newline after prompt off."))
+	default:
+		m.output = append(m.output, m.colors().Error.Render("Usage: /promptnewline [on|off]"))
+		return
+	}
+
+	if err := m.settingsManager.Save(); err != nil {
+		m.output = append(m.output, m.colors().Error.Render(fmt.Sprintf("Error saving settings: %v", err)))
+	}
+}
+
+// isPromptLine reports whether a line (without colors, trimmed) is a
+// prompt, using the prompt pattern set for this server with /promptpattern
+func (m *Model) isPromptLine(line string) bool {
+	if m.worldMap == nil {
+		return mapper.IsPromptLine(line)
+	}
+	return m.worldMap.IsPrompt(line)
+}
+
+// handlePromptPatternCommand sets the regular expression that recognizes
+// this MUD's prompt lines. It is saved with the server's map.
+func (m *Model) handlePromptPatternCommand(arg string) {
+	if arg == "" {
+		if m.worldMap.PromptPattern == "" {
+			m.output = append(m.output, m.colors().Info.Render("Prompt pattern: built-in (lines ending in '>' with H and V stats)"))
+		} else {
+			m.output = append(m.output, m.colors().Info.Render(fmt.Sprintf("Prompt pattern: %s", m.worldMap.PromptPattern)))
+		}
+		return
+	}
+
+	pattern := arg
+	if strings.ToLower(arg) == "off" {
+		pattern = ""
+	} else if len(arg) >= 2 && strings.HasPrefix(arg, "\"") && strings.HasSuffix(arg, "\"") {
+		pattern = arg[1 : len(arg)-1]
+	}
+
+	if err := m.worldMap.SetPromptPattern(pattern); err != nil {
+		m.output = append(m.output, m.colors().Error.Render(fmt.Sprintf("Error: %v", err)))
+		m.output = append(m.output, m.colors().Warn.Render("Usage: /promptpattern [\"<regex>\" | off]"))
+		return
+	}
+	if err := m.worldMap.Save(); err != nil {
+		m.output = append(m.output, m.colors().Error.Render(fmt.Sprintf("Error saving map: %v", err)))
+	}
+
+	if pattern == "" {
+		m.output = append(m.output, m.colors().Info.Render("Prompt pattern cleared. Using the built-in prompt check."))
+	} else {
+		m.output = append(m.output, m.colors().Info.Render(fmt.Sprintf("Prompt pattern set to %s", pattern)))
+	}
+}
+
+// detectReconnectWarning schedules a reconnect when a line matches the
+// MUD's reboot warning set with /reconnect-on
+func (m *Model) detectReconnectWarning(cleanLine string, now time.Time) tea.Cmd {
+	if m.worldMap == nil || m.replayPath != "" {
+		return nil
+	}
+	delay, ok := m.worldMap.ReconnectAfter(strings.TrimSpace(cleanLine))
+	if !ok {
+		return nil
+	}
+
+	at := now.Add(delay)
+	m.reconnectAt = at
+	m.output = append(m.output, m.colors().Info.Render(fmt.Sprintf("[Reconnect: reconnecting in %s - /reconnect to do it sooner]", delay)))
+	return tea.Tick(delay, func(time.Time) tea.Msg {
+		return reconnectMsg{at: at}
+	})
+}
+
+// reconnect opens a new connection to the session's server. Auto-login
+// starts over, so the character logs in again.
+func (m *Model) reconnect() tea.Cmd {
+	m.reconnectAt = time.Time{}
+	m.reconnecting = true
+	m.autoLoginState = 0
+	m.loginMenuStep = 0
+	m.output = append(m.output, m.colors().Info.Render(fmt.Sprintf("Reconnecting to %s:%d...", m.host, m.port)))
+
+	host, port, telnetDebugLog := m.host, m.port, m.telnetDebugLog
+	charset, lineEnding := m.serverEncoding(host, port)
+	return func() tea.Msg {
+		conn, err := client.NewConnectionWithCharset(host, port, telnetDebugLog, charset)
+		if err != nil {
+			return errMsg(err)
+		}
+		conn.SetLineEnding(lineEnding)
+		return conn
+	}
+}
+
+// handleReconnectCommand connects a closed session to its server again,
+// cancelling any reconnect still waiting
+func (m *Model) handleReconnectCommand() tea.Cmd {
+	if m.replayPath != "" {
+		m.output = append(m.output, m.colors().Warn.Render("Nothing to reconnect to while replaying."))
+		return nil
+	}
+	if m.connected && m.conn != nil && !m.conn.IsClosed() {
+		m.output = append(m.output, m.colors().Warn.Render(fmt.Sprintf("Already connected to %s:%d.", m.host, m.port)))
+		return nil
+	}
+	return m.reconnect()
+}
+
+// handleReconnectOnCommand sets the regular expression matching the MUD's
+// reboot warning and the delay before reconnecting. It is saved with the
+// server's map.
+func (m *Model) handleReconnectOnCommand(arg string) {
+	if arg == "" {
+		if m.worldMap.ReconnectOn == "" {
+			m.output = append(m.output, m.colors().Info.Render("Reconnect on reboot warning: off"))
+		} else {
+			m.output = append(m.output, m.colors().Info.Render(fmt.Sprintf("Reconnect on \"%s\" after %ds", m.worldMap.ReconnectOn, m.worldMap.ReconnectDelay)))
+		}
+		if !m.reconnectAt.IsZero() {
+			m.output = append(m.output, m.colors().Info.Render(fmt.Sprintf("Reconnecting in %s", time.Until(m.reconnectAt).Round(time.Second))))
+		}
+		return
+	}
+
+	pattern, delay := "", 0
+	if strings.ToLower(arg) == "off" {
+		// A reconnect already scheduled is cancelled too
+		m.reconnectAt = time.Time{}
+	} else {
+		end := strings.LastIndex(arg, "\"")
+		if !strings.HasPrefix(arg, "\"") || end < 1 {
+			m.output = append(m.output, m.colors().Warn.Render("Usage: /reconnect-on [\"<regex>\" <delay-seconds> | off]"))
+			return
+		}
+		pattern = arg[1:end]
+		if _, err := fmt.Sscanf(strings.TrimSpace(arg[end+1:]), "%d", &delay); err != nil || pattern == "" {
+			m.output = append(m.output, m.colors().Warn.Render("Usage: /reconnect-on [\"<regex>\" <delay-seconds> | off]"))
+			return
+		}
+	}
+
+	if err := m.worldMap.SetReconnectOn(pattern, delay); err != nil {
+		m.output = append(m.output, m.colors().Error.Render(fmt.Sprintf("Error: %v", err)))
+		m.output = append(m.output, m.colors().Warn.Render("Usage: /reconnect-on [\"<regex>\" <delay-seconds> | off]"))
+		return
+	}
+	if err := m.worldMap.Save(); err != nil {
+		m.output = append(m.output, m.colors().Error.Render(fmt.Sprintf("Error saving map: %v", err)))
+	}
+
+	if pattern == "" {
+		m.output = append(m.output, m.colors().Info.Render("Reconnect on reboot warning turned off."))
+	} else {
+		m.output = append(m.output, m.colors().Info.Render(fmt.Sprintf("Will reconnect %ds after a line matching %s", delay, pattern)))
+	}
+}
+
+// lastOutputLine returns the last line of output, passing over the empty
+// line added for typing after a prompt
+func (m *Model) lastOutputLine() string {
+	n := len(m.output)
+	if m.syntheticInputLine && n > 1 && m.output[n-1] == "" {
+		n--
+	}
+	if n == 0 {
+		return ""
+	}
+	return m.output[n-1]
+}
+
+// followsBlankLine reports whether the output still ends with the blank line
+// the MUD sent last. Anything added since, such as client command output or
+// a command typed on that line, ends the run.
+func (m *Model) followsBlankLine() bool {
+	n := len(m.output)
+	return m.blankLineEnd > 0 && n == m.blankLineEnd && strings.TrimSpace(ansi.Strip(m.output[n-1])) == ""
+}
+
+// handleCollapseCommand turns collapsing of blank line runs on or off
+func (m *Model) handleCollapseCommand(args []string) {
+	if m.settingsManager == nil {
+		m.settingsManager = settings.NewManager()
+	}
+
+	if len(args) == 0 {
+		if m.settingsManager.CollapseBlanks {
+			m.output = append(m.output, m.colors().Info.Render("Blank line collapsing is on."))
+		} else {
+			m.output = append(m.output, m.colors().Info.Render("Blank line collapsing is off."))
+		}
+		return
+	}
+
+	switch strings.ToLower(args[0]) {
+	case "on":
+		m.settingsManager.CollapseBlanks = true
+		m.output = append(m.output, m.colors().Info.Render("Blank line collapsing on. Runs of blank lines from the MUD show as one."))
+	case "off":
+		m.settingsManager.CollapseBlanks = false
+		m.output = append(m.output, m.colors().Info.Render("Blank line collapsing off."))
+	default:
+		m.output = append(m.output, m.colors().Error.Render("Usage: /collapse [on|off]"))
+		return
+	}
+
+	if err := m.settingsManager.Save(); err != nil {
+		m.output = append(m.output, m.colors().Error.Render(fmt.Sprintf("Error saving settings: %v", err)))
+	}
+}
+
+// handleLinksCommand turns making web addresses in the MUD output
+// clickable on or off
+func (m *Model) handleLinksCommand(args []string) {
+	if m.settingsManager == nil {
+		m.settingsManager = settings.NewManager()
+	}
+
+	if len(args) == 0 {
+		if m.settingsManager.LinkURLs {
+			m.output = append(m.output, m.colors().Info.Render("Links in MUD output are on."))
+		} else {
+			m.output = append(m.output, m.colors().Info.Render("Links in MUD output are off."))
+		}
+		return
+	}
+
+	switch strings.ToLower(args[0]) {
+	case "on":
+		m.settingsManager.LinkURLs = true
+		m.output = append(m.output, m.colors().Info.Render("Links on. Web addresses in MUD output are clickable in terminals that support it."))
+	case "off":
+		m.settingsManager.LinkURLs = false
+		m.output = append(m.output, m.colors().Info.Render("Links off."))
+	default:
+		m.output = append(m.output, m.colors().Error.Render("Usage: /links [on|off]"))
+		return
+	}
+
+	if err := m.settingsManager.Save(); err != nil {
+		m.output = append(m.output, m.colors().Error.Render(fmt.Sprintf("Error saving settings: %v", err)))
+	}
+}
+
+// hyperlink makes text a link to url in terminals that support OSC 8,
+// unless colors and other escape codes are turned off
+func (m *Model) hyperlink(url, text string) string {
+	if m.plainText() {
+		return text
+	}
+	return ansi.Hyperlink(url, text)
+}
+
+// echoed returns a command as it is echoed after the prompt, in bright
+// yellow unless colors are off
+func (m *Model) echoed(command string) string {
+	if m.plainText() {
+		return command
+	}
+	return "\x1b[93m" + command + "\x1b[0m"
+}
+
+
+// outputTab is one of the views of the output the main window can show
+type outputTab int
+
+const (
+	tabGame   outputTab = iota // Everything
+	tabChat                    // Tells, says and channel messages
+	tabSystem                  // The client's own messages: triggers, mapper notices, command replies
+	tabCount
+)
+
+// tabNames are the tabs' names, as shown in the tab bar and taken by /tab
+var tabNames = [tabCount]string{"Game", "Chat", "System"}
+
+// outputTabs sorts the output into the Chat and System tabs. The tabs hold
+// indexes into the output, so a command typed on a prompt shows in each.
+type outputTabs struct {
+	active outputTab
+	routed int           // Output lines already sorted into the tabs
+	chat   []int         // Output lines that are chat
+	system []int         // Output lines the client added
+	seen   [tabCount]int // Lines in each tab when it was last shown, for the unread count
+}
+
+// lines returns the indexes of the output lines in a tab other than Game
+func (t *outputTabs) lines(tab outputTab) []int {
+	if tab == tabChat {
+		return t.chat
+	}
+	return t.system
+}
+
+// appendGameLine adds a line from the MUD to the output. Lines the client
+// added since the last MUD line go to the System tab first, and chat lines
+// also go to the Chat tab.
+func (m *Model) appendGameLine(line string, chat bool) {
+	m.routeSystemOutput()
+	m.output = append(m.output, line)
+	m.tabs.routed = len(m.output)
+	if chat {
+		m.tabs.chat = append(m.tabs.chat, len(m.output)-1)
+	}
+}
+
+// routeSystemOutput puts the output lines not yet sorted into the System
+// tab: anything not added by appendGameLine is the client's own
+func (m *Model) routeSystemOutput() {
+	for i := m.tabs.routed; i < len(m.output); i++ {
+		m.tabs.system = append(m.tabs.system, i)
+	}
+	m.tabs.routed = len(m.output)
+}
+
+// tabOutput returns the output lines the active tab shows. The last output
+// line holds the prompt and input, so every tab ends with it.
+func (m *Model) tabOutput() []string {
+	m.routeSystemOutput()
+	if m.tabs.active == tabGame || len(m.output) == 0 {
+		m.tabs.seen[tabGame] = len(m.output)
+		return m.output
+	}
+
+	indexes := m.tabs.lines(m.tabs.active)
+	m.tabs.seen[m.tabs.active] = len(indexes)
+	last := len(m.output) - 1
+	lines := make([]string, 0, len(indexes)+1)
+	for _, i := range indexes {
+		if i < last {
+			lines = append(lines, m.output[i])
+		}
+	}
+	return append(lines, m.output[last])
+}
+
+// renderTabBar names the tabs for the main window's top border, the active
+// one in brackets and the others with how many lines arrived unseen
+func (m *Model) renderTabBar() string {
+	m.routeSystemOutput()
+	names := make([]string, 0, tabCount)
+	for tab := tabGame; tab < tabCount; tab++ {
+		name := tabNames[tab]
+		switch {
+		case tab == m.tabs.active:
+			name = "[" + name + "]"
+		case tab != tabGame:
+			if unread := len(m.tabs.lines(tab)) - m.tabs.seen[tab]; unread > 0 {
+				name = fmt.Sprintf("%s (%d)", name, unread)
+			}
+		}
+		names = append(names, name)
+	}
+	return strings.Join(names, " ")
+}
+
+// switchTab shows a tab in the main window
+func (m *Model) switchTab(tab outputTab) {
+	m.tabs.active = tab
+	m.updateViewport()
+}
+
+// tabKeys switch tabs. Few terminals report Ctrl with a digit, so Alt
+// works too.
+var tabKeys = map[string]outputTab{
+	"ctrl+1": tabGame, "ctrl+2": tabChat, "ctrl+3": tabSystem,
+	"alt+1": tabGame, "alt+2": tabChat, "alt+3": tabSystem,
+}
+
+// handleTabCommand shows the tabs or switches to one by name or number
+func (m *Model) handleTabCommand(args []string) {
+	if len(args) == 0 {
+		m.output = append(m.output, m.colors().Info.Render(fmt.Sprintf("Tabs: %s (switch with /tab <name> or Alt+1/2/3)", m.renderTabBar())))
+		return
+	}
+	for tab := tabGame; tab < tabCount; tab++ {
+		if strings.EqualFold(args[0], tabNames[tab]) || args[0] == fmt.Sprint(int(tab)+1) {
+			m.switchTab(tab)
+			return
+		}
+	}
+	m.output = append(m.output, m.colors().Error.Render(fmt.Sprintf("Error: Unknown tab '%s' (use game, chat or system)", args[0])))
+}
+// focusedOutput returns the output as shown while /focus is on: lines not
+// matching the focus are dimmed, or left out with /focus hide. The last
+// line holds the prompt and input so it is always kept as it is.
+func (m *Model) focusedOutput(output []string) []string {
+	if m.focus == nil || len(output) == 0 {
+		return output
+	}
+
+	last := len(output) - 1
+	lines := make([]string, 0, len(output))
+	for _, line := range output[:last] {
+		plain := ansi.Strip(line)
+		switch {
+		case m.focus.MatchString(plain):
+			lines = append(lines, line)
+		case !m.focusHide:
+			lines = append(lines, "\x1b[2m"+plain+"\x1b[0m")
+		}
+	}
+	return append(lines, output[last])
+}
+
+// targetSGR shows the /target's name in bold and underlined
+const targetSGR = "\x1b[1;4m"
+
+// setTarget sets the combat target and compiles the pattern its name is
+// highlighted by, so the view doesn't compile it on every redraw
+func (m *Model) setTarget(name string) {
+	m.target = name
+	m.targetPattern = nil
+	if name != "" {
+		m.targetPattern = regexp.MustCompile(`(?i)\b` + regexp.QuoteMeta(name) + `\b`)
+	}
+}
+
+// highlightTarget returns the lines with the /target's name in bold and
+// underlined. The name is found in the visible text, and the line's own
+// colors and attributes are restored after it.
+func (m *Model) highlightTarget(lines []string) []string {
+	if m.targetPattern == nil {
+		return lines
+	}
+
+	highlighted := make([]string, len(lines))
+	for i, line := range lines {
+		matches := m.targetPattern.FindAllStringIndex(ansi.Strip(line), -1)
+		// From the last match back; highlighting keeps the visible text,
+		// so the earlier offsets still hold
+		for j := len(matches) - 1; j >= 0; j-- {
+			line = ansi.Highlight(line, matches[j][0], matches[j][1], targetSGR)
+		}
+		highlighted[i] = line
+	}
+	return highlighted
+}
+
+// handleTargetCommand shows, sets or clears the combat target
+func (m *Model) handleTargetCommand(args []string) {
+	if len(args) == 0 {
+		if m.target == "" {
+			m.output = append(m.output, m.colors().Info.Render("No target. It is set by /target <name> or the combat prompt."))
+		} else {
+			m.output = append(m.output, m.colors().Info.Render("Target: ")+m.colors().Highlight.Render(m.target))
+		}
+		return
+	}
+
+	if len(args) == 1 && (strings.EqualFold(args[0], "off") || strings.EqualFold(args[0], "clear")) {
+		m.setTarget("")
+		m.output = append(m.output, m.colors().Info.Render("Target cleared."))
+		return
+	}
+
+	m.setTarget(strings.Join(args, " "))
+	m.output = append(m.output, m.colors().Info.Render(fmt.Sprintf("Target set to '%s'. <target> in commands is replaced by it.", m.target)))
+}
+
+// handleFocusCommand sets or clears the /focus pattern. Only the view
+// changes: every line stays in the output and comes back with /focus off.
+func (m *Model) handleFocusCommand(command string) {
+	args := strings.TrimSpace(strings.TrimPrefix(strings.TrimPrefix(command, "/"), "focus"))
+
+	switch strings.ToLower(args) {
+	case "":
+		if m.focus == nil {
+			m.output = append(m.output, m.colors().Info.Render("Focus is off."))
+		} else if m.focusHide {
+			m.output = append(m.output, m.colors().Info.Render(fmt.Sprintf("Focusing on \"%s\", hiding other lines.", m.focusPattern())))
+		} else {
+			m.output = append(m.output, m.colors().Info.Render(fmt.Sprintf("Focusing on \"%s\", dimming other lines.", m.focusPattern())))
+		}
+		return
+	case "off":
+		m.focus = nil
+		m.focusHide = false
+		m.output = append(m.output, m.colors().Info.Render("Focus off. All lines shown."))
+		return
+	}
+
+	pattern, option := args, ""
+	if strings.HasPrefix(args, "\"") {
+		end := strings.LastIndex(args, "\"")
+		if end == 0 {
+			m.output = append(m.output, m.colors().Error.Render("Usage: /focus \"<pattern>\" [hide] | off"))
+			return
+		}
+		pattern, option = args[1:end], strings.ToLower(strings.TrimSpace(args[end+1:]))
+	}
+	if pattern == "" || (option != "" && option != "hide" && option != "dim") {
+		m.output = append(m.output, m.colors().Error.Render("Usage: /focus \"<pattern>\" [hide] | off"))
+		return
+	}
+
+	focus, err := regexp.Compile("(?i)" + pattern)
+	if err != nil {
+		m.output = append(m.output, m.colors().Error.Render(fmt.Sprintf("Error: invalid focus pattern: %v", err)))
+		return
+	}
+	m.focus = focus
+	m.focusHide = option == "hide"
+	if m.focusHide {
+		m.output = append(m.output, m.colors().Info.Render(fmt.Sprintf("Focusing on \"%s\": other lines hidden until /focus off.", pattern)))
+	} else {
+		m.output = append(m.output, m.colors().Info.Render(fmt.Sprintf("Focusing on \"%s\": other lines dimmed until /focus off.", pattern)))
+	}
+}
+
+// focusPattern returns the /focus pattern as it was typed
+func (m *Model) focusPattern() string {
+	return strings.TrimPrefix(m.focus.String(), "(?i)")
+}
+
+// handleAnsiCommand turns color in the output on or off
+func (m *Model) handleAnsiCommand(args []string) {
+	if m.settingsManager == nil {
+		m.settingsManager = settings.NewManager()
+	}
+
+	if len(args) == 0 {
+		if m.settingsManager.PlainText {
+			m.output = append(m.output, "ANSI colors are off.")
+		} else {
+			m.output = append(m.output, m.colors().Info.Render("ANSI colors are on."))
+		}
+		return
+	}
+
+	switch strings.ToLower(args[0]) {
+	case "on":
+		m.settingsManager.PlainText = false
+		m.output = append(m.output, m.colors().Info.Render("ANSI colors on."))
+	case "off":
+		m.settingsManager.PlainText = true
+		// Convert what's already on screen, in every session; what comes
+		// after is stored as plain text
+		stripColors(m.output)
+		for _, session := range m.sessions {
+			stripColors(session.output)
+		}
+		m.output = append(m.output, "ANSI colors off. Output is shown as plain text.")
+	default:
+		m.output = append(m.output, m.colors().Error.Render("Usage: /ansi [on|off]"))
+		return
+	}
+
+	if err := m.settingsManager.Save(); err != nil {
+		m.output = append(m.output, m.colors().Error.Render(fmt.Sprintf("Error saving settings: %v", err)))
+	}
+}
+
+// handleThemeCommand shows or changes the colors of the client's messages
+func (m *Model) handleThemeCommand(args []string) {
+	if m.settingsManager == nil {
+		m.settingsManager = settings.NewManager()
+	}
+
+	if len(args) == 0 {
+		m.output = append(m.output, m.colors().Info.Render("=== Message colors ==="))
+		for _, name := range theme.Names() {
+			color, ok := m.settingsManager.Theme[name]
+			if !ok {
+				color = theme.DefaultColors[name] + " (default)"
+			}
+			m.output = append(m.output, fmt.Sprintf("  %-10s %s  %s", name, m.colors().Style(name).Render("Sample message"), color))
+		}
+		if m.plainText() {
+			m.output = append(m.output, "Colors are off. Use /ansi on to show them.")
+		}
+		return
+	}
+
+	switch strings.ToLower(args[0]) {
+	case "set":
+		if len(args) != 3 {
+			m.output = append(m.output, m.colors().Error.Render("Usage: /theme set <kind> <color>"))
+			return
+		}
+		name := strings.ToLower(args[1])
+		if !theme.ValidName(name) {
+			m.output = append(m.output, m.colors().Error.Render(fmt.Sprintf("Unknown kind '%s'. Use one of: %s", args[1], strings.Join(theme.Names(), ", "))))
+			return
+		}
+		color, err := theme.ParseColor(args[2])
+		if err != nil {
+			m.output = append(m.output, m.colors().Error.Render(fmt.Sprintf("Error: %v", err)))
+			return
+		}
+		if m.settingsManager.Theme == nil {
+			m.settingsManager.Theme = make(map[string]string)
+		}
+		m.settingsManager.Theme[name] = color
+		m.theme = nil
+		m.output = append(m.output, m.colors().Style(name).Render(fmt.Sprintf("%s messages now look like this.", name)))
+	case "reset":
+		if len(args) > 1 {
+			name := strings.ToLower(args[1])
+			if !theme.ValidName(name) {
+				m.output = append(m.output, m.colors().Error.Render(fmt.Sprintf("Unknown kind '%s'. Use one of: %s", args[1], strings.Join(theme.Names(), ", "))))
+				return
+			}
+			delete(m.settingsManager.Theme, name)
+		} else {
+			m.settingsManager.Theme = nil
+		}
+		m.theme = nil
+		m.output = append(m.output, m.colors().Info.Render("Message colors reset to the defaults."))
+	default:
+		m.output = append(m.output, m.colors().Error.Render("Usage: /theme [set <kind> <color>|reset [<kind>]]"))
+		return
+	}
+
+	if err := m.settingsManager.Save(); err != nil {
+		m.output = append(m.output, m.colors().Error.Render(fmt.Sprintf("Error saving settings: %v", err)))
+	}
+}
+
+// handleTelnetCommand sends a raw telnet negotiation or subnegotiation
+func (m *Model) handleTelnetCommand(args []string) {
+	usage := func() {
+		m.output = append(m.output, m.colors().Warn.Render("Usage: /telnet <will|wont|do|dont> <option> or /telnet sb <option> <hexbytes>"))
+	}
+	if len(args) < 2 {
+		usage()
+		return
+	}
+
+	option, err := client.ParseTelnetOption(args[1])
+	if err != nil {
+		m.output = append(m.output, m.colors().Error.Render(fmt.Sprintf("Error: %v", err)))
+		return
+	}
+
+	var seq []byte
+	var name string
+	if strings.EqualFold(args[0], "sb") {
+		if len(args) < 3 {
+			usage()
+			return
+		}
+		data, err := client.ParseHexBytes(strings.Join(args[2:], ""))
+		if err != nil {
+			m.output = append(m.output, m.colors().Error.Render(fmt.Sprintf("Error: %v", err)))
+			return
+		}
+		seq = client.EncodeSubnegotiation(option, data)
+		name = fmt.Sprintf("IAC SB %s ... IAC SE", strings.ToUpper(args[1]))
+	} else {
+		if len(args) != 2 {
+			usage()
+			return
+		}
+		cmd, err := client.ParseTelnetCommand(args[0])
+		if err != nil {
+			m.output = append(m.output, m.colors().Error.Render(fmt.Sprintf("Error: %v", err)))
+			return
+		}
+		seq = client.EncodeNegotiation(cmd, option)
+		name = fmt.Sprintf("IAC %s %s", strings.ToUpper(args[0]), strings.ToUpper(args[1]))
+	}
+
+	if m.conn == nil || !m.connected {
+		m.output = append(m.output, m.colors().Error.Render("Not connected"))
+		return
+	}
+	m.conn.SendRaw(seq)
+	m.output = append(m.output, fmt.Sprintf("%s %s", m.colors().Info.Render(fmt.Sprintf("Sent %s", name)), m.colors().Debug.Render(fmt.Sprintf("(% x)", seq))))
+}
+
+// plainText reports whether colors are turned off with /ansi off
+func (m *Model) plainText() bool {
+	return m.settingsManager != nil && m.settingsManager.PlainText
+}
+
+// colors returns the theme for the client's own messages, which leaves
+// them uncolored when colors are turned off
+func (m *Model) colors() *theme.Theme {
+	if m.plainText() {
+		return theme.Plain()
+	}
+	if m.theme == nil {
+		var overrides map[string]string
+		if m.settingsManager != nil {
+			overrides = m.settingsManager.Theme
+		}
+		m.theme = theme.New(overrides)
+	}
+	return m.theme
+}
+
+// stripColors removes escape codes from each line that has them
+func stripColors(lines []string) {
+	for i, line := range lines {
+		if strings.IndexByte(line, '\x1b') >= 0 {
+			lines[i] = ansi.Strip(line)
+		}
+	}
+}
+
+// handleReloadCommand re-reads triggers, aliases and/or the map from disk.
+// Managers are only saved from Update, so reloading here can't race a save.
+func (m *Model) handleReloadCommand(args []string) {
+	what := "all"
+	if len(args) > 0 {
+		what = strings.ToLower(args[0])
+	}
+	if what != "all" && what != "triggers" && what != "aliases" && what != "map" {
+		m.output = append(m.output, m.colors().Error.Render("Usage: /reload [triggers|aliases|map|all]"))
+		return
+	}
+
+	if what == "all" || what == "triggers" {
+		triggerManager, err := triggers.Load()
+		if err != nil {
+			m.output = append(m.output, m.colors().Error.Render(fmt.Sprintf("Error reloading triggers: %v", err)))
+		} else {
+			m.triggerManager = triggerManager
+			m.output = append(m.output, m.colors().Info.Render(fmt.Sprintf("Reloaded %d trigger(s)", len(triggerManager.Triggers))))
+		}
+	}
+
+	if what == "all" || what == "aliases" {
+		aliasManager, err := aliases.Load()
+		if err != nil {
+			m.output = append(m.output, m.colors().Error.Render(fmt.Sprintf("Error reloading aliases: %v", err)))
+		} else {
+			m.aliasManager = aliasManager
+			m.output = append(m.output, m.colors().Info.Render(fmt.Sprintf("Reloaded %d alias(es)", len(aliasManager.Aliases))))
+		}
+	}
+
+	if what == "all" || what == "map" {
+		worldMap, err := mapper.LoadForServer(m.host, m.port)
+		if err != nil {
+			m.output = append(m.output, m.colors().Error.Render(fmt.Sprintf("Error reloading map: %v", err)))
+		} else {
+			// Other sessions on the same server share the map
+			for i, session := range m.sessions {
+				if i != m.activeSession && session.host == m.host && session.port == m.port {
+					session.worldMap = worldMap
+				}
+			}
+			m.worldMap = worldMap
+			if worldMap.BarsoomMode {
+				m.barsoomMode = true
+			}
+			m.output = append(m.output, m.colors().Info.Render(fmt.Sprintf("Reloaded map with %d room(s)", len(worldMap.Rooms))))
+		}
+	}
+}
+
+// handleThrottleCommand shows or sets the minimum delay between commands
+// sent to the MUD, for servers that disconnect players who send too fast
+func (m *Model) handleThrottleCommand(args []string) {
+	if m.settingsManager == nil {
+		m.settingsManager = settings.NewManager()
+	}
+
+	if len(args) == 0 {
+		if m.settingsManager.ThrottleMs > 0 {
+			m.output = append(m.output, m.colors().Info.Render(fmt.Sprintf("Commands are sent at most every %d ms.", m.settingsManager.ThrottleMs)))
+		} else {
+			m.output = append(m.output, m.colors().Info.Render("Command throttle is off."))
+		}
+		return
+	}
+
+	ms := 0
+	if !strings.EqualFold(args[0], "off") {
+		if _, err := fmt.Sscanf(args[0], "%d", &ms); err != nil || ms < 0 {
+			m.output = append(m.output, m.colors().Error.Render("Usage: /throttle [<ms>|off]"))
+			return
+		}
+	}
+	m.settingsManager.ThrottleMs = ms
+
+	// Apply to every open connection
+	interval := time.Duration(ms) * time.Millisecond
+	if m.conn != nil {
+		m.conn.SetSendInterval(interval)
+	}
+	for i, session := range m.sessions {
+		if i != m.activeSession && session.conn != nil {
+			session.conn.SetSendInterval(interval)
+		}
+	}
+
+	if ms > 0 {
+		m.output = append(m.output, m.colors().Info.Render(fmt.Sprintf("Commands will be sent at most every %d ms.", ms)))
+	} else {
+		m.output = append(m.output, m.colors().Info.Render("Command throttle off."))
+	}
+	if err := m.settingsManager.Save(); err != nil {
+		m.output = append(m.output, m.colors().Error.Render(fmt.Sprintf("Error saving settings: %v", err)))
+	}
+}
+
+// handleStopCommand stops any pending command queue and auto-walking
+func (m *Model) handleStopCommand() {
+	if m.commandQueueActive || m.autoWalking || len(m.pendingCommands) > 0 || m.retryCast != nil {
+		m.stopCommandQueue()
+		m.retryCast = nil
+		m.output = append(m.output, m.colors().Warn.Render("Command queue and auto-walking stopped."))
+	} else {
+		m.output = append(m.output, m.colors().Warn.Render("No active command queue or auto-walking to stop."))
+	}
+}
+
+// handleAutoWalkFailure handles a failed movement during auto-walk
+func (m *Model) handleAutoWalkFailure() tea.Cmd {
+	if !m.autoWalking {
+		return nil
+	}
+
+	// Get the last direction we tried to go
+	lastDirection := ""
+	if m.autoWalkIndex > 0 && m.autoWalkIndex <= len(m.autoWalkPath) {
+		lastDirection = m.autoWalkPath[m.autoWalkIndex-1]
+	}
+
+	m.output = append(m.output, m.colors().Error.Render("[Auto-walk: Movement failed - cannot go that way]"))
+
+	// Remove the exit from the current room first, before replanning
+	if lastDirection != "" && m.worldMap.GetCurrentRoom() != nil {
+		currentRoom := m.worldMap.GetCurrentRoom()
+		m.output = append(m.output, m.colors().Warn.Render(fmt.Sprintf("[Auto-walk: Removing invalid exit '%s' from current room]", lastDirection)))
+		currentRoom.RemoveExit(lastDirection)
+		m.mapChanged()
+	}
+
+	// Stop current auto-walk and clear command queue
+	targetTitle := m.autoWalkTarget
+	m.autoWalking = false
+	m.autoWalkPath = nil
+	m.autoWalkIndex = 0
+	m.autoWalkTarget = ""
+	m.pendingCommands = nil // Clear the remaining queued commands
+	m.commandQueueActive = false
+
+	// Try to replan the route to the same destination
+	if targetTitle != "" {
+		m.output = append(m.output, m.colors().Warn.Render(fmt.Sprintf("[Auto-walk: Re-planning route to '%s']", targetTitle)))
+
+		// Find the target room again
+		rooms := m.worldMap.FindRooms(targetTitle)
+		if len(rooms) == 0 {
+			m.output = append(m.output, m.colors().Error.Render(fmt.Sprintf("[Auto-walk: Cannot find target room '%s']", targetTitle)))
+			return nil
+		}
+
+		// Find a new path
+		targetRoom := rooms[0]
+		path := m.worldMap.FindPath(targetRoom.ID)
+
+		if path == nil || len(path) == 0 {
+			m.output = append(m.output, m.colors().Error.Render(fmt.Sprintf("[Auto-walk: No valid path found to '%s']", targetTitle)))
+			return nil
+		}
+
+		// Restart auto-walking with the new path
+		m.autoWalking = true
+		m.autoWalkPath = path
+		m.autoWalkIndex = 0
+		m.autoWalkTarget = targetTitle
+		m.output = append(m.output, m.colors().Info.Render(fmt.Sprintf("[Auto-walk: Restarting with new route (%d steps)]", len(path))))
+
+		// Enqueue the new path
+		return m.enqueueCommands(path)
+	}
+
+	return nil
+}
+
+// enqueueCommands adds commands to the pending queue and starts processing if not already active
+// Commands should be split on `;` before calling this function
+func (m *Model) enqueueCommands(commands []string) tea.Cmd {
+	// Add commands to the queue
+	m.pendingCommands = append(m.pendingCommands, commands...)
+
+	// If queue is not already active, start processing
+	if !m.commandQueueActive && len(m.pendingCommands) > 0 {
+		m.commandQueueActive = true
+		return tea.Tick(m.queueInterval(), func(t time.Time) tea.Msg {
+			return commandQueueTickMsg{}
+		})
+	}
+
+	return nil
+}
+
+// pasteConfirmLines is the number of pasted lines above which the paste
+// must be confirmed before it is sent
+const pasteConfirmLines = 10
+
+// handlePaste splits pasted text at line breaks and queues each complete line
+// as a command. Text after the last line break stays in the input line.
+func (m *Model) handlePaste(text string) tea.Cmd {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	text = strings.ReplaceAll(text, "\r", "\n")
+	text = m.currentInput[:m.cursorPos] + text + m.currentInput[m.cursorPos:]
+
+	lines := strings.Split(text, "\n")
+	remainder := lines[len(lines)-1]
+	var commands []string
+	for _, line := range lines[:len(lines)-1] {
+		if line = strings.TrimSpace(line); line != "" {
+			commands = append(commands, line)
+		}
+	}
+
+	m.currentInput = remainder
+	m.cursorPos = len(remainder)
+
+	if len(commands) > pasteConfirmLines {
+		m.pendingPaste = commands
+		m.output = append(m.output, m.colors().Warn.Render(fmt.Sprintf("[Paste: %d lines - press Enter to send them, any other key to discard]", len(commands))))
+		m.updateViewport()
+		return nil
+	}
+
+	return m.queuePaste(commands)
+}
+
+// confirmPaste sends a large paste on Enter and discards it on any other key
+func (m *Model) confirmPaste(msg tea.KeyMsg) tea.Cmd {
+	commands := m.pendingPaste
+	m.pendingPaste = nil
+
+	if msg.Type != tea.KeyEnter {
+		m.output = append(m.output, m.colors().Debug.Render(fmt.Sprintf("[Paste: discarded %d lines]", len(commands))))
+		m.updateViewport()
+		return nil
+	}
+	return m.queuePaste(commands)
+}
+
+// queuePaste queues pasted commands to be sent one per tick
+func (m *Model) queuePaste(commands []string) tea.Cmd {
+	if len(commands) == 0 {
+		m.updateViewport()
+		return nil
+	}
+	m.output = append(m.output, m.colors().Debug.Render(fmt.Sprintf("[Paste: queued %d command(s)]", len(commands))))
+	m.updateViewport()
+	return m.enqueueCommands(commands)
+}
+
+// repeatConfirmCount is the count above which /repeat must be confirmed
+// before anything is queued
+const repeatConfirmCount = 20
+
+// maxRepeatCount is the most times /repeat runs a command
+const maxRepeatCount = 500
+
+// handleRepeatCommand queues a command (or an alias, or several separated by
+// semicolons) a number of times, sent one per tick like a paste
+func (m *Model) handleRepeatCommand(arg string) tea.Cmd {
+	countArg, line, _ := strings.Cut(arg, " ")
+	line = strings.TrimSpace(line)
+	var count int
+	if _, err := fmt.Sscanf(countArg, "%d", &count); err != nil || count < 1 || line == "" {
+		m.output = append(m.output, m.colors().Warn.Render("Usage: /repeat <n> <command>"))
+		m.output = append(m.output, m.colors().Warn.Render("Example: /repeat 5 cast 'magic missile' orc"))
+		return nil
+	}
+	if count > maxRepeatCount {
+		m.output = append(m.output, m.colors().Error.Render(fmt.Sprintf("Error: /repeat runs a command at most %d times", maxRepeatCount)))
+		return nil
+	}
+
+	if m.aliasManager != nil {
+		if expanded, ok := m.aliasManager.Expand(line); ok {
+			line = expanded
+		}
+	}
+	var commands []string
+	for i := 0; i < count; i++ {
+		for _, command := range strings.Split(line, ";") {
+			if command = strings.TrimSpace(command); command != "" {
+				commands = append(commands, command)
+			}
+		}
+	}
+
+	if count > repeatConfirmCount {
+		m.pendingRepeat = commands
+		m.output = append(m.output, m.colors().Warn.Render(fmt.Sprintf("[Repeat: %s %d times - press Enter to queue it, any other key to cancel]", line, count)))
+		return nil
+	}
+	return m.queueRepeat(commands)
+}
+
+// confirmRepeat queues a large /repeat on Enter and cancels it on any other key
+func (m *Model) confirmRepeat(msg tea.KeyMsg) tea.Cmd {
+	commands := m.pendingRepeat
+	m.pendingRepeat = nil
+
+	if msg.Type != tea.KeyEnter {
+		m.output = append(m.output, m.colors().Debug.Render("[Repeat: cancelled]"))
+		m.updateViewport()
+		return nil
+	}
+	cmd := m.queueRepeat(commands)
+	m.updateViewport()
+	return cmd
+}
+
+// queueRepeat queues the commands of a /repeat
+func (m *Model) queueRepeat(commands []string) tea.Cmd {
+	m.output = append(m.output, m.colors().Debug.Render(fmt.Sprintf("[Repeat: queued %d command(s) - /stop to cancel]", len(commands))))
+	return m.enqueueCommands(commands)
+}
+
+// handleNotrigCommand sends a command with the triggers off for the next
+// output from the MUD, so doing by hand what a trigger reacts to doesn't
+// set it off
+func (m *Model) handleNotrigCommand(command string) {
+	if command == "" {
+		m.output = append(m.output, m.colors().Warn.Render("Usage: /notrig <command>"))
+		return
+	}
+	if m.conn == nil || !m.connected {
+		m.output = append(m.output, m.colors().Error.Render("Error: Not connected"))
+		return
+	}
+
+	command = m.substituteVariables(m.substituteOutgoing(command))
+	m.skipTriggers = true
+	m.conn.Send(command)
+	m.output = append(m.output, m.colors().Debug.Render(fmt.Sprintf("[No triggers: %s]", command)))
+}
+
+// learnLines is how many recent lines /learn lists to choose from
+const learnLines = 10
+
+// learnCandidates returns the recent MUD lines /learn can make a trigger
+// from, newest first, leaving out blank lines and prompts
+func (m *Model) learnCandidates() []string {
+	var lines []string
+	for i := len(m.recentOutput) - 1; i >= 0 && len(lines) < learnLines; i-- {
+		clean := strings.TrimSpace(ansi.Strip(m.recentOutput[i]))
+		if clean == "" || m.isPromptLine(clean) {
+			continue
+		}
+		lines = append(lines, clean)
+	}
+	return lines
+}
+
+// handleLearnCommand makes a trigger from a line just seen: /learn lists
+// the recent lines, /learn <n> <action> previews a trigger generalized from
+// line n, and /learn save or /learn cancel keeps or drops it
+func (m *Model) handleLearnCommand(args string) {
+	fields := strings.Fields(args)
+	switch {
+	case len(fields) == 0:
+		lines := m.learnCandidates()
+		if len(lines) == 0 {
+			m.output = append(m.output, m.colors().Warn.Render("No recent lines to learn from"))
+			return
+		}
+		m.output = append(m.output, m.colors().Info.Render("=== Recent lines (newest first) ==="))
+		for i, line := range lines {
+			m.output = append(m.output, fmt.Sprintf("  %2d. %s", i+1, line))
+		}
+		m.output = append(m.output, m.colors().Debug.Render("Use /learn <number> <action> to make a trigger from a line"))
+
+	case fields[0] == "save" && len(fields) == 1:
+		if m.learnPattern == "" {
+			m.output = append(m.output, m.colors().Warn.Render("Nothing to save; use /learn <number> <action> first"))
+			return
+		}
+		trigger, err := m.triggerManager.Add(m.learnPattern, m.learnAction)
+		if err != nil {
+			m.output = append(m.output, m.colors().Error.Render(fmt.Sprintf("Error adding trigger: %v", err)))
+			return
+		}
+		m.learnPattern, m.learnAction = "", ""
+		if err := m.triggerManager.Save(); err != nil {
+			m.output = append(m.output, m.colors().Error.Render(fmt.Sprintf("Error saving triggers: %v", err)))
+			return
+		}
+		m.output = append(m.output, m.colors().Info.Render(fmt.Sprintf("Trigger added: \"%s\" -> \"%s\"", trigger.Pattern, trigger.Action)))
+
+	case fields[0] == "cancel" && len(fields) == 1:
+		if m.learnPattern == "" {
+			m.output = append(m.output, m.colors().Warn.Render("Nothing to cancel"))
+			return
+		}
+		m.learnPattern, m.learnAction = "", ""
+		m.output = append(m.output, m.colors().Info.Render("Learned trigger dropped"))
+
+	default:
+		n := 0
+		_, err := fmt.Sscanf(fields[0], "%d", &n)
+		action := strings.TrimSpace(strings.TrimPrefix(args, fields[0]))
+		if err != nil || action == "" {
+			m.output = append(m.output, m.colors().Warn.Render("Usage: /learn [<number> <action> | save | cancel]"))
+			return
+		}
+		lines := m.learnCandidates()
+		if n < 1 || n > len(lines) {
+			m.output = append(m.output, m.colors().Error.Render(fmt.Sprintf("Error: No line %d; /learn lists the lines to choose from", n)))
+			return
+		}
+		m.previewLearnedTrigger(lines[n-1], action)
+	}
+}
+
+// previewLearnedTrigger generalizes a line into a trigger pattern and shows
+// what the trigger would do on that line, holding it until /learn save
+func (m *Model) previewLearnedTrigger(line, action string) {
+	pattern := triggers.Generalize(line)
+	if len(action) >= 2 && strings.HasPrefix(action, "\"") && strings.HasSuffix(action, "\"") {
+		action = action[1 : len(action)-1]
+	}
+
+	// Try the trigger on its own line to show the captures
+	preview := triggers.NewManager()
+	if _, err := preview.Add(pattern, action); err != nil {
+		m.output = append(m.output, m.colors().Error.Render(fmt.Sprintf("Error: Can't make a trigger from that line: %v", err)))
+		return
+	}
+	m.learnPattern, m.learnAction = pattern, action
+
+	m.output = append(m.output, m.colors().Info.Render("=== Learned trigger ==="))
+	m.output = append(m.output, "  Line:    "+line)
+	m.output = append(m.output, "  Pattern: "+m.colors().Highlight.Render(pattern))
+	m.output = append(m.output, "  Action:  "+m.colors().Highlight.Render(action))
+	if results := preview.Test(line); len(results) > 0 {
+		names := make([]string, 0, len(results[0].Captures))
+		for name := range results[0].Captures {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			m.output = append(m.output, fmt.Sprintf("    <%s> = %s", name, results[0].Captures[name]))
+		}
+		m.output = append(m.output, "  On this line it would send: "+results[0].Action)
+	}
+	m.output = append(m.output, m.colors().Debug.Render("Type /learn save to add it, or /learn cancel. For other options, use /trigger with this pattern."))
+}
+
+// stopCommandQueue clears the command queue and stops auto-walking
+func (m *Model) stopCommandQueue() {
+	m.pendingCommands = nil
+	m.commandQueueActive = false
+	m.fastWalkQueue = nil
+	m.autoWalking = false
+	m.autoWalkPath = nil
+	m.autoWalkIndex = 0
+	m.autoWalkTarget = ""
+	m.walkDelayOverride = 0
+}
+
+// walkInterval returns the delay between auto-walk steps
+func (m *Model) walkInterval() time.Duration {
+	if m.walkDelayOverride > 0 {
+		return m.walkDelayOverride
+	}
+	if m.settingsManager != nil {
+		return time.Duration(m.settingsManager.GetWalkDelayMs()) * time.Millisecond
+	}
+	return time.Duration(settings.DefaultWalkDelayMs) * time.Millisecond
+}
+
+// queueInterval returns the delay between queued commands
+// Auto-walk uses the configured walk speed, everything else uses one second
+func (m *Model) queueInterval() time.Duration {
+	if m.autoWalking {
+		return m.walkInterval()
+	}
+	return time.Second
+}
+
+// lineActionSuffix matches /gag or /highlight [color] given unquoted in
+// place of a trigger's action
+var lineActionSuffix = regexp.MustCompile(`"\s+(/(?:gag|highlight)(?:\s+[\w-]+)?)\s*$`)
+
+// handleTriggerCommand adds a new trigger
+func (m *Model) handleTriggerCommand(command string) {
+	// Parse the command to extract pattern and action
+	// Expected format: /trigger "pattern" "action"
+
+	// Remove "/trigger " prefix
+	command = strings.TrimPrefix(command, "trigger ")
+	command = strings.TrimSpace(command)
+
+	// test dry-runs a line against the triggers
+	if command == "test" || strings.HasPrefix(command, "test ") {
+		m.handleTriggerTestCommand(strings.TrimSpace(strings.TrimPrefix(command, "test")))
+		return
+	}
+
+	// -glob switches the pattern to * and ? wildcards, -re to a regular
+	// expression, -cooldown <sec>
+	// keeps the trigger from firing again too soon, -multiline <n>
+	// matches the pattern against the last n lines and -group <name> puts
+	// the trigger in a group that /group can turn on and off, and -bell
+	// rings the bell when it fires
+	glob := false
+	regex := false
+	raw := false
+	bell := false
+	var cooldown time.Duration
+	multiline := 0
+	group := ""
+	for strings.HasPrefix(command, "-") {
+		fields := strings.Fields(command)
+		switch fields[0] {
+		case "-glob":
+			glob = true
+			command = strings.TrimSpace(strings.TrimPrefix(command, "-glob"))
+		case "-re":
+			regex = true
+			command = strings.TrimSpace(strings.TrimPrefix(command, "-re"))
+		case "-raw":
+			raw = true
+			command = strings.TrimSpace(strings.TrimPrefix(command, "-raw"))
+		case "-bell":
+			bell = true
+			command = strings.TrimSpace(strings.TrimPrefix(command, "-bell"))
+		case "-group":
+			if len(fields) < 2 || strings.HasPrefix(fields[1], "\"") {
+				m.output = append(m.output, m.colors().Error.Render("Error: -group needs a group name"))
+				return
+			}
+			group = fields[1]
+			command = strings.TrimSpace(strings.TrimPrefix(command, "-group"))
+			command = strings.TrimSpace(strings.TrimPrefix(command, fields[1]))
+		case "-multiline":
+			lines := 0
+			if len(fields) > 1 {
+				fmt.Sscanf(fields[1], "%d", &lines)
+			}
+			if lines < 1 || lines > triggers.MaxLines {
+				m.output = append(m.output, m.colors().Error.Render(fmt.Sprintf("Error: -multiline needs a number of lines from 1 to %d", triggers.MaxLines)))
+				return
+			}
+			multiline = lines
+			command = strings.TrimSpace(strings.TrimPrefix(command, "-multiline"))
+			command = strings.TrimSpace(strings.TrimPrefix(command, fields[1]))
+		case "-cooldown":
+			seconds := 0
+			if len(fields) > 1 {
+				fmt.Sscanf(fields[1], "%d", &seconds)
+			}
+			if seconds <= 0 {
+				m.output = append(m.output, m.colors().Error.Render("Error: -cooldown needs a number of seconds greater than 0"))
+				return
+			}
+			cooldown = time.Duration(seconds) * time.Second
+			command = strings.TrimSpace(strings.TrimPrefix(command, "-cooldown"))
+			command = strings.TrimSpace(strings.TrimPrefix(command, fields[1]))
+		default:
+			m.output = append(m.output, m.colors().Error.Render(fmt.Sprintf("Error: Unknown option '%s'", fields[0])))
+			m.output = append(m.output, m.colors().Warn.Render("Usage: /trigger [-glob | -re] [-raw] [-bell] [-cooldown <sec>] [-multiline <n>] [-group <name>] \"pattern\" \"action\""))
+			return
+		}
+	}
+	if glob && regex {
+		m.output = append(m.output, m.colors().Error.Render("Error: -glob and -re can't be used together"))
+		return
+	}
+
+	// /gag and /highlight [color] take the place of the action, quoted or not
+	command = lineActionSuffix.ReplaceAllString(command, `" "$1"`)
+
+	// Parse quoted strings
+	pattern, action, err := parseQuotedArgs(command)
+	if err != nil {
+		m.output = append(m.output, m.colors().Error.Render(fmt.Sprintf("Error: %v", err)))
+		m.output = append(m.output, m.colors().Warn.Render("Usage: /trigger [-glob | -re] [-raw] [-bell] [-cooldown <sec>] [-multiline <n>] [-group <name>] \"pattern\" \"action\""))
+		m.output = append(m.output, m.colors().Warn.Render("Example: /trigger \"hungry\" \"eat bread\""))
+		m.output = append(m.output, m.colors().Warn.Render("Example: /trigger \"The <subject> dies\" \"get <subject>\""))
+		m.output = append(m.output, m.colors().Warn.Render("Example: /trigger -glob \"You receive * gold*\" \"split <1>\""))
+		m.output = append(m.output, m.colors().Warn.Render("Example: /trigger -re \"(\\d+) gold coins\" \"get <1> coins\""))
+		m.output = append(m.output, m.colors().Warn.Render("Example: /trigger -cooldown 10 \"You are bleeding\" \"cast 'heal' self\""))
+		m.output = append(m.output, m.colors().Warn.Render("Example: /trigger \"The sun rises\" /gag"))
+		m.output = append(m.output, m.colors().Warn.Render("Example: /trigger \"<name> tells you\" /highlight yellow"))
+		return
+	}
+	actionType := triggers.ActionCommand
+	color := ""
+	if fields := strings.Fields(action); len(fields) > 0 && (fields[0] == "/gag" || fields[0] == "/highlight") {
+		actionType = triggers.ActionType(strings.TrimPrefix(fields[0], "/"))
+		action = ""
+		if len(fields) > 1 {
+			color = strings.ToLower(fields[1])
+			if _, ok := ansi.NamedColor(color); !ok || len(fields) > 2 || actionType != triggers.ActionHighlight {
+				m.output = append(m.output, m.colors().Error.Render(fmt.Sprintf("Error: Unknown highlight color '%s'", strings.Join(fields[1:], " "))))
+				m.output = append(m.output, m.colors().Warn.Render("Colors: "+strings.Join(ansi.ColorNames, ", ")))
+				return
+			}
+		}
+	}
+
+	// Add the trigger
+	var trigger *triggers.Trigger
+	switch {
+	case glob:
+		trigger, err = m.triggerManager.AddGlob(pattern, action)
+	case regex:
+		trigger, err = m.triggerManager.AddRegex(pattern, action)
+	default:
+		trigger, err = m.triggerManager.Add(pattern, action)
+	}
+	if err != nil {
+		m.output = append(m.output, m.colors().Error.Render(fmt.Sprintf("Error adding trigger: %v", err)))
+		return
+	}
+	if actionType != triggers.ActionCommand {
+		trigger.ActionType = actionType
+		trigger.Color = color
+	}
+	trigger.Cooldown = cooldown
+	trigger.Lines = multiline
+	trigger.Group = group
+	trigger.Bell = bell
+	if raw {
+		if err := trigger.SetRaw(true); err != nil {
+			m.triggerManager.Remove(len(m.triggerManager.Triggers) - 1)
+			m.output = append(m.output, m.colors().Error.Render(fmt.Sprintf("Error adding trigger: %v", err)))
+			return
+		}
+	}
+
+	// Save triggers
+	if err := m.triggerManager.Save(); err != nil {
+		m.output = append(m.output, m.colors().Error.Render(fmt.Sprintf("Error saving triggers: %v", err)))
+		return
+	}
+
+	m.output = append(m.output, m.colors().Info.Render(fmt.Sprintf("Trigger added: \"%s\" -> \"%s\"%s", trigger.Pattern, trigger.Action, formatTriggerOptions(trigger))))
+}
+
+// formatTriggerOptions describes a trigger's cooldown, line window and group
+// for listings, or returns "" when it has none
+func formatTriggerOptions(trigger *triggers.Trigger) string {
+	options := ""
+	if trigger.Color != "" {
+		options += fmt.Sprintf(" [%s %s]", trigger.Type(), trigger.Color)
+	} else if trigger.Type() != triggers.ActionCommand {
+		options += fmt.Sprintf(" [%s]", trigger.Type())
+	}
+	if trigger.Lines > 1 {
+		options += fmt.Sprintf(" [%d lines]", trigger.Lines)
+	}
+	if trigger.Cooldown > 0 {
+		options += fmt.Sprintf(" [cooldown %s]", trigger.Cooldown)
+	}
+	if trigger.Group != "" {
+		options += fmt.Sprintf(" [group %s]", trigger.Group)
+	}
+	if trigger.Raw {
+		options += " [raw]"
+	}
+	if trigger.Bell {
+		options += " [bell]"
+	}
+	return options
+}
+
+// recentLines returns up to the last n lines of recent output as received,
+// oldest first
+func (m *Model) recentLines(n int) []string {
+	recent := m.recentOutput
+	if len(recent) > n {
+		recent = recent[len(recent)-n:]
+	}
+	return recent
+}
+
+// handleTriggerTestCommand shows which triggers a line would fire, with
+// their captures and actions, without sending anything
+func (m *Model) handleTriggerTestCommand(line string) {
+	if len(line) >= 2 && strings.HasPrefix(line, "\"") && strings.HasSuffix(line, "\"") {
+		line = line[1 : len(line)-1]
+	}
+	if line == "" {
+		m.output = append(m.output, m.colors().Error.Render("Usage: /trigger test \"<line>\""))
+		return
+	}
+
+	m.output = append(m.output, m.colors().Info.Render(fmt.Sprintf("=== Trigger Test: \"%s\" ===", line)))
+	var results []triggers.MatchResult
+	if m.triggerManager != nil {
+		results = m.triggerManager.Test(line)
+	}
+	if len(results) == 0 {
+		m.output = append(m.output, m.colors().Warn.Render("No triggers match."))
+		return
+	}
+
+	for _, result := range results {
+		m.output = append(m.output, "  "+m.colors().Highlight.Render(fmt.Sprintf("%d. \"%s\" -> \"%s\"", result.Index+1, result.Trigger.Pattern, result.Trigger.Action)))
+		names := make([]string, 0, len(result.Captures))
+		for name := range result.Captures {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			m.output = append(m.output, fmt.Sprintf("     <%s> = %s", name, result.Captures[name]))
+		}
+		switch result.Trigger.Type() {
+		case triggers.ActionGag:
+			m.output = append(m.output, "     Would hide the line")
+		case triggers.ActionHighlight:
+			if result.Trigger.Color != "" {
+				m.output = append(m.output, fmt.Sprintf("     Would color the match %s", result.Trigger.Color))
+			} else {
+				m.output = append(m.output, "     Would highlight the line")
+			}
+		default:
+			m.output = append(m.output, fmt.Sprintf("     Would run: %s", result.Action))
+		}
+	}
+}
+
+// handleTriggersCommand handles /triggers list, remove, enable and disable
+func (m *Model) handleTriggersCommand(args []string) {
+	if len(args) == 0 {
+		// Default to list
+		m.handleTriggersListCommand()
+		return
+	}
+
+	subCmd := strings.ToLower(args[0])
+	switch subCmd {
+	case "list":
+		m.handleTriggersListCommand()
+	case "remove":
+		if len(args) < 2 {
+			m.output = append(m.output, m.colors().Error.Render("Usage: /triggers remove <index>"))
+			return
+		}
+		var index int
+		_, err := fmt.Sscanf(args[1], "%d", &index)
+		if err != nil {
+			m.output = append(m.output, m.colors().Error.Render("Error: Invalid index"))
+			return
+		}
+		m.handleTriggersRemoveCommand(index)
+	case "enable", "disable":
+		var index int
+		if len(args) < 2 {
+			m.output = append(m.output, m.colors().Error.Render(fmt.Sprintf("Usage: /triggers %s <index>", subCmd)))
+			return
+		}
+		if _, err := fmt.Sscanf(args[1], "%d", &index); err != nil {
+			m.output = append(m.output, m.colors().Error.Render("Error: Invalid index"))
+			return
+		}
+		m.handleTriggersEnableCommand(index, subCmd == "enable")
+	default:
+		m.output = append(m.output, m.colors().Error.Render(fmt.Sprintf("Error: Unknown subcommand '%s'", subCmd)))
+		m.output = append(m.output, m.colors().Warn.Render("Usage: /triggers [list|remove <index>|enable <index>|disable <index>]"))
+	}
+}
+
+// handleTriggersListCommand lists all triggers
+func (m *Model) handleTriggersListCommand() {
+	if len(m.triggerManager.Triggers) == 0 {
+		m.output = append(m.output, m.colors().Warn.Render("No triggers defined."))
+		m.output = append(m.output, m.colors().Warn.Render("Use /trigger \"pattern\" \"action\" to add a trigger."))
+		return
+	}
+
+	m.output = append(m.output, m.colors().Info.Render("=== Active Triggers ==="))
+	for i, trigger := range m.triggerManager.Triggers {
+		mode := ""
+		if trigger.Mode == triggers.ModeGlob || trigger.Mode == triggers.ModeRegex {
+			mode = " [" + trigger.Mode + "]"
+		}
+		state := " " + m.colors().Info.Render("[on]")
+		if trigger.Disabled {
+			state = " " + m.colors().Debug.Render("[off]")
+		} else if !m.triggerManager.Enabled(trigger) {
+			state = " " + m.colors().Debug.Render(fmt.Sprintf("[off] (group %s disabled)", trigger.Group))
+		}
+		m.output = append(m.output, "  "+m.colors().Highlight.Render(fmt.Sprintf("%d. \"%s\" -> \"%s\"%s%s", i+1, trigger.Pattern, trigger.Action, mode, formatTriggerOptions(trigger)))+state)
+	}
+}
+
+// handleEditCommand opens a full-screen editor, currently for triggers
+func (m *Model) handleEditCommand(args []string) {
+	if len(args) != 1 || strings.ToLower(args[0]) != "triggers" {
+		m.output = append(m.output, m.colors().Warn.Render("Usage: /edit triggers"))
+		return
+	}
+	if m.accessible {
+		m.output = append(m.output, m.colors().Error.Render("The editor needs the full-screen display; use /trigger and /triggers instead."))
+		return
+	}
+	m.triggerEditor = editor.New(m.triggerManager, m.width, m.height)
+}
+
+// handleTriggerEditorKey passes a key to the trigger editor, and when it
+// closes saves the edited triggers or reports that they were dropped
+func (m *Model) handleTriggerEditorKey(msg tea.KeyMsg) {
+	m.triggerEditor.Update(msg)
+	if !m.triggerEditor.Done() {
+		return
+	}
+
+	triggerEditor := m.triggerEditor
+	m.triggerEditor = nil
+	if !triggerEditor.Saved() {
+		m.output = append(m.output, m.colors().Warn.Render("Trigger changes discarded."))
+		m.updateViewport()
+		return
+	}
+
+	triggerEditor.Apply(m.triggerManager)
+	if err := m.triggerManager.Save(); err != nil {
+		m.output = append(m.output, m.colors().Error.Render(fmt.Sprintf("Error saving triggers: %v", err)))
+	} else {
+		m.output = append(m.output, m.colors().Info.Render(fmt.Sprintf("Saved %d triggers.", len(m.triggerManager.Triggers))))
+	}
+	m.updateViewport()
+}
+
+// handleTriggersRemoveCommand removes a trigger by index
+func (m *Model) handleTriggersRemoveCommand(index int) {
+	// Convert from 1-based to 0-based index
+	index--
+
+	if index < 0 || index >= len(m.triggerManager.Triggers) {
+		m.output = append(m.output, m.colors().Error.Render("Error: Invalid trigger index. Use /triggers list to see available triggers."))
+		return
+	}
+
+	trigger := m.triggerManager.Triggers[index]
+	if err := m.triggerManager.Remove(index); err != nil {
+		m.output = append(m.output, m.colors().Error.Render(fmt.Sprintf("Error removing trigger: %v", err)))
+		return
+	}
+
+	// Save triggers
+	if err := m.triggerManager.Save(); err != nil {
+		m.output = append(m.output, m.colors().Error.Render(fmt.Sprintf("Error saving triggers: %v", err)))
+		return
+	}
+
+	m.output = append(m.output, m.colors().Info.Render(fmt.Sprintf("Removed trigger: \"%s\" -> \"%s\"", trigger.Pattern, trigger.Action)))
+}
+
+// handleTriggersEnableCommand turns a trigger (1-based index) on or off
+// without removing it
+func (m *Model) handleTriggersEnableCommand(index int, enabled bool) {
+	// Convert from 1-based to 0-based index
+	index--
+
+	if err := m.triggerManager.SetEnabled(index, enabled); err != nil {
+		m.output = append(m.output, m.colors().Error.Render("Error: Invalid trigger index. Use /triggers list to see available triggers."))
+		return
+	}
+
+	if err := m.triggerManager.Save(); err != nil {
+		m.output = append(m.output, m.colors().Error.Render(fmt.Sprintf("Error saving triggers: %v", err)))
+		return
+	}
+
+	trigger := m.triggerManager.Triggers[index]
+	state := "Disabled"
+	if enabled {
+		state = "Enabled"
+	}
+	m.output = append(m.output, m.colors().Info.Render(fmt.Sprintf("%s trigger %d: \"%s\" -> \"%s\"", state, index+1, trigger.Pattern, trigger.Action)))
+	if enabled && !m.triggerManager.Enabled(trigger) {
+		m.output = append(m.output, m.colors().Warn.Render(fmt.Sprintf("Its group %s is disabled; /group enable %s turns it back on.", trigger.Group, trigger.Group)))
+	}
+}
+
+// parseQuotedArgs parses two quoted strings from a command
+func parseQuotedArgs(input string) (string, string, error) {
+	input = strings.TrimSpace(input)
+
+	// Find first quoted string
+	if !strings.HasPrefix(input, "\"") {
+		return "", "", fmt.Errorf("expected quoted pattern")
+	}
+
+	// Find the closing quote for the first string
+	endQuote := 1
+	for endQuote < len(input) {
+		if input[endQuote] == '"' && (endQuote == 1 || input[endQuote-1] != '\\') {
+			break
+		}
+		endQuote++
+	}
+
+	if endQuote >= len(input) {
+		return "", "", fmt.Errorf("unterminated pattern quote")
+	}
+
+	pattern := input[1:endQuote]
+
+	// Find second quoted string
+	rest := strings.TrimSpace(input[endQuote+1:])
+	if !strings.HasPrefix(rest, "\"") {
+		return "", "", fmt.Errorf("expected quoted action")
+	}
+
+	// Find the closing quote for the second string
+	endQuote = 1
+	for endQuote < len(rest) {
+		if rest[endQuote] == '"' && (endQuote == 1 || rest[endQuote-1] != '\\') {
+			break
+		}
+		endQuote++
+	}
+
+	if endQuote >= len(rest) {
+		return "", "", fmt.Errorf("unterminated action quote")
+	}
+
+	action := rest[1:endQuote]
+
+	return pattern, action, nil
+}
+
+// handleTickTriggerCommand handles /ticktrigger command
+func (m *Model) handleTickTriggerCommand(command string) {
+	if m.tickTimerManager == nil {
+		m.output = append(m.output, m.colors().Error.Render("Error: Tick timer not initialized"))
+		return
+	}
+
+	if m.tickTimerManager.TickInterval == 0 {
+		m.output = append(m.output, m.colors().Error.Render("Error: Tick interval not yet detected. Wait for a prompt with T:NN to appear."))
+		return
+	}
+
+	// Parse the command to extract tick time and commands
+	// Expected format: /ticktrigger 5 "cast 'heal'"
+
+	// Remove "/ticktrigger " prefix
+	command = strings.TrimPrefix(command, "ticktrigger ")
+	command = strings.TrimSpace(command)
+
+	// Split into tick time and command string
+	parts := strings.SplitN(command, " ", 2)
+	if len(parts) < 2 {
+		m.output = append(m.output, m.colors().Error.Render("Error: Invalid format"))
+		m.output = append(m.output, m.colors().Warn.Render("Usage: /ticktrigger <tick_time> \"commands\""))
+		m.output = append(m.output, m.colors().Warn.Render("Example: /ticktrigger 5 \"cast 'heal'\""))
+		m.output = append(m.output, m.colors().Warn.Render("Example: /ticktrigger 4 \"cast 'bless';say Ready!\""))
+		return
+	}
+
+	// Parse tick time
+	tickTime := 0
+	_, err := fmt.Sscanf(parts[0], "%d", &tickTime)
+	if err != nil {
+		m.output = append(m.output, m.colors().Error.Render("Error: Invalid tick time"))
+		return
+	}
+
+	// Parse command string (should be quoted)
+	commandStr := strings.TrimSpace(parts[1])
+	if !strings.HasPrefix(commandStr, "\"") || !strings.HasSuffix(commandStr, "\"") {
+		m.output = append(m.output, m.colors().Error.Render("Error: Commands must be quoted"))
+		m.output = append(m.output, m.colors().Warn.Render("Usage: /ticktrigger <tick_time> \"commands\""))
+		return
+	}
+
+	// Remove quotes
+	commandStr = strings.TrimPrefix(commandStr, "\"")
+	commandStr = strings.TrimSuffix(commandStr, "\"")
+
+	// Add the tick trigger
+	if err := m.tickTimerManager.AddTrigger(tickTime, commandStr); err != nil {
+		m.output = append(m.output, m.colors().Error.Render(fmt.Sprintf("Error adding tick trigger: %v", err)))
+		return
+	}
+
+	// Save tick timer config
+	if err := m.tickTimerManager.Save(); err != nil {
+		m.output = append(m.output, m.colors().Error.Render(fmt.Sprintf("Error saving tick triggers: %v", err)))
+		return
+	}
+
+	m.output = append(m.output, m.colors().Info.Render(fmt.Sprintf("Tick trigger added: T:%d -> \"%s\"", tickTime, commandStr)))
+}
+
+// handleTickTriggersCommand handles /ticktriggers list and /ticktriggers remove
+func (m *Model) handleTickTriggersCommand(args []string) {
+	if m.tickTimerManager == nil {
+		m.output = append(m.output, m.colors().Error.Render("Error: Tick timer not initialized"))
+		return
+	}
+
+	if len(args) == 0 {
+		// Default to list
+		m.handleTickTriggersListCommand()
+		return
+	}
+
+	subCmd := strings.ToLower(args[0])
+	switch subCmd {
+	case "list":
+		m.handleTickTriggersListCommand()
+	case "remove":
+		if len(args) < 2 {
+			m.output = append(m.output, m.colors().Error.Render("Usage: /ticktriggers remove <index>"))
+			return
+		}
+		var index int
+		_, err := fmt.Sscanf(args[1], "%d", &index)
+		if err != nil {
+			m.output = append(m.output, m.colors().Error.Render("Error: Invalid index"))
+			return
+		}
+		m.handleTickTriggersRemoveCommand(index)
+	default:
+		m.output = append(m.output, m.colors().Error.Render(fmt.Sprintf("Error: Unknown subcommand '%s'", subCmd)))
+		m.output = append(m.output, m.colors().Warn.Render("Usage: /ticktriggers [list|remove <index>]"))
+	}
+}
+
+// handleTickTriggersListCommand lists all tick triggers
+func (m *Model) handleTickTriggersListCommand() {
+	if m.tickTimerManager.TickInterval == 0 {
+		m.output = append(m.output, m.colors().Warn.Render("Tick interval not yet detected. Wait for a prompt with T:NN to appear."))
+		return
+	}
+
+	if len(m.tickTimerManager.TickTriggers) == 0 {
+		m.output = append(m.output, m.colors().Warn.Render("No tick triggers defined."))
+		m.output = append(m.output, m.colors().Warn.Render("Use /ticktrigger <tick_time> \"commands\" to add a tick trigger."))
+		return
+	}
+
+	m.output = append(m.output, m.colors().Info.Render(fmt.Sprintf("=== Active Tick Triggers (Interval: %ds) ===", m.tickTimerManager.TickInterval)))
+	for i, trigger := range m.tickTimerManager.TickTriggers {
+		m.output = append(m.output, "  "+m.colors().Highlight.Render(fmt.Sprintf("%d. T:%d -> \"%s\"", i+1, trigger.TickTime, trigger.Commands)))
+	}
+
+	// Show current tick time
+	currentTick := m.tickTimerManager.GetCurrentTickTime()
+	if currentTick > 0 {
+		m.output = append(m.output, m.colors().Debug.Render(fmt.Sprintf("(Current estimated tick time: T:%d)", currentTick)))
+	}
+}
+
+// handleTickTriggersRemoveCommand removes a tick trigger by index
+func (m *Model) handleTickTriggersRemoveCommand(index int) {
+	// Convert from 1-based to 0-based index
+	index--
+
+	if index < 0 || index >= len(m.tickTimerManager.TickTriggers) {
+		m.output = append(m.output, m.colors().Error.Render("Error: Invalid trigger index. Use /ticktriggers list to see available triggers."))
+		return
+	}
+
+	trigger := m.tickTimerManager.TickTriggers[index]
+	if err := m.tickTimerManager.RemoveTrigger(index); err != nil {
+		m.output = append(m.output, m.colors().Error.Render(fmt.Sprintf("Error removing tick trigger: %v", err)))
+		return
+	}
+
+	// Save tick timer config
+	if err := m.tickTimerManager.Save(); err != nil {
+		m.output = append(m.output, m.colors().Error.Render(fmt.Sprintf("Error saving tick triggers: %v", err)))
+		return
+	}
+
+	m.output = append(m.output, m.colors().Info.Render(fmt.Sprintf("Removed tick trigger: T:%d -> \"%s\"", trigger.TickTime, trigger.Commands)))
+}
+
+// handleHistorySearchKey handles key inputs when in history search mode
+func (m *Model) handleHistorySearchKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyEsc, tea.KeyCtrlC:
+		// Exit search mode without selecting
+		m.historySearchMode = false
+		m.historySearchQuery = ""
+		m.historySearchResults = []int{}
+		m.historySearchIndex = 0
+		m.updateViewport()
+		return m, nil
+
+	case tea.KeyEnter:
+		// Select current result, exit search mode, and send command immediately
+		if len(m.historySearchResults) > 0 && m.historySearchIndex < len(m.historySearchResults) {
+			resultIdx := m.historySearchResults[m.historySearchIndex]
+			command := m.commandHistory[resultIdx]
+
+			// Exit search mode
+			m.historySearchMode = false
+			m.historySearchQuery = ""
+			m.historySearchResults = []int{}
+			m.historySearchIndex = 0
+
+			// Set the command as current input
+			m.currentInput = command
+			m.cursorPos = len(m.currentInput)
+			m.updateViewport()
+
+			// Send the command immediately (simulate pressing Enter)
+			if m.conn != nil && m.connected {
+				// Add non-empty command to history (unless it's a password prompt)
+				if command != "" && !m.isPasswordPrompt() {
+					// Don't add duplicate consecutive commands
+					if len(m.commandHistory) == 0 || m.commandHistory[len(m.commandHistory)-1] != command {
+						m.commandHistory = append(m.commandHistory, command)
+						// Save to persistent history
+						if m.historyManager != nil {
+							m.historyManager.Add(command)
+							// Save asynchronously to avoid blocking
+							go m.historyManager.Save()
+						}
+					}
+					// Reset history navigation state
+					m.historyIndex = -1
+					m.historySavedInput = ""
+				}
+
+				// Check if this is a client command (starts with /)
+				if strings.HasPrefix(command, "/") {
+					// Save the current prompt line before executing command
+					var savedPrompt string
+					if len(m.output) > 0 {
+						savedPrompt = m.output[len(m.output)-1]
+						// Replace the prompt line with the command
+						m.output[len(m.output)-1] = savedPrompt + m.echoed(command)
+					}
+
+					clientCmd := m.handleClientCommand(command)
+
+					// Add two newlines (empty lines) and restore prompt after command output
+					m.output = append(m.output, "")
+					m.output = append(m.output, "")
+					m.output = append(m.output, savedPrompt)
+
+					m.currentInput = ""
+					m.cursorPos = 0
+					m.updateViewport()
+					return m, clientCmd
+				}
+
+				// Check if this is a movement command
+				if movement := m.movementFor(command); movement != "" {
+					m.pendingMovement = movement
+					m.odometer.Move()
+					// Clear map legend on movement
+					m.mapLegend = nil
+					m.mapLegendRooms = nil
+				}
+
+				// Send command to MUD server
+				m.conn.Send(command)
+
+				// Don't modify m.output here - let the server echo if it wants to
+				// Or we can store the command for display purposes
+				// Don't echo if it's a password prompt
+				if !m.echoSuppressed && !m.isPasswordPrompt() && command != "" {
+					// Add the command as a new line in output (it will show on the prompt line)
+					// This preserves it even when new output arrives
+					if len(m.output) > 0 {
+						// Modify the last line to include the command
+						m.output[len(m.output)-1] = m.output[len(m.output)-1] + m.echoed(command)
+					}
+				} else if (m.echoSuppressed || m.isPasswordPrompt()) && command != "" {
+					// For password input, show obfuscated bullets with random length
+					// Add -3 to +3 random bullets to the actual length to hide true length
+					actualLength := len(command)
+					randomOffset := rand.Intn(7) - 3 // Range: -3 to +3
+					displayLength := actualLength + randomOffset
+					if displayLength < 0 {
+						displayLength = 0
+					}
+					bullets := strings.Repeat("•", displayLength)
+					if len(m.output) > 0 {
+						m.output[len(m.output)-1] = m.output[len(m.output)-1] + bullets
+					}
+				}
 
-		if len(allRooms) == 0 {
-			m.output = append(m.output, "\x1b[93mNo rooms have been explored yet.\x1b[0m")
-			return
+				// Reset input
+				m.currentInput = ""
+				m.cursorPos = 0
+				// Update display immediately
+				m.updateViewport()
+			}
+			return m, nil
 		}
 
-		roomsToDisplay = make([]*mapper.Room, 0, len(allRooms))
-		for _, room := range allRooms {
-			roomsToDisplay = append(roomsToDisplay, room)
-		}
-		headerText = fmt.Sprintf("\x1b[92m=== Known Rooms (%d) ===\x1b[0m", len(roomsToDisplay))
-	} else {
-		// Filter by search terms
-		query := strings.Join(args, " ")
-		roomsToDisplay = m.worldMap.FindRooms(query)
+		// No results, just exit search mode
+		m.historySearchMode = false
+		m.historySearchQuery = ""
+		m.historySearchResults = []int{}
+		m.historySearchIndex = 0
+		m.updateViewport()
+		return m, nil
 
-		if len(roomsToDisplay) == 0 {
-			m.output = append(m.output, fmt.Sprintf("\x1b[93mNo rooms found matching '%s'\x1b[0m", query))
-			return
+	case tea.KeyUp:
+		// Navigate to previous search result
+		if len(m.historySearchResults) > 0 && m.historySearchIndex > 0 {
+			m.historySearchIndex--
+			m.updateViewport()
 		}
+		return m, nil
 
-		headerText = fmt.Sprintf("\x1b[92m=== Rooms matching '%s' (%d) ===\x1b[0m", query, len(roomsToDisplay))
-	}
-
-	m.output = append(m.output, headerText)
-
-	// Sort rooms by durable room number for consistent display
-	sort.Slice(roomsToDisplay, func(i, j int) bool {
-		numI := m.worldMap.GetRoomNumber(roomsToDisplay[i].ID)
-		numJ := m.worldMap.GetRoomNumber(roomsToDisplay[j].ID)
-		return numI < numJ
-	})
-
-	// Store results for later disambiguation
-	m.lastRoomSearch = roomsToDisplay
-
-	// Display rooms with durable numbers
-	for _, room := range roomsToDisplay {
-		exitList := make([]string, 0, len(room.Exits))
-		for dir := range room.Exits {
-			exitList = append(exitList, dir)
+	case tea.KeyDown:
+		// Navigate to next search result
+		if len(m.historySearchResults) > 0 && m.historySearchIndex < len(m.historySearchResults)-1 {
+			m.historySearchIndex++
+			m.updateViewport()
 		}
-		sort.Strings(exitList)
+		return m, nil
 
-		exitsStr := strings.Join(exitList, ", ")
-		if exitsStr == "" {
-			exitsStr = "none"
+	case tea.KeyBackspace:
+		// Remove last character from search query
+		if len(m.historySearchQuery) > 0 {
+			m.historySearchQuery = m.historySearchQuery[:len(m.historySearchQuery)-1]
+			m.updateHistorySearch()
+			m.updateViewport()
 		}
+		return m, nil
 
-		// Use durable room number
-		roomNum := m.worldMap.GetRoomNumber(room.ID)
-		m.output = append(m.output, fmt.Sprintf("  \x1b[96m%d. %s\x1b[0m \x1b[90m[%s]\x1b[0m", roomNum, room.Title, exitsStr))
-	}
-}
-
-// handleNearbyCommand lists all rooms within 5 steps of current location
-func (m *Model) handleNearbyCommand() {
-	currentRoom := m.worldMap.GetCurrentRoom()
-	if currentRoom == nil {
-		m.output = append(m.output, "\x1b[91mNo current room. You need to be in a mapped location.\x1b[0m")
-		return
-	}
-
-	nearby := m.worldMap.FindNearbyRooms(5)
+	case tea.KeySpace:
+		// Add space to search query for multi-word search
+		m.historySearchQuery += " "
+		m.updateHistorySearch()
+		m.updateViewport()
+		return m, nil
 
-	if len(nearby) == 0 {
-		m.output = append(m.output, "\x1b[93mNo nearby rooms found within 5 steps.\x1b[0m")
-		return
+	case tea.KeyRunes:
+		// Add typed characters to search query
+		m.historySearchQuery += string(msg.Runes)
+		m.updateHistorySearch()
+		m.updateViewport()
+		return m, nil
 	}
 
-	// Get visible room IDs on the map display
-	// Use typical sidebar dimensions for determining visibility
-	visibleRoomIDs := m.worldMap.GetVisibleRoomIDs(30, 15)
-	visibleSet := make(map[string]bool)
-	for _, id := range visibleRoomIDs {
-		visibleSet[id] = true
-	}
+	return m, nil
+}
 
-	// Filter nearby rooms to only those visible on the map
-	var filteredNearby []mapper.NearbyRoom
-	for _, nr := range nearby {
-		if visibleSet[nr.Room.ID] {
-			filteredNearby = append(filteredNearby, nr)
-		}
-	}
+// updateHistorySearch updates the search results based on the current query
+func (m *Model) updateHistorySearch() {
+	m.historySearchResults = []int{}
+	m.historySearchIndex = 0
 
-	if len(filteredNearby) == 0 {
-		m.output = append(m.output, "\x1b[93mNo nearby rooms are currently visible on the map.\x1b[0m")
+	if len(m.commandHistory) == 0 {
 		return
 	}
 
-	m.output = append(m.output, fmt.Sprintf("\x1b[92m=== Nearby Rooms (%d visible on map) ===\x1b[0m", len(filteredNearby)))
-
-	// Build room legend mapping for map display and store rooms for /go
-	m.mapLegend = make(map[string]int)
-	m.mapLegendRooms = make([]*mapper.Room, 0, len(filteredNearby))
+	query := strings.ToLower(m.historySearchQuery)
 
-	currentDistance := -1
-	for i, nr := range filteredNearby {
-		// Show distance header when it changes
-		if nr.Distance != currentDistance {
-			currentDistance = nr.Distance
-			stepLabel := "step"
-			if currentDistance > 1 {
-				stepLabel = "steps"
-			}
-			m.output = append(m.output, fmt.Sprintf("\x1b[93m%d %s away:\x1b[0m", currentDistance, stepLabel))
-		}
+	// Split query into individual words
+	words := strings.Fields(query)
 
-		// Get exits for display
-		exitList := make([]string, 0, len(nr.Room.Exits))
-		for dir := range nr.Room.Exits {
-			exitList = append(exitList, dir)
-		}
-		sort.Strings(exitList)
+	// Search through history in reverse order (most recent first)
+	for i := len(m.commandHistory) - 1; i >= 0; i-- {
+		cmd := strings.ToLower(m.commandHistory[i])
 
-		exitsStr := strings.Join(exitList, ", ")
-		if exitsStr == "" {
-			exitsStr = "none"
+		// Multi-word search: all words must be present (in any order)
+		if len(words) == 0 {
+			// Empty query matches all commands
+			m.historySearchResults = append(m.historySearchResults, i)
+		} else {
+			// Check if all words are present in the command
+			allWordsMatch := true
+			for _, word := range words {
+				if !strings.Contains(cmd, word) {
+					allWordsMatch = false
+					break
+				}
+			}
+			if allWordsMatch {
+				m.historySearchResults = append(m.historySearchResults, i)
+			}
 		}
-
-		m.output = append(m.output, fmt.Sprintf("  \x1b[96m%d. %s\x1b[0m \x1b[90m[%s]\x1b[0m", i+1, nr.Room.Title, exitsStr))
-
-		// Add to legend mapping and store room
-		m.mapLegend[nr.Room.ID] = i + 1
-		m.mapLegendRooms = append(m.mapLegendRooms, nr.Room)
 	}
 }
 
-// handleLegendCommand lists all rooms currently on the map using durable room numbers
-func (m *Model) handleLegendCommand() {
-	allRooms := m.worldMap.GetAllRooms()
+// handleAliasCommand adds a new alias
+func (m *Model) handleAliasCommand(command string) {
+	// Parse the command to extract name and template
+	// Expected format: /alias "name" "template"
 
-	if len(allRooms) == 0 {
-		m.output = append(m.output, "\x1b[93mNo rooms have been explored yet.\x1b[0m")
-		return
-	}
+	// Remove "/alias " prefix
+	command = strings.TrimPrefix(command, "alias ")
+	command = strings.TrimSpace(command)
 
-	// Get visible room IDs on the map display
-	// Use typical sidebar dimensions for determining visibility
-	visibleRoomIDs := m.worldMap.GetVisibleRoomIDs(30, 15)
-	visibleSet := make(map[string]bool)
-	for _, id := range visibleRoomIDs {
-		visibleSet[id] = true
+	// -group <name> puts the alias in a group that /group can turn on and off
+	group := ""
+	if fields := strings.Fields(command); len(fields) > 0 && fields[0] == "-group" {
+		if len(fields) < 2 || strings.HasPrefix(fields[1], "\"") {
+			m.output = append(m.output, m.colors().Error.Render("Error: -group needs a group name"))
+			return
+		}
+		group = fields[1]
+		command = strings.TrimSpace(strings.TrimPrefix(command, "-group"))
+		command = strings.TrimSpace(strings.TrimPrefix(command, fields[1]))
 	}
 
-	// Build list of visible rooms with their durable numbers
-	type roomWithNumber struct {
-		room   *mapper.Room
-		number int
+	// Parse quoted strings
+	name, template, err := parseQuotedArgs(command)
+	if err != nil {
+		m.output = append(m.output, m.colors().Error.Render(fmt.Sprintf("Error: %v", err)))
+		m.output = append(m.output, m.colors().Warn.Render("Usage: /alias [-group <name>] \"name\" \"template\""))
+		m.output = append(m.output, m.colors().Warn.Render("Example: /alias \"gat\" \"give all <target>\""))
+		m.output = append(m.output, m.colors().Warn.Render("Example: /alias \"gt\" \"give <object> <target>\""))
+		return
 	}
-	var visibleRooms []roomWithNumber
 
-	// Iterate through room numbering to maintain order
-	for _, roomID := range m.worldMap.RoomNumbering {
-		if visibleSet[roomID] {
-			if room := m.worldMap.Rooms[roomID]; room != nil {
-				number := m.worldMap.GetRoomNumber(roomID)
-				visibleRooms = append(visibleRooms, roomWithNumber{room: room, number: number})
-			}
-		}
+	// Add the alias
+	alias, err := m.aliasManager.AddToGroup(name, template, group)
+	if err != nil {
+		m.output = append(m.output, m.colors().Error.Render(fmt.Sprintf("Error adding alias: %v", err)))
+		return
 	}
 
-	if len(visibleRooms) == 0 {
-		m.output = append(m.output, "\x1b[93mNo rooms are currently visible on the map.\x1b[0m")
+	// Save aliases
+	if err := m.aliasManager.Save(); err != nil {
+		m.output = append(m.output, m.colors().Error.Render(fmt.Sprintf("Error saving aliases: %v", err)))
 		return
 	}
 
-	m.output = append(m.output, fmt.Sprintf("\x1b[92m=== Rooms on Map (%d visible) ===\x1b[0m", len(visibleRooms)))
+	m.output = append(m.output, m.colors().Info.Render(fmt.Sprintf("Alias added: \"%s\" -> \"%s\"%s", alias.Name, alias.Template, formatGroup(alias.Group))))
+}
 
-	// Build room legend mapping for map display using durable numbers
-	m.mapLegend = make(map[string]int)
-	m.mapLegendRooms = make([]*mapper.Room, 0)
+// formatGroup shows an alias's group in listings, or "" without one
+func formatGroup(group string) string {
+	if group == "" {
+		return ""
+	}
+	return fmt.Sprintf(" [group %s]", group)
+}
 
-	// Track mapping from display position to room for /go command
-	displayPosToRoom := make(map[int]*mapper.Room)
+// handleAliasesCommand handles /aliases list and /aliases remove
+func (m *Model) handleAliasesCommand(args []string) {
+	if len(args) == 0 {
+		// Default to list
+		m.handleAliasesListCommand()
+		return
+	}
 
-	for i, rn := range visibleRooms {
-		// Get exits for display
-		exitList := make([]string, 0, len(rn.room.Exits))
-		for dir := range rn.room.Exits {
-			exitList = append(exitList, dir)
+	subCmd := strings.ToLower(args[0])
+	switch subCmd {
+	case "list":
+		m.handleAliasesListCommand()
+	case "remove":
+		if len(args) < 2 {
+			m.output = append(m.output, m.colors().Error.Render("Usage: /aliases remove <index>"))
+			return
 		}
-		sort.Strings(exitList)
-
-		exitsStr := strings.Join(exitList, ", ")
-		if exitsStr == "" {
-			exitsStr = "none"
+		var index int
+		_, err := fmt.Sscanf(args[1], "%d", &index)
+		if err != nil {
+			m.output = append(m.output, m.colors().Error.Render("Error: Invalid index"))
+			return
 		}
+		m.handleAliasesRemoveCommand(index)
+	default:
+		m.output = append(m.output, m.colors().Error.Render(fmt.Sprintf("Error: Unknown subcommand '%s'", subCmd)))
+		m.output = append(m.output, m.colors().Warn.Render("Usage: /aliases [list|remove <index>]"))
+	}
+}
 
-		// Display with durable room number
-		m.output = append(m.output, fmt.Sprintf("  \x1b[96m%d. %s\x1b[0m \x1b[90m[%s]\x1b[0m", rn.number, rn.room.Title, exitsStr))
-
-		// Use durable number for legend mapping
-		m.mapLegend[rn.room.ID] = rn.number
-
-		// Map display position (1-indexed) to room for /go command
-		displayPosToRoom[i+1] = rn.room
+// handleAliasesListCommand lists all aliases
+func (m *Model) handleAliasesListCommand() {
+	if len(m.aliasManager.Aliases) == 0 {
+		m.output = append(m.output, m.colors().Warn.Render("No aliases defined."))
+		m.output = append(m.output, m.colors().Warn.Render("Use /alias \"name\" \"template\" to add an alias."))
+		return
 	}
 
-	// Store rooms in display order for /go to use
-	for i := 1; i <= len(displayPosToRoom); i++ {
-		if room, ok := displayPosToRoom[i]; ok {
-			m.mapLegendRooms = append(m.mapLegendRooms, room)
+	m.output = append(m.output, m.colors().Info.Render("=== Active Aliases ==="))
+	for i, alias := range m.aliasManager.Aliases {
+		disabled := ""
+		if !m.aliasManager.GroupEnabled(alias.Group) {
+			disabled = " " + m.colors().Debug.Render("(disabled)")
 		}
+		m.output = append(m.output, "  "+m.colors().Highlight.Render(fmt.Sprintf("%d. \"%s\" -> \"%s\"%s%s", i+1, alias.Name, alias.Template, formatGroup(alias.Group), disabled)))
 	}
 }
 
-// handleGoCommand starts auto-walking to a destination
-func (m *Model) handleGoCommand(args []string) tea.Cmd {
-	// If no args provided
-	if len(args) == 0 {
-		// If auto-walking or queue is active, stop it
-		if m.autoWalking || m.commandQueueActive || len(m.pendingCommands) > 0 {
-			m.stopCommandQueue()
-			m.output = append(m.output, "\x1b[93mAuto-walk cancelled.\x1b[0m")
-			return nil
-		}
-		// Otherwise show usage
-		m.output = append(m.output, "\x1b[91mUsage: /go <room search terms> or /go <number> [search terms]\x1b[0m")
-		return nil
+// handleGroupCommand lists alias and trigger groups and turns a whole group
+// on or off
+func (m *Model) handleGroupCommand(args []string) {
+	if len(args) == 0 || strings.ToLower(args[0]) == "list" {
+		m.handleGroupListCommand()
+		return
 	}
 
-	// If already auto-walking or queue is active, stop it first
-	if m.autoWalking || m.commandQueueActive || len(m.pendingCommands) > 0 {
-		m.stopCommandQueue()
-		m.output = append(m.output, "\x1b[93mAuto-walk cancelled. Start a new /go to navigate.\x1b[0m")
-		return nil
+	subCmd := strings.ToLower(args[0])
+	if (subCmd != "enable" && subCmd != "disable") || len(args) != 2 {
+		m.output = append(m.output, m.colors().Warn.Render("Usage: /group [list] | /group enable <name> | /group disable <name>"))
+		return
 	}
+	group := args[1]
 
-	var rooms []*mapper.Room
-	var query string
-
-	// Check if first argument is a number for room selection
-	if roomNum, err := fmt.Sscanf(args[0], "%d", new(int)); err == nil && roomNum == 1 {
-		var index int
-		fmt.Sscanf(args[0], "%d", &index)
-
-		// If only a number is provided, try different sources
-		if len(args) == 1 {
-			// Try mapLegendRooms first (from /nearby - temporary numbers)
-			if len(m.mapLegendRooms) > 0 {
-				if index < 1 || index > len(m.mapLegendRooms) {
-					m.output = append(m.output, fmt.Sprintf("\x1b[91mInvalid room number. Must be between 1 and %d.\x1b[0m", len(m.mapLegendRooms)))
-					return nil
-				}
-				rooms = []*mapper.Room{m.mapLegendRooms[index-1]}
-			} else if len(m.lastRoomSearch) > 0 {
-				// Use lastRoomSearch from /wayfind, /go, /point disambiguation lists
-				// Treat the number as a simple list index
-				if index < 1 || index > len(m.lastRoomSearch) {
-					m.output = append(m.output, fmt.Sprintf("\x1b[91mInvalid room number. Must be between 1 and %d.\x1b[0m", len(m.lastRoomSearch)))
-					return nil
-				}
-				rooms = []*mapper.Room{m.lastRoomSearch[index-1]}
-			} else {
-				// Try durable room number lookup as last resort
-				room := m.worldMap.GetRoomByNumber(index)
-				if room != nil {
-					rooms = []*mapper.Room{room}
-				} else {
-					m.output = append(m.output, "\x1b[91mNo previous room search to select from. Use /rooms, /nearby, or /legend to see room listings.\x1b[0m")
-					return nil
-				}
-			}
-		} else {
-			// Number followed by search terms - search first, then select by index
-			query = strings.Join(args[1:], " ")
-			allMatches := m.worldMap.FindRooms(query)
+	aliasCount, triggerCount := m.groupMembers(group)
+	if aliasCount == 0 && triggerCount == 0 {
+		m.output = append(m.output, m.colors().Error.Render(fmt.Sprintf("Error: No aliases or triggers in group '%s'", group)))
+		return
+	}
 
-			if len(allMatches) == 0 {
-				m.output = append(m.output, fmt.Sprintf("\x1b[91mNo rooms found matching '%s'\x1b[0m", query))
-				return nil
-			}
+	enabled := subCmd == "enable"
+	m.aliasManager.SetGroupEnabled(group, enabled)
+	m.triggerManager.SetGroupEnabled(group, enabled)
+	if err := m.aliasManager.Save(); err != nil {
+		m.output = append(m.output, m.colors().Error.Render(fmt.Sprintf("Error saving aliases: %v", err)))
+		return
+	}
+	if err := m.triggerManager.Save(); err != nil {
+		m.output = append(m.output, m.colors().Error.Render(fmt.Sprintf("Error saving triggers: %v", err)))
+		return
+	}
 
-			if index < 1 || index > len(allMatches) {
-				m.output = append(m.output, fmt.Sprintf("\x1b[91mInvalid room number. Found %d rooms matching '%s'. Must be between 1 and %d.\x1b[0m", len(allMatches), query, len(allMatches)))
-				return nil
-			}
+	state := "Disabled"
+	if enabled {
+		state = "Enabled"
+	}
+	m.output = append(m.output, m.colors().Info.Render(fmt.Sprintf("%s group '%s' (%d aliases, %d triggers)", state, group, aliasCount, triggerCount)))
+}
 
-			rooms = []*mapper.Room{allMatches[index-1]}
-			m.lastRoomSearch = allMatches
+// groupMembers counts the aliases and triggers in a group
+func (m *Model) groupMembers(group string) (aliasCount, triggerCount int) {
+	for _, alias := range m.aliasManager.Aliases {
+		if alias.Group == group {
+			aliasCount++
+		}
+	}
+	for _, trigger := range m.triggerManager.Triggers {
+		if trigger.Group == group {
+			triggerCount++
 		}
-	} else {
-		// Regular search without numeric selection
-		query = strings.Join(args, " ")
-		rooms = m.worldMap.FindRooms(query)
 	}
+	return aliasCount, triggerCount
+}
 
-	if len(rooms) == 0 {
-		m.output = append(m.output, fmt.Sprintf("\x1b[91mNo rooms found matching '%s'\x1b[0m", query))
-		return nil
+// handleGroupListCommand lists every group with its members and state
+func (m *Model) handleGroupListCommand() {
+	seen := make(map[string]bool)
+	var groups []string
+	for _, group := range append(m.aliasManager.Groups(), m.triggerManager.Groups()...) {
+		if !seen[group] {
+			seen[group] = true
+			groups = append(groups, group)
+		}
 	}
+	sort.Strings(groups)
 
-	if len(rooms) > 1 {
-		// Store results for later disambiguation
-		m.lastRoomSearch = rooms
+	if len(groups) == 0 {
+		m.output = append(m.output, m.colors().Warn.Render("No groups defined."))
+		m.output = append(m.output, m.colors().Warn.Render("Use /alias -group <name> or /trigger -group <name> to add to a group."))
+		return
+	}
 
-		m.output = append(m.output, fmt.Sprintf("\x1b[93mFound %d rooms matching '%s':\x1b[0m", len(rooms), query))
-		for i, room := range rooms {
-			if i >= 5 {
-				m.output = append(m.output, fmt.Sprintf("  \x1b[90m... and %d more\x1b[0m", len(rooms)-5))
-				break
-			}
-			m.output = append(m.output, fmt.Sprintf("  \x1b[96m%d. %s\x1b[0m", i+1, room.Title))
+	m.output = append(m.output, m.colors().Info.Render("=== Groups ==="))
+	for _, group := range groups {
+		aliasCount, triggerCount := m.groupMembers(group)
+		state := "enabled"
+		if !m.aliasManager.GroupEnabled(group) || !m.triggerManager.GroupEnabled(group) {
+			state = "disabled"
 		}
-		m.output = append(m.output, "\x1b[93mPlease be more specific, or use /go <number> to select a room.\x1b[0m")
-		return nil
+		m.output = append(m.output, fmt.Sprintf("  %s - %d aliases, %d triggers (%s)", m.colors().Highlight.Render(group), aliasCount, triggerCount, state))
 	}
+}
 
-	// Find path to the room
-	targetRoom := rooms[0]
-	path := m.worldMap.FindPath(targetRoom.ID)
+// handleAliasesRemoveCommand removes an alias by index
+func (m *Model) handleAliasesRemoveCommand(index int) {
+	// Convert from 1-based to 0-based index
+	index--
 
-	if path == nil {
-		m.output = append(m.output, fmt.Sprintf("\x1b[91mNo path found to '%s'\x1b[0m", targetRoom.Title))
-		return nil
+	if index < 0 || index >= len(m.aliasManager.Aliases) {
+		m.output = append(m.output, m.colors().Error.Render("Error: Invalid alias index. Use /aliases list to see available aliases."))
+		return
 	}
 
-	if len(path) == 0 {
-		m.output = append(m.output, "\x1b[92mYou are already at that location!\x1b[0m")
-		return nil
+	alias := m.aliasManager.Aliases[index]
+	if err := m.aliasManager.Remove(index); err != nil {
+		m.output = append(m.output, m.colors().Error.Render(fmt.Sprintf("Error removing alias: %v", err)))
+		return
 	}
 
-	// Enqueue the path commands
-	m.autoWalking = true // Keep this for compatibility with failure detection
-	m.autoWalkPath = path
-	m.autoWalkIndex = 0
-	m.autoWalkTarget = targetRoom.Title // Store target for recovery
-	m.output = append(m.output, fmt.Sprintf("\x1b[92mAuto-walking to '%s' (%d steps). Type /stop to cancel.\x1b[0m", targetRoom.Title, len(path)))
+	// Save aliases
+	if err := m.aliasManager.Save(); err != nil {
+		m.output = append(m.output, m.colors().Error.Render(fmt.Sprintf("Error saving aliases: %v", err)))
+		return
+	}
 
-	// Enqueue all the movement commands
-	return m.enqueueCommands(path)
+	m.output = append(m.output, m.colors().Info.Render(fmt.Sprintf("Removed alias: \"%s\" -> \"%s\"", alias.Name, alias.Template)))
 }
 
-// handleStopCommand stops any pending command queue and auto-walking
-func (m *Model) handleStopCommand() {
-	if m.commandQueueActive || m.autoWalking || len(m.pendingCommands) > 0 {
-		m.stopCommandQueue()
-		m.output = append(m.output, "\x1b[93mCommand queue and auto-walking stopped.\x1b[0m")
-	} else {
-		m.output = append(m.output, "\x1b[93mNo active command queue or auto-walking to stop.\x1b[0m")
+// substituteOutgoing rewrites the start of a command about to be sent using
+// the substitution with the longest matching prefix
+func (m *Model) substituteOutgoing(command string) string {
+	if m.substitutionManager == nil {
+		return command
 	}
+	substituted, _ := m.substitutionManager.Apply(command)
+	return substituted
 }
 
-// handleAutoWalkFailure handles a failed movement during auto-walk
-func (m *Model) handleAutoWalkFailure() tea.Cmd {
-	if !m.autoWalking {
-		return nil
+// handleSubCommand adds or replaces an outgoing substitution
+func (m *Model) handleSubCommand(command string) {
+	if m.substitutionManager == nil {
+		m.substitutionManager = substitutions.NewManager()
 	}
 
-	// Get the last direction we tried to go
-	lastDirection := ""
-	if m.autoWalkIndex > 0 && m.autoWalkIndex <= len(m.autoWalkPath) {
-		lastDirection = m.autoWalkPath[m.autoWalkIndex-1]
+	// Expected format: /sub "prefix" "replacement"
+	command = strings.TrimSpace(strings.TrimPrefix(command, "sub "))
+
+	prefix, replacement, err := parseQuotedArgs(command)
+	if err != nil {
+		m.output = append(m.output, m.colors().Error.Render(fmt.Sprintf("Error: %v", err)))
+		m.output = append(m.output, m.colors().Warn.Render("Usage: /sub \"prefix\" \"replacement\""))
+		m.output = append(m.output, m.colors().Warn.Render("Example: /sub \"'\" \"say \""))
+		return
 	}
 
-	m.output = append(m.output, "\x1b[91m[Auto-walk: Movement failed - cannot go that way]\x1b[0m")
+	sub, err := m.substitutionManager.Set(prefix, replacement)
+	if err != nil {
+		m.output = append(m.output, m.colors().Error.Render(fmt.Sprintf("Error adding substitution: %v", err)))
+		return
+	}
 
-	// Remove the exit from the current room first, before replanning
-	if lastDirection != "" && m.worldMap.GetCurrentRoom() != nil {
-		currentRoom := m.worldMap.GetCurrentRoom()
-		m.output = append(m.output, fmt.Sprintf("\x1b[93m[Auto-walk: Removing invalid exit '%s' from current room]\x1b[0m", lastDirection))
-		currentRoom.RemoveExit(lastDirection)
-		m.worldMap.Save()
+	if err := m.substitutionManager.Save(); err != nil {
+		m.output = append(m.output, m.colors().Error.Render(fmt.Sprintf("Error saving substitutions: %v", err)))
+		return
 	}
 
-	// Stop current auto-walk and clear command queue
-	targetTitle := m.autoWalkTarget
-	m.autoWalking = false
-	m.autoWalkPath = nil
-	m.autoWalkIndex = 0
-	m.autoWalkTarget = ""
-	m.pendingCommands = nil // Clear the remaining queued commands
-	m.commandQueueActive = false
+	m.output = append(m.output, m.colors().Info.Render(fmt.Sprintf("Substitution added: \"%s\" -> \"%s\"", sub.Prefix, sub.Replacement)))
+}
 
-	// Try to replan the route to the same destination
-	if targetTitle != "" {
-		m.output = append(m.output, fmt.Sprintf("\x1b[93m[Auto-walk: Re-planning route to '%s']\x1b[0m", targetTitle))
+// handleSubsCommand handles /subs list and /subs remove
+func (m *Model) handleSubsCommand(args []string) {
+	if m.substitutionManager == nil {
+		m.substitutionManager = substitutions.NewManager()
+	}
 
-		// Find the target room again
-		rooms := m.worldMap.FindRooms(targetTitle)
-		if len(rooms) == 0 {
-			m.output = append(m.output, fmt.Sprintf("\x1b[91m[Auto-walk: Cannot find target room '%s']\x1b[0m", targetTitle))
-			return nil
+	if len(args) == 0 || strings.ToLower(args[0]) == "list" {
+		if len(m.substitutionManager.Substitutions) == 0 {
+			m.output = append(m.output, m.colors().Warn.Render("No substitutions defined."))
+			m.output = append(m.output, m.colors().Warn.Render("Use /sub \"prefix\" \"replacement\" to add a substitution."))
+			return
 		}
-
-		// Find a new path
-		targetRoom := rooms[0]
-		path := m.worldMap.FindPath(targetRoom.ID)
-
-		if path == nil || len(path) == 0 {
-			m.output = append(m.output, fmt.Sprintf("\x1b[91m[Auto-walk: No valid path found to '%s']\x1b[0m", targetTitle))
-			return nil
+		m.output = append(m.output, m.colors().Info.Render("=== Active Substitutions ==="))
+		for i, sub := range m.substitutionManager.Substitutions {
+			m.output = append(m.output, "  "+m.colors().Highlight.Render(fmt.Sprintf("%d. \"%s\" -> \"%s\"", i+1, sub.Prefix, sub.Replacement)))
 		}
+		return
+	}
 
-		// Restart auto-walking with the new path
-		m.autoWalking = true
-		m.autoWalkPath = path
-		m.autoWalkIndex = 0
-		m.autoWalkTarget = targetTitle
-		m.output = append(m.output, fmt.Sprintf("\x1b[92m[Auto-walk: Restarting with new route (%d steps)]\x1b[0m", len(path)))
-
-		// Enqueue the new path
-		return m.enqueueCommands(path)
+	if strings.ToLower(args[0]) != "remove" {
+		m.output = append(m.output, m.colors().Error.Render(fmt.Sprintf("Error: Unknown subcommand '%s'", args[0])))
+		m.output = append(m.output, m.colors().Warn.Render("Usage: /subs [list|remove <index>]"))
+		return
+	}
+	if len(args) < 2 {
+		m.output = append(m.output, m.colors().Error.Render("Usage: /subs remove <index>"))
+		return
+	}
+	var index int
+	if _, err := fmt.Sscanf(args[1], "%d", &index); err != nil {
+		m.output = append(m.output, m.colors().Error.Render("Error: Invalid index"))
+		return
 	}
 
-	return nil
+	// Convert from 1-based to 0-based index
+	index--
+	if index < 0 || index >= len(m.substitutionManager.Substitutions) {
+		m.output = append(m.output, m.colors().Error.Render("Error: Invalid substitution index. Use /subs list to see available substitutions."))
+		return
+	}
+	sub := m.substitutionManager.Substitutions[index]
+	if err := m.substitutionManager.Remove(index); err != nil {
+		m.output = append(m.output, m.colors().Error.Render(fmt.Sprintf("Error removing substitution: %v", err)))
+		return
+	}
+	if err := m.substitutionManager.Save(); err != nil {
+		m.output = append(m.output, m.colors().Error.Render(fmt.Sprintf("Error saving substitutions: %v", err)))
+		return
+	}
+	m.output = append(m.output, m.colors().Info.Render(fmt.Sprintf("Removed substitution: \"%s\" -> \"%s\"", sub.Prefix, sub.Replacement)))
 }
 
-// enqueueCommands adds commands to the pending queue and starts processing if not already active
-// Commands should be split on `;` before calling this function
-func (m *Model) enqueueCommands(commands []string) tea.Cmd {
-	// Add commands to the queue
-	m.pendingCommands = append(m.pendingCommands, commands...)
-
-	// If queue is not already active, start processing
-	if !m.commandQueueActive && len(m.pendingCommands) > 0 {
-		m.commandQueueActive = true
-		return tea.Tick(time.Second, func(t time.Time) tea.Msg {
-			return commandQueueTickMsg{}
-		})
+// detectCaptures fills the /capture records from a line, showing each
+// record in a sidebar panel
+func (m *Model) detectCaptures(cleanLine string) {
+	if m.captureManager == nil {
+		return
 	}
+	for _, match := range m.captureManager.Match(cleanLine) {
+		if m.captureRecords == nil {
+			m.captureRecords = make(map[string]*captures.Record)
+		}
+		record := m.captureRecords[match.Name]
+		if record == nil {
+			record = &captures.Record{}
+			m.captureRecords[match.Name] = record
+		}
+		record.Set(match.Key, match.Text)
 
-	return nil
-}
-
-// stopCommandQueue clears the command queue and stops auto-walking
-func (m *Model) stopCommandQueue() {
-	m.pendingCommands = nil
-	m.commandQueueActive = false
-	m.autoWalking = false
-	m.autoWalkPath = nil
-	m.autoWalkIndex = 0
-	m.autoWalkTarget = ""
+		if m.userPanels == nil {
+			m.userPanels = make(map[string][]string)
+		}
+		m.userPanels[captures.PanelTitle(match.Name)] = record.Lines()
+	}
 }
 
-// handleTriggerCommand adds a new trigger
-func (m *Model) handleTriggerCommand(command string) {
-	// Parse the command to extract pattern and action
-	// Expected format: /trigger "pattern" "action"
+// handleCaptureCommand adds a capture filling a named record
+func (m *Model) handleCaptureCommand(command string) {
+	if m.captureManager == nil {
+		m.captureManager = captures.NewManager()
+	}
 
-	// Remove "/trigger " prefix
-	command = strings.TrimPrefix(command, "trigger ")
-	command = strings.TrimSpace(command)
+	// Expected format: /capture <name> "pattern" ["format"]
+	command = strings.TrimSpace(strings.TrimPrefix(command, "capture"))
+	name, rest, _ := strings.Cut(command, " ")
+	rest = strings.TrimSpace(rest)
 
-	// Parse quoted strings
-	pattern, action, err := parseQuotedArgs(command)
+	// The format is optional, so a lone pattern gets an empty one
+	pattern, format, err := parseQuotedArgs(rest)
 	if err != nil {
-		m.output = append(m.output, fmt.Sprintf("\x1b[91mError: %v\x1b[0m", err))
-		m.output = append(m.output, "\x1b[93mUsage: /trigger \"pattern\" \"action\"\x1b[0m")
-		m.output = append(m.output, "\x1b[93mExample: /trigger \"hungry\" \"eat bread\"\x1b[0m")
-		m.output = append(m.output, "\x1b[93mExample: /trigger \"The <subject> dies\" \"get <subject>\"\x1b[0m")
+		pattern, format, err = parseQuotedArgs(rest + ` ""`)
+	}
+	if name == "" || err != nil {
+		if name != "" {
+			m.output = append(m.output, m.colors().Error.Render(fmt.Sprintf("Error: %v", err)))
+		}
+		m.output = append(m.output, m.colors().Warn.Render("Usage: /capture <name> \"pattern\" [\"format\"]"))
+		m.output = append(m.output, m.colors().Warn.Render("Example: /capture quest \"Quest: (.+) - (\\d+)/(\\d+)\" \"<1>: <2>/<3>\""))
 		return
 	}
 
-	// Add the trigger
-	trigger, err := m.triggerManager.Add(pattern, action)
+	capture, err := m.captureManager.Add(name, pattern, format)
 	if err != nil {
-		m.output = append(m.output, fmt.Sprintf("\x1b[91mError adding trigger: %v\x1b[0m", err))
+		m.output = append(m.output, m.colors().Error.Render(fmt.Sprintf("Error adding capture: %v", err)))
 		return
 	}
 
-	// Save triggers
-	if err := m.triggerManager.Save(); err != nil {
-		m.output = append(m.output, fmt.Sprintf("\x1b[91mError saving triggers: %v\x1b[0m", err))
+	if err := m.captureManager.Save(); err != nil {
+		m.output = append(m.output, m.colors().Error.Render(fmt.Sprintf("Error saving captures: %v", err)))
 		return
 	}
 
-	m.output = append(m.output, fmt.Sprintf("\x1b[92mTrigger added: \"%s\" -> \"%s\"\x1b[0m", trigger.Pattern, trigger.Action))
+	m.output = append(m.output, m.colors().Info.Render(fmt.Sprintf("Capture added: %s \"%s\" (shown in the %s panel)", capture.Name, capture.Pattern, captures.PanelTitle(capture.Name))))
 }
 
-// handleTriggersCommand handles /triggers list and /triggers remove
-func (m *Model) handleTriggersCommand(args []string) {
-	if len(args) == 0 {
-		// Default to list
-		m.handleTriggersListCommand()
-		return
+// handleCapturesCommand handles /captures list, /captures remove and
+// /captures clear
+func (m *Model) handleCapturesCommand(args []string) {
+	if m.captureManager == nil {
+		m.captureManager = captures.NewManager()
 	}
 
-	subCmd := strings.ToLower(args[0])
-	switch subCmd {
-	case "list":
-		m.handleTriggersListCommand()
-	case "remove":
-		if len(args) < 2 {
-			m.output = append(m.output, "\x1b[91mUsage: /triggers remove <index>\x1b[0m")
+	if len(args) == 0 || strings.ToLower(args[0]) == "list" {
+		if len(m.captureManager.Captures) == 0 {
+			m.output = append(m.output, m.colors().Warn.Render("No captures defined."))
+			m.output = append(m.output, m.colors().Warn.Render("Use /capture <name> \"pattern\" [\"format\"] to add a capture."))
 			return
 		}
-		var index int
-		_, err := fmt.Sscanf(args[1], "%d", &index)
-		if err != nil {
-			m.output = append(m.output, "\x1b[91mError: Invalid index\x1b[0m")
-			return
+		m.output = append(m.output, m.colors().Info.Render("=== Active Captures ==="))
+		for i, capture := range m.captureManager.Captures {
+			line := fmt.Sprintf("%d. %s \"%s\"", i+1, capture.Name, capture.Pattern)
+			if capture.Format != "" {
+				line += fmt.Sprintf(" -> \"%s\"", capture.Format)
+			}
+			if record := m.captureRecords[capture.Name]; record != nil {
+				line += fmt.Sprintf(" [%d entries]", len(record.Keys))
+			}
+			m.output = append(m.output, "  "+m.colors().Highlight.Render(line))
 		}
-		m.handleTriggersRemoveCommand(index)
-	default:
-		m.output = append(m.output, fmt.Sprintf("\x1b[91mError: Unknown subcommand '%s'\x1b[0m", subCmd))
-		m.output = append(m.output, "\x1b[93mUsage: /triggers [list|remove <index>]\x1b[0m")
+		return
 	}
-}
 
-// handleTriggersListCommand lists all triggers
-func (m *Model) handleTriggersListCommand() {
-	if len(m.triggerManager.Triggers) == 0 {
-		m.output = append(m.output, "\x1b[93mNo triggers defined.\x1b[0m")
-		m.output = append(m.output, "\x1b[93mUse /trigger \"pattern\" \"action\" to add a trigger.\x1b[0m")
+	if strings.ToLower(args[0]) == "clear" {
+		// Forget what has been captured, keeping the captures themselves
+		if len(args) < 2 {
+			m.output = append(m.output, m.colors().Error.Render("Usage: /captures clear <name>"))
+			return
+		}
+		name := args[1]
+		delete(m.captureRecords, name)
+		delete(m.userPanels, captures.PanelTitle(name))
+		m.output = append(m.output, m.colors().Info.Render(fmt.Sprintf("Cleared the %s record.", name)))
 		return
 	}
 
-	m.output = append(m.output, "\x1b[92m=== Active Triggers ===\x1b[0m")
-	for i, trigger := range m.triggerManager.Triggers {
-		m.output = append(m.output, fmt.Sprintf("  \x1b[96m%d. \"%s\" -> \"%s\"\x1b[0m", i+1, trigger.Pattern, trigger.Action))
+	if strings.ToLower(args[0]) != "remove" {
+		m.output = append(m.output, m.colors().Error.Render(fmt.Sprintf("Error: Unknown subcommand '%s'", args[0])))
+		m.output = append(m.output, m.colors().Warn.Render("Usage: /captures [list|remove <index>|clear <name>]"))
+		return
+	}
+	if len(args) < 2 {
+		m.output = append(m.output, m.colors().Error.Render("Usage: /captures remove <index>"))
+		return
+	}
+	var index int
+	if _, err := fmt.Sscanf(args[1], "%d", &index); err != nil {
+		m.output = append(m.output, m.colors().Error.Render("Error: Invalid index"))
+		return
 	}
-}
 
-// handleTriggersRemoveCommand removes a trigger by index
-func (m *Model) handleTriggersRemoveCommand(index int) {
 	// Convert from 1-based to 0-based index
 	index--
-
-	if index < 0 || index >= len(m.triggerManager.Triggers) {
-		m.output = append(m.output, fmt.Sprintf("\x1b[91mError: Invalid trigger index. Use /triggers list to see available triggers.\x1b[0m"))
+	if index < 0 || index >= len(m.captureManager.Captures) {
+		m.output = append(m.output, m.colors().Error.Render("Error: Invalid capture index. Use /captures list to see available captures."))
 		return
 	}
-
-	trigger := m.triggerManager.Triggers[index]
-	if err := m.triggerManager.Remove(index); err != nil {
-		m.output = append(m.output, fmt.Sprintf("\x1b[91mError removing trigger: %v\x1b[0m", err))
+	capture := m.captureManager.Captures[index]
+	if err := m.captureManager.Remove(index); err != nil {
+		m.output = append(m.output, m.colors().Error.Render(fmt.Sprintf("Error removing capture: %v", err)))
 		return
 	}
-
-	// Save triggers
-	if err := m.triggerManager.Save(); err != nil {
-		m.output = append(m.output, fmt.Sprintf("\x1b[91mError saving triggers: %v\x1b[0m", err))
+	if err := m.captureManager.Save(); err != nil {
+		m.output = append(m.output, m.colors().Error.Render(fmt.Sprintf("Error saving captures: %v", err)))
 		return
 	}
-
-	m.output = append(m.output, fmt.Sprintf("\x1b[92mRemoved trigger: \"%s\" -> \"%s\"\x1b[0m", trigger.Pattern, trigger.Action))
+	m.output = append(m.output, m.colors().Info.Render(fmt.Sprintf("Removed capture: %s \"%s\"", capture.Name, capture.Pattern)))
 }
 
-// parseQuotedArgs parses two quoted strings from a command
-func parseQuotedArgs(input string) (string, string, error) {
-	input = strings.TrimSpace(input)
-
-	// Find first quoted string
-	if !strings.HasPrefix(input, "\"") {
-		return "", "", fmt.Errorf("expected quoted pattern")
-	}
-
-	// Find the closing quote for the first string
-	endQuote := 1
-	for endQuote < len(input) {
-		if input[endQuote] == '"' && (endQuote == 1 || input[endQuote-1] != '\\') {
-			break
-		}
-		endQuote++
+// handleMacroKey runs the macro bound to a key, if any
+// Returns false if the key is not bound so normal key handling can continue
+func (m *Model) handleMacroKey(key string) (tea.Cmd, bool) {
+	if m.macroManager == nil {
+		return nil, false
 	}
 
-	if endQuote >= len(input) {
-		return "", "", fmt.Errorf("unterminated pattern quote")
+	macro := m.macroManager.Get(key)
+	if macro == nil {
+		return nil, false
 	}
 
-	pattern := input[1:endQuote]
-
-	// Find second quoted string
-	rest := strings.TrimSpace(input[endQuote+1:])
-	if !strings.HasPrefix(rest, "\"") {
-		return "", "", fmt.Errorf("expected quoted action")
+	command := macro.Command
+	if m.aliasManager != nil {
+		if expandedCommand, expanded := m.aliasManager.Expand(command); expanded {
+			command = expandedCommand
+		}
 	}
 
-	// Find the closing quote for the second string
-	endQuote = 1
-	for endQuote < len(rest) {
-		if rest[endQuote] == '"' && (endQuote == 1 || rest[endQuote-1] != '\\') {
-			break
+	// Split on `;` to support multiple commands, like triggers
+	var commands []string
+	for _, cmd := range strings.Split(command, ";") {
+		if cmd = strings.TrimSpace(cmd); cmd != "" {
+			commands = append(commands, cmd)
 		}
-		endQuote++
 	}
-
-	if endQuote >= len(rest) {
-		return "", "", fmt.Errorf("unterminated action quote")
+	if len(commands) == 0 {
+		return nil, true
 	}
 
-	action := rest[1:endQuote]
-
-	return pattern, action, nil
+	m.output = append(m.output, m.colors().Debug.Render(fmt.Sprintf("[Macro %s: %s]", strings.ToUpper(macro.Key), command)))
+	m.updateViewport()
+	return m.enqueueCommands(commands), true
 }
 
-// handleTickTriggerCommand handles /ticktrigger command
-func (m *Model) handleTickTriggerCommand(command string) {
-	if m.tickTimerManager == nil {
-		m.output = append(m.output, "\x1b[91mError: Tick timer not initialized\x1b[0m")
-		return
-	}
-
-	if m.tickTimerManager.TickInterval == 0 {
-		m.output = append(m.output, "\x1b[91mError: Tick interval not yet detected. Wait for a prompt with T:NN to appear.\x1b[0m")
-		return
-	}
-
-	// Parse the command to extract tick time and commands
-	// Expected format: /ticktrigger 5 "cast 'heal'"
+// handleMacroCommand binds a key to a command
+func (m *Model) handleMacroCommand(command string) {
+	// Parse the command to extract key and command string
+	// Expected format: /macro F1 "cast 'cure light' self"
 
-	// Remove "/ticktrigger " prefix
-	command = strings.TrimPrefix(command, "ticktrigger ")
+	// Remove "/macro " prefix
+	command = strings.TrimPrefix(command, "macro")
 	command = strings.TrimSpace(command)
 
-	// Split into tick time and command string
 	parts := strings.SplitN(command, " ", 2)
-	if len(parts) < 2 {
-		m.output = append(m.output, "\x1b[91mError: Invalid format\x1b[0m")
-		m.output = append(m.output, "\x1b[93mUsage: /ticktrigger <tick_time> \"commands\"\x1b[0m")
-		m.output = append(m.output, "\x1b[93mExample: /ticktrigger 5 \"cast 'heal'\"\x1b[0m")
-		m.output = append(m.output, "\x1b[93mExample: /ticktrigger 4 \"cast 'bless';say Ready!\"\x1b[0m")
+	if parts[0] == "" {
+		m.output = append(m.output, m.colors().Error.Render("Error: Invalid format"))
+		m.output = append(m.output, m.colors().Warn.Render("Usage: /macro <key> \"commands\""))
+		m.output = append(m.output, m.colors().Warn.Render("Example: /macro F1 \"cast 'cure light' self\""))
 		return
 	}
 
-	// Parse tick time
-	tickTime := 0
-	_, err := fmt.Sscanf(parts[0], "%d", &tickTime)
+	key, err := macros.NormalizeKey(parts[0])
 	if err != nil {
-		m.output = append(m.output, "\x1b[91mError: Invalid tick time\x1b[0m")
+		m.output = append(m.output, m.colors().Error.Render(fmt.Sprintf("Error: %v", err)))
+		return
+	}
+
+	// With only a key, show the current binding
+	if len(parts) < 2 {
+		if macro := m.macroManager.Get(key); macro != nil {
+			m.output = append(m.output, m.colors().Highlight.Render(fmt.Sprintf("%s -> \"%s\"", strings.ToUpper(macro.Key), macro.Command)))
+		} else {
+			m.output = append(m.output, m.colors().Warn.Render(fmt.Sprintf("%s is not bound.", strings.ToUpper(key))))
+		}
 		return
 	}
 
 	// Parse command string (should be quoted)
 	commandStr := strings.TrimSpace(parts[1])
-	if !strings.HasPrefix(commandStr, "\"") || !strings.HasSuffix(commandStr, "\"") {
-		m.output = append(m.output, "\x1b[91mError: Commands must be quoted\x1b[0m")
-		m.output = append(m.output, "\x1b[93mUsage: /ticktrigger <tick_time> \"commands\"\x1b[0m")
+	if len(commandStr) < 2 || !strings.HasPrefix(commandStr, "\"") || !strings.HasSuffix(commandStr, "\"") {
+		m.output = append(m.output, m.colors().Error.Render("Error: Commands must be quoted"))
+		m.output = append(m.output, m.colors().Warn.Render("Usage: /macro <key> \"commands\""))
 		return
 	}
 
@@ -3178,411 +11750,839 @@ func (m *Model) handleTickTriggerCommand(command string) {
 	commandStr = strings.TrimPrefix(commandStr, "\"")
 	commandStr = strings.TrimSuffix(commandStr, "\"")
 
-	// Add the tick trigger
-	if err := m.tickTimerManager.AddTrigger(tickTime, commandStr); err != nil {
-		m.output = append(m.output, fmt.Sprintf("\x1b[91mError adding tick trigger: %v\x1b[0m", err))
+	macro, err := m.macroManager.Set(key, commandStr)
+	if err != nil {
+		m.output = append(m.output, m.colors().Error.Render(fmt.Sprintf("Error adding macro: %v", err)))
 		return
 	}
 
-	// Save tick timer config
-	if err := m.tickTimerManager.Save(); err != nil {
-		m.output = append(m.output, fmt.Sprintf("\x1b[91mError saving tick triggers: %v\x1b[0m", err))
+	// Save macros
+	if err := m.macroManager.Save(); err != nil {
+		m.output = append(m.output, m.colors().Error.Render(fmt.Sprintf("Error saving macros: %v", err)))
 		return
 	}
 
-	m.output = append(m.output, fmt.Sprintf("\x1b[92mTick trigger added: T:%d -> \"%s\"\x1b[0m", tickTime, commandStr))
+	m.output = append(m.output, m.colors().Info.Render(fmt.Sprintf("Macro added: %s -> \"%s\"", strings.ToUpper(macro.Key), macro.Command)))
 }
 
-// handleTickTriggersCommand handles /ticktriggers list and /ticktriggers remove
-func (m *Model) handleTickTriggersCommand(args []string) {
-	if m.tickTimerManager == nil {
-		m.output = append(m.output, "\x1b[91mError: Tick timer not initialized\x1b[0m")
-		return
-	}
-
+// handleMacrosCommand handles /macros list and /macros remove
+func (m *Model) handleMacrosCommand(args []string) {
 	if len(args) == 0 {
 		// Default to list
-		m.handleTickTriggersListCommand()
+		m.handleMacrosListCommand()
 		return
 	}
 
 	subCmd := strings.ToLower(args[0])
 	switch subCmd {
 	case "list":
-		m.handleTickTriggersListCommand()
+		m.handleMacrosListCommand()
 	case "remove":
 		if len(args) < 2 {
-			m.output = append(m.output, "\x1b[91mUsage: /ticktriggers remove <index>\x1b[0m")
+			m.output = append(m.output, m.colors().Error.Render("Usage: /macros remove <index>"))
 			return
 		}
 		var index int
 		_, err := fmt.Sscanf(args[1], "%d", &index)
 		if err != nil {
-			m.output = append(m.output, "\x1b[91mError: Invalid index\x1b[0m")
+			m.output = append(m.output, m.colors().Error.Render("Error: Invalid index"))
 			return
 		}
-		m.handleTickTriggersRemoveCommand(index)
+		m.handleMacrosRemoveCommand(index)
 	default:
-		m.output = append(m.output, fmt.Sprintf("\x1b[91mError: Unknown subcommand '%s'\x1b[0m", subCmd))
-		m.output = append(m.output, "\x1b[93mUsage: /ticktriggers [list|remove <index>]\x1b[0m")
+		m.output = append(m.output, m.colors().Error.Render(fmt.Sprintf("Error: Unknown subcommand '%s'", subCmd)))
+		m.output = append(m.output, m.colors().Warn.Render("Usage: /macros [list|remove <index>]"))
 	}
 }
 
-// handleTickTriggersListCommand lists all tick triggers
-func (m *Model) handleTickTriggersListCommand() {
-	if m.tickTimerManager.TickInterval == 0 {
-		m.output = append(m.output, "\x1b[93mTick interval not yet detected. Wait for a prompt with T:NN to appear.\x1b[0m")
-		return
-	}
-
-	if len(m.tickTimerManager.TickTriggers) == 0 {
-		m.output = append(m.output, "\x1b[93mNo tick triggers defined.\x1b[0m")
-		m.output = append(m.output, "\x1b[93mUse /ticktrigger <tick_time> \"commands\" to add a tick trigger.\x1b[0m")
+// handleMacrosListCommand lists all macros
+func (m *Model) handleMacrosListCommand() {
+	if len(m.macroManager.Macros) == 0 {
+		m.output = append(m.output, m.colors().Warn.Render("No macros defined."))
+		m.output = append(m.output, m.colors().Warn.Render("Use /macro <key> \"commands\" to bind a key."))
 		return
 	}
 
-	m.output = append(m.output, fmt.Sprintf("\x1b[92m=== Active Tick Triggers (Interval: %ds) ===\x1b[0m", m.tickTimerManager.TickInterval))
-	for i, trigger := range m.tickTimerManager.TickTriggers {
-		m.output = append(m.output, fmt.Sprintf("  \x1b[96m%d. T:%d -> \"%s\"\x1b[0m", i+1, trigger.TickTime, trigger.Commands))
-	}
-
-	// Show current tick time
-	currentTick := m.tickTimerManager.GetCurrentTickTime()
-	if currentTick > 0 {
-		m.output = append(m.output, fmt.Sprintf("\x1b[90m(Current estimated tick time: T:%d)\x1b[0m", currentTick))
+	m.output = append(m.output, m.colors().Info.Render("=== Active Macros ==="))
+	for i, macro := range m.macroManager.Macros {
+		m.output = append(m.output, "  "+m.colors().Highlight.Render(fmt.Sprintf("%d. %s -> \"%s\"", i+1, strings.ToUpper(macro.Key), macro.Command)))
 	}
 }
 
-// handleTickTriggersRemoveCommand removes a tick trigger by index
-func (m *Model) handleTickTriggersRemoveCommand(index int) {
+// handleMacrosRemoveCommand removes a macro by index
+func (m *Model) handleMacrosRemoveCommand(index int) {
 	// Convert from 1-based to 0-based index
 	index--
 
-	if index < 0 || index >= len(m.tickTimerManager.TickTriggers) {
-		m.output = append(m.output, "\x1b[91mError: Invalid trigger index. Use /ticktriggers list to see available triggers.\x1b[0m")
+	if index < 0 || index >= len(m.macroManager.Macros) {
+		m.output = append(m.output, m.colors().Error.Render("Error: Invalid macro index. Use /macros list to see available macros."))
 		return
 	}
 
-	trigger := m.tickTimerManager.TickTriggers[index]
-	if err := m.tickTimerManager.RemoveTrigger(index); err != nil {
-		m.output = append(m.output, fmt.Sprintf("\x1b[91mError removing tick trigger: %v\x1b[0m", err))
+	macro := m.macroManager.Macros[index]
+	if err := m.macroManager.Remove(index); err != nil {
+		m.output = append(m.output, m.colors().Error.Render(fmt.Sprintf("Error removing macro: %v", err)))
 		return
 	}
 
-	// Save tick timer config
-	if err := m.tickTimerManager.Save(); err != nil {
-		m.output = append(m.output, fmt.Sprintf("\x1b[91mError saving tick triggers: %v\x1b[0m", err))
+	// Save macros
+	if err := m.macroManager.Save(); err != nil {
+		m.output = append(m.output, m.colors().Error.Render(fmt.Sprintf("Error saving macros: %v", err)))
 		return
 	}
 
-	m.output = append(m.output, fmt.Sprintf("\x1b[92mRemoved tick trigger: T:%d -> \"%s\"\x1b[0m", trigger.TickTime, trigger.Commands))
-}
-
-// handleHistorySearchKey handles key inputs when in history search mode
-func (m *Model) handleHistorySearchKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
-	switch msg.Type {
-	case tea.KeyEsc, tea.KeyCtrlC:
-		// Exit search mode without selecting
-		m.historySearchMode = false
-		m.historySearchQuery = ""
-		m.historySearchResults = []int{}
-		m.historySearchIndex = 0
-		m.updateViewport()
-		return m, nil
-
-	case tea.KeyEnter:
-		// Select current result, exit search mode, and send command immediately
-		if len(m.historySearchResults) > 0 && m.historySearchIndex < len(m.historySearchResults) {
-			resultIdx := m.historySearchResults[m.historySearchIndex]
-			command := m.commandHistory[resultIdx]
-
-			// Exit search mode
-			m.historySearchMode = false
-			m.historySearchQuery = ""
-			m.historySearchResults = []int{}
-			m.historySearchIndex = 0
-
-			// Set the command as current input
-			m.currentInput = command
-			m.cursorPos = len(m.currentInput)
-			m.updateViewport()
-
-			// Send the command immediately (simulate pressing Enter)
-			if m.conn != nil && m.connected {
-				// Add non-empty command to history (unless it's a password prompt)
-				if command != "" && !m.isPasswordPrompt() {
-					// Don't add duplicate consecutive commands
-					if len(m.commandHistory) == 0 || m.commandHistory[len(m.commandHistory)-1] != command {
-						m.commandHistory = append(m.commandHistory, command)
-						// Save to persistent history
-						if m.historyManager != nil {
-							m.historyManager.Add(command)
-							// Save asynchronously to avoid blocking
-							go m.historyManager.Save()
-						}
-					}
-					// Reset history navigation state
-					m.historyIndex = -1
-					m.historySavedInput = ""
-				}
-
-				// Check if this is a client command (starts with /)
-				if strings.HasPrefix(command, "/") {
-					// Save the current prompt line before executing command
-					var savedPrompt string
-					if len(m.output) > 0 {
-						savedPrompt = m.output[len(m.output)-1]
-						// Replace the prompt line with the command
-						m.output[len(m.output)-1] = savedPrompt + "\x1b[93m" + command + "\x1b[0m"
-					}
-
-					clientCmd := m.handleClientCommand(command)
-
-					// Add two newlines (empty lines) and restore prompt after command output
-					m.output = append(m.output, "")
-					m.output = append(m.output, "")
-					m.output = append(m.output, savedPrompt)
-
-					m.currentInput = ""
-					m.cursorPos = 0
-					m.updateViewport()
-					return m, clientCmd
-				}
-
-				// Check if this is a movement command
-				if movement := mapper.DetectMovement(command); movement != "" {
-					m.pendingMovement = movement
-					// Clear map legend on movement
-					m.mapLegend = nil
-					m.mapLegendRooms = nil
-				}
-
-				// Send command to MUD server
-				m.conn.Send(command)
-
-				// Don't modify m.output here - let the server echo if it wants to
-				// Or we can store the command for display purposes
-				// Don't echo if it's a password prompt
-				if !m.echoSuppressed && !m.isPasswordPrompt() && command != "" {
-					// Add the command as a new line in output (it will show on the prompt line)
-					// This preserves it even when new output arrives
-					if len(m.output) > 0 {
-						// Modify the last line to include the command
-						m.output[len(m.output)-1] = m.output[len(m.output)-1] + "\x1b[93m" + command + "\x1b[0m"
-					}
-				} else if (m.echoSuppressed || m.isPasswordPrompt()) && command != "" {
-					// For password input, show obfuscated bullets with random length
-					// Add -3 to +3 random bullets to the actual length to hide true length
-					actualLength := len(command)
-					randomOffset := rand.Intn(7) - 3 // Range: -3 to +3
-					displayLength := actualLength + randomOffset
-					if displayLength < 0 {
-						displayLength = 0
-					}
-					bullets := strings.Repeat("•", displayLength)
-					if len(m.output) > 0 {
-						m.output[len(m.output)-1] = m.output[len(m.output)-1] + bullets
-					}
-				}
-
-				// Reset input
-				m.currentInput = ""
-				m.cursorPos = 0
-				// Update display immediately
-				m.updateViewport()
-			}
-			return m, nil
-		}
-
-		// No results, just exit search mode
-		m.historySearchMode = false
-		m.historySearchQuery = ""
-		m.historySearchResults = []int{}
-		m.historySearchIndex = 0
-		m.updateViewport()
-		return m, nil
-
-	case tea.KeyUp:
-		// Navigate to previous search result
-		if len(m.historySearchResults) > 0 && m.historySearchIndex > 0 {
-			m.historySearchIndex--
-			m.updateViewport()
-		}
-		return m, nil
-
-	case tea.KeyDown:
-		// Navigate to next search result
-		if len(m.historySearchResults) > 0 && m.historySearchIndex < len(m.historySearchResults)-1 {
-			m.historySearchIndex++
-			m.updateViewport()
+	m.output = append(m.output, m.colors().Info.Render(fmt.Sprintf("Removed macro: %s -> \"%s\"", strings.ToUpper(macro.Key), macro.Command)))
+}
+
+// variableRegex matches @name variable references in commands
+var variableRegex = regexp.MustCompile(`@(\w+)`)
+
+// variableNameRegex matches valid variable names
+var variableNameRegex = regexp.MustCompile(`^\w+$`)
+
+// substituteVariables replaces @name references with the variable's value,
+// leaving references to unset variables untouched, and <target> with the
+// /target. An alias leaves <target> for this when it is given no argument
+// for it, and it is dropped when there is no target.
+func (m *Model) substituteVariables(command string) string {
+	command = strings.ReplaceAll(command, "<target>", m.target)
+	if len(m.variables) == 0 {
+		return command
+	}
+	return variableRegex.ReplaceAllStringFunc(command, func(ref string) string {
+		if value, ok := m.variables[ref[1:]]; ok {
+			return value
 		}
-		return m, nil
+		return ref
+	})
+}
 
-	case tea.KeyBackspace:
-		// Remove last character from search query
-		if len(m.historySearchQuery) > 0 {
-			m.historySearchQuery = m.historySearchQuery[:len(m.historySearchQuery)-1]
-			m.updateHistorySearch()
-			m.updateViewport()
+// handleSetCommand sets, shows or lists variables
+func (m *Model) handleSetCommand(args []string) {
+	if len(args) == 0 {
+		if len(m.variables) == 0 {
+			m.output = append(m.output, m.colors().Warn.Render("No variables set"))
+			return
 		}
-		return m, nil
+		names := make([]string, 0, len(m.variables))
+		for name := range m.variables {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		m.output = append(m.output, m.colors().Info.Render("=== Variables ==="))
+		for _, name := range names {
+			m.output = append(m.output, "  "+m.colors().Highlight.Render(fmt.Sprintf("@%s = %s", name, m.variables[name])))
+		}
+		return
+	}
 
-	case tea.KeySpace:
-		// Add space to search query for multi-word search
-		m.historySearchQuery += " "
-		m.updateHistorySearch()
-		m.updateViewport()
-		return m, nil
+	name := strings.TrimPrefix(args[0], "@")
+	if !variableNameRegex.MatchString(name) {
+		m.output = append(m.output, m.colors().Error.Render(fmt.Sprintf("Error: Invalid variable name '%s' (use letters, digits and _)", args[0])))
+		return
+	}
 
-	case tea.KeyRunes:
-		// Add typed characters to search query
-		m.historySearchQuery += string(msg.Runes)
-		m.updateHistorySearch()
-		m.updateViewport()
-		return m, nil
+	if len(args) == 1 {
+		if value, ok := m.variables[name]; ok {
+			m.output = append(m.output, m.colors().Highlight.Render(fmt.Sprintf("@%s = %s", name, value)))
+		} else {
+			m.output = append(m.output, m.colors().Warn.Render(fmt.Sprintf("@%s is not set", name)))
+		}
+		return
 	}
 
-	return m, nil
+	if m.variables == nil {
+		m.variables = make(map[string]string)
+	}
+	value := strings.Join(args[1:], " ")
+	m.variables[name] = value
+	m.output = append(m.output, m.colors().Info.Render(fmt.Sprintf("Set @%s = %s", name, value)))
 }
 
-// updateHistorySearch updates the search results based on the current query
-func (m *Model) updateHistorySearch() {
-	m.historySearchResults = []int{}
-	m.historySearchIndex = 0
+// handleUnsetCommand removes a variable
+func (m *Model) handleUnsetCommand(args []string) {
+	if len(args) != 1 {
+		m.output = append(m.output, m.colors().Warn.Render("Usage: /unset <name>"))
+		return
+	}
 
-	if len(m.commandHistory) == 0 {
+	name := strings.TrimPrefix(args[0], "@")
+	if _, ok := m.variables[name]; !ok {
+		m.output = append(m.output, m.colors().Warn.Render(fmt.Sprintf("@%s is not set", name)))
 		return
 	}
+	delete(m.variables, name)
+	m.output = append(m.output, m.colors().Info.Render(fmt.Sprintf("Removed @%s", name)))
+}
 
-	query := strings.ToLower(m.historySearchQuery)
+// handleEchoCommand prints text to the output without sending it to the MUD
+func (m *Model) handleEchoCommand(text string) {
+	m.output = append(m.output, m.colors().Info.Render(m.substituteVariables(text)))
+}
 
-	// Split query into individual words
-	words := strings.Fields(query)
+// handleConnectCommand opens an additional session to another MUD server
+func (m *Model) handleConnectCommand(args []string) tea.Cmd {
+	if len(args) != 2 {
+		m.output = append(m.output, m.colors().Warn.Render("Usage: /connect <host> <port>"))
+		return nil
+	}
 
-	// Search through history in reverse order (most recent first)
-	for i := len(m.commandHistory) - 1; i >= 0; i-- {
-		cmd := strings.ToLower(m.commandHistory[i])
+	host := args[0]
+	var port int
+	if _, err := fmt.Sscanf(args[1], "%d", &port); err != nil || port <= 0 || port > 65535 {
+		m.output = append(m.output, m.colors().Error.Render(fmt.Sprintf("Error: Invalid port '%s'", args[1])))
+		return nil
+	}
 
-		// Multi-word search: all words must be present (in any order)
-		if len(words) == 0 {
-			// Empty query matches all commands
-			m.historySearchResults = append(m.historySearchResults, i)
-		} else {
-			// Check if all words are present in the command
-			allWordsMatch := true
-			for _, word := range words {
-				if !strings.Contains(cmd, word) {
-					allWordsMatch = false
-					break
-				}
-			}
-			if allWordsMatch {
-				m.historySearchResults = append(m.historySearchResults, i)
-			}
+	// The current state becomes the first session
+	if len(m.sessions) == 0 {
+		m.sessions = []*Session{{}}
+		m.activeSession = 0
+	}
+	m.storeSession(m.sessions[m.activeSession])
+
+	// Sessions on the same server share one map so they don't overwrite each other's saves
+	var worldMap *mapper.Map
+	for _, session := range m.sessions {
+		if session.host == host && session.port == port {
+			worldMap = session.worldMap
+			break
+		}
+	}
+	if worldMap == nil {
+		var err error
+		worldMap, err = mapper.LoadForServer(host, port)
+		if err != nil {
+			worldMap = mapper.NewMap()
+		}
+	}
+
+	tickTimerManager, err := ticktimer.Load(host, port, 0)
+	if err != nil {
+		tickTimerManager = ticktimer.NewManager(0)
+	}
+
+	index := len(m.sessions)
+	m.sessions = append(m.sessions, &Session{
+		host:             host,
+		port:             port,
+		output:           []string{m.colors().Debug.Render(fmt.Sprintf("[Session %d: connecting to %s:%d]", index+1, host, port))},
+		worldMap:         worldMap,
+		recentOutput:     []string{},
+		xpTracking:       make(map[string]*XPStat),
+		barsoomMode:      worldMap.BarsoomMode,
+		tickTimerManager: tickTimerManager,
+	})
+	m.output = append(m.output, m.colors().Info.Render(fmt.Sprintf("Opened session %d to %s:%d (Ctrl+Tab to switch)", index+1, host, port)))
+
+	telnetDebugLog := m.telnetDebugLog
+	charset, lineEnding := m.serverEncoding(host, port)
+	connect := func() tea.Msg {
+		conn, err := client.NewConnectionWithCharset(host, port, telnetDebugLog, charset)
+		if err != nil {
+			return errMsg(err)
 		}
+		conn.SetLineEnding(lineEnding)
+		return conn
 	}
+
+	// Switch once the command has finished writing to the current session
+	return tea.Batch(
+		func() tea.Msg { return switchSessionMsg(index) },
+		tagSessionCmd(index, connect),
+	)
 }
 
-// handleAliasCommand adds a new alias
-func (m *Model) handleAliasCommand(command string) {
-	// Parse the command to extract name and template
-	// Expected format: /alias "name" "template"
+// handleVersionCommand shows the build information for bug reports
+func (m *Model) handleVersionCommand() {
+	info := version.Get()
+	profile := lipgloss.ColorProfile().Name()
+	if m.plainText() {
+		profile += " (colors off with /ansi)"
+	}
 
-	// Remove "/alias " prefix
-	command = strings.TrimPrefix(command, "alias ")
-	command = strings.TrimSpace(command)
+	m.output = append(m.output, m.colors().Info.Render("=== dikuclient ==="))
+	m.output = append(m.output, "  Version:       "+m.colors().Highlight.Render(info.Version))
+	m.output = append(m.output, "  Commit:        "+m.colors().Highlight.Render(info.Commit))
+	m.output = append(m.output, "  Built:         "+m.colors().Highlight.Render(info.Date))
+	m.output = append(m.output, "  Go:            "+m.colors().Highlight.Render(info.GoVersion))
+	m.output = append(m.output, "  Color profile: "+m.colors().Highlight.Render(profile))
+}
 
-	// Parse quoted strings
-	name, template, err := parseQuotedArgs(command)
-	if err != nil {
-		m.output = append(m.output, fmt.Sprintf("\x1b[91mError: %v\x1b[0m", err))
-		m.output = append(m.output, "\x1b[93mUsage: /alias \"name\" \"template\"\x1b[0m")
-		m.output = append(m.output, "\x1b[93mExample: /alias \"gat\" \"give all <target>\"\x1b[0m")
-		m.output = append(m.output, "\x1b[93mExample: /alias \"gt\" \"give <object> <target>\"\x1b[0m")
+// debugDumpLines is how many of the latest output lines /debug dump writes
+const debugDumpLines = 50
+
+// handleDebugCommand writes the client's state to a file with /debug dump,
+// for attaching to bug reports, and controls the --log-all logs with
+// /debug flush and /debug level
+func (m *Model) handleDebugCommand(args []string) {
+	if len(args) > 0 && args[0] == "flush" && len(args) == 1 {
+		m.handleDebugFlush()
 		return
 	}
-
-	// Add the alias
-	alias, err := m.aliasManager.Add(name, template)
-	if err != nil {
-		m.output = append(m.output, fmt.Sprintf("\x1b[91mError adding alias: %v\x1b[0m", err))
+	if len(args) > 0 && args[0] == "level" && len(args) <= 2 {
+		m.handleDebugLevel(args[1:])
+		return
+	}
+	if len(args) == 0 || args[0] != "dump" || len(args) > 2 {
+		m.output = append(m.output, m.colors().Warn.Render("Usage: /debug dump [file] | /debug flush | /debug level [negotiation|all]"))
 		return
 	}
 
-	// Save aliases
-	if err := m.aliasManager.Save(); err != nil {
-		m.output = append(m.output, fmt.Sprintf("\x1b[91mError saving aliases: %v\x1b[0m", err))
+	path := fmt.Sprintf("dikuclient-debug-%s.txt", time.Now().Format("20060102-150405"))
+	if len(args) == 2 {
+		path = args[1]
+	}
+	if err := os.WriteFile(path, []byte(m.debugDump()), 0600); err != nil {
+		m.output = append(m.output, m.colors().Error.Render(fmt.Sprintf("Error writing debug dump: %v", err)))
 		return
 	}
+	m.output = append(m.output, m.colors().Info.Render(fmt.Sprintf("Wrote debug dump to %s. Passwords are left out; check it before sharing.", path)))
+}
 
-	m.output = append(m.output, fmt.Sprintf("\x1b[92mAlias added: \"%s\" -> \"%s\"\x1b[0m", alias.Name, alias.Template))
+// handleDebugFlush writes out everything the logs have buffered, so they
+// can be read while the client is still running
+func (m *Model) handleDebugFlush() {
+	if m.mudLog == nil && m.tuiLog == nil && m.telnetDebugLog == nil {
+		m.output = append(m.output, m.colors().Warn.Render("Logging is off; start the client with --log-all to write logs"))
+		return
+	}
+	for _, log := range []*debuglog.Log{m.mudLog, m.tuiLog, m.telnetDebugLog} {
+		if err := log.Flush(); err != nil {
+			m.output = append(m.output, m.colors().Error.Render(fmt.Sprintf("Error writing log: %v", err)))
+			return
+		}
+	}
+	m.output = append(m.output, m.colors().Info.Render("Logs flushed"))
 }
 
-// handleAliasesCommand handles /aliases list and /aliases remove
-func (m *Model) handleAliasesCommand(args []string) {
+// handleDebugLevel shows or sets how much the telnet debug log records
+func (m *Model) handleDebugLevel(args []string) {
+	if m.telnetDebugLog == nil {
+		m.output = append(m.output, m.colors().Warn.Render("Logging is off; start the client with --log-all to write the telnet debug log"))
+		return
+	}
 	if len(args) == 0 {
-		// Default to list
-		m.handleAliasesListCommand()
+		m.output = append(m.output, m.colors().Info.Render(fmt.Sprintf("Telnet debug log level: %s", m.telnetDebugLog.Level())))
 		return
 	}
+	level, err := debuglog.ParseLevel(args[0])
+	if err != nil {
+		m.output = append(m.output, m.colors().Error.Render(fmt.Sprintf("Error: %v", err)))
+		return
+	}
+	m.telnetDebugLog.SetLevel(level)
+	m.output = append(m.output, m.colors().Info.Render(fmt.Sprintf("Telnet debug log level set to %s", level)))
+}
 
-	subCmd := strings.ToLower(args[0])
-	switch subCmd {
-	case "list":
-		m.handleAliasesListCommand()
-	case "remove":
-		if len(args) < 2 {
-			m.output = append(m.output, "\x1b[91mUsage: /aliases remove <index>\x1b[0m")
-			return
+// SetDebugLevel sets how much the telnet debug log records (--debug-level)
+func (m *Model) SetDebugLevel(level debuglog.Level) {
+	m.telnetDebugLog.SetLevel(level)
+}
+
+// FlushLogs stops the periodic flushing of the --log-all logs and writes
+// out what they still hold, before the log files are closed
+func (m *Model) FlushLogs() {
+	for _, log := range []*debuglog.Log{m.mudLog, m.tuiLog, m.telnetDebugLog} {
+		log.Stop()
+	}
+}
+
+// debugDump describes the mapper, auto-walk, queue and manager state and
+// the latest output. The login name and password are never included.
+func (m *Model) debugDump() string {
+	var b strings.Builder
+	info := version.Get()
+	fmt.Fprintf(&b, "dikuclient debug dump, %s\n", time.Now().Format(time.RFC3339))
+	fmt.Fprintf(&b, "Version: %s (commit %s, built %s, %s)\n", info.Version, info.Commit, info.Date, info.GoVersion)
+	fmt.Fprintf(&b, "Server: %s:%d\n", m.host, m.port)
+	fmt.Fprintf(&b, "Connected: %v\n", m.connected)
+	fmt.Fprintf(&b, "Auto-login: %v (state %d)\n", m.username != "", m.autoLoginState)
+
+	b.WriteString("\n== Mapper ==\n")
+	if m.worldMap != nil {
+		fmt.Fprintf(&b, "Rooms: %d\n", len(m.worldMap.Rooms))
+		fmt.Fprintf(&b, "Barsoom mode: %v\n", m.barsoomMode || m.worldMap.BarsoomMode)
+		if room := m.worldMap.GetCurrentRoom(); room != nil {
+			fmt.Fprintf(&b, "Current room: #%d %q\n", m.worldMap.GetRoomNumber(room.ID), room.Title)
+			fmt.Fprintf(&b, "Current room ID: %q\n", room.ID)
+			fmt.Fprintf(&b, "Current room exits: %v\n", room.Exits)
+		} else {
+			b.WriteString("Current room: unknown\n")
+		}
+		fmt.Fprintf(&b, "Previous room ID: %q\n", m.worldMap.PreviousRoomID)
+		fmt.Fprintf(&b, "Last direction: %q\n", m.worldMap.LastDirection)
+		fmt.Fprintf(&b, "Prompt pattern: %q\n", m.worldMap.PromptPattern)
+	} else {
+		b.WriteString("No map loaded\n")
+	}
+	fmt.Fprintf(&b, "Pending movement: %q\n", m.pendingMovement)
+	fmt.Fprintf(&b, "Location uncertain: %v\n", m.locationUncertain)
+	fmt.Fprintf(&b, "Relocalize pending: %v\n", m.relocalizePending)
+	fmt.Fprintf(&b, "Skip next room detection: %v\n", m.skipNextRoomDetection)
+
+	b.WriteString("\n== Auto-walk and queue ==\n")
+	fmt.Fprintf(&b, "Auto-walking: %v\n", m.autoWalking)
+	fmt.Fprintf(&b, "Auto-walk target: %q\n", m.autoWalkTarget)
+	fmt.Fprintf(&b, "Auto-walk path: %v (step %d of %d)\n", m.autoWalkPath, m.autoWalkIndex, len(m.autoWalkPath))
+	fmt.Fprintf(&b, "Queue active: %v\n", m.commandQueueActive)
+	fmt.Fprintf(&b, "Queued commands: %q\n", m.pendingCommands)
+	fmt.Fprintf(&b, "Commands held until connected: %d\n", len(m.offlineCommands))
+
+	b.WriteString("\n== Vitals ==\n")
+	fmt.Fprintf(&b, "Prompt: %q\n", ansi.Strip(m.currentPrompt))
+	if m.tickTimerManager != nil {
+		fmt.Fprintf(&b, "Tick interval: %ds\n", m.tickTimerManager.TickInterval)
+	}
+	if m.affectTracker != nil {
+		fmt.Fprintf(&b, "Affects: %d\n", len(m.affectTracker.Affects))
+	}
+
+	b.WriteString("\n== Managers ==\n")
+	if m.triggerManager != nil {
+		fmt.Fprintf(&b, "Triggers: %d\n", len(m.triggerManager.Triggers))
+	}
+	if m.aliasManager != nil {
+		fmt.Fprintf(&b, "Aliases: %d\n", len(m.aliasManager.Aliases))
+	}
+	if m.macroManager != nil {
+		fmt.Fprintf(&b, "Macros: %d\n", len(m.macroManager.Macros))
+	}
+	if m.substitutionManager != nil {
+		fmt.Fprintf(&b, "Substitutions: %d\n", len(m.substitutionManager.Substitutions))
+	}
+	if m.captureManager != nil {
+		fmt.Fprintf(&b, "Captures: %d\n", len(m.captureManager.Captures))
+	}
+	if m.tickTimerManager != nil {
+		fmt.Fprintf(&b, "Tick triggers: %d\n", len(m.tickTimerManager.TickTriggers))
+	}
+	if m.itemManager != nil {
+		fmt.Fprintf(&b, "Items: %d\n", len(m.itemManager.Items))
+	}
+	fmt.Fprintf(&b, "Variables: %d\n", len(m.variables))
+	fmt.Fprintf(&b, "Sessions: %d\n", len(m.sessions))
+
+	b.WriteString("\n== Room detection buffer ==\n")
+	for _, line := range m.recentOutput {
+		fmt.Fprintf(&b, "%q\n", line)
+	}
+
+	fmt.Fprintf(&b, "\n== Last %d output lines ==\n", debugDumpLines)
+	start := len(m.output) - debugDumpLines
+	if start < 0 {
+		start = 0
+	}
+	for _, line := range m.output[start:] {
+		fmt.Fprintf(&b, "%q\n", line)
+	}
+
+	dump := b.String()
+	for _, secret := range []string{m.password, m.username} {
+		if secret != "" {
+			dump = strings.ReplaceAll(dump, secret, "[redacted]")
 		}
+	}
+	return dump
+}
+
+// handleSessionsCommand lists open sessions or switches to one by number
+func (m *Model) handleSessionsCommand(args []string) tea.Cmd {
+	if len(m.sessions) == 0 {
+		m.output = append(m.output, m.colors().Warn.Render("Only one session is open. Use /connect <host> <port> to open another."))
+		return nil
+	}
+
+	if len(args) > 0 {
 		var index int
-		_, err := fmt.Sscanf(args[1], "%d", &index)
-		if err != nil {
-			m.output = append(m.output, "\x1b[91mError: Invalid index\x1b[0m")
-			return
+		if _, err := fmt.Sscanf(args[0], "%d", &index); err != nil || index < 1 || index > len(m.sessions) {
+			m.output = append(m.output, m.colors().Error.Render(fmt.Sprintf("Error: Invalid session number '%s'", args[0])))
+			return nil
 		}
-		m.handleAliasesRemoveCommand(index)
-	default:
-		m.output = append(m.output, fmt.Sprintf("\x1b[91mError: Unknown subcommand '%s'\x1b[0m", subCmd))
-		m.output = append(m.output, "\x1b[93mUsage: /aliases [list|remove <index>]\x1b[0m")
+		return func() tea.Msg { return switchSessionMsg(index - 1) }
+	}
+
+	m.output = append(m.output, m.colors().Info.Render("=== Sessions ==="))
+	for i, session := range m.sessions {
+		host, port, connected := session.host, session.port, session.connected
+		if i == m.activeSession {
+			host, port, connected = m.host, m.port, m.connected
+		}
+		status := "connected"
+		if !connected {
+			status = "closed"
+		}
+		marker := " "
+		if i == m.activeSession {
+			marker = "*"
+		}
+		m.output = append(m.output, "  "+m.colors().Highlight.Render(fmt.Sprintf("%s%d. %s:%d (%s)", marker, i+1, host, port, status)))
 	}
+	return nil
 }
 
-// handleAliasesListCommand lists all aliases
-func (m *Model) handleAliasesListCommand() {
-	if len(m.aliasManager.Aliases) == 0 {
-		m.output = append(m.output, "\x1b[93mNo aliases defined.\x1b[0m")
-		m.output = append(m.output, "\x1b[93mUse /alias \"name\" \"template\" to add an alias.\x1b[0m")
+// switchSession makes the session at index the one shown on screen
+func (m *Model) switchSession(index int) {
+	if index < 0 || index >= len(m.sessions) || index == m.activeSession {
 		return
 	}
 
-	m.output = append(m.output, "\x1b[92m=== Active Aliases ===\x1b[0m")
-	for i, alias := range m.aliasManager.Aliases {
-		m.output = append(m.output, fmt.Sprintf("  \x1b[96m%d. \"%s\" -> \"%s\"\x1b[0m", i+1, alias.Name, alias.Template))
+	m.flushMap()
+	m.storeSession(m.sessions[m.activeSession])
+	m.loadSession(m.sessions[index])
+	m.activeSession = index
+	m.sessions[index].activity = false
+	if m.plainText() {
+		// Anything the session stored in color is shown without it
+		stripColors(m.output)
 	}
+
+	// Start the new session's buffer scrolled to the bottom
+	m.isSplit = false
+	m.historyIndex = -1
+	m.historySavedInput = ""
+	m.lastViewportContent = ""
+	m.updateViewport()
 }
 
-// handleAliasesRemoveCommand removes an alias by index
-func (m *Model) handleAliasesRemoveCommand(index int) {
-	// Convert from 1-based to 0-based index
-	index--
+// saveInterval is the least time between writes of the map or XP stats;
+// changes in between are written together on the next tick or when quitting
+const saveInterval = 5 * time.Second
 
-	if index < 0 || index >= len(m.aliasManager.Aliases) {
-		m.output = append(m.output, fmt.Sprintf("\x1b[91mError: Invalid alias index. Use /aliases list to see available aliases.\x1b[0m"))
-		return
+// mapChanged schedules the map to be saved. Walking changes it on every
+// step, so it is written at most once per saveInterval.
+func (m *Model) mapChanged() {
+	if m.mapSaves == nil {
+		m.mapSaves = autosave.New(saveInterval)
 	}
+	m.mapSaves.MarkDirty()
+	m.autosave(time.Now())
+}
 
-	alias := m.aliasManager.Aliases[index]
-	if err := m.aliasManager.Remove(index); err != nil {
-		m.output = append(m.output, fmt.Sprintf("\x1b[91mError removing alias: %v\x1b[0m", err))
-		return
+// xpStatsChanged schedules the XP stats to be saved, at most once per
+// saveInterval
+func (m *Model) xpStatsChanged() {
+	if m.xpSaves == nil {
+		m.xpSaves = autosave.New(saveInterval)
 	}
+	m.xpSaves.MarkDirty()
+	m.autosave(time.Now())
+}
 
-	// Save aliases
-	if err := m.aliasManager.Save(); err != nil {
-		m.output = append(m.output, fmt.Sprintf("\x1b[91mError saving aliases: %v\x1b[0m", err))
-		return
+// autosave writes the map and XP stats if they have changed and weren't
+// written in the last saveInterval. Errors are ignored so they don't
+// disrupt play; the next change tries again.
+func (m *Model) autosave(now time.Time) {
+	if m.mapSaves != nil && m.worldMap != nil {
+		m.mapSaves.Run(now, m.worldMap.Save)
+	}
+	if m.xpSaves != nil && m.xpStatsManager != nil {
+		m.xpSaves.Run(now, m.xpStatsManager.Save)
+	}
+}
+
+// flushMap writes the active session's map if it has unsaved changes
+func (m *Model) flushMap() {
+	if m.mapSaves != nil && m.worldMap != nil {
+		m.mapSaves.Flush(time.Now(), m.worldMap.Save)
+	}
+}
+
+// ExitMessage describes the connection failure that ended the program, or
+// returns "" when it ended some other way. Call it when the program exits.
+func (m *Model) ExitMessage() string {
+	var connErr *client.ConnectionError
+	if !errors.As(m.err, &connErr) || connErr.Kind == client.ErrorClosed {
+		return ""
+	}
+	return connErr.Friendly()
+}
+
+// FlushSaves writes any map and XP stats changes still waiting for the save
+// interval, for every session. Call it when the program exits.
+func (m *Model) FlushSaves() {
+	m.flushMap()
+	for i, session := range m.sessions {
+		if i != m.activeSession && session.mapSaves != nil && session.worldMap != nil {
+			session.mapSaves.Flush(time.Now(), session.worldMap.Save)
+		}
+	}
+	if m.xpSaves != nil && m.xpStatsManager != nil {
+		m.xpSaves.Flush(time.Now(), m.xpStatsManager.Save)
+	}
+}
+
+// updateBackgroundSession processes a message for a session that isn't on
+// screen by swapping its state in for the duration of the update
+func (m *Model) updateBackgroundSession(index int, msg tea.Msg) tea.Cmd {
+	if index < 0 || index >= len(m.sessions) {
+		return nil
 	}
 
-	m.output = append(m.output, fmt.Sprintf("\x1b[92mRemoved alias: \"%s\" -> \"%s\"\x1b[0m", alias.Name, alias.Template))
+	// The update redraws the viewports with the background session's output,
+	// so keep the on-screen ones to put back afterwards
+	active := m.activeSession
+	viewport, splitViewport, descriptionViewport := m.viewport, m.splitViewport, m.descriptionViewport
+	lastViewportContent, isSplit := m.lastViewportContent, m.isSplit
+
+	m.storeSession(m.sessions[active])
+	m.loadSession(m.sessions[index])
+	m.activeSession = index
+
+	_, cmd := m.update(msg)
+
+	m.storeSession(m.sessions[index])
+	m.loadSession(m.sessions[active])
+	m.activeSession = active
+
+	m.viewport, m.splitViewport, m.descriptionViewport = viewport, splitViewport, descriptionViewport
+	m.lastViewportContent, m.isSplit = lastViewportContent, isSplit
+
+	if _, ok := msg.(mudMsg); ok {
+		m.sessions[index].activity = true
+	}
+
+	return tagSessionCmd(index, cmd)
+}
+
+// storeSession saves the model's per-session fields into s
+func (m *Model) storeSession(s *Session) {
+	s.conn = m.conn
+	s.connected = m.connected
+	s.host = m.host
+	s.port = m.port
+	s.err = m.err
+	s.output = m.output
+	s.currentInput = m.currentInput
+	s.cursorPos = m.cursorPos
+	s.echoSuppressed = m.echoSuppressed
+	s.username = m.username
+	s.password = m.password
+	s.autoLoginState = m.autoLoginState
+	s.loginMenu = m.loginMenu
+	s.loginMenuStep = m.loginMenuStep
+	s.offlineCommands = m.offlineCommands
+	s.reconnectAt = m.reconnectAt
+	s.reconnecting = m.reconnecting
+	s.worldMap = m.worldMap
+	s.mapName = m.mapName
+	s.trail = m.trail
+	s.odometer = m.odometer
+	s.mapSaves = m.mapSaves
+	s.recentOutput = m.recentOutput
+	s.pendingMovement = m.pendingMovement
+	s.autoWalking = m.autoWalking
+	s.autoWalkPath = m.autoWalkPath
+	s.autoWalkIndex = m.autoWalkIndex
+	s.autoWalkTarget = m.autoWalkTarget
+	s.walkDelayOverride = m.walkDelayOverride
+	s.lastRoomSearch = m.lastRoomSearch
+	s.skipNextRoomDetection = m.skipNextRoomDetection
+	s.locationUncertain = m.locationUncertain
+	s.relocalizePending = m.relocalizePending
+	s.mapLegend = m.mapLegend
+	s.mapLegendRooms = m.mapLegendRooms
+	s.roomLines = m.roomLines
+	s.inventory = m.inventory
+	s.inventoryTime = m.inventoryTime
+	s.inventorySentAt = m.inventorySentAt
+	s.equipment = m.equipment
+	s.equipmentTime = m.equipmentTime
+	s.userPanels = m.userPanels
+	s.captureRecords = m.captureRecords
+	s.muteList = m.muteList
+	s.tells = m.tells
+	s.sightings = m.sightings
+	s.xpTracking = m.xpTracking
+	s.pendingKill = m.pendingKill
+	s.target = m.target
+	s.autoFollowLeader = m.autoFollowLeader
+	s.autoFollowCommand = m.autoFollowCommand
+	s.autoAssistLeader = m.autoAssistLeader
+	s.autoAssistCommand = m.autoAssistCommand
+	s.following = m.following
+	s.retryCast = m.retryCast
+	s.killTime = m.killTime
+	s.currentRoomDescription = m.currentRoomDescription
+	s.hasDescriptionSplit = m.hasDescriptionSplit
+	s.currentBarsoomTitle = m.currentBarsoomTitle
+	s.currentBarsoomExits = m.currentBarsoomExits
+	s.barsoomMode = m.barsoomMode
+	s.pendingCommands = m.pendingCommands
+	s.fastWalkQueue = m.fastWalkQueue
+	s.commandQueueActive = m.commandQueueActive
+	s.lastTriggerAction = m.lastTriggerAction
+	s.skipTriggers = m.skipTriggers
+	s.tabs = m.tabs
+	s.tickTimerManager = m.tickTimerManager
+	s.lastFiredTickTime = m.lastFiredTickTime
+	s.currentPrompt = m.currentPrompt
+	s.syntheticInputLine = m.syntheticInputLine
+	s.promptLineBreak = m.promptLineBreak
+	s.blankLineEnd = m.blankLineEnd
+	s.focus = m.focus
+	s.focusHide = m.focusHide
+	s.affectTracker = m.affectTracker
+	s.combatLog = m.combatLog
+	s.wealthTracker = m.wealthTracker
+	s.vitalsTracker = m.vitalsTracker
+	s.wimpyFled = m.wimpyFled
+	s.sessionXP = m.sessionXP
+	s.sessionXPStart = m.sessionXPStart
+	s.afkSentFor = m.afkSentFor
+	s.accessiblePrinted = m.accessiblePrinted
+}
+
+// loadSession restores the model's per-session fields from s
+func (m *Model) loadSession(s *Session) {
+	m.conn = s.conn
+	m.connected = s.connected
+	m.host = s.host
+	m.port = s.port
+	m.err = s.err
+	m.output = s.output
+	m.currentInput = s.currentInput
+	m.cursorPos = s.cursorPos
+	m.echoSuppressed = s.echoSuppressed
+	m.username = s.username
+	m.password = s.password
+	m.autoLoginState = s.autoLoginState
+	m.loginMenu = s.loginMenu
+	m.loginMenuStep = s.loginMenuStep
+	m.offlineCommands = s.offlineCommands
+	m.reconnectAt = s.reconnectAt
+	m.reconnecting = s.reconnecting
+	m.worldMap = s.worldMap
+	m.mapName = s.mapName
+	m.trail = s.trail
+	m.odometer = s.odometer
+	m.mapSaves = s.mapSaves
+	m.recentOutput = s.recentOutput
+	m.pendingMovement = s.pendingMovement
+	m.autoWalking = s.autoWalking
+	m.autoWalkPath = s.autoWalkPath
+	m.autoWalkIndex = s.autoWalkIndex
+	m.autoWalkTarget = s.autoWalkTarget
+	m.walkDelayOverride = s.walkDelayOverride
+	m.lastRoomSearch = s.lastRoomSearch
+	m.skipNextRoomDetection = s.skipNextRoomDetection
+	m.locationUncertain = s.locationUncertain
+	m.relocalizePending = s.relocalizePending
+	m.mapLegend = s.mapLegend
+	m.mapLegendRooms = s.mapLegendRooms
+	m.roomLines = s.roomLines
+	m.inventory = s.inventory
+	m.inventoryTime = s.inventoryTime
+	m.inventorySentAt = s.inventorySentAt
+	m.equipment = s.equipment
+	m.equipmentTime = s.equipmentTime
+	m.userPanels = s.userPanels
+	m.captureRecords = s.captureRecords
+	m.muteList = s.muteList
+	m.tells = s.tells
+	m.sightings = s.sightings
+	m.xpTracking = s.xpTracking
+	m.pendingKill = s.pendingKill
+	m.setTarget(s.target)
+	m.autoFollowLeader = s.autoFollowLeader
+	m.autoFollowCommand = s.autoFollowCommand
+	m.autoAssistLeader = s.autoAssistLeader
+	m.autoAssistCommand = s.autoAssistCommand
+	m.assisting = false
+	m.following = s.following
+	m.retryCast = s.retryCast
+	m.killTime = s.killTime
+	m.currentRoomDescription = s.currentRoomDescription
+	m.hasDescriptionSplit = s.hasDescriptionSplit
+	m.currentBarsoomTitle = s.currentBarsoomTitle
+	m.currentBarsoomExits = s.currentBarsoomExits
+	m.barsoomMode = s.barsoomMode
+	m.pendingCommands = s.pendingCommands
+	m.fastWalkQueue = s.fastWalkQueue
+	m.commandQueueActive = s.commandQueueActive
+	m.lastTriggerAction = s.lastTriggerAction
+	m.skipTriggers = s.skipTriggers
+	m.tabs = s.tabs
+	m.tickTimerManager = s.tickTimerManager
+	m.lastFiredTickTime = s.lastFiredTickTime
+	m.currentPrompt = s.currentPrompt
+	m.syntheticInputLine = s.syntheticInputLine
+	m.promptLineBreak = s.promptLineBreak
+	m.blankLineEnd = s.blankLineEnd
+	m.focus = s.focus
+	m.focusHide = s.focusHide
+	m.affectTracker = s.affectTracker
+	m.combatLog = s.combatLog
+	m.wealthTracker = s.wealthTracker
+	m.vitalsTracker = s.vitalsTracker
+	m.wimpyFled = s.wimpyFled
+	m.sessionXP = s.sessionXP
+	m.sessionXPStart = s.sessionXPStart
+	m.afkSentFor = s.afkSentFor
+	m.accessiblePrinted = s.accessiblePrinted
+}
+
+// renderSessionTabs renders one status bar tab per session
+func (m *Model) renderSessionTabs() string {
+	tabs := make([]string, 0, len(m.sessions))
+	for i, session := range m.sessions {
+		host, port, connected := session.host, session.port, session.connected
+		if i == m.activeSession {
+			host, port, connected = m.host, m.port, m.connected
+		}
+		label := fmt.Sprintf("%d: %s:%d", i+1, host, port)
+		if !connected {
+			label += " (closed)"
+		}
+		if session.activity {
+			label += " *"
+		}
+		if i == m.activeSession {
+			tabs = append(tabs, statusStyle.Render(label))
+		} else {
+			tabs = append(tabs, inactiveTabStyle.Render(label))
+		}
+	}
+	return lipgloss.JoinHorizontal(lipgloss.Left, tabs...)
+}
+
+// tagSessionCmd wraps the messages cmd produces so they're routed back to
+// the session at index. The first session's messages are left untagged.
+func tagSessionCmd(index int, cmd tea.Cmd) tea.Cmd {
+	if index == 0 || cmd == nil {
+		return cmd
+	}
+	return func() tea.Msg {
+		return tagSessionMsg(index, cmd())
+	}
+}
+
+// tagSessionMsg tags a session-specific message with its session index,
+// descending into batches; UI messages such as tea.Quit pass through
+func tagSessionMsg(index int, msg tea.Msg) tea.Msg {
+	switch msg := msg.(type) {
+	case tea.BatchMsg:
+		cmds := make(tea.BatchMsg, len(msg))
+		for i, cmd := range msg {
+			cmds[i] = tagSessionCmd(index, cmd)
+		}
+		return cmds
+	case mudMsg, mudPromptMsg, errMsg, echoStateMsg, subnegotiationMsg, gmcpMsg, *client.Connection, autoWalkTickMsg, fastWalkTickMsg, commandQueueTickMsg, tickTimerMsg, reconnectMsg:
+		return sessionMsg{session: index, msg: msg}
+	}
+	return msg
+}
+
+// ctrlTabSequences are the input sequences terminals send for Ctrl+Tab when
+// they report modified keys (xterm modifyOtherKeys and CSI u). Bubble Tea
+// doesn't know them and delivers them as unknown CSI sequences.
+var ctrlTabSequences = map[string]bool{
+	"?CSI[50 55 59 53 59 57 126]?": true, // ESC [ 27;5;9~
+	"?CSI[57 59 53 117]?":          true, // ESC [ 9;5u
+}
+
+// isCtrlTab reports whether msg is a Ctrl+Tab key press
+func isCtrlTab(msg tea.Msg) bool {
+	stringer, ok := msg.(fmt.Stringer)
+	return ok && ctrlTabSequences[stringer.String()]
 }
@@ -0,0 +1,70 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestAutoAssistJoinsLeaderFight verifies /autoassist sends the assist when
+// the leader attacks, once per fight, and again after the fight ends
+func TestAutoAssistJoinsLeaderFight(t *testing.T) {
+	m, server := newConnectedTestModel(t)
+	m.handleClientCommand("/autoassist Gandalf")
+
+	// Someone else's fight is left alone
+	m.Update(mudMsg("Frodo hits the goblin.\n" + testPrompt))
+	if m.assisting {
+		t.Fatal("Expected no assist for another player's fight")
+	}
+
+	m.Update(mudMsg("Gandalf's slash misses the orc.\n" + testPrompt))
+	m.Update(commandQueueTickMsg{})
+	if sent := readSent(t, server); sent != "assist Gandalf" {
+		t.Fatalf("Expected the assist to be sent, got %q", sent)
+	}
+	if !m.assisting {
+		t.Fatal("Expected the assist to be tracked")
+	}
+
+	// More of the same fight doesn't send another
+	m.output = nil
+	m.Update(mudMsg("Gandalf hits the orc very hard.\n" + testPrompt))
+	if strings.Contains(strings.Join(m.output, "\n"), "[Auto-assist") {
+		t.Errorf("Expected one assist per fight, got:\n%s", strings.Join(m.output, "\n"))
+	}
+
+	// With no combat prompt for a while the fight is over
+	m.expireAutoAssist(time.Now())
+	if !m.assisting {
+		t.Error("Expected the fight to continue right after the assist")
+	}
+	m.assistSentAt = time.Now().Add(-2 * assistCombatEnd)
+	m.lastCombatPrompt = time.Now().Add(-2 * assistCombatEnd)
+	m.expireAutoAssist(time.Now())
+	if m.assisting {
+		t.Fatal("Expected the assist cleared once combat ended")
+	}
+
+	m.handleClientCommand(`/autoassist gandalf "kill <target>"`)
+	m.Update(mudMsg("Gandalf massacres the troll to small fragments with his slash.\n" + testPrompt))
+	m.Update(commandQueueTickMsg{})
+	if sent := readSent(t, server); sent != "kill troll" {
+		t.Errorf("Expected the leader's target to be attacked, got %q", sent)
+	}
+}
+
+// TestAutoAssistWaitsWhileFighting verifies no assist is sent while already
+// in a fight of your own
+func TestAutoAssistWaitsWhileFighting(t *testing.T) {
+	m, _ := newConnectedTestModel(t)
+	m.handleClientCommand("/autoassist gandalf")
+
+	m.detectCombatPrompt("[Hero:Perfect] [goblin:Wounded] <100hp 50m 80mv>")
+	if cmd := m.detectAutoAssist("Gandalf hits the orc.", time.Now()); cmd != nil || m.assisting {
+		t.Error("Expected no assist while fighting")
+	}
+	if cmd := m.detectAutoAssist("Gandalf misses you.", time.Now().Add(time.Minute)); cmd != nil {
+		t.Error("Expected no assist when the leader attacks you")
+	}
+}
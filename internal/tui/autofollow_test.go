@@ -0,0 +1,63 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestAutoFollowAcceptsLeaderInvite verifies an invitation from the leader
+// queues the accept commands and others are ignored
+func TestAutoFollowAcceptsLeaderInvite(t *testing.T) {
+	m, server := newConnectedTestModel(t)
+	m.handleClientCommand("/autofollow Gandalf")
+
+	m.Update(mudMsg("Saruman invites you to join the group.\n" + testPrompt))
+	if len(m.pendingCommands) != 0 {
+		t.Fatalf("Expected an invitation from someone else to be ignored, got %q", m.pendingCommands)
+	}
+
+	m.Update(mudMsg("Gandalf invites you to join the group.\n" + testPrompt))
+	if got := strings.Join(m.pendingCommands, "|"); got != "follow Gandalf|group" {
+		t.Fatalf("Expected the accept commands to be queued, got %q", got)
+	}
+	for _, want := range []string{"follow Gandalf", "group"} {
+		m.Update(commandQueueTickMsg{})
+		if sent := readSent(t, server); sent != want {
+			t.Fatalf("Expected %q to be sent, got %q", want, sent)
+		}
+	}
+}
+
+// TestAutoFollowTracksFollowing verifies who is followed is tracked and
+// cleared when the MUD says following stopped
+func TestAutoFollowTracksFollowing(t *testing.T) {
+	m, _ := newConnectedTestModel(t)
+	m.handleClientCommand("/autofollow gandalf \"follow <leader>\"")
+
+	m.Update(mudMsg("Gandalf invites you to join his group.\n" + testPrompt))
+	if got := strings.Join(m.pendingCommands, "|"); got != "follow gandalf" {
+		t.Fatalf("Expected the custom command to be queued, got %q", got)
+	}
+
+	m.Update(mudMsg("You now follow Gandalf.\n" + testPrompt))
+	if m.following != "Gandalf" {
+		t.Fatalf("Expected to be following Gandalf, got %q", m.following)
+	}
+	m.output = nil
+	m.handleClientCommand("/autofollow")
+	if out := strings.Join(m.output, "\n"); !strings.Contains(out, "Following") || !strings.Contains(out, "Gandalf") {
+		t.Errorf("Expected /autofollow to show who is followed, got:\n%s", out)
+	}
+
+	m.Update(mudMsg("You stop following Gandalf.\n" + testPrompt))
+	if m.following != "" {
+		t.Errorf("Expected following to be cleared, got %q", m.following)
+	}
+
+	m.handleClientCommand("/autofollow off")
+	m.pendingCommands = nil
+	m.Update(mudMsg("Gandalf invites you to join the group.\n" + testPrompt))
+	if len(m.pendingCommands) != 0 {
+		t.Errorf("Expected no commands with auto-follow off, got %q", m.pendingCommands)
+	}
+}
@@ -0,0 +1,62 @@
+package tui
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/anicolao/dikuclient/internal/settings"
+)
+
+// newAutoLootTestModel creates a connected model with auto-loot set to action
+func newAutoLootTestModel(t *testing.T, action string) *Model {
+	m, _ := newConnectedTestModel(t)
+	settingsManager, err := settings.LoadFromPath(filepath.Join(t.TempDir(), "settings.json"))
+	if err != nil {
+		t.Fatalf("Failed to load settings: %v", err)
+	}
+	m.settingsManager = settingsManager
+	m.xpTracking = make(map[string]*XPStat)
+	m.handleAutoLootCommand("autoloot on " + action)
+	m.output = []string{}
+	return m
+}
+
+func TestAutoLootSubstitutesCreature(t *testing.T) {
+	m := newAutoLootTestModel(t, `"get all 'corpse of the <creature>';get all"`)
+
+	m.Update(mudMsg("The orc is dead! R.I.P.\n"))
+
+	expected := []string{"get all 'corpse of the orc'", "get all"}
+	if strings.Join(m.pendingCommands, "|") != strings.Join(expected, "|") {
+		t.Errorf("Expected loot commands %q to be queued, got %q", expected, m.pendingCommands)
+	}
+	if !m.commandQueueActive {
+		t.Error("Expected the loot commands to start the command queue")
+	}
+
+	// /stop cancels the queued loot commands
+	m.handleClientCommand("/stop")
+	if len(m.pendingCommands) != 0 {
+		t.Errorf("Expected /stop to clear the loot commands, got %q", m.pendingCommands)
+	}
+}
+
+func TestAutoLootDefaultAndOff(t *testing.T) {
+	m := newAutoLootTestModel(t, "")
+	if m.settingsManager.GetAutoLootCommand() != settings.DefaultAutoLootCommand {
+		t.Errorf("Expected the default loot command, got %q", m.settingsManager.GetAutoLootCommand())
+	}
+
+	m.detectXPEvents("A goblin scout is dead! R.I.P.")
+	if len(m.pendingCommands) != 1 || m.pendingCommands[0] != "get all corpse" {
+		t.Errorf("Expected 'get all corpse' to be queued, got %q", m.pendingCommands)
+	}
+
+	m.pendingCommands = nil
+	m.handleAutoLootCommand("autoloot off")
+	m.detectXPEvents("The orc is dead! R.I.P.")
+	if len(m.pendingCommands) != 0 {
+		t.Errorf("Expected nothing to be queued with auto-loot off, got %q", m.pendingCommands)
+	}
+}
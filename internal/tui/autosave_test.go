@@ -0,0 +1,64 @@
+package tui
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/anicolao/dikuclient/internal/mapper"
+)
+
+// visitRoom shows a room in the output and lets the mapper pick it up
+func visitRoom(m *Model, direction, title string) {
+	m.pendingMovement = direction
+	m.recentOutput = []string{
+		testPrompt,
+		title,
+		"    A stretch of road called " + title + ".",
+		"Exits: north, south",
+	}
+	m.detectAndUpdateRoom()
+}
+
+// savedRooms reads the map back from disk and counts its rooms
+func savedRooms(t *testing.T, mapPath string) int {
+	t.Helper()
+	saved, err := mapper.LoadFromPath(mapPath)
+	if err != nil {
+		t.Fatalf("Failed to load saved map: %v", err)
+	}
+	return len(saved.Rooms)
+}
+
+func TestRapidRoomVisitsSaveMapOnce(t *testing.T) {
+	mapPath := filepath.Join(t.TempDir(), "map.example.com.4000.json")
+	worldMap, err := mapper.LoadFromPath(mapPath)
+	if err != nil {
+		t.Fatalf("Failed to load map: %v", err)
+	}
+	m := &Model{output: []string{}, worldMap: worldMap}
+
+	visitRoom(m, "north", "Road 1")
+	visitRoom(m, "north", "Road 2")
+	visitRoom(m, "north", "Road 3")
+
+	// Only the first visit is written within the save interval
+	if n := savedRooms(t, mapPath); n != 1 {
+		t.Fatalf("Expected 1 room saved within the interval, got %d", n)
+	}
+
+	m.autosave(time.Now().Add(saveInterval))
+	if n := savedRooms(t, mapPath); n != 3 {
+		t.Fatalf("Expected the tick after the interval to save 3 rooms, got %d", n)
+	}
+
+	// Quitting writes what is still waiting
+	visitRoom(m, "north", "Road 4")
+	if n := savedRooms(t, mapPath); n != 3 {
+		t.Fatalf("Expected the new room to wait for the interval, got %d", n)
+	}
+	m.FlushSaves()
+	if n := savedRooms(t, mapPath); n != 4 {
+		t.Errorf("Expected FlushSaves to save 4 rooms, got %d", n)
+	}
+}
@@ -0,0 +1,122 @@
+package tui
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/anicolao/dikuclient/internal/mapper"
+)
+
+// newAvoidTestModel creates a model where the shortest route to the Goal goes
+// through a Danger room and a longer safe detour exists
+func newAvoidTestModel(t *testing.T) (*Model, *mapper.Room) {
+	t.Setenv("DIKUCLIENT_CONFIG_DIR", t.TempDir())
+
+	worldMap := mapper.NewMap()
+
+	start := mapper.NewRoom("Start", "The start.", []string{"east", "north"})
+	danger := mapper.NewRoom("Danger", "A dragon lair.", []string{"east", "west"})
+	goal := mapper.NewRoom("Goal", "The goal.", []string{"west", "north"})
+	path1 := mapper.NewRoom("Path One", "A winding path.", []string{"east", "south"})
+	path2 := mapper.NewRoom("Path Two", "More winding path.", []string{"west", "south"})
+
+	for _, r := range []*mapper.Room{start, danger, goal, path1, path2} {
+		worldMap.AddOrUpdateRoom(r)
+	}
+	worldMap.CurrentRoomID = danger.ID
+
+	start.Exits["east"] = danger.ID
+	danger.Exits["west"] = start.ID
+	danger.Exits["east"] = goal.ID
+	goal.Exits["west"] = danger.ID
+	start.Exits["north"] = path1.ID
+	path1.Exits["south"] = start.ID
+	path1.Exits["east"] = path2.ID
+	path2.Exits["west"] = path1.ID
+	path2.Exits["south"] = goal.ID
+	goal.Exits["north"] = path2.ID
+
+	return &Model{
+		output:   []string{},
+		worldMap: worldMap,
+	}, start
+}
+
+func TestAvoidCommandTogglesCurrentRoom(t *testing.T) {
+	m, _ := newAvoidTestModel(t)
+	danger := m.worldMap.GetCurrentRoom()
+
+	m.handleAvoidCommand([]string{})
+	if !danger.Avoid {
+		t.Fatal("Expected current room to be marked as avoided")
+	}
+
+	m.handleAvoidCommand([]string{})
+	if danger.Avoid {
+		t.Error("Expected second /avoid to clear the flag")
+	}
+}
+
+func TestAvoidCommandChangesGoRoute(t *testing.T) {
+	m, start := newAvoidTestModel(t)
+
+	// Mark the danger room while standing in it, then move back to the start
+	m.handleAvoidCommand([]string{})
+	m.worldMap.CurrentRoomID = start.ID
+	m.output = []string{}
+
+	m.handleGoCommand([]string{"goal"})
+	if !m.autoWalking {
+		t.Fatal("Expected auto-walk to start")
+	}
+	expected := []string{"north", "east", "south"}
+	if strings.Join(m.autoWalkPath, ",") != strings.Join(expected, ",") {
+		t.Errorf("Expected safe route %v, got %v", expected, m.autoWalkPath)
+	}
+	for _, line := range m.output {
+		if strings.Contains(line, "Warning") {
+			t.Errorf("Expected no warning for a safe route, got %q", line)
+		}
+	}
+}
+
+func TestAvoidWarnsWhenNoSafeRoute(t *testing.T) {
+	m, start := newAvoidTestModel(t)
+	m.handleAvoidCommand([]string{})
+	m.worldMap.CurrentRoomID = start.ID
+	delete(start.Exits, "north")
+	m.output = []string{}
+
+	m.handleWayfindCommand([]string{"goal"})
+
+	output := strings.Join(m.output, "\n")
+	if !strings.Contains(output, "east -> Danger") {
+		t.Errorf("Expected path through the avoided room, got:\n%s", output)
+	}
+	if !strings.Contains(output, "Warning") || !strings.Contains(output, "Danger") {
+		t.Errorf("Expected warning naming the avoided room, got:\n%s", output)
+	}
+}
+
+func TestAvoidFlagPersists(t *testing.T) {
+	m, _ := newAvoidTestModel(t)
+	m.handleAvoidCommand([]string{})
+
+	mapPath, err := mapper.GetMapPath()
+	if err != nil {
+		t.Fatalf("Failed to get map path: %v", err)
+	}
+	if _, err := os.Stat(mapPath); err != nil {
+		t.Fatalf("Expected map to be saved: %v", err)
+	}
+
+	loaded, err := mapper.LoadFromPath(mapPath)
+	if err != nil {
+		t.Fatalf("Failed to load map: %v", err)
+	}
+	room := loaded.Rooms[m.worldMap.CurrentRoomID]
+	if room == nil || !room.Avoid {
+		t.Error("Expected avoid flag to be persisted with the map")
+	}
+}
@@ -0,0 +1,58 @@
+package tui
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/anicolao/dikuclient/internal/captures"
+)
+
+// TestCaptureFillsQuestPanel verifies /capture fills a Quests panel from the
+// quest lines, updating a quest's entry as its progress changes
+func TestCaptureFillsQuestPanel(t *testing.T) {
+	m, _ := newConnectedTestModel(t)
+	captureManager, err := captures.LoadFromPath(filepath.Join(t.TempDir(), "captures.json"))
+	if err != nil {
+		t.Fatalf("Failed to load captures: %v", err)
+	}
+	m.captureManager = captureManager
+
+	m.handleClientCommand(`/capture quest "Quest: (.+) - (\d+)/(\d+)" "<1>: <2>/<3>"`)
+	if len(m.captureManager.Captures) != 1 {
+		t.Fatalf("Expected one capture, got %+v", m.captureManager.Captures)
+	}
+
+	m.Update(mudMsg("Quest: Slay the rats - 3/10\nQuest: Find the ring - 0/1\n" + testPrompt))
+	m.Update(mudMsg("You kill a rat.\nQuest: Slay the rats - 4/10\n" + testPrompt))
+
+	want := []string{"Slay the rats: 4/10", "Find the ring: 0/1"}
+	if got := m.userPanels["Quests"]; !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected the Quests panel %q, got %q", want, got)
+	}
+	if got := m.captureRecords["quest"].Entries["Slay the rats"]; got != "Slay the rats: 4/10" {
+		t.Errorf("Expected the quest record updated, got %q", got)
+	}
+
+	m.handleClientCommand("/captures clear quest")
+	if _, ok := m.userPanels["Quests"]; ok || m.captureRecords["quest"] != nil {
+		t.Error("Expected /captures clear to empty the record and close its panel")
+	}
+}
+
+// TestCaptureCommandErrors verifies a capture without a quoted pattern or
+// with a bad one is refused
+func TestCaptureCommandErrors(t *testing.T) {
+	m := &Model{output: []string{}, captureManager: captures.NewManager()}
+
+	m.handleClientCommand("/capture quest Quest: (.+)")
+	m.handleClientCommand(`/capture quest "Quest: ("`)
+	if len(m.captureManager.Captures) != 0 {
+		t.Fatalf("Expected no captures added, got %+v", m.captureManager.Captures)
+	}
+	out := strings.Join(m.output, "\n")
+	if !strings.Contains(out, "Usage: /capture") || !strings.Contains(out, "invalid pattern") {
+		t.Errorf("Expected usage and an invalid pattern error, got:\n%s", out)
+	}
+}
@@ -0,0 +1,117 @@
+package tui
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/anicolao/dikuclient/internal/aliases"
+	"github.com/anicolao/dikuclient/internal/settings"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func newCollapseTestModel(t *testing.T) *Model {
+	m := newHidePromptTestModel(t)
+	m.handleHidePromptCommand([]string{"off"})
+	m.handleCollapseCommand([]string{"on"})
+	m.output = []string{}
+	return m
+}
+
+func TestCollapseBlankLineRun(t *testing.T) {
+	m := newCollapseTestModel(t)
+
+	m.Update(mudMsg("The sun rises.\n\n\n\n\x1b[0m  \nA goblin arrives.\n\nIt looks hungry.\n"))
+
+	expected := []string{"The sun rises.", "", "A goblin arrives.", "", "It looks hungry."}
+	if strings.Join(m.output, "|") != strings.Join(expected, "|") {
+		t.Errorf("Expected output %q, got %q", expected, m.output)
+	}
+
+	// The detectors still see every line
+	if len(m.recentOutput) != 8 {
+		t.Errorf("Expected all 8 lines in recent output, got %d", len(m.recentOutput))
+	}
+}
+
+func TestCollapseRunAcrossMessages(t *testing.T) {
+	m := newCollapseTestModel(t)
+
+	m.Update(mudMsg("The sun rises.\n\n"))
+	m.Update(mudMsg("\n\nA goblin arrives.\n"))
+
+	expected := []string{"The sun rises.", "", "A goblin arrives."}
+	if strings.Join(m.output, "|") != strings.Join(expected, "|") {
+		t.Errorf("Expected output %q, got %q", expected, m.output)
+	}
+}
+
+func TestCollapseOffKeepsBlankLines(t *testing.T) {
+	m := newCollapseTestModel(t)
+	m.handleCollapseCommand([]string{"off"})
+	m.output = []string{}
+
+	m.Update(mudMsg("The sun rises.\n\n\nA goblin arrives.\n"))
+
+	if len(m.output) != 4 {
+		t.Errorf("Expected blank lines kept with collapsing off, got %q", m.output)
+	}
+}
+
+func TestCollapseKeepsClientSpacing(t *testing.T) {
+	m := newCollapseTestModel(t)
+
+	// Client output after a blank line from the MUD ends the run, so the
+	// spacing around it stays as it is
+	m.Update(mudMsg("The sun rises.\n\n"))
+	m.output = append(m.output, "Client output", "")
+	m.Update(mudMsg("\nA goblin arrives.\n"))
+
+	expected := []string{"The sun rises.", "", "Client output", "", "", "A goblin arrives."}
+	if strings.Join(m.output, "|") != strings.Join(expected, "|") {
+		t.Errorf("Expected output %q, got %q", expected, m.output)
+	}
+}
+
+func TestCollapseKeepsTypedCommand(t *testing.T) {
+	m, _ := newConnectedTestModel(t)
+	m.settingsManager = newCollapseTestModel(t).settingsManager
+	m.xpTracking = make(map[string]*XPStat)
+	m.aliasManager = aliases.NewManager()
+	m.output = []string{}
+
+	// A command typed on the blank line after the MUD's output isn't
+	// collapsed away by the blank line the reply starts with
+	m.Update(mudMsg("The sun rises.\n\n"))
+	m.currentInput = "look"
+	m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m.Update(mudMsg("\n\nA goblin arrives.\n"))
+
+	expected := []string{"The sun rises.", "\x1b[93mlook\x1b[0m", "", "A goblin arrives."}
+	if strings.Join(m.output, "|") != strings.Join(expected, "|") {
+		t.Errorf("Expected output %q, got %q", expected, m.output)
+	}
+}
+
+func TestCollapseSettingPersists(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "settings.json")
+	settingsManager, err := settings.LoadFromPath(path)
+	if err != nil {
+		t.Fatalf("Failed to load settings: %v", err)
+	}
+	m := &Model{settingsManager: settingsManager}
+	m.handleCollapseCommand([]string{"on"})
+
+	reloaded, err := settings.LoadFromPath(path)
+	if err != nil {
+		t.Fatalf("Failed to reload settings: %v", err)
+	}
+	if !reloaded.CollapseBlanks {
+		t.Error("Expected /collapse on to be saved")
+	}
+
+	m.handleCollapseCommand([]string{"sideways"})
+	if last := m.output[len(m.output)-1]; !strings.Contains(last, "Usage") {
+		t.Errorf("Expected usage, got %q", last)
+	}
+}
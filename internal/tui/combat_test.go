@@ -0,0 +1,79 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/anicolao/dikuclient/internal/mapper"
+	"github.com/charmbracelet/bubbles/viewport"
+)
+
+func TestCombatLogSummarizesFight(t *testing.T) {
+	m := &Model{
+		output:     []string{},
+		worldMap:   mapper.NewMap(),
+		xpTracking: make(map[string]*XPStat),
+		xpViewport: viewport.New(56, 10),
+	}
+
+	m.Update(mudMsg("Your slash hits the orc very hard.\nThe orc's claw wounds you.\n101H 132V 1000X [Osric:Good] [the orc:Bad]> "))
+
+	if m.combatLog == nil || m.combatLog.Current == nil {
+		t.Fatal("Expected a fight to be in progress")
+	}
+	sidebar := m.renderSidebar(60, 40)
+	if !strings.Contains(sidebar, "Combat") || !strings.Contains(sidebar, "Dealt 13 dmg (1/1 hits)") {
+		t.Errorf("Expected Combat panel with the damage dealt, got:\n%s", sidebar)
+	}
+
+	// The combat panel shares the XP slot, so the sidebar stays the same height
+	empty := &Model{xpViewport: viewport.New(56, 10), worldMap: mapper.NewMap()}
+	if got, want := strings.Count(sidebar, "\n"), strings.Count(empty.renderSidebar(60, 40), "\n"); got != want {
+		t.Errorf("Expected sidebar height %d with combat panel, got %d", want, got)
+	}
+
+	m.Update(mudMsg("Your slash misses the orc.\nThe orc is dead!\n"))
+
+	if m.combatLog.Current != nil {
+		t.Error("Expected the fight to end when the orc died")
+	}
+	output := strings.Join(m.output, "\n")
+	if !strings.Contains(output, "[Combat: the orc - dealt 13 dmg (1/2 hits), taken 10 dmg (1/1 hits)") {
+		t.Errorf("Expected fight summary, got %q", m.output)
+	}
+
+	m.output = []string{}
+	m.handleCombatCommand("combat")
+	if len(m.output) != 1 || !strings.Contains(m.output[0], "Last fight: the orc") {
+		t.Errorf("Expected last fight to be shown, got %q", m.output)
+	}
+}
+
+func TestCombatPatternCommand(t *testing.T) {
+	t.Setenv("DIKUCLIENT_CONFIG_DIR", t.TempDir())
+	m := &Model{
+		output:     []string{},
+		worldMap:   mapper.NewMap(),
+		xpTracking: make(map[string]*XPStat),
+	}
+
+	m.handleCombatCommand(`combat pattern dealt "^You strike (?P<target>.+) for (?P<amount>\d+) damage\.$"`)
+	if m.combatManager == nil || len(m.combatManager.Patterns) != 1 {
+		t.Fatalf("Expected custom pattern to be added, got %q", m.output)
+	}
+
+	m.Update(mudMsg("You strike the troll for 27 damage.\n"))
+	if m.combatLog.Current == nil || m.combatLog.Current.Dealt.Damage != 27 {
+		t.Errorf("Expected custom pattern to record 27 damage, got %+v", m.combatLog.Current)
+	}
+
+	m.handleCombatCommand("combat pattern taken \"(?P<who>.+) hits you\"")
+	if !strings.Contains(m.output[len(m.output)-1], "attacker") {
+		t.Errorf("Expected error about the attacker group, got %q", m.output[len(m.output)-1])
+	}
+
+	m.handleCombatCommand("combat pattern remove 1")
+	if len(m.combatManager.Patterns) != 0 {
+		t.Errorf("Expected pattern to be removed, got %+v", m.combatManager.Patterns)
+	}
+}
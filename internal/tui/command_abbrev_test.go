@@ -0,0 +1,73 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/anicolao/dikuclient/internal/ansi"
+)
+
+func TestResolveClientCommand(t *testing.T) {
+	tests := []struct {
+		name       string
+		want       string
+		candidates []string
+	}{
+		{"way", "wayfind", nil},
+		{"wayfind", "wayfind", nil},
+		{"trigger", "trigger", nil}, // A full name wins over longer names it starts
+		{"reconnect", "reconnect", nil},
+		{"promptn", "promptnewline", nil},
+		{"t", "t", []string{"trail", "tab", "theme", "telnet", "target", "throttle", "trigger", "triggers", "ticktrigger", "ticktriggers"}},
+		{"trig", "trig", []string{"trigger", "triggers"}},
+		{"xyzzy", "xyzzy", nil},
+	}
+	for _, tt := range tests {
+		got, candidates := resolveClientCommand(tt.name)
+		if got != tt.want || strings.Join(candidates, ",") != strings.Join(tt.candidates, ",") {
+			t.Errorf("resolveClientCommand(%q) = %q, %v; want %q, %v", tt.name, got, candidates, tt.want, tt.candidates)
+		}
+	}
+}
+
+func TestAbbreviatedCommandDispatches(t *testing.T) {
+	m := newHidePromptTestModel(t)
+
+	// An abbreviation does exactly what the full command does
+	m.handleClientCommand("/wayfind temple")
+	full := strings.Join(m.output, "\n")
+	m.output = nil
+	m.handleClientCommand("/way temple")
+	if got := strings.Join(m.output, "\n"); got != full || full == "" {
+		t.Errorf("Expected /way to run /wayfind, got %q want %q", got, full)
+	}
+
+	// Handlers that parse the whole command line see the full name
+	m.output = nil
+	m.handleClientCommand(`/focus "orc"`)
+	full = strings.Join(m.output, "\n")
+	m.handleClientCommand("/focus off")
+	m.output = nil
+	m.handleClientCommand(`/foc "orc"`)
+	if got := strings.Join(m.output, "\n"); got != full || m.focus == nil {
+		t.Errorf("Expected /foc to run /focus, got %q want %q", got, full)
+	}
+}
+
+func TestAmbiguousCommandListsCandidates(t *testing.T) {
+	m := newHidePromptTestModel(t)
+
+	m.handleClientCommand("/trig list")
+	if len(m.output) != 1 {
+		t.Fatalf("Expected one error line, got %q", m.output)
+	}
+	if got := ansi.Strip(m.output[0]); got != "Error: '/trig' is ambiguous: /trigger, /triggers" {
+		t.Errorf("Unexpected message %q", got)
+	}
+
+	m.output = nil
+	m.handleClientCommand("/xyzzy")
+	if len(m.output) != 1 || !strings.Contains(m.output[0], "Unknown command '/xyzzy'") {
+		t.Errorf("Expected an unknown command error, got %q", m.output)
+	}
+}
@@ -0,0 +1,58 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/anicolao/dikuclient/internal/script"
+)
+
+func TestStartupScriptRunsOnceConnected(t *testing.T) {
+	steps, err := script.Parse(strings.NewReader("# login\nwear all\n\nwait 2\n/set target orc\n"))
+	if err != nil {
+		t.Fatalf("Failed to parse script: %v", err)
+	}
+
+	m, _ := newConnectedTestModel(t)
+	m.SetStartupScript(steps)
+
+	if cmd := m.runStartupScript(); cmd == nil {
+		t.Fatal("Expected the script to start the command queue")
+	}
+	expected := []string{"wear all", "/wait 2", "/set target orc"}
+	if strings.Join(m.pendingCommands, "|") != strings.Join(expected, "|") {
+		t.Errorf("Expected queue %q, got %q", expected, m.pendingCommands)
+	}
+
+	// The script only runs once
+	m.pendingCommands = nil
+	if cmd := m.runStartupScript(); cmd != nil || len(m.pendingCommands) != 0 {
+		t.Errorf("Expected the script not to run again, got %q", m.pendingCommands)
+	}
+}
+
+func TestQueueWaitPausesQueue(t *testing.T) {
+	m, server := newConnectedTestModel(t)
+	m.variables = make(map[string]string)
+	m.pendingCommands = []string{"/wait 2", "/set target orc", "kill @target"}
+	m.commandQueueActive = true
+
+	// The wait is taken off the queue and nothing else runs on this tick
+	m.Update(commandQueueTickMsg{})
+	if len(m.pendingCommands) != 2 || m.variables["target"] != "" {
+		t.Fatalf("Expected the queue to pause at the wait, got %q", m.pendingCommands)
+	}
+
+	m.Update(commandQueueTickMsg{})
+	if sent := readSent(t, server); sent != "kill orc" {
+		t.Errorf("Expected 'kill orc' after the wait, got %q", sent)
+	}
+
+	if wait, ok := parseQueueWait(queueWaitCommand(1500 * time.Millisecond)); !ok || wait != 1500*time.Millisecond {
+		t.Errorf("Expected a 1.5s wait to round-trip, got %v (%v)", wait, ok)
+	}
+	if _, ok := parseQueueWait("/wait soon"); ok {
+		t.Error("Expected an invalid wait not to be a pause")
+	}
+}
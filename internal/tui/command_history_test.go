@@ -1,8 +1,11 @@
 package tui
 
 import (
+	"reflect"
 	"testing"
 
+	tea "github.com/charmbracelet/bubbletea"
+
 	"github.com/anicolao/dikuclient/internal/mapper"
 )
 
@@ -794,3 +797,50 @@ func TestNormalCommandAfterPasswordPrompt(t *testing.T) {
 		t.Errorf("Expected 'north' in history, got '%s'", m.commandHistory[0])
 	}
 }
+
+// TestHistoryPrefixRecall verifies Up with text typed only recalls commands
+// starting with it, newest first, and Down walks back to the typed text
+func TestHistoryPrefixRecall(t *testing.T) {
+	m, _ := newConnectedTestModel(t)
+	m.historyIndex = -1
+	m.commandHistory = []string{"kill orc", "north", "kill rat", "look", "kill orc", "say hi"}
+
+	m.currentInput = "kill "
+	m.cursorPos = len(m.currentInput)
+	var recalled []string
+	for i := 0; i < 4; i++ {
+		m.Update(tea.KeyMsg{Type: tea.KeyUp})
+		recalled = append(recalled, m.currentInput)
+	}
+	want := []string{"kill orc", "kill rat", "kill orc", "kill orc"}
+	if !reflect.DeepEqual(recalled, want) {
+		t.Errorf("Expected Up to recall %v, got %v", want, recalled)
+	}
+
+	m.Update(tea.KeyMsg{Type: tea.KeyDown})
+	if m.currentInput != "kill rat" {
+		t.Errorf("Expected Down to skip to the next match, got %q", m.currentInput)
+	}
+	m.Update(tea.KeyMsg{Type: tea.KeyDown})
+	m.Update(tea.KeyMsg{Type: tea.KeyDown})
+	if m.currentInput != "kill " || m.historyIndex != -1 {
+		t.Errorf("Expected Down past the last match to restore the typed text, got %q (index %d)", m.currentInput, m.historyIndex)
+	}
+}
+
+// TestHistoryRecallEmptyInput verifies Up on an empty line walks the whole
+// history
+func TestHistoryRecallEmptyInput(t *testing.T) {
+	m, _ := newConnectedTestModel(t)
+	m.historyIndex = -1
+	m.commandHistory = []string{"kill orc", "north", "look"}
+
+	var recalled []string
+	for i := 0; i < 3; i++ {
+		m.Update(tea.KeyMsg{Type: tea.KeyUp})
+		recalled = append(recalled, m.currentInput)
+	}
+	if want := []string{"look", "north", "kill orc"}; !reflect.DeepEqual(recalled, want) {
+		t.Errorf("Expected Up to recall %v, got %v", want, recalled)
+	}
+}
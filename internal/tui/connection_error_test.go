@@ -0,0 +1,63 @@
+package tui
+
+import (
+	"net"
+	"os"
+	"strings"
+	"syscall"
+	"testing"
+
+	"github.com/anicolao/dikuclient/internal/client"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// TestRecoverableConnectionErrorStaysOpen verifies a refused connection
+// shows a friendly message and leaves the client running for /reconnect
+func TestRecoverableConnectionErrorStaysOpen(t *testing.T) {
+	m, _ := newConnectedTestModel(t)
+	m.output = nil
+	m.conn.Close()
+
+	refused := &client.ConnectionError{
+		Kind:    client.ErrorRefused,
+		Address: "mud.example.com:4000",
+		Err:     &net.OpError{Op: "dial", Net: "tcp", Err: os.NewSyscallError("connect", syscall.ECONNREFUSED)},
+	}
+	if _, cmd := m.Update(errMsg(refused)); cmd != nil {
+		t.Fatal("Expected the client to stay open after a refused connection")
+	}
+	output := strings.Join(m.output, "\n")
+	if !strings.Contains(output, "Connection to mud.example.com:4000 was refused") || strings.Contains(output, "connect: connection refused") {
+		t.Errorf("Expected the friendly message instead of the raw error, got:\n%s", output)
+	}
+	if m.connected {
+		t.Error("Expected the session marked disconnected")
+	}
+
+	// Client commands still run while disconnected
+	m.output = nil
+	m.currentInput = "/reconnect-on"
+	m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if !strings.Contains(strings.Join(m.output, "\n"), "Reconnect on reboot warning") {
+		t.Errorf("Expected the client command to run, got:\n%s", strings.Join(m.output, "\n"))
+	}
+}
+
+// TestUnrecoverableConnectionErrorQuits verifies a bad host name ends the
+// program with the friendly message to print on exit
+func TestUnrecoverableConnectionErrorQuits(t *testing.T) {
+	m, _ := newConnectedTestModel(t)
+	m.conn.Close()
+
+	dnsErr := &client.ConnectionError{
+		Kind:    client.ErrorDNS,
+		Address: "nosuch.example:4000",
+		Err:     &net.DNSError{Name: "nosuch.example", Err: "no such host", IsNotFound: true},
+	}
+	if _, cmd := m.Update(errMsg(dnsErr)); cmd == nil {
+		t.Fatal("Expected the client to quit after an unknown host")
+	}
+	if got := m.ExitMessage(); got != "Could not resolve host nosuch.example — check the hostname" {
+		t.Errorf("Unexpected exit message %q", got)
+	}
+}
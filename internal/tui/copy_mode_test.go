@@ -0,0 +1,138 @@
+package tui
+
+import (
+	"bytes"
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"github.com/anicolao/dikuclient/internal/mapper"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestSelectedText(t *testing.T) {
+	lines := []string{
+		"\x1b[1;33mThe Temple Square\x1b[0m",
+		"You are standing in a large square.  ",
+		"Exits: north south",
+	}
+
+	tests := []struct {
+		name       string
+		start, end cellPos
+		want       string
+	}{
+		{"single cell", cellPos{0, 4}, cellPos{0, 4}, "T"},
+		{"one word", cellPos{0, 4}, cellPos{0, 9}, "Temple"},
+		{"dragged backwards", cellPos{0, 9}, cellPos{0, 4}, "Temple"},
+		{"across lines", cellPos{0, 11}, cellPos{2, 4}, "Square\nYou are standing in a large square.\nExits"},
+		{"upwards across lines", cellPos{2, 4}, cellPos{0, 11}, "Square\nYou are standing in a large square.\nExits"},
+		{"past the end of a line", cellPos{0, 11}, cellPos{0, 70}, "Square"},
+		{"past the last line", cellPos{2, 7}, cellPos{9, 0}, "north south"},
+		{"trailing spaces dropped", cellPos{1, 20}, cellPos{1, 40}, "a large square."},
+	}
+
+	for _, tt := range tests {
+		if got := selectedText(lines, tt.start, tt.end); got != tt.want {
+			t.Errorf("%s: selectedText(%v, %v) = %q, want %q", tt.name, tt.start, tt.end, got, tt.want)
+		}
+	}
+}
+
+func TestSelectionColumns(t *testing.T) {
+	start, end := cellPos{1, 5}, cellPos{3, 2}
+	tests := []struct {
+		line, length int
+		from, to     int
+	}{
+		{1, 20, 5, 20}, // First line: from the start column
+		{2, 10, 0, 10}, // Middle line: all of it
+		{3, 20, 0, 3},  // Last line: up to and including the end column
+		{1, 3, 3, 3},   // First line shorter than the start column
+	}
+
+	for _, tt := range tests {
+		from, to := selectionColumns(start, end, tt.line, tt.length)
+		if from != tt.from || to != tt.to {
+			t.Errorf("selectionColumns(line %d, length %d) = [%d, %d), want [%d, %d)", tt.line, tt.length, from, to, tt.from, tt.to)
+		}
+	}
+}
+
+func TestCellAtPosition(t *testing.T) {
+	m := &Model{output: []string{}, worldMap: mapper.NewMap(), sidebarWidth: 60}
+	m.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	m.View()
+	m.viewport.YOffset = 10
+
+	// The viewport starts below the status bar and the top border, one
+	// column in from the left border
+	if got := m.cellAtPosition(5, 4); got != (cellPos{line: 12, col: 4}) {
+		t.Errorf("Expected line 12 column 4, got %+v", got)
+	}
+	// Dragging past the edges stays on the nearest cell
+	if got := m.cellAtPosition(0, 0); got != (cellPos{line: 10, col: 0}) {
+		t.Errorf("Expected the top left cell, got %+v", got)
+	}
+	if got := m.cellAtPosition(500, 500); got != (cellPos{line: 10 + m.viewport.Height - 1, col: 120 - 60 - 2}) {
+		t.Errorf("Expected the bottom right cell, got %+v", got)
+	}
+}
+
+func TestCopyModeCopiesSelection(t *testing.T) {
+	var clipboard bytes.Buffer
+	m := &Model{
+		output:       []string{"The Temple Square", "You are standing in a large square."},
+		worldMap:     mapper.NewMap(),
+		sidebarWidth: 60,
+		clipboard:    &clipboard,
+	}
+	m.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	m.updateViewport()
+	m.View()
+
+	m.Update(tea.KeyMsg{Type: tea.KeyCtrlAt})
+	if !m.copyMode || !strings.Contains(m.renderStatusBar(), "COPY MODE") {
+		t.Fatal("Expected Ctrl+Space to enter copy mode")
+	}
+
+	// Drag from "Temple" on the first line to "standing" on the second
+	m.Update(tea.MouseMsg{X: 5, Y: 2, Action: tea.MouseActionPress, Button: tea.MouseButtonLeft})
+	m.Update(tea.MouseMsg{X: 12, Y: 3, Action: tea.MouseActionMotion, Button: tea.MouseButtonLeft})
+	m.Update(tea.MouseMsg{X: 17, Y: 3, Action: tea.MouseActionRelease, Button: tea.MouseButtonLeft})
+	if !strings.Contains(m.viewport.View(), "\x1b[7m") {
+		t.Errorf("Expected the selection to be highlighted, got %q", m.viewport.View())
+	}
+
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("y")})
+	want := "\x1b]52;c;" + base64.StdEncoding.EncodeToString([]byte("Temple Square\nYou are standing")) + "\a"
+	if clipboard.String() != want {
+		t.Errorf("Expected OSC 52 sequence %q, got %q", want, clipboard.String())
+	}
+	if m.copyMode {
+		t.Error("Expected copying to leave copy mode")
+	}
+}
+
+func TestCopyModeEscLeaves(t *testing.T) {
+	m := &Model{output: []string{"Hello"}, worldMap: mapper.NewMap(), sidebarWidth: 60}
+	m.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+
+	m.Update(tea.KeyMsg{Type: tea.KeyCtrlAt})
+	_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if m.copyMode {
+		t.Error("Expected Esc to leave copy mode")
+	}
+	if cmd != nil {
+		if _, quit := cmd().(tea.QuitMsg); quit {
+			t.Error("Expected Esc in copy mode not to quit")
+		}
+	}
+
+	// Typing in copy mode doesn't reach the input line
+	m.Update(tea.KeyMsg{Type: tea.KeyCtrlAt})
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("look")})
+	if m.currentInput != "" {
+		t.Errorf("Expected no input typed in copy mode, got %q", m.currentInput)
+	}
+}
@@ -0,0 +1,96 @@
+package tui
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/anicolao/dikuclient/internal/debuglog"
+	"github.com/anicolao/dikuclient/internal/mapper"
+)
+
+// TestDebugDump verifies /debug dump writes the mapper and queue state
+// without the login name and password
+func TestDebugDump(t *testing.T) {
+	t.Setenv("DIKUCLIENT_CONFIG_DIR", t.TempDir())
+	m := &Model{
+		output:   []string{"[Auto-login: sending username 'frodo']", "> "},
+		worldMap: mapper.NewMap(),
+		username: "frodo",
+		password: "s3cret-ring",
+	}
+	visitRoom(m, "north", "Shire Road")
+	m.pendingMovement = "east"
+	m.pendingCommands = []string{"south", "s3cret-ring"}
+	m.autoWalking = true
+	m.autoWalkTarget = "Bree"
+
+	path := filepath.Join(t.TempDir(), "dump.txt")
+	m.handleClientCommand("/debug dump " + path)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Expected the dump to be written: %v", err)
+	}
+	dump := string(data)
+
+	for _, want := range []string{
+		`Current room: #1 "Shire Road"`,
+		`Pending movement: "east"`,
+		"Auto-walking: true",
+		`Auto-walk target: "Bree"`,
+		`Queued commands: ["south" "[redacted]"]`,
+		"Exits: north, south",
+	} {
+		if !strings.Contains(dump, want) {
+			t.Errorf("Expected the dump to contain %q, got:\n%s", want, dump)
+		}
+	}
+	for _, secret := range []string{"s3cret-ring", "frodo"} {
+		if strings.Contains(dump, secret) {
+			t.Errorf("Expected %q to be left out of the dump", secret)
+		}
+	}
+}
+
+// TestDebugFlushAndLevel verifies /debug flush writes out the buffered MUD
+// log and /debug level changes what the telnet debug log records
+func TestDebugFlushAndLevel(t *testing.T) {
+	dir := t.TempDir()
+	mudFile, err := os.Create(filepath.Join(dir, "mud.log"))
+	if err != nil {
+		t.Fatalf("Failed to create MUD log: %v", err)
+	}
+	defer mudFile.Close()
+	m := &Model{
+		mudLog:         debuglog.New(mudFile, 0, 0),
+		telnetDebugLog: debuglog.New(io.Discard, 0, 0),
+	}
+
+	m.Update(mudMsg("The gates are open.\n"))
+	if data, _ := os.ReadFile(mudFile.Name()); strings.Contains(string(data), "gates") {
+		t.Fatal("Expected the MUD output held in the buffer")
+	}
+	m.handleClientCommand("/debug flush")
+	if data, _ := os.ReadFile(mudFile.Name()); !strings.Contains(string(data), "The gates are open.") {
+		t.Errorf("Expected /debug flush to write the MUD output, got %q", data)
+	}
+
+	m.handleClientCommand("/debug level negotiation")
+	if m.telnetDebugLog.Enabled(debuglog.All) || !m.telnetDebugLog.Enabled(debuglog.Negotiation) {
+		t.Error("Expected the telnet debug log limited to negotiation")
+	}
+	m.output = nil
+	m.handleClientCommand("/debug level loud")
+	if !strings.Contains(strings.Join(m.output, "\n"), "unknown debug level") {
+		t.Errorf("Expected an unknown level rejected, got:\n%s", strings.Join(m.output, "\n"))
+	}
+
+	// Without --log-all there is nothing to flush
+	m = &Model{}
+	m.handleClientCommand("/debug flush")
+	if !strings.Contains(strings.Join(m.output, "\n"), "Logging is off") {
+		t.Errorf("Expected a warning without logs, got:\n%s", strings.Join(m.output, "\n"))
+	}
+}
@@ -0,0 +1,46 @@
+package tui
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/anicolao/dikuclient/internal/mapper"
+)
+
+func TestDigCommand(t *testing.T) {
+	mapPath := filepath.Join(t.TempDir(), "map.example.com.4000.json")
+	worldMap, err := mapper.LoadFromPath(mapPath)
+	if err != nil {
+		t.Fatalf("Failed to load map: %v", err)
+	}
+	worldMap.AddOrUpdateRoom(mapper.NewRoom("Cave Mouth", "A dark opening.", []string{"north"}))
+	m := &Model{output: []string{}, worldMap: worldMap}
+
+	m.handleDigCommand(`n "Dark Tunnel"`)
+	if !strings.Contains(m.output[len(m.output)-1], "Dug north to room #2 'Dark Tunnel'") {
+		t.Fatalf("Expected the new room to be reported, got %q", m.output)
+	}
+
+	reloaded, err := mapper.LoadFromPath(mapPath)
+	if err != nil {
+		t.Fatalf("Failed to reload map: %v", err)
+	}
+	tunnel := reloaded.GetCurrentRoom()
+	if tunnel == nil || tunnel.Title != "Dark Tunnel" {
+		t.Fatalf("Expected the saved map to be in the tunnel, got %+v", tunnel)
+	}
+	if cave := reloaded.GetRoomByNumber(1); cave.Exits["north"] != tunnel.ID || tunnel.Exits["south"] != cave.ID {
+		t.Errorf("Expected exits both ways, got %v and %v", cave.Exits, tunnel.Exits)
+	}
+
+	m.handleDigCommand("north")
+	if !strings.Contains(m.output[len(m.output)-1], "Usage: /dig") {
+		t.Errorf("Expected usage without a title, got %q", m.output)
+	}
+
+	m.handleDigCommand(`south "Somewhere Else"`)
+	if !strings.Contains(m.output[len(m.output)-1], "south already leads to 'Cave Mouth'") {
+		t.Errorf("Expected an error for a known exit, got %q", m.output)
+	}
+}
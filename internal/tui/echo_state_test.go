@@ -0,0 +1,63 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/anicolao/dikuclient/internal/aliases"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestEchoTogglesAfterLogin(t *testing.T) {
+	m, server := newConnectedTestModel(t)
+	m.xpTracking = make(map[string]*XPStat)
+	m.aliasManager = aliases.NewManager()
+	m.width = 120
+	m.autoLoginState = 2 // Logged in long ago
+
+	// The server hides input for a prompt the text heuristic doesn't know
+	m.Update(mudMsg("Are you sure you want to delete this character? "))
+	m.Update(echoStateMsg(true))
+	if !m.echoSuppressed {
+		t.Fatal("Expected the echo state to follow the server after login")
+	}
+	if status := m.renderStatusBar(); !strings.Contains(status, "(hidden input)") {
+		t.Errorf("Expected a hidden input note in the status bar, got %q", status)
+	}
+
+	m.currentInput = "yes"
+	m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if sent := readSent(t, server); sent != "yes" {
+		t.Errorf("Expected 'yes' to be sent, got %q", sent)
+	}
+	if len(m.commandHistory) != 0 {
+		t.Errorf("Expected hidden input to stay out of history, got %q", m.commandHistory)
+	}
+	if last := m.output[len(m.output)-1]; strings.Contains(last, "yes") {
+		t.Errorf("Expected hidden input not to be echoed, got %q", last)
+	}
+
+	// Turning echo back on restores normal input
+	m.Update(echoStateMsg(false))
+	if status := m.renderStatusBar(); strings.Contains(status, "(hidden input)") {
+		t.Errorf("Expected no hidden input note after echo is back, got %q", status)
+	}
+	m.currentInput = "look"
+	m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	readSent(t, server)
+	if len(m.commandHistory) != 1 || m.commandHistory[0] != "look" {
+		t.Errorf("Expected 'look' in history, got %q", m.commandHistory)
+	}
+}
+
+func TestHiddenInputNoteSkippedAtPasswordPrompt(t *testing.T) {
+	m, _ := newConnectedTestModel(t)
+	m.width = 120
+	m.output = []string{"Password: "}
+	m.echoSuppressed = true
+
+	// The prompt already says why the input is hidden
+	if status := m.renderStatusBar(); strings.Contains(status, "(hidden input)") {
+		t.Errorf("Expected no note at a password prompt, got %q", status)
+	}
+}
@@ -0,0 +1,71 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/anicolao/dikuclient/internal/aliases"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// TestEchoCommand tests that /echo prints locally with variables substituted
+func TestEchoCommand(t *testing.T) {
+	m := &Model{
+		output:    []string{},
+		variables: map[string]string{"target": "orc"},
+	}
+
+	m.handleClientCommand("/echo Attacking   @target now")
+
+	if len(m.output) != 1 {
+		t.Fatalf("Expected 1 line of output, got %d: %v", len(m.output), m.output)
+	}
+	if want := m.colors().Info.Render("Attacking   orc now"); m.output[0] != want {
+		t.Errorf("Expected echoed text with variable substituted in the info color %q, got %q", want, m.output[0])
+	}
+}
+
+// TestEchoFollowsTheme tests that /echo takes its color from the theme and
+// is left plain with /ansi off
+func TestEchoFollowsTheme(t *testing.T) {
+	m, _ := newConnectedTestModel(t)
+
+	m.handleClientCommand("/theme set info 196")
+	m.output = nil
+	m.handleClientCommand("/echo hello")
+	if len(m.output) != 1 || !strings.Contains(m.output[0], "\x1b[38;5;196m") {
+		t.Errorf("Expected /echo in the theme's info color, got %q", m.output)
+	}
+
+	m.handleAnsiCommand([]string{"off"})
+	m.output = nil
+	m.handleClientCommand("/echo hello")
+	if len(m.output) != 1 || m.output[0] != "hello" {
+		t.Errorf("Expected /echo uncolored with /ansi off, got %q", m.output)
+	}
+}
+
+// TestEchoInAliasQueue tests that /echo inside a multi-command alias appends
+// to the output and is not sent to the MUD
+func TestEchoInAliasQueue(t *testing.T) {
+	m, server := newConnectedTestModel(t)
+	m.aliasManager = aliases.NewManager()
+	m.aliasManager.Add("buff", "/echo buffing <who>;cast armor")
+
+	m.currentInput = "buff bob"
+	m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if len(m.pendingCommands) != 2 || m.pendingCommands[0] != "/echo buffing bob" {
+		t.Fatalf("Expected /echo to be queued first, got %v", m.pendingCommands)
+	}
+
+	m.Update(commandQueueTickMsg{})
+
+	if !strings.Contains(strings.Join(m.output, "\n"), "buffing bob") {
+		t.Errorf("Expected echoed text in output, got %v", m.output)
+	}
+
+	// The first thing the MUD receives is the cast, so /echo sent nothing
+	if sent := readSent(t, server); sent != "cast armor" {
+		t.Errorf("Expected 'cast armor' to be sent first, got %q", sent)
+	}
+}
@@ -0,0 +1,70 @@
+package tui
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/anicolao/dikuclient/internal/mapper"
+)
+
+// newExploreTestModel creates a model standing in a hall whose only way on
+// leads to a yard with an exit nobody has taken yet
+func newExploreTestModel() (*Model, *mapper.Room) {
+	worldMap := mapper.NewMap()
+
+	hall := mapper.NewRoom("Hall", "A hall.", []string{"north"})
+	corridor := mapper.NewRoom("Corridor", "A corridor.", []string{"north", "south"})
+	yard := mapper.NewRoom("Yard", "A yard.", []string{"south", "east"})
+	for _, r := range []*mapper.Room{hall, corridor, yard} {
+		worldMap.AddOrUpdateRoom(r)
+	}
+	hall.Exits["north"] = corridor.ID
+	corridor.Exits["south"] = hall.ID
+	corridor.Exits["north"] = yard.ID
+	yard.Exits["south"] = corridor.ID
+	worldMap.CurrentRoomID = hall.ID
+
+	return &Model{output: []string{}, worldMap: worldMap}, yard
+}
+
+func TestExploreWalksToNearestUnexploredExit(t *testing.T) {
+	m, yard := newExploreTestModel()
+
+	m.handleExploreCommand()
+	if !m.autoWalking || m.autoWalkTarget != yard.Title {
+		t.Fatalf("Expected to auto-walk to the yard, got walking=%v target=%q", m.autoWalking, m.autoWalkTarget)
+	}
+	if !reflect.DeepEqual(m.autoWalkPath, []string{"north", "north"}) {
+		t.Errorf("Expected path [north north], got %v", m.autoWalkPath)
+	}
+	if output := strings.Join(m.output, "\n"); !strings.Contains(output, "east from 'Yard'") {
+		t.Errorf("Expected the exit to explore named, got:\n%s", output)
+	}
+
+	// Once there the unexplored exit is right here, so nothing is walked
+	m.stopCommandQueue()
+	m.worldMap.CurrentRoomID = yard.ID
+	m.output = nil
+	m.handleExploreCommand()
+	if m.autoWalking || !strings.Contains(strings.Join(m.output, "\n"), "go east to explore") {
+		t.Errorf("Expected to be told to go east, got walking=%v output:\n%s", m.autoWalking, strings.Join(m.output, "\n"))
+	}
+}
+
+func TestExploreReportsFullyExplored(t *testing.T) {
+	m, yard := newExploreTestModel()
+	delete(yard.Exits, "east")
+
+	m.handleExploreCommand()
+	if m.autoWalking || !strings.Contains(strings.Join(m.output, "\n"), "Map fully explored from here") {
+		t.Errorf("Expected the map reported fully explored, got walking=%v output:\n%s", m.autoWalking, strings.Join(m.output, "\n"))
+	}
+
+	m.worldMap.CurrentRoomID = ""
+	m.output = nil
+	m.handleExploreCommand()
+	if !strings.Contains(strings.Join(m.output, "\n"), "Current room unknown") {
+		t.Errorf("Expected an error without a current room, got:\n%s", strings.Join(m.output, "\n"))
+	}
+}
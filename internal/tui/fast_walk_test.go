@@ -0,0 +1,74 @@
+package tui
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/anicolao/dikuclient/internal/mapper"
+)
+
+// TestFastWalkLongPathInBatches tests that a fast walk longer than the
+// connection's command buffer is sent in batches, one per tick, and that
+// every step arrives with the send throttle on
+func TestFastWalkLongPathInBatches(t *testing.T) {
+	m, server := newConnectedTestModel(t)
+	m.xpTracking = make(map[string]*XPStat)
+	m.conn.SetSendInterval(time.Millisecond)
+
+	directions := []string{"north", "east", "south", "west"}
+	path := make([]string, 250)
+	for i := range path {
+		path[i] = directions[i%len(directions)]
+	}
+	target := &mapper.Room{ID: "far", Title: "Far Away"}
+
+	if cmd := m.fastWalk(target, path); cmd == nil {
+		t.Fatal("Expected the rest of the path to be scheduled")
+	}
+	if len(m.fastWalkQueue) != len(path)-fastWalkBatch {
+		t.Errorf("Expected one batch sent at once, %d steps left", len(m.fastWalkQueue))
+	}
+	if m.worldMap.CurrentRoomID != "far" {
+		t.Errorf("Expected the map to assume arrival, got %q", m.worldMap.CurrentRoomID)
+	}
+
+	batches := 1
+	for len(m.fastWalkQueue) > 0 {
+		left := len(m.fastWalkQueue)
+		m.Update(fastWalkTickMsg{})
+		if sent := left - len(m.fastWalkQueue); sent > fastWalkBatch {
+			t.Fatalf("Expected at most %d steps per tick, sent %d", fastWalkBatch, sent)
+		}
+		batches++
+	}
+	if batches != 5 {
+		t.Errorf("Expected 5 batches, got %d", batches)
+	}
+
+	for i, expected := range path {
+		if sent := readSent(t, server); sent != expected {
+			t.Fatalf("Step %d: expected %q, got %q", i+1, expected, sent)
+		}
+	}
+}
+
+// TestStopCancelsFastWalk tests that /stop drops the fast-walk steps not
+// yet sent
+func TestStopCancelsFastWalk(t *testing.T) {
+	m, _ := newConnectedTestModel(t)
+	m.xpTracking = make(map[string]*XPStat)
+
+	path := make([]string, 120)
+	for i := range path {
+		path[i] = fmt.Sprintf("n%d", i)
+	}
+	m.fastWalk(&mapper.Room{ID: "far", Title: "Far Away"}, path)
+	m.stopCommandQueue()
+	if len(m.fastWalkQueue) != 0 {
+		t.Errorf("Expected the fast walk to be cancelled, %d steps left", len(m.fastWalkQueue))
+	}
+	if _, cmd := m.Update(fastWalkTickMsg{}); cmd != nil {
+		t.Error("Expected nothing more to be scheduled after /stop")
+	}
+}
@@ -0,0 +1,48 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestFocusDimsOtherLines verifies lines not matching the focus are dimmed
+// in the view while the output keeps them all
+func TestFocusDimsOtherLines(t *testing.T) {
+	m := &Model{output: []string{"You hit the orc.", "Gandalf tells you 'hi'", "The orc misses you.", "> "}}
+	m.handleClientCommand(`/focus "orc"`)
+	m.updateViewport()
+
+	content := m.lastViewportContent
+	if !strings.HasPrefix(content, "You hit the orc.\n") {
+		t.Errorf("Expected matching lines shown as they are, got:\n%q", content)
+	}
+	if !strings.Contains(content, "\x1b[2mGandalf tells you 'hi'\x1b[0m") {
+		t.Errorf("Expected the other line dimmed, got:\n%q", content)
+	}
+	if len(m.output) != 5 || m.output[1] != "Gandalf tells you 'hi'" {
+		t.Errorf("Expected the output to keep every line, got %q", m.output)
+	}
+
+	m.handleClientCommand("/focus off")
+	m.updateViewport()
+	if strings.Contains(m.lastViewportContent, "\x1b[2m") {
+		t.Errorf("Expected nothing dimmed after /focus off, got:\n%q", m.lastViewportContent)
+	}
+}
+
+// TestFocusHide verifies /focus hide leaves other lines out of the view
+func TestFocusHide(t *testing.T) {
+	m := &Model{output: []string{"You hit the orc.", "Gandalf tells you 'hi'", "> "}}
+	m.handleClientCommand(`/focus "hit" hide`)
+	m.updateViewport()
+
+	if strings.Contains(m.lastViewportContent, "Gandalf") {
+		t.Errorf("Expected the other line hidden, got:\n%q", m.lastViewportContent)
+	}
+	if !strings.Contains(m.lastViewportContent, "You hit the orc.") {
+		t.Errorf("Expected the matching line shown, got:\n%q", m.lastViewportContent)
+	}
+	if !strings.Contains(m.lastViewportContent, "Focusing on") {
+		t.Errorf("Expected the last line kept, got:\n%q", m.lastViewportContent)
+	}
+}
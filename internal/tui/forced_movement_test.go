@@ -0,0 +1,113 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/anicolao/dikuclient/internal/mapper"
+)
+
+// TestFleeMarksLocationUncertain tests that fleeing leaves the position unknown
+func TestFleeMarksLocationUncertain(t *testing.T) {
+	worldMap := mapper.NewMap()
+	start := mapper.NewRoom("Dark Alley", "A narrow alley.", []string{"north", "south"})
+	worldMap.AddOrUpdateRoom(start)
+
+	m := &Model{
+		output:          []string{},
+		recentOutput:    []string{},
+		worldMap:        worldMap,
+		pendingMovement: "north",
+	}
+
+	m.detectForcedMovement("You flee head over heels!")
+
+	if !m.locationUncertain {
+		t.Error("Expected location to be uncertain after fleeing")
+	}
+	if m.pendingMovement != "" {
+		t.Error("Expected pending movement to be cleared after fleeing")
+	}
+
+	sidebar := m.renderSidebar(60, 40)
+	if !strings.Contains(sidebar, "(location uncertain)") {
+		t.Error("Expected map panel to show '(location uncertain)'")
+	}
+}
+
+// TestTeleportDoesNotLinkRooms tests that the room after a teleport is not linked to the previous room
+func TestTeleportDoesNotLinkRooms(t *testing.T) {
+	worldMap := mapper.NewMap()
+	start := mapper.NewRoom("Dark Alley", "A narrow alley.", []string{"north", "south"})
+	worldMap.AddOrUpdateRoom(start)
+
+	m := &Model{
+		output:       []string{},
+		recentOutput: []string{},
+		worldMap:     worldMap,
+	}
+
+	m.detectForcedMovement("You are magically transported to another place!")
+	if !m.locationUncertain {
+		t.Fatal("Expected location to be uncertain after teleport")
+	}
+
+	// The user walks before a room has been detected; this must not link
+	// the alley to the destination
+	m.pendingMovement = "north"
+	m.recentOutput = []string{
+		"119H 110V 3674X 0.00% 77C T:56 Exits:EW>",
+		"Temple Square",
+		"    You are standing in a large temple square. The ancient stones",
+		"speak of a glorious past.",
+		"Exits: north, south, east",
+	}
+	m.detectAndUpdateRoom()
+
+	current := worldMap.GetCurrentRoom()
+	if current == nil || current.Title != "Temple Square" {
+		t.Fatalf("Expected current room to be Temple Square, got %v", current)
+	}
+	if dest := start.Exits["north"]; dest != "" {
+		t.Errorf("Expected no link from Dark Alley after teleport, got north -> %s", dest)
+	}
+	if m.locationUncertain {
+		t.Error("Expected location to be certain again after a clean room detection")
+	}
+}
+
+// TestForcedMovementStopsAutoWalk tests that fleeing cancels an in-progress auto-walk
+func TestForcedMovementStopsAutoWalk(t *testing.T) {
+	m := &Model{
+		output:          []string{},
+		worldMap:        mapper.NewMap(),
+		autoWalking:     true,
+		autoWalkPath:    []string{"north", "east"},
+		pendingCommands: []string{"east"},
+	}
+
+	m.detectForcedMovement("You flee head over heels!")
+
+	if m.autoWalking || len(m.pendingCommands) > 0 {
+		t.Error("Expected auto-walk to be stopped after forced movement")
+	}
+}
+
+// TestNormalOutputIsNotForcedMovement tests that regular lines don't mark the location uncertain
+func TestNormalOutputIsNotForcedMovement(t *testing.T) {
+	m := &Model{
+		output:   []string{},
+		worldMap: mapper.NewMap(),
+	}
+
+	for _, line := range []string{
+		"The goblin tries to flee but fails.",
+		"You say 'I will flee if needed'",
+		"Temple Square",
+	} {
+		m.detectForcedMovement(line)
+		if m.locationUncertain {
+			t.Errorf("Did not expect %q to be treated as forced movement", line)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/anicolao/dikuclient/internal/mapper"
+)
+
+func newFuzzySearchTestModel() *Model {
+	worldMap := mapper.NewMap()
+	square := mapper.NewRoom("Temple Square", "A large temple square.", []string{"north"})
+	tower := mapper.NewRoom("Tempest Tower", "A windy tower.", []string{"east"})
+	market := mapper.NewRoom("Market Street", "A busy market.", []string{"south", "west"})
+	worldMap.AddOrUpdateRoom(tower)
+	worldMap.AddOrUpdateRoom(square)
+	worldMap.AddOrUpdateRoom(market)
+	worldMap.CurrentRoomID = market.ID
+
+	market.Exits["south"] = square.ID
+	square.Exits["north"] = market.ID
+	market.Exits["west"] = tower.ID
+	tower.Exits["east"] = market.ID
+
+	return &Model{
+		output:    []string{},
+		connected: true,
+		worldMap:  worldMap,
+	}
+}
+
+func TestGoFindsMisspelledRoom(t *testing.T) {
+	m := newFuzzySearchTestModel()
+
+	m.handleGoCommand([]string{"tempel", "squre"})
+
+	if !m.autoWalking || m.autoWalkTarget != "Temple Square" {
+		t.Fatalf("Expected to walk to Temple Square, got %q", m.output)
+	}
+	if !strings.Contains(strings.Join(m.output, "\n"), "No exact match for 'tempel squre'") {
+		t.Errorf("Expected a note that the match is approximate, got %q", m.output)
+	}
+}
+
+func TestPointListsFuzzyMatchesBestFirst(t *testing.T) {
+	m := newFuzzySearchTestModel()
+
+	// "tempel" is closer to Temple than to Tempest, although Tempest Tower
+	// was mapped first
+	m.handlePointCommand([]string{"tempel"})
+
+	if len(m.lastRoomSearch) != 2 || m.lastRoomSearch[0].Title != "Temple Square" {
+		t.Fatalf("Expected Temple Square ranked first, got %q", m.output)
+	}
+	output := strings.Join(m.output, "\n")
+	if !strings.Contains(output, "1. Temple Square") || !strings.Contains(output, "2. Tempest Tower") {
+		t.Errorf("Expected a ranked disambiguation list, got %q", m.output)
+	}
+
+	// Selecting from the list uses the ranked order
+	m.output = []string{}
+	m.handlePointCommand([]string{"1"})
+	if !strings.Contains(strings.Join(m.output, "\n"), "To reach 'Temple Square', go: south") {
+		t.Errorf("Expected directions to Temple Square, got %q", m.output)
+	}
+}
+
+func TestExactMatchSkipsFuzzySearch(t *testing.T) {
+	m := newFuzzySearchTestModel()
+
+	m.handlePointCommand([]string{"temp"})
+
+	// Substring matches still win, in room number order
+	if len(m.lastRoomSearch) != 2 || m.lastRoomSearch[0].Title != "Tempest Tower" {
+		t.Fatalf("Expected exact matches in room number order, got %q", m.output)
+	}
+	if strings.Contains(strings.Join(m.output, "\n"), "No exact match") {
+		t.Errorf("Expected no fuzzy note for exact matches, got %q", m.output)
+	}
+}
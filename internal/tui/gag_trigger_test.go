@@ -0,0 +1,156 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/anicolao/dikuclient/internal/ansi"
+	"github.com/anicolao/dikuclient/internal/mapper"
+	"github.com/anicolao/dikuclient/internal/triggers"
+)
+
+// TestGagTrigger tests that a line gagged with /trigger "..." /gag never
+// reaches the output, while room detection and other triggers still see it
+func TestGagTrigger(t *testing.T) {
+	m, server := newConnectedTestModel(t)
+	t.Setenv("DIKUCLIENT_CONFIG_DIR", t.TempDir())
+	m.xpTracking = make(map[string]*XPStat)
+	m.worldMap = mapper.NewMap()
+	m.triggerManager = triggers.NewManager()
+
+	m.handleTriggerCommand(`trigger "The sun rises" /gag`)
+	m.handleTriggerCommand(`trigger -glob "Temple Square" "/gag"`)
+	m.handleTriggerCommand(`trigger "sun rises in the <dir>." "say good morning <dir>"`)
+	if len(m.triggerManager.Triggers) != 3 || m.triggerManager.Triggers[0].Type() != triggers.ActionGag ||
+		m.triggerManager.Triggers[1].Type() != triggers.ActionGag || m.triggerManager.Triggers[0].Action != "" {
+		t.Fatalf("Expected two gag triggers, got %+v", m.triggerManager.Triggers)
+	}
+
+	m.handleTriggersListCommand()
+	if !strings.Contains(m.output[len(m.output)-3], "[gag]") {
+		t.Errorf("Expected the gag to be marked in the list, got %q", m.output[len(m.output)-3])
+	}
+
+	m.output = []string{}
+	m.pendingMovement = "north"
+	m.Update(mudMsg("The sun rises in the east.\n" + testPrompt + "\nTemple Square\n    A stretch of road called Temple Square.\nExits: north, south\n"))
+
+	for _, line := range m.output {
+		if strings.Contains(line, "sun rises") || line == "Temple Square" {
+			t.Errorf("Expected gagged lines to be hidden, got %q", m.output)
+		}
+	}
+	if !strings.Contains(strings.Join(m.output, "\n"), "A stretch of road called Temple Square.") {
+		t.Errorf("Expected other lines to be shown, got %q", m.output)
+	}
+	if room := m.worldMap.GetCurrentRoom(); room == nil || room.Title != "Temple Square" {
+		t.Errorf("Expected the gagged title to still be mapped, got %+v", room)
+	}
+
+	m.Update(commandQueueTickMsg{})
+	if sent := readSent(t, server); sent != "say good morning east" {
+		t.Errorf("Expected the command trigger to fire on the gagged line, got %q", sent)
+	}
+}
+
+// TestHighlightTrigger tests that /highlight shows the matching line in the
+// highlight color without sending anything
+func TestHighlightTrigger(t *testing.T) {
+	m := newHidePromptTestModel(t)
+	t.Setenv("DIKUCLIENT_CONFIG_DIR", t.TempDir())
+	m.triggerManager = triggers.NewManager()
+
+	m.handleTriggerCommand(`trigger -glob "* enters the game." /highlight`)
+	m.output = []string{}
+	m.Update(mudMsg("Bob enters the game.\nA goblin arrives.\n"))
+
+	expected := []string{m.colors().Highlight.Render("Bob enters the game."), "A goblin arrives."}
+	if strings.Join(m.output, "|") != strings.Join(expected, "|") {
+		t.Errorf("Expected output %q, got %q", expected, m.output)
+	}
+
+	// The MUD's own colors come back after the highlighted line
+	m.output = []string{}
+	m.Update(mudMsg("\x1b[32mAnn enters the game.\x1b[0m\n"))
+	if expected := "\x1b[32m\x1b[96mAnn enters the game.\x1b[0m\x1b[32m\x1b[0m"; m.output[0] != expected {
+		t.Errorf("Expected %q, got %q", expected, m.output[0])
+	}
+
+	m.handleTriggerTestCommand(`"Ann enters the game."`)
+	if last := m.output[len(m.output)-1]; !strings.Contains(last, "Would highlight the line") {
+		t.Errorf("Expected /trigger test to describe the highlight, got %q", last)
+	}
+}
+
+// TestHighlightTriggerWithLinks tests that a highlighted line keeps its
+// highlight when /links also turns its web address into a link
+func TestHighlightTriggerWithLinks(t *testing.T) {
+	m := newHidePromptTestModel(t)
+	t.Setenv("DIKUCLIENT_CONFIG_DIR", t.TempDir())
+	m.triggerManager = triggers.NewManager()
+	m.handleLinksCommand([]string{"on"})
+
+	m.handleTriggerCommand(`trigger -glob "* visit *" /highlight`)
+	m.output = []string{}
+	m.Update(mudMsg("Please visit http://example.com now.\n"))
+
+	line := m.output[0]
+	if !strings.Contains(line, ansi.Hyperlink("http://example.com", "http://example.com")) {
+		t.Errorf("Expected the address to be a link, got %q", line)
+	}
+	if !strings.HasPrefix(line, "\x1b[") || ansi.Strip(line) != "Please visit http://example.com now." {
+		t.Errorf("Expected the line to stay highlighted, got %q", line)
+	}
+}
+
+// TestHighlightColorTriggerWithLinks tests that a colored match keeps its
+// color when /links also turns a web address on the line into a link
+func TestHighlightColorTriggerWithLinks(t *testing.T) {
+	m := newHidePromptTestModel(t)
+	t.Setenv("DIKUCLIENT_CONFIG_DIR", t.TempDir())
+	m.triggerManager = triggers.NewManager()
+	m.handleLinksCommand([]string{"on"})
+
+	m.handleTriggerCommand(`trigger "<name> tells you" /highlight yellow`)
+	m.output = []string{}
+	m.Update(mudMsg("Bob tells you 'see http://example.com'\n"))
+
+	link := ansi.Hyperlink("http://example.com", "http://example.com")
+	if expected := "\x1b[33mBob tells you\x1b[0m 'see " + link + "'"; m.output[0] != expected {
+		t.Errorf("Expected %q, got %q", expected, m.output[0])
+	}
+}
+
+// TestHighlightColorTrigger tests that /highlight <color> wraps just the
+// matched text in that color and restores the MUD's color after it
+func TestHighlightColorTrigger(t *testing.T) {
+	m := newHidePromptTestModel(t)
+	t.Setenv("DIKUCLIENT_CONFIG_DIR", t.TempDir())
+	m.triggerManager = triggers.NewManager()
+
+	m.handleTriggerCommand(`trigger "<name> tells you" /highlight orange`)
+	if len(m.triggerManager.Triggers) != 0 || !strings.Contains(m.output[len(m.output)-1], "bright-white") {
+		t.Fatalf("Expected an unknown color to be rejected with the color names, got %q", m.output)
+	}
+
+	m.handleTriggerCommand(`trigger "<name> tells you" /highlight Yellow`)
+	if len(m.triggerManager.Triggers) != 1 || m.triggerManager.Triggers[0].Color != "yellow" {
+		t.Fatalf("Expected a yellow highlight trigger, got %+v", m.triggerManager.Triggers)
+	}
+	if last := m.output[len(m.output)-1]; !strings.Contains(last, "[highlight yellow]") {
+		t.Errorf("Expected the color in the confirmation, got %q", last)
+	}
+
+	m.output = []string{}
+	m.Update(mudMsg("\x1b[32mBob tells you 'hi'\x1b[0m\nA goblin arrives.\n"))
+
+	expected := []string{"\x1b[32m\x1b[33mBob tells you\x1b[0m\x1b[32m 'hi'\x1b[0m", "A goblin arrives."}
+	if strings.Join(m.output, "|") != strings.Join(expected, "|") {
+		t.Errorf("Expected output %q, got %q", expected, m.output)
+	}
+
+	m.handleTriggerTestCommand(`"Ann tells you 'bye'"`)
+	if last := m.output[len(m.output)-1]; !strings.Contains(last, "Would color the match yellow") {
+		t.Errorf("Expected /trigger test to describe the color, got %q", last)
+	}
+}
@@ -0,0 +1,100 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/anicolao/dikuclient/internal/triggers"
+)
+
+// TestGlobTriggerCommand tests adding a glob trigger with /trigger -glob and
+// that it fires on a colored line with its wildcard captures
+func TestGlobTriggerCommand(t *testing.T) {
+	m, server := newConnectedTestModel(t)
+	t.Setenv("DIKUCLIENT_CONFIG_DIR", t.TempDir())
+	m.xpTracking = make(map[string]*XPStat)
+	m.triggerManager = triggers.NewManager()
+
+	m.handleTriggerCommand(`trigger -glob "You receive * gold*" "split <1>"`)
+	if len(m.triggerManager.Triggers) != 1 || m.triggerManager.Triggers[0].Mode != triggers.ModeGlob {
+		t.Fatalf("Expected one glob trigger, got %+v", m.triggerManager.Triggers)
+	}
+
+	m.handleTriggersListCommand()
+	if !strings.Contains(m.output[len(m.output)-1], "[glob]") {
+		t.Errorf("Expected glob trigger to be marked in the list, got %q", m.output[len(m.output)-1])
+	}
+
+	m.Update(mudMsg("\x1b[33mYou receive 25 gold coins.\x1b[0m\n"))
+	m.Update(commandQueueTickMsg{})
+
+	if sent := readSent(t, server); sent != "split 25" {
+		t.Errorf("Expected 'split 25' to be sent, got %q", sent)
+	}
+}
+
+// TestRawTriggerCommand tests that /trigger -raw matches color codes while
+// other triggers match the plain text of the same line
+func TestRawTriggerCommand(t *testing.T) {
+	m, server := newConnectedTestModel(t)
+	t.Setenv("DIKUCLIENT_CONFIG_DIR", t.TempDir())
+	m.xpTracking = make(map[string]*XPStat)
+	m.triggerManager = triggers.NewManager()
+
+	m.handleTriggerCommand(`trigger -raw "\e[1;31m<mob> dies" "get all corpse"`)
+	m.handleTriggerCommand(`trigger "The <mob> dies" "cheer"`)
+	if len(m.triggerManager.Triggers) != 2 || !m.triggerManager.Triggers[0].Raw || m.triggerManager.Triggers[1].Raw {
+		t.Fatalf("Expected one raw and one plain trigger, got %+v", m.triggerManager.Triggers)
+	}
+
+	m.handleTriggersListCommand()
+	if !strings.Contains(strings.Join(m.output, "\n"), "[raw]") {
+		t.Errorf("Expected the raw trigger to be marked in the list, got %q", m.output)
+	}
+
+	m.Update(mudMsg("The \x1b[1;31morc\x1b[0m dies\n"))
+	m.Update(commandQueueTickMsg{})
+	m.Update(commandQueueTickMsg{})
+
+	sent := []string{readSent(t, server), readSent(t, server)}
+	if sent[0] != "get all corpse" || sent[1] != "cheer" {
+		t.Errorf("Expected both triggers to fire, got %q", sent)
+	}
+}
+
+// TestRegexTriggerCommand tests adding a regex trigger with /trigger -re,
+// that a bad expression is rejected and that the trigger fires with its
+// groups substituted
+func TestRegexTriggerCommand(t *testing.T) {
+	m, server := newConnectedTestModel(t)
+	t.Setenv("DIKUCLIENT_CONFIG_DIR", t.TempDir())
+	m.xpTracking = make(map[string]*XPStat)
+	m.triggerManager = triggers.NewManager()
+
+	m.handleTriggerCommand(`trigger -re "(\d+ gold" "get <1> coins"`)
+	if len(m.triggerManager.Triggers) != 0 || !strings.Contains(m.output[len(m.output)-1], "invalid regular expression") {
+		t.Fatalf("Expected the bad regex to be rejected, got %q", m.output[len(m.output)-1])
+	}
+
+	m.handleTriggerCommand(`trigger -glob -re "* gold" "x"`)
+	if len(m.triggerManager.Triggers) != 0 {
+		t.Fatalf("Expected -glob with -re to be rejected, got %+v", m.triggerManager.Triggers)
+	}
+
+	m.handleTriggerCommand(`trigger -re "(\d+) gold coins" "get <1> coins"`)
+	if len(m.triggerManager.Triggers) != 1 || !m.triggerManager.Triggers[0].IsRegex() {
+		t.Fatalf("Expected one regex trigger, got %+v", m.triggerManager.Triggers)
+	}
+
+	m.handleTriggersListCommand()
+	if !strings.Contains(m.output[len(m.output)-1], "[regex]") {
+		t.Errorf("Expected regex trigger to be marked in the list, got %q", m.output[len(m.output)-1])
+	}
+
+	m.Update(mudMsg("There are \x1b[33m17 gold coins\x1b[0m here.\n"))
+	m.Update(commandQueueTickMsg{})
+
+	if sent := readSent(t, server); sent != "get 17 coins" {
+		t.Errorf("Expected 'get 17 coins' to be sent, got %q", sent)
+	}
+}
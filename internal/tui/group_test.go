@@ -0,0 +1,86 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/anicolao/dikuclient/internal/aliases"
+	"github.com/anicolao/dikuclient/internal/triggers"
+)
+
+func newGroupTestModel(t *testing.T) *Model {
+	m, _ := newConnectedTestModel(t)
+	t.Setenv("DIKUCLIENT_CONFIG_DIR", t.TempDir())
+	m.xpTracking = make(map[string]*XPStat)
+	m.aliasManager = aliases.NewManager()
+	m.triggerManager = triggers.NewManager()
+
+	m.handleAliasCommand(`alias -group mage "k" "cast 'magic missile' <target>"`)
+	m.handleAliasCommand(`alias -group mage "hl" "cast 'heal' <target>"`)
+	m.handleAliasCommand(`alias "gat" "give all <target>"`)
+	m.handleTriggerCommand(`trigger -group mage "You feel less protected" "cast 'armor'"`)
+	m.handleTriggerCommand(`trigger "You are hungry" "eat bread"`)
+	m.output = []string{}
+	return m
+}
+
+func TestGroupDisableAndEnable(t *testing.T) {
+	m := newGroupTestModel(t)
+	if m.aliasManager.Aliases[0].Group != "mage" || m.triggerManager.Triggers[0].Group != "mage" {
+		t.Fatalf("Expected -group to set the group, got %+v %+v", m.aliasManager.Aliases[0], m.triggerManager.Triggers[0])
+	}
+
+	m.handleGroupCommand([]string{"disable", "mage"})
+	if last := m.output[len(m.output)-1]; !strings.Contains(last, "Disabled group 'mage' (2 aliases, 1 triggers)") {
+		t.Errorf("Expected a disable confirmation, got %q", last)
+	}
+	if _, ok := m.aliasManager.Expand("k orc"); ok {
+		t.Error("Expected the mage aliases to be disabled")
+	}
+	if _, ok := m.aliasManager.Expand("gat bob"); !ok {
+		t.Error("Expected aliases outside the group to keep working")
+	}
+	if results := m.triggerManager.Test("You feel less protected."); len(results) != 0 {
+		t.Errorf("Expected the mage trigger to be disabled, got %+v", results)
+	}
+
+	m.output = []string{}
+	m.handleAliasesListCommand()
+	if !strings.Contains(strings.Join(m.output, "\n"), "(disabled)") {
+		t.Errorf("Expected disabled aliases to be marked in the list, got %q", m.output)
+	}
+
+	m.handleGroupCommand([]string{"enable", "mage"})
+	if got, _ := m.aliasManager.Expand("k orc"); got != "cast 'magic missile' orc" {
+		t.Errorf("Expected the mage alias back, got %q", got)
+	}
+	if results := m.triggerManager.Test("You feel less protected."); len(results) != 1 {
+		t.Errorf("Expected the mage trigger back, got %+v", results)
+	}
+}
+
+func TestGroupList(t *testing.T) {
+	m := newGroupTestModel(t)
+	m.handleGroupCommand([]string{"disable", "mage"})
+	m.output = []string{}
+
+	m.handleGroupCommand(nil)
+	output := strings.Join(m.output, "\n")
+	if !strings.Contains(output, "mage\x1b[0m - 2 aliases, 1 triggers (disabled)") {
+		t.Errorf("Expected the mage group in the list, got %q", m.output)
+	}
+}
+
+func TestGroupCommandErrors(t *testing.T) {
+	m := newGroupTestModel(t)
+
+	m.handleGroupCommand([]string{"disable", "thief"})
+	if last := m.output[len(m.output)-1]; !strings.Contains(last, "No aliases or triggers in group 'thief'") {
+		t.Errorf("Expected an unknown group error, got %q", last)
+	}
+
+	m.handleGroupCommand([]string{"toggle", "mage"})
+	if last := m.output[len(m.output)-1]; !strings.Contains(last, "Usage") {
+		t.Errorf("Expected usage, got %q", last)
+	}
+}
@@ -3,6 +3,8 @@ package tui
 import (
 	"strings"
 	"testing"
+
+	"github.com/anicolao/dikuclient/internal/ansi"
 )
 
 // TestHelpCommand tests the general help command
@@ -17,7 +19,7 @@ func TestHelpCommand(t *testing.T) {
 	// Check that the output contains expected sections
 	found := false
 	for _, line := range model.output {
-		cleanLine := stripANSI(line)
+		cleanLine := ansi.Strip(line)
 		if strings.Contains(cleanLine, "Client Commands") {
 			found = true
 			break
@@ -31,7 +33,7 @@ func TestHelpCommand(t *testing.T) {
 	// Check for the new help text
 	foundHelpText := false
 	for _, line := range model.output {
-		cleanLine := stripANSI(line)
+		cleanLine := ansi.Strip(line)
 		if strings.Contains(cleanLine, "Use /help <command> for detailed help") {
 			foundHelpText = true
 			break
@@ -75,7 +77,7 @@ func TestHelpCommandWithArgument(t *testing.T) {
 			// Check that the output contains the expected text
 			found := false
 			for _, line := range model.output {
-				cleanLine := stripANSI(line)
+				cleanLine := ansi.Strip(line)
 				if strings.Contains(cleanLine, tt.expect) {
 					found = true
 					break
@@ -102,7 +104,7 @@ func TestHelpCommandWithUnknownArgument(t *testing.T) {
 	// Check that the output contains error message
 	found := false
 	for _, line := range model.output {
-		cleanLine := stripANSI(line)
+		cleanLine := ansi.Strip(line)
 		if strings.Contains(cleanLine, "Unknown command: unknown") {
 			found = true
 			break
@@ -116,7 +118,7 @@ func TestHelpCommandWithUnknownArgument(t *testing.T) {
 	// Check that it shows available commands
 	foundAvailable := false
 	for _, line := range model.output {
-		cleanLine := stripANSI(line)
+		cleanLine := ansi.Strip(line)
 		if strings.Contains(cleanLine, "Available commands for detailed help") {
 			foundAvailable = true
 			break
@@ -152,7 +154,7 @@ func TestHelpCommandPluralForms(t *testing.T) {
 			// Check that the output contains the expected text
 			found := false
 			for _, line := range model.output {
-				cleanLine := stripANSI(line)
+				cleanLine := ansi.Strip(line)
 				if strings.Contains(cleanLine, tt.expect) {
 					found = true
 					break
@@ -0,0 +1,101 @@
+package tui
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/anicolao/dikuclient/internal/aliases"
+	"github.com/anicolao/dikuclient/internal/mapper"
+	"github.com/anicolao/dikuclient/internal/settings"
+	"github.com/anicolao/dikuclient/internal/ticktimer"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const testPrompt = "101H 132V 1000X 50C T:24 Exits:NS>"
+
+// newHidePromptTestModel creates a model with prompt hiding turned on
+func newHidePromptTestModel(t *testing.T) *Model {
+	settingsManager, err := settings.LoadFromPath(filepath.Join(t.TempDir(), "settings.json"))
+	if err != nil {
+		t.Fatalf("Failed to load settings: %v", err)
+	}
+
+	m := &Model{
+		output:           []string{},
+		worldMap:         mapper.NewMap(),
+		xpTracking:       make(map[string]*XPStat),
+		tickTimerManager: ticktimer.NewManager(0),
+		settingsManager:  settingsManager,
+	}
+	m.handleHidePromptCommand([]string{"on"})
+	m.output = []string{}
+	return m
+}
+
+func TestHiddenPromptUpdatesStateButNotOutput(t *testing.T) {
+	m := newHidePromptTestModel(t)
+
+	m.Update(mudMsg("A goblin arrives.\n" + testPrompt + " "))
+
+	for _, line := range m.output {
+		if strings.Contains(line, "101H") {
+			t.Errorf("Prompt should not appear in output when hidden, got %q", line)
+		}
+	}
+	if len(m.output) != 2 || m.output[0] != "A goblin arrives." || m.output[1] != "" {
+		t.Errorf("Expected output line followed by an empty input line, got %q", m.output)
+	}
+	if m.currentPrompt != testPrompt {
+		t.Errorf("Expected current prompt %q, got %q", testPrompt, m.currentPrompt)
+	}
+	if m.tickTimerManager.TickInterval == 0 {
+		t.Error("Expected hidden prompt to still update the tick timer")
+	}
+
+	m.width = 120
+	if status := m.renderStatusBar(); !strings.Contains(status, testPrompt) {
+		t.Errorf("Expected prompt in status bar, got %q", status)
+	}
+
+	// The empty input line doesn't pile up between messages
+	m.Update(mudMsg("The goblin leaves north.\n" + testPrompt + " "))
+	expected := []string{"A goblin arrives.", "The goblin leaves north.", ""}
+	if strings.Join(m.output, "|") != strings.Join(expected, "|") {
+		t.Errorf("Expected output %q, got %q", expected, m.output)
+	}
+}
+
+func TestHiddenPromptKeepsTypedCommand(t *testing.T) {
+	m, _ := newConnectedTestModel(t)
+	hidden := newHidePromptTestModel(t)
+	m.settingsManager = hidden.settingsManager
+	m.xpTracking = make(map[string]*XPStat)
+	m.aliasManager = aliases.NewManager()
+	m.output = []string{}
+
+	m.Update(mudMsg("You are hungry.\n" + testPrompt))
+	m.currentInput = "eat bread"
+	m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m.Update(mudMsg("You eat the bread.\n" + testPrompt))
+
+	output := strings.Join(m.output, "|")
+	if !strings.Contains(output, "You are hungry.|\x1b[93meat bread\x1b[0m|You eat the bread.") {
+		t.Errorf("Expected typed command on its own line between messages, got %q", m.output)
+	}
+}
+
+func TestPromptShownWhenNotHidden(t *testing.T) {
+	m := newHidePromptTestModel(t)
+	m.handleHidePromptCommand([]string{"off"})
+	m.output = []string{}
+
+	m.Update(mudMsg("A goblin arrives.\n" + testPrompt))
+
+	if len(m.output) != 2 || m.output[1] != testPrompt {
+		t.Errorf("Expected prompt as last output line, got %q", m.output)
+	}
+	if m.currentPrompt != "" {
+		t.Errorf("Expected no status bar prompt when not hidden, got %q", m.currentPrompt)
+	}
+}
@@ -1,8 +1,12 @@
 package tui
 
 import (
+	"strings"
 	"testing"
 	"time"
+
+	"github.com/anicolao/dikuclient/internal/mapper"
+	"github.com/charmbracelet/bubbles/viewport"
 )
 
 func TestDetectAndUpdateInventory(t *testing.T) {
@@ -94,3 +98,81 @@ func TestDetectAndUpdateInventory(t *testing.T) {
 		})
 	}
 }
+
+func TestEquipmentPanel(t *testing.T) {
+	m := Model{
+		recentOutput: []string{
+			"86H 109V 7563X 0.00% 79C T:3 Exits:D> eq",
+			"You are using:",
+			"<used as light>      a torch",
+			"<worn on body>       a bronze breastplate",
+			"86H 109V 7563X 0.00% 79C T:2 Exits:D>",
+		},
+		width:        100,
+		height:       60,
+		sidebarWidth: 40,
+	}
+	m.detectAndUpdateInventory()
+
+	want := []mapper.EquipmentSlot{
+		{Slot: "used as light", Item: "a torch"},
+		{Slot: "worn on body", Item: "a bronze breastplate"},
+	}
+	if len(m.equipment) != len(want) || m.equipment[0] != want[0] || m.equipment[1] != want[1] {
+		t.Fatalf("Expected %v, got %v", want, m.equipment)
+	}
+	if m.inventory != nil {
+		t.Errorf("An equipment listing shouldn't fill the inventory, got %v", m.inventory)
+	}
+
+	m.inventoryViewport = viewport.New(m.sidebarWidth-4, 10)
+	m.tellsViewport = viewport.New(m.sidebarWidth-4, 10)
+	m.xpViewport = viewport.New(m.sidebarWidth-4, 10)
+	result := m.renderSidebar(m.sidebarWidth, m.height-10)
+	for _, text := range []string{"Equipment", "light: a torch", "body: a bronze breastplate"} {
+		if !strings.Contains(result, text) {
+			t.Errorf("Sidebar should contain %q", text)
+		}
+	}
+}
+
+func TestInventoryAutoRefresh(t *testing.T) {
+	m, _ := newConnectedTestModel(t)
+
+	m.handleInventoryCommand([]string{"auto", "5"})
+	if m.settingsManager.InventorySeconds != 0 {
+		t.Fatalf("Expected an interval under the minimum to be refused, got %d", m.settingsManager.InventorySeconds)
+	}
+
+	m.handleInventoryCommand([]string{"auto", "30"})
+	if m.settingsManager.InventorySeconds != 30 {
+		t.Fatalf("Expected a 30 second interval, got %d", m.settingsManager.InventorySeconds)
+	}
+
+	start := time.Now()
+	if m.checkInventoryRefresh(start.Add(10*time.Second)) != nil || len(m.pendingCommands) != 0 {
+		t.Fatalf("Expected no refresh before the interval, got %v", m.pendingCommands)
+	}
+	m.checkInventoryRefresh(start.Add(31 * time.Second))
+	if len(m.pendingCommands) != 1 || m.pendingCommands[0] != "inventory" {
+		t.Fatalf("Expected inventory queued after the interval, got %v", m.pendingCommands)
+	}
+	m.checkInventoryRefresh(start.Add(32 * time.Second))
+	if len(m.pendingCommands) != 1 {
+		t.Errorf("Expected one refresh per interval, got %v", m.pendingCommands)
+	}
+
+	// A listing the player asked for counts as a refresh
+	m.pendingCommands = nil
+	m.inventoryTime = start.Add(60 * time.Second)
+	m.checkInventoryRefresh(start.Add(70 * time.Second))
+	if len(m.pendingCommands) != 0 {
+		t.Errorf("Expected no refresh just after a listing, got %v", m.pendingCommands)
+	}
+
+	m.handleInventoryCommand([]string{"auto", "off"})
+	m.checkInventoryRefresh(start.Add(time.Hour))
+	if len(m.pendingCommands) != 0 {
+		t.Errorf("Expected no refresh once off, got %v", m.pendingCommands)
+	}
+}
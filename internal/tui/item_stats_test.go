@@ -0,0 +1,110 @@
+package tui
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/anicolao/dikuclient/internal/items"
+	"github.com/anicolao/dikuclient/internal/mapper"
+	"github.com/anicolao/dikuclient/internal/ticktimer"
+)
+
+// newItemTestModel creates a model with an item database at itemsPath
+func newItemTestModel(t *testing.T, itemsPath string) *Model {
+	itemManager, err := items.LoadFromPath(itemsPath)
+	if err != nil {
+		t.Fatalf("Failed to load items: %v", err)
+	}
+	return &Model{
+		output:           []string{},
+		worldMap:         mapper.NewMap(),
+		xpTracking:       make(map[string]*XPStat),
+		tickTimerManager: ticktimer.NewManager(0),
+		itemManager:      itemManager,
+	}
+}
+
+func TestIdentifyIsRememberedAndRecalled(t *testing.T) {
+	itemsPath := filepath.Join(t.TempDir(), "items.json")
+	m := newItemTestModel(t, itemsPath)
+
+	m.Update(mudMsg("You feel informed:\n" +
+		"Object 'a long sword', Item type: WEAPON\n" +
+		"Weight: 8, Value: 600, Rent: 60\n" +
+		"Damage Dice is '2D4' for an average per-round damage of 5.0.\n" +
+		testPrompt + " "))
+
+	if !strings.Contains(strings.Join(m.output, "\n"), "remembered stats for a long sword") {
+		t.Errorf("Expected a note that the item was remembered, got %q", m.output)
+	}
+
+	// The database was saved
+	loaded, err := items.LoadFromPath(itemsPath)
+	if err != nil {
+		t.Fatalf("Failed to load items: %v", err)
+	}
+	if item := loaded.Get("a long sword"); item == nil || len(item.Stats) != 3 {
+		t.Errorf("Expected the sword to be saved with 3 lines of stats, got %+v", item)
+	}
+
+	m.output = []string{}
+	m.handleStatCommand([]string{"sword"})
+	output := strings.Join(m.output, "\n")
+	if !strings.Contains(output, "=== a long sword ===") || !strings.Contains(output, "Damage Dice is '2D4'") {
+		t.Errorf("Expected the sword's stats, got %q", output)
+	}
+	if strings.Contains(output, testPrompt) || strings.Contains(output, "You feel informed") {
+		t.Errorf("Expected only the identify lines, got %q", output)
+	}
+
+	m.output = []string{}
+	m.handleStatCommand([]string{"axe"})
+	if !strings.Contains(m.output[0], "No remembered item matches 'axe'") {
+		t.Errorf("Expected no match for an unknown item, got %q", m.output)
+	}
+
+	m.handleStatCommand([]string{"forget", "a", "long", "sword"})
+	if m.itemManager.Get("a long sword") != nil {
+		t.Error("Expected /stat forget to remove the item")
+	}
+}
+
+func TestRememberStoresLastResponse(t *testing.T) {
+	m := newItemTestModel(t, filepath.Join(t.TempDir(), "items.json"))
+
+	m.Update(mudMsg(testPrompt + " \n"))
+	m.Update(mudMsg("A sturdy wooden shield, banded with iron.\nIt is in excellent condition.\n" + testPrompt + " "))
+
+	m.handleRememberCommand([]string{"a", "wooden", "shield"})
+	item := m.itemManager.Get("a wooden shield")
+	if item == nil {
+		t.Fatalf("Expected the shield to be remembered, got %q", m.output)
+	}
+	if len(item.Stats) != 2 || item.Stats[0] != "A sturdy wooden shield, banded with iron." {
+		t.Errorf("Expected the examine output as stats, got %q", item.Stats)
+	}
+
+	// Without a name, the response has to say what the item is
+	m.handleRememberCommand(nil)
+	if !strings.Contains(m.output[len(m.output)-1], "Usage: /remember") {
+		t.Errorf("Expected usage without a name, got %q", m.output[len(m.output)-1])
+	}
+}
+
+func TestInventoryMarksRememberedItems(t *testing.T) {
+	m := newItemTestModel(t, filepath.Join(t.TempDir(), "items.json"))
+	m.inventory = []string{"a long sword", "(glowing) a wooden shield", "a loaf of bread"}
+	m.itemManager.Remember("a wooden shield", []string{"Armor: 4"}, m.inventoryTime)
+
+	lines := m.inventoryLines()
+	if lines[0] != "a long sword" || lines[2] != "a loaf of bread" {
+		t.Errorf("Expected unknown items unmarked, got %q", lines)
+	}
+	if lines[1] != "(glowing) a wooden shield ✓" {
+		t.Errorf("Expected the remembered shield to be marked, got %q", lines[1])
+	}
+	if m.inventory[1] != "(glowing) a wooden shield" {
+		t.Error("Expected the inventory itself to be unchanged")
+	}
+}
@@ -0,0 +1,91 @@
+package tui
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/anicolao/dikuclient/internal/jsonlog"
+	"github.com/anicolao/dikuclient/internal/mapper"
+	"github.com/charmbracelet/bubbles/viewport"
+)
+
+func TestClassifyLine(t *testing.T) {
+	m := &Model{}
+
+	tests := []struct {
+		line string
+		kind string
+	}{
+		{"[ Exits: n e s ]", jsonlog.Room},
+		{"Obvious exits: north, south", jsonlog.Room},
+		{"101H 132V 54710X 49.60% 570C T:24 Exits:NS> ", jsonlog.Prompt},
+		{"101H 132V 1000X [Osric:Good] [the orc:Bad] Exits:NS> ", jsonlog.Prompt},
+		{"Gandalf tells you 'meet me at the temple'", jsonlog.Tell},
+		{"Your slash hits the orc very hard.", jsonlog.Combat},
+		{"The orc misses you with its claw.", jsonlog.Combat},
+		{"The orc is dead!", jsonlog.Combat},
+		{"You receive 150 experience.", jsonlog.Combat},
+		{"Temple Square", jsonlog.Other},
+		{"A large fountain stands in the middle of the square.", jsonlog.Other},
+		{"", jsonlog.Other},
+	}
+
+	for _, tt := range tests {
+		if got := m.classifyLine(tt.line); got != tt.kind {
+			t.Errorf("classifyLine(%q) = %q, want %q", tt.line, got, tt.kind)
+		}
+	}
+}
+
+func TestJSONLogRecordsOutput(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "session.jsonl")
+	m := &Model{
+		output:     []string{},
+		worldMap:   mapper.NewMap(),
+		xpTracking: make(map[string]*XPStat),
+		xpViewport: viewport.New(56, 10),
+	}
+
+	m.handleLogCommand([]string{"json", "start", path})
+	if m.jsonLog == nil {
+		t.Fatalf("Expected the JSON log to start, got %q", m.output)
+	}
+
+	m.Update(mudMsg("\x1b[32mGandalf tells you 'hi'\x1b[0m\nYour slash hits the orc.\n"))
+
+	m.handleLogCommand([]string{"json", "stop"})
+	if m.jsonLog != nil {
+		t.Error("Expected the JSON log to stop")
+	}
+	if len(m.tells) != 1 {
+		t.Errorf("Expected the tell to still reach the tells panel, got %v", m.tells)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read log: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected 2 logged lines, got %d:\n%s", len(lines), data)
+	}
+
+	var entry jsonlog.Entry
+	if err := json.Unmarshal([]byte(lines[0]), &entry); err != nil {
+		t.Fatalf("Failed to parse log line: %v", err)
+	}
+	if entry.Kind != jsonlog.Tell || entry.Stripped != "Gandalf tells you 'hi'" || !strings.Contains(entry.Raw, "\x1b[32m") || entry.TS.IsZero() {
+		t.Errorf("Unexpected tell entry %+v", entry)
+	}
+	if err := json.Unmarshal([]byte(lines[1]), &entry); err != nil || entry.Kind != jsonlog.Combat {
+		t.Errorf("Expected the second line to be combat, got %+v (%v)", entry, err)
+	}
+
+	m.handleLogCommand([]string{"json", "stop"})
+	if !strings.Contains(m.output[len(m.output)-1], "not running") {
+		t.Errorf("Expected a warning when stopping twice, got %q", m.output[len(m.output)-1])
+	}
+}
@@ -0,0 +1,67 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/anicolao/dikuclient/internal/triggers"
+)
+
+// TestLearnPreviewsThenSavesTrigger verifies /learn generalizes a recent
+// line into a trigger and only adds it on /learn save
+func TestLearnPreviewsThenSavesTrigger(t *testing.T) {
+	m, _ := newConnectedTestModel(t)
+	m.triggerManager = triggers.NewManager()
+	m.Update(mudMsg("Gandalf gives you 42 gold coins.\nThe wind howls.\n" + testPrompt))
+
+	m.output = nil
+	m.handleClientCommand("/learn")
+	output := strings.Join(m.output, "\n")
+	if !strings.Contains(output, " 1. The wind howls.") || !strings.Contains(output, " 2. Gandalf gives you 42 gold coins.") {
+		t.Fatalf("Expected the recent lines listed newest first without the prompt, got:\n%s", output)
+	}
+
+	m.output = nil
+	m.handleClientCommand("/learn 2 thank <name>;split <amount>")
+	output = strings.Join(m.output, "\n")
+	if !strings.Contains(output, "<name> gives you <amount> gold coins.") || !strings.Contains(output, "would send: thank Gandalf;split 42") {
+		t.Errorf("Expected the generalized pattern previewed with its action, got:\n%s", output)
+	}
+	if len(m.triggerManager.Triggers) != 0 {
+		t.Fatal("Expected no trigger added before /learn save")
+	}
+
+	m.handleClientCommand("/learn save")
+	if len(m.triggerManager.Triggers) != 1 {
+		t.Fatalf("Expected the trigger added, have %d", len(m.triggerManager.Triggers))
+	}
+	if trigger := m.triggerManager.Triggers[0]; trigger.Pattern != "<name> gives you <amount> gold coins." || trigger.Action != "thank <name>;split <amount>" {
+		t.Errorf("Unexpected trigger %q -> %q", trigger.Pattern, trigger.Action)
+	}
+	if m.learnPattern != "" {
+		t.Error("Expected the preview cleared once saved")
+	}
+}
+
+// TestLearnCancelAndBadLine verifies /learn cancel drops the preview and an
+// unknown line number is refused
+func TestLearnCancelAndBadLine(t *testing.T) {
+	m, _ := newConnectedTestModel(t)
+	m.triggerManager = triggers.NewManager()
+	m.Update(mudMsg("You are hungry.\n" + testPrompt))
+
+	m.handleClientCommand("/learn 5 eat bread")
+	if m.learnPattern != "" || !strings.Contains(strings.Join(m.output, "\n"), "No line 5") {
+		t.Errorf("Expected line 5 refused, got:\n%s", strings.Join(m.output, "\n"))
+	}
+
+	m.handleClientCommand("/learn 1 eat bread")
+	if m.learnPattern != "You are hungry." {
+		t.Fatalf("Expected a preview, got pattern %q", m.learnPattern)
+	}
+	m.handleClientCommand("/learn cancel")
+	m.handleClientCommand("/learn save")
+	if m.learnPattern != "" || len(m.triggerManager.Triggers) != 0 {
+		t.Errorf("Expected nothing saved after cancel, have %d triggers", len(m.triggerManager.Triggers))
+	}
+}
@@ -126,3 +126,55 @@ func TestLegendCommandNoRooms(t *testing.T) {
 		t.Error("Expected 'No rooms have been explored' message")
 	}
 }
+
+// TestLegendStaysUntilClearedOrMoved verifies the legend survives unrelated
+// commands, /go picks rooms by the numbers drawn on the map, and moving or
+// /legend clear takes it away
+func TestLegendStaysUntilClearedOrMoved(t *testing.T) {
+	m, _ := newConnectedTestModel(t)
+	center := mapper.NewRoom("Center", "The center room.", []string{"north", "east"})
+	north := mapper.NewRoom("North Room", "A room to the north.", []string{"south"})
+	east := mapper.NewRoom("East Room", "A room to the east.", []string{"west"})
+	m.worldMap.AddOrUpdateRoom(center)
+	m.worldMap.SetLastDirection("north")
+	m.worldMap.AddOrUpdateRoom(north)
+	m.worldMap.SetLastDirection("south")
+	m.worldMap.AddOrUpdateRoom(center)
+	m.worldMap.SetLastDirection("east")
+	m.worldMap.AddOrUpdateRoom(east)
+	m.worldMap.SetLastDirection("west")
+	m.worldMap.AddOrUpdateRoom(center)
+
+	m.handleClientCommand("/legend")
+	m.handleClientCommand("/help legend")
+	if len(m.mapLegend) != 3 {
+		t.Fatalf("Expected the legend kept after an unrelated command, got %v", m.mapLegend)
+	}
+
+	// The legend shows durable numbers, which /go uses
+	eastNumber := m.worldMap.GetRoomNumber(east.ID)
+	if room := m.legendRoom(eastNumber); room == nil || room.ID != east.ID {
+		t.Errorf("Expected legend number %d to be the east room, got %v", eastNumber, room)
+	}
+	m.handleClientCommand("/go 99")
+	if !strings.Contains(strings.Join(m.output, "\n"), "Room 99 is not in the map legend") {
+		t.Error("Expected a number missing from the legend to be refused")
+	}
+	if m.mapLegend == nil {
+		t.Fatal("Expected the legend kept after /go")
+	}
+
+	m.handleClientCommand("/legend clear")
+	if m.mapLegend != nil || m.mapLegendRooms != nil {
+		t.Error("Expected /legend clear to remove the legend")
+	}
+
+	m.handleClientCommand("/nearby")
+	if m.mapLegend == nil {
+		t.Fatal("Expected /nearby to number the rooms")
+	}
+	m.sendMovement("north")
+	if m.mapLegend != nil {
+		t.Error("Expected moving to remove the legend")
+	}
+}
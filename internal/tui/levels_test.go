@@ -0,0 +1,45 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/anicolao/dikuclient/internal/levels"
+)
+
+// TestLevelUpRecorded verifies a level-up message is saved to the level log
+// with the session's experience and listed by /levels
+func TestLevelUpRecorded(t *testing.T) {
+	t.Setenv("DIKUCLIENT_CONFIG_DIR", t.TempDir())
+	m, _ := newConnectedTestModel(t)
+
+	m.Update(mudMsg("You receive 250 experience.\n" + testPrompt))
+	if m.sessionXP != 250 {
+		t.Fatalf("Expected 250 XP counted this session, got %d", m.sessionXP)
+	}
+
+	m.Update(mudMsg("You raise a level! You are now level 25.\n" + testPrompt))
+	saved, err := levels.Load()
+	if err != nil {
+		t.Fatalf("Failed to load level log: %v", err)
+	}
+	if len(saved.Milestones) != 1 || saved.Milestones[0].Level != 25 || saved.Milestones[0].SessionXP != 250 {
+		t.Fatalf("Expected level 25 saved with 250 XP, got %+v", saved.Milestones)
+	}
+	if time.Since(saved.Milestones[0].Time) > time.Minute {
+		t.Errorf("Expected the level to be timestamped now, got %v", saved.Milestones[0].Time)
+	}
+
+	// An earlier level an hour and a half before gives an estimate
+	m.levelLog.Milestones = append([]levels.Milestone{{Level: 24, Time: saved.Milestones[0].Time.Add(-90 * time.Minute)}}, m.levelLog.Milestones...)
+
+	m.output = nil
+	m.handleClientCommand("/levels")
+	out := strings.Join(m.output, "\n")
+	for _, want := range []string{"25", "250 XP this session", "took 1h30m", "next level in about 1h30m"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected /levels output to contain %q, got:\n%s", want, out)
+		}
+	}
+}
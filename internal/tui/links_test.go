@@ -0,0 +1,43 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/anicolao/dikuclient/internal/ansi"
+)
+
+// TestLinksWrapURLsInOutput verifies /links on makes web addresses in the
+// MUD output hyperlinks, leaving the text the triggers see alone, and that
+// /ansi off removes them
+func TestLinksWrapURLsInOutput(t *testing.T) {
+	m, _ := newConnectedTestModel(t)
+	line := "The board reads: see https://mud.example.com/rules."
+
+	m.Update(mudMsg(line + "\n" + testPrompt))
+	if strings.Contains(strings.Join(m.output, "\n"), "\x1b]8;") {
+		t.Fatal("Expected no links while /links is off")
+	}
+
+	m.handleClientCommand("/links on")
+	m.Update(mudMsg(line + "\n" + testPrompt))
+	want := "The board reads: see " + ansi.Hyperlink("https://mud.example.com/rules", "https://mud.example.com/rules") + "."
+	found := false
+	for _, shown := range m.output {
+		found = found || shown == want
+	}
+	if !found {
+		t.Errorf("Expected the linked line %q, got %q", want, m.output)
+	}
+	for _, recent := range m.recentOutput {
+		if strings.Contains(recent, "\x1b]8;") {
+			t.Errorf("Expected the lines the detectors see left without links, got %q", recent)
+		}
+	}
+
+	m.handleClientCommand("/ansi off")
+	m.Update(mudMsg(line + "\n" + testPrompt))
+	if output := strings.Join(m.output, "\n"); strings.Contains(output, "\x1b") {
+		t.Errorf("Expected /ansi off to remove the links, got %q", output)
+	}
+}
@@ -0,0 +1,46 @@
+package tui
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/anicolao/dikuclient/internal/debuglog"
+)
+
+// TestLogRedaction verifies the MUD log hides strings added with /log
+// redact, the login name and typed passwords, and keeps everything else
+func TestLogRedaction(t *testing.T) {
+	m := newHidePromptTestModel(t)
+	var logged bytes.Buffer
+	m.mudLog = debuglog.New(&logged, 0, 0)
+	m.username = "gandalf"
+	m.applyLogRedaction()
+
+	m.handleLogCommand([]string{"redact", "add", "Elrond"})
+	m.handleLogCommand([]string{"redact", "add", "ok"})
+	if got := m.settingsManager.LogRedactions; len(got) != 1 || got[0] != "Elrond" {
+		t.Fatalf("Expected only Elrond on the redact list, got %q", got)
+	}
+	m.notePassword("mellon123")
+
+	m.Update(mudMsg("Elrond tells Gandalf 'the word is mellon123'.\n"))
+	m.mudLog.Flush()
+	out := logged.String()
+	if !strings.Contains(out, "[REDACTED] tells [REDACTED] 'the word is [REDACTED]'.") {
+		t.Errorf("Expected the name, login and password redacted, got %q", out)
+	}
+	for _, secret := range []string{"Elrond", "Gandalf", "mellon123"} {
+		if strings.Contains(out, secret) {
+			t.Errorf("Expected %q not to be logged, got %q", secret, out)
+		}
+	}
+
+	m.handleLogCommand([]string{"redact", "remove", "Elrond"})
+	logged.Reset()
+	m.Update(mudMsg("Elrond waves.\n"))
+	m.mudLog.Flush()
+	if !strings.Contains(logged.String(), "Elrond waves.") {
+		t.Errorf("Expected Elrond logged once removed from the list, got %q", logged.String())
+	}
+}
@@ -0,0 +1,74 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/anicolao/dikuclient/internal/config"
+	"github.com/anicolao/dikuclient/internal/script"
+)
+
+func TestLoginMenuAnsweredAfterPassword(t *testing.T) {
+	m, server := newConnectedTestModel(t)
+	m.username = "hero"
+	m.password = "secret"
+	m.SetStartupScript([]script.Step{{Command: "wear all"}})
+	err := m.SetLoginMenu([]config.MenuStep{
+		{Pattern: `^1\) Enter the game`, Response: "1"},
+		{Pattern: "choose a character", Response: "2"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to set login menu: %v", err)
+	}
+
+	// A menu before the password isn't answered
+	m.Update(mudMsg("1) Enter the game\nBy what name are you known? "))
+	if sent := readSent(t, server); sent != "hero" {
+		t.Fatalf("Expected the username, got %q", sent)
+	}
+	m.Update(mudMsg("Password: "))
+	if sent := readSent(t, server); sent != "secret" {
+		t.Fatalf("Expected the password, got %q", sent)
+	}
+	if len(m.pendingCommands) != 0 {
+		t.Fatalf("Expected the command file to wait for the menus, got %q", m.pendingCommands)
+	}
+
+	// Unrelated output leaves the menu waiting
+	m.Update(mudMsg("Welcome back, hero!\n"))
+	if m.loginMenuStep != 0 {
+		t.Fatalf("Expected no menu to be answered yet, got step %d", m.loginMenuStep)
+	}
+
+	m.Update(mudMsg("\x1b[1m1) Enter the game\x1b[0m\n2) Change password\nMake your choice: "))
+	if sent := readSent(t, server); sent != "1" {
+		t.Fatalf("Expected the first menu to be answered with 1, got %q", sent)
+	}
+	if len(m.pendingCommands) != 0 {
+		t.Fatalf("Expected the command file to wait for the last menu, got %q", m.pendingCommands)
+	}
+
+	m.Update(mudMsg("Choose a Character:\n1) Hero\n2) Alt\n"))
+	if sent := readSent(t, server); sent != "2" {
+		t.Fatalf("Expected the second menu to be answered with 2, got %q", sent)
+	}
+	if strings.Join(m.pendingCommands, "|") != "wear all" {
+		t.Errorf("Expected the command file to start after the menus, got %q", m.pendingCommands)
+	}
+	if !strings.Contains(strings.Join(m.output, "\n"), "[Auto-login: answering menu with '2']") {
+		t.Errorf("Expected the answers to be reported, got %q", m.output)
+	}
+
+	// Both menus are done, so showing one again sends nothing more
+	m.Update(mudMsg("1) Enter the game\n"))
+	if m.loginMenuStep != 2 {
+		t.Errorf("Expected the menu to stay finished, got step %d", m.loginMenuStep)
+	}
+}
+
+func TestLoginMenuInvalidPattern(t *testing.T) {
+	m := &Model{}
+	if err := m.SetLoginMenu([]config.MenuStep{{Pattern: "(enter", Response: "1"}}); err == nil {
+		t.Error("Expected an error for a pattern that isn't a regular expression")
+	}
+}
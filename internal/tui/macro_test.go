@@ -0,0 +1,114 @@
+package tui
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/anicolao/dikuclient/internal/aliases"
+	"github.com/anicolao/dikuclient/internal/macros"
+	"github.com/anicolao/dikuclient/internal/mapper"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// newMacroTestModel creates a model with an empty macro manager backed by a temp file
+func newMacroTestModel(t *testing.T) *Model {
+	macroManager, err := macros.LoadFromPath(filepath.Join(t.TempDir(), "macros.json"))
+	if err != nil {
+		t.Fatalf("Failed to load macros: %v", err)
+	}
+
+	return &Model{
+		output:       []string{},
+		connected:    true,
+		aliasManager: aliases.NewManager(),
+		macroManager: macroManager,
+		worldMap:     mapper.NewMap(),
+	}
+}
+
+// TestMacroCommandBindsKey tests that /macro binds a key and /macros remove unbinds it
+func TestMacroCommandBindsKey(t *testing.T) {
+	m := newMacroTestModel(t)
+
+	m.handleClientCommand(`/macro F1 "cast 'cure light' self"`)
+
+	macro := m.macroManager.Get("f1")
+	if macro == nil {
+		t.Fatalf("Expected F1 to be bound, output: %v", m.output)
+	}
+	if macro.Command != "cast 'cure light' self" {
+		t.Errorf("Expected command \"cast 'cure light' self\", got %q", macro.Command)
+	}
+
+	m.handleClientCommand("/macros remove 1")
+	if m.macroManager.Get("f1") != nil {
+		t.Error("Expected F1 to be unbound after /macros remove")
+	}
+}
+
+// TestMacroCommandRejectsTextKeys tests that ordinary typing keys can't be bound
+func TestMacroCommandRejectsTextKeys(t *testing.T) {
+	m := newMacroTestModel(t)
+
+	m.handleClientCommand(`/macro a "north"`)
+	if len(m.macroManager.Macros) != 0 {
+		t.Error("Expected binding a letter key to be rejected")
+	}
+}
+
+// TestMacroKeyDispatch tests that pressing a bound key enqueues its command
+func TestMacroKeyDispatch(t *testing.T) {
+	m := newMacroTestModel(t)
+	m.macroManager.Set("F1", "cast 'cure light' self")
+	m.macroManager.Set("F2", "flee")
+
+	model, cmd := m.Update(tea.KeyMsg{Type: tea.KeyF2})
+	m = model.(*Model)
+
+	if len(m.pendingCommands) != 1 || m.pendingCommands[0] != "flee" {
+		t.Errorf("Expected F2 to enqueue 'flee', got %v", m.pendingCommands)
+	}
+	if cmd == nil {
+		t.Error("Expected a tea.Cmd to start queue processing")
+	}
+}
+
+// TestMacroKeyWhileTyping tests that macros fire without disturbing the input line
+func TestMacroKeyWhileTyping(t *testing.T) {
+	m := newMacroTestModel(t)
+	m.macroManager.Set("alt+8", "north")
+	m.currentInput = "say hel"
+	m.cursorPos = len(m.currentInput)
+
+	model, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'8'}, Alt: true})
+	m = model.(*Model)
+
+	if len(m.pendingCommands) != 1 || m.pendingCommands[0] != "north" {
+		t.Errorf("Expected Alt+8 to enqueue 'north', got %v", m.pendingCommands)
+	}
+	if m.currentInput != "say hel" {
+		t.Errorf("Expected input to be unchanged, got %q", m.currentInput)
+	}
+
+	// Unbound keys still type normally
+	model, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'8'}})
+	m = model.(*Model)
+	if m.currentInput != "say hel8" {
+		t.Errorf("Expected plain 8 to be typed, got %q", m.currentInput)
+	}
+}
+
+// TestMacroExpandsAliasesAndSemicolons tests that macro commands go through aliases and splitting
+func TestMacroExpandsAliasesAndSemicolons(t *testing.T) {
+	m := newMacroTestModel(t)
+	m.aliasManager.Add("heal", "cast 'heal' <target>")
+	m.macroManager.Set("F3", "heal bob;say done")
+
+	m.Update(tea.KeyMsg{Type: tea.KeyF3})
+
+	expected := []string{"cast 'heal' bob", "say done"}
+	if strings.Join(m.pendingCommands, "|") != strings.Join(expected, "|") {
+		t.Errorf("Expected %v, got %v", expected, m.pendingCommands)
+	}
+}
@@ -0,0 +1,49 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/anicolao/dikuclient/internal/mapper"
+)
+
+// TestMapAnalysisCommands verifies /map deadends, unexplored and orphans
+// list the expected rooms
+func TestMapAnalysisCommands(t *testing.T) {
+	m := &Model{output: []string{}, worldMap: mapper.NewMap()}
+
+	cave := mapper.NewRoom("Cave", "A dark cave.", []string{"north"})
+	square := mapper.NewRoom("Temple Square", "A large square.", []string{"north", "east"})
+	hall := mapper.NewRoom("Hall", "A narrow hall.", []string{"south"})
+	m.worldMap.AddOrUpdateRoom(cave)
+	m.worldMap.SetLastDirection("")
+	m.worldMap.AddOrUpdateRoom(square)
+	m.worldMap.SetLastDirection("north")
+	m.worldMap.AddOrUpdateRoom(hall)
+
+	tests := []struct {
+		command string
+		want    []string
+	}{
+		{"/map deadends", []string{"Dead Ends (2)", "1. Cave", "[north]", "3. Hall", "[south]"}},
+		{"/map unexplored", []string{"Unexplored Exits (2)", "1. Cave", "2. Temple Square", "[east]"}},
+		{"/map orphans", []string{"Unreachable Rooms (1)", "1. Cave"}},
+	}
+	for _, tt := range tests {
+		m.output = nil
+		m.handleClientCommand(tt.command)
+		out := strings.Join(m.output, "\n")
+		for _, want := range tt.want {
+			if !strings.Contains(out, want) {
+				t.Errorf("%s: expected output to contain %q, got:\n%s", tt.command, want, out)
+			}
+		}
+	}
+
+	// Listed rooms can be clicked to walk there
+	m.output = nil
+	m.handleClientCommand("/map unexplored")
+	if m.roomLines[2] != square.ID {
+		t.Errorf("Expected the square's line to be tagged, got %v", m.roomLines)
+	}
+}
@@ -0,0 +1,67 @@
+package tui
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/anicolao/dikuclient/internal/mapper"
+)
+
+func TestMapGridCommand(t *testing.T) {
+	worldMap, _ := mapper.LoadFromPath(filepath.Join(t.TempDir(), "map.json"))
+	m := &Model{
+		output:   []string{},
+		worldMap: worldMap,
+	}
+
+	m.handleMapCommand([]string{"grid", "on"})
+	if !m.worldMap.UseCoordinates {
+		t.Error("Expected /map grid on to enable coordinate rendering")
+	}
+	if !strings.Contains(m.output[len(m.output)-1], "Grid map: on") {
+		t.Errorf("Expected confirmation, got %q", m.output)
+	}
+
+	m.handleMapCommand([]string{"grid", "off"})
+	if m.worldMap.UseCoordinates {
+		t.Error("Expected /map grid off to disable coordinate rendering")
+	}
+
+	m.handleMapCommand([]string{"grid", "sideways"})
+	if !strings.Contains(m.output[len(m.output)-1], "Usage: /map grid") {
+		t.Errorf("Expected usage for a bad argument, got %q", m.output)
+	}
+}
+
+func TestMapCommandShowsPositionAndConflicts(t *testing.T) {
+	worldMap := mapper.NewMap()
+	hall := mapper.NewRoom("Hall", "A long hall.", []string{"north"})
+	maze := mapper.NewRoom("Maze", "Twisty passages.", []string{"east"})
+	corner := mapper.NewRoom("Maze", "Twisty little passages.", []string{"south"})
+	bend := mapper.NewRoom("Maze", "Little twisty passages.", []string{"west"})
+	wrapped := mapper.NewRoom("Maze", "Passages, twisty and little.", []string{"east"})
+
+	worldMap.AddOrUpdateRoom(hall)
+	for _, step := range []struct {
+		direction string
+		room      *mapper.Room
+	}{{"north", maze}, {"east", corner}, {"south", bend}, {"west", wrapped}} {
+		worldMap.SetLastDirection(step.direction)
+		worldMap.AddOrUpdateRoom(step.room)
+	}
+
+	m := &Model{
+		output:   []string{},
+		worldMap: worldMap,
+	}
+	m.handleMapCommand(nil)
+
+	output := strings.Join(m.output, "\n")
+	if !strings.Contains(output, "Position: \x1b[96m(0, 0, 0)") {
+		t.Errorf("Expected the current room's position, got %q", output)
+	}
+	if !strings.Contains(output, "Coordinate conflicts: 1") || !strings.Contains(output, "Hall, Maze") {
+		t.Errorf("Expected the conflict to be listed, got %q", output)
+	}
+}
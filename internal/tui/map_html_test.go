@@ -0,0 +1,47 @@
+package tui
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/anicolao/dikuclient/internal/mapper"
+)
+
+func TestMapHTMLWritesSVG(t *testing.T) {
+	t.Chdir(t.TempDir())
+	worldMap := mapper.NewMap()
+	worldMap.AddOrUpdateRoom(mapper.NewRoom("Temple Square", "A large square.", []string{"north"}))
+	m := &Model{
+		output:       []string{},
+		worldMap:     worldMap,
+		webSessionID: "abc",
+	}
+
+	m.handleMapCommand([]string{"html"})
+
+	svg, err := os.ReadFile("map.svg")
+	if err != nil {
+		t.Fatalf("Expected map.svg in the session directory: %v", err)
+	}
+	if !strings.Contains(string(svg), `data-room="1"`) {
+		t.Errorf("Expected the room in the SVG, got %s", svg)
+	}
+	if !strings.Contains(strings.Join(m.output, "\n"), "Showing the map of 1 room(s)") {
+		t.Errorf("Expected a confirmation, got %q", m.output)
+	}
+}
+
+func TestMapHTMLNeedsWebMode(t *testing.T) {
+	t.Chdir(t.TempDir())
+	m := &Model{output: []string{}, worldMap: mapper.NewMap()}
+
+	m.handleMapCommand([]string{"html"})
+
+	if !strings.Contains(m.output[0], "only available in web mode") {
+		t.Errorf("Expected a web mode error, got %q", m.output)
+	}
+	if _, err := os.Stat("map.svg"); err == nil {
+		t.Error("Expected no map.svg outside web mode")
+	}
+}
@@ -0,0 +1,37 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/anicolao/dikuclient/internal/mapper"
+)
+
+// TestMapOrientAndCompass verifies /map orient and /map compass change how
+// the map panel is drawn
+func TestMapOrientAndCompass(t *testing.T) {
+	t.Setenv("DIKUCLIENT_CONFIG_DIR", t.TempDir())
+	m := &Model{output: []string{}, worldMap: mapper.NewMap()}
+	visitRoom(m, "north", "Temple")
+
+	m.handleClientCommand("/map orient east")
+	if m.worldMap.Orientation != "east" {
+		t.Errorf("Expected east up, got %q", m.worldMap.Orientation)
+	}
+	m.handleClientCommand("/map orient up")
+	if m.worldMap.Orientation != "east" || !strings.Contains(m.output[len(m.output)-1], "Usage: /map orient") {
+		t.Errorf("Expected a bad direction to be refused, got %q", m.output[len(m.output)-1])
+	}
+	m.handleClientCommand("/map orient north")
+	if m.worldMap.Orientation != "" {
+		t.Errorf("Expected north up to be the default, got %q", m.worldMap.Orientation)
+	}
+
+	m.handleClientCommand("/map compass on")
+	if !m.worldMap.ShowCompass {
+		t.Fatal("Expected the compass on")
+	}
+	if panel := m.worldMap.FormatMapPanelWithLegend(30, 12, nil); !strings.Contains(panel, "↑") {
+		t.Errorf("Expected the compass below the map, got:\n%s", panel)
+	}
+}
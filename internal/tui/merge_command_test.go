@@ -0,0 +1,48 @@
+package tui
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/anicolao/dikuclient/internal/mapper"
+)
+
+func TestMergeCommand(t *testing.T) {
+	worldMap, _ := mapper.LoadFromPath(filepath.Join(t.TempDir(), "map.json"))
+	square := mapper.NewRoom("Temple Square", "A large square.", []string{"north"})
+	street := mapper.NewRoom("Market Street", "A busy street.", []string{"south"})
+	squareAgain := mapper.NewRoom("Temple Square", "A large square, full of pigeons.", []string{"north"})
+
+	worldMap.AddOrUpdateRoom(square)
+	worldMap.SetLastDirection("north")
+	worldMap.AddOrUpdateRoom(street)
+	worldMap.SetLastDirection("south")
+	worldMap.AddOrUpdateRoom(squareAgain)
+
+	m := &Model{
+		output:   []string{},
+		worldMap: worldMap,
+	}
+
+	m.handleMergeCommand([]string{"suggest"})
+	if !strings.Contains(strings.Join(m.output, "\n"), "/merge 1 3") {
+		t.Errorf("Expected the two squares to be suggested, got %q", m.output)
+	}
+
+	m.handleMergeCommand([]string{"1", "3"})
+	if !strings.Contains(m.output[len(m.output)-1], "Merged room #3 into #1 'Temple Square'") {
+		t.Errorf("Expected a confirmation, got %q", m.output[len(m.output)-1])
+	}
+	if len(worldMap.Rooms) != 2 || street.Exits["south"] != square.ID || worldMap.CurrentRoomID != square.ID {
+		t.Errorf("Expected the duplicate to be merged away, got exits %v and current room %q", street.Exits, worldMap.CurrentRoomID)
+	}
+
+	for _, args := range [][]string{{"1"}, {"1", "x"}, {"1", "3"}, {"1", "1"}} {
+		before := len(m.output)
+		m.handleMergeCommand(args)
+		if len(m.output) == before || strings.Contains(m.output[len(m.output)-1], "Merged") {
+			t.Errorf("/merge %v: expected an error, got %q", args, m.output[before:])
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/anicolao/dikuclient/internal/triggers"
+)
+
+func TestMultiLineTriggerCommand(t *testing.T) {
+	m, server := newConnectedTestModel(t)
+	t.Setenv("DIKUCLIENT_CONFIG_DIR", t.TempDir())
+	m.xpTracking = make(map[string]*XPStat)
+	m.triggerManager = triggers.NewManager()
+
+	m.handleTriggerCommand(`trigger -glob -multiline 2 "*Time passes.*You are hungry.*" "eat bread"`)
+	if len(m.triggerManager.Triggers) != 1 || m.triggerManager.Triggers[0].Lines != 2 || m.triggerManager.Triggers[0].Mode != triggers.ModeGlob {
+		t.Fatalf("Expected a 2-line glob trigger, got %+v", m.triggerManager.Triggers)
+	}
+	m.handleTriggersListCommand()
+	if !strings.Contains(m.output[len(m.output)-1], "[2 lines]") {
+		t.Errorf("Expected the line window in the list, got %q", m.output[len(m.output)-1])
+	}
+
+	// Hunger without time passing just before doesn't fire
+	m.Update(mudMsg("Time passes.\nA goblin arrives.\nYou are hungry.\n"))
+	if got := countTriggerFirings(m); got != 0 {
+		t.Fatalf("Expected no firing for lines too far apart, got %d", got)
+	}
+
+	// The lines can arrive in separate messages
+	m.Update(mudMsg("Time passes.\n"))
+	m.Update(mudMsg("\x1b[33mYou are hungry.\x1b[0m\n"))
+	m.Update(mudMsg("A goblin leaves.\n"))
+	if got := countTriggerFirings(m); got != 1 {
+		t.Fatalf("Expected the trigger to fire once, got %d", got)
+	}
+	m.Update(commandQueueTickMsg{})
+	if sent := readSent(t, server); sent != "eat bread" {
+		t.Errorf("Expected 'eat bread' to be sent, got %q", sent)
+	}
+
+	m.output = []string{}
+	m.handleTriggerCommand(`trigger -multiline 99 "a" "b"`)
+	if len(m.triggerManager.Triggers) != 1 || !strings.Contains(strings.Join(m.output, "|"), "-multiline needs a number of lines") {
+		t.Errorf("Expected a window over the limit to be rejected, got %q", m.output)
+	}
+}
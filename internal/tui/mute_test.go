@@ -0,0 +1,63 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/anicolao/dikuclient/internal/mutes"
+)
+
+// TestMutedTellKeptOutOfTellsPanel verifies a muted player's tells and says
+// don't reach the Tells panel or the output, while others still do
+func TestMutedTellKeptOutOfTellsPanel(t *testing.T) {
+	t.Setenv("DIKUCLIENT_CONFIG_DIR", t.TempDir())
+	m, _ := newConnectedTestModel(t)
+	m.handleClientCommand("/mute Spammer")
+
+	m.Update(mudMsg("Spammer tells you 'buy my sword'\nspammer gossips 'cheap swords!'\nAlice tells you 'hi'\n" + testPrompt))
+
+	if len(m.tells) != 1 || m.tells[0] != "Alice: hi" {
+		t.Errorf("Expected only Alice's tell in the Tells panel, got %v", m.tells)
+	}
+	output := strings.Join(m.output, "\n")
+	if strings.Contains(output, "sword") {
+		t.Errorf("Expected the muted player's lines hidden, got:\n%s", output)
+	}
+	if !strings.Contains(output, "Alice tells you 'hi'") {
+		t.Errorf("Expected other tells still shown, got:\n%s", output)
+	}
+
+	m.handleClientCommand("/unmute spammer")
+	m.Update(mudMsg("Spammer tells you 'sorry'\n" + testPrompt))
+	if n := len(m.tells); n != 2 || m.tells[1] != "Spammer: sorry" {
+		t.Errorf("Expected tells shown again after /unmute, got %v", m.tells)
+	}
+}
+
+// TestMuteClearsExistingTells verifies muting a player drops their tells
+// already in the panel and saves the list for the account
+func TestMuteClearsExistingTells(t *testing.T) {
+	t.Setenv("DIKUCLIENT_CONFIG_DIR", t.TempDir())
+	m, _ := newConnectedTestModel(t)
+	m.detectAndParseTell("Spammer tells you 'buy my sword'")
+	m.detectAndParseTell("Alice tells you 'hi'")
+
+	m.handleClientCommand("/mute spammer")
+	if len(m.tells) != 1 || m.tells[0] != "Alice: hi" {
+		t.Errorf("Expected the muted player's tells dropped, got %v", m.tells)
+	}
+
+	saved, err := mutes.Load(m.host, m.port, m.username)
+	if err != nil {
+		t.Fatalf("Failed to load mute list: %v", err)
+	}
+	if !saved.IsMuted("Spammer") {
+		t.Errorf("Expected the mute saved, got %v", saved.Players)
+	}
+
+	m.output = nil
+	m.handleClientCommand("/mute list")
+	if !strings.Contains(strings.Join(m.output, "\n"), "spammer") {
+		t.Errorf("Expected the muted player listed, got:\n%s", strings.Join(m.output, "\n"))
+	}
+}
@@ -0,0 +1,82 @@
+package tui
+
+import (
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/anicolao/dikuclient/internal/mapper"
+)
+
+// TestNamedMapsSwitch verifies /map save, /map list and /map switch keep
+// each named map's rooms apart
+func TestNamedMapsSwitch(t *testing.T) {
+	t.Setenv("DIKUCLIENT_CONFIG_DIR", t.TempDir())
+	worldMap, err := mapper.LoadForServer("example.com", 4000)
+	if err != nil {
+		t.Fatalf("Failed to load map: %v", err)
+	}
+	m := &Model{output: []string{}, worldMap: worldMap, host: "example.com", port: 4000}
+
+	visitRoom(m, "north", "Temple")
+	visitRoom(m, "north", "Market")
+	m.handleClientCommand("/map save main")
+
+	m.handleClientCommand("/map switch experiment")
+	if m.mapName != "experiment" || len(m.worldMap.Rooms) != 0 {
+		t.Fatalf("Expected an empty map named experiment, got %q with %d rooms", m.mapName, len(m.worldMap.Rooms))
+	}
+	if !m.relocalizePending {
+		t.Error("Expected the position to be looked up in the new map")
+	}
+	visitRoom(m, "north", "Dungeon")
+
+	m.output = nil
+	m.handleClientCommand("/map list")
+	out := strings.Join(m.output, "\n")
+	for _, want := range []string{"default", "main", "experiment\x1b[0m (in use)"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected /map list to contain %q, got:\n%s", want, out)
+		}
+	}
+
+	m.handleClientCommand("/map switch main")
+	if got := roomTitleSet(m.worldMap); got != "Market,Temple" {
+		t.Errorf("Expected the main map's rooms, got %s", got)
+	}
+
+	m.handleClientCommand("/map load experiment")
+	if got := roomTitleSet(m.worldMap); got != "Dungeon" {
+		t.Errorf("Expected the experiment map's rooms, got %s", got)
+	}
+
+	m.handleClientCommand("/map switch default")
+	if m.mapName != "" || roomTitleSet(m.worldMap) != "Market,Temple" {
+		t.Errorf("Expected the server's own map back, got %q with %s", m.mapName, roomTitleSet(m.worldMap))
+	}
+}
+
+// TestNamedMapLoadMissing verifies /map load refuses a map never saved
+func TestNamedMapLoadMissing(t *testing.T) {
+	t.Setenv("DIKUCLIENT_CONFIG_DIR", t.TempDir())
+	worldMap := mapper.NewMap()
+	m := &Model{output: []string{}, worldMap: worldMap}
+
+	m.handleClientCommand("/map load nowhere")
+	if m.worldMap != worldMap {
+		t.Error("Expected the map in use to be kept")
+	}
+	if out := strings.Join(m.output, "\n"); !strings.Contains(out, "no map named 'nowhere'") {
+		t.Errorf("Expected an error for a missing map, got:\n%s", out)
+	}
+}
+
+// roomTitleSet returns the sorted titles of a map's rooms, comma separated
+func roomTitleSet(worldMap *mapper.Map) string {
+	var titles []string
+	for _, room := range worldMap.Rooms {
+		titles = append(titles, room.Title)
+	}
+	sort.Strings(titles)
+	return strings.Join(titles, ",")
+}
@@ -0,0 +1,53 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/anicolao/dikuclient/internal/triggers"
+)
+
+// TestNotrigSkipsTriggersForOneMessage verifies /notrig sends the command
+// and keeps exactly the next MUD message from the triggers
+func TestNotrigSkipsTriggersForOneMessage(t *testing.T) {
+	m, server := newConnectedTestModel(t)
+	m.triggerManager = triggers.NewManager()
+	if _, err := m.triggerManager.Add("You are hungry", "eat bread"); err != nil {
+		t.Fatalf("Failed to add trigger: %v", err)
+	}
+
+	m.handleClientCommand("/notrig say I am hungry")
+	if sent := readSent(t, server); sent != "say I am hungry" {
+		t.Fatalf("Expected the command sent, got %q", sent)
+	}
+
+	m.Update(mudMsg("You say 'I am hungry'\nYou are hungry.\n" + testPrompt))
+	if len(m.pendingCommands) != 0 {
+		t.Errorf("Expected no trigger for the /notrig reply, queued %v", m.pendingCommands)
+	}
+	if m.skipTriggers {
+		t.Error("Expected the flag cleared after one message")
+	}
+
+	m.Update(mudMsg("You are hungry.\n" + testPrompt))
+	if len(m.pendingCommands) != 1 || m.pendingCommands[0] != "eat bread" {
+		t.Errorf("Expected the trigger to fire on the next message, queued %v", m.pendingCommands)
+	}
+}
+
+// TestNotrigNeedsCommandAndConnection verifies /notrig without a command
+// or a connection sets nothing
+func TestNotrigNeedsCommandAndConnection(t *testing.T) {
+	m, _ := newConnectedTestModel(t)
+
+	m.handleClientCommand("/notrig")
+	if m.skipTriggers || !strings.Contains(strings.Join(m.output, "\n"), "Usage: /notrig") {
+		t.Errorf("Expected usage without a command, got skip=%v output:\n%s", m.skipTriggers, strings.Join(m.output, "\n"))
+	}
+
+	m.connected = false
+	m.handleClientCommand("/notrig look")
+	if m.skipTriggers {
+		t.Error("Expected no flag set while disconnected")
+	}
+}
@@ -0,0 +1,143 @@
+package tui
+
+import (
+	"bufio"
+	"net"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/anicolao/dikuclient/internal/client"
+	"github.com/anicolao/dikuclient/internal/mapper"
+	"github.com/anicolao/dikuclient/internal/settings"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// newConnectedTestModel creates a model connected to a local listener and
+// returns a reader for the lines the client sends
+func newConnectedTestModel(t *testing.T) (*Model, *bufio.Reader) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	accepted := make(chan net.Conn, 1)
+	go func() {
+		if conn, err := listener.Accept(); err == nil {
+			accepted <- conn
+		}
+	}()
+
+	addr := listener.Addr().(*net.TCPAddr)
+	conn, err := client.NewConnection("127.0.0.1", addr.Port)
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	var server net.Conn
+	select {
+	case server = <-accepted:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Timed out waiting for connection")
+	}
+	t.Cleanup(func() { server.Close() })
+
+	settingsManager, err := settings.LoadFromPath(filepath.Join(t.TempDir(), "settings.json"))
+	if err != nil {
+		t.Fatalf("Failed to load settings: %v", err)
+	}
+
+	m := &Model{
+		output:          []string{"> "},
+		conn:            conn,
+		connected:       true,
+		worldMap:        mapper.NewMap(),
+		settingsManager: settingsManager,
+	}
+	return m, bufio.NewReader(server)
+}
+
+// readSent reads the next line the client sent to the server
+func readSent(t *testing.T, server *bufio.Reader) string {
+	t.Helper()
+	line := make(chan string, 1)
+	go func() {
+		s, _ := server.ReadString('\n')
+		line <- strings.TrimRight(s, "\r\n")
+	}()
+	select {
+	case s := <-line:
+		return s
+	case <-time.After(2 * time.Second):
+		t.Fatal("Timed out waiting for client to send")
+		return ""
+	}
+}
+
+// TestNumpadWalkEmptyInput tests that numpad and arrow keys walk when the input is empty
+func TestNumpadWalkEmptyInput(t *testing.T) {
+	m, server := newConnectedTestModel(t)
+	m.handleNumpadWalkCommand([]string{"on"})
+
+	model, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'8'}})
+	m = model.(*Model)
+
+	if m.currentInput != "" {
+		t.Errorf("Expected numpad key not to be typed, got input %q", m.currentInput)
+	}
+	if m.pendingMovement != "north" {
+		t.Errorf("Expected pendingMovement 'north', got %q", m.pendingMovement)
+	}
+	if sent := readSent(t, server); sent != "north" {
+		t.Errorf("Expected 'north' to be sent, got %q", sent)
+	}
+
+	model, _ = m.Update(tea.KeyMsg{Type: tea.KeyLeft})
+	m = model.(*Model)
+	if m.pendingMovement != "west" {
+		t.Errorf("Expected pendingMovement 'west' after Left arrow, got %q", m.pendingMovement)
+	}
+	if sent := readSent(t, server); sent != "west" {
+		t.Errorf("Expected 'west' to be sent, got %q", sent)
+	}
+}
+
+// TestNumpadWalkWithTextTypesNormally tests that keys edit the input once text is typed
+func TestNumpadWalkWithTextTypesNormally(t *testing.T) {
+	m, _ := newConnectedTestModel(t)
+	m.handleNumpadWalkCommand([]string{"on"})
+	m.currentInput = "get 2"
+	m.cursorPos = len(m.currentInput)
+
+	model, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'8'}})
+	m = model.(*Model)
+	if m.currentInput != "get 28" {
+		t.Errorf("Expected '8' to be typed, got %q", m.currentInput)
+	}
+
+	model, _ = m.Update(tea.KeyMsg{Type: tea.KeyLeft})
+	m = model.(*Model)
+	if m.cursorPos != len("get 28")-1 {
+		t.Errorf("Expected Left arrow to move the cursor, got position %d", m.cursorPos)
+	}
+	if m.pendingMovement != "" {
+		t.Errorf("Expected no movement while typing, got %q", m.pendingMovement)
+	}
+}
+
+// TestNumpadWalkOffTypesNormally tests that digits are typed when the mode is off
+func TestNumpadWalkOffTypesNormally(t *testing.T) {
+	m, _ := newConnectedTestModel(t)
+
+	model, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'8'}})
+	m = model.(*Model)
+	if m.currentInput != "8" {
+		t.Errorf("Expected '8' to be typed with numpad walk off, got %q", m.currentInput)
+	}
+	if m.pendingMovement != "" {
+		t.Errorf("Expected no movement with numpad walk off, got %q", m.pendingMovement)
+	}
+}
@@ -0,0 +1,93 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// typeCommand enters a line as if typed and followed by Enter
+func typeCommand(m *Model, line string) {
+	m.currentInput = line
+	m.cursorPos = len(line)
+	m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+}
+
+// TestOfflineCommandsSentInOrder verifies commands typed before the
+// connection is up are held and sent in order once connected
+func TestOfflineCommandsSentInOrder(t *testing.T) {
+	m, server := newConnectedTestModel(t)
+	conn := m.conn
+	m.conn = nil
+	m.connected = false
+
+	typeCommand(m, "look")
+	typeCommand(m, "north;east")
+	if len(m.offlineCommands) != 2 {
+		t.Fatalf("Expected 2 commands held, got %q", m.offlineCommands)
+	}
+	if !strings.Contains(strings.Join(m.output, "\n"), "[Not connected - 'north;east' held, 2 to send after login]") {
+		t.Errorf("Expected a warning that the command is held, got %q", m.output)
+	}
+
+	m.Update(conn)
+	if len(m.offlineCommands) != 0 {
+		t.Errorf("Expected the held commands to be queued, got %q", m.offlineCommands)
+	}
+	for _, want := range []string{"look", "north", "east"} {
+		m.Update(commandQueueTickMsg{})
+		if sent := readSent(t, server); sent != want {
+			t.Fatalf("Expected %q to be sent, got %q", want, sent)
+		}
+	}
+}
+
+// TestOfflineCommandsWaitForLogin verifies held commands are sent after
+// the auto-login rather than as the username
+func TestOfflineCommandsWaitForLogin(t *testing.T) {
+	m, server := newConnectedTestModel(t)
+	conn := m.conn
+	m.conn = nil
+	m.connected = false
+	m.username = "hero"
+	m.password = "secret"
+
+	typeCommand(m, "score")
+	m.Update(conn)
+	if len(m.pendingCommands) != 0 {
+		t.Fatalf("Expected the command to wait for the login, got %q", m.pendingCommands)
+	}
+
+	m.Update(mudMsg("By what name are you known? "))
+	if sent := readSent(t, server); sent != "hero" {
+		t.Fatalf("Expected the username, got %q", sent)
+	}
+	m.Update(mudMsg("Password: "))
+	if sent := readSent(t, server); sent != "secret" {
+		t.Fatalf("Expected the password, got %q", sent)
+	}
+	m.Update(commandQueueTickMsg{})
+	if sent := readSent(t, server); sent != "score" {
+		t.Errorf("Expected the held command after login, got %q", sent)
+	}
+}
+
+// TestOfflineCommandsCapped verifies only a limited number of commands
+// are held
+func TestOfflineCommandsCapped(t *testing.T) {
+	m, _ := newConnectedTestModel(t)
+	m.conn = nil
+	m.connected = false
+
+	for i := 0; i <= maxOfflineCommands; i++ {
+		typeCommand(m, fmt.Sprintf("say %d", i))
+	}
+	if len(m.offlineCommands) != maxOfflineCommands {
+		t.Errorf("Expected %d commands held, got %d", maxOfflineCommands, len(m.offlineCommands))
+	}
+	if last := m.output[len(m.output)-1]; !strings.Contains(last, fmt.Sprintf("'say %d' dropped", maxOfflineCommands)) {
+		t.Errorf("Expected the extra command to be reported as dropped, got %q", last)
+	}
+}
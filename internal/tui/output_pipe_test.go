@@ -0,0 +1,56 @@
+package tui
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/anicolao/dikuclient/internal/mapper"
+)
+
+// failingWriter stands in for an output pipe command that has exited
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) {
+	return 0, errors.New("exit status 1")
+}
+
+func TestOutputPipeGetsStrippedLines(t *testing.T) {
+	var pipe bytes.Buffer
+	m := &Model{
+		output:     []string{},
+		worldMap:   mapper.NewMap(),
+		xpTracking: make(map[string]*XPStat),
+	}
+	m.SetOutputPipe(&pipe)
+
+	m.Update(mudMsg("\x1b[1;33mThe goblin arrives.\x1b[0m\nYou get 10 gold coins.\n101H 132V 1000X> "))
+
+	want := "The goblin arrives.\nYou get 10 gold coins.\n101H 132V 1000X> \n"
+	if pipe.String() != want {
+		t.Errorf("Expected %q written to the pipe, got %q", want, pipe.String())
+	}
+}
+
+func TestOutputPipeFailureKeepsClientRunning(t *testing.T) {
+	m := &Model{
+		output:     []string{},
+		worldMap:   mapper.NewMap(),
+		xpTracking: make(map[string]*XPStat),
+	}
+	m.SetOutputPipe(failingWriter{})
+
+	m.Update(mudMsg("The goblin arrives.\nThe goblin leaves.\n"))
+
+	if m.outputPipe != nil {
+		t.Error("Expected the failed pipe to be dropped")
+	}
+	out := strings.Join(m.output, "\n")
+	if strings.Count(out, "Output pipe stopped: exit status 1") != 1 {
+		t.Errorf("Expected one note about the pipe, got %q", m.output)
+	}
+	if !strings.Contains(out, "The goblin leaves.") {
+		t.Errorf("Expected output to carry on, got %q", m.output)
+	}
+}
@@ -0,0 +1,83 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func pasteKey(text string) tea.KeyMsg {
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(text), Paste: true}
+}
+
+func TestPasteQueuesOneCommandPerLine(t *testing.T) {
+	m := &Model{output: []string{}}
+
+	_, cmd := m.Update(pasteKey("north\r\nget all\n\nsay hello\n"))
+
+	want := []string{"north", "get all", "say hello"}
+	if strings.Join(m.pendingCommands, "|") != strings.Join(want, "|") {
+		t.Errorf("Expected queued commands %q, got %q", want, m.pendingCommands)
+	}
+	if cmd == nil || !m.commandQueueActive {
+		t.Error("Expected the command queue to start")
+	}
+	if m.currentInput != "" {
+		t.Errorf("Expected an empty input line, got %q", m.currentInput)
+	}
+}
+
+func TestPasteKeepsUnfinishedLineInInput(t *testing.T) {
+	m := &Model{output: []string{}, currentInput: "kill ", cursorPos: 5}
+
+	// The first pasted line completes what was typed; the last has no newline yet
+	m.Update(pasteKey("orc\nsay hel"))
+
+	if len(m.pendingCommands) != 1 || m.pendingCommands[0] != "kill orc" {
+		t.Errorf("Expected 'kill orc' to be queued, got %q", m.pendingCommands)
+	}
+	if m.currentInput != "say hel" || m.cursorPos != len("say hel") {
+		t.Errorf("Expected 'say hel' left in the input line, got %q at %d", m.currentInput, m.cursorPos)
+	}
+
+	// A single-line paste is just typed in
+	m.Update(pasteKey("lo"))
+	if m.currentInput != "say hello" || len(m.pendingCommands) != 1 {
+		t.Errorf("Expected a plain paste to be inserted, got %q", m.currentInput)
+	}
+}
+
+func TestLargePasteNeedsConfirmation(t *testing.T) {
+	var lines []string
+	for i := 1; i <= pasteConfirmLines+1; i++ {
+		lines = append(lines, fmt.Sprintf("say line %d", i))
+	}
+	text := strings.Join(lines, "\n") + "\n"
+
+	m := &Model{output: []string{}}
+	m.Update(pasteKey(text))
+	if len(m.pendingCommands) != 0 || len(m.pendingPaste) != len(lines) {
+		t.Fatalf("Expected the paste to wait for confirmation, got %d queued", len(m.pendingCommands))
+	}
+	if !strings.Contains(m.output[len(m.output)-1], "press Enter") {
+		t.Errorf("Expected a confirmation prompt, got %q", m.output[len(m.output)-1])
+	}
+
+	m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if len(m.pendingCommands) != len(lines) || m.pendingPaste != nil {
+		t.Errorf("Expected Enter to queue all %d lines, got %d", len(lines), len(m.pendingCommands))
+	}
+
+	// Any other key throws the paste away
+	m = &Model{output: []string{}}
+	m.Update(pasteKey(text))
+	m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if len(m.pendingCommands) != 0 || m.pendingPaste != nil {
+		t.Error("Expected the paste to be discarded")
+	}
+	if !strings.Contains(m.output[len(m.output)-1], "discarded") {
+		t.Errorf("Expected the discard to be reported, got %q", m.output[len(m.output)-1])
+	}
+}
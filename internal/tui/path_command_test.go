@@ -0,0 +1,68 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/anicolao/dikuclient/internal/mapper"
+)
+
+// TestPathCommandBetweenNonCurrentRooms tests /path between two rooms the player isn't in
+func TestPathCommandBetweenNonCurrentRooms(t *testing.T) {
+	worldMap := mapper.NewMap()
+
+	gate := mapper.NewRoom("City Gate", "The city gate.", []string{"north"})
+	street := mapper.NewRoom("Main Street", "A busy street.", []string{"north", "south"})
+	square := mapper.NewRoom("Market Square", "A busy market.", []string{"south", "east"})
+	shop := mapper.NewRoom("Weapon Shop", "Swords everywhere.", []string{"west"})
+
+	worldMap.AddOrUpdateRoom(gate)
+	worldMap.SetLastDirection("north")
+	worldMap.AddOrUpdateRoom(street)
+	worldMap.SetLastDirection("north")
+	worldMap.AddOrUpdateRoom(square)
+	worldMap.SetLastDirection("east")
+	worldMap.AddOrUpdateRoom(shop)
+
+	m := Model{
+		output:    []string{},
+		connected: true,
+		worldMap:  worldMap,
+	}
+
+	// Current room is the shop; plan from the gate (#1) to the square (#3)
+	m.handlePathCommand([]string{"1", "3"})
+
+	output := strings.Join(m.output, "\n")
+	if !strings.Contains(output, "Path from 'City Gate' to 'Market Square' (2 steps)") {
+		t.Errorf("Expected path header in output, got:\n%s", output)
+	}
+	if !strings.Contains(output, "1. north -> Main Street") || !strings.Contains(output, "2. north -> Market Square") {
+		t.Errorf("Expected path steps in output, got:\n%s", output)
+	}
+	if worldMap.CurrentRoomID != shop.ID {
+		t.Error("Expected /path not to change the current room")
+	}
+}
+
+// TestPathCommandInvalidArgs tests /path usage and invalid room numbers
+func TestPathCommandInvalidArgs(t *testing.T) {
+	worldMap := mapper.NewMap()
+	worldMap.AddOrUpdateRoom(mapper.NewRoom("City Gate", "The city gate.", []string{"north"}))
+
+	m := Model{
+		output:   []string{},
+		worldMap: worldMap,
+	}
+
+	m.handlePathCommand([]string{"1"})
+	if !strings.Contains(strings.Join(m.output, "\n"), "Usage: /path") {
+		t.Error("Expected usage message for missing argument")
+	}
+
+	m.output = []string{}
+	m.handlePathCommand([]string{"1", "9"})
+	if !strings.Contains(strings.Join(m.output, "\n"), "No room with number 9") {
+		t.Error("Expected error for unknown room number")
+	}
+}
@@ -0,0 +1,113 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/anicolao/dikuclient/internal/aliases"
+	"github.com/anicolao/dikuclient/internal/triggers"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func assertNoEscapes(t *testing.T, lines []string) {
+	t.Helper()
+	for i, line := range lines {
+		if strings.Contains(line, "\x1b") {
+			t.Errorf("Line %d still has escape codes: %q", i, line)
+		}
+	}
+}
+
+func TestAnsiOffStripsStoredOutput(t *testing.T) {
+	m, server := newConnectedTestModel(t)
+	m.output = []string{"\x1b[32mWelcome!\x1b[0m", "> "}
+	m.aliasManager = aliases.NewManager()
+
+	m.handleAnsiCommand([]string{"off"})
+	if !m.settingsManager.PlainText {
+		t.Fatal("Expected /ansi off to turn on plain text")
+	}
+
+	m.Update(mudMsg("\x1b[1;33mTemple Square\x1b[0m\n\x1b[36mA large square.\x1b[0m\n\x1b[32m101H 132V>\x1b[0m "))
+	if !strings.Contains(strings.Join(m.output, "\n"), "Temple Square") {
+		t.Errorf("Expected the MUD output to be kept, got %q", m.output)
+	}
+
+	// Client messages and the echoed command
+	m.handleClientCommand("/help ansi")
+	m.currentInput = "look"
+	m.cursorPos = 4
+	m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if got := readSent(t, server); got != "look" {
+		t.Errorf("Expected 'look' to be sent, got %q", got)
+	}
+
+	assertNoEscapes(t, m.output)
+	if strings.Contains(m.lastViewportContent, "\x1b") {
+		t.Errorf("Expected the rendered output to be plain, got %q", m.lastViewportContent)
+	}
+	if !strings.HasSuffix(m.output[len(m.output)-1], "look") {
+		t.Errorf("Expected the command to be echoed, got %q", m.output[len(m.output)-1])
+	}
+}
+
+// TestAnsiOffKeepsRawTriggers tests that with colors off a -raw trigger
+// still sees the MUD's color codes, though the line is shown without them
+func TestAnsiOffKeepsRawTriggers(t *testing.T) {
+	m, server := newConnectedTestModel(t)
+	t.Setenv("DIKUCLIENT_CONFIG_DIR", t.TempDir())
+	m.xpTracking = make(map[string]*XPStat)
+	m.triggerManager = triggers.NewManager()
+
+	m.handleAnsiCommand([]string{"off"})
+	m.handleTriggerCommand(`trigger -raw "\e[1;31m<mob> dies" "get all corpse"`)
+
+	m.Update(mudMsg("The \x1b[1;31morc\x1b[0m dies\n"))
+	m.Update(commandQueueTickMsg{})
+	if got := readSent(t, server); got != "get all corpse" {
+		t.Errorf("Expected the raw trigger to fire, got %q", got)
+	}
+
+	assertNoEscapes(t, m.output)
+	if !strings.Contains(strings.Join(m.output, "\n"), "The orc dies") {
+		t.Errorf("Expected the line shown without colors, got %q", m.output)
+	}
+}
+
+// TestAnsiOffLeavesOutputToRedraws verifies a redraw with colors off
+// shows the output plain without rewriting it, which /ansi off did once
+func TestAnsiOffLeavesOutputToRedraws(t *testing.T) {
+	m, _ := newConnectedTestModel(t)
+	m.output = []string{"\x1b[32mWelcome!\x1b[0m", "> "}
+	m.handleAnsiCommand([]string{"off"})
+	assertNoEscapes(t, m.output)
+
+	m.output = append(m.output, "\x1b[31mstored in color\x1b[0m", "> ")
+	m.updateViewport()
+	if strings.Contains(m.lastViewportContent, "\x1b") {
+		t.Errorf("Expected the redraw plain, got %q", m.lastViewportContent)
+	}
+	if m.output[len(m.output)-2] != "\x1b[31mstored in color\x1b[0m" {
+		t.Errorf("Expected the redraw to leave the output alone, got %q", m.output)
+	}
+}
+
+func TestAnsiOnKeepsColors(t *testing.T) {
+	m, _ := newConnectedTestModel(t)
+
+	m.handleAnsiCommand([]string{"off"})
+	m.handleAnsiCommand([]string{"on"})
+	if m.settingsManager.PlainText {
+		t.Fatal("Expected /ansi on to turn plain text off")
+	}
+
+	m.Update(mudMsg("\x1b[1;33mTemple Square\x1b[0m\n"))
+	if !strings.Contains(strings.Join(m.output, "\n"), "\x1b[1;33mTemple Square") {
+		t.Errorf("Expected colors to be kept, got %q", m.output)
+	}
+
+	m.handleAnsiCommand([]string{"sideways"})
+	if !strings.Contains(m.output[len(m.output)-1], "Usage: /ansi") {
+		t.Errorf("Expected usage for a bad argument, got %q", m.output[len(m.output)-1])
+	}
+}
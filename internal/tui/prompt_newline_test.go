@@ -0,0 +1,104 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/anicolao/dikuclient/internal/aliases"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// typeAfterPrompt sends a prompt without a newline, types a command on it and
+// sends the reply the way most MUDs do, starting with a newline
+func typeAfterPrompt(t *testing.T, promptNewline bool) *Model {
+	m, _ := newConnectedTestModel(t)
+	m.settingsManager = newHidePromptTestModel(t).settingsManager
+	m.handleHidePromptCommand([]string{"off"})
+	if promptNewline {
+		m.handlePromptNewlineCommand([]string{"on"})
+	}
+	m.xpTracking = make(map[string]*XPStat)
+	m.aliasManager = aliases.NewManager()
+	m.output = []string{}
+
+	m.Update(mudMsg("You are hungry.\n" + testPrompt))
+	m.currentInput = "eat bread"
+	m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m.Update(mudMsg("\nYou eat the bread.\n" + testPrompt))
+	return m
+}
+
+func TestCommandEchoedOnPromptLineByDefault(t *testing.T) {
+	m := typeAfterPrompt(t, false)
+
+	output := strings.Join(m.output, "|")
+	if !strings.Contains(output, "|"+testPrompt+"\x1b[93meat bread\x1b[0m||You eat the bread.|"+testPrompt) {
+		t.Errorf("Expected the command on the prompt line, got %q", m.output)
+	}
+}
+
+func TestPromptNewlinePutsCommandOnItsOwnLine(t *testing.T) {
+	m := typeAfterPrompt(t, true)
+
+	expected := []string{"You are hungry.", testPrompt, "\x1b[93meat bread\x1b[0m", "You eat the bread.", testPrompt, ""}
+	if strings.Join(m.output, "|") != strings.Join(expected, "|") {
+		t.Errorf("Expected output %q, got %q", expected, m.output)
+	}
+}
+
+func TestPromptNewlineWithoutTypedCommand(t *testing.T) {
+	m := newHidePromptTestModel(t)
+	m.handleHidePromptCommand([]string{"off"})
+	m.handlePromptNewlineCommand([]string{"on"})
+	m.output = []string{}
+
+	// The line started after the prompt is dropped again when nothing was
+	// typed, and a prompt ending with a newline gets no extra line
+	m.Update(mudMsg(testPrompt))
+	m.Update(mudMsg("\nA goblin arrives.\n" + testPrompt + "\n"))
+
+	expected := []string{testPrompt, "", "A goblin arrives.", testPrompt}
+	if strings.Join(m.output, "|") != strings.Join(expected, "|") {
+		t.Errorf("Expected output %q, got %q", expected, m.output)
+	}
+}
+
+func TestPromptNewlineSetting(t *testing.T) {
+	m := newHidePromptTestModel(t)
+
+	m.handlePromptNewlineCommand([]string{"on"})
+	if !m.settingsManager.PromptNewline {
+		t.Error("Expected /promptnewline on to turn the setting on")
+	}
+	m.handlePromptNewlineCommand([]string{"off"})
+	if m.settingsManager.PromptNewline {
+		t.Error("Expected /promptnewline off to turn the setting off")
+	}
+
+	m.output = []string{}
+	m.handlePromptNewlineCommand([]string{"sometimes"})
+	if len(m.output) != 1 || !strings.Contains(m.output[0], "Usage: /promptnewline") {
+		t.Errorf("Expected usage message, got %q", m.output)
+	}
+}
+
+func TestServerMarkedPromptGetsNewline(t *testing.T) {
+	m := newHidePromptTestModel(t)
+	m.handleHidePromptCommand([]string{"on"})
+	m.handlePromptNewlineCommand([]string{"on"})
+	m.output = []string{}
+
+	// A prompt the pattern doesn't know is still a prompt when the server
+	// marks it with GA, but isn't hidden as it may be a login question
+	m.Update(mudPromptMsg("Welcome!\nBy what name do you wish to be known? "))
+	m.Update(mudMsg("\nUnknown name.\n"))
+	m.Update(mudMsg("An unmarked line without a newline: "))
+
+	expected := []string{"Welcome!", "By what name do you wish to be known? ", "", "Unknown name.", "An unmarked line without a newline: "}
+	if strings.Join(m.output, "|") != strings.Join(expected, "|") {
+		t.Errorf("Expected output %q, got %q", expected, m.output)
+	}
+	if m.currentPrompt != "" {
+		t.Errorf("Expected the marked prompt left in the output, got status bar prompt %q", m.currentPrompt)
+	}
+}
@@ -0,0 +1,66 @@
+package tui
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/anicolao/dikuclient/internal/mapper"
+)
+
+const customPrompt = "<120hp 80m 95mv>"
+
+func TestPromptPatternHidesCustomPrompt(t *testing.T) {
+	m := newHidePromptTestModel(t)
+	t.Setenv("DIKUCLIENT_CONFIG_DIR", t.TempDir())
+
+	// The built-in check doesn't know this prompt
+	m.Update(mudMsg("A goblin arrives.\n" + customPrompt + " "))
+	if m.currentPrompt != "" || !strings.Contains(strings.Join(m.output, "\n"), customPrompt) {
+		t.Fatalf("Expected the custom prompt to be shown as output, got %q", m.output)
+	}
+
+	m.handlePromptPatternCommand(`"^<\d+hp \d+m \d+mv>"`)
+	m.output = []string{}
+	m.Update(mudMsg("A goblin leaves.\n" + customPrompt + " "))
+
+	if m.currentPrompt != customPrompt {
+		t.Errorf("Expected the custom prompt in the status bar, got %q", m.currentPrompt)
+	}
+	if strings.Contains(strings.Join(m.output, "\n"), customPrompt) {
+		t.Errorf("Expected the custom prompt to be hidden, got %q", m.output)
+	}
+}
+
+func TestPromptPatternCommand(t *testing.T) {
+	mapPath := filepath.Join(t.TempDir(), "map.example.com.4000.json")
+	worldMap, err := mapper.LoadFromPath(mapPath)
+	if err != nil {
+		t.Fatalf("Failed to load map: %v", err)
+	}
+	m := &Model{output: []string{}, worldMap: worldMap}
+
+	m.handlePromptPatternCommand("")
+	if !strings.Contains(m.output[len(m.output)-1], "built-in") {
+		t.Errorf("Expected the built-in check to be reported, got %q", m.output)
+	}
+
+	m.handlePromptPatternCommand(`"^\[HP:\d+"`)
+	reloaded, err := mapper.LoadFromPath(mapPath)
+	if err != nil {
+		t.Fatalf("Failed to reload map: %v", err)
+	}
+	if reloaded.PromptPattern != `^\[HP:\d+` {
+		t.Errorf("Expected the pattern to be saved with the map, got %q", reloaded.PromptPattern)
+	}
+
+	m.handlePromptPatternCommand(`"[HP:("`)
+	if !strings.Contains(strings.Join(m.output, "\n"), "invalid prompt pattern") {
+		t.Errorf("Expected an invalid pattern error, got %q", m.output)
+	}
+
+	m.handlePromptPatternCommand("off")
+	if m.worldMap.PromptPattern != "" || !m.isPromptLine(testPrompt) {
+		t.Errorf("Expected off to restore the built-in check, got %q", m.worldMap.PromptPattern)
+	}
+}
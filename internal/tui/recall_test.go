@@ -4,6 +4,7 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/anicolao/dikuclient/internal/ansi"
 	"github.com/anicolao/dikuclient/internal/mapper"
 )
 
@@ -30,7 +31,7 @@ func TestRecallDetection(t *testing.T) {
 		m.recentOutput = append(m.recentOutput, line)
 		
 		// Check for recall command
-		cleanLine := stripANSI(line)
+		cleanLine := ansi.Strip(line)
 		if strings.Contains(strings.ToLower(cleanLine), "recall") {
 			m.skipNextRoomDetection = true
 		}
@@ -117,7 +118,7 @@ func TestAutoWalkFailureDetection(t *testing.T) {
 		}
 		m.output = append(m.output, line)
 		
-		cleanLine := stripANSI(line)
+		cleanLine := ansi.Strip(line)
 		if m.autoWalking && (strings.Contains(cleanLine, "Alas, you cannot go that way") || 
 			strings.Contains(cleanLine, "cannot go that way")) {
 			m.handleAutoWalkFailure()
@@ -195,7 +196,7 @@ func TestRecallWithANSICodes(t *testing.T) {
 		m.output = append(m.output, line)
 		m.recentOutput = append(m.recentOutput, line)
 		
-		cleanLine := stripANSI(line)
+		cleanLine := ansi.Strip(line)
 		if strings.Contains(strings.ToLower(cleanLine), "recall") {
 			m.skipNextRoomDetection = true
 		}
@@ -0,0 +1,129 @@
+package tui
+
+import (
+	"errors"
+	"net"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/anicolao/dikuclient/internal/client"
+	"github.com/anicolao/dikuclient/internal/mapper"
+)
+
+// errClosedByRemote is the error the connection reports when the MUD goes down
+var errClosedByRemote = errors.New("connection closed by remote host")
+
+// newReconnectTestModel creates a connected model with a reconnect pattern
+// and a listener for it to reconnect to
+func newReconnectTestModel(t *testing.T) *Model {
+	m, _ := newConnectedTestModel(t)
+	worldMap, err := mapper.LoadFromPath(filepath.Join(t.TempDir(), "map.json"))
+	if err != nil {
+		t.Fatalf("Failed to load map: %v", err)
+	}
+	m.worldMap = worldMap
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			t.Cleanup(func() { conn.Close() })
+		}
+	}()
+	m.host = "127.0.0.1"
+	m.port = listener.Addr().(*net.TCPAddr).Port
+
+	m.handleClientCommand(`/reconnect-on "rebooting, please reconnect" 1`)
+	return m
+}
+
+// TestReconnectOnSchedulesReconnect verifies the reboot warning schedules a
+// reconnect that runs after the delay once the MUD has closed the connection
+func TestReconnectOnSchedulesReconnect(t *testing.T) {
+	m := newReconnectTestModel(t)
+
+	now := time.Now()
+	cmd := m.detectReconnectWarning("The game is rebooting, please reconnect in 60 seconds.", now)
+	if cmd == nil {
+		t.Fatal("Expected the warning to schedule a reconnect")
+	}
+	if want := now.Add(time.Second); !m.reconnectAt.Equal(want) {
+		t.Errorf("Expected the reconnect at %v, got %v", want, m.reconnectAt)
+	}
+
+	// The MUD going down leaves the session open
+	m.conn.Close()
+	if _, quit := m.Update(errMsg(errClosedByRemote)); quit != nil {
+		t.Fatal("Expected the client to wait for the reconnect instead of quitting")
+	}
+	if m.connected {
+		t.Fatal("Expected the session to be marked disconnected")
+	}
+
+	msg, ok := cmd().(reconnectMsg)
+	if !ok {
+		t.Fatal("Expected a reconnect message after the delay")
+	}
+	_, connect := m.Update(msg)
+	if connect == nil {
+		t.Fatal("Expected the reconnect to connect again")
+	}
+	conn, ok := connect().(*client.Connection)
+	if !ok {
+		t.Fatal("Expected a new connection")
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	m.Update(conn)
+	if !m.connected || m.conn != conn || m.reconnecting || !m.reconnectAt.IsZero() {
+		t.Errorf("Expected the session connected again, got connected=%v reconnecting=%v", m.connected, m.reconnecting)
+	}
+}
+
+// TestReconnectOnCancelledByManualReconnect verifies reconnecting by hand
+// first cancels the scheduled reconnect
+func TestReconnectOnCancelledByManualReconnect(t *testing.T) {
+	m := newReconnectTestModel(t)
+
+	m.Update(mudMsg("The game is rebooting, please reconnect in 60 seconds.\n" + testPrompt))
+	scheduled := m.reconnectAt
+	if scheduled.IsZero() {
+		t.Fatal("Expected the warning in the MUD output to schedule a reconnect")
+	}
+
+	// Still connected: /reconnect refuses
+	m.output = nil
+	if cmd := m.handleClientCommand("/reconnect"); cmd != nil {
+		t.Error("Expected no reconnect while connected")
+	}
+
+	m.conn.Close()
+	m.Update(errMsg(errClosedByRemote))
+	connect := m.handleClientCommand("/reconnect")
+	if connect == nil {
+		t.Fatal("Expected /reconnect to connect again")
+	}
+	if !m.reconnectAt.IsZero() {
+		t.Error("Expected the manual reconnect to cancel the scheduled one")
+	}
+	conn := connect().(*client.Connection)
+	t.Cleanup(func() { conn.Close() })
+	m.Update(conn)
+
+	m.output = nil
+	if _, cmd := m.Update(reconnectMsg{at: scheduled}); cmd != nil {
+		t.Error("Expected the cancelled reconnect to do nothing")
+	}
+	if strings.Contains(strings.Join(m.output, "\n"), "Reconnecting") {
+		t.Errorf("Expected no second reconnect, got:\n%s", strings.Join(m.output, "\n"))
+	}
+}
@@ -0,0 +1,61 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// TestResizeReflowsOutput verifies a long line already printed is wrapped to
+// the new width when the terminal is resized, while the output keeps it as
+// one line
+func TestResizeReflowsOutput(t *testing.T) {
+	long := "The quick brown fox jumps over the lazy dog near the riverbank"
+	m := &Model{output: []string{long, "> "}, sidebarWidth: 10}
+
+	m.Update(tea.WindowSizeMsg{Width: 41, Height: 30})
+	want := "The quick brown fox jumps over\nthe lazy dog near the\nriverbank\n> "
+	if m.lastViewportContent != want {
+		t.Fatalf("Expected the line wrapped at 30 columns, got %q", m.lastViewportContent)
+	}
+
+	m.Update(tea.WindowSizeMsg{Width: 31, Height: 30})
+	want = "The quick brown fox\njumps over the lazy\ndog near the\nriverbank\n> "
+	if m.lastViewportContent != want {
+		t.Errorf("Expected the line rewrapped at 20 columns, got %q", m.lastViewportContent)
+	}
+
+	m.Update(tea.WindowSizeMsg{Width: 100, Height: 30})
+	if !strings.HasPrefix(m.lastViewportContent, long+"\n") {
+		t.Errorf("Expected the line whole again when wide enough, got %q", m.lastViewportContent)
+	}
+	if len(m.output) != 2 || m.output[0] != long {
+		t.Errorf("Expected the output to keep the unwrapped line, got %q", m.output)
+	}
+}
+
+// TestRedrawWrapsOnlyNewLines verifies a redraw reuses the lines wrapped
+// for the last one, wraps lines added since, and wraps everything again
+// after a resize
+func TestRedrawWrapsOnlyNewLines(t *testing.T) {
+	m := &Model{output: []string{"one", "two", "> "}, sidebarWidth: 10}
+	m.Update(tea.WindowSizeMsg{Width: 41, Height: 30})
+
+	// Mark the cached line, which a redraw must not wrap again
+	m.wrapped.wrapped[0] = "cached"
+	m.output = append(m.output[:2], "three four five six seven eight nine ten", "> ")
+	m.updateViewport()
+	want := "cached\ntwo\nthree four five six seven\neight nine ten\n> "
+	if m.lastViewportContent != want {
+		t.Fatalf("Expected only the new line wrapped, got %q", m.lastViewportContent)
+	}
+	if got := m.wrapped.heights; len(got) != 3 || got[2] != 2 {
+		t.Errorf("Expected the line counts kept for clicks, got %v", got)
+	}
+
+	m.Update(tea.WindowSizeMsg{Width: 41, Height: 30})
+	if !strings.HasPrefix(m.lastViewportContent, "one\n") {
+		t.Errorf("Expected a resize to wrap every line again, got %q", m.lastViewportContent)
+	}
+}
@@ -0,0 +1,93 @@
+package tui
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/anicolao/dikuclient/internal/aliases"
+	"github.com/anicolao/dikuclient/internal/mapper"
+	"github.com/anicolao/dikuclient/internal/triggers"
+)
+
+func TestReloadPicksUpChangedFiles(t *testing.T) {
+	t.Setenv("DIKUCLIENT_CONFIG_DIR", t.TempDir())
+
+	m := &Model{
+		output:         []string{},
+		host:           "localhost",
+		port:           4000,
+		worldMap:       mapper.NewMap(),
+		triggerManager: triggers.NewManager(),
+		aliasManager:   aliases.NewManager(),
+	}
+
+	// Simulate the files being edited or synced from elsewhere
+	triggerManager, _ := triggers.Load()
+	triggerManager.Add("You are hungry", "eat bread")
+	triggerManager.Add("You are thirsty", "drink water")
+	if err := triggerManager.Save(); err != nil {
+		t.Fatalf("Failed to save triggers: %v", err)
+	}
+	aliasManager, _ := aliases.Load()
+	aliasManager.Add("gt", "get <item> corpse")
+	if err := aliasManager.Save(); err != nil {
+		t.Fatalf("Failed to save aliases: %v", err)
+	}
+	worldMap, _ := mapper.LoadForServer("localhost", 4000)
+	worldMap.AddOrUpdateRoom(mapper.NewRoom("Temple Square", "A large square.", []string{"north"}))
+	if err := worldMap.Save(); err != nil {
+		t.Fatalf("Failed to save map: %v", err)
+	}
+
+	m.handleReloadCommand([]string{"triggers"})
+	if len(m.triggerManager.Triggers) != 2 {
+		t.Errorf("Expected 2 reloaded triggers, got %d", len(m.triggerManager.Triggers))
+	}
+	if len(m.aliasManager.Aliases) != 0 {
+		t.Error("Expected aliases to be left alone when reloading triggers")
+	}
+	if !strings.Contains(m.output[len(m.output)-1], "Reloaded 2 trigger(s)") {
+		t.Errorf("Expected trigger count to be reported, got %q", m.output)
+	}
+
+	m.handleReloadCommand(nil)
+	if len(m.aliasManager.Aliases) != 1 || m.aliasManager.Aliases[0].Name != "gt" {
+		t.Errorf("Expected reloaded alias 'gt', got %+v", m.aliasManager.Aliases)
+	}
+	if len(m.worldMap.Rooms) != 1 {
+		t.Errorf("Expected reloaded map with 1 room, got %d", len(m.worldMap.Rooms))
+	}
+	if output := strings.Join(m.output, "\n"); !strings.Contains(output, "Reloaded 1 alias(es)") || !strings.Contains(output, "Reloaded map with 1 room(s)") {
+		t.Errorf("Expected alias and room counts to be reported, got %q", m.output)
+	}
+}
+
+func TestReloadKeepsManagerOnError(t *testing.T) {
+	t.Setenv("DIKUCLIENT_CONFIG_DIR", t.TempDir())
+
+	triggerManager := triggers.NewManager()
+	triggerManager.Add("You are hungry", "eat bread")
+	m := &Model{
+		output:         []string{},
+		triggerManager: triggerManager,
+	}
+
+	path, _ := triggers.GetTriggersPath()
+	if err := os.WriteFile(path, []byte("{not json"), 0600); err != nil {
+		t.Fatalf("Failed to write triggers file: %v", err)
+	}
+
+	m.handleReloadCommand([]string{"triggers"})
+	if m.triggerManager != triggerManager {
+		t.Error("Expected the current triggers to be kept when the file is invalid")
+	}
+	if !strings.Contains(m.output[len(m.output)-1], "Error reloading triggers") {
+		t.Errorf("Expected an error to be reported, got %q", m.output)
+	}
+
+	m.handleReloadCommand([]string{"bogus"})
+	if !strings.Contains(m.output[len(m.output)-1], "Usage: /reload") {
+		t.Errorf("Expected usage for an unknown argument, got %q", m.output)
+	}
+}
@@ -0,0 +1,101 @@
+package tui
+
+import (
+	"testing"
+
+	"github.com/anicolao/dikuclient/internal/mapper"
+)
+
+var templeSquareOutput = []string{
+	"Welcome back! You have no new mail.",
+	"Temple Square",
+	"    You are standing in a large temple square. The ancient stones",
+	"speak of a glorious past.",
+	"Exits: north, south, east",
+}
+
+func newRelocalizeTestModel(t *testing.T) (*Model, *mapper.Room, *mapper.Room) {
+	t.Setenv("DIKUCLIENT_CONFIG_DIR", t.TempDir())
+	worldMap := mapper.NewMap()
+	temple := mapper.NewRoom("Temple Square", "You are standing in a large temple square. The ancient stones speak of a glorious past.", []string{"north", "south", "east"})
+	worldMap.AddOrUpdateRoom(temple)
+	worldMap.SetLastDirection("north")
+	alley := mapper.NewRoom("Dark Alley", "A narrow alley.", []string{"south"})
+	worldMap.AddOrUpdateRoom(alley)
+
+	// Reconnecting: the saved position is the alley
+	m := &Model{
+		output:            []string{},
+		worldMap:          worldMap,
+		relocalizePending: true,
+	}
+	return m, temple, alley
+}
+
+func TestRelocalizeOnReconnect(t *testing.T) {
+	m, temple, alley := newRelocalizeTestModel(t)
+	rooms := len(m.worldMap.Rooms)
+
+	// The MUD drops us at the temple without us moving
+	m.recentOutput = templeSquareOutput
+	m.detectAndUpdateRoom()
+
+	if m.worldMap.CurrentRoomID != temple.ID {
+		t.Fatalf("Expected to be relocalized to the temple, got %q", m.worldMap.CurrentRoomID)
+	}
+	if m.relocalizePending {
+		t.Error("Expected relocalizing to happen only once")
+	}
+	if len(m.worldMap.Rooms) != rooms {
+		t.Errorf("Expected no room to be added, got %d rooms", len(m.worldMap.Rooms))
+	}
+	if dest := alley.Exits["south"]; dest != temple.ID && dest != "" {
+		t.Errorf("Expected no new link from the alley, got south -> %s", dest)
+	}
+
+	// Walking from there links rooms as usual
+	m.pendingMovement = "east"
+	m.recentOutput = []string{
+		"Market Street",
+		"    Stalls line the street.",
+		"Exits: west",
+	}
+	m.detectAndUpdateRoom()
+	if temple.Exits["east"] == "" {
+		t.Error("Expected the temple to link east after relocalizing")
+	}
+}
+
+func TestRelocalizeUnknownRoomDoesNotLink(t *testing.T) {
+	m, _, alley := newRelocalizeTestModel(t)
+
+	// Nothing matches until we walk into a room we haven't mapped
+	m.recentOutput = []string{
+		"Cellar",
+		"    A damp cellar.",
+		"Exits: up",
+	}
+	m.detectAndUpdateRoom()
+	if m.worldMap.CurrentRoomID != alley.ID || !m.relocalizePending {
+		t.Fatalf("Expected to keep looking without a movement, got %q", m.worldMap.CurrentRoomID)
+	}
+
+	m.pendingMovement = "north"
+	m.recentOutput = []string{
+		"Guard Tower",
+		"    A tall tower.",
+		"Exits: south",
+	}
+	m.detectAndUpdateRoom()
+
+	current := m.worldMap.GetCurrentRoom()
+	if current == nil || current.Title != "Guard Tower" {
+		t.Fatalf("Expected the new room to be added, got %v", current)
+	}
+	if dest := alley.Exits["north"]; dest != "" {
+		t.Errorf("Expected no link from the saved position, got north -> %s", dest)
+	}
+	if m.relocalizePending {
+		t.Error("Expected relocalizing to stop after the first room")
+	}
+}
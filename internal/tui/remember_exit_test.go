@@ -0,0 +1,43 @@
+package tui
+
+import (
+	"reflect"
+	"testing"
+)
+
+// TestRememberExit verifies /remember-exit maps the room a command leads to
+// as a special exit that /go walks through
+func TestRememberExit(t *testing.T) {
+	m, server := newConnectedTestModel(t)
+
+	visitRoom(m, "north", "Clearing")
+	clearing := m.worldMap.CurrentRoomID
+	visitRoom(m, "north", "Road")
+	road := m.worldMap.CurrentRoomID
+	visitRoom(m, "south", "Clearing")
+
+	m.handleClientCommand(`/remember-exit "enter portal"`)
+	if sent := readSent(t, server); sent != "enter portal" {
+		t.Fatalf("Expected the exit command to be sent, got %q", sent)
+	}
+	if m.pendingMovement != "enter portal" {
+		t.Fatalf("Expected the exit command to be the pending movement, got %q", m.pendingMovement)
+	}
+	visitRoom(m, m.pendingMovement, "Astral Plane")
+	astral := m.worldMap.CurrentRoomID
+
+	if got := m.worldMap.Rooms[clearing].Exits["enter portal"]; got != astral {
+		t.Fatalf("Expected the special exit to lead to the Astral Plane, got %q", got)
+	}
+
+	m.worldMap.CurrentRoomID = road
+	m.handleClientCommand("/go astral")
+	if want := []string{"south", "enter portal"}; !reflect.DeepEqual(m.autoWalkPath, want) {
+		t.Errorf("Expected /go to walk %v, got %v", want, m.autoWalkPath)
+	}
+
+	m.worldMap.CurrentRoomID = clearing
+	if got := m.movementFor("Enter Portal"); got != "enter portal" {
+		t.Errorf("Expected typing the exit command to count as movement, got %q", got)
+	}
+}
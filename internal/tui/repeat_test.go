@@ -0,0 +1,79 @@
+package tui
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/anicolao/dikuclient/internal/aliases"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// TestRepeatQueuesCommand verifies /repeat 3 foo queues exactly three foo
+// commands and sends them through the command queue
+func TestRepeatQueuesCommand(t *testing.T) {
+	m, server := newConnectedTestModel(t)
+
+	if cmd := m.handleClientCommand("/repeat 3 foo"); cmd == nil {
+		t.Fatal("Expected the queue to start")
+	}
+	if want := []string{"foo", "foo", "foo"}; !reflect.DeepEqual(m.pendingCommands, want) {
+		t.Fatalf("Expected %v queued, got %v", want, m.pendingCommands)
+	}
+
+	for i := 0; i < 3; i++ {
+		m.Update(commandQueueTickMsg{})
+		if sent := readSent(t, server); sent != "foo" {
+			t.Fatalf("Expected foo to be sent, got %q", sent)
+		}
+	}
+	if len(m.pendingCommands) != 0 {
+		t.Errorf("Expected the queue empty, got %v", m.pendingCommands)
+	}
+}
+
+// TestRepeatExpandsAliasesAndSemicolons verifies each repeat runs every
+// command of an alias
+func TestRepeatExpandsAliasesAndSemicolons(t *testing.T) {
+	m, _ := newConnectedTestModel(t)
+	m.aliasManager = aliases.NewManager()
+	m.aliasManager.Add("loot", "get all corpse;sac corpse")
+
+	m.handleClientCommand("/repeat 2 loot")
+	want := []string{"get all corpse", "sac corpse", "get all corpse", "sac corpse"}
+	if !reflect.DeepEqual(m.pendingCommands, want) {
+		t.Errorf("Expected %v queued, got %v", want, m.pendingCommands)
+	}
+}
+
+// TestRepeatLargeCountNeedsConfirmation verifies big counts wait for Enter
+// and absurd ones are refused
+func TestRepeatLargeCountNeedsConfirmation(t *testing.T) {
+	m, _ := newConnectedTestModel(t)
+
+	m.handleClientCommand("/repeat 1000 kill orc")
+	if len(m.pendingCommands) != 0 || m.pendingRepeat != nil {
+		t.Fatal("Expected a count over the cap to be refused")
+	}
+
+	m.handleClientCommand("/repeat 50 kill orc")
+	if len(m.pendingCommands) != 0 || len(m.pendingRepeat) != 50 {
+		t.Fatalf("Expected 50 commands waiting for confirmation, got %d queued", len(m.pendingCommands))
+	}
+	m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if m.pendingRepeat != nil || len(m.pendingCommands) != 0 {
+		t.Fatal("Expected another key to cancel the repeat")
+	}
+
+	m.handleClientCommand("/repeat 50 kill orc")
+	m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if len(m.pendingCommands) != 50 {
+		t.Errorf("Expected Enter to queue 50 commands, got %d", len(m.pendingCommands))
+	}
+
+	m.output = nil
+	m.handleClientCommand("/repeat foo")
+	if !strings.Contains(strings.Join(m.output, "\n"), "Usage: /repeat <n> <command>") {
+		t.Errorf("Expected usage for a missing count, got:\n%s", strings.Join(m.output, "\n"))
+	}
+}
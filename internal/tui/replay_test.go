@@ -0,0 +1,90 @@
+package tui
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/anicolao/dikuclient/internal/client"
+	"github.com/anicolao/dikuclient/internal/mapper"
+	"github.com/anicolao/dikuclient/internal/ticktimer"
+)
+
+func newReplayTestModel(chunks []client.RecordedChunk) *Model {
+	m := &Model{
+		output:           []string{},
+		worldMap:         mapper.NewMap(),
+		xpTracking:       make(map[string]*XPStat),
+		tickTimerManager: ticktimer.NewManager(0),
+	}
+	m.SetReplay("test.rec", chunks, 0)
+	return m
+}
+
+func TestReplayFeedsMudMessages(t *testing.T) {
+	m := newReplayTestModel([]client.RecordedChunk{
+		{Ms: 0, Data: []byte("Welcome to the test MUD!\r\n")},
+		{Ms: 500, Data: []byte("A goblin arrives.\r\n")},
+	})
+
+	msg := m.connect()
+	conn, ok := msg.(*client.Connection)
+	if !ok {
+		t.Fatalf("Expected a replay connection, got %T", msg)
+	}
+	defer conn.Close()
+	m.Update(conn)
+
+	if !strings.Contains(strings.Join(m.output, "\n"), "Replaying test.rec") {
+		t.Errorf("Expected a replay notice, got %q", m.output)
+	}
+
+	// Feed what the replay produces through Update until both lines are in
+	deadline := time.Now().Add(2 * time.Second)
+	for !strings.Contains(strings.Join(m.output, "\n"), "A goblin arrives.") {
+		if time.Now().After(deadline) {
+			t.Fatalf("Timed out waiting for replayed output, got %q", m.output)
+		}
+		next := m.listenForMessages()()
+		if _, ok := next.(mudMsg); !ok {
+			t.Fatalf("Expected replayed data as mudMsg, got %T", next)
+		}
+		m.Update(next)
+	}
+	if !strings.Contains(strings.Join(m.output, "\n"), "Welcome to the test MUD!") {
+		t.Errorf("Expected the first chunk in output, got %q", m.output)
+	}
+}
+
+func TestRecordCommand(t *testing.T) {
+	m, _ := newConnectedTestModel(t)
+	path := filepath.Join(t.TempDir(), "bug.rec")
+
+	m.handleRecordCommand([]string{"session", path})
+	if m.conn.Recording() == nil {
+		t.Fatalf("Expected recording to start, got %q", m.output)
+	}
+
+	m.handleRecordCommand(nil)
+	if last := m.output[len(m.output)-1]; !strings.Contains(last, path) {
+		t.Errorf("Expected status to name the file, got %q", last)
+	}
+
+	m.handleRecordCommand([]string{"stop"})
+	if m.conn.Recording() != nil {
+		t.Error("Expected recording to stop")
+	}
+	if _, err := client.LoadRecording(path); err != nil {
+		t.Errorf("Expected a readable recording, got %v", err)
+	}
+
+	m.handleRecordCommand([]string{"stop"})
+	if last := m.output[len(m.output)-1]; !strings.Contains(last, "Not recording") {
+		t.Errorf("Expected a warning when not recording, got %q", last)
+	}
+	m.handleRecordCommand([]string{"bogus"})
+	if last := m.output[len(m.output)-1]; !strings.Contains(last, "Usage") {
+		t.Errorf("Expected usage, got %q", last)
+	}
+}
@@ -0,0 +1,66 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestRetryCastResendsOnFailure verifies a failure message sends the cast
+// again and that the tries run out
+func TestRetryCastResendsOnFailure(t *testing.T) {
+	m, server := newConnectedTestModel(t)
+	m.handleClientCommand(`/retrycast "cast 'sanctuary'" "lost your concentration" 2`)
+
+	m.Update(commandQueueTickMsg{})
+	if sent := readSent(t, server); sent != "cast 'sanctuary'" {
+		t.Fatalf("Expected the cast to be sent, got %q", sent)
+	}
+
+	m.Update(mudMsg("You lost your concentration!\n" + testPrompt))
+	if got := strings.Join(m.pendingCommands, "|"); got != "cast 'sanctuary'" {
+		t.Fatalf("Expected the cast to be queued again, got %q", got)
+	}
+	m.Update(commandQueueTickMsg{})
+	if sent := readSent(t, server); sent != "cast 'sanctuary'" {
+		t.Fatalf("Expected the cast to be sent again, got %q", sent)
+	}
+	if m.retryCast == nil || m.retryCast.tries != 2 {
+		t.Fatalf("Expected the second try to be tracked, got %+v", m.retryCast)
+	}
+
+	m.output = nil
+	m.Update(mudMsg("You lost your concentration!\n" + testPrompt))
+	if len(m.pendingCommands) != 0 {
+		t.Errorf("Expected no more casts after the last try, got %q", m.pendingCommands)
+	}
+	if m.retryCast != nil {
+		t.Error("Expected the cast to be given up")
+	}
+	if out := strings.Join(m.output, "\n"); !strings.Contains(out, "giving up") {
+		t.Errorf("Expected a giving up message, got:\n%s", out)
+	}
+}
+
+// TestRetryCastStopsOnSuccess verifies a cast with no failure in time is
+// taken to have worked and not sent again
+func TestRetryCastStopsOnSuccess(t *testing.T) {
+	m, server := newConnectedTestModel(t)
+	m.handleClientCommand(`/retrycast "cast 'bless'" "lost your concentration"`)
+	m.Update(commandQueueTickMsg{})
+	readSent(t, server)
+
+	m.Update(mudMsg("You feel righteous.\n" + testPrompt))
+	if m.retryCast == nil {
+		t.Fatal("Expected the cast to be watched within the window")
+	}
+
+	m.retryCast.sentAt = time.Now().Add(-retryCastWindow - time.Second)
+	m.Update(mudMsg("You lost your concentration!\n" + testPrompt))
+	if len(m.pendingCommands) != 0 {
+		t.Errorf("Expected no cast after the window, got %q", m.pendingCommands)
+	}
+	if m.retryCast != nil {
+		t.Error("Expected the cast to be forgotten after the window")
+	}
+}
@@ -0,0 +1,80 @@
+package tui
+
+import (
+	"testing"
+
+	"github.com/anicolao/dikuclient/internal/mapper"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// newRoomClickTestModel creates a model standing in a square with a street
+// to the north, rendered at 120x40
+func newRoomClickTestModel() (*Model, *mapper.Room) {
+	m := &Model{
+		output:       []string{"Welcome!", "a multi-line\nmessage"},
+		worldMap:     mapper.NewMap(),
+		sidebarWidth: 60,
+	}
+	m.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+
+	square := mapper.NewRoom("Temple Square", "A large square.", []string{"north"})
+	street := mapper.NewRoom("Market Street", "A busy street.", []string{"south"})
+	m.worldMap.AddOrUpdateRoom(square)
+	m.worldMap.SetLastDirection("north")
+	m.worldMap.AddOrUpdateRoom(street)
+	m.worldMap.SetLastDirection("south")
+	m.worldMap.AddOrUpdateRoom(square)
+
+	m.handleNearbyCommand()
+	m.updateViewport()
+	m.View()
+	return m, street
+}
+
+func TestRoomAtPosition(t *testing.T) {
+	m, street := newRoomClickTestModel()
+
+	// Content: "Welcome!", two lines of message, the header, the distance
+	// header and the street, which is content line 5. The viewport starts
+	// below the status bar and the top border.
+	if m.viewport.YOffset != 0 {
+		t.Fatalf("Expected all output to fit in the viewport, offset %d", m.viewport.YOffset)
+	}
+	if room := m.roomAtPosition(10, 2+5); room != street {
+		t.Errorf("Expected the street at row 7, got %+v", room)
+	}
+
+	// Other lines, the border and the sidebar aren't room listings
+	for _, pos := range [][2]int{{10, 2 + 4}, {10, 2}, {0, 7}, {100, 7}, {10, 1}} {
+		if room := m.roomAtPosition(pos[0], pos[1]); room != nil {
+			t.Errorf("Expected no room at %v, got %q", pos, room.Title)
+		}
+	}
+
+	// When scrolled, the same line moves up the screen
+	for i := 0; i < 60; i++ {
+		m.output = append(m.output, "filler")
+	}
+	m.updateViewport()
+	m.viewport.SetYOffset(3)
+	if room := m.roomAtPosition(10, 2+2); room != street {
+		t.Errorf("Expected the street at row 4 after scrolling, got %+v", room)
+	}
+
+	// With the room description split off, the viewport starts lower
+	m.viewport.SetYOffset(0)
+	m.hasDescriptionSplit = true
+	if room := m.roomAtPosition(10, 9+5); room != street {
+		t.Errorf("Expected the street at row 14 with the description split, got %+v", room)
+	}
+}
+
+func TestClickingRoomWalksThere(t *testing.T) {
+	m, _ := newRoomClickTestModel()
+
+	m.Update(tea.MouseMsg{X: 10, Y: 7, Action: tea.MouseActionPress, Button: tea.MouseButtonLeft})
+
+	if !m.autoWalking || len(m.pendingCommands) != 1 || m.pendingCommands[0] != "north" {
+		t.Errorf("Expected to auto-walk north, got walking=%v queue=%q", m.autoWalking, m.pendingCommands)
+	}
+}
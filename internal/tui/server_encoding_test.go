@@ -0,0 +1,33 @@
+package tui
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/anicolao/dikuclient/internal/client"
+)
+
+func TestServerEncodingFromConfig(t *testing.T) {
+	configDir := t.TempDir()
+	t.Setenv("DIKUCLIENT_CONFIG_DIR", configDir)
+	data := `{"servers": [{"name": "Old MUD", "host": "old.test.com", "port": 4000, "charset": "latin1", "line_ending": "lf"}], "accounts": []}`
+	if err := os.WriteFile(filepath.Join(configDir, "accounts.json"), []byte(data), 0600); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	m := &Model{}
+	m.SetCharset(client.CharsetCP437)
+	m.SetLineEnding(client.LineEndingCR)
+
+	charset, lineEnding := m.serverEncoding("old.test.com", 4000)
+	if charset != client.CharsetLatin1 || lineEnding != client.LineEndingLF {
+		t.Errorf("Expected the saved server settings, got %v %v", charset, lineEnding)
+	}
+
+	// Servers without saved settings use the model's
+	charset, lineEnding = m.serverEncoding("new.test.com", 4000)
+	if charset != client.CharsetCP437 || lineEnding != client.LineEndingCR {
+		t.Errorf("Expected the defaults, got %v %v", charset, lineEnding)
+	}
+}
@@ -0,0 +1,186 @@
+package tui
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/anicolao/dikuclient/internal/mapper"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// newSessionTestModel creates a model with a second session opened via /connect
+func newSessionTestModel(t *testing.T) *Model {
+	t.Setenv("DIKUCLIENT_CONFIG_DIR", t.TempDir())
+
+	m := &Model{
+		host:         "first.example.com",
+		port:         4000,
+		connected:    true,
+		output:       []string{"Welcome to the first MUD", "> "},
+		currentInput: "kill orc",
+		cursorPos:    8,
+		worldMap:     mapper.NewMap(),
+		xpTracking:   make(map[string]*XPStat),
+		inventory:    []string{"a long sword"},
+	}
+
+	cmd := m.handleClientCommand("/connect second.example.com 5000")
+	if cmd == nil {
+		t.Fatal("Expected /connect to return a command")
+	}
+	if len(m.sessions) != 2 {
+		t.Fatalf("Expected 2 sessions, got %d", len(m.sessions))
+	}
+	if m.activeSession != 0 {
+		t.Fatal("Expected the switch to wait until the command finishes")
+	}
+
+	m.Update(switchSessionMsg(1))
+	return m
+}
+
+func TestConnectOpensAndSwitchesToNewSession(t *testing.T) {
+	m := newSessionTestModel(t)
+
+	if m.activeSession != 1 {
+		t.Fatalf("Expected session 2 to be active, got %d", m.activeSession+1)
+	}
+	if m.host != "second.example.com" || m.port != 5000 {
+		t.Errorf("Expected active host second.example.com:5000, got %s:%d", m.host, m.port)
+	}
+	if m.connected {
+		t.Error("New session should not be connected until the connection arrives")
+	}
+	if m.currentInput != "" {
+		t.Errorf("New session should start with an empty input, got %q", m.currentInput)
+	}
+	if len(m.inventory) != 0 {
+		t.Errorf("New session should start with an empty inventory, got %v", m.inventory)
+	}
+	if m.worldMap == nil {
+		t.Error("New session should have its own map")
+	}
+	if !strings.Contains(strings.Join(m.output, "\n"), "connecting to second.example.com:5000") {
+		t.Errorf("Expected connecting message in new session, got %v", m.output)
+	}
+}
+
+func TestSwitchSessionPreservesBuffers(t *testing.T) {
+	m := newSessionTestModel(t)
+
+	// Give the second session some state of its own
+	m.output = append(m.output, "Welcome to the second MUD", "> ")
+	m.currentInput = "look"
+	m.cursorPos = 4
+
+	m.switchSession(0)
+
+	if m.host != "first.example.com" || !m.connected {
+		t.Errorf("Expected connected first session, got %s:%d (connected=%v)", m.host, m.port, m.connected)
+	}
+	output := strings.Join(m.output, "\n")
+	if !strings.Contains(output, "Welcome to the first MUD") {
+		t.Errorf("Expected first session's buffer, got %v", m.output)
+	}
+	if strings.Contains(output, "Welcome to the second MUD") {
+		t.Error("First session's buffer should not contain the second session's output")
+	}
+	if m.currentInput != "kill orc" || m.cursorPos != 8 {
+		t.Errorf("Expected first session's input 'kill orc' at 8, got %q at %d", m.currentInput, m.cursorPos)
+	}
+	if len(m.inventory) != 1 || m.inventory[0] != "a long sword" {
+		t.Errorf("Expected first session's inventory, got %v", m.inventory)
+	}
+
+	m.switchSession(1)
+
+	output = strings.Join(m.output, "\n")
+	if !strings.Contains(output, "Welcome to the second MUD") {
+		t.Errorf("Expected second session's buffer, got %v", m.output)
+	}
+	if strings.Contains(output, "Welcome to the first MUD") {
+		t.Error("Second session's buffer should not contain the first session's output")
+	}
+	if m.currentInput != "look" || m.cursorPos != 4 {
+		t.Errorf("Expected second session's input 'look' at 4, got %q at %d", m.currentInput, m.cursorPos)
+	}
+}
+
+func TestBackgroundSessionReceivesOutput(t *testing.T) {
+	m := newSessionTestModel(t)
+	m.switchSession(0)
+
+	m.Update(sessionMsg{session: 1, msg: mudMsg("A goblin arrives.\n")})
+
+	if strings.Contains(strings.Join(m.output, "\n"), "goblin") {
+		t.Error("Output for a background session should not appear in the active session")
+	}
+	if !strings.Contains(strings.Join(m.sessions[1].output, "\n"), "A goblin arrives.") {
+		t.Errorf("Expected output in background session, got %v", m.sessions[1].output)
+	}
+	if !m.sessions[1].activity {
+		t.Error("Expected background session to be marked as having new output")
+	}
+	if m.host != "first.example.com" {
+		t.Errorf("Active session should be unchanged, got %s", m.host)
+	}
+
+	m.switchSession(1)
+	if m.sessions[1].activity {
+		t.Error("Switching to a session should clear its activity marker")
+	}
+}
+
+func TestUntaggedMessagesGoToFirstSession(t *testing.T) {
+	m := newSessionTestModel(t)
+
+	// The first session's listener was started before sessions existed, so
+	// its messages arrive untagged even while another session is active
+	m.Update(mudMsg("The first MUD says hello.\n"))
+
+	if strings.Contains(strings.Join(m.output, "\n"), "hello") {
+		t.Error("Untagged output should not appear in the second session")
+	}
+	if !strings.Contains(strings.Join(m.sessions[0].output, "\n"), "The first MUD says hello.") {
+		t.Errorf("Expected untagged output in first session, got %v", m.sessions[0].output)
+	}
+}
+
+func TestClosedSessionDoesNotQuit(t *testing.T) {
+	m := newSessionTestModel(t)
+
+	_, cmd := m.Update(sessionMsg{session: 1, msg: errMsg(errors.New("connection refused"))})
+	if cmd != nil {
+		if _, ok := cmd().(tea.QuitMsg); ok {
+			t.Fatal("Closing one of several sessions should not quit the client")
+		}
+	}
+	if m.connected {
+		t.Error("Expected closed session to be marked disconnected")
+	}
+	if !strings.Contains(strings.Join(m.output, "\n"), "connection refused") {
+		t.Errorf("Expected error in closed session's output, got %v", m.output)
+	}
+}
+
+func TestTagSessionCmd(t *testing.T) {
+	if cmd := tagSessionCmd(0, func() tea.Msg { return tickTimerMsg{} }); cmd == nil {
+		t.Fatal("Expected command for first session")
+	} else if _, ok := cmd().(tickTimerMsg); !ok {
+		t.Error("First session's messages should stay untagged")
+	}
+
+	msg := tagSessionCmd(2, func() tea.Msg { return commandQueueTickMsg{} })()
+	tagged, ok := msg.(sessionMsg)
+	if !ok || tagged.session != 2 {
+		t.Fatalf("Expected message tagged with session 2, got %#v", msg)
+	}
+	if _, ok := tagged.msg.(commandQueueTickMsg); !ok {
+		t.Errorf("Expected wrapped commandQueueTickMsg, got %#v", tagged.msg)
+	}
+
+	if _, ok := tagSessionCmd(2, tea.Quit)().(tea.QuitMsg); !ok {
+		t.Error("tea.Quit should pass through untagged")
+	}
+}
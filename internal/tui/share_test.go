@@ -4,6 +4,9 @@ import (
 	"os"
 	"strings"
 	"testing"
+
+	"github.com/anicolao/dikuclient/internal/ansi"
+	"github.com/anicolao/dikuclient/internal/qrcode"
 )
 
 // TestShareCommandInWebMode tests that the /share command works in web mode
@@ -28,28 +31,20 @@ func TestShareCommandInWebMode(t *testing.T) {
 	}
 
 	// Execute the /share command
-	model.handleShareCommand()
+	model.handleShareCommand(nil)
 
-	// Check that the output contains the expected URL
-	found := false
-	expectedURL := "http://localhost:8080/?id=test-session-123"
+	// The screen is streamed to spectators, so the link that gives
+	// control must not be printed on it
 	for _, line := range model.output {
-		// Strip ANSI codes for easier checking
-		cleanLine := stripANSI(line)
-		if strings.Contains(cleanLine, expectedURL) {
-			found = true
-			break
+		if strings.Contains(line, "test-session-123") {
+			t.Errorf("Expected the session ID kept out of the shared output, got: %q", line)
 		}
 	}
 
-	if !found {
-		t.Errorf("Expected output to contain '%s', got: %v", expectedURL, model.output)
-	}
-
 	// Check that the output contains the expected header
 	foundHeader := false
 	for _, line := range model.output {
-		cleanLine := stripANSI(line)
+		cleanLine := ansi.Strip(line)
 		if strings.Contains(cleanLine, "Share This Session") {
 			foundHeader = true
 			break
@@ -61,6 +56,31 @@ func TestShareCommandInWebMode(t *testing.T) {
 	}
 }
 
+// TestShareCommandShowsQRCode tests that /share view renders a QR code of the URL
+func TestShareCommandShowsQRCode(t *testing.T) {
+	t.Setenv("DIKUCLIENT_WEB_SESSION_ID", "test-session-123")
+	t.Setenv("DIKUCLIENT_WEB_SERVER_URL", "http://localhost:8080")
+	t.Setenv("DIKUCLIENT_WEB_VIEW_ID", "view-456")
+
+	model := NewModel("localhost", 4000, nil, nil)
+	model.handleShareCommand([]string{"view"})
+
+	code, err := qrcode.Encode("http://localhost:8080/?view=view-456")
+	if err != nil {
+		t.Fatalf("Failed to encode share URL: %v", err)
+	}
+	expected := code.Render(2)
+
+	output := make([]string, len(model.output))
+	for i, line := range model.output {
+		output[i] = ansi.Strip(line)
+	}
+	joined := strings.Join(output, "\n")
+	if !strings.Contains(joined, strings.Join(expected, "\n")) {
+		t.Errorf("Expected output to contain the QR code for the share URL, got:\n%s", joined)
+	}
+}
+
 // TestShareCommandNotInWebMode tests that the /share command shows an error when not in web mode
 func TestShareCommandNotInWebMode(t *testing.T) {
 	// Make sure environment variables are not set
@@ -81,12 +101,12 @@ func TestShareCommandNotInWebMode(t *testing.T) {
 	}
 
 	// Execute the /share command
-	model.handleShareCommand()
+	model.handleShareCommand(nil)
 
 	// Check that the output contains an error message
 	found := false
 	for _, line := range model.output {
-		cleanLine := stripANSI(line)
+		cleanLine := ansi.Strip(line)
 		if strings.Contains(cleanLine, "only available in web mode") {
 			found = true
 			break
@@ -110,7 +130,7 @@ func TestHelpCommandIncludesShare(t *testing.T) {
 	// Check that the output contains the /share command
 	found := false
 	for _, line := range model.output {
-		cleanLine := stripANSI(line)
+		cleanLine := ansi.Strip(line)
 		if strings.Contains(cleanLine, "/share") {
 			found = true
 			break
@@ -121,3 +141,43 @@ func TestHelpCommandIncludesShare(t *testing.T) {
 		t.Error("Expected /help output to include /share command")
 	}
 }
+
+// TestShareCommandLinksURL verifies the /share view URL is an OSC 8 hyperlink
+func TestShareCommandLinksURL(t *testing.T) {
+	t.Setenv("DIKUCLIENT_WEB_SESSION_ID", "test-session-123")
+	t.Setenv("DIKUCLIENT_WEB_SERVER_URL", "http://localhost:8080")
+	t.Setenv("DIKUCLIENT_WEB_VIEW_ID", "view-456")
+
+	model := NewModel("localhost", 4000, nil, nil)
+	model.handleShareCommand([]string{"view"})
+
+	link := "\x1b]8;;http://localhost:8080/?view=view-456\x1b\\"
+	if !strings.Contains(strings.Join(model.output, "\n"), link) {
+		t.Errorf("Expected the share URL wrapped in a hyperlink, got: %q", model.output)
+	}
+}
+
+// TestShareViewGivesSpectatorURL tests that /share view links by view ID,
+// leaving the session ID that gives control out of the URL
+func TestShareViewGivesSpectatorURL(t *testing.T) {
+	t.Setenv("DIKUCLIENT_WEB_SESSION_ID", "test-session-123")
+	t.Setenv("DIKUCLIENT_WEB_SERVER_URL", "http://localhost:8080")
+	t.Setenv("DIKUCLIENT_WEB_VIEW_ID", "view-456")
+
+	model := NewModel("localhost", 4000, nil, nil)
+	model.handleShareCommand([]string{"view"})
+
+	joined := ansi.Strip(strings.Join(model.output, "\n"))
+	if !strings.Contains(joined, "http://localhost:8080/?view=view-456") {
+		t.Errorf("Expected the spectator URL, got:\n%s", joined)
+	}
+	if strings.Contains(joined, "test-session-123") {
+		t.Errorf("Expected the session ID kept out of a spectator link, got:\n%s", joined)
+	}
+
+	model.output = nil
+	model.handleShareCommand([]string{"edit"})
+	if len(model.output) != 1 || !strings.Contains(model.output[0], "Usage: /share [view]") {
+		t.Errorf("Expected usage message, got %q", model.output)
+	}
+}
@@ -1,8 +1,11 @@
 package tui
 
 import (
+	"fmt"
+	"strings"
 	"testing"
 
+	"github.com/anicolao/dikuclient/internal/settings"
 	tea "github.com/charmbracelet/bubbletea"
 )
 
@@ -224,3 +227,61 @@ func TestSplitViewportRendering(t *testing.T) {
 		t.Errorf("Split view seems too short: %d characters", len(view))
 	}
 }
+
+func TestSplitHeightsFillContent(t *testing.T) {
+	for _, ratio := range []int{settings.MinSplitRatio, 25, 50, settings.DefaultSplitRatio, settings.MaxSplitRatio} {
+		for height := 8; height <= 80; height++ {
+			top, bottom := splitHeights(height, ratio)
+			if top+bottom+1 != height {
+				t.Errorf("Ratio %d, height %d: %d+%d+1 doesn't fill the height", ratio, height, top, bottom)
+			}
+			if top < 2 || bottom < 3 {
+				t.Errorf("Ratio %d, height %d: panes %d and %d leave no room for output", ratio, height, top, bottom)
+			}
+		}
+	}
+	if top, bottom := splitHeights(41, 50); top != 20 || bottom != 20 {
+		t.Errorf("Expected an even split of 41 lines as 20 and 20, got %d and %d", top, bottom)
+	}
+}
+
+func TestSplitPinnedAndRatioAcrossResizes(t *testing.T) {
+	t.Setenv("DIKUCLIENT_CONFIG_DIR", t.TempDir())
+	m := NewModel("localhost", 4000, nil, nil)
+	model := &m
+	for i := 0; i < 100; i++ {
+		model.output = append(model.output, fmt.Sprintf("line %d", i))
+	}
+
+	for _, size := range []tea.WindowSizeMsg{{Width: 100, Height: 40}, {Width: 120, Height: 57}} {
+		model.Update(size)
+		model.handleSplitCommand([]string{"off"})
+		model.updateViewport()
+		model.View()
+		content := model.viewport.Height + 2 // Unsplit, the viewport has the height less its borders
+
+		model.handleSplitCommand([]string{"on"})
+		for _, ratio := range []string{"30", "50%", "80"} {
+			model.handleSplitCommand([]string{"ratio", ratio})
+			model.View()
+			top, bottom := splitHeights(content, model.splitRatio())
+			if model.viewport.Height != top-1 || model.splitViewport.Height != bottom-2 {
+				t.Errorf("Size %dx%d ratio %s: viewports %d and %d, want %d and %d",
+					size.Width, size.Height, ratio, model.viewport.Height, model.splitViewport.Height, top-1, bottom-2)
+			}
+			// Pinned without scrolling back, the top pane ends where the live pane starts
+			if got := model.viewport.YOffset + model.viewport.Height + model.splitViewport.Height; got != model.viewport.TotalLineCount() {
+				t.Errorf("Size %dx%d ratio %s: top pane ends at %d of %d lines", size.Width, size.Height, ratio, got, model.viewport.TotalLineCount())
+			}
+		}
+	}
+
+	if !model.settingsManager.SplitPinned || model.settingsManager.SplitRatio != 80 {
+		t.Errorf("Expected the split settings kept, got pinned %v ratio %d", model.settingsManager.SplitPinned, model.settingsManager.SplitRatio)
+	}
+	model.output = nil
+	model.handleSplitCommand([]string{"ratio", "half"})
+	if len(model.output) != 1 || !strings.Contains(model.output[0], "Invalid percentage") {
+		t.Errorf("Expected an error for a bad ratio, got %q", model.output)
+	}
+}
@@ -0,0 +1,49 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/anicolao/dikuclient/internal/ansi"
+)
+
+// TestStatsCountsMovesAndNewRooms verifies the odometer counts every move
+// sent, but only rooms not on the map yet as new
+func TestStatsCountsMovesAndNewRooms(t *testing.T) {
+	t.Setenv("DIKUCLIENT_CONFIG_DIR", t.TempDir())
+	m, _ := newConnectedTestModel(t)
+
+	walkTo := func(direction, title string) {
+		m.sendMovement(direction)
+		visitRoom(m, direction, title)
+	}
+	walkTo("north", "Road 1")
+	walkTo("north", "Road 2")
+	walkTo("south", "Road 1")
+	walkTo("north", "Road 2")
+	m.sendMovement("west") // Into a wall: no room follows
+
+	if m.odometer.Moves != 5 {
+		t.Errorf("Expected 5 moves, got %d", m.odometer.Moves)
+	}
+	if m.odometer.Rooms != 4 {
+		t.Errorf("Expected 4 rooms entered, got %d", m.odometer.Rooms)
+	}
+	if m.odometer.NewRooms != 2 {
+		t.Errorf("Expected only the first visit to each road to be new, got %d", m.odometer.NewRooms)
+	}
+
+	m.output = nil
+	m.handleClientCommand("/stats")
+	out := ansi.Strip(strings.Join(m.output, "\n"))
+	for _, want := range []string{"Moves: 5", "New rooms: 2", "2 new rooms in the last 5 moves (40%)", "Map: 2 rooms"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected /stats output to contain %q, got:\n%s", want, out)
+		}
+	}
+
+	m.handleClientCommand("/stats reset")
+	if m.odometer.Moves != 0 || m.odometer.NewRooms != 0 {
+		t.Errorf("Expected /stats reset to clear the counts, got %+v", m.odometer)
+	}
+}
@@ -0,0 +1,32 @@
+package tui
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+func TestStatusBarShowsClock(t *testing.T) {
+	m := &Model{width: 80}
+
+	status := m.renderStatusBar()
+	if !regexp.MustCompile(`\d\d:\d\d:\d\d`).MatchString(status) {
+		t.Errorf("Expected a clock in the status bar, got %q", status)
+	}
+	if got := lipgloss.Width(status); got != m.width {
+		t.Errorf("Expected the status bar to fill %d columns, got %d", m.width, got)
+	}
+
+	now := time.Date(2024, 1, 1, 9, 5, 7, 0, time.UTC)
+	if got := m.statusInfo(now); got != "09:05:07" {
+		t.Errorf("statusInfo() = %q, want the clock only while disconnected", got)
+	}
+
+	// Too narrow for the clock: it's left off rather than wrapping
+	m.width = 20
+	if got := lipgloss.Width(m.renderStatusBar()); got > m.width {
+		t.Errorf("Expected the status bar to fit in %d columns, got %d", m.width, got)
+	}
+}
@@ -0,0 +1,54 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/anicolao/dikuclient/internal/ansi"
+)
+
+func TestStopwatchCommand(t *testing.T) {
+	m := newHidePromptTestModel(t)
+	start := time.Date(2025, 1, 1, 9, 0, 0, 0, time.UTC)
+
+	m.handleStopwatchCommand([]string{"lap"}, start)
+	if len(m.output) != 1 || !strings.Contains(m.output[0], "isn't running") {
+		t.Errorf("Expected a lap before starting to be refused, got %q", m.output)
+	}
+
+	m.output = nil
+	m.handleStopwatchCommand([]string{"start"}, start)
+	m.handleStopwatchCommand([]string{"lap"}, start.Add(95*time.Second))
+	m.handleStopwatchCommand([]string{"lap"}, start.Add(2*time.Minute+5500*time.Millisecond))
+	if got := m.statusInfo(start.Add(3*time.Minute + 400*time.Millisecond)); got != "Stopwatch: 3:00  09:03:00" {
+		t.Errorf("statusInfo() = %q, want the running time before the clock", got)
+	}
+	m.handleStopwatchCommand([]string{"stop"}, start.Add(4*time.Minute))
+
+	expected := []string{
+		"Stopwatch started.",
+		"Lap 1: 1:35.0  (total 1:35.0)",
+		"Lap 2: 0:30.5  (total 2:05.5)",
+		"Stopwatch stopped at 4:00.0.",
+		"  Lap 1: 1:35.0",
+		"  Lap 2: 0:30.5  (fastest)",
+		"  Lap 3: 1:54.5",
+	}
+	got := make([]string, len(m.output))
+	for i, line := range m.output {
+		got[i] = ansi.Strip(line)
+	}
+	if strings.Join(got, "|") != strings.Join(expected, "|") {
+		t.Errorf("Expected output %q, got %q", expected, got)
+	}
+	if got := m.statusInfo(start.Add(5 * time.Minute)); got != "09:05:00" {
+		t.Errorf("statusInfo() = %q, want only the clock once stopped", got)
+	}
+
+	m.output = nil
+	m.handleStopwatchCommand(nil, start.Add(time.Hour))
+	if len(m.output) == 0 || ansi.Strip(m.output[0]) != "Stopwatch stopped: 4:00.0" {
+		t.Errorf("Expected the stopped total shown, got %q", m.output)
+	}
+}
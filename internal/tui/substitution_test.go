@@ -0,0 +1,79 @@
+package tui
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/anicolao/dikuclient/internal/aliases"
+	"github.com/anicolao/dikuclient/internal/substitutions"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestSubstitutionRewritesOutgoingCommands(t *testing.T) {
+	m, server := newConnectedTestModel(t)
+	m.aliasManager = aliases.NewManager()
+	substitutionManager, err := substitutions.LoadFromPath(filepath.Join(t.TempDir(), "substitutions.json"))
+	if err != nil {
+		t.Fatalf("Failed to load substitutions: %v", err)
+	}
+	m.substitutionManager = substitutionManager
+
+	m.handleSubCommand(`sub "'" "say "`)
+	m.handleSubCommand(`sub "gt " "group tell "`)
+	if len(m.substitutionManager.Substitutions) != 2 {
+		t.Fatalf("Expected two substitutions, got %q", m.output)
+	}
+
+	// Substitutions apply after alias expansion
+	m.aliasManager.Add("hi", "'hello <who>")
+
+	tests := []struct {
+		typed string
+		sent  string
+	}{
+		{"'hello there", "say hello there"},
+		{"gt ready?", "group tell ready?"},
+		{"look", "look"},
+		{"hi bob", "say hello bob"},
+	}
+	for _, tt := range tests {
+		m.currentInput = tt.typed
+		m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+		if sent := readSent(t, server); sent != tt.sent {
+			t.Errorf("Typed %q: expected %q to be sent, got %q", tt.typed, tt.sent, sent)
+		}
+	}
+
+	// Passwords go out as typed
+	m.output = append(m.output, "Password: ")
+	m.currentInput = "'secret"
+	m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if sent := readSent(t, server); sent != "'secret" {
+		t.Errorf("Expected the password to be sent unchanged, got %q", sent)
+	}
+}
+
+func TestSubsListAndRemove(t *testing.T) {
+	m := &Model{
+		output:              []string{},
+		substitutionManager: substitutions.NewManager(),
+	}
+	m.substitutionManager.Set("'", "say ")
+
+	m.handleSubsCommand([]string{"list"})
+	if !strings.Contains(strings.Join(m.output, "\n"), `1. "'" -> "say "`) {
+		t.Errorf("Expected the substitution to be listed, got %q", m.output)
+	}
+
+	m.handleSubsCommand([]string{"remove", "2"})
+	if len(m.substitutionManager.Substitutions) != 1 {
+		t.Error("Expected an invalid index not to remove anything")
+	}
+
+	m.substitutionManager.Substitutions = m.substitutionManager.Substitutions[:0]
+	m.handleSubsCommand(nil)
+	if !strings.Contains(m.output[len(m.output)-2], "No substitutions defined") {
+		t.Errorf("Expected an empty list message, got %q", m.output)
+	}
+}
@@ -0,0 +1,67 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/anicolao/dikuclient/internal/triggers"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// TestChatTabShowsTells verifies a tell shows in the Chat tab, and other
+// MUD output and client messages don't
+func TestChatTabShowsTells(t *testing.T) {
+	m, _ := newConnectedTestModel(t)
+	m.output = append(m.output, m.colors().Info.Render("[Mapper: room added]"))
+	m.Update(mudMsg("The Temple Square\nGandalf tells you 'Meet me at the inn.'\nA pigeon flies by.\n" + testPrompt))
+
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("2"), Alt: true})
+	if m.tabs.active != tabChat {
+		t.Fatalf("Expected Alt+2 to show the Chat tab, got %v", m.tabs.active)
+	}
+	shown := strings.Join(m.tabOutput(), "\n")
+	if !strings.Contains(shown, "Gandalf tells you 'Meet me at the inn.'") {
+		t.Errorf("Expected the tell in the Chat tab, got:\n%s", shown)
+	}
+	for _, other := range []string{"A pigeon flies by.", "The Temple Square", "[Mapper: room added]"} {
+		if strings.Contains(shown, other) {
+			t.Errorf("Expected %q left out of the Chat tab, got:\n%s", other, shown)
+		}
+	}
+
+	m.handleClientCommand("/tab game")
+	if shown := strings.Join(m.tabOutput(), "\n"); !strings.Contains(shown, "A pigeon flies by.") || !strings.Contains(shown, "Gandalf tells you") {
+		t.Errorf("Expected the Game tab to show everything, got:\n%s", shown)
+	}
+}
+
+// TestSystemTabShowsTriggerNotice verifies the notice of a trigger firing
+// shows in the System tab without the MUD line that fired it
+func TestSystemTabShowsTriggerNotice(t *testing.T) {
+	m, _ := newConnectedTestModel(t)
+	m.triggerManager = triggers.NewManager()
+	if _, err := m.triggerManager.Add("You are hungry", "eat bread"); err != nil {
+		t.Fatalf("Failed to add trigger: %v", err)
+	}
+
+	m.Update(mudMsg("You are hungry.\n" + testPrompt))
+	if bar := m.renderTabBar(); !strings.Contains(bar, "[Game]") || !strings.Contains(bar, "System (") {
+		t.Errorf("Expected the System tab to count the unseen notice, got %q", bar)
+	}
+
+	m.handleClientCommand("/tab system")
+	lines := m.tabOutput()
+	shown := strings.Join(lines, "\n")
+	if !strings.Contains(shown, "[Trigger: eat bread]") {
+		t.Errorf("Expected the trigger notice in the System tab, got:\n%s", shown)
+	}
+	if strings.Contains(shown, "You are hungry.") {
+		t.Errorf("Expected the MUD line left out of the System tab, got:\n%s", shown)
+	}
+	if lines[len(lines)-1] != m.output[len(m.output)-1] {
+		t.Errorf("Expected the tab to end with the prompt line, got %q", lines[len(lines)-1])
+	}
+	if bar := m.renderTabBar(); !strings.Contains(bar, "[System]") || strings.Contains(bar, "System (") {
+		t.Errorf("Expected the System tab shown and read, got %q", bar)
+	}
+}
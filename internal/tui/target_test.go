@@ -0,0 +1,108 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/anicolao/dikuclient/internal/aliases"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// TestTargetSubstitutedAndHighlighted verifies /target is sent for <target>
+// and its name is highlighted in the view only
+func TestTargetSubstitutedAndHighlighted(t *testing.T) {
+	m, server := newConnectedTestModel(t)
+	m.handleClientCommand("/target orc")
+	if m.target != "orc" {
+		t.Fatalf("Expected the target to be set, got %q", m.target)
+	}
+
+	m.enqueueCommands([]string{"kill <target>"})
+	m.Update(commandQueueTickMsg{})
+	if sent := readSent(t, server); sent != "kill orc" {
+		t.Errorf("Expected <target> to be replaced, got %q", sent)
+	}
+
+	m.output = []string{"The Orc swings at you.", "> "}
+	m.updateViewport()
+	if !strings.Contains(m.lastViewportContent, "The \x1b[1;4mOrc\x1b[0m swings") {
+		t.Errorf("Expected the target highlighted, got %q", m.lastViewportContent)
+	}
+	if m.output[0] != "The Orc swings at you." {
+		t.Errorf("Expected the output itself unchanged, got %q", m.output[0])
+	}
+}
+
+// TestTargetHighlightKeepsColors verifies a colored name is highlighted,
+// the line's attributes come back after it and escape codes are left alone
+func TestTargetHighlightKeepsColors(t *testing.T) {
+	m := &Model{}
+	m.setTarget("orc")
+	got := m.highlightTarget([]string{"\x1b[1mThe \x1b[31morc\x1b[0m\x1b[1m hits the orc.\x1b[0m"})
+	want := "\x1b[1mThe \x1b[31m\x1b[1;4morc\x1b[0m\x1b[1m\x1b[31m\x1b[0m\x1b[1m hits the \x1b[1;4morc\x1b[0m\x1b[1m.\x1b[0m"
+	if got[0] != want {
+		t.Errorf("Expected %q, got %q", want, got[0])
+	}
+
+	// A name that looks like part of an escape sequence is only found in
+	// the text
+	m.setTarget("31")
+	got = m.highlightTarget([]string{"\x1b[31mRoom 31\x1b[0m"})
+	if want := "\x1b[31mRoom \x1b[1;4m31\x1b[0m\x1b[31m\x1b[0m"; got[0] != want {
+		t.Errorf("Expected %q, got %q", want, got[0])
+	}
+
+	m.setTarget("")
+	if got := m.highlightTarget([]string{"orc"}); got[0] != "orc" {
+		t.Errorf("Expected no highlight without a target, got %q", got[0])
+	}
+}
+
+// TestTargetClearedOnDeath verifies the target is cleared when it dies and
+// taken from the combat prompt when none is set
+func TestTargetClearedOnDeath(t *testing.T) {
+	m, _ := newConnectedTestModel(t)
+	m.handleClientCommand("/target orc")
+
+	m.detectXPEvents("A goblin is dead!")
+	if m.target != "orc" {
+		t.Fatalf("Expected another death to keep the target, got %q", m.target)
+	}
+	m.detectXPEvents("The orc is dead!")
+	if m.target != "" {
+		t.Fatalf("Expected the target cleared on its death, got %q", m.target)
+	}
+
+	m.detectCombatPrompt("[Hero:Perfect] [troll:Wounded] <100hp 50m 80mv>")
+	if m.target != "troll" {
+		t.Errorf("Expected the combat prompt's target, got %q", m.target)
+	}
+}
+
+// TestTargetInAlias verifies an alias's <target> takes the /target when the
+// alias is given no argument for it
+func TestTargetInAlias(t *testing.T) {
+	m, server := newConnectedTestModel(t)
+	m.aliasManager = aliases.NewManager()
+	m.aliasManager.Add("k", "kill <target>")
+
+	send := func(input string) string {
+		m.currentInput = input
+		m.cursorPos = len(input)
+		m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+		return readSent(t, server)
+	}
+
+	m.handleClientCommand("/target orc")
+	if sent := send("k"); sent != "kill orc" {
+		t.Errorf("Expected the alias to use the target, got %q", sent)
+	}
+	if sent := send("k goblin"); sent != "kill goblin" {
+		t.Errorf("Expected an argument to win over the target, got %q", sent)
+	}
+
+	m.handleClientCommand("/target off")
+	if sent := send("k"); sent != "kill " {
+		t.Errorf("Expected <target> dropped without a target, got %q", sent)
+	}
+}
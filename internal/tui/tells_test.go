@@ -4,6 +4,7 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/anicolao/dikuclient/internal/ansi"
 	"github.com/anicolao/dikuclient/internal/mapper"
 )
 
@@ -182,7 +183,7 @@ func TestStripANSI(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := stripANSI(tt.input)
+			result := ansi.Strip(tt.input)
 			if result != tt.expected {
 				t.Errorf("Expected '%s', got '%s'", tt.expected, result)
 			}
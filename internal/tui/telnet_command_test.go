@@ -0,0 +1,79 @@
+package tui
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+// readRaw reads exactly n bytes sent by the client
+func readRaw(t *testing.T, server *bufio.Reader, n int) []byte {
+	t.Helper()
+	data := make(chan []byte, 1)
+	go func() {
+		buf := make([]byte, n)
+		io.ReadFull(server, buf)
+		data <- buf
+	}()
+	select {
+	case buf := <-data:
+		return buf
+	case <-time.After(2 * time.Second):
+		t.Fatal("Timed out waiting for client to send")
+		return nil
+	}
+}
+
+func TestTelnetCommandSendsIACSequences(t *testing.T) {
+	m, server := newConnectedTestModel(t)
+
+	tests := []struct {
+		args []string
+		want []byte
+	}{
+		{[]string{"do", "gmcp"}, []byte{0xFF, 0xFD, 0xC9}},
+		{[]string{"WILL", "naws"}, []byte{0xFF, 0xFB, 0x1F}},
+		{[]string{"wont", "24"}, []byte{0xFF, 0xFC, 0x18}},
+		{[]string{"dont", "msdp"}, []byte{0xFF, 0xFE, 0x45}},
+		{[]string{"sb", "naws", "0050", "0018"}, []byte{0xFF, 0xFA, 0x1F, 0x00, 0x50, 0x00, 0x18, 0xFF, 0xF0}},
+		{[]string{"sb", "201", "ff"}, []byte{0xFF, 0xFA, 0xC9, 0xFF, 0xFF, 0xFF, 0xF0}},
+	}
+	for _, tt := range tests {
+		m.handleTelnetCommand(tt.args)
+		if got := readRaw(t, server, len(tt.want)); !bytes.Equal(got, tt.want) {
+			t.Errorf("/telnet %s: sent % X, want % X", strings.Join(tt.args, " "), got, tt.want)
+		}
+	}
+
+	if last := m.output[len(m.output)-1]; !strings.Contains(last, "Sent IAC SB 201") || !strings.Contains(last, "ff fa c9 ff ff ff f0") {
+		t.Errorf("Expected the sent bytes to be shown, got %q", last)
+	}
+}
+
+func TestTelnetCommandRejectsBadArguments(t *testing.T) {
+	m := &Model{output: []string{}}
+
+	for _, args := range [][]string{
+		nil,
+		{"do"},
+		{"maybe", "gmcp"},
+		{"do", "bogus"},
+		{"do", "gmcp", "extra"},
+		{"sb", "gmcp"},
+		{"sb", "gmcp", "zz"},
+	} {
+		before := len(m.output)
+		m.handleTelnetCommand(args)
+		if len(m.output) != before+1 || strings.Contains(m.output[before], "Sent") {
+			t.Errorf("/telnet %v: expected one error line, got %q", args, m.output[before:])
+		}
+	}
+
+	m.handleTelnetCommand([]string{"do", "gmcp"})
+	if !strings.Contains(m.output[len(m.output)-1], "Not connected") {
+		t.Errorf("Expected an error without a connection, got %q", m.output[len(m.output)-1])
+	}
+}
@@ -0,0 +1,71 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/anicolao/dikuclient/internal/settings"
+)
+
+// TestThemeSetChangesMessageColor verifies /theme set is reflected in the
+// client's messages and saved
+func TestThemeSetChangesMessageColor(t *testing.T) {
+	t.Setenv("DIKUCLIENT_CONFIG_DIR", t.TempDir())
+	m := &Model{output: []string{}, settingsManager: settings.NewManager()}
+
+	m.handleClientCommand("/ansi bogus")
+	if last := m.output[len(m.output)-1]; !strings.HasPrefix(last, "\x1b[91m") {
+		t.Fatalf("Expected the default error color, got %q", last)
+	}
+
+	m.handleClientCommand("/theme set error 196")
+	m.handleClientCommand("/ansi bogus")
+	if last := m.output[len(m.output)-1]; last != "\x1b[38;5;196mUsage: /ansi [on|off]\x1b[0m" {
+		t.Errorf("Expected the error in color 196, got %q", last)
+	}
+
+	saved, err := settings.Load()
+	if err != nil {
+		t.Fatalf("Failed to load settings: %v", err)
+	}
+	if saved.Theme["error"] != "196" {
+		t.Errorf("Expected the color to be saved, got %v", saved.Theme)
+	}
+
+	m.handleClientCommand("/theme reset")
+	m.handleClientCommand("/ansi bogus")
+	if last := m.output[len(m.output)-1]; !strings.HasPrefix(last, "\x1b[91m") {
+		t.Errorf("Expected the default error color after reset, got %q", last)
+	}
+}
+
+// TestThemeInvalid verifies bad kinds and colors are rejected
+func TestThemeInvalid(t *testing.T) {
+	t.Setenv("DIKUCLIENT_CONFIG_DIR", t.TempDir())
+	m := &Model{output: []string{}, settingsManager: settings.NewManager()}
+
+	m.handleClientCommand("/theme set loud 196")
+	if last := m.output[len(m.output)-1]; !strings.Contains(last, "Unknown kind 'loud'") {
+		t.Errorf("Expected an unknown kind error, got %q", last)
+	}
+	m.handleClientCommand("/theme set error 300")
+	if last := m.output[len(m.output)-1]; !strings.Contains(last, "invalid color '300'") {
+		t.Errorf("Expected an invalid color error, got %q", last)
+	}
+	if len(m.settingsManager.Theme) != 0 {
+		t.Errorf("Expected no color to be set, got %v", m.settingsManager.Theme)
+	}
+}
+
+// TestThemePlainWhenAnsiOff verifies messages are uncolored with /ansi off
+func TestThemePlainWhenAnsiOff(t *testing.T) {
+	t.Setenv("DIKUCLIENT_CONFIG_DIR", t.TempDir())
+	m := &Model{output: []string{}, settingsManager: settings.NewManager()}
+
+	m.handleClientCommand("/theme set error 196")
+	m.handleClientCommand("/ansi off")
+	m.handleClientCommand("/ansi bogus")
+	if last := m.output[len(m.output)-1]; last != "Usage: /ansi [on|off]" {
+		t.Errorf("Expected a plain message, got %q", last)
+	}
+}
@@ -0,0 +1,47 @@
+package tui
+
+import (
+	"testing"
+	"time"
+
+	"github.com/anicolao/dikuclient/internal/aliases"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// TestThrottleSpacesTypedCommands tests that commands typed in a burst are
+// queued and sent at least the throttle interval apart
+func TestThrottleSpacesTypedCommands(t *testing.T) {
+	m, server := newConnectedTestModel(t)
+	m.aliasManager = aliases.NewManager()
+
+	m.handleThrottleCommand([]string{"60"})
+	if m.settingsManager.ThrottleMs != 60 {
+		t.Fatalf("Expected throttle of 60 ms to be saved, got %d", m.settingsManager.ThrottleMs)
+	}
+	if m.conn.SendInterval() != 60*time.Millisecond {
+		t.Fatalf("Expected connection send interval of 60ms, got %v", m.conn.SendInterval())
+	}
+
+	commands := []string{"look", "score", "inventory"}
+	for _, command := range commands {
+		m.currentInput = command
+		m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	}
+
+	var last time.Time
+	for i, expected := range commands {
+		if sent := readSent(t, server); sent != expected {
+			t.Errorf("Expected %q to be sent, got %q", expected, sent)
+		}
+		now := time.Now()
+		if i > 0 && now.Sub(last) < 50*time.Millisecond {
+			t.Errorf("Expected %q at least 60ms after the previous command, got %v", expected, now.Sub(last))
+		}
+		last = now
+	}
+
+	m.handleThrottleCommand([]string{"off"})
+	if m.settingsManager.ThrottleMs != 0 || m.conn.SendInterval() != 0 {
+		t.Errorf("Expected throttle to be off, got %d ms / %v", m.settingsManager.ThrottleMs, m.conn.SendInterval())
+	}
+}
@@ -0,0 +1,82 @@
+package tui
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/anicolao/dikuclient/internal/mapper"
+	"github.com/anicolao/dikuclient/internal/settings"
+)
+
+// TestTrailCommand verifies rooms entered are listed by /trail and that
+// /trail back queues the way back
+func TestTrailCommand(t *testing.T) {
+	t.Setenv("DIKUCLIENT_CONFIG_DIR", t.TempDir())
+	m := &Model{output: []string{}, worldMap: mapper.NewMap()}
+
+	visitRoom(m, "north", "Road 1")
+	visitRoom(m, "north", "Road 2")
+	visitRoom(m, "north", "Road 3")
+
+	m.output = nil
+	m.handleClientCommand("/trail")
+	out := strings.Join(m.output, "\n")
+	for _, want := range []string{"Trail (last 3 rooms)", "north Road 2", "north Road 3", "(here)"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected /trail output to contain %q, got:\n%s", want, out)
+		}
+	}
+	if strings.Index(out, "Road 1") > strings.Index(out, "Road 3") {
+		t.Errorf("Expected the trail oldest first, got:\n%s", out)
+	}
+
+	m.handleClientCommand("/trail back")
+	if !m.autoWalking {
+		t.Fatal("Expected /trail back to start an auto-walk")
+	}
+	if want := []string{"south", "south"}; !reflect.DeepEqual(m.pendingCommands, want) {
+		t.Errorf("Expected %v queued, got %v", want, m.pendingCommands)
+	}
+	if m.autoWalkTarget != "Road 1" {
+		t.Errorf("Expected to walk back to Road 1, got %q", m.autoWalkTarget)
+	}
+}
+
+// TestTrailBackSteps verifies /trail back n only retraces the last n steps
+func TestTrailBackSteps(t *testing.T) {
+	t.Setenv("DIKUCLIENT_CONFIG_DIR", t.TempDir())
+	m := &Model{output: []string{}, worldMap: mapper.NewMap()}
+
+	visitRoom(m, "north", "Road 1")
+	visitRoom(m, "north", "Road 2")
+	visitRoom(m, "north", "Road 3")
+
+	m.handleClientCommand("/trail back 1")
+	if want := []string{"south"}; !reflect.DeepEqual(m.pendingCommands, want) {
+		t.Errorf("Expected %v queued, got %v", want, m.pendingCommands)
+	}
+	if m.autoWalkTarget != "Road 2" {
+		t.Errorf("Expected to walk back to Road 2, got %q", m.autoWalkTarget)
+	}
+}
+
+// TestTrailPanel verifies the trail panel is shown in the sidebar when on
+func TestTrailPanel(t *testing.T) {
+	t.Setenv("DIKUCLIENT_CONFIG_DIR", t.TempDir())
+	m := &Model{output: []string{}, worldMap: mapper.NewMap(), settingsManager: settings.NewManager()}
+
+	visitRoom(m, "north", "Road 1")
+	visitRoom(m, "north", "Road 2")
+
+	if strings.Contains(m.renderSidebar(40, 60), "Trail") {
+		t.Error("Expected no trail panel while it is off")
+	}
+	m.handleClientCommand("/trail panel on")
+	if !m.settingsManager.TrailPanel {
+		t.Fatal("Expected /trail panel on to turn the panel on")
+	}
+	if !strings.Contains(m.renderSidebar(40, 60), "Trail") {
+		t.Error("Expected the trail panel in the sidebar")
+	}
+}
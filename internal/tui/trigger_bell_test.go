@@ -0,0 +1,77 @@
+package tui
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/anicolao/dikuclient/internal/triggers"
+)
+
+// TestTriggerBell verifies a -bell trigger rings the bell when it fires, and
+// only once for a burst of matching lines
+func TestTriggerBell(t *testing.T) {
+	t.Setenv("DIKUCLIENT_CONFIG_DIR", t.TempDir())
+	m, _ := newConnectedTestModel(t)
+	m.xpTracking = make(map[string]*XPStat)
+	m.triggerManager = triggers.NewManager()
+	bell := &bytes.Buffer{}
+	m.bell = bell
+
+	m.handleTriggerCommand(`trigger -bell -glob "* tells you *" ""`)
+	if len(m.triggerManager.Triggers) != 1 || !m.triggerManager.Triggers[0].Bell {
+		t.Fatalf("Expected one bell trigger, got %+v", m.triggerManager.Triggers)
+	}
+	m.handleTriggersListCommand()
+	if !strings.Contains(m.output[len(m.output)-1], "[bell]") {
+		t.Errorf("Expected the bell in the list, got %q", m.output[len(m.output)-1])
+	}
+
+	m.Update(mudMsg("Bob tells you 'hi'\n"))
+	if bell.String() != "\a" {
+		t.Fatalf("Expected the bell to ring, got %q", bell.String())
+	}
+
+	m.Update(mudMsg("Bob tells you 'are you there?'\n"))
+	if bell.String() != "\a" {
+		t.Errorf("Expected no second bell so soon, got %q", bell.String())
+	}
+
+	m.Update(mudMsg("The goblin dies.\n"))
+	m.lastBell = m.lastBell.Add(-bellInterval)
+	m.Update(mudMsg("Alice tells you 'hello'\n"))
+	if bell.String() != "\a\a" {
+		t.Errorf("Expected the bell to ring again after the interval, got %q", bell.String())
+	}
+}
+
+// TestHighlightTriggerBell verifies a -bell trigger that highlights rings
+// the bell as well, within its cooldown
+func TestHighlightTriggerBell(t *testing.T) {
+	t.Setenv("DIKUCLIENT_CONFIG_DIR", t.TempDir())
+	m, _ := newConnectedTestModel(t)
+	m.xpTracking = make(map[string]*XPStat)
+	m.triggerManager = triggers.NewManager()
+	bell := &bytes.Buffer{}
+	m.bell = bell
+
+	m.handleTriggerCommand(`trigger -bell -cooldown 60 "Vecna" /highlight red`)
+	if len(m.triggerManager.Triggers) != 1 || !m.triggerManager.Triggers[0].Bell {
+		t.Fatalf("Expected one bell trigger, got %+v", m.triggerManager.Triggers)
+	}
+
+	m.Update(mudMsg("Vecna arrives from the north.\n"))
+	if bell.String() != "\a" {
+		t.Fatalf("Expected the highlight trigger to ring the bell, got %q", bell.String())
+	}
+	if !strings.Contains(strings.Join(m.output, "\n"), "\x1b[31mVecna") {
+		t.Errorf("Expected the name highlighted, got %q", m.output)
+	}
+
+	// Past the bell's interval but within the trigger's cooldown
+	m.lastBell = m.lastBell.Add(-bellInterval)
+	m.Update(mudMsg("Vecna casts a spell.\n"))
+	if bell.String() != "\a" {
+		t.Errorf("Expected no bell within the cooldown, got %q", bell.String())
+	}
+}
@@ -0,0 +1,73 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/anicolao/dikuclient/internal/triggers"
+)
+
+// countTriggerFirings counts the [Trigger: ...] lines in the output
+func countTriggerFirings(m *Model) int {
+	count := 0
+	for _, line := range m.output {
+		if strings.Contains(line, "[Trigger: ") {
+			count++
+		}
+	}
+	return count
+}
+
+func TestTriggerCooldownCommand(t *testing.T) {
+	m, _ := newConnectedTestModel(t)
+	t.Setenv("DIKUCLIENT_CONFIG_DIR", t.TempDir())
+	m.xpTracking = make(map[string]*XPStat)
+	m.triggerManager = triggers.NewManager()
+
+	m.handleTriggerCommand(`trigger -cooldown 30 "You are bleeding" "cast heal"`)
+	if len(m.triggerManager.Triggers) != 1 || m.triggerManager.Triggers[0].Cooldown != 30*time.Second {
+		t.Fatalf("Expected one trigger with a 30s cooldown, got %+v", m.triggerManager.Triggers)
+	}
+
+	m.handleTriggersListCommand()
+	if !strings.Contains(m.output[len(m.output)-1], "[cooldown 30s]") {
+		t.Errorf("Expected the cooldown in the list, got %q", m.output[len(m.output)-1])
+	}
+
+	m.handleTriggerCommand(`trigger -glob -cooldown 5 "* hits you*" "flee"`)
+	if len(m.triggerManager.Triggers) != 2 || m.triggerManager.Triggers[1].Mode != triggers.ModeGlob || m.triggerManager.Triggers[1].Cooldown != 5*time.Second {
+		t.Errorf("Expected a glob trigger with a 5s cooldown, got %+v", m.triggerManager.Triggers[1])
+	}
+
+	m.output = []string{}
+	m.handleTriggerCommand(`trigger -cooldown soon "You are bleeding" "cast heal"`)
+	if len(m.triggerManager.Triggers) != 2 || !strings.Contains(strings.Join(m.output, "|"), "-cooldown needs a number") {
+		t.Errorf("Expected an invalid cooldown to be rejected, got %q", m.output)
+	}
+}
+
+func TestTriggerCooldownSuppressesRefiring(t *testing.T) {
+	m, _ := newConnectedTestModel(t)
+	m.xpTracking = make(map[string]*XPStat)
+	m.triggerManager = triggers.NewManager()
+	trigger, _ := m.triggerManager.Add("You are bleeding", "cast heal")
+	trigger.Cooldown = 50 * time.Millisecond
+
+	m.Update(mudMsg("You are bleeding.\n"))
+	if got := countTriggerFirings(m); got != 1 {
+		t.Fatalf("Expected the trigger to fire, got %d firings", got)
+	}
+
+	m.Update(mudMsg("You are bleeding.\n"))
+	if got := countTriggerFirings(m); got != 1 {
+		t.Errorf("Expected the trigger to be suppressed within its cooldown, got %d firings", got)
+	}
+
+	// Once the cooldown has elapsed the same action fires again
+	time.Sleep(60 * time.Millisecond)
+	m.Update(mudMsg("You are bleeding.\n"))
+	if got := countTriggerFirings(m); got != 2 {
+		t.Errorf("Expected the trigger to fire again after its cooldown, got %d firings", got)
+	}
+}
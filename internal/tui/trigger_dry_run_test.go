@@ -0,0 +1,48 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/anicolao/dikuclient/internal/aliases"
+	"github.com/anicolao/dikuclient/internal/triggers"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// TestTriggerTestCommandDoesNotSend tests that /trigger test reports matching
+// triggers and their captures without sending or queueing anything
+func TestTriggerTestCommandDoesNotSend(t *testing.T) {
+	m, server := newConnectedTestModel(t)
+	m.aliasManager = aliases.NewManager()
+	m.triggerManager = triggers.NewManager()
+	m.triggerManager.Add("hungry", "eat bread")
+	m.triggerManager.Add("<who> attacks you!", "kill <who>")
+
+	m.output = []string{}
+	m.handleClientCommand(`/trigger test "orc attacks you!"`)
+
+	output := strings.Join(m.output, "\n")
+	if !strings.Contains(output, `2. "<who> attacks you!" -> "kill <who>"`) ||
+		!strings.Contains(output, "<who> = orc") ||
+		!strings.Contains(output, "Would run: kill orc") {
+		t.Errorf("Expected match with capture and action, got %q", m.output)
+	}
+	if strings.Contains(output, "eat bread") {
+		t.Errorf("Expected only the matching trigger, got %q", m.output)
+	}
+	if len(m.pendingCommands) != 0 || m.commandQueueActive {
+		t.Errorf("Expected nothing queued, got %v", m.pendingCommands)
+	}
+
+	m.handleClientCommand(`/trigger test "You feel fine."`)
+	if !strings.Contains(m.output[len(m.output)-1], "No triggers match") {
+		t.Errorf("Expected no matches, got %q", m.output[len(m.output)-1])
+	}
+
+	// The first thing the server sees is the next real command
+	m.currentInput = "look"
+	m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if sent := readSent(t, server); sent != "look" {
+		t.Errorf("Expected dry run to send nothing, but server got %q", sent)
+	}
+}
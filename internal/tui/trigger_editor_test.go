@@ -0,0 +1,84 @@
+package tui
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/anicolao/dikuclient/internal/triggers"
+)
+
+func TestEditTriggersSavesOnClose(t *testing.T) {
+	triggersPath := filepath.Join(t.TempDir(), "triggers.json")
+	manager, err := triggers.LoadFromPath(triggersPath)
+	if err != nil {
+		t.Fatalf("Failed to load triggers: %v", err)
+	}
+	manager.Add("You are hungry.", "eat bread")
+	manager.Add("You are thirsty.", "drink water")
+	m := &Model{output: []string{}, triggerManager: manager, width: 80, height: 24}
+
+	m.handleClientCommand("/edit triggers")
+	if m.triggerEditor == nil {
+		t.Fatal("Expected /edit triggers to open the editor")
+	}
+	if view := m.View(); !strings.Contains(view, "Edit Triggers (2)") || !strings.Contains(view, "You are thirsty.") {
+		t.Errorf("Expected the editor to fill the screen, got %q", view)
+	}
+
+	// Move the second trigger to the top and switch it off
+	m.Update(tea.KeyMsg{Type: tea.KeyDown})
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("K")})
+	m.Update(tea.KeyMsg{Type: tea.KeySpace, Runes: []rune(" ")})
+	if manager.Triggers[0].Pattern != "You are hungry." {
+		t.Fatal("Expected the triggers to be unchanged until the editor closes")
+	}
+
+	m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if m.triggerEditor != nil {
+		t.Fatal("Expected Esc to close the editor")
+	}
+	if !strings.Contains(m.output[len(m.output)-1], "Saved 2 triggers.") {
+		t.Errorf("Expected the save to be reported, got %q", m.output)
+	}
+
+	saved, err := triggers.LoadFromPath(triggersPath)
+	if err != nil {
+		t.Fatalf("Failed to reload triggers: %v", err)
+	}
+	if saved.Triggers[0].Pattern != "You are thirsty." || !saved.Triggers[0].Disabled {
+		t.Errorf("Expected the saved triggers to be reordered and disabled, got %+v", saved.Triggers[0])
+	}
+	if results := saved.Test("You are thirsty."); len(results) != 0 {
+		t.Errorf("Expected the disabled trigger not to fire, got %+v", results)
+	}
+
+	m.handleTriggersListCommand()
+	if !strings.Contains(strings.Join(m.output, "\n"), "\"You are thirsty.\" -> \"drink water\"\x1b[0m \x1b[90m[off]") {
+		t.Errorf("Expected the list to show the trigger as disabled, got %q", m.output)
+	}
+}
+
+func TestEditTriggersDiscard(t *testing.T) {
+	manager := triggers.NewManager()
+	manager.Add("You are hungry.", "eat bread")
+	m := &Model{output: []string{}, triggerManager: manager, width: 80, height: 24}
+
+	m.handleClientCommand("/edit triggers")
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("d")})
+	m.Update(tea.KeyMsg{Type: tea.KeyCtrlC})
+
+	if m.triggerEditor != nil || len(manager.Triggers) != 1 {
+		t.Errorf("Expected Ctrl+C to close without deleting, got %d triggers", len(manager.Triggers))
+	}
+	if !strings.Contains(m.output[len(m.output)-1], "discarded") {
+		t.Errorf("Expected the discard to be reported, got %q", m.output)
+	}
+
+	m.handleClientCommand("/edit aliases")
+	if m.triggerEditor != nil || !strings.Contains(m.output[len(m.output)-1], "Usage: /edit triggers") {
+		t.Errorf("Expected usage for an unknown list, got %q", m.output)
+	}
+}
@@ -0,0 +1,68 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/anicolao/dikuclient/internal/triggers"
+)
+
+// TestTriggersEnableDisable tests turning a trigger off and on with
+// /triggers disable and /triggers enable, and the [on]/[off] list markers
+func TestTriggersEnableDisable(t *testing.T) {
+	m := newHidePromptTestModel(t)
+	t.Setenv("DIKUCLIENT_CONFIG_DIR", t.TempDir())
+	m.triggerManager = triggers.NewManager()
+	m.handleTriggerCommand(`trigger "hungry" "eat bread"`)
+	m.handleTriggerCommand(`trigger -group mage "thirsty" "drink water"`)
+
+	m.handleTriggersCommand([]string{"disable", "1"})
+	if last := m.output[len(m.output)-1]; !strings.Contains(last, `Disabled trigger 1: "hungry" -> "eat bread"`) {
+		t.Errorf("Expected a disable confirmation, got %q", last)
+	}
+	if results := m.triggerManager.Test("You are hungry."); len(results) != 0 {
+		t.Errorf("Expected the disabled trigger not to match, got %+v", results)
+	}
+
+	saved, err := triggers.Load()
+	if err != nil {
+		t.Fatalf("Failed to reload triggers: %v", err)
+	}
+	if !saved.Triggers[0].Disabled || saved.Triggers[1].Disabled {
+		t.Errorf("Expected the disabled trigger to be saved, got %+v", saved.Triggers)
+	}
+
+	m.triggerManager.SetGroupEnabled("mage", false)
+	m.output = []string{}
+	m.handleTriggersListCommand()
+	expected := []string{
+		m.colors().Info.Render("=== Active Triggers ==="),
+		"  " + m.colors().Highlight.Render(`1. "hungry" -> "eat bread"`) + " " + m.colors().Debug.Render("[off]"),
+		"  " + m.colors().Highlight.Render(`2. "thirsty" -> "drink water" [group mage]`) + " " + m.colors().Debug.Render("[off] (group mage disabled)"),
+	}
+	if strings.Join(m.output, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("Expected list %q, got %q", expected, m.output)
+	}
+
+	m.handleTriggersCommand([]string{"enable", "1"})
+	m.handleTriggersCommand([]string{"enable", "2"})
+	if last := m.output[len(m.output)-1]; !strings.Contains(last, "/group enable mage") {
+		t.Errorf("Expected a note about the disabled group, got %q", last)
+	}
+	m.triggerManager.SetGroupEnabled("mage", true)
+	m.output = []string{}
+	m.handleTriggersListCommand()
+	if strings.Count(strings.Join(m.output, "\n"), "[on]") != 2 {
+		t.Errorf("Expected both triggers on, got %q", m.output)
+	}
+	if results := m.triggerManager.Test("You are hungry."); len(results) != 1 {
+		t.Errorf("Expected the enabled trigger to match again, got %+v", results)
+	}
+
+	for _, args := range [][]string{{"disable"}, {"disable", "x"}, {"enable", "3"}} {
+		m.handleTriggersCommand(args)
+		if last := m.output[len(m.output)-1]; !strings.Contains(last, "Usage") && !strings.Contains(last, "Invalid") {
+			t.Errorf("Expected an error for %v, got %q", args, last)
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/anicolao/dikuclient/internal/triggers"
+)
+
+func newUserPanelTestModel(t *testing.T) *Model {
+	m, _ := newConnectedTestModel(t)
+	m.xpTracking = make(map[string]*XPStat)
+	m.triggerManager = triggers.NewManager()
+	m.triggerManager.AddGlob("Quest: *", "panel:Quest:<1>")
+	return m
+}
+
+func TestTriggerRoutesToPanel(t *testing.T) {
+	m := newUserPanelTestModel(t)
+
+	m.Update(mudMsg("Quest: 3 of 10 rats killed\n"))
+
+	if lines := m.userPanels["Quest"]; len(lines) != 1 || lines[0] != "3 of 10 rats killed" {
+		t.Fatalf("Expected the captured text in the Quest panel, got %q", m.userPanels)
+	}
+	if len(m.pendingCommands) != 0 {
+		t.Errorf("Expected nothing sent to the MUD, got %q", m.pendingCommands)
+	}
+	if strings.Contains(strings.Join(m.output, "\n"), "[Trigger: ") {
+		t.Errorf("Expected no trigger note in the main window, got %q", m.output)
+	}
+}
+
+func TestPanelActionWithCommands(t *testing.T) {
+	m := newUserPanelTestModel(t)
+	m.triggerManager.Add("<who> gives you a reward.", "panel:Quest:Reward from <who>;say thanks <who>")
+
+	m.Update(mudMsg("Mayor gives you a reward.\n"))
+
+	if lines := m.userPanels["Quest"]; len(lines) != 1 || lines[0] != "Reward from Mayor" {
+		t.Errorf("Expected the reward in the Quest panel, got %q", m.userPanels)
+	}
+	if len(m.pendingCommands) != 1 || m.pendingCommands[0] != "say thanks Mayor" {
+		t.Errorf("Expected only the say command to be queued, got %q", m.pendingCommands)
+	}
+}
+
+func TestUserPanelRendersNewestLines(t *testing.T) {
+	m := newUserPanelTestModel(t)
+	for i := 1; i <= userPanelMaxLines+5; i++ {
+		m.appendToUserPanel("Quest", fmt.Sprintf("step %d", i))
+	}
+	if lines := m.userPanels["Quest"]; len(lines) != userPanelMaxLines || lines[0] != "step 6" {
+		t.Fatalf("Expected the panel to keep the last %d lines, got %d starting %q", userPanelMaxLines, len(lines), lines[0])
+	}
+
+	sidebar := m.renderSidebar(40, 40)
+	if !strings.Contains(sidebar, "Quest") || !strings.Contains(sidebar, fmt.Sprintf("step %d", userPanelMaxLines+5)) {
+		t.Errorf("Expected the Quest panel with its newest line, got:\n%s", sidebar)
+	}
+	if strings.Contains(sidebar, "step 6 ") {
+		t.Errorf("Expected old lines to scroll out of the panel, got:\n%s", sidebar)
+	}
+
+	// The sidebar keeps its height with the extra panel
+	if got := strings.Count(sidebar, "\n") + 1; got != strings.Count(m.renderSidebarWithoutPanels(40, 40), "\n")+1 {
+		t.Errorf("Expected the sidebar height unchanged, got %d lines", got)
+	}
+}
+
+// renderSidebarWithoutPanels renders the sidebar as it looks before any
+// trigger fills a panel
+func (m *Model) renderSidebarWithoutPanels(width, height int) string {
+	panels := m.userPanels
+	m.userPanels = nil
+	defer func() { m.userPanels = panels }()
+	return m.renderSidebar(width, height)
+}
+
+func TestParsePanelAction(t *testing.T) {
+	tests := []struct {
+		action, name, text string
+		ok                 bool
+	}{
+		{"panel:Quest:3 rats left", "Quest", "3 rats left", true},
+		{"panel:Quest:time: 5 minutes", "Quest", "time: 5 minutes", true},
+		{"panel::no name", "", "", false},
+		{"panel:Quest", "", "", false},
+		{"say panel:Quest:hi", "", "", false},
+	}
+	for _, tt := range tests {
+		name, text, ok := parsePanelAction(tt.action)
+		if name != tt.name || text != tt.text || ok != tt.ok {
+			t.Errorf("parsePanelAction(%q) = %q, %q, %v, want %q, %q, %v", tt.action, name, text, ok, tt.name, tt.text, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/anicolao/dikuclient/internal/aliases"
+	"github.com/anicolao/dikuclient/internal/triggers"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// TestTriggerCaptureSetsVariable tests that a trigger can store a capture in a
+// variable that a later trigger substitutes into its command
+func TestTriggerCaptureSetsVariable(t *testing.T) {
+	m, server := newConnectedTestModel(t)
+	m.xpTracking = make(map[string]*XPStat)
+	m.triggerManager = triggers.NewManager()
+	m.triggerManager.Add("<who> attacks you!", "/set target <who>")
+	m.triggerManager.Add("You feel better.", "kill @target")
+
+	m.Update(mudMsg("orc attacks you!\n"))
+	if !m.commandQueueActive {
+		t.Fatal("Expected trigger action to be queued")
+	}
+	m.Update(commandQueueTickMsg{})
+
+	if m.variables["target"] != "orc" {
+		t.Fatalf("Expected @target to be 'orc', got %q", m.variables["target"])
+	}
+	if m.commandQueueActive {
+		t.Error("Expected queue to finish after running the client command")
+	}
+
+	m.Update(mudMsg("You feel better.\n"))
+	m.Update(commandQueueTickMsg{})
+
+	if sent := readSent(t, server); sent != "kill orc" {
+		t.Errorf("Expected 'kill orc' to be sent, got %q", sent)
+	}
+}
+
+// TestTypedCommandSubstitutesVariables tests that @name is replaced in typed
+// commands and that unknown variables are left alone
+func TestTypedCommandSubstitutesVariables(t *testing.T) {
+	m, server := newConnectedTestModel(t)
+	m.aliasManager = aliases.NewManager()
+	m.handleSetCommand([]string{"target", "goblin"})
+
+	m.currentInput = "kill @target"
+	m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if sent := readSent(t, server); sent != "kill goblin" {
+		t.Errorf("Expected 'kill goblin' to be sent, got %q", sent)
+	}
+
+	m.currentInput = "say mail me at bob@home"
+	m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if sent := readSent(t, server); sent != "say mail me at bob@home" {
+		t.Errorf("Expected unknown variable to be left alone, got %q", sent)
+	}
+}
+
+// TestAliasRunsSetCommand tests that an alias expanding to /set runs it as a
+// client command rather than sending it to the MUD
+func TestAliasRunsSetCommand(t *testing.T) {
+	m, _ := newConnectedTestModel(t)
+	m.aliasManager = aliases.NewManager()
+	m.aliasManager.Add("tgt", "/set target <name>")
+
+	m.currentInput = "tgt dragon"
+	m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+
+	if m.variables["target"] != "dragon" {
+		t.Errorf("Expected @target to be 'dragon', got %q", m.variables["target"])
+	}
+}
+
+func TestSetCommand(t *testing.T) {
+	m := &Model{output: []string{}}
+
+	m.handleSetCommand(nil)
+	if !strings.Contains(m.output[len(m.output)-1], "No variables set") {
+		t.Errorf("Expected empty list message, got %q", m.output[len(m.output)-1])
+	}
+
+	m.handleSetCommand([]string{"@weapon", "long", "sword"})
+	if m.variables["weapon"] != "long sword" {
+		t.Errorf("Expected @weapon to be 'long sword', got %q", m.variables["weapon"])
+	}
+
+	m.handleSetCommand([]string{"bad-name", "x"})
+	if !strings.Contains(m.output[len(m.output)-1], "Invalid variable name") {
+		t.Errorf("Expected invalid name error, got %q", m.output[len(m.output)-1])
+	}
+
+	if got := m.substituteVariables("wield @weapon"); got != "wield long sword" {
+		t.Errorf("Expected 'wield long sword', got %q", got)
+	}
+
+	m.handleUnsetCommand([]string{"weapon"})
+	if _, ok := m.variables["weapon"]; ok {
+		t.Error("Expected @weapon to be removed")
+	}
+	if got := m.substituteVariables("wield @weapon"); got != "wield @weapon" {
+		t.Errorf("Expected unset variable to be left alone, got %q", got)
+	}
+}
@@ -0,0 +1,24 @@
+package tui
+
+import (
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/anicolao/dikuclient/internal/version"
+)
+
+func TestVersionCommand(t *testing.T) {
+	defer func(v, c, d string) { version.Version, version.Commit, version.Date = v, c, d }(version.Version, version.Commit, version.Date)
+	version.Version, version.Commit, version.Date = "v1.2.0", "1a2b3c4", "2024-05-01T12:00:00Z"
+
+	m := &Model{output: []string{}}
+	m.handleClientCommand("/version")
+
+	out := strings.Join(m.output, "\n")
+	for _, want := range []string{"v1.2.0", "1a2b3c4", "2024-05-01T12:00:00Z", runtime.Version(), "Color profile:"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected %q in /version output, got %q", want, out)
+		}
+	}
+}
@@ -0,0 +1,160 @@
+package tui
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/anicolao/dikuclient/internal/mapper"
+	"github.com/anicolao/dikuclient/internal/settings"
+)
+
+// newWalkTestModel creates a model with a simple two-step map: Market -> Temple -> Altar
+func newWalkTestModel(t *testing.T, settingsPath string) *Model {
+	worldMap := mapper.NewMap()
+
+	market := mapper.NewRoom("Market Square", "A busy market.", []string{"north"})
+	temple := mapper.NewRoom("Temple", "A quiet temple.", []string{"south", "north"})
+	altar := mapper.NewRoom("Altar", "A stone altar.", []string{"south"})
+
+	worldMap.AddOrUpdateRoom(market)
+	worldMap.AddOrUpdateRoom(temple)
+	worldMap.AddOrUpdateRoom(altar)
+	worldMap.CurrentRoomID = market.ID
+
+	market.Exits["north"] = temple.ID
+	temple.Exits["south"] = market.ID
+	temple.Exits["north"] = altar.ID
+	altar.Exits["south"] = temple.ID
+
+	settingsManager, err := settings.LoadFromPath(settingsPath)
+	if err != nil {
+		t.Fatalf("Failed to load settings: %v", err)
+	}
+
+	return &Model{
+		output:          []string{},
+		connected:       true,
+		worldMap:        worldMap,
+		settingsManager: settingsManager,
+	}
+}
+
+// TestWalkIntervalDefault tests that auto-walk defaults to one step per second
+func TestWalkIntervalDefault(t *testing.T) {
+	m := &Model{}
+	if got := m.walkInterval(); got != time.Second {
+		t.Errorf("Expected default walk interval of 1s, got %v", got)
+	}
+}
+
+// TestWalkSpeedCommandSetsInterval tests that /walkspeed changes the walk interval and persists it
+func TestWalkSpeedCommandSetsInterval(t *testing.T) {
+	settingsPath := filepath.Join(t.TempDir(), "settings.json")
+	m := newWalkTestModel(t, settingsPath)
+
+	m.handleWalkSpeedCommand([]string{"250"})
+	if got := m.walkInterval(); got != 250*time.Millisecond {
+		t.Errorf("Expected walk interval of 250ms, got %v", got)
+	}
+
+	// Values below the minimum are clamped
+	m.handleWalkSpeedCommand([]string{"5"})
+	if got := m.walkInterval(); got != time.Duration(settings.MinWalkDelayMs)*time.Millisecond {
+		t.Errorf("Expected walk interval to be clamped to %dms, got %v", settings.MinWalkDelayMs, got)
+	}
+
+	// Setting should survive a reload
+	reloaded, err := settings.LoadFromPath(settingsPath)
+	if err != nil {
+		t.Fatalf("Failed to reload settings: %v", err)
+	}
+	if reloaded.GetWalkDelayMs() != settings.MinWalkDelayMs {
+		t.Errorf("Expected persisted walk delay %d, got %d", settings.MinWalkDelayMs, reloaded.GetWalkDelayMs())
+	}
+}
+
+// TestGoCommandRespectsWalkSpeed tests that the queue tick fires after the configured interval
+func TestGoCommandRespectsWalkSpeed(t *testing.T) {
+	m := newWalkTestModel(t, filepath.Join(t.TempDir(), "settings.json"))
+	m.handleWalkSpeedCommand([]string{"150"})
+
+	cmd := m.handleGoCommand([]string{"altar"})
+	if cmd == nil {
+		t.Fatal("Expected a tea.Cmd to start auto-walk")
+	}
+	if !m.autoWalking {
+		t.Fatal("Expected auto-walking to start")
+	}
+	if got := m.queueInterval(); got != 150*time.Millisecond {
+		t.Errorf("Expected queue interval of 150ms while walking, got %v", got)
+	}
+
+	start := time.Now()
+	msg := cmd()
+	elapsed := time.Since(start)
+
+	if _, ok := msg.(commandQueueTickMsg); !ok {
+		t.Fatalf("Expected commandQueueTickMsg, got %T", msg)
+	}
+	if elapsed < 150*time.Millisecond {
+		t.Errorf("Expected tick after at least 150ms, got %v", elapsed)
+	}
+	if elapsed >= time.Second {
+		t.Errorf("Expected tick well before the 1s default, got %v", elapsed)
+	}
+}
+
+// TestGoCommandSpeedOption tests that /go -speed overrides the interval for one walk
+func TestGoCommandSpeedOption(t *testing.T) {
+	m := newWalkTestModel(t, filepath.Join(t.TempDir(), "settings.json"))
+
+	cmd := m.handleGoCommand([]string{"-speed", "200", "altar"})
+	if cmd == nil {
+		t.Fatal("Expected a tea.Cmd to start auto-walk")
+	}
+	if got := m.walkInterval(); got != 200*time.Millisecond {
+		t.Errorf("Expected walk interval of 200ms, got %v", got)
+	}
+
+	// Stopping the walk restores the configured speed
+	m.handleStopCommand()
+	if got := m.walkInterval(); got != time.Second {
+		t.Errorf("Expected walk interval to return to 1s after /stop, got %v", got)
+	}
+
+	// Non-walk queue commands keep the one second spacing
+	if got := m.queueInterval(); got != time.Second {
+		t.Errorf("Expected queue interval of 1s when not walking, got %v", got)
+	}
+}
+
+// TestGoCommandFastMode tests that /go -fast sends the whole path at once
+func TestGoCommandFastMode(t *testing.T) {
+	m := newWalkTestModel(t, filepath.Join(t.TempDir(), "settings.json"))
+
+	cmd := m.handleGoCommand([]string{"-fast", "altar"})
+	if cmd != nil {
+		t.Error("Expected no queue tick for fast walk")
+	}
+	if m.autoWalking || len(m.pendingCommands) > 0 {
+		t.Error("Expected fast walk not to use the command queue")
+	}
+
+	current := m.worldMap.GetCurrentRoom()
+	if current == nil || current.Title != "Altar" {
+		t.Errorf("Expected current room to be Altar after fast walk, got %v", current)
+	}
+
+	foundWarning := false
+	for _, line := range m.output {
+		if strings.Contains(line, "Warning") {
+			foundWarning = true
+			break
+		}
+	}
+	if !foundWarning {
+		t.Error("Expected a warning about fast walk in output")
+	}
+}
@@ -0,0 +1,40 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/anicolao/dikuclient/internal/mapper"
+)
+
+func TestWealthTrackedFromOutput(t *testing.T) {
+	m := &Model{
+		output:     []string{},
+		worldMap:   mapper.NewMap(),
+		xpTracking: make(map[string]*XPStat),
+	}
+
+	m.handleWealthCommand(nil)
+	if !strings.Contains(m.output[len(m.output)-1], "No money seen yet") {
+		t.Fatalf("Expected no money to be reported, got %q", m.output)
+	}
+
+	m.Update(mudMsg("101H 132V 1000X 500C> "))
+	m.Update(mudMsg("You get 150 gold coins from the corpse of a goblin.\n101H 132V 1000X 650C> "))
+
+	if m.wealthTracker.Balance != 650 || m.wealthTracker.Earned != 150 {
+		t.Fatalf("Expected 650 gold carried and 150 earned, got %+v", m.wealthTracker)
+	}
+
+	m.output = []string{}
+	m.handleWealthCommand(nil)
+	out := strings.Join(m.output, "\n")
+	if !strings.Contains(out, "Carrying: \x1b[96m650 gold") || !strings.Contains(out, "+150 gold\x1b[0m (150 picked up)") {
+		t.Errorf("Expected the balance and session total, got %q", out)
+	}
+
+	m.handleWealthCommand([]string{"reset"})
+	if m.wealthTracker.HaveBalance || m.wealthTracker.Earned != 0 {
+		t.Errorf("Expected reset to start over, got %+v", m.wealthTracker)
+	}
+}
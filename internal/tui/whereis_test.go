@@ -0,0 +1,102 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/anicolao/dikuclient/internal/mapper"
+)
+
+func newWhereisTestModel() *Model {
+	worldMap := mapper.NewMap()
+	worldMap.AddOrUpdateRoom(mapper.NewRoom("Temple Square", "A large square.", []string{"north"}))
+	return &Model{
+		output:     []string{},
+		worldMap:   worldMap,
+		xpTracking: make(map[string]*XPStat),
+	}
+}
+
+func TestTellRecordsSenderAgainstCurrentRoom(t *testing.T) {
+	m := newWhereisTestModel()
+
+	m.detectAndParseTell("Gandalf tells you 'meet me at the gate'")
+
+	sighting, ok := m.sightings["gandalf"]
+	if !ok {
+		t.Fatal("Expected the tell to record where Gandalf was seen")
+	}
+	if sighting.RoomID != m.worldMap.CurrentRoomID || sighting.RoomTitle != "Temple Square" {
+		t.Errorf("Expected Gandalf to be seen in Temple Square, got %+v", sighting)
+	}
+
+	// Mobs with multi-word names aren't players
+	m.detectAndParseTell("The guildmaster tells you 'practice hard'")
+	if len(m.sightings) != 1 {
+		t.Errorf("Expected only Gandalf to be recorded, got %v", m.sightings)
+	}
+}
+
+func TestParseSighting(t *testing.T) {
+	tests := map[string]string{
+		"Gandalf says 'hello'":                    "Gandalf",
+		"Frodo has arrived.":                      "Frodo",
+		"Sam is standing here.":                   "Sam",
+		"Merry is resting here.":                  "Merry",
+		"Pippin is fighting a goblin here!":       "Pippin",
+		"A goblin is standing here.":              "",
+		"The guard says 'Move along'":             "",
+		"Someone has arrived.":                    "",
+		"You say 'hello'":                         "",
+		"Gandalf tells you 'not a room sighting'": "",
+	}
+	for line, expected := range tests {
+		if got := parseSighting(line); got != expected {
+			t.Errorf("parseSighting(%q) = %q, expected %q", line, got, expected)
+		}
+	}
+}
+
+func TestWhereisCommand(t *testing.T) {
+	m := newWhereisTestModel()
+	m.Update(mudMsg("Gandalf says 'well met'\n"))
+
+	m.handleWhereisCommand([]string{"gandalf"})
+	last := m.output[len(m.output)-1]
+	if !strings.Contains(last, "Gandalf was last seen in Temple Square (#1) just now.") {
+		t.Errorf("Expected where Gandalf was seen, got %q", last)
+	}
+
+	m.sightings["gandalf"].Time = time.Now().Add(-3 * time.Minute)
+	m.handleWhereisCommand([]string{"Gandalf"})
+	if last := m.output[len(m.output)-1]; !strings.Contains(last, "3 minutes ago") {
+		t.Errorf("Expected the time since the sighting, got %q", last)
+	}
+
+	m.handleWhereisCommand([]string{"Saruman"})
+	if last := m.output[len(m.output)-1]; !strings.Contains(last, "Haven't seen Saruman") {
+		t.Errorf("Expected an unknown player to be reported, got %q", last)
+	}
+
+	m.output = []string{}
+	m.handleWhereisCommand(nil)
+	if len(m.output) != 2 || !strings.Contains(m.output[1], "Gandalf") {
+		t.Errorf("Expected a list with Gandalf, got %q", m.output)
+	}
+}
+
+func TestFormatAgo(t *testing.T) {
+	tests := map[time.Duration]string{
+		5 * time.Second:  "just now",
+		45 * time.Second: "45 seconds ago",
+		time.Minute:      "1 minute ago",
+		3 * time.Minute:  "3 minutes ago",
+		2 * time.Hour:    "2 hours ago",
+	}
+	for d, expected := range tests {
+		if got := formatAgo(d); got != expected {
+			t.Errorf("formatAgo(%v) = %q, expected %q", d, got, expected)
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package tui
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/anicolao/dikuclient/internal/client"
+	"github.com/anicolao/dikuclient/internal/vitals"
+)
+
+// TestWimpyPrefersGMCP verifies GMCP hit points drive the flee decision
+// when the server sends them, even when the prompt disagrees
+func TestWimpyPrefersGMCP(t *testing.T) {
+	t.Setenv("DIKUCLIENT_CONFIG_DIR", t.TempDir())
+	m, server := newConnectedTestModel(t)
+	m.worldMap.SetPromptPattern(`^<\d+%hp`)
+
+	// Turning wimpy on asks for GMCP and MSDP vitals
+	m.handleClientCommand("/wimpy 25%")
+	var want []byte
+	want = append(want, client.EncodeNegotiation(client.DO, client.TELOPT_GMCP)...)
+	want = append(want, client.EncodeNegotiation(client.DO, client.TELOPT_MSDP)...)
+	want = append(want, client.EncodeSubnegotiation(client.TELOPT_MSDP, vitals.MSDPReport())...)
+	got := make([]byte, len(want))
+	if _, err := io.ReadFull(server, got); err != nil || !bytes.Equal(got, want) {
+		t.Fatalf("Expected the vitals request %q, got %q (%v)", want, got, err)
+	}
+	if m.settingsManager.Wimpy != "25%" {
+		t.Errorf("Expected the threshold saved in settings, got %q", m.settingsManager.Wimpy)
+	}
+
+	// GMCP at 20% flees although the prompt says 90%
+	m.Update(mudMsg("<90%hp 100%m 100%mv>\n"))
+	m.Update(gmcpMsg{Package: "Char.Vitals", JSON: []byte(`{"hp": "40", "maxhp": "200"}`)})
+	if sent := readSent(t, server); sent != "flee" {
+		t.Fatalf("Expected wimpy to flee, sent %q", sent)
+	}
+	if output := strings.Join(m.output, "\n"); !strings.Contains(output, "40/200 hp (20%, GMCP) is below 25%") {
+		t.Errorf("Expected the flee explained with its source, got:\n%s", output)
+	}
+
+	// It flees once, and a low prompt doesn't override a healthy GMCP reading
+	m.output = nil
+	m.Update(gmcpMsg{Package: "Char.Vitals", JSON: []byte(`{"hp": "30"}`)})
+	m.Update(gmcpMsg{Package: "Char.Vitals", JSON: []byte(`{"hp": "180"}`)})
+	m.Update(mudMsg("<10%hp 100%m 100%mv>\n"))
+	if strings.Contains(strings.Join(m.output, "\n"), "Wimpy") {
+		t.Errorf("Expected no second flee, got:\n%s", strings.Join(m.output, "\n"))
+	}
+
+	// Once recovered, a new drop flees again
+	m.Update(gmcpMsg{Package: "Char.Vitals", JSON: []byte(`{"hp": "20"}`)})
+	if sent := readSent(t, server); sent != "flee" {
+		t.Errorf("Expected wimpy to flee again after recovering, sent %q", sent)
+	}
+}
+
+// TestWimpyFallsBackToPromptPercent verifies the prompt percent drives the
+// flee decision when the server sends no GMCP or MSDP
+func TestWimpyFallsBackToPromptPercent(t *testing.T) {
+	m, server := newConnectedTestModel(t)
+	m.worldMap.SetPromptPattern(`^<\d+%hp`)
+	m.settingsManager.Wimpy = "25%"
+
+	m.Update(mudMsg("<80%hp 100%m 100%mv>\n"))
+	if m.wimpyFled {
+		t.Fatal("Expected no flee at 80%")
+	}
+
+	m.Update(mudMsg("The troll hits you very hard.\n<20%hp 100%m 100%mv>\n"))
+	if sent := readSent(t, server); sent != "flee" {
+		t.Fatalf("Expected wimpy to flee, sent %q", sent)
+	}
+	if output := strings.Join(m.output, "\n"); !strings.Contains(output, "20% hp (prompt) is below 25%") {
+		t.Errorf("Expected the flee explained with its source, got:\n%s", output)
+	}
+
+	m.output = nil
+	m.handleClientCommand("/wimpy off")
+	m.Update(mudMsg("<5%hp 100%m 100%mv>\n"))
+	if strings.Contains(strings.Join(m.output, "\n"), "[Wimpy") {
+		t.Errorf("Expected no flee with wimpy off, got:\n%s", strings.Join(m.output, "\n"))
+	}
+}
+
+// TestGMCPVitalsGauge verifies Char.Vitals shows a hit point bar in the
+// status bar, and that nothing is shown before the server sends them
+func TestGMCPVitalsGauge(t *testing.T) {
+	m := newHidePromptTestModel(t)
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	if info := m.statusInfo(now); strings.Contains(info, "HP") {
+		t.Errorf("Expected no gauge before GMCP vitals, got %q", info)
+	}
+
+	// A prompt alone doesn't fill the gauge
+	m.Update(mudMsg("<90%hp 100%m 100%mv>\n"))
+	if info := m.statusInfo(now); strings.Contains(info, "HP [") {
+		t.Errorf("Expected the gauge to wait for GMCP, got %q", info)
+	}
+
+	m.Update(gmcpMsg{Package: "Char.Vitals", JSON: []byte(`{"hp": 40, "maxhp": 100, "mp": 12}`)})
+	if info := m.statusInfo(now); info != "HP [████░░░░░░] 40/100  12:00:00" {
+		t.Errorf("Expected the hit point gauge before the clock, got %q", info)
+	}
+}
@@ -0,0 +1,75 @@
+// Package version holds the build information reported by /version and at
+// the top of --log-all logs. Release builds set it at link time:
+//
+//	go build -ldflags "-X github.com/anicolao/dikuclient/internal/version.Version=v1.2.0 \
+//	  -X github.com/anicolao/dikuclient/internal/version.Commit=$(git rev-parse --short HEAD) \
+//	  -X github.com/anicolao/dikuclient/internal/version.Date=$(date -u +%Y-%m-%dT%H:%M:%SZ)" ./cmd/dikuclient
+package version
+
+import (
+	"fmt"
+	"runtime"
+	"runtime/debug"
+)
+
+// Set with -ldflags "-X ..."; left empty, the commit and date come from the
+// version control information Go records in the binary, when there is any
+var (
+	Version = "dev"
+	Commit  = ""
+	Date    = ""
+)
+
+// Info is the build information of the running binary
+type Info struct {
+	Version   string
+	Commit    string // "unknown" when not recorded
+	Date      string // "unknown" when not recorded
+	GoVersion string
+}
+
+// Get returns the build information
+func Get() Info {
+	info := Info{
+		Version:   Version,
+		Commit:    Commit,
+		Date:      Date,
+		GoVersion: runtime.Version(),
+	}
+
+	if buildInfo, ok := debug.ReadBuildInfo(); ok {
+		for _, setting := range buildInfo.Settings {
+			switch setting.Key {
+			case "vcs.revision":
+				if info.Commit == "" {
+					info.Commit = setting.Value
+					if len(info.Commit) > 12 {
+						info.Commit = info.Commit[:12]
+					}
+				}
+			case "vcs.time":
+				if info.Date == "" {
+					info.Date = setting.Value
+				}
+			case "vcs.modified":
+				if setting.Value == "true" && Commit == "" && info.Commit != "" {
+					info.Commit += "-dirty"
+				}
+			}
+		}
+	}
+
+	if info.Commit == "" {
+		info.Commit = "unknown"
+	}
+	if info.Date == "" {
+		info.Date = "unknown"
+	}
+	return info
+}
+
+// String returns the build information on one line, e.g.
+// "dikuclient v1.2.0 (commit 1a2b3c4, built 2024-05-01T12:00:00Z, go1.24.0)"
+func (i Info) String() string {
+	return fmt.Sprintf("dikuclient %s (commit %s, built %s, %s)", i.Version, i.Commit, i.Date, i.GoVersion)
+}
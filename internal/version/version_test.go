@@ -0,0 +1,35 @@
+package version
+
+import (
+	"runtime"
+	"testing"
+)
+
+func TestGetUsesLinkedValues(t *testing.T) {
+	defer func(v, c, d string) { Version, Commit, Date = v, c, d }(Version, Commit, Date)
+	Version, Commit, Date = "v1.2.0", "1a2b3c4", "2024-05-01T12:00:00Z"
+
+	info := Get()
+	if info.Version != "v1.2.0" || info.Commit != "1a2b3c4" || info.Date != "2024-05-01T12:00:00Z" {
+		t.Errorf("Expected the linked values, got %+v", info)
+	}
+	if info.GoVersion != runtime.Version() {
+		t.Errorf("Expected Go version %s, got %s", runtime.Version(), info.GoVersion)
+	}
+
+	want := "dikuclient v1.2.0 (commit 1a2b3c4, built 2024-05-01T12:00:00Z, " + runtime.Version() + ")"
+	if info.String() != want {
+		t.Errorf("Expected %q, got %q", want, info.String())
+	}
+}
+
+func TestGetFillsUnknowns(t *testing.T) {
+	info := Get()
+	if info.Version != "dev" {
+		t.Errorf("Expected the default version 'dev', got %q", info.Version)
+	}
+	// Test binaries carry no version control information
+	if info.Commit == "" || info.Date == "" {
+		t.Errorf("Expected a commit and date (or 'unknown'), got %+v", info)
+	}
+}
@@ -0,0 +1,334 @@
+// Package vitals follows the character's hit points from the best source the
+// MUD offers: GMCP, then MSDP, then the prompt.
+package vitals
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Source is where a hit point reading came from
+type Source int
+
+const (
+	SourceNone   Source = iota // No reading yet
+	SourcePrompt               // Numbers or a percent in the prompt
+	SourceMSDP                 // MSDP HEALTH and HEALTH_MAX variables
+	SourceGMCP                 // GMCP Char.Vitals message
+)
+
+// Precedence lists the sources from most to least trusted. Structured data
+// comes before the prompt, which may be customised or only show a percent.
+var Precedence = []Source{SourceGMCP, SourceMSDP, SourcePrompt}
+
+// String returns the source's name as shown to the player
+func (s Source) String() string {
+	switch s {
+	case SourcePrompt:
+		return "prompt"
+	case SourceMSDP:
+		return "MSDP"
+	case SourceGMCP:
+		return "GMCP"
+	}
+	return "none"
+}
+
+// Reading is the character's hit points as one source reported them
+type Reading struct {
+	Source      Source
+	HP          int  // Current hit points, when HaveHP
+	HaveHP      bool // The source gave the current hit points
+	MaxHP       int  // Maximum hit points (0 = unknown)
+	Percent     int  // Current hit points as a percent of the maximum, when HavePercent
+	HavePercent bool // The source gave a percent (prompts such as <85%hp>)
+}
+
+// String describes the reading, such as "40/200 hp (20%, GMCP)"
+func (r Reading) String() string {
+	var hp string
+	switch {
+	case r.HaveHP && r.MaxHP > 0:
+		hp = fmt.Sprintf("%d/%d hp", r.HP, r.MaxHP)
+	case r.HaveHP:
+		hp = fmt.Sprintf("%d hp", r.HP)
+	}
+	if percent, ok := r.percent(0); ok {
+		if hp == "" {
+			return fmt.Sprintf("%d%% hp (%s)", percent, r.Source)
+		}
+		return fmt.Sprintf("%s (%d%%, %s)", hp, percent, r.Source)
+	}
+	return fmt.Sprintf("%s (%s)", hp, r.Source)
+}
+
+// hp returns the current hit points, working them out from a percent when
+// the maximum is known from this or another source
+func (r Reading) hp(knownMax int) (int, bool) {
+	if r.HaveHP {
+		return r.HP, true
+	}
+	maxHP := r.MaxHP
+	if maxHP == 0 {
+		maxHP = knownMax
+	}
+	if r.HavePercent && maxHP > 0 {
+		return r.Percent * maxHP / 100, true
+	}
+	return 0, false
+}
+
+// percent returns the current hit points as a percent of the maximum, which
+// may be known from another source
+func (r Reading) percent(knownMax int) (int, bool) {
+	if r.HavePercent {
+		return r.Percent, true
+	}
+	maxHP := r.MaxHP
+	if maxHP == 0 {
+		maxHP = knownMax
+	}
+	if r.HaveHP && maxHP > 0 {
+		return r.HP * 100 / maxHP, true
+	}
+	return 0, false
+}
+
+// Threshold is the hit points at which wimpy flees: a number of hit points,
+// or a percent of the maximum
+type Threshold struct {
+	Value   int
+	Percent bool
+}
+
+// ParseThreshold reads a threshold written as hit points ("50") or a
+// percent ("25%")
+func ParseThreshold(s string) (Threshold, error) {
+	s = strings.TrimSpace(s)
+	percent := strings.HasSuffix(s, "%")
+	value, err := strconv.Atoi(strings.TrimSpace(strings.TrimSuffix(s, "%")))
+	if err != nil || value <= 0 {
+		return Threshold{}, fmt.Errorf("invalid threshold %q (use hit points such as 50, or a percent such as 25%%)", s)
+	}
+	if percent && value >= 100 {
+		return Threshold{}, fmt.Errorf("invalid threshold %q (a percent must be below 100%%)", s)
+	}
+	return Threshold{Value: value, Percent: percent}, nil
+}
+
+// String returns the threshold as ParseThreshold reads it
+func (t Threshold) String() string {
+	if t.Percent {
+		return fmt.Sprintf("%d%%", t.Value)
+	}
+	return strconv.Itoa(t.Value)
+}
+
+// Tracker keeps the latest reading from each source
+type Tracker struct {
+	readings map[Source]Reading
+	maxHP    int // Latest maximum hit points from any source
+}
+
+// NewTracker creates a tracker with no readings
+func NewTracker() *Tracker {
+	return &Tracker{readings: make(map[Source]Reading)}
+}
+
+// Reading returns the latest reading from a source
+func (t *Tracker) Reading(source Source) (Reading, bool) {
+	r, ok := t.readings[source]
+	return r, ok
+}
+
+// Best returns the reading from the most trusted source that has one
+func (t *Tracker) Best() (Reading, bool) {
+	for _, source := range Precedence {
+		if r, ok := t.readings[source]; ok {
+			return r, true
+		}
+	}
+	return Reading{}, false
+}
+
+// Below reports whether the hit points are below the threshold, using the
+// most trusted source that can be compared with it. A percent threshold
+// needs the maximum, and a hit point threshold needs the current hit points
+// or a percent and the maximum; a source that can't tell is skipped, so
+// the prompt is used when GMCP and MSDP are absent or incomplete. ok is
+// false when no source can tell.
+func (t *Tracker) Below(threshold Threshold) (below bool, reading Reading, ok bool) {
+	for _, source := range Precedence {
+		r, have := t.readings[source]
+		if !have {
+			continue
+		}
+		if threshold.Percent {
+			if percent, known := r.percent(t.maxHP); known {
+				return percent < threshold.Value, r, true
+			}
+		} else if hp, known := r.hp(t.maxHP); known {
+			return hp < threshold.Value, r, true
+		}
+	}
+	return false, Reading{}, false
+}
+
+// update stores a reading, remembering its maximum for the other sources.
+// A maximum without the current hit points isn't a reading, but still
+// helps read a prompt that only shows a percent.
+func (t *Tracker) update(r Reading) bool {
+	if r.MaxHP > 0 {
+		t.maxHP = r.MaxHP
+	}
+	if !r.HaveHP && !r.HavePercent {
+		return false
+	}
+	t.readings[r.Source] = r
+	return true
+}
+
+// UpdateGMCP reads hit points from a GMCP message such as
+// Char.Vitals {"hp": "40", "maxhp": "200"}, returning false for messages
+// without them
+func (t *Tracker) UpdateGMCP(data []byte) bool {
+	name, payload, _ := strings.Cut(string(data), " ")
+	return t.UpdateGMCPMessage(name, []byte(payload))
+}
+
+// UpdateGMCPMessage is UpdateGMCP for a message already split into its
+// package name and JSON data
+func (t *Tracker) UpdateGMCPMessage(pkg string, payload []byte) bool {
+	if !strings.EqualFold(pkg, "Char.Vitals") {
+		return false
+	}
+	var fields map[string]any
+	if err := json.Unmarshal(payload, &fields); err != nil {
+		return false
+	}
+
+	r := t.readings[SourceGMCP]
+	r.Source = SourceGMCP
+	found := false
+	for key, value := range fields {
+		n, isNumber := jsonInt(value)
+		if !isNumber {
+			continue
+		}
+		switch strings.ToLower(key) {
+		case "hp", "health":
+			r.HP, r.HaveHP, found = n, true, true
+		case "maxhp", "max_hp", "maxhealth", "max_health":
+			r.MaxHP, found = n, true
+		}
+	}
+	return found && t.update(r)
+}
+
+// jsonInt reads a whole number that GMCP may send as a number or a string
+func jsonInt(value any) (int, bool) {
+	switch v := value.(type) {
+	case float64:
+		return int(v), true
+	case string:
+		n, err := strconv.Atoi(strings.TrimSpace(v))
+		return n, err == nil
+	}
+	return 0, false
+}
+
+// MSDP variable and value markers
+const (
+	msdpVar = 1
+	msdpVal = 2
+)
+
+// UpdateMSDP reads the HEALTH and HEALTH_MAX variables from MSDP data,
+// which servers may send together or one at a time, returning false when
+// neither is present
+func (t *Tracker) UpdateMSDP(data []byte) bool {
+	r := t.readings[SourceMSDP]
+	r.Source = SourceMSDP
+	found := false
+	for _, field := range bytes.Split(data, []byte{msdpVar})[1:] {
+		name, value, hasValue := bytes.Cut(field, []byte{msdpVal})
+		if !hasValue {
+			continue
+		}
+		n, err := strconv.Atoi(strings.TrimSpace(string(value)))
+		if err != nil {
+			continue
+		}
+		switch string(name) {
+		case "HEALTH":
+			r.HP, r.HaveHP, found = n, true, true
+		case "HEALTH_MAX":
+			r.MaxHP, found = n, true
+		}
+	}
+	return found && t.update(r)
+}
+
+// MSDPReport is the MSDP request for the server to send the health
+// variables whenever they change
+func MSDPReport() []byte {
+	return []byte("\x01REPORT\x02HEALTH\x02HEALTH_MAX")
+}
+
+// promptPatterns match the hit points in a prompt, in order of preference.
+// Each has an hp group and may have a max group; a percent group is used
+// when the prompt shows a percent instead.
+var promptPatterns = []*regexp.Regexp{
+	// <120/150hp>, 120/150 HP
+	regexp.MustCompile(`(?i)(?P<hp>-?\d+)/(?P<max>\d+)\s*hp\b`),
+	// [HP:50/100], hp 50/100
+	regexp.MustCompile(`(?i)\bhp:?\s*(?P<hp>-?\d+)/(?P<max>\d+)`),
+	// 101/120H 132V> (the stats prompt the client recognises by default)
+	regexp.MustCompile(`(?:^|[\s<\[])(?P<hp>-?\d+)/(?P<max>\d+)H\b`),
+	// <85%hp>, [HP:85%]
+	regexp.MustCompile(`(?i)(?P<percent>\d+)%\s*hp\b`),
+	regexp.MustCompile(`(?i)\bhp:?\s*(?P<percent>\d+)%`),
+	// <120hp 80m 95mv>, [HP:120]
+	regexp.MustCompile(`(?i)(?P<hp>-?\d+)\s*hp\b`),
+	regexp.MustCompile(`(?i)\bhp:?\s*(?P<hp>-?\d+)\b`),
+	// 101H 132V 1000X 50C>
+	regexp.MustCompile(`(?:^|[\s<\[])(?P<hp>-?\d+)H\b`),
+}
+
+// ParsePrompt reads the hit points shown in a prompt
+func ParsePrompt(prompt string) (Reading, bool) {
+	for _, pattern := range promptPatterns {
+		matches := pattern.FindStringSubmatch(prompt)
+		if matches == nil {
+			continue
+		}
+		r := Reading{Source: SourcePrompt}
+		for i, name := range pattern.SubexpNames() {
+			n, err := strconv.Atoi(matches[i])
+			if err != nil {
+				continue
+			}
+			switch name {
+			case "hp":
+				r.HP, r.HaveHP = n, true
+			case "max":
+				r.MaxHP = n
+			case "percent":
+				r.Percent, r.HavePercent = n, true
+			}
+		}
+		return r, true
+	}
+	return Reading{}, false
+}
+
+// UpdatePrompt reads the hit points from a prompt, returning false when it
+// doesn't show them
+func (t *Tracker) UpdatePrompt(prompt string) bool {
+	r, ok := ParsePrompt(prompt)
+	return ok && t.update(r)
+}
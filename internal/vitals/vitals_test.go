@@ -0,0 +1,133 @@
+package vitals
+
+import "testing"
+
+func TestParsePrompt(t *testing.T) {
+	tests := []struct {
+		prompt string
+		want   Reading
+		ok     bool
+	}{
+		{"<120/150hp 80/100m 95/95mv>", Reading{HP: 120, HaveHP: true, MaxHP: 150}, true},
+		{"[HP:50/100 MP:20/20]", Reading{HP: 50, HaveHP: true, MaxHP: 100}, true},
+		{"<85%hp 100%m 100%mv>", Reading{Percent: 85, HavePercent: true}, true},
+		{"[HP:40%]", Reading{Percent: 40, HavePercent: true}, true},
+		{"<120hp 80m 95mv>", Reading{HP: 120, HaveHP: true}, true},
+		{"<-3hp 0m 10mv>", Reading{HP: -3, HaveHP: true}, true},
+		{"101H 132V 1000X 50C T:24 Exits:NS>", Reading{HP: 101, HaveHP: true}, true},
+		{"101/120H 132/140V>", Reading{HP: 101, HaveHP: true, MaxHP: 120}, true},
+		{"> ", Reading{}, false},
+	}
+
+	for _, tt := range tests {
+		got, ok := ParsePrompt(tt.prompt)
+		if ok && tt.ok {
+			tt.want.Source = SourcePrompt
+		}
+		if ok != tt.ok || got != tt.want {
+			t.Errorf("ParsePrompt(%q) = %+v, %v, want %+v, %v", tt.prompt, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestParseThreshold(t *testing.T) {
+	for input, want := range map[string]Threshold{"50": {50, false}, "25%": {25, true}, " 30 % ": {30, true}} {
+		got, err := ParseThreshold(input)
+		if err != nil || got != want {
+			t.Errorf("ParseThreshold(%q) = %v, %v, want %v", input, got, err, want)
+		}
+	}
+	for _, input := range []string{"", "0", "-5", "100%", "lots"} {
+		if _, err := ParseThreshold(input); err == nil {
+			t.Errorf("ParseThreshold(%q) expected an error", input)
+		}
+	}
+}
+
+func TestGMCPPreferredOverPrompt(t *testing.T) {
+	tracker := NewTracker()
+	tracker.UpdatePrompt("<80%hp 100%m 100%mv>")
+	if !tracker.UpdateGMCP([]byte(`Char.Vitals {"hp": "40", "maxhp": "200", "mp": "10"}`)) {
+		t.Fatal("Expected Char.Vitals to give a reading")
+	}
+
+	// GMCP says 20% although the prompt says 80%
+	below, r, ok := tracker.Below(Threshold{Value: 25, Percent: true})
+	if !ok || !below || r.Source != SourceGMCP {
+		t.Errorf("Expected GMCP to decide, got below=%v source=%v ok=%v", below, r.Source, ok)
+	}
+	if best, _ := tracker.Best(); best.Source != SourceGMCP {
+		t.Errorf("Expected GMCP as the best source, got %v", best.Source)
+	}
+	if got := r.String(); got != "40/200 hp (20%, GMCP)" {
+		t.Errorf("Unexpected reading description %q", got)
+	}
+
+	// Later updates may carry only some of the fields
+	tracker.UpdateGMCP([]byte(`Char.Vitals {"hp": 190}`))
+	if below, r, _ := tracker.Below(Threshold{Value: 50}); below || r.HP != 190 || r.MaxHP != 200 {
+		t.Errorf("Expected the partial update merged, got %+v below=%v", r, below)
+	}
+
+	// Other GMCP messages are ignored
+	if tracker.UpdateGMCP([]byte(`Room.Info {"num": 3001}`)) {
+		t.Error("Expected Room.Info to be ignored")
+	}
+}
+
+func TestPromptUsedWithoutStructuredData(t *testing.T) {
+	tracker := NewTracker()
+	if _, _, ok := tracker.Below(Threshold{Value: 25, Percent: true}); ok {
+		t.Fatal("Expected no decision without any reading")
+	}
+
+	tracker.UpdatePrompt("<20%hp 100%m 100%mv>")
+	below, r, ok := tracker.Below(Threshold{Value: 25, Percent: true})
+	if !ok || !below || r.Source != SourcePrompt {
+		t.Errorf("Expected the prompt percent to decide, got below=%v source=%v ok=%v", below, r.Source, ok)
+	}
+
+	// A hit point threshold can't be compared with a percent until the
+	// maximum is known from somewhere
+	if _, _, ok := tracker.Below(Threshold{Value: 50}); ok {
+		t.Error("Expected no hit point decision from a percent alone")
+	}
+	tracker.UpdateMSDP([]byte("\x01HEALTH_MAX\x02200"))
+	if below, _, ok := tracker.Below(Threshold{Value: 50}); !ok || !below {
+		t.Errorf("Expected 20%% of 200 to be below 50 hp, got below=%v ok=%v", below, ok)
+	}
+}
+
+func TestIncompleteStructuredDataFallsBackToPrompt(t *testing.T) {
+	tracker := NewTracker()
+	tracker.UpdatePrompt("<30/100hp 10m 10mv>")
+	tracker.UpdateMSDP([]byte("\x01HEALTH\x0290"))
+
+	// MSDP has no maximum, so a percent threshold is read from the prompt
+	below, r, ok := tracker.Below(Threshold{Value: 50, Percent: true})
+	if !ok || r.Source != SourceMSDP || below {
+		t.Errorf("Expected MSDP with the prompt's maximum to decide, got below=%v source=%v ok=%v", below, r.Source, ok)
+	}
+
+	tracker = NewTracker()
+	tracker.UpdatePrompt("<30%hp>")
+	tracker.UpdateGMCP([]byte(`Char.Vitals {"hp": 300}`))
+	below, r, ok = tracker.Below(Threshold{Value: 50, Percent: true})
+	if !ok || r.Source != SourcePrompt || !below {
+		t.Errorf("Expected the prompt to decide when GMCP has no maximum, got below=%v source=%v ok=%v", below, r.Source, ok)
+	}
+}
+
+func TestUpdateMSDP(t *testing.T) {
+	tracker := NewTracker()
+	if !tracker.UpdateMSDP([]byte("\x01HEALTH\x0240\x01HEALTH_MAX\x02200\x01MANA\x0210")) {
+		t.Fatal("Expected HEALTH to give a reading")
+	}
+	r, _ := tracker.Reading(SourceMSDP)
+	if r.HP != 40 || r.MaxHP != 200 {
+		t.Errorf("Unexpected MSDP reading %+v", r)
+	}
+	if tracker.UpdateMSDP([]byte("\x01ROOM_NAME\x02Temple")) {
+		t.Error("Expected other variables to be ignored")
+	}
+}
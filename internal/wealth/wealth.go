@@ -0,0 +1,166 @@
+package wealth
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// PlatinumValue is what one platinum coin is worth in gold
+const PlatinumValue = 10
+
+// Tracker follows the character's money over a session, in gold
+type Tracker struct {
+	Start        time.Time // First line seen; rates are averaged from here
+	Earned       int       // Gold picked up according to the MUD's messages
+	Balance      int       // Gold carried, once known
+	StartBalance int       // Gold carried when the balance was first seen
+	HaveBalance  bool      // The balance has been read from the prompt or a message
+}
+
+// gainPatterns match messages about picking up or receiving money. Each has
+// an amount group and a coin group, which is empty for plain "coins".
+var gainPatterns = []*regexp.Regexp{
+	// You get 150 gold coins. / You get a gold coin from the corpse of a rat.
+	// You receive 50 gold. / You find 3 platinum coins.
+	regexp.MustCompile(`(?i)^you (?:get|receive|find|loot|collect|pick up) (?P<amount>\d[\d,]*|an?|one) (?:(?P<coin>platinum|gold)(?: coins?| pieces?)?|(?P<coin>)coins?)(?:[.!]|\s+(?:from|in|on)\b|$)`),
+	// CircleMUD after getting a pile of coins: There were 23 coins.
+	regexp.MustCompile(`(?i)^there (?:was|were) (?P<amount>\d[\d,]*|an?|one) (?P<coin>platinum|gold)? ?coins?\.`),
+}
+
+// balancePattern matches a full statement of the money carried, such as
+// "You have 3 platinum, 20 gold." or "You have 1500 gold coins."
+var balancePattern = regexp.MustCompile(`(?i)^you have ((?:\d[\d,]* (?:platinum|gold)(?: coins?)?(?:, and |, | and )?)+)\.?$`)
+
+// balancePart matches one amount in a balance statement
+var balancePart = regexp.MustCompile(`(?i)(\d[\d,]*) (platinum|gold)`)
+
+// promptCoinsPattern matches the coins field of a prompt, like 570C
+var promptCoinsPattern = regexp.MustCompile(`(?:^|\s)(\d+)C(?:\s|>|$)`)
+
+// NewTracker creates an empty wealth tracker
+func NewTracker() *Tracker {
+	return &Tracker{}
+}
+
+// ParseGain parses a message about getting money, returning the amount in
+// gold
+func ParseGain(line string) (int, bool) {
+	line = strings.TrimSpace(line)
+	for _, pattern := range gainPatterns {
+		matches := pattern.FindStringSubmatch(line)
+		if matches == nil {
+			continue
+		}
+
+		amount, ok := parseAmount(matches[pattern.SubexpIndex("amount")])
+		if !ok {
+			continue
+		}
+		coin := ""
+		for i, name := range pattern.SubexpNames() {
+			if name == "coin" && matches[i] != "" {
+				coin = matches[i]
+			}
+		}
+		return amount * coinValue(coin), true
+	}
+	return 0, false
+}
+
+// ParseBalance parses the money carried from a "You have ..." message, or
+// from the coins field when the line is a prompt
+func ParseBalance(line string, isPrompt bool) (int, bool) {
+	line = strings.TrimSpace(line)
+	if isPrompt {
+		matches := promptCoinsPattern.FindStringSubmatch(line)
+		if matches == nil {
+			return 0, false
+		}
+		return parseAmount(matches[1])
+	}
+
+	matches := balancePattern.FindStringSubmatch(line)
+	if matches == nil {
+		return 0, false
+	}
+	total := 0
+	for _, part := range balancePart.FindAllStringSubmatch(matches[1], -1) {
+		amount, _ := parseAmount(part[1])
+		total += amount * coinValue(part[2])
+	}
+	return total, true
+}
+
+// parseAmount reads an amount like "1,500", "a" or "one"
+func parseAmount(s string) (int, bool) {
+	switch strings.ToLower(s) {
+	case "a", "an", "one":
+		return 1, true
+	}
+	amount, err := strconv.Atoi(strings.ReplaceAll(s, ",", ""))
+	return amount, err == nil
+}
+
+// coinValue returns what one coin is worth in gold; plain coins are gold
+func coinValue(coin string) int {
+	if strings.EqualFold(coin, "platinum") {
+		return PlatinumValue
+	}
+	return 1
+}
+
+// ProcessLine feeds one line of MUD output (without colors) to the tracker.
+// It returns the gold gained when the line is a message about getting money.
+func (t *Tracker) ProcessLine(line string, isPrompt bool, now time.Time) int {
+	if t.Start.IsZero() {
+		t.Start = now
+	}
+
+	if balance, ok := ParseBalance(line, isPrompt); ok {
+		if !t.HaveBalance {
+			t.StartBalance = balance
+			t.HaveBalance = true
+		}
+		t.Balance = balance
+		return 0
+	}
+	if isPrompt {
+		return 0
+	}
+
+	gained, ok := ParseGain(line)
+	if !ok {
+		return 0
+	}
+	t.Earned += gained
+	// The next prompt or balance message corrects this if it's off
+	if t.HaveBalance {
+		t.Balance += gained
+	}
+	return gained
+}
+
+// Net returns the change in wealth over the session: the change in the
+// balance once it is known, so spending counts too, and the gold picked up
+// otherwise
+func (t *Tracker) Net() int {
+	if t.HaveBalance {
+		return t.Balance - t.StartBalance
+	}
+	return t.Earned
+}
+
+// PerHour returns the net change in wealth per hour since the tracker
+// started
+func (t *Tracker) PerHour(now time.Time) float64 {
+	if t.Start.IsZero() {
+		return 0
+	}
+	hours := now.Sub(t.Start).Hours()
+	if hours <= 0 {
+		return 0
+	}
+	return float64(t.Net()) / hours
+}
@@ -0,0 +1,102 @@
+package wealth
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseGain(t *testing.T) {
+	tests := []struct {
+		line string
+		gold int
+		ok   bool
+	}{
+		{"You get 150 gold coins.", 150, true},
+		{"You get 150 gold coins from the corpse of a goblin.", 150, true},
+		{"You get a gold coin.", 1, true},
+		{"You get 1,200 coins.", 1200, true},
+		{"You receive 50 gold.", 50, true},
+		{"You find 3 platinum coins.", 30, true},
+		{"There were 23 coins.", 23, true},
+		{"There was one coin.", 1, true},
+		{"You get a long sword.", 0, false},
+		{"You get 3 gold rings.", 0, false},
+		{"Bob gets 150 gold coins.", 0, false},
+	}
+
+	for _, tt := range tests {
+		gold, ok := ParseGain(tt.line)
+		if ok != tt.ok || gold != tt.gold {
+			t.Errorf("ParseGain(%q) = (%d, %v), want (%d, %v)", tt.line, gold, ok, tt.gold, tt.ok)
+		}
+	}
+}
+
+func TestParseBalance(t *testing.T) {
+	tests := []struct {
+		line     string
+		isPrompt bool
+		gold     int
+		ok       bool
+	}{
+		{"You have 3 platinum, 20 gold.", false, 50, true},
+		{"You have 1,500 gold coins.", false, 1500, true},
+		{"You have 2 platinum and 5 gold coins.", false, 25, true},
+		{"You have 3 gold rings.", false, 0, false},
+		{"101H 132V 1000X 570C T:24 Exits:NS>", true, 570, true},
+		{"101H 132V 1000X 570C T:24 Exits:NS>", false, 0, false},
+		{"101H 132V 1000X>", true, 0, false},
+	}
+
+	for _, tt := range tests {
+		gold, ok := ParseBalance(tt.line, tt.isPrompt)
+		if ok != tt.ok || gold != tt.gold {
+			t.Errorf("ParseBalance(%q, %v) = (%d, %v), want (%d, %v)", tt.line, tt.isPrompt, gold, ok, tt.gold, tt.ok)
+		}
+	}
+}
+
+func TestRunningTotalFromMessages(t *testing.T) {
+	tracker := NewTracker()
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tracker.ProcessLine("The goblin is dead!", false, start)
+	if gained := tracker.ProcessLine("You get 150 gold coins.", false, start.Add(10*time.Minute)); gained != 150 {
+		t.Errorf("Expected 150 gold gained, got %d", gained)
+	}
+	tracker.ProcessLine("There were 23 coins.", false, start.Add(20*time.Minute))
+	tracker.ProcessLine("You find 2 platinum coins.", false, start.Add(25*time.Minute))
+
+	if tracker.Earned != 193 || tracker.Net() != 193 {
+		t.Errorf("Expected 193 gold earned, got %d (net %d)", tracker.Earned, tracker.Net())
+	}
+	if rate := tracker.PerHour(start.Add(30 * time.Minute)); rate != 386 {
+		t.Errorf("Expected 386 gold per hour, got %.1f", rate)
+	}
+}
+
+func TestRunningTotalFromPrompt(t *testing.T) {
+	tracker := NewTracker()
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tracker.ProcessLine("101H 132V 1000X 500C>", true, start)
+	tracker.ProcessLine("You get 150 gold coins.", false, start.Add(time.Minute))
+	if tracker.Balance != 650 {
+		t.Errorf("Expected the gain added to the balance, got %d", tracker.Balance)
+	}
+
+	// The prompt is the authority, and spending counts against the total
+	tracker.ProcessLine("101H 132V 1000X 650C>", true, start.Add(time.Minute))
+	tracker.ProcessLine("You buy a bread for 30 gold coins.", false, start.Add(2*time.Minute))
+	tracker.ProcessLine("101H 132V 1000X 620C>", true, start.Add(2*time.Minute))
+
+	if tracker.Earned != 150 {
+		t.Errorf("Expected 150 gold earned, got %d", tracker.Earned)
+	}
+	if tracker.Net() != 120 {
+		t.Errorf("Expected a net change of 120, got %d", tracker.Net())
+	}
+	if rate := tracker.PerHour(start.Add(time.Hour)); rate != 120 {
+		t.Errorf("Expected 120 gold per hour, got %.1f", rate)
+	}
+}
@@ -0,0 +1,61 @@
+//go:build !windows
+// +build !windows
+
+package web
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+)
+
+// ClientBinaryEnv is the environment variable that sets the dikuclient binary
+// run for each web session when -client-binary isn't given
+const ClientBinaryEnv = "DIKUCLIENT_BINARY"
+
+// ResolveClientBinary returns the dikuclient binary to run for each web
+// session: the path given with -client-binary, then $DIKUCLIENT_BINARY, then
+// dikuclient on the PATH or in the working directory, and last the program
+// that is running. A path that was set explicitly must be an executable file.
+func ResolveClientBinary(path string) (string, error) {
+	if path != "" {
+		return checkClientBinary(path, "-client-binary")
+	}
+	if path := os.Getenv(ClientBinaryEnv); path != "" {
+		return checkClientBinary(path, ClientBinaryEnv)
+	}
+
+	if path, err := exec.LookPath("dikuclient"); err == nil {
+		return filepath.Abs(path)
+	}
+	if cwd, err := os.Getwd(); err == nil {
+		if path, err := checkClientBinary(filepath.Join(cwd, "dikuclient"), ""); err == nil {
+			return path, nil
+		}
+	}
+	if path, err := os.Executable(); err == nil {
+		return path, nil
+	}
+	return "", fmt.Errorf("dikuclient binary not found on PATH or in the working directory; set it with -client-binary or %s", ClientBinaryEnv)
+}
+
+// checkClientBinary returns the absolute path of a client binary, or an error
+// naming the setting it came from if it isn't an executable file
+func checkClientBinary(path, source string) (string, error) {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return "", fmt.Errorf("%s %s: %w", source, path, err)
+	}
+	info, err := os.Stat(absPath)
+	if err != nil {
+		return "", fmt.Errorf("%s %s: %w", source, path, err)
+	}
+	if info.IsDir() {
+		return "", fmt.Errorf("%s %s: is a directory, not the dikuclient binary", source, path)
+	}
+	if info.Mode().Perm()&0111 == 0 {
+		return "", fmt.Errorf("%s %s: is not executable", source, path)
+	}
+	return absPath, nil
+}
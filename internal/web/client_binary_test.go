@@ -0,0 +1,89 @@
+//go:build !windows
+// +build !windows
+
+package web
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestResolveClientBinary_Flag(t *testing.T) {
+	t.Setenv(ClientBinaryEnv, "")
+	binary := filepath.Join(t.TempDir(), "my-dikuclient")
+	if err := os.WriteFile(binary, []byte("#!/bin/sh\n"), 0755); err != nil {
+		t.Fatalf("Failed to write binary: %v", err)
+	}
+
+	path, err := ResolveClientBinary(binary)
+	if err != nil || path != binary {
+		t.Errorf("ResolveClientBinary(%q) = (%q, %v), want the path", binary, path, err)
+	}
+}
+
+func TestResolveClientBinary_Env(t *testing.T) {
+	binary := filepath.Join(t.TempDir(), "dikuclient-env")
+	if err := os.WriteFile(binary, []byte("#!/bin/sh\n"), 0755); err != nil {
+		t.Fatalf("Failed to write binary: %v", err)
+	}
+	t.Setenv(ClientBinaryEnv, binary)
+
+	path, err := ResolveClientBinary("")
+	if err != nil || path != binary {
+		t.Errorf("Expected %s from the environment, got (%q, %v)", binary, path, err)
+	}
+}
+
+func TestResolveClientBinary_Default(t *testing.T) {
+	t.Setenv(ClientBinaryEnv, "")
+	t.Setenv("PATH", t.TempDir())
+
+	// Without dikuclient anywhere the running program is used
+	path, err := ResolveClientBinary("")
+	if err != nil {
+		t.Fatalf("ResolveClientBinary() error = %v", err)
+	}
+	executable, _ := os.Executable()
+	if path != executable {
+		t.Errorf("Expected the running program %s, got %s", executable, path)
+	}
+}
+
+func TestResolveClientBinary_NotExecutable(t *testing.T) {
+	t.Setenv(ClientBinaryEnv, "")
+	dir := t.TempDir()
+	notExecutable := filepath.Join(dir, "dikuclient.txt")
+	if err := os.WriteFile(notExecutable, []byte("text"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	tests := []struct {
+		path string
+		want string
+	}{
+		{notExecutable, "is not executable"},
+		{dir, "is a directory"},
+		{filepath.Join(dir, "missing"), "no such file"},
+	}
+	for _, tt := range tests {
+		_, err := ResolveClientBinary(tt.path)
+		if err == nil || !strings.Contains(err.Error(), "-client-binary") || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("ResolveClientBinary(%q) error = %v, want -client-binary and %q", tt.path, err, tt.want)
+		}
+	}
+
+	// A bad path in the environment names the variable
+	t.Setenv(ClientBinaryEnv, notExecutable)
+	if _, err := ResolveClientBinary(""); err == nil || !strings.Contains(err.Error(), ClientBinaryEnv) {
+		t.Errorf("Expected an error naming %s, got %v", ClientBinaryEnv, err)
+	}
+}
+
+func TestStartWithClientBinary_RejectsBadPath(t *testing.T) {
+	err := StartWithClientBinary(0, false, filepath.Join(t.TempDir(), "missing"))
+	if err == nil || !strings.Contains(err.Error(), "-client-binary") {
+		t.Errorf("Expected the server to refuse to start, got %v", err)
+	}
+}
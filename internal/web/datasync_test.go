@@ -96,3 +96,47 @@ func TestDataMessageSerialization(t *testing.T) {
 		t.Errorf("Content mismatch: got %s, want %s", decoded.Content, msg.Content)
 	}
 }
+
+func TestShareURLMessage(t *testing.T) {
+	msg := shareURLMessage(`{"type":"share_url","url":"http://localhost:8080/?id=abc"}`)
+	if msg == nil {
+		t.Fatal("Expected share URL hint to produce a message")
+	}
+	if msg.Type != "share_url" {
+		t.Errorf("Type mismatch: got %s, want share_url", msg.Type)
+	}
+	if msg.Content != "http://localhost:8080/?id=abc" {
+		t.Errorf("Content mismatch: got %s", msg.Content)
+	}
+
+	// Password hints are not share URLs
+	if msg := shareURLMessage(`{"account":"host:4000:user","password":"secret"}`); msg != nil {
+		t.Errorf("Expected nil for password hint, got %+v", msg)
+	}
+	if msg := shareURLMessage("not json"); msg != nil {
+		t.Errorf("Expected nil for invalid hint, got %+v", msg)
+	}
+}
+
+func TestMapHTMLMessage(t *testing.T) {
+	msg := mapHTMLMessage(`{"type":"map_html","url":"/map?id=abc"}`)
+	if msg == nil || msg.Type != "map_html" || msg.Content != "/map?id=abc" {
+		t.Fatalf("Expected a map_html message, got %+v", msg)
+	}
+
+	// Share URLs are not maps
+	if msg := mapHTMLMessage(`{"type":"share_url","url":"http://localhost:8080/?id=abc"}`); msg != nil {
+		t.Errorf("Expected nil for share URL hint, got %+v", msg)
+	}
+}
+
+func TestBellMessage(t *testing.T) {
+	if msg := bellMessage(`{"type":"bell"}`); msg == nil || msg.Type != "bell" {
+		t.Fatalf("Expected a bell message, got %+v", msg)
+	}
+
+	// Password hints are not bells
+	if msg := bellMessage(`{"account":"example.com:4000:hero","password":"secret"}`); msg != nil {
+		t.Errorf("Expected nil for password hint, got %+v", msg)
+	}
+}
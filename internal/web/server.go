@@ -7,7 +7,9 @@ import (
 	"fmt"
 	"log"
 	"net/http"
+	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/google/uuid"
 )
@@ -40,7 +42,22 @@ func Start(port int) error {
 
 // StartWithLogging starts the HTTP server with logging option
 func StartWithLogging(port int, enableLogs bool) error {
+	return StartWithClientBinary(port, enableLogs, "")
+}
+
+// StartWithClientBinary starts the HTTP server, running the given dikuclient
+// binary for each session (empty = the default from ResolveClientBinary).
+// The binary is checked before the server starts, so a bad path fails at
+// once instead of when a browser connects.
+func StartWithClientBinary(port int, enableLogs bool, clientBinary string) error {
+	clientBinary, err := ResolveClientBinary(clientBinary)
+	if err != nil {
+		return err
+	}
+	log.Printf("Running %s for each session", clientBinary)
+
 	server := NewServerWithLogging(port, enableLogs)
+	server.handler.SetClientBinary(clientBinary)
 
 	// Handle root with session management
 	http.HandleFunc("/", server.handleRoot)
@@ -58,6 +75,9 @@ func StartWithLogging(port int, enableLogs bool) error {
 	http.HandleFunc("/ws", server.handler.HandleWebSocket)
 	http.HandleFunc("/data-ws", server.handler.HandleDataWebSocket)
 
+	// Map written by /map html in the session's TUI
+	http.HandleFunc("/map", server.handleMap)
+
 	addr := fmt.Sprintf(":%d", port)
 	log.Printf("Starting web server on %s", addr)
 	return http.ListenAndServe(addr, nil)
@@ -75,6 +95,12 @@ func (s *Server) handleRoot(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// A spectator link from /share view has no session ID of its own
+	if r.URL.Query().Get("view") != "" {
+		http.ServeFile(w, r, filepath.Join("web", "static", "index.html"))
+		return
+	}
+
 	// Check if session ID is provided
 	sessionID := r.URL.Query().Get("id")
 	
@@ -141,3 +167,32 @@ func (s *Server) handleRoot(w http.ResponseWriter, r *http.Request) {
 	// Serve the static index.html file
 	http.ServeFile(w, r, filepath.Join("web", "static", "index.html"))
 }
+
+// mapPageScript sends /go <number> to the page showing the map when a room
+// is clicked, which types it into the session's terminal
+const mapPageScript = `<script>
+document.querySelectorAll('.room').forEach(room => {
+    room.addEventListener('click', () => {
+        window.parent.postMessage({ type: 'map_go', room: room.dataset.room }, window.location.origin);
+    });
+});
+</script>`
+
+// handleMap serves the map drawn by /map html as a page whose rooms can be
+// clicked to walk there
+func (s *Server) handleMap(w http.ResponseWriter, r *http.Request) {
+	sessionID := r.URL.Query().Get("id")
+	if sessionID == "" || sessionID != filepath.Base(sessionID) || strings.HasPrefix(sessionID, ".") {
+		http.Error(w, "Session ID required", http.StatusBadRequest)
+		return
+	}
+
+	svg, err := os.ReadFile(filepath.Join(".websessions", sessionID, "map.svg"))
+	if err != nil {
+		http.Error(w, "No map yet, run /map html in the session", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	fmt.Fprintf(w, "<!DOCTYPE html>\n<html>\n<head><meta charset=\"utf-8\"><title>Map</title></head>\n<body style=\"margin: 0; background: #1e1e1e;\">\n%s%s\n</body>\n</html>\n", svg, mapPageScript)
+}
@@ -6,6 +6,8 @@ package web
 import (
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 )
@@ -166,3 +168,44 @@ func TestHandleRoot_NewPath(t *testing.T) {
 		t.Errorf("should not redirect to /?id=new, should be a UUID")
 	}
 }
+
+func TestHandleMap(t *testing.T) {
+	t.Chdir(t.TempDir())
+	server := NewServer(8080)
+
+	// No map until /map html has run in the session
+	w := httptest.NewRecorder()
+	server.handleMap(w, httptest.NewRequest("GET", "/map?id=abc", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("expected status %d, got %d", http.StatusNotFound, w.Code)
+	}
+
+	if err := os.MkdirAll(filepath.Join(".websessions", "abc"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	svg := `<svg><g class="room" data-room="1"></g></svg>`
+	if err := os.WriteFile(filepath.Join(".websessions", "abc", "map.svg"), []byte(svg), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	w = httptest.NewRecorder()
+	server.handleMap(w, httptest.NewRequest("GET", "/map?id=abc", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, w.Code)
+	}
+	body := w.Body.String()
+	if !strings.Contains(body, svg) || !strings.Contains(body, "map_go") {
+		t.Errorf("expected the SVG and click script in the page, got %s", body)
+	}
+}
+
+func TestHandleMap_RejectsPathsOutsideSession(t *testing.T) {
+	server := NewServer(8080)
+	for _, id := range []string{"", "..", "../abc", "abc/../../x"} {
+		w := httptest.NewRecorder()
+		server.handleMap(w, httptest.NewRequest("GET", "/map?id="+id, nil))
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("expected status %d for id %q, got %d", http.StatusBadRequest, id, w.Code)
+		}
+	}
+}
@@ -16,3 +16,8 @@ func Start(port int) error {
 func StartWithLogging(port int, enableLogs bool) error {
 	return fmt.Errorf("web mode is not supported on Windows")
 }
+
+// StartWithClientBinary returns an error on Windows as web mode is not supported
+func StartWithClientBinary(port int, enableLogs bool, clientBinary string) error {
+	return fmt.Errorf("web mode is not supported on Windows")
+}
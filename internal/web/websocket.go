@@ -20,6 +20,7 @@ import (
 	"unicode/utf8"
 
 	"github.com/creack/pty"
+	"github.com/google/uuid"
 	"github.com/gorilla/websocket"
 )
 
@@ -42,6 +43,7 @@ type SessionServerInfo struct {
 type WebSocketHandler struct {
 	sessions       map[*websocket.Conn]*ClientConnection
 	sharedSessions map[string]*SharedSession // Maps session ID to shared session
+	viewIDs        map[string]string         // Maps a spectator link's view ID to its session ID
 	mu             sync.RWMutex
 	enableLogs     bool   // Whether to enable logging for spawned TUI instances
 	currentSessID  string // Current session ID to use for new connections
@@ -51,6 +53,7 @@ type WebSocketHandler struct {
 	passwordMu     sync.RWMutex
 	sessionServers map[string]*SessionServerInfo // sessionID -> server info
 	sessionServerMu sync.RWMutex
+	clientBinary   string // dikuclient binary run for each session (see ResolveClientBinary)
 }
 
 // SharedSession represents a shared PTY session that multiple clients can connect to
@@ -64,6 +67,7 @@ type SharedSession struct {
 	utf8Buffer []byte // Buffer for incomplete UTF-8 sequences at PTY read boundaries
 	rows       uint16 // Current terminal height
 	cols       uint16 // Current terminal width
+	viewID     string // ID spectators join with; unlike the session ID it gives no control
 }
 
 // ClientConnection represents a single WebSocket client connection to a shared session
@@ -71,6 +75,7 @@ type ClientConnection struct {
 	ws            *websocket.Conn
 	sharedSession *SharedSession
 	sessionID     string
+	spectator     bool // Joined with a view ID, so input and resizes are dropped
 }
 
 // Session represents a WebSocket session with a PTY running the TUI (kept for compatibility)
@@ -110,12 +115,31 @@ func NewWebSocketHandlerWithLogging(enableLogs bool) *WebSocketHandler {
 	return &WebSocketHandler{
 		sessions:       make(map[*websocket.Conn]*ClientConnection),
 		sharedSessions: make(map[string]*SharedSession),
+		viewIDs:        make(map[string]string),
 		enableLogs:     enableLogs,
 		passwordStore:  make(map[string]map[string]string),
 		sessionServers: make(map[string]*SessionServerInfo),
 	}
 }
 
+// SetClientBinary sets the dikuclient binary run for each session
+func (h *WebSocketHandler) SetClientBinary(path string) {
+	h.clientBinary = path
+}
+
+// clientBinaryPath returns the dikuclient binary to run for a session,
+// resolving the default when none was set
+func (h *WebSocketHandler) clientBinaryPath() string {
+	if h.clientBinary != "" {
+		return h.clientBinary
+	}
+	path, err := ResolveClientBinary("")
+	if err != nil {
+		return "dikuclient"
+	}
+	return path
+}
+
 // SetSessionID sets the current session ID for the next connection
 func (h *WebSocketHandler) SetSessionID(sessionID string) {
 	h.sessionIDMu.Lock()
@@ -163,9 +187,20 @@ func (h *WebSocketHandler) HandleWebSocket(w http.ResponseWriter, r *http.Reques
 
 	log.Printf("New WebSocket connection from %s", r.RemoteAddr)
 
-	// Get session ID from query parameter
+	// Get session ID from query parameter. A spectator link gives the view
+	// ID instead, which can't be used to take control of the session.
 	sessionID := r.URL.Query().Get("id")
-	if sessionID == "" {
+	viewID := r.URL.Query().Get("view")
+	spectator := viewID != ""
+	if spectator {
+		id, ok := h.sessionForView(viewID)
+		if !ok {
+			log.Printf("Rejected spectator with unknown view ID %s", viewID)
+			ws.WriteMessage(websocket.TextMessage, []byte("\r\n\x1b[31mERROR: This spectator link is not for a running session\x1b[0m\r\n"))
+			return
+		}
+		sessionID = id
+	} else if sessionID == "" {
 		log.Printf("Warning: No session ID in WebSocket URL, using default")
 		sessionID = "default"
 	}
@@ -210,7 +245,7 @@ func (h *WebSocketHandler) HandleWebSocket(w http.ResponseWriter, r *http.Reques
 	// Add this client to the shared session
 	sharedSession.mu.Lock()
 	sharedSession.clients[ws] = true
-	needsStart := sharedSession.ptmx == nil
+	needsStart := sharedSession.ptmx == nil && !spectator
 	sharedSession.mu.Unlock()
 
 	// Create client connection
@@ -218,6 +253,7 @@ func (h *WebSocketHandler) HandleWebSocket(w http.ResponseWriter, r *http.Reques
 		ws:            ws,
 		sharedSession: sharedSession,
 		sessionID:     sessionID,
+		spectator:     spectator,
 	}
 
 	h.mu.Lock()
@@ -242,6 +278,7 @@ func (h *WebSocketHandler) HandleWebSocket(w http.ResponseWriter, r *http.Reques
 			sharedSession.cleanup()
 			h.mu.Lock()
 			delete(h.sharedSessions, sessionID)
+			delete(h.viewIDs, sharedSession.viewID)
 			h.mu.Unlock()
 		} else {
 			log.Printf("Client disconnected from session %s, %d clients remaining", sessionID, clientCount)
@@ -266,33 +303,68 @@ func (h *WebSocketHandler) HandleWebSocket(w http.ResponseWriter, r *http.Reques
 		}
 
 		if messageType == websocket.TextMessage {
-			// Try to parse as JSON for control messages
-			var msg map[string]interface{}
-			if err := json.Unmarshal(message, &msg); err == nil {
-				if msgType, ok := msg["type"].(string); ok {
-					switch msgType {
-					case "resize":
-						h.handleSharedResize(sharedSession, message)
-						continue
-					}
-				}
+			if err := h.handleClientMessage(client, message); err != nil {
+				log.Printf("Error writing to PTY: %v", err)
+				break
 			}
+		}
+	}
+}
 
-			// Otherwise, it's terminal input - send to PTY
-			sharedSession.mu.RLock()
-			ptmx := sharedSession.ptmx
-			closed := sharedSession.closed
-			sharedSession.mu.RUnlock()
-
-			if ptmx != nil && !closed {
-				_, err := ptmx.Write(message)
-				if err != nil {
-					log.Printf("Error writing to PTY: %v", err)
-					break
-				}
+// handleClientMessage passes a message from a client to its shared session:
+// a resize request or terminal input for the PTY. Spectators only watch, so
+// everything they send is dropped.
+func (h *WebSocketHandler) handleClientMessage(client *ClientConnection, message []byte) error {
+	if client.spectator {
+		return nil
+	}
+	sharedSession := client.sharedSession
+
+	// Try to parse as JSON for control messages
+	var msg map[string]interface{}
+	if err := json.Unmarshal(message, &msg); err == nil {
+		if msgType, ok := msg["type"].(string); ok {
+			switch msgType {
+			case "resize":
+				h.handleSharedResize(sharedSession, message)
+				return nil
 			}
 		}
 	}
+
+	// Otherwise, it's terminal input - send to PTY
+	sharedSession.mu.RLock()
+	ptmx := sharedSession.ptmx
+	closed := sharedSession.closed
+	sharedSession.mu.RUnlock()
+
+	if ptmx != nil && !closed {
+		if _, err := ptmx.Write(message); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// viewIDFor returns the view ID spectators use to watch a shared session,
+// making one the first time it is asked for
+func (h *WebSocketHandler) viewIDFor(sharedSession *SharedSession) string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if sharedSession.viewID == "" {
+		sharedSession.viewID = uuid.New().String()
+		h.viewIDs[sharedSession.viewID] = sharedSession.sessionID
+	}
+	return sharedSession.viewID
+}
+
+// sessionForView returns the ID of the session a spectator's view ID
+// watches, if that session is still running
+func (h *WebSocketHandler) sessionForView(viewID string) (string, bool) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	sessionID, ok := h.viewIDs[viewID]
+	return sessionID, ok
 }
 
 // startSharedTUI starts the TUI for a shared session
@@ -320,16 +392,7 @@ func (h *WebSocketHandler) startSharedTUI(sharedSession *SharedSession, initialS
 	}
 
 	// Get the path to the dikuclient binary
-	dikuclientPath, err := exec.LookPath("dikuclient")
-	if err != nil {
-		// Try relative path from current working directory
-		cwd, _ := os.Getwd()
-		dikuclientPath = filepath.Join(cwd, "dikuclient")
-		// Check if it exists
-		if _, err := os.Stat(dikuclientPath); err != nil {
-			dikuclientPath = "./dikuclient"
-		}
-	}
+	dikuclientPath := h.clientBinaryPath()
 
 	// Build command arguments
 	args := []string{}
@@ -357,6 +420,7 @@ func (h *WebSocketHandler) startSharedTUI(sharedSession *SharedSession, initialS
 	envVars := []string{
 		fmt.Sprintf("DIKUCLIENT_CONFIG_DIR=%s", configDir),
 		fmt.Sprintf("DIKUCLIENT_WEB_SESSION_ID=%s", sharedSession.sessionID),
+		fmt.Sprintf("DIKUCLIENT_WEB_VIEW_ID=%s", h.viewIDFor(sharedSession)),
 		fmt.Sprintf("DIKUCLIENT_WEB_SERVER_URL=%s", serverURL),
 		"TERM=xterm-kitty",        // Ensure consistent color support regardless of server terminal
 		"COLORTERM=truecolor",      // Enable 24-bit true color support
@@ -429,16 +493,7 @@ func (h *WebSocketHandler) autoStartTUIWithSize(session *Session, initialSize *R
 	}
 
 	// Get the path to the dikuclient binary
-	dikuclientPath, err := exec.LookPath("dikuclient")
-	if err != nil {
-		// Try relative path from current working directory
-		cwd, _ := os.Getwd()
-		dikuclientPath = filepath.Join(cwd, "dikuclient")
-		// Check if it exists
-		if _, err := os.Stat(dikuclientPath); err != nil {
-			dikuclientPath = "./dikuclient"
-		}
-	}
+	dikuclientPath := h.clientBinaryPath()
 
 	// Build command arguments - no host/port, just optional logging
 	args := []string{}
@@ -522,12 +577,7 @@ func (h *WebSocketHandler) handleConnect(session *Session, message []byte) {
 	}
 
 	// Get the path to the dikuclient binary
-	// In production, this should be configurable
-	dikuclientPath, err := exec.LookPath("dikuclient")
-	if err != nil {
-		// Try relative path
-		dikuclientPath = "./dikuclient"
-	}
+	dikuclientPath := h.clientBinaryPath()
 
 	// Build command arguments
 	args := []string{"--host", connectMsg.Host, "--port", fmt.Sprintf("%d", connectMsg.Port)}
@@ -901,7 +951,7 @@ type PasswordEntry struct {
 }
 
 type DataMessage struct {
-	Type      string          `json:"type"`       // "file_update", "file_request", "file_not_found", "merge_complete", "passwords_init"
+	Type      string          `json:"type"`       // "file_update", "file_request", "file_not_found", "merge_complete", "passwords_init", "share_url", "map_html", "bell"
 	Path      string          `json:"path"`       // File path relative to config directory
 	Content   string          `json:"content"`    // File content (JSON string)
 	Timestamp int64           `json:"timestamp"`  // Unix timestamp in milliseconds
@@ -1169,7 +1219,7 @@ func (h *WebSocketHandler) watchSessionFiles(conn *DataConnection) {
 	log.Printf("Initial file sync complete for session %s", conn.sessionID)
 }
 
-// watchPasswordHints watches for password hints via FIFO and sends them to client
+// watchPasswordHints watches for password and share URL hints via FIFO and sends them to client
 func (h *WebSocketHandler) watchPasswordHints(conn *DataConnection) {
 	sessionDir := filepath.Join(".websessions", conn.sessionID)
 	fifoPath := filepath.Join(sessionDir, ".password_hint_fifo")
@@ -1220,6 +1270,22 @@ func (h *WebSocketHandler) watchPasswordHints(conn *DataConnection) {
 			for scanner.Scan() {
 				data := scanner.Text()
 				if data != "" {
+					// Share URL hints go straight to the client
+					if msg := shareURLMessage(data); msg != nil {
+						conn.sendMessage(msg)
+						log.Printf("[Server] Sent share URL to client for session %s", conn.sessionID)
+						continue
+					}
+					if msg := mapHTMLMessage(data); msg != nil {
+						conn.sendMessage(msg)
+						log.Printf("[Server] Sent map to client for session %s", conn.sessionID)
+						continue
+					}
+					if msg := bellMessage(data); msg != nil {
+						conn.sendMessage(msg)
+						continue
+					}
+
 					// Parse the hint to update server's password store
 					var hint map[string]string
 					if err := json.Unmarshal([]byte(data), &hint); err == nil {
@@ -1258,6 +1324,47 @@ func (h *WebSocketHandler) watchPasswordHints(conn *DataConnection) {
 	}
 }
 
+// shareURLMessage converts a share URL hint written by the TUI into a
+// share_url data message, or returns nil if the hint is not a share URL
+func shareURLMessage(data string) *DataMessage {
+	return urlHintMessage(data, "share_url")
+}
+
+// mapHTMLMessage converts the hint written by /map html into a map_html data
+// message, or returns nil if the hint is not a map
+func mapHTMLMessage(data string) *DataMessage {
+	return urlHintMessage(data, "map_html")
+}
+
+// bellMessage converts the hint written when a -bell trigger fires into a
+// bell data message, or returns nil if the hint is not a bell
+func bellMessage(data string) *DataMessage {
+	var hint map[string]string
+	if err := json.Unmarshal([]byte(data), &hint); err != nil {
+		return nil
+	}
+	if hint["type"] != "bell" {
+		return nil
+	}
+	return &DataMessage{Type: "bell"}
+}
+
+// urlHintMessage converts a hint of the given type carrying a URL into a data
+// message of the same type, or returns nil for any other hint
+func urlHintMessage(data, hintType string) *DataMessage {
+	var hint map[string]string
+	if err := json.Unmarshal([]byte(data), &hint); err != nil {
+		return nil
+	}
+	if hint["type"] != hintType || hint["url"] == "" {
+		return nil
+	}
+	return &DataMessage{
+		Type:    hintType,
+		Content: hint["url"],
+	}
+}
+
 // sendMessage sends a data message to the client
 func (conn *DataConnection) sendMessage(msg *DataMessage) error {
 	conn.mu.Lock()
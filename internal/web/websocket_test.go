@@ -5,8 +5,12 @@ package web
 
 import (
 	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/gorilla/websocket"
 )
@@ -191,3 +195,75 @@ func TestHandleSharedResize_StoresSize(t *testing.T) {
 		t.Errorf("expected size 100x40 to be stored, got %dx%d", session.cols, session.rows)
 	}
 }
+
+func TestSpectatorInputIsDropped(t *testing.T) {
+	// A pipe stands in for the PTY so what reaches it can be read back
+	reader, writer, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("Failed to create pipe: %v", err)
+	}
+	defer reader.Close()
+	defer writer.Close()
+
+	handler := NewWebSocketHandler()
+	session := &SharedSession{
+		sessionID: "test-session",
+		clients:   make(map[*websocket.Conn]bool),
+		ptmx:      writer,
+		rows:      24,
+		cols:      80,
+	}
+	spectator := &ClientConnection{sharedSession: session, sessionID: "test-session", spectator: true}
+	controller := &ClientConnection{sharedSession: session, sessionID: "test-session"}
+
+	resize, _ := json.Marshal(ResizeMessage{Type: "resize", Cols: 200, Rows: 60})
+	for _, message := range [][]byte{[]byte("quit\r"), resize} {
+		if err := handler.handleClientMessage(spectator, message); err != nil {
+			t.Fatalf("Spectator message failed: %v", err)
+		}
+	}
+	if session.rows != 24 || session.cols != 80 {
+		t.Errorf("Expected a spectator's resize ignored, got %dx%d", session.cols, session.rows)
+	}
+
+	if err := handler.handleClientMessage(controller, []byte("look\r")); err != nil {
+		t.Fatalf("Controller input failed: %v", err)
+	}
+	buf := make([]byte, 64)
+	n, err := reader.Read(buf)
+	if err != nil {
+		t.Fatalf("Failed to read PTY input: %v", err)
+	}
+	if got := string(buf[:n]); got != "look\r" {
+		t.Errorf("Expected only the controller's input on the PTY, got %q", got)
+	}
+}
+
+func TestSpectatorNeedsKnownViewID(t *testing.T) {
+	handler := NewWebSocketHandler()
+	session := &SharedSession{sessionID: "test-session", clients: make(map[*websocket.Conn]bool)}
+	viewID := handler.viewIDFor(session)
+	if viewID == "" || viewID == session.sessionID || handler.viewIDFor(session) != viewID {
+		t.Fatalf("Expected one view ID distinct from the session ID, got %q", viewID)
+	}
+	if id, ok := handler.sessionForView(viewID); !ok || id != "test-session" {
+		t.Errorf("Expected the view ID to find the session, got %q, %v", id, ok)
+	}
+
+	// A made-up view ID is turned away before any session is joined
+	server := httptest.NewServer(http.HandlerFunc(handler.HandleWebSocket))
+	defer server.Close()
+	ws, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http")+"/ws?view=guess", nil)
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer ws.Close()
+	ws.SetReadDeadline(time.Now().Add(2 * time.Second))
+	_, message, err := ws.ReadMessage()
+	if err != nil || !strings.Contains(string(message), "spectator link") {
+		t.Errorf("Expected the spectator link rejected, got %q (%v)", message, err)
+	}
+	if len(handler.sharedSessions) != 0 {
+		t.Errorf("Expected no session created for an unknown view ID, got %d", len(handler.sharedSessions))
+	}
+}
@@ -6,6 +6,9 @@ let fitAddon = null;
 let useFallback = false;
 let fallbackContent = '';
 
+// A /share view link watches the session without being able to type in it
+const spectatorView = new URLSearchParams(window.location.search).get('view') || '';
+
 
 
 // DOM elements
@@ -29,6 +32,20 @@ window.addEventListener('load', () => {
     }, 100);
 });
 
+// Rooms clicked in the /map html panel walk there with /go
+window.addEventListener('message', (event) => {
+    if (event.origin !== window.location.origin || !event.data || event.data.type !== 'map_go') {
+        return;
+    }
+    const room = parseInt(event.data.room, 10);
+    if (ws && connected && !spectatorView && room > 0) {
+        ws.send(`/go ${room}\r`);
+        if (term) {
+            term.focus();
+        }
+    }
+});
+
 // Initialize xterm.js terminal
 function initTerminal() {
     if (useFallback) {
@@ -74,7 +91,7 @@ function initTerminal() {
         
         // Handle terminal input
         term.onData(data => {
-            if (ws && connected) {
+            if (ws && connected && !spectatorView) {
                 ws.send(data);
             }
         });
@@ -120,14 +137,14 @@ function initFallbackTerminal() {
     
     // Handle keyboard input in fallback mode
     terminalDiv.addEventListener('keypress', (e) => {
-        if (ws && connected) {
+        if (ws && connected && !spectatorView) {
             ws.send(e.key);
             e.preventDefault();
         }
     });
     
     terminalDiv.addEventListener('keydown', (e) => {
-        if (ws && connected) {
+        if (ws && connected && !spectatorView) {
             // Handle special keys
             const specialKeys = {
                 'Enter': '\r',
@@ -167,7 +184,10 @@ function connectToServer() {
     const wsProtocol = window.location.protocol === 'https:' ? 'wss:' : 'ws:';
     
     // Use the current host (includes port if non-standard) for reverse proxy compatibility
-    const wsUrl = `${wsProtocol}//${window.location.host}/ws?id=${sessionId}`;
+    // Spectators join by the session's view ID rather than its ID
+    const wsUrl = spectatorView
+        ? `${wsProtocol}//${window.location.host}/ws?view=${spectatorView}`
+        : `${wsProtocol}//${window.location.host}/ws?id=${sessionId}`;
     
     ws = new WebSocket(wsUrl);
 
@@ -63,6 +63,18 @@ async function handleDataMessage(message) {
             // Server sent a password hint (for manually entered passwords)
             await handlePasswordHint(message);
             break;
+        case 'share_url':
+            // User ran /share, offer a copy button for the URL
+            showShareUrl(message.content);
+            break;
+        case 'map_html':
+            // User ran /map html, show the map over the terminal
+            showMap(message.content);
+            break;
+        case 'bell':
+            // A -bell trigger fired, play a short alert
+            playBell();
+            break;
         case 'merge_complete':
             console.log('Data merge complete:', message.files);
             break;
@@ -97,6 +109,91 @@ async function handlePasswordHint(message) {
 }
 
 
+// Show the share URL in a small banner with a copy button
+function showShareUrl(url) {
+    let banner = document.getElementById('share-banner');
+    if (!banner) {
+        banner = document.createElement('div');
+        banner.id = 'share-banner';
+
+        const text = document.createElement('span');
+        text.className = 'share-url';
+        banner.appendChild(text);
+
+        const copyButton = document.createElement('button');
+        copyButton.className = 'share-copy';
+        copyButton.textContent = 'Copy';
+        copyButton.addEventListener('click', async () => {
+            try {
+                await navigator.clipboard.writeText(banner.dataset.url);
+                copyButton.textContent = 'Copied!';
+            } catch (e) {
+                console.error('[Client] Failed to copy share URL:', e);
+                copyButton.textContent = 'Copy failed';
+            }
+            setTimeout(() => { copyButton.textContent = 'Copy'; }, 2000);
+        });
+        banner.appendChild(copyButton);
+
+        const closeButton = document.createElement('button');
+        closeButton.className = 'share-close';
+        closeButton.textContent = '×';
+        closeButton.addEventListener('click', () => banner.remove());
+        banner.appendChild(closeButton);
+
+        document.body.appendChild(banner);
+    }
+
+    banner.dataset.url = url;
+    banner.querySelector('.share-url').textContent = url;
+}
+
+// Show the map from /map html in a panel over the terminal. Clicking a
+// room in it walks there (see the map_go handler in app.js).
+function showMap(url) {
+    let overlay = document.getElementById('map-overlay');
+    if (!overlay) {
+        overlay = document.createElement('div');
+        overlay.id = 'map-overlay';
+
+        const closeButton = document.createElement('button');
+        closeButton.className = 'map-close';
+        closeButton.textContent = '×';
+        closeButton.addEventListener('click', () => overlay.remove());
+        overlay.appendChild(closeButton);
+
+        const frame = document.createElement('iframe');
+        frame.title = 'Map';
+        overlay.appendChild(frame);
+
+        document.body.appendChild(overlay);
+    }
+
+    // Reload even when the URL is unchanged, the map may have grown
+    overlay.querySelector('iframe').src = `${url}&t=${Date.now()}`;
+}
+
+// Play a short beep for a -bell trigger, the browser's stand-in for the
+// terminal bell
+let bellAudio = null;
+function playBell() {
+    try {
+        if (!bellAudio) {
+            bellAudio = new (window.AudioContext || window.webkitAudioContext)();
+        }
+        const oscillator = bellAudio.createOscillator();
+        const gain = bellAudio.createGain();
+        oscillator.frequency.value = 880;
+        gain.gain.setValueAtTime(0.2, bellAudio.currentTime);
+        gain.gain.exponentialRampToValueAtTime(0.001, bellAudio.currentTime + 0.3);
+        oscillator.connect(gain);
+        gain.connect(bellAudio.destination);
+        oscillator.start();
+        oscillator.stop(bellAudio.currentTime + 0.3);
+    } catch (err) {
+        console.log('Could not play bell:', err);
+    }
+}
 
 // Handle file update from server
 async function handleFileUpdate(message) {
@@ -303,6 +400,11 @@ async function handleDataMerge() {
 
 // Initialize data sync on page load
 window.addEventListener('load', () => {
+    // Spectators have no files or passwords to sync
+    if (new URLSearchParams(window.location.search).get('view')) {
+        return;
+    }
+
     // Wait a bit for the main terminal connection to establish first
     setTimeout(() => {
         connectDataSync();